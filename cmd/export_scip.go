@@ -0,0 +1,78 @@
+// cmd/export_scip.go - export-scip subcommand
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"codebase-indexer/internal/utils"
+	"codebase-indexer/pkg/codegraph/scip"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
+)
+
+const exportSCIPCommand = "export-scip"
+
+// runExportSCIP exports the codegraph index of a workspace as a SCIP index file.
+// The daemon holds the index lock, so it must be stopped before running this command.
+func runExportSCIP(args []string) int {
+	fs := flag.NewFlagSet(exportSCIPCommand, flag.ExitOnError)
+	appName := fs.String("appname", "codebase-indexer", "app name")
+	workspacePath := fs.String("workspace", "", "workspace path to export (required)")
+	output := fs.String("output", "index.scip", "output SCIP file path")
+	logLevel := fs.String("loglevel", "info", "log level (debug, info, warn, error)")
+	_ = fs.Parse(args)
+
+	if *workspacePath == "" {
+		fmt.Fprintln(os.Stderr, "missing required -workspace")
+		fs.Usage()
+		return 2
+	}
+	absWorkspace, err := filepath.Abs(*workspacePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid workspace path: %v\n", err)
+		return 1
+	}
+	if err := initDir(*appName); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize directory: %v\n", err)
+		return 1
+	}
+	appLogger, err := logger.NewLogger(utils.LogsDir, *logLevel, *appName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize logging system: %v\n", err)
+		return 1
+	}
+
+	codegraphStore, err := store.NewGraphStorage(store.StorageBackend(os.Getenv(store.StorageBackendEnv)), utils.IndexDir, appLogger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open codegraph store: %v\n", err)
+		return 1
+	}
+	defer codegraphStore.Close()
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create output file: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+
+	exporter := scip.NewExporter(workspace.NewWorkSpaceReader(appLogger), codegraphStore, appLogger)
+	exporter.ToolVersion = version
+	if err := exporter.ExportSCIP(context.Background(), absWorkspace, writer); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to export scip index: %v\n", err)
+		return 1
+	}
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write output file: %v\n", err)
+		return 1
+	}
+	fmt.Printf("scip index written to %s\n", *output)
+	return 0
+}
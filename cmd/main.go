@@ -10,7 +10,7 @@ import (
 	"fmt"
 	"runtime"
 
-	// "net"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
@@ -31,10 +31,12 @@ import (
 	"codebase-indexer/pkg/codegraph/analyzer"
 	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
 	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/logger"
-	// "google.golang.org/grpc"
+
+	"google.golang.org/grpc"
 )
 
 var (
@@ -45,6 +47,14 @@ var (
 )
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == exportSCIPCommand {
+		os.Exit(runExportSCIP(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == verifyIndexCommand {
+		os.Exit(runVerifyIndex(os.Args[2:]))
+	}
+
 	if osName != "" {
 		fmt.Printf("OS: %s\n", osName)
 	}
@@ -57,7 +67,7 @@ func main() {
 
 	// Parse command line arguments
 	appName := flag.String("appname", "codebase-indexer", "app name")
-	// grpcServer := flag.String("grpc", "localhost:51353", "gRPC server address")
+	grpcServer := flag.String("grpc", "", "gRPC server address for codegraph queries, e.g. localhost:51353 (disabled if empty)")
 	httpServer := flag.String("http", "localhost:11380", "HTTP server address")
 	logLevel := flag.String("loglevel", "info", "log level (debug, info, warn, error)")
 	enableSwagger := flag.Bool("swagger", false, "enable swagger documentation")
@@ -129,6 +139,7 @@ func main() {
 	// Initialize repositories
 	workspaceRepo := repository.NewWorkspaceRepository(dbManager, appLogger)
 	eventRepo := repository.NewEventRepository(dbManager, appLogger)
+	metricsRepo := repository.NewMetricsRepository(dbManager, appLogger)
 	scanRepo := repository.NewFileScanner(appLogger)
 	syncRepo := repository.NewHTTPSync(syncServiceConfig, appLogger)
 
@@ -140,8 +151,12 @@ func main() {
 	embeddingStatusService := service.NewEmbeddingStatusService(codebaseEmbeddingRepo, workspaceRepo, eventRepo, syncRepo, appLogger)
 
 	// 创建存储
-	codegraphStore, err := store.NewLevelDBStorage(utils.IndexDir, appLogger)
-	defer func(codegraphStore *store.LevelDBStorage) {
+	codegraphStore, err := store.NewGraphStorage(store.StorageBackend(os.Getenv(store.StorageBackendEnv)), utils.IndexDir, appLogger)
+	if err != nil {
+		appLogger.Fatal("failed to initialize codegraph store: %v", err)
+		return
+	}
+	defer func(codegraphStore store.GraphStorage) {
 		err = codegraphStore.Close()
 		if err != nil {
 			appLogger.Error("failed to close codegraph store: %v", err)
@@ -162,13 +177,16 @@ func main() {
 	indexer := service.NewCodeIndexer(scanRepo, sourceFileParser, dependencyAnalyzer, workspaceReader, codegraphStore,
 		workspaceRepo, service.IndexerConfig{VisitPattern: workspace.DefaultVisitPattern}, appLogger)
 
-	codegraphProcessor := service.NewCodegraphProcessor(workspaceReader, indexer, workspaceRepo, eventRepo, appLogger)
-	codebaseService := service.NewCodebaseService(storageManager, appLogger, workspaceReader, workspaceRepo, definition.NewDefinitionParser(), indexer)
+	codegraphProcessor := service.NewCodegraphProcessor(workspaceReader, indexer, workspaceRepo, eventRepo, metricsRepo, appLogger)
+	codebaseService := service.NewCodebaseService(storageManager, appLogger, workspaceReader, workspaceRepo, metricsRepo, eventRepo,
+		definition.NewDefinitionParser(), indexer)
 	extensionService := service.NewExtensionService(storageManager, syncRepo, scanRepo, workspaceRepo, eventRepo, codebaseEmbeddingRepo, codebaseService, fileScanService, appLogger)
 
 	// Initialize job layer
 	// 定时全量扫工作区
 	fileScanJob := job.NewFileScanJob(fileScanService, storageManager, syncRepo, appLogger, 5*time.Minute)
+	// 监听文件变更，去抖后增量生成事件
+	fileWatchJob := job.NewFileWatchJob(fileScanService, scanRepo, storageManager, appLogger, 2*time.Second)
 	eventProcessorJob := job.NewEventProcessorJob(appLogger, syncRepo, embeddingProcessService, codegraphProcessor, 120*time.Second, storageManager)
 	// 超时处理
 	statusCheckerJob := job.NewStatusCheckerJob(embeddingStatusService, storageManager, syncRepo, appLogger, 80*time.Second)
@@ -188,8 +206,33 @@ func main() {
 	// s := grpc.NewServer()
 	// api.RegisterSyncServiceServer(s, grpcHandler)
 
+	// Start codegraph gRPC server if enabled, sharing the services and auth/rate limit rules with the HTTP handlers
+	var grpcServerInstance *grpc.Server
+	if *grpcServer != "" {
+		lis, err := net.Listen("tcp", *grpcServer)
+		if err != nil {
+			appLogger.Error("failed to listen gRPC address %s: %v", *grpcServer, err)
+			return
+		}
+		grpcServerInstance = grpc.NewServer(server.GRPCServerOptions(appLogger)...)
+		codegraphpb.RegisterCodegraphServiceServer(grpcServerInstance,
+			handler.NewCodegraphGRPCHandler(codebaseService, extensionService, appLogger))
+		go func() {
+			if err := grpcServerInstance.Serve(lis); err != nil {
+				appLogger.Error("gRPC server error: %v", err)
+			}
+		}()
+		appLogger.Info("gRPC server listening on %s", *grpcServer)
+	}
+
 	// Initialize HTTP server
 	httpServerInstance := server.NewServer(extensionHandler, backendHandler, appLogger)
+	httpServerInstance.AddReadinessCheck("database", func(ctx context.Context) error {
+		return dbManager.GetDB().PingContext(ctx)
+	})
+	httpServerInstance.AddReadinessCheck("codegraphStore", func(ctx context.Context) error {
+		return store.PingStorage(ctx, codegraphStore)
+	})
 	if *enableSwagger {
 		httpServerInstance.EnableSwagger()
 		appLogger.Info("swagger documentation enabled")
@@ -197,12 +240,31 @@ func main() {
 
 	// Start daemonProcess process
 	// daemonProcess := daemonProcess.NewDaemon(syncScheduler, s, lis, httpSync, fileScanner, storageManager, appLogger)
-	daemonProcess := daemon.NewDaemon(schedulerService, syncRepo, scanRepo, storageManager, appLogger,
-		fileScanJob, eventProcessorJob, statusCheckerJob, indexCleanJob, eventCleanerJob)
+	jobConfig := config.LoadJobConfig(store.IsReadOnlyStorage(codegraphStore))
+	var jobs []daemon.Job
+	for _, j := range []struct {
+		name config.JobName
+		job  daemon.Job
+	}{
+		{config.JobFileScan, fileScanJob},
+		{config.JobFileWatch, fileWatchJob},
+		{config.JobEventProcessor, eventProcessorJob},
+		{config.JobStatusChecker, statusCheckerJob},
+		{config.JobIndexClean, indexCleanJob},
+		{config.JobEventCleaner, eventCleanerJob},
+	} {
+		if !jobConfig.JobEnabled(j.name) {
+			appLogger.Info("%s job is disabled, file watch enabled: %v, codegraph store read-only: %v",
+				j.name, jobConfig.FileWatchEnabled, jobConfig.ReadOnly)
+			continue
+		}
+		jobs = append(jobs, j.job)
+	}
+	daemonProcess := daemon.NewDaemon(schedulerService, syncRepo, scanRepo, storageManager, appLogger, jobs...)
 	go daemonProcess.Start()
 
 	// Start pprof server if enabled
-	setupPprof(appLogger)
+	setupPprof(appLogger, indexer)
 
 	// Start HTTP server
 	httpErrChan := make(chan error, 1)
@@ -244,6 +306,13 @@ func main() {
 	if err := httpServerInstance.Shutdown(ctx); err != nil {
 		appLogger.Error("HTTP server shutdown error: %v", err)
 	}
+	if grpcServerInstance != nil {
+		grpcServerInstance.GracefulStop()
+	}
+	// 关闭存储前等待进行中的调用图反向索引构建刷盘
+	if err := indexer.Shutdown(ctx); err != nil {
+		appLogger.Error("indexer shutdown error: %v", err)
+	}
 
 	appLogger.Info("client has been successfully closed")
 }
@@ -253,7 +322,16 @@ func memStatsHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(memStats)
 	w.Header().Set("Content-Type", "application/json")
 }
-func setupPprof(appLogger logger.Logger) {
+
+// cacheStatsHandler 返回索引符号缓存的命中、未命中、淘汰计数，用于调整 CACHE_CAPACITY
+func cacheStatsHandler(indexer service.Indexer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(indexer.SymbolCacheStats())
+	}
+}
+
+func setupPprof(appLogger logger.Logger, indexer service.Indexer) {
 	pprofConfig := config.GetClientConfig().Pprof
 	if pprofConfig.Enabled {
 		go func() {
@@ -268,6 +346,7 @@ func setupPprof(appLogger logger.Logger) {
 			pprofMux.Handle("/debug/pprof/threadcreate", pprof.Handler("threadcreate"))
 			pprofMux.Handle("/debug/pprof/block", pprof.Handler("block"))
 			pprofMux.Handle("/debug/pprof/memStats", http.HandlerFunc(memStatsHandler))
+			pprofMux.Handle("/debug/pprof/cacheStats", cacheStatsHandler(indexer))
 
 			appLogger.Info("pprof server starting on %s", pprofConfig.Address)
 			if err := http.ListenAndServe(pprofConfig.Address, pprofMux); err != nil && err != http.ErrServerClosed {
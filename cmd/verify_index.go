@@ -0,0 +1,78 @@
+// cmd/verify_index.go - verify-index subcommand
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"codebase-indexer/internal/service/indexer"
+	"codebase-indexer/internal/utils"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
+)
+
+const verifyIndexCommand = "verify-index"
+
+// verifyIndexInconsistent is the exit code when the index has inconsistencies.
+const verifyIndexInconsistent = 3
+
+// runVerifyIndex checks the codegraph index of a workspace for inconsistencies and prints a JSON report.
+// The daemon holds the index lock, so it must be stopped before running this command.
+func runVerifyIndex(args []string) int {
+	fs := flag.NewFlagSet(verifyIndexCommand, flag.ExitOnError)
+	appName := fs.String("appname", "codebase-indexer", "app name")
+	workspacePath := fs.String("workspace", "", "workspace path to verify (required)")
+	logLevel := fs.String("loglevel", "info", "log level (debug, info, warn, error)")
+	_ = fs.Parse(args)
+
+	if *workspacePath == "" {
+		fmt.Fprintln(os.Stderr, "missing required -workspace")
+		fs.Usage()
+		return 2
+	}
+	absWorkspace, err := filepath.Abs(*workspacePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid workspace path: %v\n", err)
+		return 1
+	}
+	if err := initDir(*appName); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize directory: %v\n", err)
+		return 1
+	}
+	appLogger, err := logger.NewLogger(utils.LogsDir, *logLevel, *appName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize logging system: %v\n", err)
+		return 1
+	}
+
+	codegraphStore, err := store.NewGraphStorage(store.StorageBackend(os.Getenv(store.StorageBackendEnv)), utils.IndexDir, appLogger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open codegraph store: %v\n", err)
+		return 1
+	}
+	defer codegraphStore.Close()
+
+	// verification only reads the store and discovers projects on disk, no parser or database is needed
+	codeIndexer := indexer.NewIndexer(nil, nil, nil, workspace.NewWorkSpaceReader(appLogger), codegraphStore,
+		nil, indexer.Config{}, appLogger)
+	report, err := codeIndexer.VerifyIndex(context.Background(), absWorkspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to verify index: %v\n", err)
+		return 1
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+		return 1
+	}
+	if !report.Consistent() {
+		return verifyIndexInconsistent
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// FileWatchEnabledEnv 开启文件监听任务的环境变量
+const FileWatchEnabledEnv = "FILE_WATCH_ENABLED"
+
+// JobName 后台任务名称
+type JobName string
+
+const (
+	JobFileScan       JobName = "file scan"
+	JobFileWatch      JobName = "file watch"
+	JobEventProcessor JobName = "event processor"
+	JobStatusChecker  JobName = "status checker"
+	JobIndexClean     JobName = "index clean"
+	JobEventCleaner   JobName = "event cleaner"
+)
+
+// JobConfig 后台任务配置
+type JobConfig struct {
+	FileWatchEnabled bool `json:"fileWatchEnabled"` // 开启文件监听任务，默认关闭，由定时全量扫描兜底
+	ReadOnly         bool `json:"readOnly"`         // 代码图存储只读，实例只提供查询
+}
+
+// LoadJobConfig 加载后台任务配置，readOnly 为代码图存储是否以只读方式打开
+func LoadJobConfig(readOnly bool) *JobConfig {
+	jobConfig := &JobConfig{ReadOnly: readOnly}
+	if env, ok := os.LookupEnv(FileWatchEnabledEnv); ok {
+		if val, err := strconv.ParseBool(env); err == nil {
+			jobConfig.FileWatchEnabled = val
+		}
+	}
+	return jobConfig
+}
+
+// JobEnabled 后台任务是否运行：只读实例不扫描、监听文件，也不建立或清理索引；文件监听任务需显式开启
+func (c *JobConfig) JobEnabled(name JobName) bool {
+	switch name {
+	case JobFileWatch:
+		return c.FileWatchEnabled && !c.ReadOnly
+	case JobFileScan, JobEventProcessor, JobIndexClean:
+		return !c.ReadOnly
+	default:
+		return true
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadJobConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want bool
+	}{
+		{name: "开启文件监听", env: "true", want: true},
+		{name: "关闭文件监听", env: "false", want: false},
+		{name: "无效值保持默认关闭", env: "yes-please", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FileWatchEnabledEnv, tt.env)
+			assert.Equal(t, &JobConfig{FileWatchEnabled: tt.want, ReadOnly: true}, LoadJobConfig(true))
+		})
+	}
+}
+
+func TestJobConfig_JobEnabled(t *testing.T) {
+	allJobs := []JobName{JobFileScan, JobFileWatch, JobEventProcessor, JobStatusChecker, JobIndexClean, JobEventCleaner}
+	tests := []struct {
+		name   string
+		config JobConfig
+		want   []JobName
+	}{
+		{
+			name:   "默认不运行文件监听",
+			config: JobConfig{},
+			want:   []JobName{JobFileScan, JobEventProcessor, JobStatusChecker, JobIndexClean, JobEventCleaner},
+		},
+		{
+			name:   "开启文件监听",
+			config: JobConfig{FileWatchEnabled: true},
+			want:   allJobs,
+		},
+		{
+			name:   "只读实例只运行不写索引的任务",
+			config: JobConfig{FileWatchEnabled: true, ReadOnly: true},
+			want:   []JobName{JobStatusChecker, JobEventCleaner},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enabled []JobName
+			for _, name := range allJobs {
+				if tt.config.JobEnabled(name) {
+					enabled = append(enabled, name)
+				}
+			}
+			assert.Equal(t, tt.want, enabled)
+		})
+	}
+}
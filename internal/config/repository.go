@@ -19,6 +19,17 @@ type SyncConfig struct {
 	ClientId  string `json:"machine_id"`
 	Token     string `json:"access_token"`
 	ServerURL string `json:"base_url"`
+	// MaxRetries 上传失败后的最大重试次数，<=0 使用默认值
+	MaxRetries int `json:"max_retries,omitempty"`
+	// RetryBaseDelay 首次重试前的等待时间，之后按指数退避翻倍，<=0 使用默认值
+	RetryBaseDelay time.Duration `json:"retry_base_delay,omitempty"`
+	// RetryMaxDelay 单次退避等待的上限，<=0 使用默认值
+	RetryMaxDelay time.Duration `json:"retry_max_delay,omitempty"`
+	// ChunkSize 分片大小，超过该大小的文件分片上传并支持断点续传，<=0 不分片。
+	// 需要服务端支持分片上传接口，服务端不支持时整体上传
+	ChunkSize int64 `json:"chunk_size,omitempty"`
+	// RetryMaxElapsed 单个请求（含所有重试和等待）的总耗时上限，<=0 使用默认值
+	RetryMaxElapsed time.Duration `json:"retry_max_elapsed,omitempty"`
 }
 
 type CodebaseEnv struct {
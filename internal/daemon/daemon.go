@@ -43,6 +43,8 @@ func NewDaemon(scheduler *service.Scheduler, httpSync repository.SyncInterface,
 	fileScanner repository.ScannerInterface, storage repository.StorageInterface, logger logger.Logger,
 	jobs ...Job) *Daemon {
 	ctx, cancel := context.WithCancel(context.Background())
+	// daemon 停止时取消正在等待的上传、拉取重试
+	httpSync.SetContext(ctx)
 	return &Daemon{
 		scheduler: scheduler,
 		// grpcServer:  grpcServer,
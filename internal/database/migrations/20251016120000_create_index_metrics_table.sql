@@ -0,0 +1,19 @@
+-- 创建索引任务指标表
+CREATE TABLE IF NOT EXISTS index_metrics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    workspace_path VARCHAR(500) NOT NULL,
+    start_ts BIGINT NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    total_files INTEGER NOT NULL DEFAULT 0,
+    failed_files INTEGER NOT NULL DEFAULT 0,
+    skipped_files INTEGER NOT NULL DEFAULT 0,
+    total_symbols INTEGER NOT NULL DEFAULT 0,
+    saved_symbols INTEGER NOT NULL DEFAULT 0,
+    total_variables INTEGER NOT NULL DEFAULT 0,
+    saved_variables INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_index_metrics_workspace_path ON index_metrics(workspace_path, start_ts);
@@ -12,7 +12,22 @@ type SearchReferenceRequest struct {
 	FilePath     string `form:"filePath"` // 可选，适配单符号查询
 	StartLine    int    `form:"startLine"`
 	EndLine      int    `form:"endLine"`
+	StartColumn  int    `form:"startColumn,omitempty"`
+	EndColumn    int    `form:"endColumn,omitempty"`
 	SymbolName   string `form:"symbolName"`
+	ProjectUuid  string `form:"projectUuid,omitempty"` // 可选，指定项目uuid时跳过项目发现
+	// 可选，只返回指定种类的引用：call 调用、reference 调用以外的引用，为空时返回全部
+	ReferenceKinds []string `form:"referenceKinds,omitempty"`
+	// 可选，行范围最大跨度，小于等于0时使用默认值200，最大5000
+	MaxLineLimit int `form:"maxLineLimit,omitempty"`
+	// 可选，排除测试文件中的引用
+	ExcludeTests bool `form:"excludeTests,omitempty"`
+	// 可选，只在 filePath 所在文件内查找引用，不遍历项目
+	SameFileOnly bool `form:"sameFileOnly,omitempty"`
+	// 可选，分页时跳过的引用数
+	Offset int `form:"offset,omitempty"`
+	// 可选，每页最多返回的引用数，小于等于0时不分页
+	Limit int `form:"limit,omitempty"`
 }
 
 // RelationNode 关系节点
@@ -33,7 +48,22 @@ type Position struct {
 }
 
 type ReferenceData struct {
-	List []*types.RelationNode `json:"list"`
+	List  []*types.RelationNode `json:"list"`
+	Total int                   `json:"total"` // 分页前的引用总数
+}
+
+const (
+	ReferenceStreamTypeDefinition = "definition"
+	ReferenceStreamTypeReference  = "reference"
+	ReferenceStreamTypeError      = "error"
+)
+
+// ReferenceStreamItem 流式关系检索的一行（NDJSON），定义先于其引用返回
+type ReferenceStreamItem struct {
+	Type            string              `json:"type"`            // definition / reference / error
+	DefinitionIndex int                 `json:"definitionIndex"` // 节点所属定义的序号，按定义返回顺序从0开始
+	Node            *types.RelationNode `json:"node,omitempty"`
+	Message         string              `json:"message,omitempty"` // type 为 error 时的错误信息
 }
 
 // SearchDefinitionRequest 获取定义请求
@@ -44,22 +74,47 @@ type SearchDefinitionRequest struct {
 	SymbolNames  string `form:"symbolNames"`
 	StartLine    int    `form:"startLine,omitempty"`
 	EndLine      int    `form:"endLine,omitempty"`
+	StartColumn  int    `form:"startColumn,omitempty"`
+	EndColumn    int    `form:"endColumn,omitempty"`
 	CodeSnippet  string `form:"codeSnippet,omitempty"`
+	ProjectUuid  string `form:"projectUuid,omitempty"` // 可选，指定项目uuid时跳过项目发现
+	StartByte    int    `form:"startByte,omitempty"`   // 可选，开始字节偏移（从0开始）
+	EndByte      int    `form:"endByte,omitempty"`     // 可选，结束字节偏移（不含），大于0时按字节偏移查询
+	// 可选，无法按扩展名推断语言时在所有支持的语言中查找定义
+	LanguageFallback bool `form:"languageFallback,omitempty"`
+	// 可选，按名称匹配到多个定义时的取舍策略：relaxed（默认，代码片段查询默认为 strict）、strict、scoped
+	Resolution string `form:"resolution,omitempty"`
+	// 可选，返回定义的文档注释
+	IncludeDoc bool `form:"includeDoc,omitempty"`
+	// 可选，只返回当前文件内的定义
+	LocalOnly bool `form:"localOnly,omitempty"`
 }
 
 // CallGraphData 代码片段内部元素或单符号的调用链
 type CallGraphData struct {
-	List []*types.RelationNode `json:"list"`
+	List   []*types.RelationNode   `json:"list"`
+	Cycles []*types.CallGraphCycle `json:"cycles,omitempty"` // 开启环检测时返回检测到的环
+	// Truncated 达到节点数或耗时上限，调用图不完整
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // GetCallGraphRequest 获取函数调用链及其函数定义
 type SearchCallGraphRequest struct {
-	ClientId     string `form:"clientId" binding:"required"`
-	CodebasePath string `form:"codebasePath" binding:"required"`
-	FilePath     string `form:"filePath" binding:"required"`
-	LineRange    string `form:"lineRange,omitempty"`
-	SymbolName   string `form:"symbolName,omitempty"`
-	MaxLayer     int    `form:"maxLayer,omitempty"`
+	ClientId         string `form:"clientId" binding:"required"`
+	CodebasePath     string `form:"codebasePath" binding:"required"`
+	FilePath         string `form:"filePath" binding:"required"`
+	LineRange        string `form:"lineRange,omitempty"`
+	SymbolName       string `form:"symbolName,omitempty"`
+	MaxLayer         int    `form:"maxLayer,omitempty"`
+	ProjectUuid      string `form:"projectUuid,omitempty"`      // 可选，指定项目uuid时跳过项目发现
+	MaxLineLimit     int    `form:"maxLineLimit,omitempty"`     // 可选，行范围最大跨度，小于等于0时使用默认值1000，最大5000
+	PathPrefix       string `form:"pathPrefix,omitempty"`       // 可选，只沿该目录下的调用者遍历
+	DetectCycles     bool   `form:"detectCycles,omitempty"`     // 可选，标记并汇总调用图中的环
+	IncludeVariables bool   `form:"includeVariables,omitempty"` // 可选，附加函数引用的变量、常量、字段定义叶子节点
+	MaxNodes         int    `form:"maxNodes,omitempty"`         // 可选，调用图节点总数上限，达到后停止展开，不超过服务端上限
+	TimeoutMs        int    `form:"timeoutMs,omitempty"`        // 可选，构建调用图的耗时上限（毫秒），超时后停止展开
+	ExcludeTests     bool   `form:"excludeTests,omitempty"`     // 可选，不沿测试文件中的调用者遍历
+	Format           string `form:"format,omitempty"`           // 可选，返回格式：json（默认）、dot、mermaid
 }
 
 type ReadCodeSnippetsRequest struct {
@@ -92,6 +147,8 @@ type DefinitionInfo struct {
 	Type     string   `json:"type"`
 	Content  string   `json:"content,omitempty"`
 	Position Position `json:"position"`
+	Score    int      `json:"score,omitempty"`
+	Doc      string   `json:"doc,omitempty"`
 }
 
 type DefinitionData struct {
@@ -160,6 +217,16 @@ type GetIndexSummaryRequest struct {
 	CodebasePath string `form:"codebasePath" binding:"required"`
 }
 
+// DefaultIndexMetricsLimit 未指定条数时返回的索引任务指标条数
+const DefaultIndexMetricsLimit = 20
+
+// ListIndexMetricsRequest 获取索引任务指标历史请求
+type ListIndexMetricsRequest struct {
+	ClientId     string `form:"clientId" binding:"required"`
+	CodebasePath string `form:"codebasePath" binding:"required"`
+	Limit        int    `form:"limit,omitempty"`
+}
+
 // ExportIndexRequest 导出索引请求
 type ExportIndexRequest struct {
 	ClientId     string `form:"clientId" binding:"required"`
@@ -173,6 +240,36 @@ type DeleteIndexRequest struct {
 	IndexType    string `form:"indexType" binding:"required"`
 }
 
+// RebuildProjectRequest 重建项目索引请求
+type RebuildProjectRequest struct {
+	ClientId     string `json:"clientId" binding:"required"`
+	CodebasePath string `json:"codebasePath" binding:"required"`
+	ProjectPath  string `json:"projectPath" binding:"required"` // 工作区下项目的根目录绝对路径
+}
+
+// CompactIndexRequest 压缩索引请求
+type CompactIndexRequest struct {
+	ClientId     string `json:"clientId" binding:"required"`
+	CodebasePath string `json:"codebasePath"` // 可选，为空时压缩所有项目的索引
+}
+
+// CompactIndexData 压缩索引结果
+type CompactIndexData struct {
+	ProjectUuids []string `json:"projectUuids"` // 已压缩的项目uuid
+}
+
+// ReconcileIndexRequest 核对索引文件数请求
+type ReconcileIndexRequest struct {
+	ClientId     string `json:"clientId" binding:"required"`
+	CodebasePath string `json:"codebasePath"` // 可选，为空时核对所有工作区
+	Fix          bool   `json:"fix"`          // 是否以索引存储为准修正数据库记录
+}
+
+// ReconcileIndexData 核对索引文件数结果
+type ReconcileIndexData struct {
+	Results []*types.CodegraphReconcileResult `json:"results"`
+}
+
 // IndexSummary 索引摘要
 type IndexSummary struct {
 	Codegraph CodegraphInfo `json:"codegraph"`
@@ -182,6 +279,7 @@ type IndexSummary struct {
 type CodegraphInfo struct {
 	Status     string `json:"status"`
 	TotalFiles int    `json:"totalFiles"`
+	Truncated  bool   `json:"truncated"` // 达到最大文件数限制，索引不完整
 }
 
 // ToPosition 辅助函数：将 ranges 转换为 Position
@@ -246,6 +344,51 @@ type FileSkeletonElement struct {
 	Range        []int  `json:"range"`        // [startLine, startCol, endLine, endCol] - 从1开始
 }
 
+// GetFileElementsRequest 获取文件原始元素表请求
+type GetFileElementsRequest struct {
+	Workspace string `form:"workspace" binding:"required"`
+	Path      string `form:"path" binding:"required"`
+}
+
+// GetFileOutlineRequest 获取文件大纲请求
+type GetFileOutlineRequest struct {
+	Workspace string `form:"workspace" binding:"required"`
+	Path      string `form:"path" binding:"required"`
+}
+
+// FileElementsData 解码后的文件元素表，range 保持存储中的原始值（从0开始）
+type FileElementsData struct {
+	Path      string                `json:"path"`
+	Language  string                `json:"language"`
+	Timestamp int64                 `json:"timestamp"` // 索引时记录的文件修改时间
+	Package   *FileElementPackage   `json:"package,omitempty"`
+	Imports   []*FileElementImport  `json:"imports"`
+	Elements  []*FileElementElement `json:"elements"`
+}
+
+// FileElementPackage 包信息
+type FileElementPackage struct {
+	Name  string  `json:"name"`
+	Range []int32 `json:"range"`
+}
+
+// FileElementImport 导入信息
+type FileElementImport struct {
+	Name   string  `json:"name"`
+	Source string  `json:"source,omitempty"`
+	Alias  string  `json:"alias,omitempty"`
+	Range  []int32 `json:"range"`
+}
+
+// FileElementElement 元素信息
+type FileElementElement struct {
+	Name         string         `json:"name"`
+	ElementType  string         `json:"elementType"`
+	IsDefinition bool           `json:"isDefinition"`
+	Range        []int32        `json:"range"`
+	ExtraData    map[string]any `json:"extraData,omitempty"`
+}
+
 const (
 	Embedding = "embedding"
 	Codegraph = "codegraph"
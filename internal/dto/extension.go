@@ -287,6 +287,14 @@ type TriggerIndexRequest struct {
 	// enum: codegraph,embedding,all
 	// example: codegraph
 	Type string `json:"type" binding:"required"`
+
+	// 本次代码图索引只包含匹配的文件，语法同 .gitignore，相对工作区根目录
+	// example: ["src/**/*.go"]
+	Include []string `json:"include,omitempty"`
+
+	// 本次代码图索引跳过匹配的文件或目录，语法同 .gitignore，相对工作区根目录
+	// example: ["**/testdata/**"]
+	Exclude []string `json:"exclude,omitempty"`
 }
 
 // TriggerIndexResponse represents the response for triggering index build
@@ -31,6 +31,17 @@ type UploadReq struct {
 	UploadToken  string `json:"uploadToken"`
 }
 
+// UploadChunkResp 分片上传响应，Offset 为服务端已确认接收的字节数
+type UploadChunkResp struct {
+	Code    int                 `json:"code"`
+	Message string              `json:"message"`
+	Data    UploadChunkRespData `json:"data"`
+}
+
+type UploadChunkRespData struct {
+	Offset int64 `json:"offset"`
+}
+
 // UploadTokenReq 获取上传令牌请求
 type UploadTokenReq struct {
 	ClientId     string `json:"clientId"`
@@ -9,13 +9,16 @@ import (
 var ErrUnSupportedLanguage = response.NewError("codebase-indexer.unsupported_language", "Unsupported Language")
 var ErrIndexDisabled = response.NewError("codebase-indexer.index_disabled", "index is disabled")
 var ErrRecordNotFound = errors.New("record not found")
+var ErrFileNotIndexed = errors.New("file not indexed")
+var ErrInvalidParam = errors.New("invalid request params")
+var ErrMissingParam = errors.New("missing required param")
 
-var errorInvalidParamFmt = "invalid request params: %s %v"
+var errorInvalidParamFmt = "%w: %s %v"
 var errorRecordNotFoundFmt = "%s not found by %s"
-var errorMissingParamFmt = "missing required param: %s"
+var errorMissingParamFmt = "%w: %s"
 
 func NewInvalidParamErr(name string, value interface{}) error {
-	return fmt.Errorf(errorInvalidParamFmt, name, value)
+	return fmt.Errorf(errorInvalidParamFmt, ErrInvalidParam, name, value)
 }
 
 func NewRecordNotFoundErr(name string, value interface{}) error {
@@ -23,5 +26,5 @@ func NewRecordNotFoundErr(name string, value interface{}) error {
 }
 
 func NewMissingParamError(name string) error {
-	return fmt.Errorf(errorMissingParamFmt, name)
+	return fmt.Errorf(errorMissingParamFmt, ErrMissingParam, name)
 }
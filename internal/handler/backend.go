@@ -2,16 +2,35 @@
 package handler
 
 import (
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/response"
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/errs"
 	"codebase-indexer/internal/service"
 	"codebase-indexer/pkg/logger"
 )
 
+// normalizeRequestPaths 解码编辑器传入的 file:// URI，相对路径由索引层基于工作区解析
+func normalizeRequestPaths(codebasePath, filePath *string) {
+	*codebasePath = utils.FileURIToPath(*codebasePath)
+	*filePath = utils.FileURIToPath(*filePath)
+}
+
+// withRequestId 为查询请求生成请求ID写入上下文，请求头带合法的 X-Request-ID 时沿用，并通过响应头返回，
+// 查询链路的日志都会带上该ID
+func withRequestId(c *gin.Context, ctx context.Context) context.Context {
+	id := logger.NormalizeRequestId(c.GetHeader(logger.RequestIdHeader))
+	c.Header(logger.RequestIdHeader, id)
+	return logger.WithRequestId(ctx, id)
+}
+
 // BackendHandler 实现BackendHandler接口的HTTP处理器
 type BackendHandler struct {
 	codebaseService service.CodebaseService
@@ -44,6 +63,11 @@ func NewBackendHandler(codebaseService service.CodebaseService, logger logger.Lo
 // @Param symbolName query string false "符号名"
 // @Param includeContent query bool false "是否需要返回代码内容"
 // @Param maxLayer query int false "最大图层数"
+// @Param maxLineLimit query int false "行范围最大跨度，默认200，最大5000"
+// @Param excludeTests query bool false "排除测试文件中的引用"
+// @Param sameFileOnly query bool false "只在filePath所在文件内查找引用，不遍历项目"
+// @Param offset query int false "分页时跳过的引用数"
+// @Param limit query int false "每页最多返回的引用数，不传时不分页"
 // @Success 200 {object} SearchRelationResponse "成功"
 // @Failure 400 {object} SearchRelationResponse "请求参数错误"
 // @Failure 500 {object} SearchRelationResponse "服务器内部错误"
@@ -56,9 +80,11 @@ func (h *BackendHandler) SearchReference(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("relation search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
+	normalizeRequestPaths(&req.CodebasePath, &req.FilePath)
+	ctx := withRequestId(c, c)
+	logger.ContextLogger(ctx, h.logger).Info("relation search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
 
-	relations, err := h.codebaseService.QueryReference(c, &req)
+	relations, err := h.codebaseService.QueryReference(ctx, &req)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, err)
 		return
@@ -66,6 +92,49 @@ func (h *BackendHandler) SearchReference(c *gin.Context) {
 	response.OkJson(c, relations)
 }
 
+// SearchReferenceStream 流式关系检索接口
+// @Summary 流式关系检索
+// @Description 参数同关系检索，以 NDJSON 逐行返回定义和引用，适用于引用数量很多的符号
+// @Tags search
+// @Accept json
+// @Produce application/x-ndjson
+// @Param clientId query string true "用户机器ID"
+// @Param codebasePath query string true "代码库绝对路径"
+// @Param filePath query string false "文件相对路径"
+// @Param startLine query int false "开始行号"
+// @Param endLine query int false "结束行号"
+// @Param symbolName query string false "符号名"
+// @Success 200 {object} dto.ReferenceStreamItem "成功，每行一个对象"
+// @Failure 400 {object} SearchRelationResponse "请求参数错误"
+// @Router /codebase-indexer/api/v1/search/reference/stream [get]
+func (h *BackendHandler) SearchReferenceStream(c *gin.Context) {
+	var req dto.SearchReferenceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	normalizeRequestPaths(&req.CodebasePath, &req.FilePath)
+	ctx := withRequestId(c, c.Request.Context())
+	logger.ContextLogger(ctx, h.logger).Info("relation stream search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
+
+	writer := response.NewNDJSONWriter(c)
+	err := h.codebaseService.StreamReference(ctx, &req, func(item *dto.ReferenceStreamItem) error {
+		return writer.Write(item)
+	})
+	if err == nil {
+		return
+	}
+	h.logger.Error("stream search reference err: %v", err)
+	if !writer.Started() {
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	// 已开始写出，以最后一行返回错误
+	_ = writer.Write(&dto.ReferenceStreamItem{Type: dto.ReferenceStreamTypeError, Message: err.Error()})
+}
+
 // SearchDefinition 获取代码文件范围的内容定义
 // @Summary 获取定义
 // @Description 获取一个代码文件范围的内容定义
@@ -76,8 +145,14 @@ func (h *BackendHandler) SearchReference(c *gin.Context) {
 // @Param codebasePath query string true "代码库绝对路径"
 // @Param filePath query string true "文件相对路径"
 // @Param startLine query int false "开始行号"
+// @Param startColumn query int false "开始列号"
 // @Param endLine query int false "结束行号"
+// @Param endColumn query int false "结束列号"
 // @Param codeSnippet query string false "代码片段"
+// @Param languageFallback query bool false "无法按扩展名推断语言时在所有语言中查找定义"
+// @Param resolution query string false "定义解析策略：relaxed（默认，导入不匹配时返回所有同名定义）、strict（只返回导入匹配的定义）、scoped（优先同文件、同目录）"
+// @Param includeDoc query bool false "返回定义的文档注释"
+// @Param localOnly query bool false "只返回当前文件内的定义"
 // @Success 200 {object} SearchDefinitionResponse "成功"
 // @Failure 400 {object} SearchDefinitionResponse "请求参数错误"
 // @Failure 500 {object} SearchDefinitionResponse "服务器内部错误"
@@ -90,11 +165,14 @@ func (h *BackendHandler) SearchDefinition(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("definition search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
+	normalizeRequestPaths(&req.CodebasePath, &req.FilePath)
+	ctx := withRequestId(c, c)
+	log := logger.ContextLogger(ctx, h.logger)
+	log.Info("definition search request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
 
-	definitions, err := h.codebaseService.QueryDefinition(c, &req)
+	definitions, err := h.codebaseService.QueryDefinition(ctx, &req)
 	if err != nil {
-		h.logger.Error("search definition err:%v", err)
+		log.Error("search definition err:%v", err)
 		response.Error(c, http.StatusBadRequest, err)
 		return
 	}
@@ -114,7 +192,15 @@ func (h *BackendHandler) SearchDefinition(c *gin.Context) {
 // @Param endLine query int false "结束行号，从1开始"
 // @Param symbolName query string false "符号名，比如函数名、类名等"
 // @Param maxLayer query int false "最大层数，默认最大10层"
-// @Success 200 {object} response.Response{data=dto.CallGraphData} "成功"
+// @Param maxLineLimit query int false "行范围最大跨度，默认1000，最大5000"
+// @Param pathPrefix query string false "只沿该目录下的调用者遍历，支持相对路径"
+// @Param detectCycles query bool false "标记并汇总调用图中的环"
+// @Param includeVariables query bool false "附加函数引用的变量、常量、字段定义作为叶子节点"
+// @Param maxNodes query int false "调用图节点总数上限，达到后停止展开并返回truncated，不超过服务端上限CALLGRAPH_MAX_NODES（默认5000）"
+// @Param timeoutMs query int false "构建调用图的耗时上限（毫秒），超时后停止展开并返回truncated"
+// @Param excludeTests query bool false "不沿测试文件中的调用者遍历"
+// @Param format query string false "返回格式：json（默认）、dot（Graphviz）、mermaid"
+// @Success 200 {object} response.Response{data=dto.CallGraphData} "成功，format 为 dot、mermaid 时返回对应格式的文本"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /codebase-indexer/api/v1/callgraph [get]
@@ -125,14 +211,30 @@ func (h *BackendHandler) SearchCallGraph(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, err)
 		return
 	}
-	h.logger.Info("search callgraph request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
-	callGraph, err := h.codebaseService.QueryCallGraph(c, &req)
+	switch req.Format {
+	case "", types.CallGraphFormatJSON, types.CallGraphFormatDOT, types.CallGraphFormatMermaid:
+	default:
+		response.Error(c, http.StatusBadRequest, errs.NewInvalidParamErr("format", req.Format))
+		return
+	}
+	normalizeRequestPaths(&req.CodebasePath, &req.FilePath)
+	ctx := withRequestId(c, c)
+	log := logger.ContextLogger(ctx, h.logger)
+	log.Info("search callgraph request: ClientId=%s, Workspace=%s, FilePath=%s", req.ClientId, req.CodebasePath, req.FilePath)
+	callGraph, err := h.codebaseService.QueryCallGraph(ctx, &req)
 	if err != nil {
-		h.logger.Error("search callgraph err:%v", err)
+		log.Error("search callgraph err:%v", err)
 		response.Error(c, http.StatusBadRequest, err)
 		return
 	}
-	response.OkJson(c, callGraph)
+	switch req.Format {
+	case types.CallGraphFormatDOT:
+		response.Text(c, "text/vnd.graphviz; charset=utf-8", types.FormatCallGraphDOT(callGraph.List))
+	case types.CallGraphFormatMermaid:
+		response.Text(c, "text/plain; charset=utf-8", types.FormatCallGraphMermaid(callGraph.List))
+	default:
+		response.OkJson(c, callGraph)
+	}
 }
 
 // GetFileContent 获取源文件内容接口
@@ -270,6 +372,55 @@ func (h *BackendHandler) GetIndexSummary(c *gin.Context) {
 	response.OkJson(c, summarize)
 }
 
+// ListWorkspaces 列出所有工作区的索引状态
+// @Summary 列出工作区索引状态
+// @Description 列出所有工作区的已索引文件数、最近索引时间、调用图是否已构建以及索引占用磁盘大小
+// @Tags index
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response "成功"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /codebase-indexer/api/v1/workspaces [get]
+func (h *BackendHandler) ListWorkspaces(c *gin.Context) {
+	statuses, err := h.codebaseService.ListIndexedWorkspaces(c)
+	if err != nil {
+		h.logger.Error("list indexed workspaces: %v", err)
+		response.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	response.OkJson(c, statuses)
+}
+
+// ListIndexMetrics 获取工作区索引任务指标历史
+// @Summary 获取索引任务指标历史
+// @Description 按开始时间倒序返回工作区最近的代码图全量索引任务指标：耗时、文件数、失败文件数、符号数，用于分析索引耗时和失败率的变化趋势
+// @Tags index
+// @Accept json
+// @Produce json
+// @Param clientId query string true "用户机器ID"
+// @Param codebasePath query string true "项目绝对路径"
+// @Param limit query int false "返回条数，默认20，最多100"
+// @Success 200 {object} response.Response "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /codebase-indexer/api/v1/index/metrics [get]
+func (h *BackendHandler) ListIndexMetrics(c *gin.Context) {
+	var req dto.ListIndexMetricsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	metrics, err := h.codebaseService.ListIndexMetrics(c, &req)
+	if err != nil {
+		h.logger.Error("list index metrics: %v", err)
+		response.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	response.OkJson(c, metrics)
+}
+
 func (h *BackendHandler) ExportIndex(c *gin.Context) {
 	var req dto.ExportIndexRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -303,6 +454,90 @@ func (h *BackendHandler) DeleteIndex(c *gin.Context) {
 	response.Ok(c)
 }
 
+// RebuildProject 重建单个项目的索引
+// @Summary 重建项目索引
+// @Description 提交重建任务，由事件队列异步清空工作区下指定项目的代码图索引后重新索引，不影响工作区中的其它项目，用于单个项目索引损坏的场景
+// @Tags index
+// @Accept json
+// @Produce json
+// @Param request body dto.RebuildProjectRequest true "重建项目索引请求"
+// @Success 202 {object} response.Response "已受理"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /codebase-indexer/api/v1/index/rebuild [post]
+func (h *BackendHandler) RebuildProject(c *gin.Context) {
+	var req dto.RebuildProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.codebaseService.RebuildProject(c, &req); err != nil {
+		h.logger.Error("rebuild project index err: %v", err)
+		response.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	response.Accepted(c)
+}
+
+// CompactIndex 压缩索引
+// @Summary 压缩索引
+// @Description 压缩代码图索引存储，回收文件重命名、删除后遗留的空间，不指定工作区时压缩所有项目
+// @Tags index
+// @Accept json
+// @Produce json
+// @Param request body dto.CompactIndexRequest true "压缩索引请求"
+// @Success 200 {object} response.Response{data=dto.CompactIndexData} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /codebase-indexer/api/v1/index/compact [post]
+func (h *BackendHandler) CompactIndex(c *gin.Context) {
+	var req dto.CompactIndexRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := h.codebaseService.CompactIndex(c, &req)
+	if err != nil {
+		h.logger.Error("compact index err: %v", err)
+		response.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
+// ReconcileIndex 核对索引文件数
+// @Summary 核对索引文件数
+// @Description 以索引存储为准核对数据库记录的代码图索引文件数，fix 为 true 时修正不一致的记录，不指定工作区时核对所有工作区。
+// @Description 进行中的索引完成后才核对
+// @Tags index
+// @Accept json
+// @Produce json
+// @Param request body dto.ReconcileIndexRequest true "核对索引文件数请求"
+// @Success 200 {object} response.Response{data=dto.ReconcileIndexData} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /codebase-indexer/api/v1/index/reconcile [post]
+func (h *BackendHandler) ReconcileIndex(c *gin.Context) {
+	var req dto.ReconcileIndexRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := h.codebaseService.ReconcileIndex(c, &req)
+	if err != nil {
+		h.logger.Error("reconcile index err: %v", err)
+		response.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	response.OkJson(c, data)
+}
+
 func (h *BackendHandler) ReadCodeSnippets(c *gin.Context) {
 	var req dto.ReadCodeSnippetsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -351,3 +586,71 @@ func (h *BackendHandler) GetFileSkeleton(c *gin.Context) {
 	}
 	response.OkJson(c, skeleton)
 }
+
+// GetFileElements 获取文件的原始元素表
+// @Summary 获取文件元素表
+// @Description 获取文件索引中解码后的元素表（元素名称、类型、范围、导入及索引时间戳），用于排查查询无结果的问题
+// @Tags index
+// @Accept json
+// @Produce json
+// @Param workspace query string true "工作区绝对路径"
+// @Param path query string true "文件路径，支持相对工作区的路径"
+// @Success 200 {object} response.Response{data=dto.FileElementsData} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /codebase-indexer/api/v1/index/file-elements [get]
+func (h *BackendHandler) GetFileElements(c *gin.Context) {
+	var req dto.GetFileElementsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	normalizeRequestPaths(&req.Workspace, &req.Path)
+
+	h.logger.Info("get file elements request: Workspace=%s, Path=%s", req.Workspace, req.Path)
+
+	elements, err := h.codebaseService.GetFileElements(c, &req)
+	if err != nil {
+		h.logger.Error("get file elements err: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, elements)
+}
+
+// GetFileOutline 获取文件大纲
+// @Summary 获取文件大纲
+// @Description 获取已索引文件的全部定义、引用、调用和导入及其位置（从1开始），用于文档符号/大纲视图
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param workspace query string true "工作区绝对路径"
+// @Param path query string true "文件路径，支持相对工作区的路径"
+// @Success 200 {object} response.Response{data=types.FileOutline} "成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "文件未索引"
+// @Router /codebase-indexer/api/v1/files/outline [get]
+func (h *BackendHandler) GetFileOutline(c *gin.Context) {
+	var req dto.GetFileOutlineRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("invalid request format: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	normalizeRequestPaths(&req.Workspace, &req.Path)
+
+	h.logger.Info("get file outline request: Workspace=%s, Path=%s", req.Workspace, req.Path)
+
+	outline, err := h.codebaseService.GetFileOutline(c, &req)
+	if errors.Is(err, errs.ErrFileNotIndexed) {
+		response.Error(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		h.logger.Error("get file outline err: %v", err)
+		response.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	response.OkJson(c, outline)
+}
@@ -0,0 +1,227 @@
+// internal/handler/codegraph_grpc.go - 代码图查询gRPC处理器
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/logger"
+)
+
+// CodegraphGRPCHandler 代码图查询的gRPC处理器，与 HTTP 接口共用同一套服务：
+// 索引通过事件队列异步执行，调用图查询与 HTTP 查询共用同一把锁
+type CodegraphGRPCHandler struct {
+	codebaseService  service.CodebaseService
+	extensionService service.ExtensionService
+	logger           logger.Logger
+	codegraphpb.UnimplementedCodegraphServiceServer
+}
+
+// NewCodegraphGRPCHandler 创建代码图查询的gRPC处理器
+func NewCodegraphGRPCHandler(codebaseService service.CodebaseService, extensionService service.ExtensionService,
+	logger logger.Logger) *CodegraphGRPCHandler {
+	return &CodegraphGRPCHandler{
+		codebaseService:  codebaseService,
+		extensionService: extensionService,
+		logger:           logger,
+	}
+}
+
+// grpcClientId 从请求元数据的 client-id 中取客户端ID，与 HTTP 接口的 Client-ID 头一致
+func grpcClientId(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("client-id"); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// toGRPCError 将服务层错误转换为gRPC状态，与 HTTP 接口一致区分客户端参数错误、未找到和服务端错误
+func toGRPCError(err error, action string) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, errs.ErrIndexDisabled):
+		code = codes.FailedPrecondition
+	case errors.Is(err, errs.ErrMissingParam), errors.Is(err, errs.ErrInvalidParam),
+		errors.Is(err, errs.ErrUnSupportedLanguage):
+		code = codes.InvalidArgument
+	case errors.Is(err, errs.ErrFileNotIndexed), errors.Is(err, errs.ErrRecordNotFound):
+		code = codes.NotFound
+	}
+	return status.Errorf(code, "%s: %v", action, err)
+}
+
+// withGRPCRequestId 与 HTTP 接口一致，从请求元数据的 x-request-id 中取请求ID，不存在或不合法时生成，写入上下文
+func withGRPCRequestId(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(logger.RequestIdHeader)); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	return logger.WithRequestId(ctx, logger.NormalizeRequestId(id))
+}
+
+// IndexWorkspace 提交工作区索引任务，与 HTTP 触发索引一致创建重建事件，由事件队列异步执行
+func (h *CodegraphGRPCHandler) IndexWorkspace(ctx context.Context, req *codegraphpb.IndexWorkspaceRequest) (*codegraphpb.IndexWorkspaceResponse, error) {
+	if req.Workspace == "" {
+		return nil, status.Error(codes.InvalidArgument, "workspace is required")
+	}
+	ctx = withGRPCRequestId(ctx)
+	workspacePath := utils.FileURIToPath(req.Workspace)
+	if err := h.extensionService.TriggerIndex(ctx, workspacePath, dto.IndexTypeCodegraph, grpcClientId(ctx), nil); err != nil {
+		logger.ContextLogger(ctx, h.logger).Error("grpc trigger index for workspace %s failed: %v", workspacePath, err)
+		return nil, toGRPCError(err, "trigger index")
+	}
+	return &codegraphpb.IndexWorkspaceResponse{Accepted: true}, nil
+}
+
+// QueryDefinitions 查询定义
+func (h *CodegraphGRPCHandler) QueryDefinitions(ctx context.Context, req *codegraphpb.QueryDefinitionsRequest) (*codegraphpb.QueryDefinitionsResponse, error) {
+	if req.Workspace == "" {
+		return nil, status.Error(codes.InvalidArgument, "workspace is required")
+	}
+	ctx = withGRPCRequestId(ctx)
+	data, err := h.codebaseService.QueryDefinition(ctx, &dto.SearchDefinitionRequest{
+		ClientId:     grpcClientId(ctx),
+		CodebasePath: utils.FileURIToPath(req.Workspace),
+		FilePath:     utils.FileURIToPath(req.FilePath),
+		StartLine:    int(req.StartLine),
+		EndLine:      int(req.EndLine),
+		SymbolNames:  req.SymbolNames,
+		CodeSnippet:  req.CodeSnippet,
+		ProjectUuid:  req.ProjectUuid,
+	})
+	if err != nil {
+		logger.ContextLogger(ctx, h.logger).Error("grpc query definitions failed: %v", err)
+		return nil, toGRPCError(err, "query definitions")
+	}
+	resp := &codegraphpb.QueryDefinitionsResponse{
+		Definitions: make([]*codegraphpb.Definition, 0, len(data.List)),
+	}
+	for _, d := range data.List {
+		resp.Definitions = append(resp.Definitions, &codegraphpb.Definition{
+			Name:  d.Name,
+			Type:  d.Type,
+			Path:  d.FilePath,
+			Range: toPBRange(d.Position),
+			Score: int32(d.Score),
+		})
+	}
+	return resp, nil
+}
+
+// QueryReferences 流式查询引用，每找到一个定义或引用即发送一条消息
+func (h *CodegraphGRPCHandler) QueryReferences(req *codegraphpb.QueryReferencesRequest, stream grpc.ServerStreamingServer[codegraphpb.ReferenceItem]) error {
+	if req.Workspace == "" {
+		return status.Error(codes.InvalidArgument, "workspace is required")
+	}
+	ctx := withGRPCRequestId(stream.Context())
+	err := h.codebaseService.StreamReference(ctx, &dto.SearchReferenceRequest{
+		ClientId:     grpcClientId(ctx),
+		CodebasePath: utils.FileURIToPath(req.Workspace),
+		FilePath:     utils.FileURIToPath(req.FilePath),
+		StartLine:    int(req.StartLine),
+		EndLine:      int(req.EndLine),
+		SymbolName:   req.SymbolName,
+		ProjectUuid:  req.ProjectUuid,
+	}, func(item *dto.ReferenceStreamItem) error {
+		return stream.Send(&codegraphpb.ReferenceItem{
+			Type:            item.Type,
+			DefinitionIndex: int32(item.DefinitionIndex),
+			Node:            toPBRelationNode(item.Node, false),
+		})
+	})
+	if err != nil {
+		logger.ContextLogger(ctx, h.logger).Error("grpc query references failed: %v", err)
+		return toGRPCError(err, "query references")
+	}
+	return nil
+}
+
+// QueryCallGraph 流式查询调用图，每个根节点连同其调用者作为一条消息发送
+func (h *CodegraphGRPCHandler) QueryCallGraph(req *codegraphpb.QueryCallGraphRequest, stream grpc.ServerStreamingServer[codegraphpb.RelationNode]) error {
+	if req.Workspace == "" {
+		return status.Error(codes.InvalidArgument, "workspace is required")
+	}
+	if req.FilePath == "" {
+		return status.Error(codes.InvalidArgument, "filePath is required")
+	}
+	ctx := withGRPCRequestId(stream.Context())
+	data, err := h.codebaseService.QueryCallGraph(ctx, &dto.SearchCallGraphRequest{
+		ClientId:         grpcClientId(ctx),
+		CodebasePath:     utils.FileURIToPath(req.Workspace),
+		FilePath:         utils.FileURIToPath(req.FilePath),
+		LineRange:        req.LineRange,
+		SymbolName:       req.SymbolName,
+		MaxLayer:         int(req.MaxLayer),
+		ProjectUuid:      req.ProjectUuid,
+		PathPrefix:       utils.FileURIToPath(req.PathPrefix),
+		DetectCycles:     req.DetectCycles,
+		IncludeVariables: req.IncludeVariables,
+		MaxNodes:         int(req.MaxNodes),
+		TimeoutMs:        int(req.TimeoutMs),
+		ExcludeTests:     req.ExcludeTests,
+	})
+	if err != nil {
+		logger.ContextLogger(ctx, h.logger).Error("grpc query call graph failed: %v", err)
+		return toGRPCError(err, "query call graph")
+	}
+	for _, node := range data.List {
+		if err := stream.Send(toPBRelationNode(node, true)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toPBRange 将从1开始的位置转换为从0开始的范围，位置为空时返回 nil
+func toPBRange(position dto.Position) []int32 {
+	if position == (dto.Position{}) {
+		return nil
+	}
+	return []int32{int32(position.StartLine - 1), int32(position.StartColumn - 1),
+		int32(position.EndLine - 1), int32(position.EndColumn - 1)}
+}
+
+// toPBRelationNode 转换关系节点，withChildren 为 true 时递归转换子节点
+func toPBRelationNode(node *types.RelationNode, withChildren bool) *codegraphpb.RelationNode {
+	if node == nil {
+		return nil
+	}
+	pbNode := &codegraphpb.RelationNode{
+		SymbolName: node.SymbolName,
+		FilePath:   node.FilePath,
+		NodeType:   node.NodeType,
+		Cyclic:     node.Cyclic,
+		Variable:   node.Variable,
+		Truncated:  node.Truncated,
+	}
+	if node.Position != nil {
+		pbNode.Position = &codegraphpb.Position{
+			StartLine:   int32(node.Position.StartLine),
+			StartColumn: int32(node.Position.StartColumn),
+			EndLine:     int32(node.Position.EndLine),
+			EndColumn:   int32(node.Position.EndColumn),
+		}
+	}
+	if withChildren {
+		for _, child := range node.Children {
+			pbNode.Children = append(pbNode.Children, toPBRelationNode(child, true))
+		}
+	}
+	return pbNode
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/codegraph/definition"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
+)
+
+// newTestCodegraphClient 在内存连接上启动代码图gRPC服务，返回客户端
+func newTestCodegraphClient(t *testing.T, workspaceDir string) codegraphpb.CodegraphServiceClient {
+	t.Helper()
+	appLogger, err := logger.NewLogger(t.TempDir(), "error", "handler-test")
+	require.NoError(t, err)
+	indexer := newTestCodegraphIndexer(t, workspaceDir, appLogger)
+	manager, err := repository.NewStorageManager(t.TempDir(), appLogger)
+	require.NoError(t, err)
+	codebaseService := service.NewCodebaseService(manager, appLogger, workspace.NewWorkSpaceReader(appLogger),
+		nil, nil, nil, definition.NewDefinitionParser(), indexer)
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	codegraphpb.RegisterCodegraphServiceServer(s,
+		NewCodegraphGRPCHandler(codebaseService, &syncIndexExtensionService{indexer: indexer}, appLogger))
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return codegraphpb.NewCodegraphServiceClient(conn)
+}
+
+func TestCodegraphGRPCHandler(t *testing.T) {
+	workspaceDir := t.TempDir()
+	source := "package main\n\nfunc Target() {}\n\nfunc Caller() {\n\tTarget()\n}\n\nfunc main() {\n\tCaller()\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, "main.go"), []byte(source), 0644))
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	// 测试文件中的调用者，调用图查询时排除
+	require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, "main_test.go"),
+		[]byte("package main\n\nfunc TestTarget() {\n\tTarget()\n}\n"), 0644))
+
+	client := newTestCodegraphClient(t, workspaceDir)
+	ctx := context.Background()
+
+	resp, err := client.IndexWorkspace(ctx, &codegraphpb.IndexWorkspaceRequest{Workspace: workspaceDir})
+	require.NoError(t, err)
+	assert.True(t, resp.Accepted)
+
+	t.Run("查询定义", func(t *testing.T) {
+		resp, err := client.QueryDefinitions(ctx, &codegraphpb.QueryDefinitionsRequest{
+			Workspace:   workspaceDir,
+			SymbolNames: "Target",
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Definitions, 1)
+		assert.Equal(t, "Target", resp.Definitions[0].Name)
+		assert.Equal(t, mainFile, resp.Definitions[0].Path)
+	})
+
+	t.Run("流式查询引用", func(t *testing.T) {
+		stream, err := client.QueryReferences(ctx, &codegraphpb.QueryReferencesRequest{
+			Workspace:  workspaceDir,
+			FilePath:   mainFile,
+			StartLine:  3,
+			EndLine:    3,
+			SymbolName: "Target",
+		})
+		require.NoError(t, err)
+		var items []*codegraphpb.ReferenceItem
+		for {
+			item, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			items = append(items, item)
+		}
+		require.Len(t, items, 3)
+		assert.Equal(t, "definition", items[0].Type)
+		assert.Equal(t, "Target", items[0].Node.SymbolName)
+		assert.Equal(t, "reference", items[1].Type)
+		assert.Equal(t, int32(0), items[1].DefinitionIndex)
+		assert.Equal(t, int32(6), items[1].Node.Position.StartLine)
+		assert.Equal(t, "reference", items[2].Type)
+		assert.Equal(t, filepath.Join(workspaceDir, "main_test.go"), items[2].Node.FilePath)
+	})
+
+	t.Run("流式查询调用图", func(t *testing.T) {
+		stream, err := client.QueryCallGraph(ctx, &codegraphpb.QueryCallGraphRequest{
+			Workspace:    workspaceDir,
+			FilePath:     mainFile,
+			SymbolName:   "Target",
+			MaxLayer:     5,
+			ExcludeTests: true,
+		})
+		require.NoError(t, err)
+		var roots []*codegraphpb.RelationNode
+		for {
+			node, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			roots = append(roots, node)
+		}
+		require.Len(t, roots, 1)
+		assert.Equal(t, "Target", roots[0].SymbolName)
+		require.Len(t, roots[0].Children, 1)
+		assert.Equal(t, "Caller", roots[0].Children[0].SymbolName)
+		require.Len(t, roots[0].Children[0].Children, 1)
+		assert.Equal(t, "main", roots[0].Children[0].Children[0].SymbolName)
+	})
+
+	t.Run("缺少工作区", func(t *testing.T) {
+		_, err := client.QueryDefinitions(ctx, &codegraphpb.QueryDefinitionsRequest{SymbolNames: "Target"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestToGRPCError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "索引被禁用", err: errs.ErrIndexDisabled, want: codes.FailedPrecondition},
+		{name: "缺少参数", err: errs.NewMissingParamError("workspace"), want: codes.InvalidArgument},
+		{name: "参数不合法", err: errs.NewInvalidParamErr("resolution", "loose"), want: codes.InvalidArgument},
+		{name: "不支持的语言", err: errs.ErrUnSupportedLanguage, want: codes.InvalidArgument},
+		{name: "文件未索引", err: fmt.Errorf("failed to get file element table: %w", errs.ErrFileNotIndexed), want: codes.NotFound},
+		{name: "记录不存在", err: errs.ErrRecordNotFound, want: codes.NotFound},
+		{name: "其他错误为服务端错误", err: errors.New("storage unavailable"), want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, status.Code(toGRPCError(tt.err, "query definitions")))
+		})
+	}
+}
+
+func TestWithGRPCRequestId(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantKeep bool
+	}{
+		{name: "沿用合法的请求ID", id: "1a2b3c4d", wantKeep: true},
+		{name: "缺少请求ID时生成"},
+		{name: "替换包含控制字符的请求ID", id: "abc\r\n[req:forged]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.id != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", tt.id))
+			}
+			id := logger.RequestIdFromContext(withGRPCRequestId(ctx))
+			if tt.wantKeep {
+				assert.Equal(t, tt.id, id)
+				return
+			}
+			assert.NotEqual(t, tt.id, id)
+			assert.True(t, logger.IsValidRequestId(id))
+		})
+	}
+}
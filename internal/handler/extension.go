@@ -13,6 +13,7 @@ import (
 	"codebase-indexer/internal/dto"
 	"codebase-indexer/internal/errs"
 	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/logger"
 )
 
@@ -430,11 +431,24 @@ func (h *ExtensionHandler) TriggerIndex(c *gin.Context) {
 		return
 	}
 
+	filter := &types.IndexFilter{Include: req.Include, Exclude: req.Exclude}
+	if err := filter.Validate(); err != nil {
+		h.logger.Error("invalid index filter: %v", err)
+		c.JSON(http.StatusBadRequest, dto.TriggerIndexResponse{
+			Code:    errs.ErrBadRequest,
+			Success: false,
+			Message: fmt.Sprintf("invalid index filter: %v", err),
+			Data:    0,
+		})
+		return
+	}
+
 	clientId := c.GetHeader("Client-ID")
-	h.logger.Info("trigger index request: Workspace=%s, Type=%s, ClientID=%s", req.Workspace, req.Type, clientId)
+	h.logger.Info("trigger index request: Workspace=%s, Type=%s, ClientID=%s, Include=%v, Exclude=%v",
+		req.Workspace, req.Type, clientId, req.Include, req.Exclude)
 
 	// 调用service层处理业务逻辑
-	err := h.extensionService.TriggerIndex(c.Request.Context(), req.Workspace, req.Type, clientId)
+	err := h.extensionService.TriggerIndex(c.Request.Context(), req.Workspace, req.Type, clientId, filter)
 	if err != nil {
 		h.logger.Error("failed to trigger index: %v", err)
 		c.JSON(http.StatusInternalServerError, dto.TriggerIndexResponse{
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/model"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/codegraph/analyzer"
+	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
+	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
+	"codebase-indexer/test/mocks"
+)
+
+// syncIndexExtensionService 同步执行代码图索引的扩展服务，代替事件处理流程
+type syncIndexExtensionService struct {
+	service.ExtensionService
+	indexer service.Indexer
+	calls   int
+}
+
+func (s *syncIndexExtensionService) TriggerIndex(ctx context.Context, workspacePath, indexType, clientID string, filter *types.IndexFilter) error {
+	s.calls++
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	_, err := s.indexer.IndexWorkspace(types.WithIndexFilter(ctx, filter), workspacePath)
+	return err
+}
+
+func newTestCodegraphIndexer(t *testing.T, workspaceDir string, appLogger logger.Logger) service.Indexer {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	storage, err := store.NewLevelDBStorage(t.TempDir(), appLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	workspaceRepo := mocks.NewMockWorkspaceRepository(ctrl)
+	workspaceRepo.EXPECT().GetWorkspaceByPath(workspaceDir).
+		Return(&model.Workspace{WorkspacePath: workspaceDir, Active: "true"}, nil).AnyTimes()
+	workspaceRepo.EXPECT().UpdateCodegraphInfo(workspaceDir, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	workspaceReader := workspace.NewWorkSpaceReader(appLogger)
+	return service.NewCodeIndexer(
+		repository.NewFileScanner(appLogger),
+		parser.NewSourceFileParser(appLogger),
+		analyzer.NewDependencyAnalyzer(appLogger, packageclassifier.NewPackageClassifier(), workspaceReader, storage),
+		workspaceReader,
+		storage,
+		workspaceRepo,
+		service.IndexerConfig{MaxConcurrency: 1, MaxBatchSize: 10},
+		appLogger,
+	)
+}
+
+func TestExtensionHandler_TriggerIndexWithFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"main.go":                  "package main\n\nfunc main() {}\n",
+		"pkg/util.go":              "package pkg\n\nfunc Util() {}\n",
+		"pkg/testdata/fixture.go":  "package testdata\n\nfunc Fixture() {}\n",
+		"testdata/golden/input.go": "package golden\n\nfunc Input() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	tests := []struct {
+		name        string
+		include     []string
+		exclude     []string
+		wantStatus  int
+		wantIndexed []string
+		wantSkipped []string
+	}{
+		{
+			name:        "排除testdata目录",
+			exclude:     []string{"**/testdata/**"},
+			wantStatus:  http.StatusOK,
+			wantIndexed: []string{"main.go", "pkg/util.go"},
+			wantSkipped: []string{"pkg/testdata/fixture.go", "testdata/golden/input.go"},
+		},
+		{
+			name:        "只包含pkg目录",
+			include:     []string{"pkg/**/*.go"},
+			exclude:     []string{"**/testdata/**"},
+			wantStatus:  http.StatusOK,
+			wantIndexed: []string{"pkg/util.go"},
+			wantSkipped: []string{"main.go", "pkg/testdata/fixture.go", "testdata/golden/input.go"},
+		},
+		{
+			name:       "非法规则",
+			exclude:    []string{"src/[a-"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appLogger, err := logger.NewLogger(t.TempDir(), "error", "handler-test")
+			require.NoError(t, err)
+			indexer := newTestCodegraphIndexer(t, workspaceDir, appLogger)
+			extensionService := &syncIndexExtensionService{indexer: indexer}
+			h := NewExtensionHandler(extensionService, appLogger)
+			router := gin.New()
+			router.POST("/index", h.TriggerIndex)
+
+			body, err := json.Marshal(dto.TriggerIndexRequest{
+				Workspace: workspaceDir,
+				Type:      dto.IndexTypeCodegraph,
+				Include:   tt.include,
+				Exclude:   tt.exclude,
+			})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/index", bytes.NewReader(body)))
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				assert.Equal(t, 0, extensionService.calls)
+				return
+			}
+
+			ctx := context.Background()
+			for _, name := range tt.wantIndexed {
+				_, err := indexer.GetFileElementTable(ctx, workspaceDir, filepath.Join(workspaceDir, name))
+				assert.NoError(t, err, name)
+			}
+			for _, name := range tt.wantSkipped {
+				_, err := indexer.GetFileElementTable(ctx, workspaceDir, filepath.Join(workspaceDir, name))
+				assert.Error(t, err, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/pkg/logger"
+)
+
+// defaultWatchRefreshInterval 刷新活跃工作区监听的间隔
+const defaultWatchRefreshInterval = 30 * time.Second
+
+// FileWatchJob 基于文件系统监听的变更检测任务，监听活跃工作区，变更经去抖后写入事件表；
+// 是否运行由 config.JobConfig 决定（默认关闭），FileScanJob 的定时全量扫描继续作为兜底
+type FileWatchJob struct {
+	scanner         service.FileScanService
+	fileScanner     repository.ScannerInterface
+	storage         repository.StorageInterface
+	logger          logger.Logger
+	debounce        time.Duration
+	refreshInterval time.Duration
+}
+
+// NewFileWatchJob 创建文件监听任务
+func NewFileWatchJob(
+	scanner service.FileScanService,
+	fileScanner repository.ScannerInterface,
+	storage repository.StorageInterface,
+	logger logger.Logger,
+	debounce time.Duration,
+) *FileWatchJob {
+	return &FileWatchJob{
+		scanner:         scanner,
+		fileScanner:     fileScanner,
+		storage:         storage,
+		logger:          logger,
+		debounce:        debounce,
+		refreshInterval: defaultWatchRefreshInterval,
+	}
+}
+
+// Start 启动文件监听任务，daemon 关闭（ctx 结束）时停止监听
+func (j *FileWatchJob) Start(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			j.logger.Error("recovered from panic in file watch job: %v", r)
+		}
+	}()
+	watcher, err := service.NewWorkspaceWatcher(j.scanner, j.fileScanner, j.logger, j.debounce)
+	if err != nil {
+		// 监听不可用时依赖 FileScanJob 的定时扫描
+		j.logger.Warn("failed to create file watcher, fall back to periodic scan: %v", err)
+		return
+	}
+	j.logger.Info("file watch job started with debounce: %v", j.debounce)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.Run(ctx, j.enabled)
+	}()
+
+	j.refreshWorkspaces(ctx, watcher)
+	refreshTicker := time.NewTicker(j.refreshInterval)
+	defer refreshTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			<-done
+			j.logger.Info("file watch job stopped")
+			return
+		case <-done:
+			j.logger.Warn("file watcher stopped unexpectedly")
+			return
+		case <-refreshTicker.C:
+			j.refreshWorkspaces(ctx, watcher)
+		}
+	}
+}
+
+// refreshWorkspaces 同步活跃工作区的监听，新增工作区建立监听，失活工作区取消监听
+func (j *FileWatchJob) refreshWorkspaces(ctx context.Context, watcher *service.WorkspaceWatcher) {
+	workspaces, err := j.scanner.ScanActiveWorkspaces()
+	if err != nil {
+		j.logger.Error("file watch job failed to scan active workspaces: %v", err)
+		return
+	}
+	active := make(map[string]struct{}, len(workspaces))
+	for _, ws := range workspaces {
+		active[ws.WorkspacePath] = struct{}{}
+	}
+
+	for _, workspacePath := range watcher.Workspaces() {
+		if _, ok := active[workspacePath]; !ok {
+			watcher.UnwatchWorkspace(workspacePath)
+		}
+	}
+	for workspacePath := range active {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := watcher.WatchWorkspace(workspacePath); err != nil {
+			j.logger.Warn("failed to watch workspace %s, fall back to polling: %v", workspacePath, err)
+		}
+	}
+}
+
+// enabled 与 FileScanJob 保持一致，codebase 关闭或未登录时不生成事件
+func (j *FileWatchJob) enabled() bool {
+	authInfo := config.GetAuthInfo()
+	if authInfo.ClientId == "" || authInfo.Token == "" || authInfo.ServerURL == "" {
+		j.logger.Debug("auth info is nil, skip file watch job")
+		return false
+	}
+	codebaseEnv := j.storage.GetCodebaseEnv()
+	if codebaseEnv != nil && codebaseEnv.Switch == dto.SwitchOff {
+		j.logger.Debug("codebase is disabled, skip file watch job")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"codebase-indexer/internal/model"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/internal/service"
+	"codebase-indexer/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// activeWorkspacesScanService 返回指定的活跃工作区
+type activeWorkspacesScanService struct {
+	service.FileScanService
+	workspaces []*model.Workspace
+}
+
+func (s *activeWorkspacesScanService) ScanActiveWorkspaces() ([]*model.Workspace, error) {
+	return s.workspaces, nil
+}
+
+func TestFileWatchJob_RefreshWorkspaces(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	workspaceA, workspaceB := t.TempDir(), t.TempDir()
+	scanService := &activeWorkspacesScanService{}
+	fileScanner := repository.NewFileScanner(logger)
+	job := NewFileWatchJob(scanService, fileScanner, nil, logger, 50*time.Millisecond)
+	watcher, err := service.NewWorkspaceWatcher(scanService, fileScanner, logger, 50*time.Millisecond)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.Run(ctx, func() bool { return false })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	tests := []struct {
+		name   string
+		active []string
+		want   []string
+	}{
+		{name: "新增活跃工作区建立监听", active: []string{workspaceA, workspaceB}, want: []string{workspaceA, workspaceB}},
+		{name: "失活工作区取消监听", active: []string{workspaceB}, want: []string{workspaceB}},
+		{name: "没有活跃工作区", active: nil, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanService.workspaces = nil
+			for _, workspacePath := range tt.active {
+				scanService.workspaces = append(scanService.workspaces, &model.Workspace{WorkspacePath: workspacePath})
+			}
+			job.refreshWorkspaces(ctx, watcher)
+			assert.ElementsMatch(t, tt.want, watcher.Workspaces())
+		})
+	}
+}
@@ -18,6 +18,18 @@ import (
 const defaultCleanInterval = 60 * time.Minute
 const defaultExpiryPeriod = 3 * 24 * time.Hour
 
+// defaultCompactMinDeletes 项目上次压缩以来删除的 key 数达到该值时，在清理任务中压缩索引
+const defaultCompactMinDeletes = 10000
+
+// ReconcileMode 启动时 SQLite 与 LevelDB 索引文件数不一致的处理方式
+type ReconcileMode string
+
+const (
+	ReconcileModeFix    ReconcileMode = "fix"    // 以 LevelDB 为准修正 SQLite（默认）
+	ReconcileModeReport ReconcileMode = "report" // 只记录日志，不修正
+	ReconcileModeOff    ReconcileMode = "off"    // 不核对
+)
+
 type IndexCleanJob struct {
 	logger                logger.Logger
 	indexer               service.Indexer
@@ -29,6 +41,8 @@ type IndexCleanJob struct {
 	checkInterval         time.Duration
 	expiryPeriod          time.Duration
 	embeddingExpiryPeriod time.Duration
+	reconcileMode         ReconcileMode
+	compactMinDeletes     int64
 }
 
 func NewIndexCleanJob(logger logger.Logger, indexer service.Indexer,
@@ -70,6 +84,21 @@ func NewIndexCleanJob(logger logger.Logger, indexer service.Indexer,
 		embeddingExpiryPeriod = 7 * 24 * time.Hour // 默认 7 天
 	}
 
+	reconcileMode := ReconcileModeFix
+	if env, ok := os.LookupEnv("CODEGRAPH_RECONCILE_MODE"); ok {
+		switch mode := ReconcileMode(env); mode {
+		case ReconcileModeFix, ReconcileModeReport, ReconcileModeOff:
+			reconcileMode = mode
+		}
+	}
+
+	var compactMinDeletes int64 = defaultCompactMinDeletes
+	if env, ok := os.LookupEnv("INDEX_COMPACT_MIN_DELETES"); ok {
+		if val, err := strconv.ParseInt(env, 10, 64); err == nil && val > 0 {
+			compactMinDeletes = val
+		}
+	}
+
 	return &IndexCleanJob{
 		logger:                logger,
 		indexer:               indexer,
@@ -81,6 +110,8 @@ func NewIndexCleanJob(logger logger.Logger, indexer service.Indexer,
 		checkInterval:         checkInterval,
 		expiryPeriod:          expiryPeriod,
 		embeddingExpiryPeriod: embeddingExpiryPeriod,
+		reconcileMode:         reconcileMode,
+		compactMinDeletes:     compactMinDeletes,
 	}
 }
 
@@ -88,6 +119,11 @@ func (j *IndexCleanJob) Start(ctx context.Context) {
 	j.logger.Info("starting index clean job with checkInterval %.0f minutes, expiry period %.0f hours",
 		j.checkInterval.Minutes(), j.expiryPeriod.Hours())
 
+	// 启动时核对 SQLite 与 LevelDB 的索引文件数，工作区正在索引时等待其完成后再核对
+	if j.reconcileMode != ReconcileModeOff {
+		go j.reconcileCodegraphFileNums(ctx)
+	}
+
 	// 原有的清理过期工作区索引的协程
 	go func() {
 		ticker := time.NewTicker(j.checkInterval)
@@ -100,6 +136,8 @@ func (j *IndexCleanJob) Start(ctx context.Context) {
 				return
 			case <-ticker.C:
 				j.cleanupExpiredWorkspaceIndexes(ctx)
+				j.cleanupOrphanedProjectIndexes(ctx)
+				j.compactProjectIndexes(ctx)
 			}
 		}
 	}()
@@ -189,6 +227,79 @@ func (j *IndexCleanJob) cleanupExpiredWorkspaceIndexes(ctx context.Context) {
 	j.logger.Info("clean up expired workspace indexes end.")
 }
 
+// cleanupOrphanedProjectIndexes 回收工作区记录已删除或源码目录已不存在的项目索引，避免 LevelDB 累积无用数据
+func (j *IndexCleanJob) cleanupOrphanedProjectIndexes(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			j.logger.Error("recovered from panic in orphaned index clean job: %v", r)
+		}
+	}()
+
+	workspaces, err := j.workspaceRepo.ListWorkspaces()
+	if err != nil {
+		j.logger.Warn("list workspaces failed with %v", err)
+		return
+	}
+	workspacePaths := make([]string, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		workspacePaths = append(workspacePaths, workspace.WorkspacePath)
+	}
+
+	cleaned, err := j.indexer.CleanOrphanedProjectIndexes(ctx, workspacePaths)
+	if err != nil {
+		j.logger.Error("clean up orphaned project indexes failed with %v", err)
+	}
+	j.logger.Info("clean up orphaned project indexes end, reclaimed %d projects.", len(cleaned))
+}
+
+// compactProjectIndexes 压缩删除较多的项目索引，回收文件重命名、删除后遗留的空间
+func (j *IndexCleanJob) compactProjectIndexes(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			j.logger.Error("recovered from panic in index compact job: %v", r)
+		}
+	}()
+
+	compacted, err := j.indexer.CompactIndexes(ctx, "", j.compactMinDeletes)
+	if err != nil {
+		j.logger.Error("compact project indexes failed with %v", err)
+	}
+	j.logger.Info("compact project indexes end, compacted %d projects.", len(compacted))
+}
+
+// reconcileCodegraphFileNums 核对所有工作区记录的索引文件数，按配置决定是否修正
+func (j *IndexCleanJob) reconcileCodegraphFileNums(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			j.logger.Error("recovered from panic in codegraph reconcile: %v", r)
+		}
+	}()
+
+	workspaces, err := j.workspaceRepo.ListWorkspaces()
+	if err != nil {
+		j.logger.Warn("list workspaces failed with %v", err)
+		return
+	}
+
+	fix := j.reconcileMode == ReconcileModeFix
+	outOfSync := 0
+	for _, workspace := range workspaces {
+		if ctx.Err() != nil {
+			return
+		}
+		result, err := j.indexer.ReconcileCodegraphFileNum(ctx, workspace.WorkspacePath, fix)
+		if err != nil {
+			j.logger.Error("reconcile workspace %s codegraph file num failed with %v", workspace.WorkspacePath, err)
+			continue
+		}
+		if result.DatabaseFileNum != result.IndexFileNum {
+			outOfSync++
+		}
+	}
+	j.logger.Info("reconcile codegraph file num end, mode %s, workspaces %d, out of sync %d.",
+		j.reconcileMode, len(workspaces), outOfSync)
+}
+
 // cleanupInactiveWorkspaceEmbeddings 清理非活跃工作区的 embedding 索引
 func (j *IndexCleanJob) cleanupInactiveWorkspaceEmbeddings(ctx context.Context) {
 	defer func() {
@@ -46,6 +46,7 @@ const (
 	EventTypeOpenWorkspace    = "open_workspace"    // 打开工作区事件
 	EventTypeCloseWorkspace   = "close_workspace"   // 关闭工作区事件
 	EventTypeRebuildWorkspace = "rebuild_workspace" // 重新构建工作区事件
+	EventTypeRebuildProject   = "rebuild_project"   // 重新构建单个项目事件，SourceFilePath 为项目根目录
 )
 
 const True = "true"
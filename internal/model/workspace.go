@@ -56,3 +56,20 @@ type CodegraphState struct {
 	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt     time.Time `json:"updatedAt" db:"updated_at"`
 }
+
+// IndexMetrics 代码图索引任务指标数据模型，每次全量索引记录一条
+type IndexMetrics struct {
+	ID             int64     `json:"id" db:"id"`
+	WorkspacePath  string    `json:"workspacePath" db:"workspace_path"`
+	StartTs        int64     `json:"startTs" db:"start_ts"`
+	DurationMs     int64     `json:"durationMs" db:"duration_ms"`
+	TotalFiles     int       `json:"totalFiles" db:"total_files"`
+	FailedFiles    int       `json:"failedFiles" db:"failed_files"`
+	SkippedFiles   int       `json:"skippedFiles" db:"skipped_files"`
+	TotalSymbols   int       `json:"totalSymbols" db:"total_symbols"`
+	SavedSymbols   int       `json:"savedSymbols" db:"saved_symbols"`
+	TotalVariables int       `json:"totalVariables" db:"total_variables"`
+	SavedVariables int       `json:"savedVariables" db:"saved_variables"`
+	ErrorMessage   string    `json:"errorMessage" db:"error_message"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"codebase-indexer/internal/database"
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/logger"
+)
+
+// MaxIndexMetricsPerWorkspace 每个工作区保留的索引任务指标条数，超出后删除最早的记录
+const MaxIndexMetricsPerWorkspace = 100
+
+// MetricsRepository 索引任务指标数据访问层
+type MetricsRepository interface {
+	// InsertIndexMetrics 记录一次索引任务指标，只保留工作区最近 MaxIndexMetricsPerWorkspace 条
+	InsertIndexMetrics(metrics *model.IndexMetrics) error
+	// ListIndexMetricsByWorkspace 按开始时间倒序列出工作区最近 limit 条索引任务指标，limit<=0 时返回全部
+	ListIndexMetricsByWorkspace(workspacePath string, limit int) ([]*model.IndexMetrics, error)
+}
+
+// metricsRepository 索引任务指标Repository实现
+type metricsRepository struct {
+	db     database.DatabaseManager
+	logger logger.Logger
+}
+
+// NewMetricsRepository 创建索引任务指标Repository
+func NewMetricsRepository(db database.DatabaseManager, logger logger.Logger) MetricsRepository {
+	return &metricsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// InsertIndexMetrics 记录一次索引任务指标
+func (r *metricsRepository) InsertIndexMetrics(metrics *model.IndexMetrics) error {
+	query := `
+		INSERT INTO index_metrics (workspace_path, start_ts, duration_ms, total_files, failed_files,
+			skipped_files, total_symbols, saved_symbols, total_variables, saved_variables, error_message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.GetDB().Exec(query,
+		metrics.WorkspacePath,
+		metrics.StartTs,
+		metrics.DurationMs,
+		metrics.TotalFiles,
+		metrics.FailedFiles,
+		metrics.SkippedFiles,
+		metrics.TotalSymbols,
+		metrics.SavedSymbols,
+		metrics.TotalVariables,
+		metrics.SavedVariables,
+		metrics.ErrorMessage,
+	)
+	if err != nil {
+		return fmt.Errorf("[DB] failed to insert index metrics: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("[DB] failed to get last insert ID: %w", err)
+	}
+	metrics.ID = id
+
+	// 清理超出保留条数的历史记录
+	cleanup := `
+		DELETE FROM index_metrics
+		WHERE workspace_path = ? AND id NOT IN (
+			SELECT id FROM index_metrics WHERE workspace_path = ?
+			ORDER BY start_ts DESC, id DESC LIMIT ?
+		)
+	`
+	if _, err = r.db.GetDB().Exec(cleanup, metrics.WorkspacePath, metrics.WorkspacePath, MaxIndexMetricsPerWorkspace); err != nil {
+		r.logger.Warn("[DB] failed to cleanup index metrics for workspace %s: %v", metrics.WorkspacePath, err)
+	}
+	return nil
+}
+
+// ListIndexMetricsByWorkspace 按开始时间倒序列出工作区最近的索引任务指标
+func (r *metricsRepository) ListIndexMetricsByWorkspace(workspacePath string, limit int) ([]*model.IndexMetrics, error) {
+	query := `
+		SELECT id, workspace_path, start_ts, duration_ms, total_files, failed_files,
+			skipped_files, total_symbols, saved_symbols, total_variables, saved_variables,
+			error_message, created_at
+		FROM index_metrics
+		WHERE workspace_path = ?
+		ORDER BY start_ts DESC, id DESC
+	`
+	args := []interface{}{workspacePath}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("[DB] failed to list index metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metricsList []*model.IndexMetrics
+	for rows.Next() {
+		var metrics model.IndexMetrics
+		var createdAt time.Time
+
+		err := rows.Scan(
+			&metrics.ID,
+			&metrics.WorkspacePath,
+			&metrics.StartTs,
+			&metrics.DurationMs,
+			&metrics.TotalFiles,
+			&metrics.FailedFiles,
+			&metrics.SkippedFiles,
+			&metrics.TotalSymbols,
+			&metrics.SavedSymbols,
+			&metrics.TotalVariables,
+			&metrics.SavedVariables,
+			&metrics.ErrorMessage,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("[DB] failed to scan index_metrics table row: %w", err)
+		}
+
+		metrics.CreatedAt = createdAt
+		metricsList = append(metricsList, &metrics)
+	}
+
+	return metricsList, rows.Err()
+}
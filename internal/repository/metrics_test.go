@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"codebase-indexer/internal/model"
+	"codebase-indexer/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRepository(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	logger.On("Info", mock.Anything, mock.Anything).Maybe().Return()
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe().Return()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe().Return()
+	logger.On("Debug", mock.Anything, mock.Anything).Maybe().Return()
+
+	dbManager, cleanup := setupTestWorkspaceDB(t)
+	defer cleanup()
+
+	metricsRepo := NewMetricsRepository(dbManager, logger)
+
+	t.Run("插入并按工作区倒序查询", func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			metrics := &model.IndexMetrics{
+				WorkspacePath: "/path/to/workspace",
+				StartTs:       int64(1000 + i),
+				DurationMs:    int64(100 * i),
+				TotalFiles:    10 * i,
+				FailedFiles:   i,
+				TotalSymbols:  50 * i,
+			}
+			require.NoError(t, metricsRepo.InsertIndexMetrics(metrics))
+			assert.NotZero(t, metrics.ID)
+		}
+		require.NoError(t, metricsRepo.InsertIndexMetrics(&model.IndexMetrics{
+			WorkspacePath: "/path/to/other",
+			StartTs:       2000,
+			ErrorMessage:  "index failed",
+		}))
+
+		list, err := metricsRepo.ListIndexMetricsByWorkspace("/path/to/workspace", 0)
+		require.NoError(t, err)
+		require.Len(t, list, 3)
+		assert.Equal(t, int64(1003), list[0].StartTs)
+		assert.Equal(t, int64(300), list[0].DurationMs)
+		assert.Equal(t, 30, list[0].TotalFiles)
+		assert.Equal(t, 3, list[0].FailedFiles)
+		assert.Equal(t, 150, list[0].TotalSymbols)
+		assert.Equal(t, int64(1001), list[2].StartTs)
+
+		list, err = metricsRepo.ListIndexMetricsByWorkspace("/path/to/workspace", 2)
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+
+		list, err = metricsRepo.ListIndexMetricsByWorkspace("/path/to/other", 0)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "index failed", list[0].ErrorMessage)
+	})
+
+	t.Run("超出保留条数删除最早的记录", func(t *testing.T) {
+		workspacePath := "/path/to/retention"
+		total := MaxIndexMetricsPerWorkspace + 5
+		for i := 0; i < total; i++ {
+			require.NoError(t, metricsRepo.InsertIndexMetrics(&model.IndexMetrics{
+				WorkspacePath: workspacePath,
+				StartTs:       int64(i),
+				ErrorMessage:  fmt.Sprintf("run %d", i),
+			}))
+		}
+		list, err := metricsRepo.ListIndexMetricsByWorkspace(workspacePath, 0)
+		require.NoError(t, err)
+		require.Len(t, list, MaxIndexMetricsPerWorkspace)
+		assert.Equal(t, int64(total-1), list[0].StartTs)
+		assert.Equal(t, int64(total-MaxIndexMetricsPerWorkspace), list[len(list)-1].StartTs)
+	})
+}
@@ -141,11 +141,13 @@ func (s *FileScanner) CheckIgnoreFile(ignoreConfig *config.IgnoreConfig, codebas
 		return true, nil
 	}
 
-	// 是文件，检查后缀
+	// 是文件，检查后缀，没有扩展名的脚本按 shebang 识别语言
 	if !fileInfo.IsDir && len(fileIncludeMap) > 0 {
 		fileExt := filepath.Ext(filePath)
 		if _, ok := fileIncludeMap[fileExt]; ok {
 			return false, nil
+		} else if fileExt == types.EmptyString && includesScriptLanguage(fileIncludeMap, filePath) {
+			return false, nil
 		} else {
 			return true, nil
 		}
@@ -154,6 +156,24 @@ func (s *FileScanner) CheckIgnoreFile(ignoreConfig *config.IgnoreConfig, codebas
 	return false, nil
 }
 
+// includesScriptLanguage 没有扩展名的脚本按 shebang 识别语言，该语言的扩展名在包含列表中时才收录
+func includesScriptLanguage(fileIncludeMap map[string]struct{}, filePath string) bool {
+	language, ok := lang.InferScriptLanguage(filePath)
+	if !ok {
+		return false
+	}
+	parser, err := lang.GetSitterParserByLanguage(language)
+	if err != nil {
+		return false
+	}
+	for _, ext := range parser.SupportedExts {
+		if _, ok := fileIncludeMap[ext]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadIgnoreRules Load and combine default ignore rules with .gitignore rules
 func (s *FileScanner) LoadIgnoreRules(codebasePath string) *gitignore.GitIgnore {
 	// First create ignore object with default rules
@@ -482,9 +502,10 @@ func (s *FileScanner) ScanDirectory(codebasePath, dirPath string) (map[string]st
 			return nil
 		}
 
+		// Verify file extension is supported
 		if len(fileIncludeMap) > 0 {
-			if _, ok := fileIncludeMap[relPath]; !ok {
-				s.logger.Debug("skipping file not included: %s", relPath)
+			if _, ok := fileIncludeMap[filepath.Ext(path)]; !ok {
+				s.logger.Debug("skipping file with unsupported extension: %s", relPath)
 				return nil
 			}
 		}
@@ -547,8 +568,8 @@ func (s *FileScanner) ScanFile(codebasePath, filePath string) (string, error) {
 		return "", fmt.Errorf("file larger than %dKB(size: %.2f KB)", maxFileSizeKB, float64(info.Size())/1024)
 	}
 	if len(fileIncludeMap) > 0 {
-		if _, ok := fileIncludeMap[relPath]; !ok {
-			return "", fmt.Errorf("file not included: %s", relPath)
+		if _, ok := fileIncludeMap[filepath.Ext(filePath)]; !ok {
+			return "", fmt.Errorf("file with unsupported extension: %s", relPath)
 		}
 	}
 	hash, err := utils.CalculateFileTimestamp(filePath)
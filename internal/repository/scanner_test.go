@@ -2,6 +2,7 @@ package repository
 
 import (
 	"codebase-indexer/internal/config"
+	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/test/mocks"
 	"os"
 	"path/filepath"
@@ -9,6 +10,7 @@ import (
 	"strconv"
 	"testing"
 
+	gitignore "github.com/sabhiram/go-gitignore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -83,6 +85,46 @@ func TestLoadIgnoreRules(t *testing.T) {
 	})
 }
 
+func TestCheckIgnoreFileScripts(t *testing.T) {
+	fs := &FileScanner{scannerConfig: scannerConfig, logger: &mocks.MockLogger{}}
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"manage":     "#!/usr/bin/env python3\nprint('hi')\n",
+		"server":     "#!/usr/bin/env node\nconsole.log(1)\n",
+		"deploy":     "#!/bin/bash\necho hi\n",
+		"server.cgi": "#!/usr/bin/env python3\nprint('hi')\n",
+		"main.py":    "print('hi')\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0755))
+	}
+	ignoreConfig := &config.IgnoreConfig{
+		IgnoreRules:  gitignore.CompileIgnoreLines(),
+		IncludeRules: []string{".py"},
+		MaxFileSize:  100,
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		wantSkip bool
+	}{
+		{name: "包含列表中的扩展名", file: "main.py", wantSkip: false},
+		{name: "shebang 语言在包含列表中", file: "manage", wantSkip: false},
+		{name: "shebang 语言不在包含列表中", file: "server", wantSkip: true},
+		{name: "没有解析器的脚本", file: "deploy", wantSkip: true},
+		{name: "扩展名不在包含列表中时不识别 shebang", file: "server.cgi", wantSkip: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tt.file)
+			skip, err := fs.CheckIgnoreFile(ignoreConfig, tempDir, &types.FileInfo{Path: path, Size: 10})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSkip, skip)
+		})
+	}
+}
+
 func TestScanDirectory(t *testing.T) {
 	logger := &mocks.MockLogger{}
 	logger.On("Info", mock.Anything, mock.Anything).Return()
@@ -3,13 +3,18 @@ package repository
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
@@ -23,6 +28,7 @@ import (
 const (
 	API_UPLOAD_TOKEN         = "/codebase-embedder/api/v1/files/token"
 	API_UPLOAD_FILE          = "/codebase-embedder/api/v1/files/upload"
+	API_UPLOAD_CHUNK         = "/codebase-embedder/api/v1/files/upload/chunk"
 	API_FILE_STATUS          = "/codebase-embedder/api/v1/files/status"
 	API_GET_CODEBASE_HASH    = "/codebase-embedder/api/v1/codebases/hash"
 	API_DELETE_EMBEDDING     = "/codebase-embedder/api/v1/embeddings"
@@ -30,6 +36,8 @@ const (
 )
 
 type SyncInterface interface {
+	// SetContext 设置请求重试所属的上下文，取消后正在等待的重试立即结束
+	SetContext(ctx context.Context)
 	SetSyncConfig(config *config.SyncConfig)
 	GetSyncConfig() *config.SyncConfig
 	FetchServerHashTree(codebasePath string) (map[string]string, error)
@@ -46,6 +54,7 @@ type HTTPSync struct {
 	httpClient *utils.HTTPClient
 	logger     logger.Logger
 	rwMutex    sync.RWMutex
+	ctx        context.Context
 }
 
 func NewHTTPSync(syncConfig *config.SyncConfig, logger logger.Logger) SyncInterface {
@@ -53,6 +62,7 @@ func NewHTTPSync(syncConfig *config.SyncConfig, logger logger.Logger) SyncInterf
 		syncConfig: syncConfig,
 		httpClient: utils.NewHTTPClient(),
 		logger:     logger,
+		ctx:        context.Background(),
 	}
 }
 
@@ -93,6 +103,22 @@ func (hs *HTTPSync) ValidateSyncConfig(authInfo config.AuthInfo) error {
 	return nil
 }
 
+func (hs *HTTPSync) SetContext(ctx context.Context) {
+	hs.rwMutex.Lock()
+	defer hs.rwMutex.Unlock()
+	hs.ctx = ctx
+}
+
+// context 请求重试所属的上下文，未设置时不可取消
+func (hs *HTTPSync) context() context.Context {
+	hs.rwMutex.RLock()
+	defer hs.rwMutex.RUnlock()
+	if hs.ctx == nil {
+		return context.Background()
+	}
+	return hs.ctx
+}
+
 func (hs *HTTPSync) SetSyncConfig(config *config.SyncConfig) {
 	hs.rwMutex.Lock()
 	defer hs.rwMutex.Unlock()
@@ -128,7 +154,10 @@ func (hs *HTTPSync) FetchServerHashTree(codebasePath string) (map[string]string,
 	var responseData dto.CodebaseHashResp
 	hs.logger.Info("sending HTTP %s request to: %s", "GET", url)
 	startTime := time.Now()
-	if err := hs.httpClient.DoGetRequest(url, queryParams, authInfo.Token, &responseData); err != nil {
+	err := hs.retryWithBackoff(hs.context(), hs.uploadRetryConfig(), "fetch hash tree of "+codebasePath, func() error {
+		return hs.httpClient.DoGetRequest(url, queryParams, authInfo.Token, &responseData)
+	})
+	if err != nil {
 		return nil, err
 	}
 	duration := time.Since(startTime)
@@ -194,39 +223,61 @@ func (hs *HTTPSync) UploadFile(filePath string, uploadReq dto.UploadReq) error {
 			filePath, fileSize, counter.n, float64(counter.n)/float64(fileSize)*100, duration, float64(counter.n)/1024/duration.Seconds())
 	}()
 
-	// 构建multipart表单数据
-	formData := &utils.MultipartFormData{
-		Files: map[string]*utils.MultipartFile{
-			"file": {
-				FileName: filepath.Base(filePath),
-				Reader:   file, // 直接使用文件读取器
-			},
-		},
-		Fields: map[string]string{
-			"clientId":     uploadReq.ClientId,
-			"codebasePath": uploadReq.CodebasePath,
-			"codebaseName": uploadReq.CodebaseName,
-			"uploadToken":  uploadReq.UploadToken,
-		},
+	retryCfg := hs.uploadRetryConfig()
+	if retryCfg.chunkSize > 0 && fileSize > retryCfg.chunkSize {
+		// 大文件分片上传，失败后从服务端确认的偏移处续传；服务端不支持分片上传时整体上传
+		err := hs.uploadFileInChunks(hs.context(), file, fileSize, filepath.Base(filePath), uploadReq, authInfo, retryCfg, counter)
+		if err == nil {
+			hs.logger.Info("file uploaded successfully: %s", filePath)
+			return nil
+		}
+		if !isChunkUploadUnsupported(err) {
+			return err
+		}
+		hs.logger.Warn("server does not support chunked upload: %v, fall back to single upload: %s", err, filePath)
 	}
 
 	// 执行上传请求
 	url := fmt.Sprintf("%s%s", authInfo.ServerURL, API_UPLOAD_FILE)
+	err = hs.retryWithBackoff(hs.context(), retryCfg, "upload "+filePath, func() error {
+		// 每次重试从文件头重新读取
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek file: %v", err)
+		}
+		counter.n = 0
+
+		// 构建multipart表单数据
+		formData := &utils.MultipartFormData{
+			Files: map[string]*utils.MultipartFile{
+				"file": {
+					FileName: filepath.Base(filePath),
+					Reader:   file, // 直接使用文件读取器
+				},
+			},
+			Fields: map[string]string{
+				"clientId":     uploadReq.ClientId,
+				"codebasePath": uploadReq.CodebasePath,
+				"codebaseName": uploadReq.CodebaseName,
+				"uploadToken":  uploadReq.UploadToken,
+			},
+		}
 
-	// 创建带有超时的HTTP请求
-	httpReq := &utils.HTTPRequest{
-		Method:      "POST",
-		URL:         url,
-		Timeout:     timeout,
-		ContentType: "multipart/form-data",
-		Headers: map[string]string{
-			"X-Request-ID": uploadReq.RequestId,
-		},
-	}
+		// 创建带有超时的HTTP请求
+		httpReq := &utils.HTTPRequest{
+			Method:      "POST",
+			URL:         url,
+			Timeout:     timeout,
+			ContentType: "multipart/form-data",
+			Headers: map[string]string{
+				"X-Request-ID": uploadReq.RequestId,
+			},
+		}
 
-	// 使用自定义执行方法处理multipart请求
-	hs.logger.Info("sending HTTP %s request to: %s", "POST", url)
-	if err := hs.executeMultipartUpload(httpReq, formData, file, counter, authInfo.Token); err != nil {
+		// 使用自定义执行方法处理multipart请求
+		hs.logger.Info("sending HTTP %s request to: %s", "POST", url)
+		return hs.executeMultipartUpload(httpReq, formData, file, counter, authInfo.Token)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -284,6 +335,241 @@ func (hs *HTTPSync) executeMultipartUpload(httpReq *utils.HTTPRequest, formData
 	return nil
 }
 
+// 上传重试、分片的默认配置
+const (
+	DefaultUploadMaxRetries      = 3
+	DefaultUploadRetryBaseDelay  = 1 * time.Second
+	DefaultUploadRetryMaxDelay   = 30 * time.Second
+	DefaultUploadRetryMaxElapsed = 5 * time.Minute
+)
+
+// uploadRetryConfig 上传重试配置，SyncConfig 未设置的项使用默认值
+type uploadRetryConfig struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	maxElapsed time.Duration
+	chunkSize  int64
+}
+
+func (hs *HTTPSync) uploadRetryConfig() uploadRetryConfig {
+	cfg := uploadRetryConfig{
+		maxRetries: DefaultUploadMaxRetries,
+		baseDelay:  DefaultUploadRetryBaseDelay,
+		maxDelay:   DefaultUploadRetryMaxDelay,
+		maxElapsed: DefaultUploadRetryMaxElapsed,
+	}
+	syncConfig := hs.GetSyncConfig()
+	if syncConfig == nil {
+		return cfg
+	}
+	if syncConfig.MaxRetries > 0 {
+		cfg.maxRetries = syncConfig.MaxRetries
+	}
+	if syncConfig.RetryBaseDelay > 0 {
+		cfg.baseDelay = syncConfig.RetryBaseDelay
+	}
+	if syncConfig.RetryMaxDelay > 0 {
+		cfg.maxDelay = syncConfig.RetryMaxDelay
+	}
+	if syncConfig.ChunkSize > 0 {
+		cfg.chunkSize = syncConfig.ChunkSize
+	}
+	if syncConfig.RetryMaxElapsed > 0 {
+		cfg.maxElapsed = syncConfig.RetryMaxElapsed
+	}
+	return cfg
+}
+
+// backoffDelay 第 attempt 次重试前的等待时间：指数退避后在 [delay/2, delay] 内随机抖动，避免多个客户端同时重试；
+// 服务端通过 Retry-After 指定等待时间时以其为准
+func (cfg uploadRetryConfig) backoffDelay(attempt int, err error) time.Duration {
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter
+	}
+	delay := cfg.baseDelay << attempt
+	if delay <= 0 || delay > cfg.maxDelay {
+		delay = cfg.maxDelay
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// retryWithBackoff 执行操作，可重试的错误按带抖动的指数退避重试，最多执行 maxRetries+1 次，
+// 且总耗时不超过 maxElapsed。ctx 取消时停止等待并返回，最终错误中包含已尝试的次数
+func (hs *HTTPSync) retryWithBackoff(ctx context.Context, cfg uploadRetryConfig, operation string, fn func() error) error {
+	start := time.Now()
+	var err error
+	attempts := 0
+	for attempt := 0; attempt <= cfg.maxRetries; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%s canceled after %d attempts: %w", operation, attempts, ctxErr)
+		}
+		attempts++
+		if err = fn(); err == nil {
+			return nil
+		}
+		if !isRetryableUploadError(err) {
+			return err
+		}
+		if attempt == cfg.maxRetries {
+			break
+		}
+		delay := cfg.backoffDelay(attempt, err)
+		if cfg.maxElapsed > 0 && time.Since(start)+delay > cfg.maxElapsed {
+			hs.logger.Warn("%s failed: %v, retry delay %v exceeds max elapsed %v, give up", operation, err, delay, cfg.maxElapsed)
+			break
+		}
+		hs.logger.Warn("%s failed: %v, retry %d/%d after %v", operation, err, attempt+1, cfg.maxRetries, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s canceled after %d attempts, last err: %v: %w", operation, attempts, err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+	return fmt.Errorf("%s failed after %d attempts: %w", operation, attempts, err)
+}
+
+// isRetryableUploadError 网络错误、超时、限流和服务端错误可重试，其余 4xx 不重试
+func isRetryableUploadError(err error) bool {
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusRequestTimeout ||
+			httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var abortErr *uploadAbortError
+	return !errors.As(err, &abortErr)
+}
+
+// isChunkUploadUnsupported 服务端没有分片上传接口
+func isChunkUploadUnsupported(err error) bool {
+	var httpErr *utils.HTTPError
+	return errors.As(err, &httpErr) &&
+		(httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusMethodNotAllowed)
+}
+
+// uploadAbortError 本地读文件失败、服务端返回非法偏移等重试无意义的错误
+type uploadAbortError struct {
+	err error
+}
+
+func (e *uploadAbortError) Error() string {
+	return e.err.Error()
+}
+
+func (e *uploadAbortError) Unwrap() error {
+	return e.err
+}
+
+// uploadFileInChunks 分片上传文件。每个分片的响应中包含服务端已确认的偏移，下一个分片从该偏移开始；
+// 分片失败时先向服务端查询已确认的偏移，再从该处续传，避免整个文件重新上传
+func (hs *HTTPSync) uploadFileInChunks(ctx context.Context, file io.ReaderAt, fileSize int64, fileName string, uploadReq dto.UploadReq,
+	authInfo config.AuthInfo, cfg uploadRetryConfig, counter *writeCounter) error {
+	url := fmt.Sprintf("%s%s", authInfo.ServerURL, API_UPLOAD_CHUNK)
+	queryParams := map[string]string{
+		"uploadId":     uploadReq.RequestId,
+		"clientId":     uploadReq.ClientId,
+		"codebasePath": uploadReq.CodebasePath,
+		"codebaseName": uploadReq.CodebaseName,
+		"uploadToken":  uploadReq.UploadToken,
+		"fileName":     fileName,
+		"fileSize":     strconv.FormatInt(fileSize, 10),
+	}
+
+	// 可能是之前中断的上传，先确认服务端已接收的偏移
+	offset, err := hs.fetchUploadOffset(url, queryParams, authInfo.Token, fileSize)
+	if err != nil {
+		hs.logger.Warn("fetch upload offset for %s failed: %v, upload from beginning", fileName, err)
+		offset = 0
+	}
+	if offset > 0 {
+		hs.logger.Info("resume uploading %s from offset %d", fileName, offset)
+	}
+
+	buf := make([]byte, cfg.chunkSize)
+	for offset < fileSize {
+		err := hs.retryWithBackoff(ctx, cfg, fmt.Sprintf("upload %s chunk at offset %d", fileName, offset), func() error {
+			end := offset + cfg.chunkSize
+			if end > fileSize {
+				end = fileSize
+			}
+			chunk := buf[:end-offset]
+			if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+				return &uploadAbortError{err: fmt.Errorf("failed to read file chunk at offset %d: %v", offset, err)}
+			}
+			acked, err := hs.uploadChunk(url, queryParams, chunk, offset, fileSize, uploadReq.RequestId, authInfo.Token)
+			if err != nil {
+				// 分片可能已部分写入服务端，按服务端确认的偏移续传
+				if confirmed, qerr := hs.fetchUploadOffset(url, queryParams, authInfo.Token, fileSize); qerr == nil {
+					offset = confirmed
+				}
+				return err
+			}
+			if acked <= offset {
+				return fmt.Errorf("server acknowledged offset %d does not advance from %d", acked, offset)
+			}
+			counter.n += acked - offset
+			offset = acked
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadChunk 上传单个分片，返回服务端确认的偏移
+func (hs *HTTPSync) uploadChunk(url string, queryParams map[string]string, chunk []byte, offset, fileSize int64,
+	requestId string, token string) (int64, error) {
+	httpReq := &utils.HTTPRequest{
+		Method:      "POST",
+		URL:         url,
+		QueryParams: queryParams,
+		Body:        chunk,
+		ContentType: "application/octet-stream",
+		Timeout:     hs.calculateTimeout(int64(len(chunk))),
+		Headers: map[string]string{
+			"X-Request-ID":  requestId,
+			"Content-Range": fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, fileSize),
+		},
+	}
+	resp, err := hs.httpClient.DoHTTPRequest(httpReq, token)
+	if err != nil {
+		return 0, err
+	}
+	return parseUploadOffset(resp.Body, fileSize)
+}
+
+// fetchUploadOffset 查询服务端已确认接收的偏移
+func (hs *HTTPSync) fetchUploadOffset(url string, queryParams map[string]string, token string, fileSize int64) (int64, error) {
+	resp, err := hs.httpClient.DoHTTPRequest(&utils.HTTPRequest{
+		Method:      "GET",
+		URL:         url,
+		QueryParams: queryParams,
+	}, token)
+	if err != nil {
+		return 0, err
+	}
+	return parseUploadOffset(resp.Body, fileSize)
+}
+
+func parseUploadOffset(body []byte, fileSize int64) (int64, error) {
+	var chunkResp dto.UploadChunkResp
+	if err := json.Unmarshal(body, &chunkResp); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal upload chunk response: %v", err)
+	}
+	if chunkResp.Data.Offset < 0 || chunkResp.Data.Offset > fileSize {
+		return 0, &uploadAbortError{err: fmt.Errorf("invalid upload offset %d acknowledged by server, file size %d",
+			chunkResp.Data.Offset, fileSize)}
+	}
+	return chunkResp.Data.Offset, nil
+}
+
 // Client config file URI
 const (
 	API_GET_CLIENT_CONFIG = "/costrict/codebase-indexer/config/%scodebase-indexer-config.json"
@@ -312,7 +598,10 @@ func (hs *HTTPSync) GetClientConfig() (config.ClientConfig, error) {
 	var clientConfig config.ClientConfig
 	hs.logger.Info("sending HTTP %s request to: %s", "GET", url)
 	startTime := time.Now()
-	if err := hs.httpClient.DoGetRequest(url, nil, authInfo.Token, &clientConfig); err != nil {
+	err := hs.retryWithBackoff(hs.context(), hs.uploadRetryConfig(), "fetch client config", func() error {
+		return hs.httpClient.DoGetRequest(url, nil, authInfo.Token, &clientConfig)
+	})
+	if err != nil {
 		return config.ClientConfig{}, err
 	}
 	duration := time.Since(startTime)
@@ -454,7 +743,10 @@ func (hs *HTTPSync) FetchCombinedSummary(req dto.CombinedSummaryReq) (*dto.Combi
 	var responseData dto.CombinedSummaryResp
 	hs.logger.Info("sending HTTP %s request to: %s", "GET", url)
 	startTime := time.Now()
-	if err := hs.httpClient.DoGetRequest(url, queryParams, authInfo.Token, &responseData); err != nil {
+	err := hs.retryWithBackoff(hs.context(), hs.uploadRetryConfig(), "fetch combined summary of "+req.CodebasePath, func() error {
+		return hs.httpClient.DoGetRequest(url, queryParams, authInfo.Token, &responseData)
+	})
+	if err != nil {
 		return nil, err
 	}
 	duration := time.Since(startTime)
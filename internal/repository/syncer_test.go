@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHTTPSync(t *testing.T, serverURL string, syncConfig *config.SyncConfig) *HTTPSync {
+	logger := &mocks.MockLogger{}
+	logger.On("Info", mock.Anything, mock.Anything).Maybe().Return()
+	logger.On("Warn", mock.Anything, mock.Anything).Maybe().Return()
+	logger.On("Error", mock.Anything, mock.Anything).Maybe().Return()
+	logger.On("Debug", mock.Anything, mock.Anything).Maybe().Return()
+
+	previous := config.GetAuthInfo()
+	config.SetAuthInfo(config.AuthInfo{ClientId: "client", Token: "token", ServerURL: serverURL})
+	t.Cleanup(func() { config.SetAuthInfo(previous) })
+
+	return NewHTTPSync(syncConfig, logger).(*HTTPSync)
+}
+
+func writeTestUploadFile(t *testing.T, content string) string {
+	filePath := filepath.Join(t.TempDir(), "upload.zip")
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	return filePath
+}
+
+func TestHTTPSync_UploadFileRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failCount    int
+		failStatus   int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "首次成功", failCount: 0, failStatus: http.StatusServiceUnavailable, wantAttempts: 1},
+		{name: "前两次失败后重试成功", failCount: 2, failStatus: http.StatusServiceUnavailable, wantAttempts: 3},
+		{name: "超过最大重试次数", failCount: 10, failStatus: http.StatusInternalServerError, wantErr: true, wantAttempts: 4},
+		{name: "客户端错误不重试", failCount: 10, failStatus: http.StatusBadRequest, wantErr: true, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			attempts := 0
+			var received string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				require.Equal(t, API_UPLOAD_FILE, r.URL.Path)
+				attempts++
+				if attempts <= tt.failCount {
+					w.WriteHeader(tt.failStatus)
+					return
+				}
+				file, _, err := r.FormFile("file")
+				require.NoError(t, err)
+				data, _ := io.ReadAll(file)
+				received = string(data)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			hs := newTestHTTPSync(t, server.URL, &config.SyncConfig{
+				MaxRetries:     3,
+				RetryBaseDelay: time.Millisecond,
+				RetryMaxDelay:  5 * time.Millisecond,
+			})
+			err := hs.UploadFile(writeTestUploadFile(t, "hello codebase"), dto.UploadReq{RequestId: "req-1"})
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, tt.wantAttempts, attempts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "hello codebase", received)
+		})
+	}
+}
+
+// chunkServer 模拟支持断点续传的服务端，failAtOffset 处的分片只接收一半后返回 500
+type chunkServer struct {
+	mu            sync.Mutex
+	received      []byte
+	failAtOffset  int64
+	failed        bool
+	chunkRequests int
+	uploadedBytes int
+}
+
+func (s *chunkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != API_UPLOAD_CHUNK || r.URL.Query().Get("uploadId") != "req-1" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodPost {
+		s.chunkRequests++
+		var start, end, total int64
+		if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil ||
+			start != int64(len(s.received)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.uploadedBytes += len(body)
+		if start == s.failAtOffset && !s.failed {
+			// 传输中断：只写入了部分数据
+			s.failed = true
+			s.received = append(s.received, body[:len(body)/2]...)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.received = append(s.received, body...)
+	}
+	_ = json.NewEncoder(w).Encode(dto.UploadChunkResp{Data: dto.UploadChunkRespData{Offset: int64(len(s.received))}})
+}
+
+func TestHTTPSync_UploadFileResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 5)
+	tests := []struct {
+		name         string
+		preReceived  int
+		failAtOffset int64
+		wantUploaded int
+	}{
+		{name: "传输中断后从确认偏移续传", preReceived: 0, failAtOffset: 16, wantUploaded: 50 + 4},
+		{name: "从服务端已有偏移继续上传", preReceived: 20, failAtOffset: -1, wantUploaded: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &chunkServer{received: []byte(content[:tt.preReceived]), failAtOffset: tt.failAtOffset}
+			server := httptest.NewServer(srv)
+			defer server.Close()
+
+			hs := newTestHTTPSync(t, server.URL, &config.SyncConfig{
+				MaxRetries:     3,
+				RetryBaseDelay: time.Millisecond,
+				RetryMaxDelay:  5 * time.Millisecond,
+				ChunkSize:      8,
+			})
+			err := hs.UploadFile(writeTestUploadFile(t, content), dto.UploadReq{RequestId: "req-1"})
+			require.NoError(t, err)
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			assert.Equal(t, content, string(srv.received))
+			// 续传不会重新上传已确认的数据，只多传中断分片中未确认的部分
+			assert.Equal(t, tt.wantUploaded, srv.uploadedBytes)
+		})
+	}
+}
+
+func TestHTTPSync_UploadFileChunkFallback(t *testing.T) {
+	content := strings.Repeat("0123456789", 5)
+	tests := []struct {
+		name        string
+		chunkSize   int64
+		chunkStatus int
+		wantPaths   []string
+	}{
+		{name: "未配置分片大小时整体上传", chunkSize: 0, wantPaths: []string{API_UPLOAD_FILE}},
+		{name: "分片接口不存在时整体上传", chunkSize: 8, chunkStatus: http.StatusNotFound,
+			wantPaths: []string{API_UPLOAD_CHUNK, API_UPLOAD_CHUNK, API_UPLOAD_CHUNK, API_UPLOAD_FILE}},
+		{name: "分片接口不支持 POST 时整体上传", chunkSize: 8, chunkStatus: http.StatusMethodNotAllowed,
+			wantPaths: []string{API_UPLOAD_CHUNK, API_UPLOAD_CHUNK, API_UPLOAD_CHUNK, API_UPLOAD_FILE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var paths []string
+			var received string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				paths = append(paths, r.URL.Path)
+				if r.URL.Path != API_UPLOAD_FILE {
+					w.WriteHeader(tt.chunkStatus)
+					return
+				}
+				file, _, err := r.FormFile("file")
+				require.NoError(t, err)
+				data, _ := io.ReadAll(file)
+				received = string(data)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			hs := newTestHTTPSync(t, server.URL, &config.SyncConfig{
+				MaxRetries:     3,
+				RetryBaseDelay: time.Millisecond,
+				RetryMaxDelay:  5 * time.Millisecond,
+				ChunkSize:      tt.chunkSize,
+			})
+			require.NoError(t, hs.UploadFile(writeTestUploadFile(t, content), dto.UploadReq{RequestId: "req-1"}))
+
+			mu.Lock()
+			defer mu.Unlock()
+			// 分片接口的 404/405 不重试：查询偏移、上传首个分片、失败后查询偏移各一次
+			assert.Equal(t, tt.wantPaths, paths)
+			assert.Equal(t, content, received)
+		})
+	}
+}
+
+func TestHTTPSync_UploadFileRetryAfter(t *testing.T) {
+	var mu sync.Mutex
+	var attemptTimes []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attemptTimes = append(attemptTimes, time.Now())
+		if len(attemptTimes) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	hs := newTestHTTPSync(t, server.URL, &config.SyncConfig{
+		MaxRetries:     3,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  5 * time.Millisecond,
+	})
+	require.NoError(t, hs.UploadFile(writeTestUploadFile(t, "hello codebase"), dto.UploadReq{RequestId: "req-1"}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, attemptTimes, 2)
+	// 服务端指定的等待时间优先于退避配置
+	assert.GreaterOrEqual(t, attemptTimes[1].Sub(attemptTimes[0]), 900*time.Millisecond)
+}
+
+func TestHTTPSync_RetryLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		syncConfig   *config.SyncConfig
+		cancelAfter  time.Duration
+		wantAttempts int
+		wantErrMsg   string
+	}{
+		{
+			name:         "错误信息包含尝试次数",
+			syncConfig:   &config.SyncConfig{MaxRetries: 2, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 2 * time.Millisecond},
+			wantAttempts: 3,
+			wantErrMsg:   "failed after 3 attempts",
+		},
+		{
+			name: "超过总耗时上限停止重试",
+			syncConfig: &config.SyncConfig{MaxRetries: 10, RetryBaseDelay: time.Second, RetryMaxDelay: time.Second,
+				RetryMaxElapsed: 100 * time.Millisecond},
+			wantAttempts: 1,
+			wantErrMsg:   "failed after 1 attempts",
+		},
+		{
+			name:         "取消上下文中断重试等待",
+			syncConfig:   &config.SyncConfig{MaxRetries: 10, RetryBaseDelay: 10 * time.Second, RetryMaxDelay: 10 * time.Second},
+			cancelAfter:  50 * time.Millisecond,
+			wantAttempts: 1,
+			wantErrMsg:   "canceled after 1 attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer server.Close()
+
+			hs := newTestHTTPSync(t, server.URL, tt.syncConfig)
+			if tt.cancelAfter > 0 {
+				ctx, cancel := context.WithCancel(context.Background())
+				hs.SetContext(ctx)
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+			start := time.Now()
+			err := hs.UploadFile(writeTestUploadFile(t, "hello codebase"), dto.UploadReq{RequestId: "req-1"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErrMsg)
+			assert.Less(t, time.Since(start), 5*time.Second)
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
@@ -15,6 +15,7 @@ func SetupBackendRoutes(router *gin.Engine, backendHandler *handler.BackendHandl
 	{
 		api.GET("/callgraph", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchCallGraph)
 		api.GET("/search/reference", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchReference)
+		api.GET("/search/reference/stream", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchReferenceStream)
 		api.GET("/search/definition", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.SearchDefinition)
 		api.GET("/files/content", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetFileContent)
 		api.GET("/files/skeleton", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetFileSkeleton)
@@ -22,7 +23,14 @@ func SetupBackendRoutes(router *gin.Engine, backendHandler *handler.BackendHandl
 		api.GET("/codebases/directory", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetCodebaseDirectory)
 		api.GET("/files/structure", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetFileStructure)
 		api.GET("/index/summary", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetIndexSummary)
+		api.GET("/index/metrics", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.ListIndexMetrics)
 		api.GET("/index/export", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.ExportIndex)
+		api.GET("/index/file-elements", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetFileElements)
+		api.GET("/files/outline", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.GetFileOutline)
+		api.GET("/workspaces", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.ListWorkspaces)
 		api.DELETE("/index", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.DeleteIndex)
+		api.POST("/index/rebuild", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.RebuildProject)
+		api.POST("/index/compact", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.CompactIndex)
+		api.POST("/index/reconcile", AuthMiddleware(logger), BackendRateLimitMiddleware(logger), backendHandler.ReconcileIndex)
 	}
 }
@@ -0,0 +1,65 @@
+// internal/server/grpc.go - gRPC 服务的认证和限流拦截器
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/pkg/logger"
+)
+
+// GRPCServerOptions 返回代码图gRPC服务的拦截器选项，认证和限流规则与后端 HTTP 接口一致
+func GRPCServerOptions(logger logger.Logger) []grpc.ServerOption {
+	limiter := rate.NewLimiter(rate.Every(time.Second), 300)
+	check := func(ctx context.Context) error {
+		if !limiter.Allow() {
+			logger.Error("grpc rate limit exceeded")
+			return status.Error(codes.ResourceExhausted, "too many requests")
+		}
+		return checkGRPCAuth(ctx, logger)
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// checkGRPCAuth 与 AuthMiddleware 一致，校验请求元数据中的 authorization 是否与配置中的token值一致
+func checkGRPCAuth(ctx context.Context, logger logger.Logger) error {
+	var authHeader string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authHeader = values[0]
+		}
+	}
+	if authHeader == "" {
+		logger.Error("missing grpc authorization metadata")
+		return status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+	if token != config.GetAuthInfo().Token {
+		logger.Error("grpc request with invalid or expired token")
+		return status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/pkg/logger"
+)
+
+func TestGRPCServerOptionsAuth(t *testing.T) {
+	appLogger, err := logger.NewLogger(t.TempDir(), "error", "server-test")
+	require.NoError(t, err)
+	previous := config.GetAuthInfo()
+	config.SetAuthInfo(config.AuthInfo{Token: "test-token"})
+	t.Cleanup(func() { config.SetAuthInfo(previous) })
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(GRPCServerOptions(appLogger)...)
+	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      codes.Code
+	}{
+		{name: "缺少token", wantCode: codes.Unauthenticated},
+		{name: "token不匹配", authorization: "Bearer other-token", wantCode: codes.Unauthenticated},
+		{name: "token匹配", authorization: "Bearer test-token", wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.authorization)
+			}
+			_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
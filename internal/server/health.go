@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/utils"
+)
+
+// readinessCheckTimeout 单次就绪检查的超时时间
+const readinessCheckTimeout = 3 * time.Second
+
+// ReadinessCheck 就绪检查项，Check 返回错误表示依赖不可用
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// AddReadinessCheck 注册就绪检查项，/readyz 只在所有检查项都通过时返回200
+func (s *server) AddReadinessCheck(name string, check func(ctx context.Context) error) {
+	s.readinessChecks = append(s.readinessChecks, ReadinessCheck{Name: name, Check: check})
+}
+
+// setupHealthRoutes 设置存活和就绪检查路由，供容器编排探测使用
+func (s *server) setupHealthRoutes() {
+	s.engine.GET("/healthz", s.healthz)
+	s.engine.GET("/readyz", s.readyz)
+}
+
+// healthz 存活检查，进程能处理请求即返回200
+func (s *server) healthz(c *gin.Context) {
+	utils.Success(c, map[string]interface{}{
+		"status":  "ok",
+		"appInfo": config.GetAppInfo(),
+	})
+}
+
+// readyz 就绪检查，依次执行所有检查项，任一失败返回503
+func (s *server) readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	ready := true
+	checks := make(map[string]string, len(s.readinessChecks))
+	for _, check := range s.readinessChecks {
+		if err := check.Check(ctx); err != nil {
+			s.logger.Error("readiness check %s failed: %v", check.Name, err)
+			checks[check.Name] = err.Error()
+			ready = false
+			continue
+		}
+		checks[check.Name] = "ok"
+	}
+
+	data := map[string]interface{}{
+		"status":  "ok",
+		"appInfo": config.GetAppInfo(),
+		"checks":  checks,
+	}
+	if !ready {
+		data["status"] = "unavailable"
+		utils.FailWithCodeAndData(c, "503", "service not ready", data, http.StatusServiceUnavailable)
+		return
+	}
+	utils.Success(c, data)
+}
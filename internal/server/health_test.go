@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/database"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/logger"
+)
+
+func newTestHealthServer(t *testing.T) (*server, database.DatabaseManager, store.GraphStorage) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	appLogger, err := logger.NewLogger(t.TempDir(), "error", "server-test")
+	require.NoError(t, err)
+
+	dbManager := database.NewSQLiteManager(&config.DatabaseConfig{
+		DataDir:         t.TempDir(),
+		DatabaseName:    "test.db",
+		MaxOpenConns:    1,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: time.Minute,
+	}, appLogger)
+	require.NoError(t, dbManager.Initialize())
+	t.Cleanup(func() { _ = dbManager.Close() })
+
+	codegraphStore, err := store.NewLevelDBStorage(t.TempDir(), appLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = codegraphStore.Close() })
+
+	s := &server{engine: gin.New(), logger: appLogger}
+	s.AddReadinessCheck("database", func(ctx context.Context) error {
+		return dbManager.GetDB().PingContext(ctx)
+	})
+	s.AddReadinessCheck("codegraphStore", func(ctx context.Context) error {
+		return store.PingStorage(ctx, codegraphStore)
+	})
+	s.setupHealthRoutes()
+	return s, dbManager, codegraphStore
+}
+
+func TestServer_HealthEndpoints(t *testing.T) {
+	config.SetAppInfo(config.AppInfo{AppName: "codebase-indexer", Version: "1.0.0", OSName: "linux", ArchName: "amd64"})
+
+	tests := []struct {
+		name           string
+		breakDeps      func(dbManager database.DatabaseManager, codegraphStore store.GraphStorage)
+		wantReadyCode  int
+		wantFailedDeps []string
+	}{
+		{
+			name:          "依赖正常",
+			wantReadyCode: http.StatusOK,
+		},
+		{
+			name: "数据库已关闭",
+			breakDeps: func(dbManager database.DatabaseManager, _ store.GraphStorage) {
+				_ = dbManager.Close()
+			},
+			wantReadyCode:  http.StatusServiceUnavailable,
+			wantFailedDeps: []string{"database"},
+		},
+		{
+			name: "索引存储已关闭",
+			breakDeps: func(_ database.DatabaseManager, codegraphStore store.GraphStorage) {
+				_ = codegraphStore.Close()
+			},
+			wantReadyCode:  http.StatusServiceUnavailable,
+			wantFailedDeps: []string{"codegraphStore"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, dbManager, codegraphStore := newTestHealthServer(t)
+			if tt.breakDeps != nil {
+				tt.breakDeps(dbManager, codegraphStore)
+			}
+
+			// 存活检查不依赖数据库和索引存储
+			w := httptest.NewRecorder()
+			s.engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			w = httptest.NewRecorder()
+			s.engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			require.Equal(t, tt.wantReadyCode, w.Code, w.Body.String())
+
+			var resp struct {
+				Data struct {
+					Status  string            `json:"status"`
+					AppInfo config.AppInfo    `json:"appInfo"`
+					Checks  map[string]string `json:"checks"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "1.0.0", resp.Data.AppInfo.Version)
+			assert.Equal(t, "linux", resp.Data.AppInfo.OSName)
+			assert.Equal(t, "amd64", resp.Data.AppInfo.ArchName)
+			require.Len(t, resp.Data.Checks, 2)
+			for name, result := range resp.Data.Checks {
+				if slices.Contains(tt.wantFailedDeps, name) {
+					assert.NotEqual(t, "ok", result, name)
+				} else {
+					assert.Equal(t, "ok", result, name)
+				}
+			}
+		})
+	}
+}
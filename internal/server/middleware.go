@@ -28,13 +28,22 @@ func RecoveryMiddleware(logger logger.Logger) gin.HandlerFunc {
 	})
 }
 
+// requestIdForLog 返回请求头中可以写入日志的请求ID，不合法时返回空串
+func requestIdForLog(c *gin.Context) string {
+	id := c.GetHeader(logger.RequestIdHeader)
+	if !logger.IsValidRequestId(id) {
+		return ""
+	}
+	return id
+}
+
 // LoggingMiddleware 请求日志中间件
 func LoggingMiddleware(logger logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
-		requestId := c.GetHeader("X-Request-ID")
+		requestId := requestIdForLog(c)
 
 		// 处理请求
 		c.Next()
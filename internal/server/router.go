@@ -19,6 +19,8 @@ type Server interface {
 	Start(addr string) error
 	Shutdown(ctx context.Context) error
 	EnableSwagger()
+	// AddReadinessCheck 注册 /readyz 的就绪检查项
+	AddReadinessCheck(name string, check func(ctx context.Context) error)
 }
 
 // NewServer 创建新的HTTP服务器
@@ -41,6 +43,7 @@ type server struct {
 	logger           logger.Logger
 	httpServer       *http.Server
 	swaggerEnabled   bool
+	readinessChecks  []ReadinessCheck
 }
 
 // Start 启动服务器
@@ -100,6 +103,7 @@ func (s *server) setupMiddleware() {
 		}
 		utils.Success(c, data)
 	})
+	s.setupHealthRoutes()
 
 	// Swagger文档路由
 	if s.swaggerEnabled {
@@ -6,6 +6,7 @@ import (
 	"codebase-indexer/internal/model"
 	"codebase-indexer/internal/repository"
 	"codebase-indexer/pkg/codegraph/definition"
+	"codebase-indexer/pkg/codegraph/proto"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
@@ -21,6 +22,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -54,19 +56,44 @@ type CodebaseService interface {
 	// QueryReference 查询代码间的关系（如调用、引用等）
 	QueryReference(ctx context.Context, req *dto.SearchReferenceRequest) (*dto.ReferenceData, error)
 
+	// StreamReference 流式查询代码间的关系，定义和引用找到即回调，不在内存中累积
+	StreamReference(ctx context.Context, req *dto.SearchReferenceRequest, emit func(item *dto.ReferenceStreamItem) error) error
+
 	// QueryCallGraph 查询代码片段内部元素或单符号的调用链及其里面的元素定义，支持代码片段检索
 	QueryCallGraph(ctx context.Context, req *dto.SearchCallGraphRequest) (*dto.CallGraphData, error)
 
 	// Summarize 获取代码库索引摘要信息
 	Summarize(ctx context.Context, req *dto.GetIndexSummaryRequest) (*dto.IndexSummary, error)
 
+	// ListIndexedWorkspaces 列出已建立代码图索引的工作区及其索引状态：文件数、最近索引时间、调用图是否已构建、索引占用磁盘大小
+	ListIndexedWorkspaces(ctx context.Context) ([]*types.WorkspaceStatus, error)
+
+	// ListIndexMetrics 列出工作区最近的代码图索引任务指标，按开始时间倒序
+	ListIndexMetrics(ctx context.Context, req *dto.ListIndexMetricsRequest) ([]*model.IndexMetrics, error)
+
+	// SetIndexFilter 设置重建事件的代码图索引临时使用的 include/exclude 规则
+	SetIndexFilter(eventId int64, filter *types.IndexFilter) error
+
 	// DeleteIndex 删除代码库的索引（支持按类型删除）
 	DeleteIndex(ctx context.Context, req *dto.DeleteIndexRequest) error
+
+	// RebuildProject 提交重建工作区下单个项目代码图索引的事件，由事件队列异步执行
+	RebuildProject(ctx context.Context, req *dto.RebuildProjectRequest) error
+	// CompactIndex 压缩工作区（为空时为所有项目）的代码图索引，回收删除遗留的空间
+	CompactIndex(ctx context.Context, req *dto.CompactIndexRequest) (*dto.CompactIndexData, error)
+	// ReconcileIndex 核对工作区（为空时为所有工作区）数据库记录的索引文件数，fix 为 true 时修正
+	ReconcileIndex(ctx context.Context, req *dto.ReconcileIndexRequest) (*dto.ReconcileIndexData, error)
 	ExportIndex(c *gin.Context, d *dto.ExportIndexRequest) error
 	ReadCodeSnippets(c *gin.Context, d *dto.ReadCodeSnippetsRequest) (*dto.CodeSnippetsData, error)
 
 	// GetFileSkeleton 获取文件骨架信息
 	GetFileSkeleton(ctx context.Context, req *dto.GetFileSkeletonRequest) (*dto.FileSkeletonData, error)
+
+	// GetFileElements 获取文件解码后的原始元素表，用于排查查询无结果的问题
+	GetFileElements(ctx context.Context, req *dto.GetFileElementsRequest) (*dto.FileElementsData, error)
+
+	// GetFileOutline 获取文件大纲（定义、引用、调用、导入），用于文档符号/大纲视图
+	GetFileOutline(ctx context.Context, req *dto.GetFileOutlineRequest) (*types.FileOutline, error)
 }
 
 const maxReadLine = 5000
@@ -83,6 +110,8 @@ func NewCodebaseService(
 	logger logger.Logger,
 	workspaceReader workspace.WorkspaceReader,
 	workspaceRepository repository.WorkspaceRepository,
+	metricsRepository repository.MetricsRepository,
+	eventRepository repository.EventRepository,
 	fileDefinitionParser *definition.DefParser,
 	indexer Indexer) CodebaseService {
 	return &codebaseService{
@@ -90,6 +119,8 @@ func NewCodebaseService(
 		logger:               logger,
 		workspaceReader:      workspaceReader,
 		workspaceRepository:  workspaceRepository,
+		metricsRepository:    metricsRepository,
+		eventRepository:      eventRepository,
 		fileDefinitionParser: fileDefinitionParser,
 		indexer:              indexer,
 	}
@@ -100,6 +131,8 @@ type codebaseService struct {
 	logger               logger.Logger
 	workspaceReader      workspace.WorkspaceReader
 	workspaceRepository  repository.WorkspaceRepository
+	metricsRepository    repository.MetricsRepository
+	eventRepository      repository.EventRepository
 	fileDefinitionParser *definition.DefParser
 	indexer              Indexer
 	mu                   sync.Mutex
@@ -399,12 +432,21 @@ func (l *codebaseService) QueryDefinition(ctx context.Context, req *dto.SearchDe
 	}
 
 	nodes, err := l.indexer.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
-		Workspace:   req.CodebasePath,
-		StartLine:   req.StartLine,
-		EndLine:     req.EndLine,
-		FilePath:    req.FilePath,
-		CodeSnippet: []byte(req.CodeSnippet),
-		SymbolNames: req.SymbolNames,
+		Workspace:        req.CodebasePath,
+		StartLine:        req.StartLine,
+		EndLine:          req.EndLine,
+		StartColumn:      req.StartColumn,
+		EndColumn:        req.EndColumn,
+		FilePath:         req.FilePath,
+		CodeSnippet:      []byte(req.CodeSnippet),
+		SymbolNames:      req.SymbolNames,
+		ProjectUuid:      req.ProjectUuid,
+		StartByte:        req.StartByte,
+		EndByte:          req.EndByte,
+		LanguageFallback: req.LanguageFallback,
+		Resolution:       types.DefinitionResolution(req.Resolution),
+		IncludeDoc:       req.IncludeDoc,
+		LocalOnly:        req.LocalOnly,
 	})
 	if err != nil {
 		return nil, err
@@ -413,7 +455,7 @@ func (l *codebaseService) QueryDefinition(ctx context.Context, req *dto.SearchDe
 	// 填充content，控制层数和节点数
 	definitions, err := l.convert2DefinitionInfo(ctx, nodes, definitionFillContentNodeLimit, definitionFillContentLineLimit)
 	if err != nil {
-		l.logger.Error("fill definition query contents err:%v", err)
+		logger.ContextLogger(ctx, l.logger).Error("fill definition query contents err:%v", err)
 	}
 
 	return &dto.DefinitionData{List: definitions}, nil
@@ -440,6 +482,8 @@ func (l *codebaseService) convert2DefinitionInfo(ctx context.Context, nodes []*t
 			Name:     node.Name,
 			Type:     node.Type,
 			Position: position,
+			Score:    node.Score,
+			Doc:      node.Doc,
 		}
 		definitions = append(definitions, def)
 		startLine := position.StartLine
@@ -478,33 +522,114 @@ func (l *codebaseService) QueryReference(ctx context.Context, req *dto.SearchRef
 		return nil, errs.NewMissingParamError("codebasePath")
 	}
 
-	nodes, err := l.indexer.QueryReferences(ctx, &types.QueryReferenceOptions{
-		Workspace:  req.CodebasePath,
-		FilePath:   req.FilePath,
-		StartLine:  req.StartLine,
-		EndLine:    req.EndLine,
-		SymbolName: req.SymbolName,
+	page, err := l.indexer.QueryReferencesPage(ctx, &types.QueryReferenceOptions{
+		Workspace:      req.CodebasePath,
+		FilePath:       req.FilePath,
+		StartLine:      req.StartLine,
+		EndLine:        req.EndLine,
+		StartColumn:    req.StartColumn,
+		EndColumn:      req.EndColumn,
+		SymbolName:     req.SymbolName,
+		ProjectUuid:    req.ProjectUuid,
+		ReferenceKinds: toSymbolKinds(req.ReferenceKinds),
+		MaxLineLimit:   req.MaxLineLimit,
+		ExcludeTests:   req.ExcludeTests,
+		SameFileOnly:   req.SameFileOnly,
+		Offset:         req.Offset,
+		Limit:          req.Limit,
 	})
 	if err != nil {
 		return nil, err
 	}
+	nodes := page.Definitions
 	// 如果filePath为空，且symbolName不为空，则根据symbolName查询引用
 	if req.FilePath == types.EmptyString && req.SymbolName != types.EmptyString {
 		if len(nodes) == 0 {
-			return &dto.ReferenceData{List: nodes}, nil
+			return &dto.ReferenceData{List: nodes, Total: page.Total}, nil
 		}
 		// 填充content，控制层数和节点数，只填充子节点内容，不填充根节点内容
 		if err = l.fillContent(ctx, nodes[0].Children, relationFillContentLayerLimit, relationFillContentLayerNodeLimit, defaultLineLimit); err != nil {
-			l.logger.Error("fill graph query contents err:%v", err)
+			logger.ContextLogger(ctx, l.logger).Error("fill graph query contents err:%v", err)
 		}
-		return &dto.ReferenceData{List: nodes}, nil
+		return &dto.ReferenceData{List: nodes, Total: page.Total}, nil
 	}
 	// 如果filePath不为空，则根据filePath查询引用
 	// 填充content，控制层数和节点数
 	if err = l.fillContent(ctx, nodes, relationFillContentLayerLimit, relationFillContentLayerNodeLimit, defaultLineLimit); err != nil {
-		l.logger.Error("fill graph query contents err:%v", err)
+		logger.ContextLogger(ctx, l.logger).Error("fill graph query contents err:%v", err)
 	}
-	return &dto.ReferenceData{List: nodes}, nil
+	return &dto.ReferenceData{List: nodes, Total: page.Total}, nil
+}
+
+func (l *codebaseService) StreamReference(ctx context.Context, req *dto.SearchReferenceRequest,
+	emit func(item *dto.ReferenceStreamItem) error) error {
+	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
+		return errs.ErrIndexDisabled
+	}
+	if req.CodebasePath == types.EmptyString {
+		return errs.NewMissingParamError("codebasePath")
+	}
+
+	// 定义节点 -> 序号及已填充内容的引用数
+	type definitionState struct {
+		index  int
+		filled int
+	}
+	definitions := make(map[*types.RelationNode]*definitionState)
+	return l.indexer.QueryReferencesStream(ctx, &types.QueryReferenceOptions{
+		Workspace:      req.CodebasePath,
+		FilePath:       req.FilePath,
+		StartLine:      req.StartLine,
+		EndLine:        req.EndLine,
+		StartColumn:    req.StartColumn,
+		EndColumn:      req.EndColumn,
+		SymbolName:     req.SymbolName,
+		ProjectUuid:    req.ProjectUuid,
+		ReferenceKinds: toSymbolKinds(req.ReferenceKinds),
+		MaxLineLimit:   req.MaxLineLimit,
+		ExcludeTests:   req.ExcludeTests,
+		SameFileOnly:   req.SameFileOnly,
+	}, func(definition, reference *types.RelationNode) error {
+		if reference == nil {
+			state := &definitionState{index: len(definitions)}
+			definitions[definition] = state
+			// 与 QueryReference 一致，按符号名查询时不填充定义内容
+			if req.FilePath != types.EmptyString && state.index < relationFillContentLayerNodeLimit {
+				l.fillNodeContent(ctx, definition, defaultLineLimit)
+			}
+			return emit(&dto.ReferenceStreamItem{
+				Type:            dto.ReferenceStreamTypeDefinition,
+				DefinitionIndex: state.index,
+				Node:            definition,
+			})
+		}
+		state, ok := definitions[definition]
+		if !ok {
+			return fmt.Errorf("reference %s emitted before its definition", reference.SymbolName)
+		}
+		// 每个定义只填充前若干个引用的内容，控制读取文件的开销
+		if state.filled < relationFillContentLayerNodeLimit {
+			l.fillNodeContent(ctx, reference, defaultLineLimit)
+			state.filled++
+		}
+		return emit(&dto.ReferenceStreamItem{
+			Type:            dto.ReferenceStreamTypeReference,
+			DefinitionIndex: state.index,
+			Node:            reference,
+		})
+	})
+}
+
+// toSymbolKinds 转换请求中的引用种类
+func toSymbolKinds(kinds []string) []types.SymbolKind {
+	if len(kinds) == 0 {
+		return nil
+	}
+	symbolKinds := make([]types.SymbolKind, 0, len(kinds))
+	for _, kind := range kinds {
+		symbolKinds = append(symbolKinds, types.SymbolKind(kind))
+	}
+	return symbolKinds
 }
 
 const defaultMaxLayerLimit = 10
@@ -530,22 +655,35 @@ func (l *codebaseService) QueryCallGraph(ctx context.Context, req *dto.SearchCal
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	nodes, err := l.indexer.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
-		Workspace:  req.CodebasePath,
-		FilePath:   req.FilePath,
-		LineRange:  req.LineRange,
-		SymbolName: req.SymbolName,
-		MaxLayer:   req.MaxLayer,
+		Workspace:        req.CodebasePath,
+		FilePath:         req.FilePath,
+		LineRange:        req.LineRange,
+		SymbolName:       req.SymbolName,
+		MaxLayer:         req.MaxLayer,
+		ProjectUuid:      req.ProjectUuid,
+		MaxLineLimit:     req.MaxLineLimit,
+		PathPrefix:       req.PathPrefix,
+		DetectCycles:     req.DetectCycles,
+		IncludeVariables: req.IncludeVariables,
+		MaxNodes:         req.MaxNodes,
+		Timeout:          time.Duration(req.TimeoutMs) * time.Millisecond,
+		ExcludeTests:     req.ExcludeTests,
 	})
 	if err != nil {
 		return nil, err
 	}
 	// 填充content，控制层数和节点数
 	if err = l.fillContent(ctx, nodes, req.MaxLayer, maxLayerNodeLimit, defaultLineLimit); err != nil {
-		l.logger.Error("fill graph query contents err:%v", err)
+		logger.ContextLogger(ctx, l.logger).Error("fill graph query contents err:%v", err)
 	}
-	return &dto.CallGraphData{
-		List: nodes,
-	}, nil
+	resp = &dto.CallGraphData{
+		List:      nodes,
+		Truncated: types.IsCallGraphTruncated(nodes),
+	}
+	if req.DetectCycles {
+		resp.Cycles = types.CollectCallGraphCycles(nodes)
+	}
+	return resp, nil
 }
 
 func (l *codebaseService) fillContent(ctx context.Context, nodes []*types.RelationNode, layerLimit, layerNodeLimit, lineLimit int) error {
@@ -558,20 +696,8 @@ func (l *codebaseService) fillContent(ctx context.Context, nodes []*types.Relati
 		if i >= layerNodeLimit {
 			break
 		}
-		if node.Position.EndLine-node.Position.StartLine <= lineLimit {
-			// 读取文件内容
-			content, err := l.workspaceReader.ReadFile(ctx, node.FilePath, types.ReadOptions{
-				StartLine: node.Position.StartLine,
-				EndLine:   node.Position.EndLine,
-			})
-
-			if err != nil {
-				l.logger.Error("read file content failed: %v", err)
-				continue
-			}
-
-			// 设置节点内容
-			node.Content = string(content)
+		if !l.fillNodeContent(ctx, node, lineLimit) {
+			continue
 		}
 
 		// 如果还没有达到层级限制且有子节点，递归处理子节点
@@ -585,6 +711,25 @@ func (l *codebaseService) fillContent(ctx context.Context, nodes []*types.Relati
 	return nil
 }
 
+// fillNodeContent 节点行数不超过 lineLimit 时读取并设置节点内容，读取失败时返回 false
+func (l *codebaseService) fillNodeContent(ctx context.Context, node *types.RelationNode, lineLimit int) bool {
+	if node.Position == nil || node.Position.EndLine-node.Position.StartLine > lineLimit {
+		return true
+	}
+	// 读取文件内容
+	content, err := l.workspaceReader.ReadFile(ctx, node.FilePath, types.ReadOptions{
+		StartLine: node.Position.StartLine,
+		EndLine:   node.Position.EndLine,
+	})
+	if err != nil {
+		l.logger.Error("read file content failed: %v", err)
+		return false
+	}
+	// 设置节点内容
+	node.Content = string(content)
+	return true
+}
+
 func (l *codebaseService) Summarize(ctx context.Context, req *dto.GetIndexSummaryRequest) (*dto.IndexSummary, error) {
 	if l.manager.GetCodebaseEnv().Switch == dto.SwitchOff {
 		return nil, errs.ErrIndexDisabled
@@ -602,12 +747,67 @@ func (l *codebaseService) Summarize(ctx context.Context, req *dto.GetIndexSummar
 		Codegraph: dto.CodegraphInfo{
 			Status:     convertStatus(status),
 			TotalFiles: summary.TotalFiles,
+			Truncated:  summary.Truncated,
 		},
 	}
 
 	return resp, nil
 }
 
+func (l *codebaseService) ListIndexedWorkspaces(ctx context.Context) ([]*types.WorkspaceStatus, error) {
+	workspaces, err := l.workspaceRepository.ListWorkspaces()
+	if err != nil {
+		return nil, fmt.Errorf("list workspaces err: %w", err)
+	}
+	statuses := make([]*types.WorkspaceStatus, 0, len(workspaces))
+	for _, w := range workspaces {
+		status := &types.WorkspaceStatus{
+			WorkspaceName: w.WorkspaceName,
+			WorkspacePath: w.WorkspacePath,
+			Active:        w.Active == model.True,
+			FileNum:       w.CodegraphFileNum,
+			LastIndexTime: w.CodegraphTs,
+		}
+		// 以存储中实际的索引为准，工作区目录已删除等情况下沿用数据库记录
+		summary, err := l.indexer.GetSummary(ctx, w.WorkspacePath)
+		if err != nil {
+			l.logger.Debug("get workspace %s index summary err: %v", w.WorkspacePath, err)
+		} else if summary != nil {
+			status.FileNum = summary.TotalFiles
+			status.CallGraphBuilt = summary.CallGraphBuilt
+			status.DiskUsage = summary.DiskUsage
+		}
+		// 从未建立过代码图索引的工作区不列出
+		if status.FileNum == 0 && status.LastIndexTime == 0 {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (l *codebaseService) ListIndexMetrics(ctx context.Context, req *dto.ListIndexMetricsRequest) ([]*model.IndexMetrics, error) {
+	if req.CodebasePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("codebasePath")
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = dto.DefaultIndexMetricsLimit
+	}
+	if limit > repository.MaxIndexMetricsPerWorkspace {
+		limit = repository.MaxIndexMetricsPerWorkspace
+	}
+	metrics, err := l.metricsRepository.ListIndexMetricsByWorkspace(req.CodebasePath, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list index metrics err: %w", err)
+	}
+	return metrics, nil
+}
+
+func (l *codebaseService) SetIndexFilter(eventId int64, filter *types.IndexFilter) error {
+	return l.indexer.SetIndexFilter(eventId, filter)
+}
+
 func (l *codebaseService) DeleteIndex(ctx context.Context, req *dto.DeleteIndexRequest) error {
 	indexType := req.IndexType
 	codebasePath := req.CodebasePath
@@ -635,6 +835,73 @@ func (l *codebaseService) DeleteIndex(ctx context.Context, req *dto.DeleteIndexR
 	return nil
 }
 
+func (l *codebaseService) RebuildProject(ctx context.Context, req *dto.RebuildProjectRequest) error {
+	if req.CodebasePath == types.EmptyString {
+		return errs.NewMissingParamError("codebasePath")
+	}
+	if req.ProjectPath == types.EmptyString {
+		return errs.NewMissingParamError("projectPath")
+	}
+	projectPath := filepath.Clean(utils.FileURIToPath(req.ProjectPath))
+	// 同一项目已有待处理的重建事件时不重复创建
+	pendingEvents, err := l.eventRepository.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeRebuildProject},
+		[]string{req.CodebasePath}, -1, false, nil, []int{model.CodegraphStatusInit})
+	if err != nil {
+		return fmt.Errorf("failed to get rebuild_project events: %w", err)
+	}
+	for _, event := range pendingEvents {
+		if filepath.Clean(event.SourceFilePath) == projectPath {
+			l.logger.Info("rebuild project %s event %d already pending for workspace %s", projectPath, event.ID, req.CodebasePath)
+			return nil
+		}
+	}
+	event := &model.Event{
+		WorkspacePath:   req.CodebasePath,
+		EventType:       model.EventTypeRebuildProject,
+		SourceFilePath:  projectPath,
+		EmbeddingStatus: model.EmbeddingStatusSuccess,
+		CodegraphStatus: model.CodegraphStatusInit,
+	}
+	if err := l.eventRepository.CreateEvent(event); err != nil {
+		return fmt.Errorf("failed to create rebuild_project event: %w", err)
+	}
+	l.logger.Info("created rebuild project %s event %d for workspace %s", projectPath, event.ID, req.CodebasePath)
+	return nil
+}
+
+func (l *codebaseService) CompactIndex(ctx context.Context, req *dto.CompactIndexRequest) (*dto.CompactIndexData, error) {
+	compacted, err := l.indexer.CompactIndexes(ctx, req.CodebasePath, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compact index, err:%w", err)
+	}
+	l.logger.Info("compact index successfully for workspace %s, compacted %d projects", req.CodebasePath, len(compacted))
+	return &dto.CompactIndexData{ProjectUuids: compacted}, nil
+}
+
+func (l *codebaseService) ReconcileIndex(ctx context.Context, req *dto.ReconcileIndexRequest) (*dto.ReconcileIndexData, error) {
+	workspacePaths := []string{req.CodebasePath}
+	if req.CodebasePath == types.EmptyString {
+		workspaces, err := l.workspaceRepository.ListWorkspaces()
+		if err != nil {
+			return nil, fmt.Errorf("list workspaces err: %w", err)
+		}
+		workspacePaths = make([]string, 0, len(workspaces))
+		for _, w := range workspaces {
+			workspacePaths = append(workspacePaths, w.WorkspacePath)
+		}
+	}
+	results := make([]*types.CodegraphReconcileResult, 0, len(workspacePaths))
+	for _, workspacePath := range workspacePaths {
+		result, err := l.indexer.ReconcileCodegraphFileNum(ctx, workspacePath, req.Fix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile workspace %s index, err:%w", workspacePath, err)
+		}
+		results = append(results, result)
+	}
+	l.logger.Info("reconcile index successfully for %d workspaces, fix: %v", len(results), req.Fix)
+	return &dto.ReconcileIndexData{Results: results}, nil
+}
+
 func (s *codebaseService) GetFileSkeleton(ctx context.Context, req *dto.GetFileSkeletonRequest) (*dto.FileSkeletonData, error) {
 	// 1. 参数校验
 	if req.WorkspacePath == "" || req.FilePath == "" {
@@ -671,6 +938,79 @@ func (s *codebaseService) GetFileSkeleton(ctx context.Context, req *dto.GetFileS
 	return result, nil
 }
 
+// resolveWorkspaceFile 校验工作区和文件路径参数，相对路径基于工作区解析，并确认文件位于工作区内
+func (s *codebaseService) resolveWorkspaceFile(ctx context.Context, workspacePath string, path string) (string, error) {
+	if workspacePath == "" || path == "" {
+		return "", errs.NewMissingParamError("workspace or path")
+	}
+
+	filePath := path
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(workspacePath, filePath)
+	}
+	if err := s.checkPath(ctx, workspacePath, []string{filePath}); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+func (s *codebaseService) GetFileElements(ctx context.Context, req *dto.GetFileElementsRequest) (*dto.FileElementsData, error) {
+	filePath, err := s.resolveWorkspaceFile(ctx, req.Workspace, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := s.indexer.GetFileElementTable(ctx, req.Workspace, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file element table: %w", err)
+	}
+	return convertToFileElementsData(table), nil
+}
+
+func (s *codebaseService) GetFileOutline(ctx context.Context, req *dto.GetFileOutlineRequest) (*types.FileOutline, error) {
+	filePath, err := s.resolveWorkspaceFile(ctx, req.Workspace, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.indexer.GetFileOutline(ctx, req.Workspace, filePath)
+}
+
+// convertToFileElementsData 转换 FileElementTable 到 FileElementsData，解码 extra_data
+func convertToFileElementsData(table *codegraphpb.FileElementTable) *dto.FileElementsData {
+	data := &dto.FileElementsData{
+		Path:      table.Path,
+		Language:  table.Language,
+		Timestamp: table.Timestamp,
+		Imports:   make([]*dto.FileElementImport, 0, len(table.Imports)),
+		Elements:  make([]*dto.FileElementElement, 0, len(table.Elements)),
+	}
+	if table.Package != nil {
+		data.Package = &dto.FileElementPackage{Name: table.Package.Name, Range: table.Package.Range}
+	}
+	for _, imp := range table.Imports {
+		data.Imports = append(data.Imports, &dto.FileElementImport{
+			Name:   imp.Name,
+			Source: imp.Source,
+			Alias:  imp.Alias,
+			Range:  imp.Range,
+		})
+	}
+	for _, elem := range table.Elements {
+		element := &dto.FileElementElement{
+			Name:         elem.Name,
+			ElementType:  elem.ElementType.String(),
+			IsDefinition: elem.IsDefinition,
+			Range:        elem.Range,
+		}
+		if extraData, err := proto.UnMarshalExtraData(elem); err == nil && len(extraData) > 0 {
+			element.ExtraData = extraData
+		}
+		data.Elements = append(data.Elements, element)
+	}
+	return data
+}
+
 func convertStatus(status int) string {
 	var indexStatus string
 	switch status {
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"codebase-indexer/internal/dto"
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/test/mocks"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodebaseService_ListIndexedWorkspaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWorkspaceRepo := mocks.NewMockWorkspaceRepository(ctrl)
+	mockIndexer := mocks.NewMockIndexer(ctrl)
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	svc := &codebaseService{
+		logger:              mockLogger,
+		workspaceRepository: mockWorkspaceRepo,
+		indexer:             mockIndexer,
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		workspaces []*model.Workspace
+		listErr    error
+		summaries  map[string]*types.CodeGraphSummary
+		summaryErr error
+		want       []*types.WorkspaceStatus
+		wantErr    bool
+	}{
+		{
+			name: "返回各工作区的文件数和调用图构建状态",
+			workspaces: []*model.Workspace{
+				{WorkspaceName: "shop", WorkspacePath: "/path/shop", Active: model.True, CodegraphFileNum: 10, CodegraphTs: 1700000000},
+				{WorkspaceName: "blog", WorkspacePath: "/path/blog", Active: "false", CodegraphFileNum: 5, CodegraphTs: 1700000100},
+			},
+			summaries: map[string]*types.CodeGraphSummary{
+				"/path/shop": {TotalFiles: 12, CallGraphBuilt: true, DiskUsage: 4096},
+				"/path/blog": {TotalFiles: 5},
+			},
+			want: []*types.WorkspaceStatus{
+				{WorkspaceName: "shop", WorkspacePath: "/path/shop", Active: true, FileNum: 12, LastIndexTime: 1700000000,
+					CallGraphBuilt: true, DiskUsage: 4096},
+				{WorkspaceName: "blog", WorkspacePath: "/path/blog", FileNum: 5, LastIndexTime: 1700000100},
+			},
+		},
+		{
+			name: "不列出未建立代码图索引的工作区",
+			workspaces: []*model.Workspace{
+				{WorkspaceName: "shop", WorkspacePath: "/path/shop", Active: model.True, CodegraphFileNum: 10, CodegraphTs: 1700000000},
+				{WorkspaceName: "new", WorkspacePath: "/path/new", Active: model.True},
+				{WorkspaceName: "synced", WorkspacePath: "/path/synced", Active: model.True},
+			},
+			summaries: map[string]*types.CodeGraphSummary{
+				"/path/shop":   {TotalFiles: 10, CallGraphBuilt: true},
+				"/path/new":    {},
+				"/path/synced": {TotalFiles: 3},
+			},
+			want: []*types.WorkspaceStatus{
+				{WorkspaceName: "shop", WorkspacePath: "/path/shop", Active: true, FileNum: 10, LastIndexTime: 1700000000,
+					CallGraphBuilt: true},
+				{WorkspaceName: "synced", WorkspacePath: "/path/synced", Active: true, FileNum: 3},
+			},
+		},
+		{
+			name: "获取索引摘要失败时沿用数据库记录",
+			workspaces: []*model.Workspace{
+				{WorkspaceName: "shop", WorkspacePath: "/path/shop", Active: model.True, CodegraphFileNum: 10},
+			},
+			summaryErr: errors.New("storage closed"),
+			want: []*types.WorkspaceStatus{
+				{WorkspaceName: "shop", WorkspacePath: "/path/shop", Active: true, FileNum: 10},
+			},
+		},
+		{
+			name: "没有工作区",
+			want: []*types.WorkspaceStatus{},
+		},
+		{
+			name:    "查询工作区失败",
+			listErr: errors.New("db locked"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWorkspaceRepo.EXPECT().ListWorkspaces().Return(tt.workspaces, tt.listErr)
+			for _, w := range tt.workspaces {
+				mockIndexer.EXPECT().GetSummary(gomock.Any(), w.WorkspacePath).Return(tt.summaries[w.WorkspacePath], tt.summaryErr)
+			}
+
+			statuses, err := svc.ListIndexedWorkspaces(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, statuses)
+		})
+	}
+}
+
+func TestCodebaseService_ReconcileIndex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWorkspaceRepo := mocks.NewMockWorkspaceRepository(ctrl)
+	mockIndexer := mocks.NewMockIndexer(ctrl)
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	svc := &codebaseService{
+		logger:              mockLogger,
+		workspaceRepository: mockWorkspaceRepo,
+		indexer:             mockIndexer,
+	}
+	ctx := context.Background()
+
+	t.Run("核对指定工作区", func(t *testing.T) {
+		result := &types.CodegraphReconcileResult{WorkspacePath: "/path/shop", DatabaseFileNum: 10, IndexFileNum: 8, Corrected: true}
+		mockIndexer.EXPECT().ReconcileCodegraphFileNum(gomock.Any(), "/path/shop", true).Return(result, nil)
+
+		data, err := svc.ReconcileIndex(ctx, &dto.ReconcileIndexRequest{ClientId: "client", CodebasePath: "/path/shop", Fix: true})
+		require.NoError(t, err)
+		assert.Equal(t, []*types.CodegraphReconcileResult{result}, data.Results)
+	})
+
+	t.Run("未指定工作区时核对所有工作区", func(t *testing.T) {
+		mockWorkspaceRepo.EXPECT().ListWorkspaces().Return([]*model.Workspace{
+			{WorkspacePath: "/path/shop"}, {WorkspacePath: "/path/blog"},
+		}, nil)
+		for _, path := range []string{"/path/shop", "/path/blog"} {
+			mockIndexer.EXPECT().ReconcileCodegraphFileNum(gomock.Any(), path, false).
+				Return(&types.CodegraphReconcileResult{WorkspacePath: path}, nil)
+		}
+
+		data, err := svc.ReconcileIndex(ctx, &dto.ReconcileIndexRequest{ClientId: "client"})
+		require.NoError(t, err)
+		require.Len(t, data.Results, 2)
+		assert.Equal(t, "/path/blog", data.Results[1].WorkspacePath)
+	})
+
+	t.Run("核对失败", func(t *testing.T) {
+		mockIndexer.EXPECT().ReconcileCodegraphFileNum(gomock.Any(), "/path/missing", false).
+			Return(nil, errors.New("workspace not found"))
+
+		_, err := svc.ReconcileIndex(ctx, &dto.ReconcileIndexRequest{ClientId: "client", CodebasePath: "/path/missing"})
+		assert.Error(t, err)
+	})
+}
+
+func TestCodebaseService_RebuildProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEventRepo := mocks.NewMockEventRepository(ctrl)
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	svc := &codebaseService{
+		logger:          mockLogger,
+		eventRepository: mockEventRepo,
+	}
+	ctx := context.Background()
+	req := &dto.RebuildProjectRequest{ClientId: "client", CodebasePath: "/workspace", ProjectPath: "/workspace/app/"}
+
+	t.Run("创建重建项目事件，不同步执行重建", func(t *testing.T) {
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeRebuildProject},
+			[]string{"/workspace"}, -1, false, nil, []int{model.CodegraphStatusInit}).Return(nil, nil)
+		mockEventRepo.EXPECT().CreateEvent(gomock.Any()).DoAndReturn(func(event *model.Event) error {
+			assert.Equal(t, model.EventTypeRebuildProject, event.EventType)
+			assert.Equal(t, "/workspace", event.WorkspacePath)
+			assert.Equal(t, "/workspace/app", event.SourceFilePath)
+			assert.Equal(t, model.CodegraphStatusInit, event.CodegraphStatus)
+			assert.Equal(t, model.EmbeddingStatusSuccess, event.EmbeddingStatus)
+			return nil
+		})
+
+		require.NoError(t, svc.RebuildProject(ctx, req))
+	})
+
+	t.Run("已有待处理的重建事件时不重复创建", func(t *testing.T) {
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(gomock.Any(), gomock.Any(), gomock.Any(),
+			gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*model.Event{{ID: 7, EventType: model.EventTypeRebuildProject, SourceFilePath: "/workspace/app"}}, nil)
+
+		require.NoError(t, svc.RebuildProject(ctx, req))
+	})
+
+	t.Run("缺少项目路径", func(t *testing.T) {
+		err := svc.RebuildProject(ctx, &dto.RebuildProjectRequest{CodebasePath: "/workspace"})
+		assert.Error(t, err)
+	})
+}
+
+func TestCodebaseService_ResolveWorkspaceFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWorkspaceRepo := mocks.NewMockWorkspaceRepository(ctrl)
+	mockWorkspaceRepo.EXPECT().GetWorkspaceByPath("/workspace").Return(&model.Workspace{}, nil).AnyTimes()
+	svc := &codebaseService{workspaceRepository: mockWorkspaceRepo}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		workspace string
+		path      string
+		want      string
+		wantErr   bool
+	}{
+		{name: "相对路径基于工作区解析", workspace: "/workspace", path: "pkg/main.go", want: "/workspace/pkg/main.go"},
+		{name: "绝对路径保持不变", workspace: "/workspace", path: "/workspace/main.go", want: "/workspace/main.go"},
+		{name: "缺少文件路径", workspace: "/workspace", wantErr: true},
+		{name: "缺少工作区", path: "main.go", wantErr: true},
+		{name: "路径位于工作区外", workspace: "/workspace", path: "../other/main.go", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.resolveWorkspaceFile(ctx, tt.workspace, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,16 +1,21 @@
 package service
 
 import (
+	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	"codebase-indexer/internal/model"
 	"codebase-indexer/internal/repository"
+	internalutils "codebase-indexer/internal/utils"
 	"codebase-indexer/pkg/logger"
 )
 
@@ -32,7 +37,20 @@ type CodegraphProcessor struct {
 	workspaceReader workspace.WorkspaceReader
 	workspaceRepo   repository.WorkspaceRepository
 	eventRepo       repository.EventRepository
+	metricsRepo     repository.MetricsRepository
 	logger          logger.Logger
+	// deferOnGitOperation git rebase/merge 等批量操作进行中时暂停工作区的事件索引，操作结束后做一次整体核对索引
+	deferOnGitOperation bool
+	deferredMu          sync.Mutex
+	deferredWorkspaces  map[string]struct{}
+}
+
+// fileEventTypes 文件变更类事件，git 操作结束后的核对索引已覆盖这些事件，删除和重命名事件在核对前重放
+var fileEventTypes = []string{
+	model.EventTypeAddFile,
+	model.EventTypeModifyFile,
+	model.EventTypeDeleteFile,
+	model.EventTypeRenameFile,
 }
 
 func NewCodegraphProcessor(
@@ -40,14 +58,25 @@ func NewCodegraphProcessor(
 	indexer Indexer,
 	workspaceRepo repository.WorkspaceRepository,
 	eventRepo repository.EventRepository,
+	metricsRepo repository.MetricsRepository,
 	logger logger.Logger,
 ) CodegraphProcessService {
+	// 从环境变量获取是否在 git 批量操作期间暂停索引（环境变量名：CODEGRAPH_DEFER_ON_GIT_OPERATION，默认开启）
+	deferOnGitOperation := true
+	if envVal, ok := os.LookupEnv("CODEGRAPH_DEFER_ON_GIT_OPERATION"); ok {
+		if val, err := strconv.ParseBool(envVal); err == nil {
+			deferOnGitOperation = val
+		}
+	}
 	return &CodegraphProcessor{
-		workspaceReader: workspaceReader,
-		indexer:         indexer,
-		workspaceRepo:   workspaceRepo,
-		eventRepo:       eventRepo,
-		logger:          logger,
+		workspaceReader:     workspaceReader,
+		indexer:             indexer,
+		workspaceRepo:       workspaceRepo,
+		eventRepo:           eventRepo,
+		metricsRepo:         metricsRepo,
+		logger:              logger,
+		deferOnGitOperation: deferOnGitOperation,
+		deferredWorkspaces:  make(map[string]struct{}),
 	}
 }
 
@@ -152,6 +181,8 @@ func (c *CodegraphProcessor) ProcessRebuildWorkspaceEvent(ctx context.Context, e
 	if err := c.indexer.RemoveAllIndexes(ctx, event.WorkspacePath); err != nil {
 		return err
 	}
+	// 触发重建时指定的过滤规则只对本次索引生效
+	ctx = types.WithIndexFilter(ctx, c.indexer.TakeIndexFilter(event.ID))
 
 	if err := c.ProcessOpenWorkspaceEvent(ctx, event); err != nil {
 		return err
@@ -159,6 +190,15 @@ func (c *CodegraphProcessor) ProcessRebuildWorkspaceEvent(ctx context.Context, e
 	return nil
 }
 
+// ProcessRebuildProjectEvent 处理重建单个项目事件，清空该项目的索引后重新索引
+func (c *CodegraphProcessor) ProcessRebuildProjectEvent(ctx context.Context, event *model.Event) error {
+	err := c.indexer.RebuildProject(ctx, event.WorkspacePath, event.SourceFilePath)
+	if err = c.updateEventStatusFinally(event, err); err != nil {
+		return fmt.Errorf("codegraph update rebuild_project event %d err: %w", event.ID, err)
+	}
+	return nil
+}
+
 func (c *CodegraphProcessor) ProcessOpenWorkspaceEvent(ctx context.Context, event *model.Event) error {
 	// TODO 增加比对逻辑，如果构建过索引，进行比对。
 	fileInfo, err := c.workspaceReader.Stat(event.WorkspacePath)
@@ -191,7 +231,9 @@ func (c *CodegraphProcessor) ProcessOpenWorkspaceEvent(ctx context.Context, even
 		return err
 	}
 	// todo open_workspace过程中会更新进度，其余事件结束更新进度。
-	_, err = c.indexer.IndexWorkspace(ctx, event.WorkspacePath)
+	start := time.Now()
+	metrics, err := c.indexer.IndexWorkspace(ctx, event.WorkspacePath)
+	c.recordIndexMetrics(event.WorkspacePath, start, metrics, err)
 	if err = c.updateEventStatusFinally(event, err); err != nil {
 		return fmt.Errorf("codegraph update modify event %d err: %w", event.ID, err)
 	}
@@ -201,6 +243,10 @@ func (c *CodegraphProcessor) ProcessOpenWorkspaceEvent(ctx context.Context, even
 
 // ProcessEvents 处理事件记录
 func (c *CodegraphProcessor) ProcessEvents(ctx context.Context, workspacePaths []string) error {
+	workspacePaths = c.filterGitOperationWorkspaces(ctx, workspacePaths)
+	if len(workspacePaths) == 0 {
+		return nil
+	}
 
 	codegraphStatuses := []int{
 		model.CodegraphStatusInit,
@@ -227,6 +273,25 @@ func (c *CodegraphProcessor) ProcessEvents(ctx context.Context, workspacePaths [
 		c.logger.Info("codegraph process rebuild_workspace event successfully: %s", event.WorkspacePath)
 	}
 
+	// 重建单个项目事件
+	rebuildProjectEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeRebuildProject}, workspacePaths, 10,
+		false, nil, codegraphStatuses)
+
+	if err != nil {
+		c.logger.Error("failed to get rebuild_project events: %v", err)
+		return fmt.Errorf("failed to get rebuild_project events: %w", err)
+	}
+
+	for _, event := range rebuildProjectEvents {
+		c.logger.Info("codegraph start to process rebuild_project event: %s", event.SourceFilePath)
+		err = c.ProcessRebuildProjectEvent(ctx, event)
+		if err != nil {
+			c.logger.Error("failed to process rebuild_project event for codegraph: %v", err)
+			continue
+		}
+		c.logger.Info("codegraph process rebuild_project event successfully: %s", event.SourceFilePath)
+	}
+
 	// 打开工作区事件
 	openEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces([]string{model.EventTypeOpenWorkspace}, workspacePaths, 10,
 		false, nil, codegraphStatuses)
@@ -334,6 +399,113 @@ func (c *CodegraphProcessor) ProcessEvents(ctx context.Context, workspacePaths [
 	return nil
 }
 
+// filterGitOperationWorkspaces 过滤掉有进行中 git 批量操作的工作区，其事件保留待操作结束后处理；
+// 之前被暂停且操作已结束的工作区，先做一次核对索引
+func (c *CodegraphProcessor) filterGitOperationWorkspaces(ctx context.Context, workspacePaths []string) []string {
+	if !c.deferOnGitOperation {
+		return workspacePaths
+	}
+	c.deferredMu.Lock()
+	defer c.deferredMu.Unlock()
+	if c.deferredWorkspaces == nil {
+		c.deferredWorkspaces = make(map[string]struct{})
+	}
+
+	filtered := make([]string, 0, len(workspacePaths))
+	for _, workspacePath := range workspacePaths {
+		if marker, ok := internalutils.GitOperationInProgress(workspacePath); ok {
+			if _, deferred := c.deferredWorkspaces[workspacePath]; !deferred {
+				c.logger.Info("codegraph git operation in progress (%s) in workspace %s, defer indexing until it completes",
+					marker, workspacePath)
+				c.deferredWorkspaces[workspacePath] = struct{}{}
+			}
+			continue
+		}
+		if _, deferred := c.deferredWorkspaces[workspacePath]; deferred {
+			if err := c.reconcileDeferredWorkspace(ctx, workspacePath); err != nil {
+				// 保留暂停状态，下次重试；事件仍未处理，不会丢失
+				c.logger.Error("codegraph reconcile workspace %s after git operation err: %v", workspacePath, err)
+				continue
+			}
+			delete(c.deferredWorkspaces, workspacePath)
+		}
+		filtered = append(filtered, workspacePath)
+	}
+	return filtered
+}
+
+// reconcileDeferredWorkspace git 操作结束后对工作区做一次增量索引，并将暂停期间积压的文件事件标记为已处理。
+// 增量索引不清理已删除文件的索引，积压的删除和重命名事件先按顺序重放
+func (c *CodegraphProcessor) reconcileDeferredWorkspace(ctx context.Context, workspacePath string) error {
+	start := time.Now()
+	// 先取出积压事件，核对索引期间新产生的事件按正常流程处理
+	pendingEvents, err := c.eventRepo.GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, []string{workspacePath}, -1,
+		false, nil, []int{model.CodegraphStatusInit})
+	if err != nil {
+		return fmt.Errorf("failed to get pending file events: %w", err)
+	}
+	c.logger.Info("codegraph git operation completed in workspace %s, start to reconcile index, pending %d file events",
+		workspacePath, len(pendingEvents))
+
+	changedEvents := make([]*model.Event, 0, len(pendingEvents))
+	for _, event := range pendingEvents {
+		switch event.EventType {
+		case model.EventTypeDeleteFile:
+			c.convertWorkspaceFilePathToAbs(event)
+			err = c.ProcessDeleteFileEvent(ctx, event)
+		case model.EventTypeRenameFile:
+			c.convertWorkspaceFilePathToAbs(event)
+			err = c.ProcessRenameFileEvent(ctx, event)
+		default:
+			changedEvents = append(changedEvents, event)
+			continue
+		}
+		if err != nil {
+			c.logger.Error("codegraph replay %s event %d after git operation err: %v", event.EventType, event.ID, err)
+		}
+	}
+
+	metrics, err := c.indexer.IndexWorkspace(ctx, workspacePath)
+	c.recordIndexMetrics(workspacePath, start, metrics, err)
+	if err != nil {
+		return fmt.Errorf("failed to index workspace: %w", err)
+	}
+	for _, event := range changedEvents {
+		if err = c.updateEventStatusFinally(event, nil); err != nil {
+			c.logger.Error("codegraph update event %d after reconcile err: %v", event.ID, err)
+		}
+	}
+	c.logger.Info("codegraph reconcile workspace %s end, cost %d ms", workspacePath, time.Since(start).Milliseconds())
+	return nil
+}
+
+// recordIndexMetrics 持久化一次全量索引的任务指标，用于分析索引耗时和失败率的趋势，记录失败不影响索引流程
+func (c *CodegraphProcessor) recordIndexMetrics(workspacePath string, start time.Time, taskMetrics *types.IndexTaskMetrics, indexErr error) {
+	if c.metricsRepo == nil {
+		return
+	}
+	metrics := &model.IndexMetrics{
+		WorkspacePath: workspacePath,
+		StartTs:       start.Unix(),
+		DurationMs:    time.Since(start).Milliseconds(),
+	}
+	if taskMetrics != nil {
+		metrics.TotalFiles = taskMetrics.TotalFiles
+		metrics.FailedFiles = taskMetrics.TotalFailedFiles
+		metrics.SkippedFiles = taskMetrics.TotalSkippedFiles
+		metrics.TotalSymbols = taskMetrics.TotalSymbols
+		metrics.SavedSymbols = taskMetrics.TotalSavedSymbols
+		metrics.TotalVariables = taskMetrics.TotalVariables
+		metrics.SavedVariables = taskMetrics.TotalSavedVariables
+	}
+	if indexErr != nil {
+		metrics.ErrorMessage = indexErr.Error()
+	}
+	if err := c.metricsRepo.InsertIndexMetrics(metrics); err != nil {
+		c.logger.Error("codegraph record index metrics for workspace %s err: %v", workspacePath, err)
+	}
+}
+
 func (c *CodegraphProcessor) updateEventStatusFinally(event *model.Event, err error) error {
 	updatedEvent := &model.Event{ID: event.ID}
 	if err != nil {
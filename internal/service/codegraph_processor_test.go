@@ -2,16 +2,26 @@ package service
 
 import (
 	"codebase-indexer/internal/model"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/pkg/codegraph/analyzer"
+	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
 	"codebase-indexer/test/mocks"
 	"context"
 	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCodegraphProcessor_ProcessActiveWorkspaces(t *testing.T) {
@@ -843,6 +853,8 @@ func TestCodegraphProcessor_ProcessRebuildWorkspaceEvent(t *testing.T) {
 			setupMocks: func() {
 				// 删除所有索引成功
 				mockIndexer.EXPECT().RemoveAllIndexes(gomock.Any(), "/workspace").Return(nil)
+				filter := &types.IndexFilter{Include: []string{"pkg/**"}}
+				mockIndexer.EXPECT().TakeIndexFilter(int64(1)).Return(filter)
 
 				// 处理打开工作区事件成功
 				// 工作区存在且是目录
@@ -856,8 +868,12 @@ func TestCodegraphProcessor_ProcessRebuildWorkspaceEvent(t *testing.T) {
 				// 更新 codegraph 信息
 				mockWorkspaceRepo.EXPECT().UpdateCodegraphInfo("/workspace", 0, gomock.Any()).Return(nil)
 
-				// 索引工作区成功
-				mockIndexer.EXPECT().IndexWorkspace(gomock.Any(), "/workspace").Return(&types.IndexTaskMetrics{}, nil)
+				// 索引工作区成功，过滤规则通过上下文只传给本次索引
+				mockIndexer.EXPECT().IndexWorkspace(gomock.Any(), "/workspace").
+					DoAndReturn(func(ctx context.Context, workspacePath string) (*types.IndexTaskMetrics, error) {
+						assert.Equal(t, filter, types.IndexFilterFromContext(ctx))
+						return &types.IndexTaskMetrics{}, nil
+					})
 
 				// 更新事件状态为成功
 				mockEventRepo.EXPECT().UpdateEvent(gomock.Any()).Return(nil)
@@ -891,6 +907,7 @@ func TestCodegraphProcessor_ProcessRebuildWorkspaceEvent(t *testing.T) {
 			setupMocks: func() {
 				// 删除所有索引成功
 				mockIndexer.EXPECT().RemoveAllIndexes(gomock.Any(), "/workspace").Return(nil)
+				mockIndexer.EXPECT().TakeIndexFilter(int64(3)).Return(nil)
 
 				// 处理打开工作区事件失败
 				// 工作区不存在
@@ -925,6 +942,53 @@ func TestCodegraphProcessor_ProcessRebuildWorkspaceEvent(t *testing.T) {
 	}
 }
 
+func TestCodegraphProcessor_ProcessRebuildProjectEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIndexer := mocks.NewMockIndexer(ctrl)
+	mockEventRepo := mocks.NewMockEventRepository(ctrl)
+	processor := &CodegraphProcessor{
+		logger:    &mocks.MockLogger{},
+		indexer:   mockIndexer,
+		eventRepo: mockEventRepo,
+	}
+
+	tests := []struct {
+		name        string
+		rebuildErr  error
+		expectError bool
+		errorMsg    string
+	}{
+		{name: "成功重建项目索引"},
+		{name: "重建项目索引失败", rebuildErr: errors.New("project not found"), expectError: true, errorMsg: "project not found"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &model.Event{
+				ID:              int64(i + 1),
+				WorkspacePath:   "/workspace",
+				EventType:       model.EventTypeRebuildProject,
+				SourceFilePath:  "/workspace/app",
+				CodegraphStatus: model.CodegraphStatusInit,
+			}
+			mockIndexer.EXPECT().RebuildProject(gomock.Any(), "/workspace", "/workspace/app").Return(tt.rebuildErr)
+			// 无论成功失败都更新事件状态，不再重试
+			mockEventRepo.EXPECT().UpdateEvent(gomock.Any()).Return(nil)
+
+			err := processor.ProcessRebuildProjectEvent(context.Background(), event)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestCodegraphProcessor_updateEventStatusFinally(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1096,3 +1160,144 @@ func TestCodegraphProcessor_convertFilePathToAbs(t *testing.T) {
 		})
 	}
 }
+
+func TestCodegraphProcessor_ProcessEventsDuringGitOperation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe().Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Maybe().Return()
+	mockIndexer := mocks.NewMockIndexer(ctrl)
+	mockEventRepo := mocks.NewMockEventRepository(ctrl)
+	processor := &CodegraphProcessor{
+		logger:              mockLogger,
+		indexer:             mockIndexer,
+		eventRepo:           mockEventRepo,
+		deferOnGitOperation: true,
+	}
+
+	workspacePath := t.TempDir()
+	rebaseMarker := filepath.Join(workspacePath, ".git", "rebase-merge")
+	require.NoError(t, os.MkdirAll(rebaseMarker, 0755))
+
+	// rebase 进行中：不查询事件，不索引
+	require.NoError(t, processor.ProcessEvents(context.Background(), []string{workspacePath}))
+	require.NoError(t, processor.ProcessEvents(context.Background(), []string{workspacePath}))
+	assert.Contains(t, processor.deferredWorkspaces, workspacePath)
+
+	// rebase 结束：先重放积压的删除和重命名事件，再整体核对索引一次，其余文件事件标记为成功，
+	// 然后按正常流程处理剩余事件
+	require.NoError(t, os.RemoveAll(rebaseMarker))
+	pendingEvents := []*model.Event{
+		{ID: 1, WorkspacePath: workspacePath, EventType: model.EventTypeModifyFile, SourceFilePath: "a.go"},
+		{ID: 2, WorkspacePath: workspacePath, EventType: model.EventTypeDeleteFile, SourceFilePath: "b.go"},
+		{ID: 3, WorkspacePath: workspacePath, EventType: model.EventTypeRenameFile, SourceFilePath: "c.go",
+			TargetFilePath: "d.go"},
+	}
+	gomock.InOrder(
+		mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, []string{workspacePath}, -1,
+			false, nil, []int{model.CodegraphStatusInit}).Return(pendingEvents, nil),
+		mockIndexer.EXPECT().RemoveIndexes(gomock.Any(), workspacePath, []string{filepath.Join(workspacePath, "b.go")}).
+			Return(nil),
+		mockEventRepo.EXPECT().UpdateEvent(&eventStatusMatcher{id: 2, status: model.CodegraphStatusSuccess}).Return(nil),
+		mockIndexer.EXPECT().RenameIndexes(gomock.Any(), workspacePath, filepath.Join(workspacePath, "c.go"),
+			filepath.Join(workspacePath, "d.go")).Return(nil),
+		mockEventRepo.EXPECT().UpdateEvent(&eventStatusMatcher{id: 3, status: model.CodegraphStatusSuccess}).Return(nil),
+		mockIndexer.EXPECT().IndexWorkspace(gomock.Any(), workspacePath).Return(&types.IndexTaskMetrics{}, nil),
+		mockEventRepo.EXPECT().UpdateEvent(&eventStatusMatcher{id: 1, status: model.CodegraphStatusSuccess}).Return(nil),
+	)
+	mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(gomock.Any(), []string{workspacePath}, 10,
+		false, nil, []int{model.CodegraphStatusInit}).Return(nil, nil).Times(7)
+
+	require.NoError(t, processor.ProcessEvents(context.Background(), []string{workspacePath}))
+	assert.NotContains(t, processor.deferredWorkspaces, workspacePath)
+
+	// 核对完成后恢复正常处理，不再重复核对
+	mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(gomock.Any(), []string{workspacePath}, 10,
+		false, nil, []int{model.CodegraphStatusInit}).Return(nil, nil).Times(7)
+	require.NoError(t, processor.ProcessEvents(context.Background(), []string{workspacePath}))
+}
+
+func TestCodegraphProcessor_DeleteFileDuringGitOperation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	appLogger, err := logger.NewLogger(t.TempDir(), "error", "service-test")
+	require.NoError(t, err)
+	workspacePath := t.TempDir()
+	keptFile := filepath.Join(workspacePath, "a.go")
+	deletedFile := filepath.Join(workspacePath, "b.go")
+	require.NoError(t, os.WriteFile(keptFile, []byte("package main\n\nfunc A() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(deletedFile, []byte("package main\n\nfunc B() {}\n"), 0644))
+
+	storage, err := store.NewLevelDBStorage(t.TempDir(), appLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+	workspaceRepo := mocks.NewMockWorkspaceRepository(ctrl)
+	workspaceRepo.EXPECT().GetWorkspaceByPath(workspacePath).
+		Return(&model.Workspace{WorkspacePath: workspacePath, Active: model.True}, nil).AnyTimes()
+	workspaceRepo.EXPECT().UpdateCodegraphInfo(workspacePath, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	workspaceReader := workspace.NewWorkSpaceReader(appLogger)
+	codeIndexer := NewCodeIndexer(
+		repository.NewFileScanner(appLogger),
+		parser.NewSourceFileParser(appLogger),
+		analyzer.NewDependencyAnalyzer(appLogger, packageclassifier.NewPackageClassifier(), workspaceReader, storage),
+		workspaceReader,
+		storage,
+		workspaceRepo,
+		IndexerConfig{MaxConcurrency: 1, MaxBatchSize: 10},
+		appLogger,
+	)
+	_, err = codeIndexer.IndexWorkspace(ctx, workspacePath)
+	require.NoError(t, err)
+	_, err = codeIndexer.GetFileElementTable(ctx, workspacePath, deletedFile)
+	require.NoError(t, err)
+
+	mockEventRepo := mocks.NewMockEventRepository(ctrl)
+	processor := &CodegraphProcessor{
+		logger:              appLogger,
+		indexer:             codeIndexer,
+		eventRepo:           mockEventRepo,
+		deferOnGitOperation: true,
+	}
+
+	// rebase 期间删除文件，删除事件积压
+	rebaseMarker := filepath.Join(workspacePath, ".git", "rebase-merge")
+	require.NoError(t, os.MkdirAll(rebaseMarker, 0755))
+	require.NoError(t, processor.ProcessEvents(ctx, []string{workspacePath}))
+	require.NoError(t, os.Remove(deletedFile))
+
+	// rebase 结束后重放删除事件，已删除文件的索引被清理
+	require.NoError(t, os.RemoveAll(rebaseMarker))
+	mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(fileEventTypes, []string{workspacePath}, -1,
+		false, nil, []int{model.CodegraphStatusInit}).Return([]*model.Event{
+		{ID: 1, WorkspacePath: workspacePath, EventType: model.EventTypeDeleteFile, SourceFilePath: "b.go"},
+	}, nil)
+	mockEventRepo.EXPECT().UpdateEvent(&eventStatusMatcher{id: 1, status: model.CodegraphStatusSuccess}).Return(nil)
+	mockEventRepo.EXPECT().GetEventsByTypeAndStatusAndWorkspaces(gomock.Any(), []string{workspacePath}, 10,
+		false, nil, []int{model.CodegraphStatusInit}).Return(nil, nil).AnyTimes()
+	require.NoError(t, processor.ProcessEvents(ctx, []string{workspacePath}))
+
+	projectUuid := workspace.NewProject(filepath.Base(workspacePath), workspacePath).Uuid
+	_, err = storage.Get(ctx, projectUuid, store.ElementPathKey{Language: lang.Go, Path: deletedFile})
+	assert.ErrorIs(t, err, store.ErrKeyNotFound)
+	_, err = storage.Get(ctx, projectUuid, store.ElementPathKey{Language: lang.Go, Path: keptFile})
+	assert.NoError(t, err)
+}
+
+// eventStatusMatcher 匹配更新事件的 ID 和代码图状态
+type eventStatusMatcher struct {
+	id     int64
+	status int
+}
+
+func (m *eventStatusMatcher) Matches(x interface{}) bool {
+	event, ok := x.(*model.Event)
+	return ok && event.ID == m.id && event.CodegraphStatus == m.status
+}
+
+func (m *eventStatusMatcher) String() string {
+	return "event id and codegraph status match"
+}
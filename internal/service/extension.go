@@ -41,8 +41,8 @@ type ExtensionService interface {
 	// PublishEvents 发布工作区事件
 	PublishEvents(ctx context.Context, workspacePath, clientID string, events []dto.WorkspaceEvent) (int, error)
 
-	// TriggerIndex 触发索引构建
-	TriggerIndex(ctx context.Context, workspacePath, indexType, clientID string) error
+	// TriggerIndex 触发索引构建，filter 为本次代码图索引临时使用的 include/exclude 规则，可为空
+	TriggerIndex(ctx context.Context, workspacePath, indexType, clientID string, filter *types.IndexFilter) error
 
 	// GetIndexStatus 获取索引状态
 	GetIndexStatus(ctx context.Context, workspacePath string) (*dto.IndexStatusResponse, error)
@@ -832,7 +832,11 @@ func (s *extensionService) initEmbeddingConfig(workspacePath, clientID string) e
 }
 
 // TriggerIndex 触发索引构建
-func (s *extensionService) TriggerIndex(ctx context.Context, workspacePath, indexType, clientID string) error {
+func (s *extensionService) TriggerIndex(ctx context.Context, workspacePath, indexType, clientID string, filter *types.IndexFilter) error {
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("invalid index filter: %w", err)
+	}
+
 	// 创建代码库配置
 	fileNum, err := s.createCodebaseConfig(workspacePath, clientID)
 	if err != nil {
@@ -914,6 +918,11 @@ func (s *extensionService) TriggerIndex(ctx context.Context, workspacePath, inde
 		rebuildEventId = eventModel.ID
 	}
 
+	// 临时过滤规则只作用于代码图索引，绑定到重建事件，处理该事件时取出，不影响其他索引
+	if err := s.codebaseService.SetIndexFilter(rebuildEventId, filter); err != nil {
+		return fmt.Errorf("failed to set index filter: %w", err)
+	}
+
 	// 获取所有非进行中状态的事件（排除新创建的事件）
 	nonProcessingEmbeddingStatuses, nonProcessingCodegraphStatuses := getNonProcessingStatusesByTriggerType(indexType)
 	eventsToDelete, err := s.eventRepo.GetEventsByTypeAndStatusAndWorkspaces(
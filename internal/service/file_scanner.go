@@ -2,6 +2,9 @@ package service
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"codebase-indexer/internal/model"
@@ -14,6 +17,7 @@ import (
 type FileScanService interface {
 	ScanActiveWorkspaces() ([]*model.Workspace, error)
 	DetectFileChanges(workspacePath string) ([]*model.Event, error)
+	DetectPathChanges(workspacePath string, paths []string) ([]*model.Event, error)
 	UpdateWorkspaceStats(workspace *model.Workspace) error
 	MapFileStatusToEventType(status string) string
 }
@@ -105,6 +109,75 @@ func (ws *fileScanService) DetectFileChanges(workspacePath string) ([]*model.Eve
 		return nil, nil
 	}
 
+	return ws.saveFileChangeEvents(workspacePath, changes)
+}
+
+// DetectPathChanges 检测指定路径的文件变更，用于文件监听触发的增量检测
+func (ws *fileScanService) DetectPathChanges(workspacePath string, paths []string) ([]*model.Event, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	// 计算变更路径的哈希，忽略规则与全量扫描一致，目录会递归扫描
+	currentHashes, err := ws.fileScanner.ScanFilePaths(workspacePath, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan file paths: %w", err)
+	}
+
+	codebaseId := utils.GenerateCodebaseID(workspacePath)
+	codebaseConfig, err := ws.storage.GetCodebaseConfig(codebaseId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codebase config: %w", err)
+	}
+	embeddingId := utils.GenerateEmbeddingID(workspacePath)
+	embeddingConfig, err := ws.embeddingRepo.GetEmbeddingConfig(embeddingId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding config: %w", err)
+	}
+	if codebaseConfig.HashTree == nil {
+		codebaseConfig.HashTree = make(map[string]string)
+	}
+
+	// 只比较本次变更路径（及已删除目录下的文件），避免全量哈希
+	local := make(map[string]string)
+	remote := make(map[string]string)
+	for relPath, hash := range currentHashes {
+		local[relPath] = hash
+		codebaseConfig.HashTree[relPath] = hash
+		if remoteHash, ok := embeddingConfig.HashTree[relPath]; ok {
+			remote[relPath] = remoteHash
+		}
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			continue
+		}
+		relPath, err := filepath.Rel(workspacePath, path)
+		if err != nil {
+			continue
+		}
+		dirPrefix := filepath.ToSlash(relPath) + "/"
+		for remotePath, remoteHash := range embeddingConfig.HashTree {
+			if remotePath == relPath || strings.HasPrefix(filepath.ToSlash(remotePath), dirPrefix) {
+				remote[remotePath] = remoteHash
+				delete(codebaseConfig.HashTree, remotePath)
+			}
+		}
+	}
+
+	changes := utils.CalculateFileChanges(local, remote)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := ws.storage.SaveCodebaseConfig(codebaseConfig); err != nil {
+		return nil, fmt.Errorf("failed to save codebase config: %w", err)
+	}
+	ws.logger.Info("detected %d changes from %d watched paths in workspace: %s", len(changes), len(paths), workspacePath)
+
+	return ws.saveFileChangeEvents(workspacePath, changes)
+}
+
+// saveFileChangeEvents 根据文件变更生成事件，与已有事件去重后批量写入
+func (ws *fileScanService) saveFileChangeEvents(workspacePath string, changes []*utils.FileStatus) ([]*model.Event, error) {
 	// 在生成新事件后，查询工作区内所有现有事件
 	existingEvents, err := ws.eventRepo.GetEventsByWorkspaceForDeduplication(workspacePath)
 	if err != nil {
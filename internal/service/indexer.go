@@ -4,6 +4,7 @@ import (
 	"codebase-indexer/internal/repository"
 	"codebase-indexer/internal/service/indexer"
 	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/cache"
 	"codebase-indexer/pkg/codegraph/parser"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/store"
@@ -11,6 +12,7 @@ import (
 	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/logger"
 	"context"
+	"io"
 )
 
 // Indexer 定义代码索引器的接口，便于mock测试
@@ -19,9 +21,24 @@ type Indexer interface {
 	// IndexWorkspace 索引整个工作区
 	IndexWorkspace(ctx context.Context, workspacePath string) (*types.IndexTaskMetrics, error)
 
+	// ReindexWorkspace 强制重建工作区索引，不按时间戳过滤文件，并清理已不存在文件的索引
+	ReindexWorkspace(ctx context.Context, workspacePath string) (*types.IndexTaskMetrics, error)
+
 	// IndexFiles 根据工作区路径、文件路径，批量保存索引
 	IndexFiles(ctx context.Context, workspacePath string, filePaths []string) error
 
+	// IndexChangedSince 以 git 引用为基准，只更新工作区内发生变更的文件索引
+	IndexChangedSince(ctx context.Context, workspacePath string, gitRef string) (*types.IndexTaskMetrics, error)
+
+	// ParseAndIndexBlobs 直接解析内存中的文件内容并保存到虚拟项目索引，不访问磁盘
+	ParseAndIndexBlobs(ctx context.Context, projectId string, files []types.SourceFile) (*types.IndexTaskMetrics, error)
+
+	// SetIndexFilter 设置重建事件临时使用的 include/exclude 规则，不持久化
+	SetIndexFilter(eventId int64, filter *types.IndexFilter) error
+
+	// TakeIndexFilter 取出并清除重建事件的过滤规则，没有时返回 nil
+	TakeIndexFilter(eventId int64) *types.IndexFilter
+
 	// RenameIndexes 重命名索引，根据路径（文件或文件夹）
 	RenameIndexes(ctx context.Context, workspacePath string, sourceFilePath string, targetFilePath string) error
 
@@ -31,12 +48,30 @@ type Indexer interface {
 	// RemoveAllIndexes 删除工作区的所有索引
 	RemoveAllIndexes(ctx context.Context, workspacePath string) error
 
+	// RemoveProjectIndexes 删除工作区下指定项目的全部索引
+	RemoveProjectIndexes(ctx context.Context, workspacePath string, projectUuids []string) error
+
+	// RebuildProject 清空并重建工作区下指定项目的索引
+	RebuildProject(ctx context.Context, workspacePath, projectPath string) error
+
 	// QueryReferences 查询引用
 	QueryReferences(ctx context.Context, opts *types.QueryReferenceOptions) ([]*types.RelationNode, error)
 
+	// QueryReferencesPage 分页查询引用，同时返回引用总数
+	QueryReferencesPage(ctx context.Context, opts *types.QueryReferenceOptions) (*types.ReferencePage, error)
+
+	// QueryReferencesStream 流式查询引用，找到的定义和引用逐个回调
+	QueryReferencesStream(ctx context.Context, opts *types.QueryReferenceOptions, emit types.ReferenceEmitter) error
+
 	// QueryDefinitions 查询定义
 	QueryDefinitions(ctx context.Context, options *types.QueryDefinitionOptions) ([]*types.Definition, error)
 
+	// QueryTypeDefinitions 查询变量、参数的声明类型的定义
+	QueryTypeDefinitions(ctx context.Context, opts *types.QueryDefinitionOptions) ([]*types.Definition, error)
+
+	// ResolveQualifiedName 按限定名（如 net/http.HandlerFunc、com.foo.Bar）查询定义，不需要文件上下文
+	ResolveQualifiedName(ctx context.Context, workspacePath, qualifiedName string) ([]*types.Definition, error)
+
 	// QueryCallGraph 查询代码片段内部元素或单符号的调用链及其里面的元素定义，支持代码片段检索
 	QueryCallGraph(ctx context.Context, opts *types.QueryCallGraphOptions) ([]*types.RelationNode, error)
 
@@ -48,6 +83,45 @@ type Indexer interface {
 
 	// GetFileElementTable 获取文件元素表
 	GetFileElementTable(ctx context.Context, workspacePath string, filePath string) (*codegraphpb.FileElementTable, error)
+
+	// GetFileOutline 获取单个文件的大纲（定义、引用、调用、导入），文件未索引时返回 errs.ErrFileNotIndexed
+	GetFileOutline(ctx context.Context, workspacePath string, filePath string) (*types.FileOutline, error)
+
+	// QueryExpandedContext 查询定义及其直接引用的符号定义，组装为一份上下文
+	QueryExpandedContext(ctx context.Context, opts *types.QueryExpandedContextOptions) (*types.ExpandedContext, error)
+
+	// QueryNamingIssues 按语言规则检查已索引定义的命名规范
+	QueryNamingIssues(ctx context.Context, workspacePath string, rules []*types.NamingRule) ([]*types.NamingIssue, error)
+
+	// FindUnusedSymbols 查询项目内没有任何引用的导出定义，用于死代码分析
+	FindUnusedSymbols(ctx context.Context, workspacePath string, opts *types.FindUnusedSymbolsOptions) ([]*types.Definition, error)
+
+	// ExportSCIP 将工作区索引导出为 SCIP 格式
+	ExportSCIP(ctx context.Context, workspacePath string, w io.Writer) error
+
+	// ReconcileCodegraphFileNum 核对并按需修正 SQLite 中记录的索引文件数
+	ReconcileCodegraphFileNum(ctx context.Context, workspacePath string, fix bool) (*types.CodegraphReconcileResult, error)
+
+	// ListIndexedProjects 列出工作区下存储中已有索引的项目，包括源码目录已删除的孤立索引
+	ListIndexedProjects(ctx context.Context, workspacePath string) ([]*types.IndexedProject, error)
+
+	// CleanOrphanedProjectIndexes 回收工作区记录已删除或源码目录已不存在的项目索引
+	CleanOrphanedProjectIndexes(ctx context.Context, workspacePaths []string) ([]*types.IndexedProject, error)
+
+	// CompactIndexes 压缩项目索引，minDeleted 大于0时只压缩删除数达到该值的项目
+	CompactIndexes(ctx context.Context, workspacePath string, minDeleted int64) ([]string, error)
+
+	// VerifyIndex 检查工作区索引的一致性，按类别报告不一致的数据，不修改索引
+	VerifyIndex(ctx context.Context, workspacePath string) (*types.VerifyReport, error)
+
+	// RebuildCalleeMap 强制全量重建工作区的调用图反向索引，正常情况下反向索引在查询间复用并随文件变更增量更新
+	RebuildCalleeMap(ctx context.Context, workspacePath string) error
+
+	// SymbolCacheStats 返回索引符号缓存的累计命中、未命中、淘汰计数
+	SymbolCacheStats() cache.Stats
+
+	// Shutdown 停止接受新的调用图反向索引构建，等待进行中的构建刷盘，需在关闭存储前调用
+	Shutdown(ctx context.Context) error
 }
 
 // IndexerConfig 索引器配置（类型别名，保持向后兼容）
@@ -10,6 +10,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"runtime/debug"
 	"time"
 )
 
@@ -22,6 +24,10 @@ type BatchProcessParams struct {
 	BatchSize   int
 	TotalFiles  int
 	Project     *workspace.Project
+	// CleanupStaleSymbols 保存前清理文件中已删除符号的旧定义，增量索引已有文件时开启
+	CleanupStaleSymbols bool
+	// Contents 内存中的文件内容，按路径命中时不再读取磁盘
+	Contents map[string][]byte
 }
 
 // BatchProcessResult 批处理结果
@@ -41,6 +47,7 @@ type BatchProcessingParams struct {
 	WorkspacePath        string
 	Concurrency          int
 	BatchSize            int
+	CleanupStaleSymbols  bool
 }
 
 // BatchProcessingResult 批处理阶段结果
@@ -67,10 +74,20 @@ func (idx *Indexer) processBatch(ctx context.Context, batchId int, params *Batch
 		batchId, params.BatchStart, params.BatchEnd, params.TotalFiles, params.BatchSize)
 
 	// 解析文件
-	elementTables, metrics, err := idx.parseFiles(ctx, params.SourceFiles)
+	elementTables, metrics, err := idx.parseFilesWithContents(ctx, params.SourceFiles, params.Contents)
 	if err != nil {
 		return nil, fmt.Errorf("parse files failed: %w", err)
 	}
+	// 之前索引过、现在变为空文件或无法读取的文件，删除旧的元素表和符号，同时移出缓存
+	if params.CleanupStaleSymbols && len(metrics.SkippedFiles) > 0 {
+		removedNames, err := idx.cleanupSkippedFileIndexes(ctx, params.ProjectUuid, metrics.SkippedFiles)
+		if err != nil {
+			idx.logger.Error("batch-%d cleanup skipped file indexes error: %v", batchId, utils.TruncateError(err))
+		}
+		for _, name := range removedNames {
+			symbolCache.Remove(name)
+		}
+	}
 	if len(elementTables) == 0 {
 		return metrics, nil
 	}
@@ -78,6 +95,17 @@ func (idx *Indexer) processBatch(ctx context.Context, batchId int, params *Batch
 	idx.logger.Info("batch-%d [%d:%d]/%d parse files end, cost %d ms", batchId,
 		params.BatchStart, params.BatchEnd, params.TotalFiles, time.Since(batchStartTime).Milliseconds())
 
+	// 清理文件中已不再定义的符号，同时移出缓存，避免缓存中的旧出现位置被重新写回
+	if params.CleanupStaleSymbols {
+		staleNames, err := idx.cleanupStaleSymbolOccurrences(ctx, params.ProjectUuid, elementTables)
+		if err != nil {
+			idx.logger.Error("batch-%d cleanup stale symbol occurrences error: %v", batchId, utils.TruncateError(err))
+		}
+		for _, name := range staleNames {
+			symbolCache.Remove(name)
+		}
+	}
+
 	// 项目符号表存储
 	symbolStart := time.Now()
 
@@ -113,14 +141,56 @@ func (idx *Indexer) processBatch(ctx context.Context, batchId int, params *Batch
 		params.BatchStart, params.BatchEnd, params.TotalFiles, time.Since(batchSaveStart).Milliseconds(),
 		time.Since(batchStartTime).Milliseconds())
 
+	// 已构建的 callee map 按本批次文件增量更新
+	changedPaths := make([]string, 0, len(elementTables))
+	for _, t := range elementTables {
+		changedPaths = append(changedPaths, t.Path)
+	}
+	idx.updateCalleeMap(ctx, params.ProjectUuid, changedPaths)
+
 	return metrics, nil
 }
 
+// readHeapAlloc 读取当前堆内存占用（字节）
+func readHeapAlloc() uint64 {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return memStats.HeapAlloc
+}
+
+// adjustSymbolCacheForMemory 内存受限模式下根据堆内存调整符号缓存，返回是否发生收缩。
+// 超过 MemoryLimitMB 时清空缓存并将容量减半（不低于 MinCacheCapacity），回落到阈值一半以下时逐步恢复到 CacheCapacity。
+// 缓存中的符号每批都已写入存储，清空后按需从磁盘重新加载，不影响索引结果
+func (idx *Indexer) adjustSymbolCacheForMemory(symbolCache *cache.LRUCache[*codegraphpb.SymbolOccurrence], heapAlloc uint64) bool {
+	limit := uint64(idx.config.MemoryLimitMB) * 1024 * 1024
+	if limit == 0 {
+		return false
+	}
+	capacity := symbolCache.MaxCapacity()
+	if heapAlloc > limit {
+		newCapacity := utils.Max(capacity/2, utils.Min(MinCacheCapacity, idx.config.CacheCapacity))
+		cached := symbolCache.Len()
+		symbolCache.Purge()
+		symbolCache.Resize(newCapacity)
+		debug.FreeOSMemory()
+		idx.logger.Info("heap alloc %d MB exceeds memory limit %d MB, flushed %d cached symbols, cache capacity %d -> %d",
+			heapAlloc/1024/1024, idx.config.MemoryLimitMB, cached, capacity, newCapacity)
+		return true
+	}
+	if heapAlloc < limit/2 && capacity < idx.config.CacheCapacity {
+		newCapacity := utils.Min(capacity*2, idx.config.CacheCapacity)
+		symbolCache.Resize(newCapacity)
+		idx.logger.Debug("heap alloc %d MB below half of memory limit, cache capacity %d -> %d",
+			heapAlloc/1024/1024, capacity, newCapacity)
+	}
+	return false
+}
+
 // indexFilesInBatches 批量处理文件
 func (idx *Indexer) indexFilesInBatches(ctx context.Context, params *BatchProcessingParams) (*BatchProcessingResult, error) {
 
-	idx.logger.Info("%s, concurrency: %d, batch_size: %d cache_capacity: %d",
-		params.Project.Path, idx.config.MaxConcurrency, idx.config.MaxBatchSize, idx.config.CacheCapacity)
+	idx.logger.Info("%s, concurrency: %d, batch_size: %d cache_capacity: %d memory_limit_mb: %d",
+		params.Project.Path, idx.config.MaxConcurrency, idx.config.MaxBatchSize, idx.config.CacheCapacity, idx.config.MemoryLimitMB)
 
 	startTime := time.Now()
 	totalNeedIndexFiles := len(params.NeedIndexSourceFiles)
@@ -133,8 +203,8 @@ func (idx *Indexer) indexFilesInBatches(ctx context.Context, params *BatchProces
 		FailedFilePaths: make([]string, 0, totalNeedIndexFiles/4), // 预估失败文件数约为文件数的5%
 	}
 	// 缓存
-	symbolCache := cache.NewLRUCache[*codegraphpb.SymbolOccurrence](1000, idx.config.CacheCapacity)
-	defer symbolCache.Purge()
+	symbolCache, releaseCache := idx.newSymbolCache()
+	defer releaseCache()
 
 	var processedFilesCnt int
 	var batchId int
@@ -146,13 +216,14 @@ func (idx *Indexer) indexFilesInBatches(ctx context.Context, params *BatchProces
 		batchId++
 		// 构建批处理参数
 		batchParams := &BatchProcessParams{
-			ProjectUuid: params.ProjectUuid,
-			SourceFiles: sourceFilesBatch,
-			BatchStart:  batchStart,
-			BatchEnd:    batchEnd,
-			BatchSize:   batch,
-			TotalFiles:  totalNeedIndexFiles,
-			Project:     params.Project,
+			ProjectUuid:         params.ProjectUuid,
+			SourceFiles:         sourceFilesBatch,
+			BatchStart:          batchStart,
+			BatchEnd:            batchEnd,
+			BatchSize:           batch,
+			TotalFiles:          totalNeedIndexFiles,
+			Project:             params.Project,
+			CleanupStaleSymbols: params.CleanupStaleSymbols,
 		}
 
 		// 提交任务
@@ -171,6 +242,9 @@ func (idx *Indexer) indexFilesInBatches(ctx context.Context, params *BatchProces
 			projectMetrics.TotalVariables += metrics.TotalVariables
 			projectMetrics.TotalSavedVariables += metrics.TotalSavedVariables
 			projectMetrics.FailedFilePaths = append(projectMetrics.FailedFilePaths, metrics.FailedFilePaths...)
+			mergeSkippedFiles(projectMetrics, metrics)
+			projectMetrics.TotalParseErrorFiles += metrics.TotalParseErrorFiles
+			projectMetrics.ParseErrorFilePaths = append(projectMetrics.ParseErrorFilePaths, metrics.ParseErrorFilePaths...)
 			//TODO 更新进度
 			batchUpdateStart := time.Now()
 			if err := idx.updateProgress(ctx, &ProgressInfo{
@@ -182,6 +256,10 @@ func (idx *Indexer) indexFilesInBatches(ctx context.Context, params *BatchProces
 				return fmt.Errorf("update progress failed: %w", err)
 			}
 
+			if idx.config.MemoryLimitMB > 0 {
+				idx.adjustSymbolCacheForMemory(symbolCache, readHeapAlloc())
+			}
+
 			idx.logger.Info("update batch-%d workspace %s successful, file num %d/%d, cache size %d, cost %d ms, batch %d cost %d ms",
 				taskID, params.WorkspacePath, processedFilesCnt+params.PreviousFileNum,
 				totalNeedIndexFiles, symbolCache.Len(), time.Since(batchUpdateStart).Milliseconds(), batch, time.Since(batchStartTime).Milliseconds())
@@ -0,0 +1,47 @@
+package indexer
+
+import (
+	"strconv"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/cache"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustSymbolCacheForMemory(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		name          string
+		memoryLimitMB int
+		capacity      int
+		heapAllocMB   uint64
+		wantShrunk    bool
+		wantCapacity  int
+		wantLen       int
+	}{
+		{name: "未开启内存限制", memoryLimitMB: 0, capacity: 4000, heapAllocMB: 4096, wantCapacity: 4000, wantLen: 100},
+		{name: "未超过阈值保持不变", memoryLimitMB: 512, capacity: 4000, heapAllocMB: 300, wantCapacity: 4000, wantLen: 100},
+		{name: "超过阈值清空并减半", memoryLimitMB: 512, capacity: 4000, heapAllocMB: 600, wantShrunk: true, wantCapacity: 2000, wantLen: 0},
+		{name: "收缩不低于下限", memoryLimitMB: 512, capacity: 1500, heapAllocMB: 600, wantShrunk: true, wantCapacity: MinCacheCapacity, wantLen: 0},
+		{name: "回落到阈值一半以下逐步恢复", memoryLimitMB: 512, capacity: 1500, heapAllocMB: 100, wantCapacity: 3000, wantLen: 100},
+		{name: "恢复不超过配置容量", memoryLimitMB: 512, capacity: 3000, heapAllocMB: 100, wantCapacity: 4000, wantLen: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, _ := newTestIndexerWithStorage(t)
+			idx.config = &Config{CacheCapacity: 4000, MemoryLimitMB: tt.memoryLimitMB}
+			symbolCache := cache.NewLRUCache[*codegraphpb.SymbolOccurrence](0, tt.capacity)
+			for i := 0; i < 100; i++ {
+				name := "symbol" + strconv.Itoa(i)
+				symbolCache.Put(name, &codegraphpb.SymbolOccurrence{Name: name})
+			}
+
+			shrunk := idx.adjustSymbolCacheForMemory(symbolCache, tt.heapAllocMB*mb)
+			assert.Equal(t, tt.wantShrunk, shrunk)
+			assert.Equal(t, tt.wantCapacity, symbolCache.MaxCapacity())
+			assert.Equal(t, tt.wantLen, symbolCache.Len())
+		})
+	}
+}
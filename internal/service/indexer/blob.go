@@ -0,0 +1,74 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"fmt"
+	"time"
+)
+
+// blobProjectPathPrefix 内存文件虚拟项目的路径前缀，避免与磁盘上的项目路径冲突
+const blobProjectPathPrefix = "blob://"
+
+// newBlobProject 根据项目标识创建内存文件的虚拟项目
+func newBlobProject(projectId string) *workspace.Project {
+	return workspace.NewProject(projectId, blobProjectPathPrefix+projectId)
+}
+
+// BlobProjectUuid 返回 ParseAndIndexBlobs 索引保存的虚拟项目uuid，查询时通过 ProjectUuid 指定
+func BlobProjectUuid(projectId string) string {
+	return newBlobProject(projectId).Uuid
+}
+
+// ParseAndIndexBlobs 直接解析内存中的文件内容并保存索引，不访问磁盘，也不依赖工作区。
+// 索引保存在由 projectId 生成的虚拟项目下（见 BlobProjectUuid），同一项目重复调用时按路径覆盖已有文件的索引
+func (idx *Indexer) ParseAndIndexBlobs(ctx context.Context, projectId string, files []types.SourceFile) (*types.IndexTaskMetrics, error) {
+	start := time.Now()
+	if projectId == types.EmptyString {
+		return nil, fmt.Errorf("project id cannot be empty")
+	}
+	project := newBlobProject(projectId)
+	if len(files) == 0 {
+		return &types.IndexTaskMetrics{}, nil
+	}
+	if err := idx.migrateProjectSchema(ctx, types.EmptyString, project); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	sourceFiles := make([]*types.FileWithModTimestamp, 0, len(files))
+	contents := make(map[string][]byte, len(files))
+	for _, f := range files {
+		if f.Path == types.EmptyString {
+			continue
+		}
+		if _, ok := contents[f.Path]; !ok {
+			sourceFiles = append(sourceFiles, &types.FileWithModTimestamp{Path: f.Path, ModTime: now})
+		}
+		contents[f.Path] = f.Content
+	}
+
+	symbolCache, releaseCache := idx.newSymbolCache()
+	defer releaseCache()
+	metrics, err := idx.processBatch(ctx, 0, &BatchProcessParams{
+		ProjectUuid:         project.Uuid,
+		SourceFiles:         sourceFiles,
+		BatchEnd:            len(sourceFiles),
+		BatchSize:           len(sourceFiles),
+		TotalFiles:          len(sourceFiles),
+		Project:             project,
+		CleanupStaleSymbols: true,
+		Contents:            contents,
+	}, symbolCache)
+	if err != nil {
+		return nil, fmt.Errorf("index blobs of project %s failed: %w", projectId, err)
+	}
+	if err = idx.saveProjectMeta(ctx, project); err != nil {
+		idx.logger.Error("save blob project %s meta err: %v", projectId, err)
+	}
+
+	idx.logger.Info("index blob project %s end, cost %d ms, total %d files, failed %d files, saved symbols %d",
+		projectId, time.Since(start).Milliseconds(), metrics.TotalFiles, metrics.TotalFailedFiles, metrics.TotalSavedSymbols)
+	return metrics, nil
+}
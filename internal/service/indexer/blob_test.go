@@ -0,0 +1,71 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndIndexBlobs(t *testing.T) {
+	ctx := context.Background()
+	idx, _ := newTestIndexingIndexer(t, t.TempDir())
+	idx.config.CacheCapacity = DefaultCacheCapacity
+
+	// 路径不存在于磁盘上
+	mainPath := "/virtual/app/main.go"
+	utilPath := "/virtual/app/util.go"
+	files := []types.SourceFile{
+		{Path: mainPath, Content: []byte("package main\n\nfunc main() {\n\tHelper()\n}\n")},
+		{Path: utilPath, Content: []byte("package main\n\nfunc Helper() {}\n")},
+		{Path: "/virtual/app/README", Content: []byte("not source")},
+	}
+	metrics, err := idx.ParseAndIndexBlobs(ctx, "remote-app", files)
+	require.NoError(t, err)
+	assert.Equal(t, 0, metrics.TotalFailedFiles)
+
+	projectUuid := BlobProjectUuid("remote-app")
+	definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+		Workspace:   "/virtual/app",
+		ProjectUuid: projectUuid,
+		SymbolNames: "Helper",
+	})
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+	assert.Equal(t, utilPath, definitions[0].Path)
+
+	references, err := idx.QueryReferences(ctx, &types.QueryReferenceOptions{
+		Workspace:   "/virtual/app",
+		ProjectUuid: projectUuid,
+		FilePath:    utilPath,
+		StartLine:   3,
+		EndLine:     3,
+		SymbolName:  "Helper",
+	})
+	require.NoError(t, err)
+	require.Len(t, references, 1)
+	require.Len(t, references[0].Children, 1)
+	assert.Equal(t, mainPath, references[0].Children[0].FilePath)
+
+	t.Run("重复调用覆盖已有文件", func(t *testing.T) {
+		_, err := idx.ParseAndIndexBlobs(ctx, "remote-app", []types.SourceFile{
+			{Path: utilPath, Content: []byte("package main\n\nfunc Renamed() {}\n")},
+		})
+		require.NoError(t, err)
+		definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+			Workspace:   "/virtual/app",
+			ProjectUuid: projectUuid,
+			SymbolNames: "Helper",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, definitions)
+	})
+
+	t.Run("项目标识为空", func(t *testing.T) {
+		_, err := idx.ParseAndIndexBlobs(ctx, "", files)
+		assert.Error(t, err)
+	})
+}
@@ -12,15 +12,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"runtime/debug"
+	"sort"
 	"time"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+	"google.golang.org/protobuf/encoding/protowire"
 )
 
 // IndexWorkspace 索引整个工作区
 func (idx *Indexer) IndexWorkspace(ctx context.Context, workspacePath string) (*types.IndexTaskMetrics, error) {
+	return idx.indexWorkspace(ctx, workspacePath, false)
+}
+
+// ReindexWorkspace 强制重建工作区索引，不按时间戳过滤文件，并清理已不存在文件的索引
+func (idx *Indexer) ReindexWorkspace(ctx context.Context, workspacePath string) (*types.IndexTaskMetrics, error) {
+	return idx.indexWorkspace(ctx, workspacePath, true)
+}
+
+func (idx *Indexer) indexWorkspace(ctx context.Context, workspacePath string, force bool) (*types.IndexTaskMetrics, error) {
 	taskMetrics := &types.IndexTaskMetrics{}
 	workspaceStart := time.Now()
-	idx.logger.Info("start to index workspace：%s", workspacePath)
+	idx.logger.Info("start to index workspace：%s, force: %v", workspacePath, force)
+	defer idx.lockWorkspace(workspacePath)()
 	exists, err := idx.workspaceReader.Exists(ctx, workspacePath)
 	if err == nil && !exists {
 		return taskMetrics, fmt.Errorf("workspace %s not exists", workspacePath)
@@ -30,43 +47,174 @@ func (idx *Indexer) IndexWorkspace(ctx context.Context, workspacePath string) (*
 	if projectsCnt == 0 {
 		return taskMetrics, fmt.Errorf("find no projects in workspace: %s", workspacePath)
 	}
-	if projectsCnt > idx.config.MaxProjects {
-		projects = projects[:idx.config.MaxProjects]
-		idx.logger.Debug("%s found %d projects, exceed %d max_projects config, use config size.", workspacePath, projectsCnt)
+	projects = idx.selectProjects(ctx, workspacePath, projects)
+	if len(projects) == 0 {
+		return taskMetrics, fmt.Errorf("no projects selected in workspace %s by strategy %q",
+			workspacePath, idx.config.ProjectSelectStrategy)
+	}
+
+	if force {
+		// 所有文件都会重新解析，进度从0开始累加
+		if err := idx.workspaceRepository.UpdateCodegraphInfo(workspacePath, 0, time.Now().Unix()); err != nil {
+			return taskMetrics, fmt.Errorf("reset workspace %s codegraph info err: %w", workspacePath, err)
+		}
 	}
 
 	var errs []error
 
 	// 循环项目，逐个处理
 	for _, project := range projects {
-		projectTaskMetrics, err := idx.indexProject(ctx, workspacePath, project)
+		projectTaskMetrics, err := idx.indexProjectSafely(ctx, workspacePath, project, force)
 		if err != nil {
 			idx.logger.Error("index project %s err: %v",
 				project.Path, utils.TruncateError(errors.Join(err...)))
 			errs = append(errs, err...)
+			taskMetrics.FailedProjects = append(taskMetrics.FailedProjects, project.Path)
 			continue
 		}
 
 		taskMetrics.TotalFiles += projectTaskMetrics.TotalFiles
 		taskMetrics.TotalFailedFiles += projectTaskMetrics.TotalFailedFiles
+		taskMetrics.TotalForceReparsedFiles += projectTaskMetrics.TotalForceReparsedFiles
 		taskMetrics.FailedFilePaths = append(taskMetrics.FailedFilePaths, projectTaskMetrics.FailedFilePaths...)
+		mergeSkippedFiles(taskMetrics, projectTaskMetrics)
+		taskMetrics.TotalParseErrorFiles += projectTaskMetrics.TotalParseErrorFiles
+		taskMetrics.ParseErrorFilePaths = append(taskMetrics.ParseErrorFilePaths, projectTaskMetrics.ParseErrorFilePaths...)
+		if projectTaskMetrics.FileLimitHit {
+			taskMetrics.Truncated = true
+			taskMetrics.FileLimitHit = true
+			taskMetrics.MaxFilesLimit = projectTaskMetrics.MaxFilesLimit
+		}
 	}
 
 	idx.logger.Info("workspace %s index end. cost %d ms, indexed %d projects, visited %d files, "+
-		"parsed %d files successfully, failed %d files", workspacePath,
+		"parsed %d files successfully, failed %d files, force reparsed %d files, skipped %d files "+
+		"(%d empty, %d unreadable), %d files with syntax errors", workspacePath,
 		time.Since(workspaceStart).Milliseconds(), len(projects), taskMetrics.TotalFiles,
-		taskMetrics.TotalFiles-taskMetrics.TotalFailedFiles, taskMetrics.TotalFailedFiles)
+		taskMetrics.TotalFiles-taskMetrics.TotalFailedFiles, taskMetrics.TotalFailedFiles,
+		taskMetrics.TotalForceReparsedFiles, taskMetrics.TotalSkippedFiles, taskMetrics.TotalSkippedEmptyFiles,
+		taskMetrics.TotalSkippedUnreadableFiles, taskMetrics.TotalParseErrorFiles)
+	if taskMetrics.FileLimitHit {
+		idx.logger.Warn("workspace %s index is partial, max files limit %d reached", workspacePath, taskMetrics.MaxFilesLimit)
+	}
 	return taskMetrics, nil
 }
 
+// indexProjectSafely 索引单个项目，项目索引过程中的 panic 转为错误返回，不影响工作区中其它项目
+func (idx *Indexer) indexProjectSafely(ctx context.Context, workspacePath string, project *workspace.Project,
+	force bool) (metrics *types.IndexTaskMetrics, errs []error) {
+	defer func() {
+		if r := recover(); r != nil {
+			idx.logger.Error("recovered from panic when indexing project %s: %v\nStack trace:\n%s",
+				project.Path, r, debug.Stack())
+			metrics = nil
+			errs = []error{fmt.Errorf("panic during indexing project %s: %v", project.Path, r)}
+		}
+	}()
+	return idx.indexProject(ctx, workspacePath, project, force)
+}
+
+// selectProjects 按配置的策略排序或过滤项目，再截断到 MaxProjects，并记录被丢弃的项目
+func (idx *Indexer) selectProjects(ctx context.Context, workspacePath string, projects []*workspace.Project) []*workspace.Project {
+	selected := make([]*workspace.Project, len(projects))
+	copy(selected, projects)
+
+	switch idx.config.ProjectSelectStrategy {
+	case ProjectSelectDefault:
+	case ProjectSelectAlphabetical:
+		sort.SliceStable(selected, func(i, j int) bool {
+			return selected[i].Path < selected[j].Path
+		})
+	case ProjectSelectLargestFirst:
+		fileCounts := make(map[string]int, len(selected))
+		for _, p := range selected {
+			fileCounts[p.Path] = idx.countProjectFiles(ctx, p.Path)
+		}
+		sort.SliceStable(selected, func(i, j int) bool {
+			if fileCounts[selected[i].Path] != fileCounts[selected[j].Path] {
+				return fileCounts[selected[i].Path] > fileCounts[selected[j].Path]
+			}
+			return selected[i].Path < selected[j].Path
+		})
+	case ProjectSelectAllowlist:
+		byPath := make(map[string]*workspace.Project, len(selected))
+		for _, p := range selected {
+			byPath[filepath.Clean(p.Path)] = p
+		}
+		selected = selected[:0]
+		for _, allowed := range idx.config.ProjectAllowlist {
+			if !filepath.IsAbs(allowed) {
+				allowed = filepath.Join(workspacePath, allowed)
+			}
+			p, ok := byPath[filepath.Clean(allowed)]
+			if !ok {
+				idx.logger.Warn("allowlist project %s not found in workspace %s", allowed, workspacePath)
+				continue
+			}
+			selected = append(selected, p)
+			delete(byPath, filepath.Clean(allowed))
+		}
+	default:
+		idx.logger.Warn("unknown project select strategy %q, use default", idx.config.ProjectSelectStrategy)
+	}
+
+	if len(selected) > idx.config.MaxProjects {
+		selected = selected[:idx.config.MaxProjects]
+	}
+	if len(selected) < len(projects) {
+		kept := make(map[*workspace.Project]struct{}, len(selected))
+		for _, p := range selected {
+			kept[p] = struct{}{}
+		}
+		var dropped []string
+		for _, p := range projects {
+			if _, ok := kept[p]; !ok {
+				dropped = append(dropped, p.Path)
+			}
+		}
+		idx.logger.Info("%s found %d projects, selected %d by strategy %q with max_projects %d, dropped: %v",
+			workspacePath, len(projects), len(selected), idx.config.ProjectSelectStrategy, idx.config.MaxProjects, dropped)
+	}
+	return selected
+}
+
+// countProjectFiles 统计项目下的源码文件数，用于按项目大小选择
+func (idx *Indexer) countProjectFiles(ctx context.Context, projectPath string) int {
+	visitPattern := idx.config.VisitPattern
+	if visitPattern == nil {
+		visitPattern = workspace.DefaultVisitPattern
+	}
+	count := 0
+	err := idx.workspaceReader.WalkFile(ctx, projectPath, func(walkCtx *types.WalkContext) error {
+		if !walkCtx.Info.IsDir {
+			count++
+		}
+		return nil
+	}, types.WalkOptions{IgnoreError: true, VisitPattern: visitPattern,
+		SkipSymlinks: idx.config.SkipSymlinks, FollowExternalSymlinks: idx.config.FollowExternalSymlinks,
+		Concurrency: idx.config.WalkConcurrency})
+	if err != nil {
+		idx.logger.Warn("count project %s files err: %v", projectPath, err)
+	}
+	return count
+}
+
 // indexProject 索引单个项目
-func (idx *Indexer) indexProject(ctx context.Context, workspacePath string, project *workspace.Project) (*types.IndexTaskMetrics, []error) {
+func (idx *Indexer) indexProject(ctx context.Context, workspacePath string, project *workspace.Project, force bool) (*types.IndexTaskMetrics, []error) {
 	projectStart := time.Now()
 	projectUuid := project.Uuid
 
 	idx.logger.Info("start to index project：%s, max_concurrency: %d, batch_size: %d",
 		project.Path, idx.config.MaxConcurrency, idx.config.MaxBatchSize)
 
+	// 旧版本UUID下的索引迁移到当前UUID
+	idx.migrateProjectUuid(ctx, project)
+
+	// schema 版本不一致时清空旧索引，下面按无索引全量重建
+	if err := idx.migrateProjectSchema(ctx, workspacePath, project); err != nil {
+		return nil, []error{err}
+	}
+
 	// 获取工作区信息
 	workspaceModel, err := idx.workspaceRepository.GetWorkspaceByPath(workspacePath)
 	if err != nil {
@@ -79,26 +227,54 @@ func (idx *Indexer) indexProject(ctx context.Context, workspacePath string, proj
 	databasePreviousFileNum := workspaceModel.CodegraphFileNum
 
 	// 收集要处理的源码文件
-	sourceFileTimestamps, err := idx.collectFiles(ctx, workspacePath, project.Path)
+	sourceFileTimestamps, maxFiles, fileLimitHit, err := idx.collectFiles(ctx, workspacePath, project.Path)
 	if err != nil {
 		return &types.IndexTaskMetrics{TotalFiles: 0}, []error{fmt.Errorf("collect project files err:%v", err)}
 	}
+	if err = idx.saveFileLimitMeta(ctx, projectUuid, fileLimitHit, maxFiles); err != nil {
+		idx.logger.Error("save project %s file limit meta err: %v", project.Path, err)
+	}
 
 	totalFilesCnt := len(sourceFileTimestamps)
 	if totalFilesCnt == 0 {
 		idx.logger.Info("found no source files in project %s, not index.", project.Path)
 		return &types.IndexTaskMetrics{TotalFiles: 0}, nil
 	}
-	// 校验文件时间戳和索引时间戳，比对需要索引
-	filterStart := time.Now()
-	needIndexFiles := idx.filterSourceFilesByTimestamp(ctx, projectUuid, sourceFileTimestamps)
-	// gc
-	sourceFileTimestamps = nil
-
-	filteredCnt := totalFilesCnt - len(needIndexFiles)
-
-	idx.logger.Info("workspace %s filter files by timestamp cost %d ms, total %d files, remaining %d files, filtered %d files.", workspacePath,
-		time.Since(filterStart).Milliseconds(), totalFilesCnt, len(needIndexFiles), filteredCnt)
+	var needIndexFiles []*types.FileWithModTimestamp
+	var forceReparsedCnt int
+	filteredCnt := 0
+	if force {
+		// 强制重建：不过滤时间戳未变的文件，并清理已不存在文件的索引
+		staleFiles, unchangedCnt := idx.scanIndexedFiles(ctx, projectUuid, sourceFileTimestamps)
+		if fileLimitHit {
+			// 收集结果被文件数上限截断，未收集到的文件不一定已删除，只清理确认不存在的文件
+			staleFiles = filterMissingFiles(staleFiles)
+		}
+		if len(staleFiles) > 0 {
+			removed, err := idx.removeIndexByFilePaths(ctx, projectUuid, staleFiles)
+			if err != nil {
+				idx.logger.Error("remove project %s stale file indexes err: %v", project.Path, err)
+			}
+			idx.logger.Info("project %s removed %d stale file indexes", project.Path, removed)
+		}
+		forceReparsedCnt = unchangedCnt
+		needIndexFiles = make([]*types.FileWithModTimestamp, 0, len(sourceFileTimestamps))
+		for k, v := range sourceFileTimestamps {
+			needIndexFiles = append(needIndexFiles, &types.FileWithModTimestamp{Path: k, ModTime: v})
+		}
+		sourceFileTimestamps = nil
+	} else {
+		// 校验文件时间戳和索引时间戳，比对需要索引
+		filterStart := time.Now()
+		needIndexFiles = idx.filterSourceFilesByTimestamp(ctx, projectUuid, sourceFileTimestamps)
+		// gc
+		sourceFileTimestamps = nil
+
+		filteredCnt = totalFilesCnt - len(needIndexFiles)
+
+		idx.logger.Info("workspace %s filter files by timestamp cost %d ms, total %d files, remaining %d files, filtered %d files.", workspacePath,
+			time.Since(filterStart).Milliseconds(), totalFilesCnt, len(needIndexFiles), filteredCnt)
+	}
 
 	// 阶段1-3：批量处理文件（解析、检查、保存符号表）
 	batchParams := &BatchProcessingParams{
@@ -129,11 +305,20 @@ func (idx *Indexer) indexProject(ctx context.Context, workspacePath string, proj
 		batchResult.ProjectMetrics.TotalSavedVariables,
 	)
 
+	batchResult.ProjectMetrics.TotalForceReparsedFiles = forceReparsedCnt
+	if fileLimitHit {
+		batchResult.ProjectMetrics.Truncated = true
+		batchResult.ProjectMetrics.FileLimitHit = true
+		batchResult.ProjectMetrics.MaxFilesLimit = maxFiles
+	}
+	if err := idx.saveProjectMeta(ctx, project); err != nil {
+		idx.logger.Error("save project %s meta err: %v", project.Path, err)
+	}
 	return batchResult.ProjectMetrics, nil
 }
 
-// filterSourceFilesByTimestamp 根据时间戳过滤需要索引的文件
-func (idx *Indexer) filterSourceFilesByTimestamp(ctx context.Context, projectUuid string, sourceFileTimestamps map[string]int64) []*types.FileWithModTimestamp {
+// scanIndexedFiles 扫描项目已有的文件索引，返回已不存在（不在本次收集结果中）的文件，以及时间戳未变化的文件数
+func (idx *Indexer) scanIndexedFiles(ctx context.Context, projectUuid string, sourceFileTimestamps map[string]int64) ([]string, int) {
 	iter := idx.storage.Iter(ctx, projectUuid)
 	defer func(iter store.Iterator) {
 		err := iter.Close()
@@ -141,6 +326,13 @@ func (idx *Indexer) filterSourceFilesByTimestamp(ctx context.Context, projectUui
 			idx.logger.Error("project %s iter close err: %v", projectUuid, err)
 		}
 	}(iter)
+	// 索引中的路径为 / 分隔符，收集到的文件路径为系统分隔符
+	storagePathTimestamps := make(map[string]int64, len(sourceFileTimestamps))
+	for path, timestamp := range sourceFileTimestamps {
+		storagePathTimestamps[utils.ToStoragePath(path)] = timestamp
+	}
+	var staleFiles []string
+	var unchangedCnt int
 	for iter.Next() {
 		if !store.IsElementPathKey(iter.Key()) {
 			continue
@@ -150,8 +342,9 @@ func (idx *Indexer) filterSourceFilesByTimestamp(ctx context.Context, projectUui
 			idx.logger.Error("convert key %s to element_path_key err:%v", iter.Key(), err)
 			continue
 		}
-		fileTimestamp, ok := sourceFileTimestamps[key.Path]
+		fileTimestamp, ok := storagePathTimestamps[key.Path]
 		if !ok {
+			staleFiles = append(staleFiles, key.Path)
 			continue
 		}
 		var elementTable codegraphpb.FileElementTable
@@ -160,17 +353,110 @@ func (idx *Indexer) filterSourceFilesByTimestamp(ctx context.Context, projectUui
 			continue
 		}
 		if elementTable.Timestamp == fileTimestamp {
-			delete(sourceFileTimestamps, key.Path)
+			unchangedCnt++
 		}
 	}
+	return staleFiles, unchangedCnt
+}
 
-	needIndexFiles := make([]*types.FileWithModTimestamp, 0, len(sourceFileTimestamps))
-	for k, v := range sourceFileTimestamps {
-		needIndexFiles = append(needIndexFiles, &types.FileWithModTimestamp{Path: k, ModTime: v})
+// filterMissingFiles 返回磁盘上已不存在的文件，文件数上限截断收集结果时用于确认过期索引
+func filterMissingFiles(filePaths []string) []string {
+	missing := make([]string, 0, len(filePaths))
+	for _, path := range filePaths {
+		if _, err := os.Stat(filepath.FromSlash(path)); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
+
+// filterLookupBatchSize 每次 BatchGet 查询的候选文件数，限制一次读入内存的元素表数量
+const filterLookupBatchSize = 1000
+
+// elementTableTimestampField FileElementTable 时间戳字段的字段号，过滤时只解码该字段
+var elementTableTimestampField = (&codegraphpb.FileElementTable{}).ProtoReflect().Descriptor().
+	Fields().ByName("timestamp").Number()
+
+// filterSourceFilesByTimestamp 根据时间戳过滤需要索引的文件，未建立索引的文件需要索引。
+// 只按候选文件的元素表 key 查询，不遍历项目的其它索引，并且只解码元素表的时间戳字段
+func (idx *Indexer) filterSourceFilesByTimestamp(ctx context.Context, projectUuid string, sourceFileTimestamps map[string]int64) []*types.FileWithModTimestamp {
+	upToDate := idx.upToDateFilesByLookup(ctx, projectUuid, sourceFileTimestamps)
+	needIndexFiles := make([]*types.FileWithModTimestamp, 0, len(sourceFileTimestamps)-len(upToDate))
+	for path, fileTimestamp := range sourceFileTimestamps {
+		if _, ok := upToDate[path]; !ok {
+			needIndexFiles = append(needIndexFiles, &types.FileWithModTimestamp{Path: path, ModTime: fileTimestamp})
+		}
 	}
 	return needIndexFiles
 }
 
+// upToDateFilesByLookup 按候选文件的元素表 key 分批 BatchGet，返回索引时间戳与文件时间戳一致的文件。
+// key 中的路径统一转换为存储形式（/ 分隔符），系统分隔符的候选路径同样可以命中
+func (idx *Indexer) upToDateFilesByLookup(ctx context.Context, projectUuid string,
+	sourceFileTimestamps map[string]int64) map[string]struct{} {
+	upToDate := make(map[string]struct{}, len(sourceFileTimestamps))
+	paths := make([]string, 0, filterLookupBatchSize)
+	keys := make([]store.Key, 0, filterLookupBatchSize)
+	lookup := func() {
+		values, err := idx.storage.BatchGet(ctx, projectUuid, keys)
+		if err != nil {
+			idx.logger.Error("batch get project %s element_tables err:%v", projectUuid, err)
+		}
+		for i, value := range values {
+			if value != nil && idx.isElementTableUpToDate(paths[i], value, sourceFileTimestamps[paths[i]]) {
+				upToDate[paths[i]] = struct{}{}
+			}
+		}
+		paths, keys = paths[:0], keys[:0]
+	}
+	for path := range sourceFileTimestamps {
+		// 只按扩展名推断候选 key，不逐个读取文件，没有扩展名的脚本任一候选命中即可
+		candidates, err := candidateElementPathKeys(path)
+		if err != nil {
+			continue
+		}
+		for _, key := range candidates {
+			paths = append(paths, path)
+			keys = append(keys, key)
+		}
+		if len(keys) >= filterLookupBatchSize {
+			lookup()
+		}
+	}
+	if len(keys) > 0 {
+		lookup()
+	}
+	return upToDate
+}
+
+// isElementTableUpToDate 元素表的索引时间戳与文件时间戳一致，只解码时间戳字段
+func (idx *Indexer) isElementTableUpToDate(path string, value []byte, fileTimestamp int64) bool {
+	for len(value) > 0 {
+		num, typ, n := protowire.ConsumeTag(value)
+		if n < 0 {
+			idx.logger.Error("unmarshal file %s element_table value err:%v", path, protowire.ParseError(n))
+			return false
+		}
+		value = value[n:]
+		if num == elementTableTimestampField && typ == protowire.VarintType {
+			timestamp, n := protowire.ConsumeVarint(value)
+			if n < 0 {
+				idx.logger.Error("unmarshal file %s element_table value err:%v", path, protowire.ParseError(n))
+				return false
+			}
+			return int64(timestamp) == fileTimestamp
+		}
+		n = protowire.ConsumeFieldValue(num, typ, value)
+		if n < 0 {
+			idx.logger.Error("unmarshal file %s element_table value err:%v", path, protowire.ParseError(n))
+			return false
+		}
+		value = value[n:]
+	}
+	// 时间戳为默认值 0 时不会编码
+	return fileTimestamp == 0
+}
+
 // preprocessImports 预处理（过滤、转换分隔符）
 func (idx *Indexer) preprocessImports(ctx context.Context, elementTables []*parser.FileElementTable,
 	project *workspace.Project) error {
@@ -189,7 +475,10 @@ func (idx *Indexer) preprocessImports(ctx context.Context, elementTables []*pars
 // IndexFiles 根据工作区路径、文件路径，批量保存索引
 func (idx *Indexer) IndexFiles(ctx context.Context, workspacePath string, filePaths []string) error {
 	start := time.Now()
+	workspacePath = utils.FileURIToPath(workspacePath)
+	filePaths = normalizeFilePaths(workspacePath, filePaths)
 	idx.logger.Info("start to index workspace %s projectFiles: %v", workspacePath, filePaths)
+	defer idx.lockWorkspace(workspacePath)()
 	exists, err := idx.workspaceReader.Exists(ctx, workspacePath)
 	if err == nil && !exists {
 		return fmt.Errorf("workspace path %s not exists", workspacePath)
@@ -222,10 +511,10 @@ func (idx *Indexer) IndexFiles(ctx context.Context, workspacePath string, filePa
 			continue
 		}
 
-		if idx.storage.Size(ctx, projectUuid, store.PathKeySystemPrefix) == 0 {
-			idx.logger.Info("project %s has not indexed yet, index project.", projectUuid)
-			// 如果项目没有索引过，索引整个项目
-			_, err := idx.indexProject(ctx, workspacePath, project)
+		if idx.storage.Size(ctx, projectUuid, store.PathKeySystemPrefix) == 0 || idx.isSchemaOutdated(ctx, projectUuid) {
+			idx.logger.Info("project %s has not indexed yet or index schema outdated, index project.", projectUuid)
+			// 如果项目没有索引过或索引版本过期，索引整个项目
+			_, err := idx.indexProject(ctx, workspacePath, project, false)
 			if err != nil {
 				idx.logger.Error("index project %s err: %v", projectUuid, utils.TruncateError(errors.Join(err...)))
 				errs = append(errs, err...)
@@ -248,6 +537,7 @@ func (idx *Indexer) IndexFiles(ctx context.Context, workspacePath string, filePa
 				PreviousFileNum:      workspaceModel.FileNum,
 				Concurrency:          idx.config.MaxConcurrency,
 				BatchSize:            idx.config.MaxBatchSize,
+				CleanupStaleSymbols:  true,
 			}
 
 			batchResult, err := idx.indexFilesInBatches(ctx, batchParams)
@@ -271,6 +561,12 @@ func (idx *Indexer) IndexFiles(ctx context.Context, workspacePath string, filePa
 
 // parseFiles 解析文件
 func (idx *Indexer) parseFiles(ctx context.Context, files []*types.FileWithModTimestamp) ([]*parser.FileElementTable, *types.IndexTaskMetrics, error) {
+	return idx.parseFilesWithContents(ctx, files, nil)
+}
+
+// parseFilesWithContents 解析文件，contents 中有对应路径的内容时直接使用，不再读取磁盘
+func (idx *Indexer) parseFilesWithContents(ctx context.Context, files []*types.FileWithModTimestamp,
+	contents map[string][]byte) ([]*parser.FileElementTable, *types.IndexTaskMetrics, error) {
 	totalFiles := len(files)
 
 	// 优化：预分配切片容量，减少动态扩容
@@ -289,20 +585,41 @@ func (idx *Indexer) parseFiles(ctx context.Context, files []*types.FileWithModTi
 		}
 
 		// 直接读取文件并解析，避免不必要的中间变量
-		content, err := idx.workspaceReader.ReadFile(ctx, f.Path, types.ReadOptions{})
+		content, ok := contents[f.Path]
+		if !ok {
+			content, err = idx.workspaceReader.ReadFile(ctx, f.Path, types.ReadOptions{})
+		}
 		if err != nil {
+			// 无权限或收集后被删除的文件跳过，不计为失败
+			if reason := unreadableReason(err); reason != types.EmptyString {
+				addSkippedFile(projectTaskMetrics, f.Path, reason)
+				idx.logger.Debug("skip file %s parsing, reason: %s", f.Path, reason)
+				continue
+			}
 			projectTaskMetrics.TotalFailedFiles++
 			projectTaskMetrics.FailedFilePaths = append(projectTaskMetrics.FailedFilePaths, f.Path)
 			idx.logger.Debug("read file %s err:%v", f, err)
 			continue
 		}
+		// 空文件没有符号，不解析也不保存空的元素表
+		if len(content) == 0 {
+			addSkippedFile(projectTaskMetrics, f.Path, SkipReasonEmpty)
+			idx.logger.Debug("skip file %s parsing, reason: %s", f.Path, SkipReasonEmpty)
+			continue
+		}
+		content, skipReason := normalizeEncoding(content, idx.config.SourceEncoding)
+		if skipReason != types.EmptyString {
+			addSkippedFile(projectTaskMetrics, f.Path, skipReason)
+			idx.logger.Debug("skip file %s parsing, reason: %s", f.Path, skipReason)
+			continue
+		}
 		// 创建源文件对象并解析
 		sourceFile := &types.SourceFile{
 			Path:    f.Path,
 			Content: content,
 		}
 
-		fileElementTable, err := idx.parser.Parse(ctx, sourceFile)
+		fileElementTable, err := idx.parseFile(ctx, sourceFile)
 		if err != nil {
 			projectTaskMetrics.TotalFailedFiles++
 			projectTaskMetrics.FailedFilePaths = append(projectTaskMetrics.FailedFilePaths, f.Path)
@@ -313,24 +630,45 @@ func (idx *Indexer) parseFiles(ctx context.Context, files []*types.FileWithModTi
 			continue
 		}
 		fileElementTable.Timestamp = f.ModTime
+		// 索引中的路径统一为 / 分隔符，与 key 保持一致
+		fileElementTable.Path = utils.ToStoragePath(fileElementTable.Path)
+		if fileElementTable.HasParseErrors {
+			projectTaskMetrics.TotalParseErrorFiles++
+			projectTaskMetrics.ParseErrorFilePaths = append(projectTaskMetrics.ParseErrorFilePaths, f.Path)
+		}
 		fileElementTables = append(fileElementTables, fileElementTable)
 	}
 
 	return fileElementTables, projectTaskMetrics, errors.Join(errs...)
 }
 
+// parseFile 在 ParseTimeout 内解析单个文件，超时后 tree-sitter 解析和后续的查询匹配都会中断，
+// 避免个别超大或异常文件拖住整个批次
+func (idx *Indexer) parseFile(ctx context.Context, sourceFile *types.SourceFile) (*parser.FileElementTable, error) {
+	timeout := idx.config.ParseTimeout
+	if timeout <= 0 {
+		timeout = DefaultParseTimeout
+	}
+	parseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	fileElementTable, err := idx.parser.Parse(parseCtx, sourceFile)
+	if err != nil && ctx.Err() == nil && errors.Is(parseCtx.Err(), context.DeadlineExceeded) {
+		idx.logger.Warn("parse file %s timeout after %v, skip it", sourceFile.Path, timeout)
+		return nil, fmt.Errorf("parse file %s timeout after %v: %w", sourceFile.Path, timeout, err)
+	}
+	return fileElementTable, err
+}
+
 // collectFiles 收集文件用于index
-func (idx *Indexer) collectFiles(ctx context.Context, workspacePath string, projectPath string) (map[string]int64, error) {
+// 返回文件及修改时间戳、生效的最大文件数，以及是否因达到该上限而丢弃了部分文件
+func (idx *Indexer) collectFiles(ctx context.Context, workspacePath string, projectPath string) (map[string]int64, int, bool, error) {
 	startTime := time.Now()
 	filePathModTimestamps := make(map[string]int64, 100)
 	ignoreConfig := idx.ignoreScanner.LoadIgnoreConfig(workspacePath)
 	if ignoreConfig == nil {
 		idx.logger.Error("collect source files ignore_config is nil")
 	}
-	visitPattern := idx.config.VisitPattern
-	if visitPattern == nil {
-		visitPattern = workspace.DefaultVisitPattern
-	}
+	visitPattern := idx.copyVisitPattern()
 	maxFiles := DefaultMaxFiles
 	if ignoreConfig != nil {
 		visitPattern.SkipFunc = func(fileInfo *types.FileInfo) (bool, error) {
@@ -338,40 +676,152 @@ func (idx *Indexer) collectFiles(ctx context.Context, workspacePath string, proj
 		}
 		maxFiles = ignoreConfig.MaxFileCount
 	}
+	if filter := types.IndexFilterFromContext(ctx); filter != nil {
+		visitPattern.SkipFunc = withIndexFilter(visitPattern.SkipFunc, workspacePath, filter)
+		idx.logger.Info("collect project %s source files with index filter, include %v, exclude %v",
+			projectPath, filter.Include, filter.Exclude)
+	}
+
+	if idx.config.SkipTestFiles {
+		visitPattern.SkipFunc = withSkipTestFiles(visitPattern.SkipFunc)
+	}
+
+	// 嵌套的子项目单独索引，不计入当前项目
+	if nestedRoots := workspace.NestedProjectRoots(projectPath, workspace.DefaultVisitPattern); len(nestedRoots) > 0 {
+		visitPattern.SkipFunc = withSkipDirs(visitPattern.SkipFunc, nestedRoots)
+		idx.logger.Info("collect project %s source files skip nested projects: %v", projectPath, nestedRoots)
+	}
 
 	// 从配置中获取(环境变量)
 	if idx.config.MaxFiles > 0 {
 		maxFiles = idx.config.MaxFiles
 	}
 
+	fileLimitHit := false
 	err := idx.workspaceReader.WalkFile(ctx, projectPath, func(walkCtx *types.WalkContext) error {
 		if walkCtx.Info.IsDir {
 			return nil
 		}
 		if len(filePathModTimestamps) >= maxFiles {
-			idx.logger.Info("collect source files max files %d reached, return.", maxFiles)
+			fileLimitHit = true
+			idx.logger.Warn("collect project %s source files max files %d reached, remaining files will not be indexed",
+				projectPath, maxFiles)
 			return filepath.SkipAll
 		}
 		filePathModTimestamps[walkCtx.Path] = walkCtx.Info.ModTime.Unix()
 		return nil
-	}, types.WalkOptions{IgnoreError: true, VisitPattern: visitPattern})
+	}, types.WalkOptions{IgnoreError: true, VisitPattern: visitPattern,
+		SkipSymlinks: idx.config.SkipSymlinks, FollowExternalSymlinks: idx.config.FollowExternalSymlinks,
+		Concurrency: idx.config.WalkConcurrency})
 
 	if err != nil {
-		return nil, err
+		return nil, maxFiles, false, err
 	}
 
 	idx.logger.Info("collect project source files finish. cost %d ms, found %d source files to index, max files limit %d",
 		time.Since(startTime).Milliseconds(), len(filePathModTimestamps), maxFiles)
 
-	return filePathModTimestamps, nil
+	return filePathModTimestamps, maxFiles, fileLimitHit, nil
 }
 
-// filterSourceFiles 根据规则过滤源文件
-func (idx *Indexer) filterSourceFiles(ctx context.Context, workspacePath string, files []string) []*types.FileWithModTimestamp {
+// SetIndexFilter 设置重建事件临时使用的 include/exclude 规则，不持久化，filter 为空时清除
+func (idx *Indexer) SetIndexFilter(eventId int64, filter *types.IndexFilter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	if filter.IsEmpty() {
+		idx.indexFilters.Delete(eventId)
+		return nil
+	}
+	idx.indexFilters.Store(eventId, filter)
+	return nil
+}
+
+// TakeIndexFilter 取出并清除重建事件的过滤规则，没有时返回 nil
+func (idx *Indexer) TakeIndexFilter(eventId int64) *types.IndexFilter {
+	value, ok := idx.indexFilters.LoadAndDelete(eventId)
+	if !ok {
+		return nil
+	}
+	return value.(*types.IndexFilter)
+}
+
+// withIndexFilter 在已有跳过规则基础上合并 include/exclude 规则。
+// exclude 对文件和目录都生效，include 只作用于文件，目录需要继续遍历才能找到匹配的文件
+func withIndexFilter(skip types.SkipFunc, workspacePath string, filter *types.IndexFilter) types.SkipFunc {
+	var include, exclude *gitignore.GitIgnore
+	if len(filter.Include) > 0 {
+		include = gitignore.CompileIgnoreLines(filter.Include...)
+	}
+	if len(filter.Exclude) > 0 {
+		exclude = gitignore.CompileIgnoreLines(filter.Exclude...)
+	}
+	return func(fileInfo *types.FileInfo) (bool, error) {
+		if skip != nil {
+			if skipped, err := skip(fileInfo); skipped || err != nil {
+				return skipped, err
+			}
+		}
+		relPath, err := filepath.Rel(workspacePath, fileInfo.Path)
+		if err != nil {
+			return false, nil
+		}
+		relPath = filepath.ToSlash(relPath)
+		if fileInfo.IsDir {
+			return exclude != nil && exclude.MatchesPath(relPath+"/"), nil
+		}
+		if exclude != nil && exclude.MatchesPath(relPath) {
+			return true, nil
+		}
+		return include != nil && !include.MatchesPath(relPath), nil
+	}
+}
+
+// withSkipTestFiles 在已有跳过规则基础上跳过按语言约定识别的测试文件
+func withSkipTestFiles(skip types.SkipFunc) types.SkipFunc {
+	return func(fileInfo *types.FileInfo) (bool, error) {
+		if skip != nil {
+			if skipped, err := skip(fileInfo); skipped || err != nil {
+				return skipped, err
+			}
+		}
+		return !fileInfo.IsDir && lang.IsTestFile(fileInfo.Path), nil
+	}
+}
+
+// withSkipDirs 在原有跳过规则的基础上跳过指定目录
+func withSkipDirs(skip types.SkipFunc, dirs []string) types.SkipFunc {
+	skipDirs := make(map[string]struct{}, len(dirs))
+	for _, dir := range dirs {
+		skipDirs[filepath.Clean(dir)] = struct{}{}
+	}
+	return func(fileInfo *types.FileInfo) (bool, error) {
+		if skip != nil {
+			if skipped, err := skip(fileInfo); skipped || err != nil {
+				return skipped, err
+			}
+		}
+		if !fileInfo.IsDir {
+			return false, nil
+		}
+		_, ok := skipDirs[filepath.Clean(fileInfo.Path)]
+		return ok, nil
+	}
+}
+
+// copyVisitPattern 复制访问规则，设置 SkipFunc 时不影响共享的默认规则
+func (idx *Indexer) copyVisitPattern() *types.VisitPattern {
 	visitPattern := idx.config.VisitPattern
 	if visitPattern == nil {
 		visitPattern = workspace.DefaultVisitPattern
 	}
+	copied := *visitPattern
+	return &copied
+}
+
+// filterSourceFiles 根据规则过滤源文件
+func (idx *Indexer) filterSourceFiles(ctx context.Context, workspacePath string, files []string) []*types.FileWithModTimestamp {
+	visitPattern := idx.copyVisitPattern()
 	ignoreConfig := idx.ignoreScanner.LoadIgnoreConfig(workspacePath)
 	maxFilesLimit := DefaultMaxFiles
 	if ignoreConfig != nil {
@@ -1,23 +1,183 @@
 package indexer
 
 import (
+	"codebase-indexer/internal/model"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/pkg/codegraph/analyzer"
+	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFilterSourceFilesByTimestamp(t *testing.T) {
-	// 这个测试需要mock存储层，这里提供基本的结构测试
-	sourceFileTimestamps := map[string]int64{
-		"/test/file1.go": 123456,
-		"/test/file2.go": 123457,
-		"/test/file3.go": 123458,
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	dir := t.TempDir()
+	projectUuid := "test-project"
+
+	unchanged := filepath.Join(dir, "unchanged.go")
+	modified := filepath.Join(dir, "modified.go")
+	notIndexed := filepath.Join(dir, "new.go")
+	unsupported := filepath.Join(dir, "readme.unknown")
+	for _, f := range []string{unchanged, modified} {
+		saveTestFileElementTable(t, storage, projectUuid, lang.Go, f, "package main\n", []*codegraphpb.Element{
+			{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{0, 0, 0, 1}},
+		})
+	}
+	stamped := filepath.Join(dir, "stamped.go")
+	require.NoError(t, storage.Put(ctx, projectUuid, &store.Entry{
+		Key:   store.ElementPathKey{Language: lang.Go, Path: stamped},
+		Value: &codegraphpb.FileElementTable{Path: stamped, Language: string(lang.Go), Timestamp: 400},
+	}))
+	// Windows 路径，索引中以 / 分隔符存储
+	windowsUnchanged, windowsModified := `C:\ws\pkg\win.go`, `C:\ws\pkg\win_modified.go`
+	for _, f := range []string{windowsUnchanged, windowsModified} {
+		require.NoError(t, storage.Put(ctx, projectUuid, &store.Entry{
+			Key:   store.ElementPathKey{Language: lang.Go, Path: f},
+			Value: &codegraphpb.FileElementTable{Path: utils.ToStoragePath(f), Language: string(lang.Go), Timestamp: 500},
+		}))
+	}
+
+	tests := []struct {
+		name                 string
+		sourceFileTimestamps map[string]int64
+		wantUpToDate         map[string]struct{}
+		wantNeedIndex        map[string]int64
+	}{
+		{
+			// 保存的元素表时间戳为0
+			name: "按时间戳过滤",
+			sourceFileTimestamps: map[string]int64{
+				unchanged:   0,
+				modified:    100,
+				notIndexed:  200,
+				unsupported: 300,
+				stamped:     400,
+			},
+			wantUpToDate:  map[string]struct{}{unchanged: {}, stamped: {}},
+			wantNeedIndex: map[string]int64{modified: 100, notIndexed: 200, unsupported: 300},
+		},
+		{
+			name:                 "反斜杠路径命中以存储形式保存的索引",
+			sourceFileTimestamps: map[string]int64{windowsUnchanged: 500, windowsModified: 600},
+			wantUpToDate:         map[string]struct{}{windowsUnchanged: {}},
+			wantNeedIndex:        map[string]int64{windowsModified: 600},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantUpToDate, idx.upToDateFilesByLookup(ctx, projectUuid, tt.sourceFileTimestamps))
+			got := make(map[string]int64)
+			for _, f := range idx.filterSourceFilesByTimestamp(ctx, projectUuid, tt.sourceFileTimestamps) {
+				got[f.Path] = f.ModTime
+			}
+			assert.Equal(t, tt.wantNeedIndex, got)
+		})
 	}
 
-	// 测试map不为空
-	assert.Len(t, sourceFileTimestamps, 3)
-	assert.Contains(t, sourceFileTimestamps, "/test/file1.go")
+	t.Run("候选文件超过一批时分批查询", func(t *testing.T) {
+		sourceFileTimestamps := make(map[string]int64, filterLookupBatchSize+10)
+		for i := 0; i < filterLookupBatchSize+10; i++ {
+			path := filepath.Join(dir, "batch", fmt.Sprintf("file%d.go", i))
+			sourceFileTimestamps[path] = 1
+			if i%2 == 0 {
+				require.NoError(t, storage.Put(ctx, projectUuid, &store.Entry{
+					Key:   store.ElementPathKey{Language: lang.Go, Path: path},
+					Value: &codegraphpb.FileElementTable{Path: path, Language: string(lang.Go), Timestamp: 1},
+				}))
+			}
+		}
+		assert.Len(t, idx.upToDateFilesByLookup(ctx, projectUuid, sourceFileTimestamps), (filterLookupBatchSize+10)/2)
+	})
+}
+
+// filterSourceFilesByFullScan 遍历项目全部 key 并完整解码元素表的时间戳过滤，作为基准对比
+func filterSourceFilesByFullScan(ctx context.Context, idx *Indexer, projectUuid string, sourceFileTimestamps map[string]int64) int {
+	iter := idx.storage.Iter(ctx, projectUuid)
+	defer iter.Close()
+	unchanged := 0
+	for iter.Next() {
+		if !store.IsElementPathKey(iter.Key()) {
+			continue
+		}
+		key, err := store.ToElementPathKey(iter.Key())
+		if err != nil {
+			continue
+		}
+		fileTimestamp, ok := sourceFileTimestamps[key.Path]
+		if !ok {
+			continue
+		}
+		var elementTable codegraphpb.FileElementTable
+		if err = store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
+			continue
+		}
+		if elementTable.Timestamp == fileTimestamp {
+			unchanged++
+		}
+	}
+	return len(sourceFileTimestamps) - unchanged
+}
+
+// BenchmarkFilterSourceFilesByTimestamp 大项目（20000 个文件及其符号）中少量文件变更时的过滤耗时。
+// 候选文件与实际索引一致，是收集到的项目全部文件
+func BenchmarkFilterSourceFilesByTimestamp(b *testing.B) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(b)
+	projectUuid := "bench-project"
+	const totalFiles, changedFiles, symbolsPerFile = 20000, 20, 5
+
+	sourceFileTimestamps := make(map[string]int64, totalFiles)
+	for i := 0; i < totalFiles; i++ {
+		path := fmt.Sprintf("/bench/pkg%d/file%d.go", i%50, i)
+		elements := make([]*codegraphpb.Element, 0, symbolsPerFile)
+		for j := 0; j < symbolsPerFile; j++ {
+			name := fmt.Sprintf("Symbol%d_%d", i, j)
+			elements = append(elements, &codegraphpb.Element{Name: name, IsDefinition: true,
+				ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{int32(j), 0, int32(j), 10}})
+			require.NoError(b, storage.Put(ctx, projectUuid, &store.Entry{
+				Key:   store.SymbolNameKey{Language: lang.Go, Name: name},
+				Value: &codegraphpb.SymbolOccurrence{Name: name, Language: string(lang.Go)},
+			}))
+		}
+		require.NoError(b, storage.Put(ctx, projectUuid, &store.Entry{
+			Key:   store.ElementPathKey{Language: lang.Go, Path: path},
+			Value: &codegraphpb.FileElementTable{Path: path, Language: string(lang.Go), Timestamp: 1, Elements: elements},
+		}))
+		sourceFileTimestamps[path] = 1
+		if i%(totalFiles/changedFiles) == 0 {
+			sourceFileTimestamps[path] = 2
+		}
+	}
+	b.ResetTimer()
+
+	b.Run("批量查询", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			needIndexFiles := idx.filterSourceFilesByTimestamp(ctx, projectUuid, sourceFileTimestamps)
+			require.Len(b, needIndexFiles, changedFiles)
+		}
+	})
+	b.Run("全量遍历", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			require.Equal(b, changedFiles, filterSourceFilesByFullScan(ctx, idx, projectUuid, sourceFileTimestamps))
+		}
+	})
 }
 
 func TestBatchProcessParams_Validation(t *testing.T) {
@@ -115,13 +275,225 @@ func TestPreprocessImports(t *testing.T) {
 }
 
 func TestCollectFiles(t *testing.T) {
-	// 这个测试需要完整的文件系统访问
-	t.Skip("需要完整的文件系统环境")
+	ctx := context.Background()
+	idx, _ := newTestIndexerWithStorage(t)
+	idx.ignoreScanner = repository.NewFileScanner(idx.logger)
+	workspaceDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte("package main\n"), 0644))
+	}
+
+	tests := []struct {
+		name         string
+		maxFiles     int
+		wantFiles    int
+		wantLimitHit bool
+	}{
+		{name: "未达到上限", maxFiles: 3, wantFiles: 3, wantLimitHit: false},
+		{name: "达到上限截断", maxFiles: 2, wantFiles: 2, wantLimitHit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.config.MaxFiles = tt.maxFiles
+			files, maxFiles, limitHit, err := idx.collectFiles(ctx, workspaceDir, workspaceDir)
+			require.NoError(t, err)
+			assert.Len(t, files, tt.wantFiles)
+			assert.Equal(t, tt.maxFiles, maxFiles)
+			assert.Equal(t, tt.wantLimitHit, limitHit)
+		})
+	}
+}
+
+func TestCollectFilesSkipNestedProjects(t *testing.T) {
+	ctx := context.Background()
+	idx, _ := newTestIndexerWithStorage(t)
+	idx.ignoreScanner = repository.NewFileScanner(idx.logger)
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/root\n",
+		"main.go":          "package main\n",
+		"tools/go.mod":     "module example.com/root/tools\n",
+		"tools/gen.go":     "package tools\n",
+		"internal/util.go": "package internal\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	nestedDir := filepath.Join(workspaceDir, "tools")
+
+	tests := []struct {
+		name        string
+		projectPath string
+		want        []string
+	}{
+		{name: "外层项目跳过嵌套模块", projectPath: workspaceDir,
+			want: []string{filepath.Join(workspaceDir, "internal", "util.go"), filepath.Join(workspaceDir, "main.go")}},
+		{name: "嵌套模块收集自身文件", projectPath: nestedDir, want: []string{filepath.Join(nestedDir, "gen.go")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collected, _, _, err := idx.collectFiles(ctx, workspaceDir, tt.projectPath)
+			require.NoError(t, err)
+			var paths []string
+			for path := range collected {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			assert.Equal(t, tt.want, paths)
+		})
+	}
+}
+
+func TestCollectFilesShebangScripts(t *testing.T) {
+	ctx := context.Background()
+	idx, _ := newTestIndexerWithStorage(t)
+	idx.ignoreScanner = repository.NewFileScanner(idx.logger)
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"main.go": "package main\n",
+		"manage":  "#!/usr/bin/env python3\nprint('hi')\n",
+		"deploy":  "#!/bin/bash\necho hi\n",
+		"notes":   "plain text\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte(content), 0755))
+	}
+
+	collected, _, _, err := idx.collectFiles(ctx, workspaceDir, workspaceDir)
+	require.NoError(t, err)
+	assert.Len(t, collected, 2)
+	assert.Contains(t, collected, filepath.Join(workspaceDir, "main.go"))
+	assert.Contains(t, collected, filepath.Join(workspaceDir, "manage"))
+}
+
+// newTestIndexingIndexer 创建可完整执行索引流程的索引器，并在工作区仓库中登记 workspaceDir
+func newTestIndexingIndexer(t *testing.T, workspaceDir string) (*Indexer, *store.LevelDBStorage) {
+	t.Helper()
+	idx, storage := newTestIndexerWithStorage(t)
+	idx.ignoreScanner = repository.NewFileScanner(idx.logger)
+	idx.parser = parser.NewSourceFileParser(idx.logger)
+	idx.analyzer = analyzer.NewDependencyAnalyzer(idx.logger, packageclassifier.NewPackageClassifier(),
+		idx.workspaceReader, storage)
+	idx.config.MaxProjects = 1
+	idx.config.MaxConcurrency = 1
+	idx.config.MaxBatchSize = 10
+	workspaceRepo := newTestWorkspaceRepository(t, idx)
+	require.NoError(t, workspaceRepo.CreateWorkspace(&model.Workspace{
+		WorkspaceName: filepath.Base(workspaceDir),
+		WorkspacePath: workspaceDir,
+		Active:        "true",
+	}))
+	return idx, storage
+}
+
+func TestIndexWorkspace_FileLimitReported(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte("package main\n"), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+
+	tests := []struct {
+		name          string
+		maxFiles      int
+		wantTruncated bool
+		wantLimit     int
+	}{
+		{name: "达到上限时报告索引不完整", maxFiles: 2, wantTruncated: true, wantLimit: 2},
+		{name: "调大上限后不再报告", maxFiles: 10, wantTruncated: false, wantLimit: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.config.MaxFiles = tt.maxFiles
+			metrics, err := idx.IndexWorkspace(ctx, workspaceDir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTruncated, metrics.Truncated)
+			assert.Equal(t, tt.wantTruncated, metrics.FileLimitHit)
+			assert.Equal(t, tt.wantLimit, metrics.MaxFilesLimit)
+
+			summary, err := idx.GetSummary(ctx, workspaceDir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTruncated, summary.Truncated)
+		})
+	}
+}
+
+func TestReindexWorkspace_FileLimitKeepsUncollectedFiles(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	names := []string{"a.go", "b.go", "c.go", "d.go"}
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte("package main\n"), 0644))
+	}
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	projectUuid := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir).Uuid
+
+	idx.config.MaxFiles = 10
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	// 删除一个文件后以低于文件数的上限强制重建
+	deleted := filepath.Join(workspaceDir, "d.go")
+	require.NoError(t, os.Remove(deleted))
+	idx.config.MaxFiles = 1
+	metrics, err := idx.ReindexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	require.True(t, metrics.FileLimitHit)
+
+	for _, name := range names {
+		path := filepath.ToSlash(filepath.Join(workspaceDir, name))
+		exists, err := storage.Exists(ctx, projectUuid, store.ElementPathKey{Language: lang.Go, Path: path})
+		require.NoError(t, err)
+		assert.Equal(t, name != "d.go", exists, name)
+	}
 }
 
 func TestParseFiles(t *testing.T) {
-	// 这个测试需要完整的parser依赖
-	t.Skip("需要完整的依赖注入环境")
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	normalFile := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, os.WriteFile(normalFile, []byte("package main\n\nfunc main() {}\n"), 0644))
+	// 足够大的文件，解析耗时远超超时时间
+	hugeFile := filepath.Join(workspaceDir, "huge.go")
+	var sb strings.Builder
+	sb.WriteString("package main\n\n")
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&sb, "func f%d(a, b int) int { if a > b { return a - b }; return f%d(b, a) }\n", i, i)
+	}
+	require.NoError(t, os.WriteFile(hugeFile, []byte(sb.String()), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+
+	tests := []struct {
+		name            string
+		parseTimeout    time.Duration
+		files           []string
+		wantTables      int
+		wantFailedPaths []string
+	}{
+		{name: "正常文件解析成功", parseTimeout: time.Minute, files: []string{normalFile}, wantTables: 1, wantFailedPaths: []string{}},
+		{name: "超时文件计为失败并继续", parseTimeout: 100 * time.Millisecond, files: []string{hugeFile, normalFile},
+			wantTables: 1, wantFailedPaths: []string{hugeFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.config.ParseTimeout = tt.parseTimeout
+			files := make([]*types.FileWithModTimestamp, 0, len(tt.files))
+			for _, f := range tt.files {
+				files = append(files, &types.FileWithModTimestamp{Path: f, ModTime: 1})
+			}
+			start := time.Now()
+			tables, metrics, err := idx.parseFiles(ctx, files)
+			require.NoError(t, err)
+			assert.Len(t, tables, tt.wantTables)
+			assert.Equal(t, tt.wantFailedPaths, metrics.FailedFilePaths)
+			assert.Equal(t, len(tt.wantFailedPaths), metrics.TotalFailedFiles)
+			// 超时后解析被中断，而不是等待整个文件解析完成
+			assert.Less(t, time.Since(start), 10*time.Second)
+		})
+	}
 }
 
 func TestIndexFilesInBatches(t *testing.T) {
@@ -165,3 +537,123 @@ func TestBatchCalculation(t *testing.T) {
 	}
 }
 
+func TestScanIndexedFiles(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	dir := t.TempDir()
+	projectUuid := "test-project"
+
+	unchanged := filepath.Join(dir, "unchanged.go")
+	modified := filepath.Join(dir, "modified.go")
+	deleted := filepath.Join(dir, "deleted.go")
+	for _, f := range []string{unchanged, modified, deleted} {
+		saveTestFileElementTable(t, storage, projectUuid, lang.Go, f, "package main\n",
+			[]*codegraphpb.Element{})
+	}
+	// 保存的元素表时间戳为0，modified 时间戳不同，deleted 不在本次收集结果中
+	sourceFileTimestamps := map[string]int64{
+		unchanged: 0,
+		modified:  100,
+	}
+
+	staleFiles, unchangedCnt := idx.scanIndexedFiles(ctx, projectUuid, sourceFileTimestamps)
+	assert.Equal(t, []string{deleted}, staleFiles)
+	assert.Equal(t, 1, unchangedCnt)
+	// 强制重建不修改收集结果
+	assert.Len(t, sourceFileTimestamps, 2)
+
+	removed, err := idx.removeIndexByFilePaths(ctx, projectUuid, staleFiles)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	staleFiles, _ = idx.scanIndexedFiles(ctx, projectUuid, sourceFileTimestamps)
+	assert.Empty(t, staleFiles)
+}
+
+func TestSelectProjects(t *testing.T) {
+	ctx := context.Background()
+	idx, _ := newTestIndexerWithStorage(t)
+	idx.config.MaxProjects = 2
+	workspaceDir := t.TempDir()
+
+	// 发现顺序为 c、a、d、b，文件数分别为 1、2、4、3
+	var projects []*workspace.Project
+	for _, p := range []struct {
+		name  string
+		files int
+	}{{"c", 1}, {"a", 2}, {"d", 4}, {"b", 3}} {
+		projectPath := filepath.Join(workspaceDir, p.name)
+		assert.NoError(t, os.MkdirAll(projectPath, 0755))
+		for i := 0; i < p.files; i++ {
+			assert.NoError(t, os.WriteFile(filepath.Join(projectPath, fmt.Sprintf("f%d.go", i)), []byte("package main\n"), 0644))
+		}
+		projects = append(projects, workspace.NewProject(p.name, projectPath))
+	}
+
+	tests := []struct {
+		name      string
+		strategy  ProjectSelectStrategy
+		allowlist []string
+		want      []string
+	}{
+		{name: "默认按发现顺序截断", strategy: ProjectSelectDefault, want: []string{"c", "a"}},
+		{name: "按字母序", strategy: ProjectSelectAlphabetical, want: []string{"a", "b"}},
+		{name: "按文件数从多到少", strategy: ProjectSelectLargestFirst, want: []string{"d", "b"}},
+		{name: "白名单", strategy: ProjectSelectAllowlist,
+			allowlist: []string{"b", filepath.Join(workspaceDir, "d")}, want: []string{"b", "d"}},
+		{name: "白名单忽略不存在的项目", strategy: ProjectSelectAllowlist,
+			allowlist: []string{"not-exists", "a"}, want: []string{"a"}},
+		{name: "白名单超过最大项目数", strategy: ProjectSelectAllowlist,
+			allowlist: []string{"a", "b", "c"}, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.config.ProjectSelectStrategy = tt.strategy
+			idx.config.ProjectAllowlist = tt.allowlist
+			selected := idx.selectProjects(ctx, workspaceDir, projects)
+			var names []string
+			for _, p := range selected {
+				names = append(names, p.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+	// 选择不改变原项目列表顺序
+	assert.Equal(t, "c", projects[0].Name)
+}
+
+// panickingStorage 保存指定项目的索引时 panic，模拟单个项目索引崩溃
+type panickingStorage struct {
+	store.GraphStorage
+	projectUuid string
+}
+
+func (s *panickingStorage) BatchSave(ctx context.Context, projectUuid string, values store.Entries) error {
+	if projectUuid == s.projectUuid {
+		panic("injected panic")
+	}
+	return s.GraphStorage.BatchSave(ctx, projectUuid, values)
+}
+
+func TestIndexWorkspace_RecoverProjectPanic(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	for _, name := range []string{"bad", "good"} {
+		projectDir := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".git"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "main.go"),
+			[]byte("package main\n\nfunc main() {}\n"), 0644))
+	}
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.MaxProjects = 2
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	badProject := workspace.NewProject("bad", filepath.Join(workspaceDir, "bad"))
+	goodProject := workspace.NewProject("good", filepath.Join(workspaceDir, "good"))
+	idx.storage = &panickingStorage{GraphStorage: storage, projectUuid: badProject.Uuid}
+
+	metrics, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{badProject.Path}, metrics.FailedProjects)
+	assert.Equal(t, 1, metrics.TotalFiles)
+	assert.Equal(t, 1, storage.Size(ctx, goodProject.Uuid, store.PathKeySystemPrefix))
+	assert.Equal(t, 0, storage.Size(ctx, badProject.Uuid, store.PathKeySystemPrefix))
+}
@@ -1,13 +1,19 @@
 package indexer
 
 import (
+	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/proto"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/codegraph/workspace"
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,6 +26,9 @@ const (
 	defaultMaxLayer = 3
 )
 
+// ErrIndexerClosing 索引器正在关闭，不再构建调用图反向索引
+var ErrIndexerClosing = errors.New("indexer is closing")
+
 // QueryCallGraph 获取符号定义代码块里面的调用图
 func (idx *Indexer) QueryCallGraph(ctx context.Context, opts *types.QueryCallGraphOptions) ([]*types.RelationNode, error) {
 	startTime := time.Now()
@@ -28,6 +37,12 @@ func (idx *Indexer) QueryCallGraph(ctx context.Context, opts *types.QueryCallGra
 	if opts.MaxLayer <= 0 {
 		opts.MaxLayer = defaultMaxLayer // 默认最大层数
 	}
+	// 节点数不超过服务端上限，未指定时按上限截断
+	if limit := idx.config.CallGraphMaxNodes; limit > 0 && (opts.MaxNodes <= 0 || opts.MaxNodes > limit) {
+		opts.MaxNodes = limit
+	}
+	opts.Workspace = utils.FileURIToPath(opts.Workspace)
+	opts.FilePath = utils.FileURIToPath(opts.FilePath)
 	// 支持相对路径，防止目录遍历攻击
 	if !filepath.IsAbs(opts.FilePath) {
 		absFilePath, err := safeFilePath(opts.Workspace, opts.FilePath)
@@ -36,14 +51,22 @@ func (idx *Indexer) QueryCallGraph(ctx context.Context, opts *types.QueryCallGra
 		}
 		opts.FilePath = absFilePath
 	}
+	filePath, err := utils.NormalizeFilePath(opts.Workspace, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	opts.FilePath = filePath
+	if opts.PathPrefix, err = utils.NormalizeFilePath(opts.Workspace, opts.PathPrefix); err != nil {
+		return nil, err
+	}
 
-	project, err := idx.GetProjectByFilePath(ctx, opts.Workspace, opts.FilePath)
+	project, err := idx.getQueryProject(ctx, opts.Workspace, opts.ProjectUuid, opts.FilePath)
 	if err != nil {
 		return nil, err
 	}
 	projectUuid := project.Uuid
 	defer func() {
-		idx.logger.Info("query callgraph cost %d ms", time.Since(startTime).Milliseconds())
+		idx.ctxLogger(ctx).Info("query callgraph cost %d ms", time.Since(startTime).Milliseconds())
 	}()
 
 	var results []*types.RelationNode
@@ -63,15 +86,15 @@ func (idx *Indexer) QueryCallGraph(ctx context.Context, opts *types.QueryCallGra
 			return nil, fmt.Errorf("line number format error: %s", opts.LineRange)
 		}
 		// 查询组合1：文件路径+行范围
-		startLine, endLine = NormalizeLineRange(startLine, endLine, 1000)
-		results, err = idx.queryCallGraphByLineRange(ctx, projectUuid, opts.Workspace, opts.FilePath, startLine, endLine, opts.MaxLayer)
+		startLine, endLine = NormalizeLineRange(startLine, endLine, lineLimitOrDefault(opts.MaxLineLimit, MaxCallGraphLineLimit))
+		results, err = idx.queryCallGraphByLineRange(ctx, projectUuid, opts, startLine, endLine)
 		return results, err
 	}
 	opts.SymbolName = strings.TrimSpace(opts.SymbolName)
 	// 根据查询类型处理
 	if opts.SymbolName != "" {
 		// 查询组合2：文件路径+符号名(类、函数)
-		results, err = idx.queryCallGraphBySymbol(ctx, projectUuid, opts.Workspace, opts.FilePath, opts.SymbolName, opts.MaxLayer)
+		results, err = idx.queryCallGraphBySymbol(ctx, projectUuid, opts)
 		return results, err
 	}
 
@@ -79,7 +102,8 @@ func (idx *Indexer) QueryCallGraph(ctx context.Context, opts *types.QueryCallGra
 }
 
 // queryCallGraphBySymbol 根据符号名查询调用链
-func (idx *Indexer) queryCallGraphBySymbol(ctx context.Context, projectUuid string, workspace, filePath, symbolName string, maxLayer int) ([]*types.RelationNode, error) {
+func (idx *Indexer) queryCallGraphBySymbol(ctx context.Context, projectUuid string, opts *types.QueryCallGraphOptions) ([]*types.RelationNode, error) {
+	filePath, symbolName := opts.FilePath, opts.SymbolName
 	// 查找符号定义
 	fileTable, err := idx.getFileElementTableByPath(ctx, projectUuid, filePath)
 	if err != nil {
@@ -88,58 +112,16 @@ func (idx *Indexer) queryCallGraphBySymbol(ctx context.Context, projectUuid stri
 	// 查询符号
 	foundSymbols := idx.querySymbolsByName(fileTable, &types.QueryReferenceOptions{SymbolName: symbolName})
 
-	// 检索符号定义
-	var definitions []*types.RelationNode
-	var calleeElements []*CalleeInfo
 	// 找定义节点，如函数、方法
-	for _, symbol := range foundSymbols {
-		// 根节点只能是函数、方法的定义
-		if !symbol.IsDefinition {
-			continue
-		}
-		if symbol.ElementType != codegraphpb.ElementType_METHOD &&
-			symbol.ElementType != codegraphpb.ElementType_FUNCTION {
-			continue
-		}
-		params, err := proto.GetParametersFromExtraData(symbol.ExtraData)
-		if err != nil {
-			idx.logger.Error("failed to get parameters from extra data, err: %v", err)
-			continue
-		}
-		isVariadic := false
-		paramCount := len(params)
-		if len(params) > 0 {
-			lastParam := params[paramCount-1]
-			if strings.Contains(lastParam.Name, VarVariadic) {
-				isVariadic = true
-				paramCount = paramCount - 1
-			}
-		}
-		position := types.ToPosition(symbol.Range)
-		node := &types.RelationNode{
-			SymbolName: symbol.Name,
-			FilePath:   filePath,
-			NodeType:   string(types.NodeTypeDefinition),
-			Position:   &position,
-			Children:   make([]*types.RelationNode, 0),
-		}
-		callee := &CalleeInfo{
-			SymbolName: symbol.Name,
-			FilePath:   filePath,
-			ParamCount: paramCount,
-			IsVariadic: isVariadic,
-			Position:   types.ToPosition(symbol.Range),
-		}
-		definitions = append(definitions, node)
-		calleeElements = append(calleeElements, callee)
-	}
+	definitions, calleeElements := idx.callGraphRoots(ctx, fileTable, foundSymbols, filePath)
 	visited := make(map[string]struct{})
-	idx.buildCallGraphBFS(ctx, projectUuid, workspace, definitions, calleeElements, maxLayer, visited)
+	idx.buildCallGraphBFS(ctx, projectUuid, opts, definitions, calleeElements, visited)
 	return definitions, nil
 }
 
 // queryCallGraphByLineRange 根据行范围查询调用链
-func (idx *Indexer) queryCallGraphByLineRange(ctx context.Context, projectUuid string, workspace string, filePath string, startLine, endLine, maxLayer int) ([]*types.RelationNode, error) {
+func (idx *Indexer) queryCallGraphByLineRange(ctx context.Context, projectUuid string, opts *types.QueryCallGraphOptions, startLine, endLine int) ([]*types.RelationNode, error) {
+	filePath := opts.FilePath
 	// 获取文件元素表
 	fileTable, err := idx.getFileElementTableByPath(ctx, projectUuid, filePath)
 	if err != nil {
@@ -152,21 +134,45 @@ func (idx *Indexer) queryCallGraphByLineRange(ctx context.Context, projectUuid s
 	foundSymbols := idx.findSymbolInDocByLineRange(ctx, fileTable, queryStartLine, queryEndLine)
 
 	// 提取调用函数或方法调用，构建调用图
+	definitions, calleeElements := idx.callGraphRoots(ctx, fileTable, foundSymbols, filePath)
+	visited := make(map[string]struct{})
+	idx.buildCallGraphBFS(ctx, projectUuid, opts, definitions, calleeElements, visited)
+
+	return definitions, nil
+}
+
+// callGraphRoots 从符号中选出调用图的根节点：函数、方法的定义，以及类的构造函数。
+// 类本身作为根节点时展开为其构造函数，没有显式构造函数时按默认构造函数查找实例化该类的调用
+func (idx *Indexer) callGraphRoots(ctx context.Context, fileTable *codegraphpb.FileElementTable,
+	symbols []*codegraphpb.Element, filePath string) ([]*types.RelationNode, []*CalleeInfo) {
 	var definitions []*types.RelationNode
 	var calleeElements []*CalleeInfo
-	for _, symbol := range foundSymbols {
-		// 根节点只能是函数、方法的定义
-		if !symbol.IsDefinition {
-			continue
-		}
-		if symbol.ElementType != codegraphpb.ElementType_METHOD &&
-			symbol.ElementType != codegraphpb.ElementType_FUNCTION {
-			continue
+	seen := make(map[string]struct{})
+	addRoot := func(symbol *codegraphpb.Element, callee *CalleeInfo) {
+		if _, ok := seen[callee.Key()]; ok {
+			return
 		}
+		seen[callee.Key()] = struct{}{}
+		position := types.ToPosition(symbol.Range)
+		definitions = append(definitions, &types.RelationNode{
+			SymbolName: symbol.Name,
+			FilePath:   filePath,
+			NodeType:   string(types.NodeTypeDefinition),
+			Position:   &position,
+			Children:   make([]*types.RelationNode, 0),
+		})
+		calleeElements = append(calleeElements, callee)
+	}
+	// addFunction 函数、方法作为根节点，构造函数按所属类名查找调用者
+	addFunction := func(symbol *codegraphpb.Element) {
 		params, err := proto.GetParametersFromExtraData(symbol.ExtraData)
 		if err != nil {
-			idx.logger.Error("failed to get parameters from extra data, err: %v", err)
-			continue
+			idx.ctxLogger(ctx).Error("failed to get parameters from extra data, err: %v", err)
+			return
+		}
+		className, err := proto.GetConstructorClassFromExtraData(symbol.ExtraData)
+		if err != nil {
+			idx.ctxLogger(ctx).Debug("failed to get constructor class from extra data, err: %v", err)
 		}
 		isVariadic := false
 		paramCount := len(params)
@@ -177,40 +183,99 @@ func (idx *Indexer) queryCallGraphByLineRange(ctx context.Context, projectUuid s
 				paramCount = paramCount - 1
 			}
 		}
-		position := types.ToPosition(symbol.Range)
-		node := &types.RelationNode{
-			SymbolName: symbol.Name,
-			FilePath:   filePath,
-			NodeType:   string(types.NodeTypeDefinition),
-			Position:   &position,
-			Children:   make([]*types.RelationNode, 0),
-		}
-		callee := &CalleeInfo{
+		addRoot(symbol, &CalleeInfo{
 			SymbolName: symbol.Name,
 			FilePath:   filePath,
 			ParamCount: paramCount,
 			IsVariadic: isVariadic,
 			Position:   types.ToPosition(symbol.Range),
+			ClassName:  className,
+		})
+	}
+
+	for _, symbol := range symbols {
+		// 根节点只能是函数、方法、类的定义
+		if !symbol.IsDefinition {
+			continue
+		}
+		switch symbol.ElementType {
+		case codegraphpb.ElementType_METHOD, codegraphpb.ElementType_FUNCTION:
+			addFunction(symbol)
+		case codegraphpb.ElementType_CLASS:
+			constructors := findConstructors(fileTable, symbol.Name)
+			for _, c := range constructors {
+				addFunction(c)
+			}
+			if len(constructors) > 0 {
+				continue
+			}
+			// 默认构造函数没有参数，Python 的类可能从父类继承 __init__，不限制参数个数
+			language := lang.Language(fileTable.Language)
+			addRoot(symbol, &CalleeInfo{
+				SymbolName: symbol.Name,
+				FilePath:   filePath,
+				IsVariadic: language == lang.Python,
+				Position:   types.ToPosition(symbol.Range),
+				ClassName:  symbol.Name,
+			})
 		}
-		definitions = append(definitions, node)
-		calleeElements = append(calleeElements, callee)
 	}
-	visited := make(map[string]struct{})
-	idx.buildCallGraphBFS(ctx, projectUuid, workspace, definitions, calleeElements, maxLayer, visited)
+	return definitions, calleeElements
+}
 
-	return definitions, nil
+// findConstructors 查找文件中类 className 的构造函数定义
+func findConstructors(fileTable *codegraphpb.FileElementTable, className string) []*codegraphpb.Element {
+	var constructors []*codegraphpb.Element
+	for _, e := range fileTable.Elements {
+		if !e.IsDefinition || e.ElementType != codegraphpb.ElementType_METHOD {
+			continue
+		}
+		if owner, err := proto.GetConstructorClassFromExtraData(e.ExtraData); err == nil && owner == className {
+			constructors = append(constructors, e)
+		}
+	}
+	return constructors
+}
+
+// constructsByPlainCall 语言中不带 new 的普通调用也会实例化类，如 Python 的 Foo()、C++ 的临时对象 Foo(1)；
+// 其他语言只有 new 表达式等显式的构造调用才关联到构造函数
+func constructsByPlainCall(language lang.Language) bool {
+	return language == lang.Python || language == lang.CPP
+}
+
+// isUnderPathPrefix 判断文件是否位于 pathPrefix 目录下或就是该文件，pathPrefix 为空时不限制
+func isUnderPathPrefix(filePath, pathPrefix string) bool {
+	if pathPrefix == types.EmptyString {
+		return true
+	}
+	filePath, pathPrefix = utils.ToStoragePath(filePath), utils.ToStoragePath(pathPrefix)
+	return filePath == pathPrefix || strings.HasPrefix(filePath, strings.TrimSuffix(pathPrefix, types.Slash)+types.Slash)
 }
 
-// buildCallGraphBFS 使用BFS层次遍历构建调用链
-func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, workspace string, rootNodes []*types.RelationNode, calleeInfos []*CalleeInfo, maxLayer int, visited map[string]struct{}) {
+// buildCallGraphBFS 使用BFS层次遍历构建调用链，PathPrefix 非空时只沿该路径下的调用者遍历，ExcludeTests 开启时跳过测试文件中的调用者，
+// DetectCycles 开启时将定义已在祖先路径上的调用者标记为 Cyclic 叶子节点，
+// IncludeVariables 开启时为每个展开的函数附加其引用的变量叶子节点。
+// 节点总数达到 MaxNodes 或耗时超过 Timeout 时停止展开，未展开完的节点标记为 Truncated
+func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, opts *types.QueryCallGraphOptions, rootNodes []*types.RelationNode, calleeInfos []*CalleeInfo, visited map[string]struct{}) {
+	workspace, maxLayer, pathPrefix := opts.Workspace, opts.MaxLayer, opts.PathPrefix
 	if len(rootNodes) == 0 || maxLayer <= 0 {
 		return
 	}
 
-	// 初始化队列，存储当前层的节点和对应的被调用元素
+	// 初始化队列，存储当前层的节点和对应的被调用元素，parent 用于判断是否成环
 	type layerNode struct {
 		node   *types.RelationNode
 		callee *CalleeInfo
+		parent *layerNode
+	}
+	// onPath 判断定义是否出现在 ln 到根节点的路径上
+	onPath := func(ln *layerNode, key string) bool {
+		for ; ln != nil; ln = ln.parent {
+			if ln.callee.Key() == key {
+				return true
+			}
+		}
+		return false
 	}
 
 	currentLayerNodes := make([]*layerNode, 0)
@@ -228,24 +293,77 @@ func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, w
 			})
 		}
 	}
-	// 构建反向索引映射：callee -> []caller
-	err := idx.buildCalleeMap(ctx, projectUuid)
+	// 构建反向索引映射：callee -> []caller，已构建时直接复用
+	err := idx.ensureCalleeMap(ctx, projectUuid)
 	if err != nil {
-		idx.logger.Error("failed to build callee map for write, err: %v", err)
+		idx.ctxLogger(ctx).Error("failed to build callee map for write, err: %v", err)
 		return
 	}
 	calleeMap, err := lru.New[string, []CallerInfo](MaxCalleeMapCacheCapacity / 2)
 	if err != nil {
-		idx.logger.Error("failed to create callee map cache, err: %v", err)
+		idx.ctxLogger(ctx).Error("failed to create callee map cache, err: %v", err)
 		return
 	}
+	// 同一次查询内复用文件元素表，每个文件最多从存储读取一次
+	fileTables := make(map[string]*codegraphpb.FileElementTable)
+
+	// languageOf 按扩展名推断语言，没有扩展名的脚本使用已索引元素表中的语言，不读取文件
+	languageOf := func(path string) lang.Language {
+		if language, err := lang.InferLanguageByExt(path); err == nil {
+			return language
+		}
+		if table, _ := idx.getCachedFileElementTable(ctx, projectUuid, path, fileTables); table != nil {
+			return lang.Language(table.Language)
+		}
+		return types.EmptyString
+	}
+
+	// 节点数和耗时预算，truncated 表示已达到上限，停止展开
+	nodeCount := len(rootNodes)
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+	truncated := false
+	// limitNodes 按剩余节点预算截断新增的子节点数，超出时标记父节点被截断
+	limitNodes := func(parent *types.RelationNode, n int) int {
+		if opts.MaxNodes <= 0 {
+			return n
+		}
+		if remaining := max(opts.MaxNodes-nodeCount, 0); n > remaining {
+			parent.Truncated = true
+			truncated = true
+			return remaining
+		}
+		return n
+	}
 	// BFS层次遍历
-	for layer := 0; layer < maxLayer && len(currentLayerNodes) > 0; layer++ {
+	for layer := 0; layer < maxLayer && len(currentLayerNodes) > 0 && !truncated; layer++ {
 		nextLayerNodes := make([]*layerNode, 0)
 		// 使用反向索引直接查找调用者
-		for _, ln := range currentLayerNodes {
-			// 构建callee的key
+		for k, ln := range currentLayerNodes {
+			if truncated || (opts.MaxNodes > 0 && nodeCount >= opts.MaxNodes) ||
+				(!deadline.IsZero() && time.Now().After(deadline)) {
+				// 当前层剩余的节点不再展开
+				truncated = true
+				for _, rest := range currentLayerNodes[k:] {
+					rest.node.Truncated = true
+				}
+				break
+			}
+			// 函数体内引用的变量作为叶子节点，不进入下一层
+			if opts.IncludeVariables {
+				leaves := idx.collectVariableLeaves(ctx, projectUuid, ln.callee, fileTables)
+				leaves = leaves[:limitNodes(ln.node, len(leaves))]
+				ln.node.Children = append(ln.node.Children, leaves...)
+				nodeCount += len(leaves)
+			}
+			// 构建callee的key，构造函数按类名查找构造调用
 			calleeKey := ln.callee.SymbolName
+			isConstructor := ln.callee.ClassName != types.EmptyString
+			if isConstructor {
+				calleeKey = ln.callee.ClassName
+			}
 
 			// 从反向索引中获取调用者列表
 			callers, exists := calleeMap.Get(calleeKey)
@@ -261,7 +379,25 @@ func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, w
 				calleeMap.Add(calleeKey, callers)
 			}
 			realCallers := make([]CallerInfo, 0, len(callers))
+			var cyclicCallers []CallerInfo
+			// 按被调用符号的语言选择打分实现
+			calleeLanguage := languageOf(ln.callee.FilePath)
 			for i := range len(callers) {
+				// 路径范围外的调用者在打分和 TopN 截断前剪枝
+				if !isUnderPathPrefix(callers[i].FilePath, pathPrefix) {
+					continue
+				}
+				if opts.ExcludeTests && lang.IsTestFile(callers[i].FilePath) {
+					continue
+				}
+				// 构造函数只关联实例化该类的调用，其他被调用者不关联 new 表达式
+				if isConstructor {
+					if !callers[i].CalleeKey.IsConstructor && !constructsByPlainCall(languageOf(callers[i].FilePath)) {
+						continue
+					}
+				} else if callers[i].CalleeKey.IsConstructor {
+					continue
+				}
 				// 根据可变参数，过滤掉不符合条件的调用者
 				if ln.callee.IsVariadic && callers[i].CalleeKey.ParamCount < ln.callee.ParamCount {
 					// 调用者传入的参数少于被调用者的固定参数个数（可变参数）
@@ -271,20 +407,32 @@ func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, w
 					// 调用者传入的参数不等于被调用者的固定参数个数（固定参数）
 					continue
 				}
-				// 可以保留递归情况的层次信息，但是不继续遍历下去
-				if _, ok := visited[callers[i].Key()]; ok {
-					// 防止循环引用
+				fileElementTable, err := idx.getCachedFileElementTable(ctx, projectUuid, callers[i].FilePath, fileTables)
+				if err != nil {
+					idx.ctxLogger(ctx).Error("failed to get file element table by path, err: %v", err)
+				}
+				if fileElementTable == nil {
 					continue
 				}
-
-				fileElementTable, err := idx.getFileElementTableByPath(ctx, projectUuid, callers[i].FilePath)
-				if err != nil {
-					idx.logger.Error("failed to get file element table by path, err: %v", err)
+				// 调用者记录的是调用处位置，按调用者定义的位置去重
+				callers[i].definitionPosition = callers[i].Position
+				if definition := findCallerDefinition(fileElementTable, &callers[i]); definition != nil {
+					callers[i].definitionPosition = types.ToPosition(definition.Range)
+					// 调用者是构造函数时，继续向上按类名查找实例化它的调用
+					callers[i].constructorOf, _ = proto.GetConstructorClassFromExtraData(definition.ExtraData)
+				}
+				// 可以保留递归情况的层次信息，但是不继续遍历下去
+				if _, ok := visited[callers[i].definitionKey()]; ok {
+					// 防止循环引用，开启环检测时记录闭合环路的调用者
+					if opts.DetectCycles && onPath(ln, callers[i].definitionKey()) &&
+						!slices.ContainsFunc(cyclicCallers, func(c CallerInfo) bool { return c.definitionKey() == callers[i].definitionKey() }) {
+						cyclicCallers = append(cyclicCallers, callers[i])
+					}
 					continue
 				}
 				imports := fileElementTable.Imports
 				// 计算匹配分数
-				score := idx.analyzer.CalculateSymbolMatchScore(workspace, imports, callers[i].FilePath, ln.callee.FilePath,
+				score := idx.analyzer.ScoreSymbolMatch(calleeLanguage, workspace, imports, callers[i].FilePath, ln.callee.FilePath,
 					ln.callee.SymbolName, callers[i].SymbolName)
 				callers[i].Score = float64(score)
 				realCallers = append(realCallers, callers[i])
@@ -297,6 +445,15 @@ func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, w
 			if layer != 0 && len(realCallers) > DefaultTopN {
 				realCallers = realCallers[:DefaultTopN]
 			}
+			realCallers = realCallers[:limitNodes(ln.node, len(realCallers))]
+			nodeCount += len(realCallers)
+			cyclicCallers = cyclicCallers[:limitNodes(ln.node, len(cyclicCallers))]
+			nodeCount += len(cyclicCallers)
+			// 构造函数的调用者是实例化类的位置
+			callerNodeType := types.NodeTypeReference
+			if isConstructor {
+				callerNodeType = types.NodeTypeConstructorCall
+			}
 
 			for i := range len(realCallers) {
 				// 创建对应的被调用元素
@@ -304,15 +461,16 @@ func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, w
 					FilePath:   realCallers[i].FilePath,
 					SymbolName: realCallers[i].SymbolName,
 					ParamCount: realCallers[i].ParamCount,
-					Position:   realCallers[i].Position,
+					Position:   realCallers[i].definitionPosition,
 					IsVariadic: realCallers[i].IsVariadic,
+					ClassName:  realCallers[i].constructorOf,
 				}
 				// 创建调用者节点
 				callerNode := &types.RelationNode{
 					FilePath:   realCallers[i].FilePath,
 					SymbolName: realCallers[i].SymbolName,
 					Position:   &realCallers[i].Position,
-					NodeType:   string(types.NodeTypeReference),
+					NodeType:   string(callerNodeType),
 					Children:   make([]*types.RelationNode, 0),
 				}
 				// 将调用者添加到当前节点的children中
@@ -323,23 +481,219 @@ func (idx *Indexer) buildCallGraphBFS(ctx context.Context, projectUuid string, w
 				nextLayerNodes = append(nextLayerNodes, &layerNode{
 					node:   callerNode,
 					callee: calleeInfo,
+					parent: ln,
+				})
+			}
+			// 成环的调用者作为叶子节点保留，不进入下一层
+			for i := range cyclicCallers {
+				ln.node.Children = append(ln.node.Children, &types.RelationNode{
+					FilePath:   cyclicCallers[i].FilePath,
+					SymbolName: cyclicCallers[i].SymbolName,
+					Position:   &cyclicCallers[i].Position,
+					NodeType:   string(callerNodeType),
+					Children:   make([]*types.RelationNode, 0),
+					Cyclic:     true,
 				})
 			}
 		}
 
+		// 达到上限时，本应继续展开的下一层节点标记为被截断
+		if truncated && layer+1 < maxLayer {
+			for _, next := range nextLayerNodes {
+				next.node.Truncated = true
+			}
+		}
 		// 移动到下一层
 		currentLayerNodes = nextLayerNodes
 	}
-	// 清除数据库
-	err = idx.storage.DeleteAllWithPrefix(ctx, projectUuid, store.CalleeMapKeySystemPrefix)
+}
+
+// variableIdentifierRegex 匹配函数体中的标识符，用于查找非调用的变量引用
+var variableIdentifierRegex = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+
+// collectVariableLeaves 提取函数体内引用的包级变量、常量和字段定义，作为调用图中不再展开的叶子节点。
+// 元素表只记录调用，非调用的引用从函数体源码中按标识符提取，排除参数和局部变量后按名称解析为变量定义，
+// 同文件的定义优先，其次按符号名在项目中查找
+func (idx *Indexer) collectVariableLeaves(ctx context.Context, projectUuid string, callee *CalleeInfo,
+	fileTables map[string]*codegraphpb.FileElementTable) []*types.RelationNode {
+	loadTable := func(path string) *codegraphpb.FileElementTable {
+		table, err := idx.getCachedFileElementTable(ctx, projectUuid, path, fileTables)
+		if err != nil {
+			idx.ctxLogger(ctx).Debug("collect variable leaves get file %s element table err: %v", path, err)
+		}
+		return table
+	}
+	fileTable := loadTable(callee.FilePath)
+	if fileTable == nil {
+		return nil
+	}
+	var function *codegraphpb.Element
+	for _, e := range fileTable.Elements {
+		if e.IsDefinition && e.Name == callee.SymbolName && isValidRange(e.Range) &&
+			(e.ElementType == codegraphpb.ElementType_FUNCTION || e.ElementType == codegraphpb.ElementType_METHOD) &&
+			int(e.Range[0]) == callee.Position.StartLine-1 {
+			function = e
+			break
+		}
+	}
+	if function == nil {
+		return nil
+	}
+	startLine, endLine := function.Range[0], function.Range[2]
+
+	// 参数、局部变量以及函数自身不是外部依赖
+	exclude := map[string]struct{}{function.Name: {}}
+	if params, err := proto.GetParametersFromExtraData(function.ExtraData); err == nil {
+		for _, p := range params {
+			exclude[strings.TrimSpace(strings.ReplaceAll(p.Name, VarVariadic, types.EmptyString))] = struct{}{}
+		}
+	}
+	var names []string
+	for _, e := range fileTable.Elements {
+		if !isValidRange(e.Range) || e.Range[0] < startLine || e.Range[2] > endLine {
+			continue
+		}
+		switch {
+		case e.IsDefinition && e.ElementType == codegraphpb.ElementType_VARIABLE:
+			exclude[e.Name] = struct{}{}
+		case e.ElementType == codegraphpb.ElementType_REFERENCE:
+			names = append(names, e.Name)
+		}
+	}
+	content, err := idx.workspaceReader.ReadFile(ctx, callee.FilePath, types.ReadOptions{
+		StartLine: int(startLine) + 1,
+		EndLine:   int(endLine) + 1,
+	})
 	if err != nil {
-		idx.logger.Error("failed to delete callee map for project %s, err: %v", projectUuid, err)
-		return
+		idx.ctxLogger(ctx).Debug("collect variable leaves read file %s err: %v", callee.FilePath, err)
+	}
+	names = append(names, variableIdentifierRegex.FindAllString(string(content), -1)...)
+
+	seen := make(map[string]struct{}, len(names))
+	leaves := make([]*types.RelationNode, 0)
+	for _, name := range names {
+		if _, ok := exclude[name]; ok {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		path, rng := idx.resolveVariableDefinition(ctx, projectUuid, fileTable, name, loadTable)
+		if rng == nil {
+			continue
+		}
+		position := types.ToPosition(rng)
+		leaves = append(leaves, &types.RelationNode{
+			FilePath:   path,
+			SymbolName: name,
+			Position:   &position,
+			NodeType:   string(types.NodeTypeReference),
+			Children:   make([]*types.RelationNode, 0),
+			Variable:   true,
+		})
+	}
+	return leaves
+}
+
+// resolveVariableDefinition 将名称解析为函数外的变量定义，先查当前文件，再按符号名查项目中其他文件
+func (idx *Indexer) resolveVariableDefinition(ctx context.Context, projectUuid string, fileTable *codegraphpb.FileElementTable,
+	name string, loadTable func(path string) *codegraphpb.FileElementTable) (string, []int32) {
+	for _, e := range fileTable.Elements {
+		if e.IsDefinition && e.Name == name && e.ElementType == codegraphpb.ElementType_VARIABLE &&
+			isValidRange(e.Range) && !isInsideFunction(fileTable, e.Range) {
+			return fileTable.Path, e.Range
+		}
+	}
+	occurrence, err := idx.getSymbolOccurrenceByName(ctx, projectUuid, lang.Language(fileTable.Language), name)
+	if err != nil {
+		return types.EmptyString, nil
+	}
+	for _, o := range occurrence.Occurrences {
+		if o.ElementType != codegraphpb.ElementType_VARIABLE || o.Path == fileTable.Path || !isValidRange(o.Range) {
+			continue
+		}
+		if table := loadTable(o.Path); table == nil || isInsideFunction(table, o.Range) {
+			continue
+		}
+		return o.Path, o.Range
+	}
+	return types.EmptyString, nil
+}
+
+// isInsideFunction 判断范围是否位于某个函数或方法定义内部，用于排除局部变量
+func isInsideFunction(fileTable *codegraphpb.FileElementTable, rng []int32) bool {
+	for _, e := range fileTable.Elements {
+		if !e.IsDefinition || !isValidRange(e.Range) ||
+			(e.ElementType != codegraphpb.ElementType_FUNCTION && e.ElementType != codegraphpb.ElementType_METHOD) {
+			continue
+		}
+		if rng[0] >= e.Range[0] && rng[2] <= e.Range[2] {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureCalleeMap 确保项目的 callee map 已构建。callee map 持久化后在查询间复用，
+// 文件变更时由 updateCalleeMap 增量维护，只有尚未构建或上次构建被中断时才全量构建
+func (idx *Indexer) ensureCalleeMap(ctx context.Context, projectUuid string) error {
+	if idx.isCallGraphBuilt(ctx, projectUuid) {
+		return nil
+	}
+	idx.calleeMapMu.Lock()
+	defer idx.calleeMapMu.Unlock()
+	// 等锁期间可能已被其他查询构建完成
+	if idx.isCallGraphBuilt(ctx, projectUuid) {
+		return nil
+	}
+	return idx.rebuildCalleeMap(ctx, projectUuid)
+}
+
+// rebuildCalleeMap 全量构建 callee map 并记录构建完成元数据，调用方需持有 calleeMapMu
+func (idx *Indexer) rebuildCalleeMap(ctx context.Context, projectUuid string) error {
+	if err := idx.buildCalleeMap(ctx, projectUuid); err != nil {
+		return err
+	}
+	if err := idx.saveCallGraphBuiltMeta(ctx, projectUuid); err != nil {
+		idx.ctxLogger(ctx).Debug("save project %s callgraph built meta err: %v", projectUuid, err)
+	}
+	return nil
+}
+
+// RebuildCalleeMap 强制全量重建工作区下所有项目的 callee map
+func (idx *Indexer) RebuildCalleeMap(ctx context.Context, workspacePath string) error {
+	projects, err := idx.getQueryProjects(ctx, workspacePath, types.EmptyString)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		return fmt.Errorf("no project found in workspace %s", workspacePath)
+	}
+	idx.calleeMapMu.Lock()
+	defer idx.calleeMapMu.Unlock()
+	for _, p := range projects {
+		start := time.Now()
+		if err := idx.rebuildCalleeMap(ctx, p.Uuid); err != nil {
+			return fmt.Errorf("rebuild project %s callee map err: %w", p.Uuid, err)
+		}
+		idx.logger.Info("rebuild project %s callee map cost %d ms", p.Uuid, time.Since(start).Milliseconds())
 	}
+	return nil
 }
 
-// buildCalleeMap 构建反向索引映射：callee -> []caller
+// buildCalleeMap 构建反向索引映射：callee -> []caller，以及调用者文件到被调用者的文件索引。
+// 构建被中断（查询取消或服务关闭）时已缓冲的数据仍会刷盘，但不会记录构建完成元数据，下次构建前会被清理
 func (idx *Indexer) buildCalleeMap(ctx context.Context, projectUuid string) error {
+	if !idx.beginCalleeMapBuild() {
+		return ErrIndexerClosing
+	}
+	defer idx.calleeMapBuilds.Done()
+
+	if err := idx.clearStaleCalleeMap(ctx, projectUuid); err != nil {
+		return err
+	}
+
 	// 创建batcher实例
 	batcher := NewMapBatcher(idx.storage, idx.logger, projectUuid, DefaultMapBatchSize)
 	defer batcher.Flush()
@@ -354,72 +708,268 @@ func (idx *Indexer) buildCalleeMap(ctx context.Context, projectUuid string) erro
 	iter := idx.storage.Iter(ctx, projectUuid)
 	defer iter.Close()
 
+	// 调用者文件 -> 被调用者的文件索引，用于增量更新
+	var refs workspace.CallerFileKeys
 	for iter.Next() {
+		if idx.closing.Load() {
+			return ErrIndexerClosing
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		key := iter.Key()
-		if store.IsSymbolNameKey(key) {
+		if !store.IsElementPathKey(key) {
 			continue
 		}
 
 		var elementTable codegraphpb.FileElementTable
 		if err := store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
-			idx.logger.Error("failed to unmarshal file %s element_table value, err: %v", elementTable.Path, err)
+			idx.ctxLogger(ctx).Error("failed to unmarshal file %s element_table value, err: %v", elementTable.Path, err)
 			continue
 		}
 
-		// 遍历所有函数/方法定义
-		for _, element := range elementTable.Elements {
-			if !element.IsDefinition ||
-				(element.ElementType != codegraphpb.ElementType_FUNCTION &&
-					element.ElementType != codegraphpb.ElementType_METHOD) {
-				continue
+		refs = idx.collectCallerRefs(ctx, &elementTable, refs, func(calleeName string, callerInfo CallerInfo) {
+			// 添加到缓存
+			if val, ok := calleeMap.Get(calleeName); ok {
+				calleeMap.Add(calleeName, append(val, callerInfo))
+			} else {
+				calleeMap.Add(calleeName, []CallerInfo{callerInfo})
 			}
+		})
+		if len(refs) >= DefaultMapBatchSize {
+			if err := idx.storage.BatchSave(ctx, projectUuid, refs); err != nil {
+				return fmt.Errorf("save project %s callee refs err: %w", projectUuid, err)
+			}
+			refs = refs[:0]
+		}
+	}
 
-			// 获取调用者（函数/方法）参数个数
-			callerParams, err := proto.GetParametersFromExtraData(element.ExtraData)
-			if err != nil {
-				idx.logger.Debug("parse caller parameters from extra data, err: %v", err)
-				continue
+	// 清空缓存，必须全部写到数据库里面去，保证数据库是最新的
+	calleeMap.Purge()
+	if len(refs) > 0 {
+		if err := idx.storage.BatchSave(ctx, projectUuid, refs); err != nil {
+			return fmt.Errorf("save project %s callee refs err: %w", projectUuid, err)
+		}
+	}
+	return nil
+}
+
+// collectCallers 遍历文件中的函数/方法定义，为其内部的每个调用回调被调用符号名和调用者信息
+func (idx *Indexer) collectCallers(ctx context.Context, elementTable *codegraphpb.FileElementTable, add func(calleeName string, caller CallerInfo)) {
+	for _, element := range elementTable.Elements {
+		if !element.IsDefinition ||
+			(element.ElementType != codegraphpb.ElementType_FUNCTION &&
+				element.ElementType != codegraphpb.ElementType_METHOD) {
+			continue
+		}
+
+		// 获取调用者（函数/方法）参数个数
+		callerParams, err := proto.GetParametersFromExtraData(element.ExtraData)
+		if err != nil {
+			idx.ctxLogger(ctx).Debug("parse caller parameters from extra data, err: %v", err)
+			continue
+		}
+		callerParamCount := len(callerParams)
+		isVariadic := false
+		if callerParamCount > 0 {
+			lastParam := callerParams[callerParamCount-1]
+			if strings.Contains(lastParam.Name, VarVariadic) {
+				callerParamCount = callerParamCount - 1
+				isVariadic = true
 			}
-			callerParamCount := len(callerParams)
-			isVariadic := false
-			if callerParamCount > 0 {
-				lastParam := callerParams[callerParamCount-1]
-				if strings.Contains(lastParam.Name, VarVariadic) {
-					callerParamCount = callerParamCount - 1
-					isVariadic = true
-				}
+		}
+		// 查找该函数内部的所有调用
+		callSites := idx.extractCalleeSymbols(elementTable, element.Range[0], element.Range[2])
+
+		// 为每个被调用的符号添加调用者信息，位置记录调用处而不是调用者的定义
+		for _, site := range callSites {
+			add(site.SymbolName, CallerInfo{
+				SymbolName: element.Name,
+				FilePath:   elementTable.Path,
+				Position:   site.Position,
+				ParamCount: callerParamCount,
+				IsVariadic: isVariadic,
+				CalleeKey:  site.CalleeKey,
+			})
+		}
+	}
+}
+
+// updateCalleeMap 文件变更后增量更新已构建的 callee map：移除变更文件中的旧调用者，再按文件当前的元素表写入新调用者。
+// filePaths 包括新增、修改、删除的文件，callee map 尚未构建时跳过，由下次查询全量构建。更新失败时清除构建完成元数据，下次查询重建
+func (idx *Indexer) updateCalleeMap(ctx context.Context, projectUuid string, filePaths []string) {
+	if len(filePaths) == 0 || !idx.isCallGraphBuilt(ctx, projectUuid) {
+		return
+	}
+	idx.calleeMapMu.Lock()
+	defer idx.calleeMapMu.Unlock()
+	if err := idx.updateCalleeMapLocked(ctx, projectUuid, filePaths); err != nil {
+		idx.logger.Warn("incremental update project %s callee map failed, will rebuild on next query, err: %v", projectUuid, err)
+		if err = idx.storage.Delete(ctx, projectUuid, store.ProjectMetaKey{MetaType: store.MetaTypeCallGraphBuilt}); err != nil {
+			idx.logger.Debug("delete project %s callgraph built meta err: %v", projectUuid, err)
+		}
+	}
+}
+
+// updateCalleeMapLocked 增量更新 callee map，调用方需持有 calleeMapMu
+func (idx *Indexer) updateCalleeMapLocked(ctx context.Context, projectUuid string, filePaths []string) error {
+	changed := make(map[string]struct{}, len(filePaths))
+	for _, fp := range filePaths {
+		changed[fp] = struct{}{}
+	}
+
+	// 1. 通过文件索引找到变更文件调用过的被调用者，只读取并移除这些 callee map 中的旧调用者
+	var calleeKeys []store.Key
+	var staleKeys []store.Key
+	seen := make(map[string]struct{})
+	for _, fp := range filePaths {
+		prefix := store.CallerFilePrefix(fp)
+		iter := idx.storage.IterPrefix(ctx, projectUuid, prefix)
+		for iter.Next() {
+			calleeName := strings.TrimPrefix(iter.Key(), prefix)
+			staleKeys = append(staleKeys, store.CallerFileKey{FilePath: fp, CalleeName: calleeName})
+			if _, ok := seen[calleeName]; ok {
+				continue
 			}
-			// 查找该函数内部的所有调用
-			calleeKeys := idx.extractCalleeSymbols(&elementTable, element.Range[0], element.Range[2])
-
-			// 为每个被调用的符号添加调用者信息
-			for _, calleeKey := range calleeKeys {
-				callerInfo := CallerInfo{
-					SymbolName: element.Name,
-					FilePath:   elementTable.Path,
-					Position:   types.ToPosition(element.Range),
-					ParamCount: callerParamCount,
-					IsVariadic: isVariadic,
-					CalleeKey:  calleeKey,
-				}
-				// 添加到缓存
-				if val, ok := calleeMap.Get(calleeKey.SymbolName); ok {
-					calleeMap.Add(calleeKey.SymbolName, append(val, callerInfo))
-				} else {
-					calleeMap.Add(calleeKey.SymbolName, []CallerInfo{callerInfo})
-				}
+			seen[calleeName] = struct{}{}
+			calleeKeys = append(calleeKeys, store.CalleeMapKey{SymbolName: calleeName})
+		}
+		err := iter.Error()
+		_ = iter.Close()
+		if err != nil {
+			return fmt.Errorf("iter file %s callee refs err: %w", fp, err)
+		}
+	}
+
+	values, err := idx.storage.BatchGet(ctx, projectUuid, calleeKeys)
+	if err != nil {
+		return fmt.Errorf("get project %s callee map err: %w", projectUuid, err)
+	}
+	var updated []*store.Entry
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		var item codegraphpb.CalleeMapItem
+		if err := store.UnmarshalValue(value, &item); err != nil {
+			return fmt.Errorf("unmarshal callee map %v err: %w", calleeKeys[i], err)
+		}
+		kept := item.Callers[:0]
+		for _, c := range item.Callers {
+			if _, ok := changed[c.FilePath]; !ok {
+				kept = append(kept, c)
 			}
 		}
+		if len(kept) == len(item.Callers) {
+			continue
+		}
+		if len(kept) == 0 {
+			staleKeys = append(staleKeys, calleeKeys[i])
+			continue
+		}
+		item.Callers = kept
+		updated = append(updated, &store.Entry{Key: calleeKeys[i], Value: &item})
+	}
+	if len(staleKeys) > 0 {
+		if err := idx.storage.BatchDelete(ctx, projectUuid, staleKeys); err != nil {
+			return err
+		}
+	}
+	for _, entry := range updated {
+		if err := idx.storage.Put(ctx, projectUuid, entry); err != nil {
+			return err
+		}
 	}
 
-	// 清空缓存，必须全部写到数据库里面去，保证数据库是最新的
-	calleeMap.Purge()
+	// 2. 按文件当前的元素表写入新调用者和文件索引，已删除的文件没有元素表
+	batcher := NewMapBatcher(idx.storage, idx.logger, projectUuid, DefaultMapBatchSize)
+	var refs workspace.CallerFileKeys
+	for _, fp := range filePaths {
+		elementTable, err := idx.getFileElementTableByPath(ctx, projectUuid, fp)
+		if err != nil {
+			continue
+		}
+		refs = idx.collectCallerRefs(ctx, elementTable, refs, func(calleeName string, caller CallerInfo) {
+			batcher.Add(calleeName, []CallerInfo{caller}, true)
+		})
+	}
+	batcher.Flush()
+	if len(refs) > 0 {
+		if err := idx.storage.BatchSave(ctx, projectUuid, refs); err != nil {
+			return fmt.Errorf("save project %s callee refs err: %w", projectUuid, err)
+		}
+	}
 	return nil
 }
 
-// extractCalleeSymbols 提取函数定义范围内的所有被调用符号
-func (idx *Indexer) extractCalleeSymbols(fileTable *codegraphpb.FileElementTable, startLine, endLine int32) []CalleeKey {
-	var calleeKeys []CalleeKey
+// collectCallerRefs 同 collectCallers，并把文件调用的被调用者去重后追加到 refs
+func (idx *Indexer) collectCallerRefs(ctx context.Context, elementTable *codegraphpb.FileElementTable, refs workspace.CallerFileKeys,
+	add func(calleeName string, caller CallerInfo)) workspace.CallerFileKeys {
+	seen := make(map[string]struct{})
+	idx.collectCallers(ctx, elementTable, func(calleeName string, caller CallerInfo) {
+		add(calleeName, caller)
+		if _, ok := seen[calleeName]; ok {
+			return
+		}
+		seen[calleeName] = struct{}{}
+		refs = append(refs, store.CallerFileKey{FilePath: elementTable.Path, CalleeName: calleeName})
+	})
+	return refs
+}
+
+// clearStaleCalleeMap 清理上次构建残留的 callee map。构建按调用者合并已有数据，残留数据会导致调用者重复；
+// 没有构建完成元数据说明上次构建被中断，残留数据不完整
+func (idx *Indexer) clearStaleCalleeMap(ctx context.Context, projectUuid string) error {
+	built := idx.isCallGraphBuilt(ctx, projectUuid)
+	if err := idx.storage.Delete(ctx, projectUuid, store.ProjectMetaKey{MetaType: store.MetaTypeCallGraphBuilt}); err != nil {
+		idx.ctxLogger(ctx).Debug("delete project %s callgraph built meta err: %v", projectUuid, err)
+	}
+	for _, prefix := range []string{store.CalleeMapKeySystemPrefix, store.CallerFileKeySystemPrefix} {
+		if idx.storage.Size(ctx, projectUuid, prefix) == 0 {
+			continue
+		}
+		if !built && prefix == store.CalleeMapKeySystemPrefix {
+			idx.ctxLogger(ctx).Warn("project %s callee map was partially built, rebuilding", projectUuid)
+		}
+		if err := idx.storage.DeleteAllWithPrefix(ctx, projectUuid, prefix); err != nil {
+			return fmt.Errorf("failed to clear stale callee map for project %s, err: %w", projectUuid, err)
+		}
+	}
+	return nil
+}
+
+// beginCalleeMapBuild 登记一次 callee map 构建，已开始关闭时返回 false；登记成功后需调用 calleeMapBuilds.Done
+func (idx *Indexer) beginCalleeMapBuild() bool {
+	idx.closingMu.Lock()
+	defer idx.closingMu.Unlock()
+	if idx.closing.Load() {
+		return false
+	}
+	idx.calleeMapBuilds.Add(1)
+	return true
+}
+
+// Shutdown 停止接受新的 callee map 构建，并等待进行中的构建中断并刷盘，最后关闭外部索引，需在关闭存储前调用
+func (idx *Indexer) Shutdown(ctx context.Context) error {
+	idx.closingMu.Lock()
+	idx.closing.Store(true)
+	idx.closingMu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		idx.calleeMapBuilds.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return errors.Join(fmt.Errorf("wait for callee map builds to flush: %w", ctx.Err()), idx.closeExternalIndexes())
+	}
+	return idx.closeExternalIndexes()
+}
+
+// extractCalleeSymbols 提取函数定义范围内的所有被调用符号及调用处位置
+func (idx *Indexer) extractCalleeSymbols(fileTable *codegraphpb.FileElementTable, startLine, endLine int32) []CallSite {
+	var callSites []CallSite
 
 	// 直接遍历元素，避免调用 findSymbolInDocByLineRange
 	for _, element := range fileTable.Elements {
@@ -442,13 +992,31 @@ func (idx *Indexer) extractCalleeSymbols(fileTable *codegraphpb.FileElementTable
 			continue
 		}
 		// 被调用的符号
-		calleeKeys = append(calleeKeys, CalleeKey{
-			SymbolName: element.Name,
-			ParamCount: len(params),
+		callSites = append(callSites, CallSite{
+			CalleeKey: CalleeKey{
+				SymbolName:    element.Name,
+				ParamCount:    len(params),
+				IsConstructor: proto.IsConstructorCallFromExtraData(element.ExtraData),
+			},
+			Position: types.ToPosition(element.Range),
 		})
 	}
 
-	return calleeKeys
+	return callSites
+}
+
+// findCallerDefinition 查找包含调用处的调用者定义，找不到时返回 nil
+func findCallerDefinition(fileTable *codegraphpb.FileElementTable, caller *CallerInfo) *codegraphpb.Element {
+	callLine := int32(caller.Position.StartLine - 1)
+	for _, e := range fileTable.Elements {
+		if !e.IsDefinition || e.Name != caller.SymbolName || !isValidRange(e.Range) {
+			continue
+		}
+		if callLine >= e.Range[0] && callLine <= e.Range[2] {
+			return e
+		}
+	}
+	return nil
 }
 
 // queryCallersFromDB 从数据库查询指定符号的调用者列表
@@ -477,8 +1045,12 @@ func (idx *Indexer) queryCallersFromDB(ctx context.Context, projectUuid string,
 				EndColumn:   int(c.Position.EndColumn),
 			},
 			ParamCount: int(c.ParamCount),
-			CalleeKey:  CalleeKey{SymbolName: c.CalleeKey.SymbolName, ParamCount: int(c.CalleeKey.ParamCount)},
-			Score:      c.Score,
+			CalleeKey: CalleeKey{
+				SymbolName:    c.CalleeKey.SymbolName,
+				ParamCount:    int(c.CalleeKey.ParamCount),
+				IsConstructor: c.CalleeKey.IsConstructor,
+			},
+			Score: c.Score,
 		})
 	}
 	return callers, nil
@@ -1,11 +1,24 @@
 package indexer
 
 import (
+	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCalleeInfo_Key(t *testing.T) {
@@ -98,6 +111,57 @@ func TestExtractCalleeSymbols(t *testing.T) {
 	for _, result := range results {
 		assert.NotEmpty(t, result.SymbolName)
 	}
+	// 保留调用处的位置
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, types.Position{StartLine: 11, StartColumn: 1, EndLine: 11, EndColumn: 11}, results[0].Position)
+		assert.Equal(t, types.Position{StartLine: 16, StartColumn: 1, EndLine: 16, EndColumn: 11}, results[1].Position)
+	}
+}
+
+func TestFindCallerDefinition(t *testing.T) {
+	fileTable := &codegraphpb.FileElementTable{
+		Path: "/test/file.go",
+		Elements: []*codegraphpb.Element{
+			{Name: "caller", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 8, 1}},
+			{Name: "caller", IsDefinition: true, ElementType: codegraphpb.ElementType_METHOD, Range: []int32{10, 0, 14, 1}},
+			{Name: "callee", ElementType: codegraphpb.ElementType_CALL, Range: []int32{12, 1, 12, 7}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		position types.Position
+		want     *types.Position
+	}{
+		{
+			name:     "调用处位于第一个同名定义内",
+			position: types.Position{StartLine: 5, StartColumn: 2, EndLine: 5, EndColumn: 8},
+			want:     &types.Position{StartLine: 3, StartColumn: 1, EndLine: 9, EndColumn: 2},
+		},
+		{
+			name:     "调用处位于第二个同名定义内",
+			position: types.Position{StartLine: 13, StartColumn: 2, EndLine: 13, EndColumn: 8},
+			want:     &types.Position{StartLine: 11, StartColumn: 1, EndLine: 15, EndColumn: 2},
+		},
+		{
+			// 找不到定义时调用方退化为调用处位置
+			name:     "找不到定义时返回nil",
+			position: types.Position{StartLine: 20, StartColumn: 2, EndLine: 20, EndColumn: 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &CallerInfo{SymbolName: "caller", FilePath: fileTable.Path, Position: tt.position}
+			definition := findCallerDefinition(fileTable, caller)
+			if tt.want == nil {
+				assert.Nil(t, definition)
+				return
+			}
+			if assert.NotNil(t, definition) {
+				assert.Equal(t, *tt.want, types.ToPosition(definition.Range))
+			}
+		})
+	}
 }
 
 func TestBuildCallGraphBFS(t *testing.T) {
@@ -110,6 +174,62 @@ func TestBuildCalleeMap(t *testing.T) {
 	t.Skip("需要完整的存储依赖")
 }
 
+func TestBuildCalleeMap_RebuildAfterInterrupt(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	filePath := filepath.Join(workspaceDir, "main.go")
+	content := "package main\n\nfunc Target() {}\n\nfunc Caller() {\n\tTarget()\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	projectUuid := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir).Uuid
+
+	// 模拟上次构建中断：残留部分 callee map，且没有构建完成元数据
+	require.NoError(t, storage.Put(ctx, projectUuid, &store.Entry{
+		Key: store.CalleeMapKey{SymbolName: "Target"},
+		Value: &codegraphpb.CalleeMapItem{
+			CalleeName: "Target",
+			Callers: []*codegraphpb.CallerInfo{
+				{SymbolName: "Caller", FilePath: filePath, Position: &codegraphpb.Position{StartLine: 6, EndLine: 6}},
+			},
+		},
+	}))
+	require.False(t, idx.isCallGraphBuilt(ctx, projectUuid))
+
+	require.NoError(t, idx.buildCalleeMap(ctx, projectUuid))
+	value, err := storage.Get(ctx, projectUuid, store.CalleeMapKey{SymbolName: "Target"})
+	require.NoError(t, err)
+	var item codegraphpb.CalleeMapItem
+	require.NoError(t, store.UnmarshalValue(value, &item))
+	// 残留数据被清理，调用者不重复
+	require.Len(t, item.Callers, 1)
+	assert.Equal(t, "Caller", item.Callers[0].SymbolName)
+
+	// 关闭后不再接受新的构建
+	require.NoError(t, idx.Shutdown(ctx))
+	assert.ErrorIs(t, idx.buildCalleeMap(ctx, projectUuid), ErrIndexerClosing)
+}
+
+func TestIndexer_ShutdownRejectsConcurrentBuilds(t *testing.T) {
+	idx := &Indexer{}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if idx.beginCalleeMapBuild() {
+				idx.calleeMapBuilds.Done()
+			}
+		}()
+	}
+	require.NoError(t, idx.Shutdown(context.Background()))
+	// 关闭返回后不再登记新的构建
+	assert.False(t, idx.beginCalleeMapBuild())
+	wg.Wait()
+}
+
 func TestMapBatcher_Add(t *testing.T) {
 	// 创建一个mock的MapBatcher用于测试
 	// 这里测试基本的数据结构
@@ -141,6 +261,369 @@ func TestQueryCallGraph(t *testing.T) {
 	t.Skip("需要完整的依赖注入环境")
 }
 
+func TestIsUnderPathPrefix(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "repo", "pkg")
+	tests := []struct {
+		name       string
+		filePath   string
+		pathPrefix string
+		want       bool
+	}{
+		{name: "未指定前缀", filePath: filepath.Join(dir, "a.go"), pathPrefix: "", want: true},
+		{name: "目录下的文件", filePath: filepath.Join(dir, "sub", "a.go"), pathPrefix: dir, want: true},
+		{name: "前缀就是文件本身", filePath: filepath.Join(dir, "a.go"), pathPrefix: filepath.Join(dir, "a.go"), want: true},
+		{name: "同名前缀的兄弟目录", filePath: dir + "2" + string(filepath.Separator) + "a.go", pathPrefix: dir, want: false},
+		{name: "目录外的文件", filePath: filepath.Join(string(filepath.Separator), "repo", "main.go"), pathPrefix: dir, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isUnderPathPrefix(tt.filePath, tt.pathPrefix))
+		})
+	}
+}
+
+func TestQueryCallGraph_PathPrefix(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"target.go":     "package main\n\nfunc Target() {}\n",
+		"api/api.go":    "package main\n\nfunc Handle() {\n\tTarget()\n}\n",
+		"api/route.go":  "package main\n\nfunc Route() {\n\tHandle()\n}\n",
+		"cli/cli.go":    "package main\n\nfunc Run() {\n\tTarget()\n}\n",
+		"cli/entry.go":  "package main\n\nfunc Entry() {\n\tHandle()\n}\n",
+		"apiv2/main.go": "package main\n\nfunc Serve() {\n\tTarget()\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	// collect 按层收集调用者符号名
+	var collect func(nodes []*types.RelationNode, layer int, layers map[int][]string)
+	collect = func(nodes []*types.RelationNode, layer int, layers map[int][]string) {
+		for _, n := range nodes {
+			if layer > 0 {
+				layers[layer] = append(layers[layer], n.SymbolName)
+			}
+			collect(n.Children, layer+1, layers)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		pathPrefix string
+		want       map[int][]string
+	}{
+		{name: "不限制路径", pathPrefix: "", want: map[int][]string{1: {"Handle", "Run", "Serve"}, 2: {"Entry", "Route"}}},
+		{name: "相对路径限制到目录", pathPrefix: "api", want: map[int][]string{1: {"Handle"}, 2: {"Route"}}},
+		{name: "绝对路径限制到目录", pathPrefix: filepath.Join(workspaceDir, "cli"), want: map[int][]string{1: {"Run"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+				Workspace:  workspaceDir,
+				FilePath:   filepath.Join(workspaceDir, "target.go"),
+				SymbolName: "Target",
+				MaxLayer:   2,
+				PathPrefix: tt.pathPrefix,
+			})
+			require.NoError(t, err)
+			require.Len(t, nodes, 1)
+			layers := make(map[int][]string)
+			collect(nodes, 0, layers)
+			for _, names := range layers {
+				sort.Strings(names)
+			}
+			assert.Equal(t, tt.want, layers)
+		})
+	}
+}
+
+func TestQueryCallGraph_DetectCycles(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	filePath := filepath.Join(workspaceDir, "main.go")
+	content := "package main\n\nfunc A(n int) {\n\tif n > 0 {\n\t\tB(n - 1)\n\t}\n}\n\n" +
+		"func B(n int) {\n\tif n > 0 {\n\t\tA(n - 1)\n\t}\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		detectCycles bool
+		wantCyclic   bool
+	}{
+		{name: "开启环检测时标记闭合环路的节点", detectCycles: true, wantCyclic: true},
+		{name: "未开启时跳过已访问的调用者", detectCycles: false, wantCyclic: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+				Workspace:    workspaceDir,
+				FilePath:     filePath,
+				SymbolName:   "A",
+				MaxLayer:     5,
+				DetectCycles: tt.detectCycles,
+			})
+			require.NoError(t, err)
+			// A <- B <- A
+			require.Len(t, nodes, 1)
+			require.Len(t, nodes[0].Children, 1)
+			b := nodes[0].Children[0]
+			assert.Equal(t, "B", b.SymbolName)
+			assert.False(t, b.Cyclic)
+
+			cycles := types.CollectCallGraphCycles(nodes)
+			if !tt.wantCyclic {
+				assert.Empty(t, b.Children)
+				assert.Empty(t, cycles)
+				return
+			}
+			require.Len(t, b.Children, 1)
+			closing := b.Children[0]
+			assert.Equal(t, "A", closing.SymbolName)
+			assert.True(t, closing.Cyclic)
+			assert.Empty(t, closing.Children)
+
+			require.Len(t, cycles, 1)
+			var names []string
+			for _, n := range cycles[0].Nodes {
+				names = append(names, n.SymbolName)
+			}
+			assert.Equal(t, []string{"A", "B", "A"}, names)
+		})
+	}
+}
+
+func TestQueryCallGraph_Constructors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		files  map[string]string
+		target string
+		symbol string
+		// want 每个根节点的符号名 -> 构造调用者
+		want map[string][]string
+	}{
+		{
+			name: "Java new 表达式关联到参数个数一致的构造函数",
+			files: map[string]string{
+				"Foo.java": "package demo;\n\npublic class Foo {\n    private int x;\n\n" +
+					"    public Foo() {\n        this.x = 0;\n    }\n\n" +
+					"    public Foo(int x, String name) {\n        this.x = x;\n    }\n}\n",
+				"App.java": "package demo;\n\npublic class App {\n" +
+					"    public void create() {\n        Foo a = new Foo();\n    }\n\n" +
+					"    public void createNamed() {\n        Foo b = new Foo(1, \"b\");\n    }\n\n" +
+					"    public boolean check(Object o) {\n        return o instanceof Foo;\n    }\n}\n",
+			},
+			target: "Foo.java",
+			symbol: "Foo",
+			want:   map[string][]string{"Foo:6": {"create"}, "Foo:10": {"createNamed"}},
+		},
+		{
+			name: "Python 类名调用关联到 __init__",
+			files: map[string]string{
+				"shop.py": "class Order:\n    def __init__(self, oid, amount):\n        self.oid = oid\n\n\n" +
+					"def make():\n    return Order(1, 2)\n\n\ndef count():\n    return len([])\n",
+			},
+			target: "shop.py",
+			symbol: "Order",
+			want:   map[string][]string{"__init__:2": {"make"}},
+		},
+		{
+			name: "没有构造函数的类按默认构造函数查找",
+			files: map[string]string{
+				"shop.py": "class Empty:\n    pass\n\n\ndef make_empty():\n    return Empty()\n",
+			},
+			target: "shop.py",
+			symbol: "Empty",
+			want:   map[string][]string{"Empty:1": {"make_empty"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceDir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte(content), 0644))
+			}
+			idx, _ := newTestIndexingIndexer(t, workspaceDir)
+			idx.config.CacheCapacity = DefaultCacheCapacity
+			_, err := idx.IndexWorkspace(ctx, workspaceDir)
+			require.NoError(t, err)
+
+			nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+				Workspace:  workspaceDir,
+				FilePath:   filepath.Join(workspaceDir, tt.target),
+				SymbolName: tt.symbol,
+				MaxLayer:   1,
+			})
+			require.NoError(t, err)
+			got := make(map[string][]string)
+			for _, root := range nodes {
+				assert.Equal(t, string(types.NodeTypeDefinition), root.NodeType)
+				key := fmt.Sprintf("%s:%d", root.SymbolName, root.Position.StartLine)
+				got[key] = []string{}
+				for _, child := range root.Children {
+					assert.Equal(t, string(types.NodeTypeConstructorCall), child.NodeType)
+					got[key] = append(got[key], child.SymbolName)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueryCallGraph_IncludeVariables(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	mainPath := filepath.Join(workspaceDir, "main.go")
+	configPath := filepath.Join(workspaceDir, "config.go")
+	files := map[string]string{
+		mainPath: "package main\n\nvar maxRetries = 3\n\nfunc Run(n int) int {\n\ttotal := maxRetries + n\n" +
+			"\tprintln(defaultName)\n\treturn total\n}\n\nfunc Main() {\n\tRun(1)\n}\n",
+		configPath: "package main\n\nconst defaultName = \"indexer\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		includeVariables bool
+		wantVariables    map[string]string
+	}{
+		{
+			name:             "附加引用的包级变量和其他文件的常量",
+			includeVariables: true,
+			wantVariables:    map[string]string{"maxRetries": mainPath, "defaultName": configPath},
+		},
+		{
+			name:          "未开启时不附加变量节点",
+			wantVariables: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+				Workspace:        workspaceDir,
+				FilePath:         mainPath,
+				SymbolName:       "Run",
+				MaxLayer:         2,
+				IncludeVariables: tt.includeVariables,
+			})
+			require.NoError(t, err)
+			require.Len(t, nodes, 1)
+
+			variables := make(map[string]string)
+			var callers []string
+			for _, child := range nodes[0].Children {
+				if child.Variable {
+					assert.Equal(t, string(types.NodeTypeReference), child.NodeType)
+					assert.Empty(t, child.Children)
+					variables[child.SymbolName] = child.FilePath
+					continue
+				}
+				callers = append(callers, child.SymbolName)
+				// Main 函数体内没有引用变量
+				for _, grandChild := range child.Children {
+					assert.False(t, grandChild.Variable, grandChild.SymbolName)
+				}
+			}
+			assert.Equal(t, []string{"Main"}, callers)
+			// 参数 n、局部变量 total 和调用 println 不作为变量节点
+			assert.Equal(t, tt.wantVariables, variables)
+		})
+	}
+}
+
+func TestQueryCallGraph_Budget(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	filePath := filepath.Join(workspaceDir, "main.go")
+	// 20 个函数调用 Target，另外 20 个函数调用全部这 20 个函数
+	var content strings.Builder
+	content.WriteString("package main\n\nfunc Target() {}\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&content, "\nfunc C%d() {\n\tTarget()\n}\n", i)
+	}
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&content, "\nfunc D%d() {\n", i)
+		for j := 0; j < 20; j++ {
+			fmt.Fprintf(&content, "\tC%d()\n", j)
+		}
+		content.WriteString("}\n")
+	}
+	require.NoError(t, os.WriteFile(filePath, []byte(content.String()), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	countNodes := func(nodes []*types.RelationNode) int {
+		var count func(nodes []*types.RelationNode) int
+		count = func(nodes []*types.RelationNode) int {
+			n := len(nodes)
+			for _, node := range nodes {
+				n += count(node.Children)
+			}
+			return n
+		}
+		return count(nodes)
+	}
+
+	tests := []struct {
+		name           string
+		maxNodes       int
+		configMaxNodes int
+		timeout        time.Duration
+		wantTruncated  bool
+		wantNodes      int
+	}{
+		{name: "不限制时完整展开", wantNodes: 1 + 20 + 20},
+		{name: "节点数达到上限后停止展开", maxNodes: 10, wantTruncated: true, wantNodes: 10},
+		{name: "节点数上限足够时不截断", maxNodes: 100, wantNodes: 1 + 20 + 20},
+		{name: "超时后停止展开", timeout: time.Nanosecond, wantTruncated: true, wantNodes: 1},
+		{name: "未指定时按服务端上限截断", configMaxNodes: 15, wantTruncated: true, wantNodes: 15},
+		{name: "超过服务端上限时按服务端上限截断", maxNodes: 100, configMaxNodes: 15, wantTruncated: true, wantNodes: 15},
+		{name: "小于服务端上限时按请求截断", maxNodes: 5, configMaxNodes: 15, wantTruncated: true, wantNodes: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.config.CallGraphMaxNodes = tt.configMaxNodes
+			if tt.configMaxNodes == 0 {
+				idx.config.CallGraphMaxNodes = DefaultCallGraphMaxNodes
+			}
+			nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+				Workspace:  workspaceDir,
+				FilePath:   filePath,
+				SymbolName: "Target",
+				MaxLayer:   3,
+				MaxNodes:   tt.maxNodes,
+				Timeout:    tt.timeout,
+			})
+			require.NoError(t, err)
+			require.Len(t, nodes, 1)
+			assert.Equal(t, tt.wantNodes, countNodes(nodes))
+			assert.Equal(t, tt.wantTruncated, types.IsCallGraphTruncated(nodes))
+			if tt.wantTruncated {
+				assert.True(t, nodes[0].Truncated)
+			}
+		})
+	}
+}
+
 func TestQueryCallGraphBySymbol(t *testing.T) {
 	// 这个测试需要完整的依赖注入
 	t.Skip("需要完整的依赖注入环境")
@@ -202,3 +685,194 @@ func TestVariadicParameterMatching(t *testing.T) {
 	}
 }
 
+
+// iterCountingStorage 统计全量遍历、callee map 遍历次数和读取的 callee map 数量的存储
+type iterCountingStorage struct {
+	store.GraphStorage
+	iters       int
+	calleeScans int
+	calleeGets  []string
+}
+
+func (s *iterCountingStorage) IterPrefix(ctx context.Context, projectUuid string, prefix string) store.Iterator {
+	if prefix == store.CalleeMapKeySystemPrefix {
+		s.calleeScans++
+	}
+	return s.GraphStorage.IterPrefix(ctx, projectUuid, prefix)
+}
+
+func (s *iterCountingStorage) BatchGet(ctx context.Context, projectUuid string, keys []store.Key) ([][]byte, error) {
+	for _, key := range keys {
+		if calleeKey, ok := key.(store.CalleeMapKey); ok {
+			s.calleeGets = append(s.calleeGets, calleeKey.SymbolName)
+		}
+	}
+	return s.GraphStorage.BatchGet(ctx, projectUuid, keys)
+}
+
+func (s *iterCountingStorage) Iter(ctx context.Context, projectUuid string) store.Iterator {
+	s.iters++
+	return s.GraphStorage.Iter(ctx, projectUuid)
+}
+
+func TestQueryCallGraph_ReuseCalleeMap(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	mainPath := filepath.Join(workspaceDir, "main.go")
+	otherPath := filepath.Join(workspaceDir, "other.go")
+	require.NoError(t, os.WriteFile(mainPath,
+		[]byte("package main\n\nfunc Target() {}\n\nfunc Caller() {\n\tTarget()\n}\n"), 0644))
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	projectUuid := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir).Uuid
+	counting := &iterCountingStorage{GraphStorage: storage}
+	idx.storage = counting
+
+	queryCallers := func() []string {
+		nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+			Workspace:  workspaceDir,
+			FilePath:   mainPath,
+			SymbolName: "Target",
+			MaxLayer:   3,
+		})
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+		var callers []string
+		for _, child := range nodes[0].Children {
+			callers = append(callers, child.SymbolName)
+		}
+		sort.Strings(callers)
+		return callers
+	}
+
+	// 首次查询全量构建 callee map 并保留
+	assert.Equal(t, []string{"Caller"}, queryCallers())
+	assert.Positive(t, counting.iters)
+	assert.True(t, idx.isCallGraphBuilt(ctx, projectUuid))
+	assert.Positive(t, storage.Size(ctx, projectUuid, store.CalleeMapKeySystemPrefix))
+
+	// 相同查询复用 callee map，不再遍历元素表
+	counting.iters = 0
+	assert.Equal(t, []string{"Caller"}, queryCallers())
+	assert.Zero(t, counting.iters)
+
+	// 新增文件后增量更新，不遍历元素表和整个 callee map
+	require.NoError(t, os.WriteFile(otherPath, []byte("package main\n\nfunc Other() {\n\tTarget()\n}\n"), 0644))
+	require.NoError(t, idx.IndexFiles(ctx, workspaceDir, []string{otherPath}))
+	assert.True(t, idx.isCallGraphBuilt(ctx, projectUuid))
+	assert.Zero(t, counting.iters)
+	assert.Zero(t, counting.calleeScans)
+	counting.iters = 0
+	assert.Equal(t, []string{"Caller", "Other"}, queryCallers())
+	assert.Zero(t, counting.iters)
+
+	// 删除文件后通过文件索引移除其调用者，只读取该文件调用的 callee map
+	counting.calleeGets = nil
+	require.NoError(t, idx.RemoveIndexes(ctx, workspaceDir, []string{otherPath}))
+	assert.Zero(t, counting.calleeScans)
+	assert.Equal(t, []string{"Target"}, counting.calleeGets)
+	assert.Zero(t, storage.Size(ctx, projectUuid, store.CallerFilePrefix(otherPath)))
+	assert.Equal(t, []string{"Caller"}, queryCallers())
+
+	// 修改文件后移除不再存在的调用
+	require.NoError(t, os.WriteFile(mainPath,
+		[]byte("package main\n\nfunc Target() {}\n\nfunc Helper() {}\n\nfunc Caller() {\n\tHelper()\n}\n"), 0644))
+	require.NoError(t, idx.IndexFiles(ctx, workspaceDir, []string{mainPath}))
+	assert.Zero(t, counting.calleeScans)
+	nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+		Workspace: workspaceDir, FilePath: mainPath, SymbolName: "Target", MaxLayer: 3})
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Empty(t, nodes[0].Children)
+	require.NoError(t, os.WriteFile(mainPath,
+		[]byte("package main\n\nfunc Target() {}\n\nfunc Caller() {\n\tTarget()\n}\n"), 0644))
+	require.NoError(t, idx.IndexFiles(ctx, workspaceDir, []string{mainPath}))
+	assert.Equal(t, []string{"Caller"}, queryCallers())
+
+	// 强制重建结果不变，调用者不重复
+	require.NoError(t, idx.RebuildCalleeMap(ctx, workspaceDir))
+	assert.Equal(t, []string{"Caller"}, queryCallers())
+}
+
+func TestQueryCallGraph_SymbolScorer(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"target.go":     "package main\n\nfunc Target() {}\n",
+		"near.go":       "package main\n\nfunc Near() {\n\tTarget()\n}\n",
+		"remote/far.go": "package main\n\nfunc Far() {\n\tTarget()\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	queryCallers := func() []string {
+		nodes, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+			Workspace:  workspaceDir,
+			FilePath:   filepath.Join(workspaceDir, "target.go"),
+			SymbolName: "Target",
+			MaxLayer:   1,
+		})
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+		var names []string
+		for _, n := range nodes[0].Children {
+			names = append(names, n.SymbolName)
+		}
+		return names
+	}
+
+	// 默认打分同目录的调用者优先
+	assert.Equal(t, []string{"Near", "Far"}, queryCallers())
+
+	// 注册 Go 的打分实现，远处目录的调用者优先
+	analyzer.RegisterSymbolScorer(lang.Go, analyzer.SymbolScorerFunc(func(workspace string, callerImports []*codegraphpb.Import,
+		callerFilePath, calleeFilePath, calleeSymbolName, callerSymbolName string) int {
+		if callerSymbolName == "Far" {
+			return 100
+		}
+		return 0
+	}))
+	t.Cleanup(func() { analyzer.RegisterSymbolScorer(lang.Go, nil) })
+	assert.Equal(t, []string{"Far", "Near"}, queryCallers())
+}
+
+func BenchmarkUpdateCalleeMap(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("callees=%d", size), func(b *testing.B) {
+			ctx := context.Background()
+			idx, storage := newTestIndexerWithStorage(b)
+			projectUuid := "bench-project"
+			items := make(workspace.CalleeMapItems, 0, size)
+			refs := make(workspace.CallerFileKeys, 0, size)
+			for i := 0; i < size; i++ {
+				name := fmt.Sprintf("Callee%d", i)
+				file := fmt.Sprintf("/bench/file%d.go", i)
+				items = append(items, &codegraphpb.CalleeMapItem{CalleeName: name,
+					Callers: []*codegraphpb.CallerInfo{{SymbolName: "Caller", FilePath: file}}})
+				refs = append(refs, store.CallerFileKey{FilePath: file, CalleeName: name})
+			}
+			require.NoError(b, storage.BatchSave(ctx, projectUuid, items))
+			require.NoError(b, storage.BatchSave(ctx, projectUuid, refs))
+			changed := []string{"/bench/file0.go"}
+			b.ResetTimer()
+
+			// 更新耗时只与变更文件涉及的 callee map 有关，不随 callee map 总量增长
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				require.NoError(b, storage.BatchSave(ctx, projectUuid, items[:1]))
+				require.NoError(b, storage.BatchSave(ctx, projectUuid, refs[:1]))
+				b.StartTimer()
+				require.NoError(b, idx.updateCalleeMapLocked(ctx, projectUuid, changed))
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package indexer
+
+import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// defaultExpandedContextMaxRefs 扩展上下文默认最多展开的引用符号定义数
+const defaultExpandedContextMaxRefs = 10
+
+// QueryExpandedContext 查询目标定义的内容，以及其内部引用的符号（一层）的定义内容。
+// 引用定义超过 MaxQueryLineLimit 行时只保留首行签名，总数受 MaxRefs 限制
+func (idx *Indexer) QueryExpandedContext(ctx context.Context, opts *types.QueryExpandedContextOptions) (*types.ExpandedContext, error) {
+	if opts.Workspace == "" {
+		return nil, fmt.Errorf("workspace cannot be empty")
+	}
+	if opts.SymbolName == "" {
+		return nil, fmt.Errorf("symbol name cannot be empty")
+	}
+	opts.Workspace = utils.FileURIToPath(opts.Workspace)
+	filePath, err := utils.NormalizeFilePath(opts.Workspace, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	opts.FilePath = filePath
+	if opts.FilePath == "" || !filepath.IsAbs(opts.FilePath) {
+		return nil, fmt.Errorf("param filePath must be absolute path")
+	}
+	language, err := lang.InferLanguage(opts.FilePath)
+	if err != nil {
+		return nil, errs.ErrUnSupportedLanguage
+	}
+	maxRefs := opts.MaxRefs
+	if maxRefs <= 0 {
+		maxRefs = defaultExpandedContextMaxRefs
+	}
+
+	startTime := time.Now()
+	defer func() {
+		idx.logger.Info("query expanded context of %s cost %d ms", opts.SymbolName, time.Since(startTime).Milliseconds())
+	}()
+
+	project, err := idx.getQueryProject(ctx, opts.Workspace, opts.ProjectUuid, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	fileTable, err := idx.getFileElementTable(ctx, project.Uuid, language, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	target := findDefinitionElement(fileTable, opts.SymbolName)
+	if target == nil {
+		return nil, fmt.Errorf("definition %s not found in file %s", opts.SymbolName, opts.FilePath)
+	}
+
+	result := &types.ExpandedContext{
+		Target: &types.Definition{
+			Name:  target.Name,
+			Type:  string(proto.ElementTypeFromProto(target.ElementType)),
+			Path:  opts.FilePath,
+			Range: target.Range,
+		},
+	}
+	result.Target.Content = idx.readDefinitionContent(ctx, result.Target, 0)
+
+	// 复用按行范围查询定义，解析目标定义范围内引用的符号
+	position := types.ToPosition(target.Range)
+	definitions, err := idx.queryFuncDefinitionsByLineRange(ctx, project, []lang.Language{language}, &types.QueryDefinitionOptions{
+		Workspace: opts.Workspace,
+		FilePath:  opts.FilePath,
+		StartLine: position.StartLine,
+		EndLine:   position.EndLine,
+	})
+	if err != nil {
+		return nil, err
+	}
+	visited := make(map[string]bool)
+	for _, d := range definitions {
+		if !isValidRange(d.Range) || isSymbolExists(d.Path, d.Range, visited) {
+			continue
+		}
+		// 目标定义内部的定义（含目标自身）已包含在目标内容中
+		if utils.PathEqual(d.Path, opts.FilePath) && d.Range[0] >= target.Range[0] && d.Range[2] <= target.Range[2] {
+			continue
+		}
+		visited[symbolMapKey(d.Path, d.Range)] = true
+		if len(result.References) >= maxRefs {
+			result.Truncated = true
+			break
+		}
+		d.Content = idx.readDefinitionContent(ctx, d, MaxQueryLineLimit)
+		result.References = append(result.References, d)
+	}
+	return result, nil
+}
+
+// findDefinitionElement 在文件元素表中查找指定名称的定义，同名时取第一个
+func findDefinitionElement(fileTable *codegraphpb.FileElementTable, symbolName string) *codegraphpb.Element {
+	for _, e := range fileTable.Elements {
+		if e.IsDefinition && e.Name == symbolName && isValidRange(e.Range) {
+			return e
+		}
+	}
+	return nil
+}
+
+// readDefinitionContent 读取定义内容，lineLimit > 0 且定义超过该行数时只读取首行作为签名
+func (idx *Indexer) readDefinitionContent(ctx context.Context, d *types.Definition, lineLimit int) []byte {
+	position := types.ToPosition(d.Range)
+	endLine := position.EndLine
+	if lineLimit > 0 && endLine-position.StartLine > lineLimit {
+		endLine = position.StartLine
+	}
+	content, err := idx.workspaceReader.ReadFile(ctx, d.Path, types.ReadOptions{
+		StartLine: position.StartLine,
+		EndLine:   endLine,
+	})
+	if err != nil {
+		idx.logger.Debug("read definition %s content from %s err: %v", d.Name, d.Path, err)
+		return nil
+	}
+	return content
+}
@@ -0,0 +1,98 @@
+package indexer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryExpandedContext(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, mainFile,
+		"package main\n\nfunc Handle() {\n\tx := helperA()\n\thelperB(x)\n\thelperC()\n}\n",
+		[]*codegraphpb.Element{
+			{Name: "Handle", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 6, 1}},
+			{Name: "x", IsDefinition: true, ElementType: codegraphpb.ElementType_VARIABLE, Range: []int32{3, 1, 3, 2}},
+			{Name: "helperA", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 6, 3, 15}},
+			{Name: "helperB", ElementType: codegraphpb.ElementType_CALL, Range: []int32{4, 1, 4, 11}},
+			{Name: "helperC", ElementType: codegraphpb.ElementType_CALL, Range: []int32{5, 1, 5, 10}},
+		})
+
+	utilFile := filepath.Join(workspaceDir, "util.go")
+	helpers := []struct {
+		name  string
+		rng   []int32
+		first string
+	}{
+		{name: "helperA", rng: []int32{2, 0, 4, 1}, first: "func helperA() int {"},
+		{name: "helperB", rng: []int32{6, 0, 7, 1}, first: "func helperB(x int) {"},
+		{name: "helperC", rng: []int32{9, 0, 10, 1}, first: "func helperC() {"},
+	}
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, utilFile,
+		"package main\n\nfunc helperA() int {\n\treturn 1\n}\n\nfunc helperB(x int) {\n}\n\nfunc helperC() {\n}\n",
+		nil)
+	for _, h := range helpers {
+		require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+			Key: store.SymbolNameKey{Language: lang.Go, Name: h.name},
+			Value: &codegraphpb.SymbolOccurrence{Name: h.name, Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+				{Path: utilFile, Range: h.rng, ElementType: codegraphpb.ElementType_FUNCTION},
+			}},
+		}))
+	}
+
+	tests := []struct {
+		name          string
+		symbolName    string
+		maxRefs       int
+		wantRefs      []string
+		wantTruncated bool
+		wantErr       bool
+	}{
+		{name: "默认上限内展开全部引用", symbolName: "Handle", wantRefs: []string{"helperA", "helperB", "helperC"}},
+		{name: "超过上限截断", symbolName: "Handle", maxRefs: 2, wantRefs: []string{"helperA", "helperB"}, wantTruncated: true},
+		{name: "定义不存在", symbolName: "Missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.QueryExpandedContext(ctx, &types.QueryExpandedContextOptions{
+				Workspace:  workspaceDir,
+				FilePath:   mainFile,
+				SymbolName: tt.symbolName,
+				MaxRefs:    tt.maxRefs,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Handle", result.Target.Name)
+			assert.Contains(t, string(result.Target.Content), "func Handle() {")
+			assert.Contains(t, string(result.Target.Content), "helperC()")
+
+			var names []string
+			for _, ref := range result.References {
+				names = append(names, ref.Name)
+				assert.Equal(t, utilFile, ref.Path)
+			}
+			assert.Equal(t, tt.wantRefs, names)
+			assert.Equal(t, tt.wantTruncated, result.Truncated)
+			for i, ref := range result.References {
+				assert.Contains(t, string(ref.Content), helpers[i].first)
+			}
+		})
+	}
+}
@@ -3,15 +3,23 @@ package indexer
 import (
 	"codebase-indexer/internal/repository"
 	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/cache"
+	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/scip"
 	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"codebase-indexer/pkg/logger"
 	"context"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,6 +34,18 @@ type Indexer struct {
 	config              *Config
 	logger              logger.Logger
 	mu                  sync.Mutex
+	externalMu          sync.Mutex // 保护外部索引的延迟打开和关闭
+	externalOpened      bool       // 已尝试打开外部索引，关闭后同样为 true，不再重新打开
+	externalIndexes     []*store.ExternalIndex
+	indexFilters        sync.Map       // 重建事件 ID -> *types.IndexFilter，处理该事件时取出
+	calleeMapBuilds     sync.WaitGroup // 进行中的 callee map 构建，关闭时等待其刷盘
+	closing             atomic.Bool    // 已开始关闭，不再接受新的 callee map 构建
+	closingMu           sync.Mutex     // 保证关闭检查与登记构建原子完成，关闭后不会再有构建登记
+	calleeMapMu         sync.Mutex     // 串行化 callee map 的构建和增量更新
+	workspaceLocks      sync.Map       // 工作区路径 -> *sync.Mutex，串行化同一工作区的索引写入和文件数核对
+	cacheStatsMu        sync.Mutex
+	cacheStats          cache.Stats                                                 // 已结束的索引任务累计的符号缓存计数
+	activeCaches        map[*cache.LRUCache[*codegraphpb.SymbolOccurrence]]struct{} // 进行中的索引任务使用的符号缓存
 }
 
 // NewIndexer 创建新的代码索引器
@@ -52,6 +72,11 @@ func NewIndexer(
 	}
 }
 
+// ctxLogger 返回附加上下文请求ID的日志器，用于查询链路中区分并发请求的日志
+func (idx *Indexer) ctxLogger(ctx context.Context) logger.Logger {
+	return logger.ContextLogger(ctx, idx.logger)
+}
+
 // initConfig 初始化配置，增加环境变量读取逻辑
 func initConfig(config *Config) {
 	// 从环境变量获取MaxConcurrency（环境变量名：MAX_CONCURRENCY）
@@ -92,6 +117,63 @@ func initConfig(config *Config) {
 		config.MaxProjects = DefaultMaxProjects
 	}
 
+	// 从环境变量获取ProjectSelectStrategy（环境变量名：PROJECT_SELECT_STRATEGY）
+	if envVal, ok := os.LookupEnv("PROJECT_SELECT_STRATEGY"); ok {
+		config.ProjectSelectStrategy = ProjectSelectStrategy(strings.TrimSpace(envVal))
+	}
+
+	// 从环境变量获取ProjectAllowlist（环境变量名：PROJECT_ALLOWLIST，逗号分隔）
+	if envVal, ok := os.LookupEnv("PROJECT_ALLOWLIST"); ok {
+		for p := range strings.SplitSeq(envVal, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				config.ProjectAllowlist = append(config.ProjectAllowlist, p)
+			}
+		}
+	}
+
+	// 从环境变量获取ExternalIndexPaths（环境变量名：EXTERNAL_INDEX_PATHS，逗号分隔）
+	if envVal, ok := os.LookupEnv("EXTERNAL_INDEX_PATHS"); ok {
+		for p := range strings.SplitSeq(envVal, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				config.ExternalIndexPaths = append(config.ExternalIndexPaths, p)
+			}
+		}
+	}
+
+	// 从环境变量获取DefinitionPreference（环境变量名：DEFINITION_PREFERENCE，可选 none）
+	if envVal, ok := os.LookupEnv("DEFINITION_PREFERENCE"); ok {
+		config.DefinitionPreference = DefinitionPreference(strings.TrimSpace(envVal))
+	}
+
+	// 从环境变量获取InteropLanguages（环境变量名：INTEROP_LANGUAGES，如 java:kotlin,javascript:typescript，none 表示关闭）
+	if envVal, ok := os.LookupEnv("INTEROP_LANGUAGES"); ok {
+		config.InteropLanguages = parseInteropLanguages(envVal)
+	}
+	if config.InteropLanguages == nil {
+		config.InteropLanguages = DefaultInteropLanguages
+	}
+
+	// 从环境变量获取SkipTestFiles（环境变量名：SKIP_TEST_FILES）
+	if envVal, ok := os.LookupEnv("SKIP_TEST_FILES"); ok {
+		if val, err := strconv.ParseBool(envVal); err == nil {
+			config.SkipTestFiles = val
+		}
+	}
+
+	// 从环境变量获取SkipSymlinks（环境变量名：SKIP_SYMLINKS）
+	if envVal, ok := os.LookupEnv("SKIP_SYMLINKS"); ok {
+		if val, err := strconv.ParseBool(envVal); err == nil {
+			config.SkipSymlinks = val
+		}
+	}
+
+	// 从环境变量获取FollowExternalSymlinks（环境变量名：FOLLOW_EXTERNAL_SYMLINKS）
+	if envVal, ok := os.LookupEnv("FOLLOW_EXTERNAL_SYMLINKS"); ok {
+		if val, err := strconv.ParseBool(envVal); err == nil {
+			config.FollowExternalSymlinks = val
+		}
+	}
+
 	// 从环境变量获取CacheCapacity（环境变量名：CACHE_CAPACITY）
 	if envVal, ok := os.LookupEnv("CACHE_CAPACITY"); ok {
 		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
@@ -101,6 +183,82 @@ func initConfig(config *Config) {
 	if config.CacheCapacity <= 0 {
 		config.CacheCapacity = DefaultCacheCapacity
 	}
+
+	// 从环境变量获取CacheInitCapacity（环境变量名：CACHE_INIT_CAPACITY）
+	if envVal, ok := os.LookupEnv("CACHE_INIT_CAPACITY"); ok {
+		if val, err := strconv.Atoi(envVal); err == nil && val >= 0 {
+			config.CacheInitCapacity = val
+		}
+	}
+	if config.CacheInitCapacity <= 0 {
+		config.CacheInitCapacity = DefaultCacheInitCapacity
+	}
+	config.CacheInitCapacity = utils.Min(config.CacheInitCapacity, config.CacheCapacity)
+
+	// 从环境变量获取CallGraphMaxNodes（环境变量名：CALLGRAPH_MAX_NODES）
+	if envVal, ok := os.LookupEnv("CALLGRAPH_MAX_NODES"); ok {
+		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
+			config.CallGraphMaxNodes = val
+		}
+	}
+	if config.CallGraphMaxNodes <= 0 {
+		config.CallGraphMaxNodes = DefaultCallGraphMaxNodes
+	}
+
+	// 从环境变量获取WalkConcurrency（环境变量名：WALK_CONCURRENCY）
+	if envVal, ok := os.LookupEnv("WALK_CONCURRENCY"); ok {
+		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
+			config.WalkConcurrency = val
+		}
+	}
+	if config.WalkConcurrency <= 0 {
+		config.WalkConcurrency = DefaultWalkConcurrency
+	}
+
+	// 从环境变量获取MemoryLimitMB（环境变量名：MEMORY_LIMIT_MB）
+	if envVal, ok := os.LookupEnv("MEMORY_LIMIT_MB"); ok {
+		if val, err := strconv.Atoi(envVal); err == nil && val >= 0 {
+			config.MemoryLimitMB = val
+		}
+	}
+
+	// 从环境变量获取ParseTimeout（环境变量名：PARSE_TIMEOUT，如 30s、2m）
+	if envVal, ok := os.LookupEnv("PARSE_TIMEOUT"); ok {
+		if val, err := time.ParseDuration(envVal); err == nil && val > 0 {
+			config.ParseTimeout = val
+		}
+	}
+	if config.ParseTimeout <= 0 {
+		config.ParseTimeout = DefaultParseTimeout
+	}
+
+	// 从环境变量获取SourceEncoding（环境变量名：SOURCE_ENCODING，可选 gb18030、latin1）
+	if envVal, ok := os.LookupEnv("SOURCE_ENCODING"); ok {
+		switch encoding := SourceEncoding(strings.ToLower(strings.TrimSpace(envVal))); encoding {
+		case SourceEncodingNone, SourceEncodingGB18030, SourceEncodingLatin1:
+			config.SourceEncoding = encoding
+		}
+	}
+}
+
+// parseInteropLanguages 解析互操作语言组，组之间逗号分隔，组内语言冒号分隔，少于两种语言的组忽略
+func parseInteropLanguages(val string) [][]lang.Language {
+	groups := make([][]lang.Language, 0)
+	if strings.EqualFold(strings.TrimSpace(val), "none") {
+		return groups
+	}
+	for g := range strings.SplitSeq(val, ",") {
+		var group []lang.Language
+		for l := range strings.SplitSeq(g, ":") {
+			if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+				group = append(group, lang.Language(l))
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
 }
 
 // IndexIter 获取索引迭代器
@@ -115,12 +273,76 @@ func (idx *Indexer) GetSummary(ctx context.Context, workspacePath string) (*type
 		return nil, nil
 	}
 	summary := new(types.CodeGraphSummary)
+	reporter, canReportDiskUsage := idx.storage.(store.DiskUsageReporter)
+	indexedProjects, builtProjects := 0, 0
 	for _, p := range projects {
-		summary.TotalFiles += idx.storage.Size(ctx, p.Uuid, store.PathKeySystemPrefix)
+		files := idx.storage.Size(ctx, p.Uuid, store.PathKeySystemPrefix)
+		summary.TotalFiles += files
+		if idx.getFileLimitMeta(ctx, p.Uuid) > 0 {
+			summary.Truncated = true
+		}
+		if files > 0 {
+			indexedProjects++
+			if idx.isCallGraphBuilt(ctx, p.Uuid) {
+				builtProjects++
+			}
+		}
+		if canReportDiskUsage {
+			usage, err := reporter.DiskUsage(p.Uuid)
+			if err != nil {
+				idx.logger.Debug("get project %s index disk usage err: %v", p.Uuid, err)
+				continue
+			}
+			summary.DiskUsage += usage
+		}
 	}
+	summary.CallGraphBuilt = indexedProjects > 0 && builtProjects == indexedProjects
 	return summary, nil
 }
 
+// SymbolCacheStats 返回索引符号缓存的累计命中、未命中、淘汰计数，包括进行中的索引任务
+func (idx *Indexer) SymbolCacheStats() cache.Stats {
+	idx.cacheStatsMu.Lock()
+	defer idx.cacheStatsMu.Unlock()
+	stats := idx.cacheStats
+	for c := range idx.activeCaches {
+		stats.Add(c.Stats())
+	}
+	return stats
+}
+
+// newSymbolCache 创建一次索引任务使用的符号缓存，并登记到计数中，任务结束时调用返回的函数释放
+func (idx *Indexer) newSymbolCache() (*cache.LRUCache[*codegraphpb.SymbolOccurrence], func()) {
+	symbolCache := cache.NewLRUCache[*codegraphpb.SymbolOccurrence](idx.config.CacheInitCapacity, idx.config.CacheCapacity)
+	idx.cacheStatsMu.Lock()
+	if idx.activeCaches == nil {
+		idx.activeCaches = make(map[*cache.LRUCache[*codegraphpb.SymbolOccurrence]]struct{})
+	}
+	idx.activeCaches[symbolCache] = struct{}{}
+	idx.cacheStatsMu.Unlock()
+	return symbolCache, func() {
+		idx.cacheStatsMu.Lock()
+		delete(idx.activeCaches, symbolCache)
+		idx.cacheStats.Add(symbolCache.Stats())
+		idx.cacheStatsMu.Unlock()
+		symbolCache.Purge()
+	}
+}
+
+// ExportSCIP 将工作区索引导出为 SCIP 格式，写入 w
+func (idx *Indexer) ExportSCIP(ctx context.Context, workspacePath string, w io.Writer) error {
+	return scip.NewExporter(idx.workspaceReader, idx.storage, idx.logger).ExportSCIP(ctx, workspacePath, w)
+}
+
+// lockWorkspace 锁定工作区的索引写入，返回解锁函数。
+// 索引、删除索引和文件数核对都会更新 SQLite 中的文件数，串行执行避免互相覆盖
+func (idx *Indexer) lockWorkspace(workspacePath string) func() {
+	lock, _ := idx.workspaceLocks.LoadOrStore(utils.FileURIToPath(workspacePath), &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
 // updateProgress 更新进度
 func (idx *Indexer) updateProgress(ctx context.Context, progress *ProgressInfo) error {
 
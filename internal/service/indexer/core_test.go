@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/types"
 	"os"
 	"testing"
@@ -62,6 +63,35 @@ func TestInitConfig(t *testing.T) {
 				assert.Equal(t, 50, cfg.MaxBatchSize)  // defaultBatchSize
 			},
 		},
+		{
+			name:          "互操作语言组默认值",
+			envVars:       map[string]string{},
+			initialConfig: &Config{},
+			validateConfig: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, DefaultInteropLanguages, cfg.InteropLanguages)
+			},
+		},
+		{
+			name: "从环境变量读取互操作语言组",
+			envVars: map[string]string{
+				"INTEROP_LANGUAGES": " Java:Kotlin , c:cpp:, python",
+			},
+			initialConfig: &Config{},
+			validateConfig: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, [][]lang.Language{{lang.Java, lang.Kotlin}, {lang.C, lang.CPP}}, cfg.InteropLanguages)
+			},
+		},
+		{
+			name: "环境变量关闭互操作语言组",
+			envVars: map[string]string{
+				"INTEROP_LANGUAGES": "none",
+			},
+			initialConfig: &Config{},
+			validateConfig: func(t *testing.T, cfg *Config) {
+				assert.NotNil(t, cfg.InteropLanguages)
+				assert.Empty(t, cfg.InteropLanguages)
+			},
+		},
 		{
 			name:    "保留已设置的正值",
 			envVars: map[string]string{},
@@ -0,0 +1,114 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"slices"
+	"strings"
+)
+
+// fillDefinitionDocs 为定义填充文档注释。文档注释范围在索引时由解析器记录，这里按范围读取源码，
+// 找不到元素、没有文档注释或文件无法读取时保持为空
+func (idx *Indexer) fillDefinitionDocs(ctx context.Context, workspacePath, projectUuid string, definitions []*types.Definition) {
+	byPath := make(map[string][]*types.Definition)
+	for _, d := range definitions {
+		if d != nil && d.Path != types.EmptyString {
+			byPath[d.Path] = append(byPath[d.Path], d)
+		}
+	}
+	for path, defs := range byPath {
+		project, err := idx.getQueryProject(ctx, workspacePath, projectUuid, path)
+		if err != nil {
+			idx.logger.Debug("get project of definition file %s err: %v", path, err)
+			continue
+		}
+		table, err := idx.getFileElementTableByPath(ctx, project.Uuid, path)
+		if err != nil {
+			idx.logger.Debug("get element table of definition file %s err: %v", path, err)
+			continue
+		}
+		var content []byte
+		for _, d := range defs {
+			for _, e := range table.Elements {
+				if !e.IsDefinition || e.Name != d.Name || !slices.Equal(e.Range, d.Range) {
+					continue
+				}
+				docRange, err := proto.GetDocRangeFromExtraData(e.ExtraData)
+				if err != nil || len(docRange) != 4 {
+					break
+				}
+				if content == nil {
+					if content, err = idx.workspaceReader.ReadFile(ctx, path, types.ReadOptions{}); err != nil {
+						idx.logger.Debug("read definition file %s err: %v", path, err)
+						break
+					}
+				}
+				d.Doc = cleanDocComment(sliceByRange(content, docRange))
+				break
+			}
+		}
+	}
+}
+
+// sliceByRange 按 [开始行，开始列，结束行，结束列]（均从0开始，列为字节偏移）截取内容，范围越界时返回空串
+func sliceByRange(content []byte, r []int32) string {
+	lines := strings.Split(string(content), "\n")
+	startLine, startCol, endLine, endCol := int(r[0]), int(r[1]), int(r[2]), int(r[3])
+	if startLine < 0 || endLine >= len(lines) || startLine > endLine ||
+		startCol > len(lines[startLine]) || endCol > len(lines[endLine]) {
+		return types.EmptyString
+	}
+	if startLine == endLine {
+		if startCol > endCol {
+			return types.EmptyString
+		}
+		return lines[startLine][startCol:endCol]
+	}
+	parts := make([]string, 0, endLine-startLine+1)
+	parts = append(parts, lines[startLine][startCol:])
+	parts = append(parts, lines[startLine+1:endLine]...)
+	parts = append(parts, lines[endLine][:endCol])
+	return strings.Join(parts, "\n")
+}
+
+// cleanDocComment 去掉注释符号（//、/* */、/** */、#）和 docstring 引号，返回注释正文
+func cleanDocComment(raw string) string {
+	text := strings.TrimSpace(strings.ReplaceAll(raw, "\r\n", "\n"))
+	// Python docstring，可能带 r、u 等前缀
+	if trimmed := strings.TrimLeft(text, "rRuUbB"); strings.HasPrefix(trimmed, `"`) || strings.HasPrefix(trimmed, "'") {
+		for _, quote := range []string{`"""`, "'''", `"`, "'"} {
+			if strings.HasPrefix(trimmed, quote) && strings.HasSuffix(trimmed, quote) && len(trimmed) >= 2*len(quote) {
+				text = trimmed[len(quote) : len(trimmed)-len(quote)]
+				break
+			}
+		}
+		return joinDocLines(strings.Split(text, "\n"), func(line string) string { return line })
+	}
+	return joinDocLines(strings.Split(text, "\n"), func(line string) string {
+		switch {
+		case strings.HasPrefix(line, "///"):
+			line = line[3:]
+		case strings.HasPrefix(line, "//"):
+			line = line[2:]
+		case strings.HasPrefix(line, "/**"):
+			line = line[3:]
+		case strings.HasPrefix(line, "/*"):
+			line = line[2:]
+		case strings.HasPrefix(line, "*") && !strings.HasPrefix(line, "*/"):
+			line = line[1:]
+		case strings.HasPrefix(line, "#"):
+			line = line[1:]
+		}
+		return strings.TrimSuffix(strings.TrimRight(line, " \t"), "*/")
+	})
+}
+
+// joinDocLines 逐行去掉首尾空白和注释符号后拼接，丢弃首尾的空行
+func joinDocLines(lines []string, strip func(string) string) string {
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		cleaned = append(cleaned, strings.TrimSpace(strip(strings.TrimSpace(line))))
+	}
+	return strings.TrimSpace(strings.Join(cleaned, "\n"))
+}
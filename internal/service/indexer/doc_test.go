@@ -0,0 +1,79 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanDocComment(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "Go 行注释", raw: "// Foo 做某事\n// 第二行", want: "Foo 做某事\n第二行"},
+		{name: "块注释", raw: "/* Foo */", want: "Foo"},
+		{name: "Javadoc", raw: "/**\n * Returns the sum.\n * @param a first\n */", want: "Returns the sum.\n@param a first"},
+		{name: "Python docstring", raw: "\"\"\"\n    Say hello.\n\n    Details.\n    \"\"\"", want: "Say hello.\n\nDetails."},
+		{name: "单引号 docstring", raw: "'one line'", want: "one line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cleanDocComment(tt.raw))
+		})
+	}
+}
+
+func TestQueryDefinitionsIncludeDoc(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"main.go": "package main\n\nvar x = 1 // trailing\n\n// Documented 返回固定值\n// 第二行\nfunc Documented() int {\n\treturn 1\n}\n\n" +
+			"// 与下面的函数之间有空行\n\nfunc Undocumented() {}\n\n/* BlockDoc 块注释 */\nfunc BlockDoc() {}\n",
+		"util.py": "def greet(name):\n    \"\"\"Say hello.\"\"\"\n    return name\n\n\ndef plain():\n    return 1\n",
+		"Calc.java": "public class Calc {\n    /**\n     * Adds two numbers.\n     */\n    public int add(int a, int b) {\n" +
+			"        return a + b;\n    }\n\n    public int sub(int a, int b) {\n        return a - b;\n    }\n}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		symbol     string
+		includeDoc bool
+		wantDoc    string
+	}{
+		{name: "Go 函数注释", symbol: "Documented", includeDoc: true, wantDoc: "Documented 返回固定值\n第二行"},
+		{name: "空行隔开的注释不属于函数", symbol: "Undocumented", includeDoc: true, wantDoc: ""},
+		{name: "Go 块注释", symbol: "BlockDoc", includeDoc: true, wantDoc: "BlockDoc 块注释"},
+		{name: "Python docstring", symbol: "greet", includeDoc: true, wantDoc: "Say hello."},
+		{name: "Python 无 docstring", symbol: "plain", includeDoc: true, wantDoc: ""},
+		{name: "Javadoc", symbol: "add", includeDoc: true, wantDoc: "Adds two numbers."},
+		{name: "Java 无 Javadoc", symbol: "sub", includeDoc: true, wantDoc: ""},
+		{name: "未指定 IncludeDoc 时不填充", symbol: "Documented", includeDoc: false, wantDoc: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace:   workspaceDir,
+				SymbolNames: tt.symbol,
+				IncludeDoc:  tt.includeDoc,
+			})
+			require.NoError(t, err)
+			require.Len(t, definitions, 1)
+			assert.Equal(t, tt.wantDoc, definitions[0].Doc)
+		})
+	}
+}
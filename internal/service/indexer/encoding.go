@@ -0,0 +1,160 @@
+package indexer
+
+import (
+	"bytes"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"errors"
+	"io/fs"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 跳过解析的原因
+const (
+	SkipReasonBinary           = "binary"
+	SkipReasonEmpty            = "empty"
+	SkipReasonPermissionDenied = "permission-denied"
+	SkipReasonNotFound         = "not-found"
+)
+
+// binarySniffLen 检测二进制内容时检查的前缀长度（与 git 一致）
+const binarySniffLen = 8000
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// normalizeEncoding 解析前检测文件编码：去除 UTF-8 BOM，按 BOM 转码 UTF-16；只有含空字节的视为二进制跳过，
+// 其它非 UTF-8 文本按配置的 encoding 转码（见 decodeLegacyText）。返回转码后的内容和跳过原因（不跳过时为空）。
+// 行号不受转码影响；列和字节偏移按返回的内容计算，只有发生转码时才与磁盘上的文件字节不同，
+// 按字节偏移查询时用同一函数处理文件内容（见 convertByteOffsets），与索引中的位置一致
+func normalizeEncoding(content []byte, encoding SourceEncoding) ([]byte, string) {
+	switch {
+	case bytes.HasPrefix(content, utf8BOM):
+		content = content[len(utf8BOM):]
+	case bytes.HasPrefix(content, utf16LEBOM):
+		return decodeUTF16(content[len(utf16LEBOM):], false)
+	case bytes.HasPrefix(content, utf16BEBOM):
+		return decodeUTF16(content[len(utf16BEBOM):], true)
+	}
+
+	if bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) != -1 {
+		return nil, SkipReasonBinary
+	}
+	if utf8.Valid(content) {
+		return content, ""
+	}
+	return decodeLegacyText(content, encoding), ""
+}
+
+// decodeLegacyText 按配置的编码转码非 UTF-8 文本。字节序列无法区分 Latin-1 和 GBK 等编码（如 0xE9 0x73），不做猜测：
+// 未配置编码或按配置编码解码失败时不转码，只把无法解码的字节逐个替换为 '?'，长度不变，位置与源文件字节一致
+func decodeLegacyText(content []byte, encoding SourceEncoding) []byte {
+	switch encoding {
+	case SourceEncodingGB18030:
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(content)
+		if err == nil && !bytes.ContainsRune(decoded, utf8.RuneError) {
+			return decoded
+		}
+	case SourceEncodingLatin1:
+		return decodeLatin1(content)
+	}
+	return replaceInvalidUTF8(content)
+}
+
+// replaceInvalidUTF8 将不构成合法 UTF-8 的字节逐个替换为 '?'，合法字符保持不变，
+// 保证元素名等字段可以写入索引（protobuf 的 string 字段要求合法 UTF-8）
+func replaceInvalidUTF8(content []byte) []byte {
+	replaced := make([]byte, len(content))
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRune(content[i:])
+		if r == utf8.RuneError && size == 1 {
+			replaced[i] = '?'
+		} else {
+			copy(replaced[i:], content[i:i+size])
+		}
+		i += size
+	}
+	return replaced
+}
+
+// decodeUTF16 将 UTF-16 内容转为 UTF-8，长度为奇数时视为二进制
+func decodeUTF16(content []byte, bigEndian bool) ([]byte, string) {
+	if len(content)%2 != 0 {
+		return nil, SkipReasonBinary
+	}
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(content[2*i])<<8 | uint16(content[2*i+1])
+		} else {
+			units[i] = uint16(content[2*i+1])<<8 | uint16(content[2*i])
+		}
+	}
+	runes := utf16.Decode(units)
+	decoded := make([]byte, 0, len(runes))
+	for _, r := range runes {
+		if r == 0 {
+			return nil, SkipReasonBinary
+		}
+		decoded = utf8.AppendRune(decoded, r)
+	}
+	return decoded, ""
+}
+
+// decodeLatin1 将 Latin-1（ISO-8859-1）内容转为 UTF-8，每个字节即一个码点
+func decodeLatin1(content []byte) []byte {
+	decoded := make([]byte, 0, len(content)+len(content)/4)
+	for _, b := range content {
+		decoded = utf8.AppendRune(decoded, rune(b))
+	}
+	return decoded
+}
+
+// unreadableReason 返回读取文件出错时应跳过的原因，无权限和文件不存在之外的错误返回空串，按解析失败处理
+func unreadableReason(err error) string {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return SkipReasonPermissionDenied
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, workspace.ErrPathNotExists):
+		return SkipReasonNotFound
+	default:
+		return types.EmptyString
+	}
+}
+
+// addSkippedFile 记录一个跳过解析的文件及原因
+func addSkippedFile(metrics *types.IndexTaskMetrics, path, reason string) {
+	metrics.TotalSkippedFiles++
+	switch reason {
+	case SkipReasonEmpty:
+		metrics.TotalSkippedEmptyFiles++
+	case SkipReasonPermissionDenied, SkipReasonNotFound:
+		metrics.TotalSkippedUnreadableFiles++
+	}
+	if metrics.SkippedFiles == nil {
+		metrics.SkippedFiles = make(map[string]string)
+	}
+	metrics.SkippedFiles[path] = reason
+}
+
+// mergeSkippedFiles 合并跳过解析的文件统计
+func mergeSkippedFiles(dst, src *types.IndexTaskMetrics) {
+	dst.TotalSkippedFiles += src.TotalSkippedFiles
+	dst.TotalSkippedEmptyFiles += src.TotalSkippedEmptyFiles
+	dst.TotalSkippedUnreadableFiles += src.TotalSkippedUnreadableFiles
+	if len(src.SkippedFiles) == 0 {
+		return
+	}
+	if dst.SkippedFiles == nil {
+		dst.SkippedFiles = make(map[string]string, len(src.SkippedFiles))
+	}
+	for path, reason := range src.SkippedFiles {
+		dst.SkippedFiles[path] = reason
+	}
+}
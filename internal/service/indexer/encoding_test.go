@@ -0,0 +1,167 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/parser"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEncoding(t *testing.T) {
+	// "café" 的 Latin-1 编码，0xE9 后没有合法的 GB18030 尾字节
+	latin1 := []byte{'c', 'a', 'f', 0xE9}
+	// "és" 的 Latin-1 编码，同时也是合法的 GBK 双字节字符
+	ambiguous := []byte{0xE9, 's'}
+	// "// 中文" 的 GBK 编码
+	gbk := []byte{'/', '/', ' ', 0xD6, 0xD0, 0xCE, 0xC4}
+	tests := []struct {
+		name       string
+		content    []byte
+		encoding   SourceEncoding
+		want       string
+		wantReason string
+	}{
+		{name: "UTF-8", content: []byte("x = 'café'"), want: "x = 'café'"},
+		{name: "空文件", content: []byte{}, want: ""},
+		{name: "去除 UTF-8 BOM", content: append([]byte{0xEF, 0xBB, 0xBF}, "x = 1"...), want: "x = 1"},
+		{name: "UTF-16 LE BOM 转码", content: []byte{0xFF, 0xFE, 'x', 0, '=', 0, 0xE9, 0}, want: "x=é"},
+		{name: "UTF-16 BE BOM 转码", content: []byte{0xFE, 0xFF, 0, 'x', 0, '=', 0, 0xE9}, want: "x=é"},
+		{name: "含空字节视为二进制", content: []byte{0x7F, 'E', 'L', 'F', 0, 0, 1}, wantReason: SkipReasonBinary},
+		{name: "未配置编码时不猜测 GBK", content: ambiguous, want: "?s"},
+		{name: "未配置编码时逐字节替换无法解码的字节", content: gbk, want: "// ????"},
+		{name: "按配置的 Latin-1 转码", content: ambiguous, encoding: SourceEncodingLatin1, want: "és"},
+		{name: "按配置的 GB18030 转码", content: gbk, encoding: SourceEncodingGB18030, want: "// 中文"},
+		{name: "按配置的 GB18030 解码失败时不转码", content: latin1, encoding: SourceEncodingGB18030, want: "caf?"},
+		{name: "非 UTF-8 的二进制仍跳过", content: []byte{0xE9, 0, 0xE9}, wantReason: SkipReasonBinary},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := normalizeEncoding(tt.content, tt.encoding)
+			assert.Equal(t, tt.wantReason, reason)
+			if tt.wantReason == "" {
+				assert.Equal(t, tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestParseFilesWithNonUTF8Content(t *testing.T) {
+	ctx := context.Background()
+	idx, _ := newTestIndexerWithStorage(t)
+	idx.parser = parser.NewSourceFileParser(idx.logger)
+	dir := t.TempDir()
+
+	// Latin-1 编码的 Python 文件：注释中含 é
+	latin1File := filepath.Join(dir, "latin1.py")
+	require.NoError(t, os.WriteFile(latin1File, []byte("# caf\xe9\ndef hello():\n    return 1\n"), 0644))
+	// GBK 编码的 Go 文件：注释中含中文
+	gbkFile := filepath.Join(dir, "gbk.go")
+	require.NoError(t, os.WriteFile(gbkFile, []byte("package main\n\n// \xd6\xd0\xce\xc4\nfunc World() {}\n"), 0644))
+	binaryFile := filepath.Join(dir, "blob.py")
+	require.NoError(t, os.WriteFile(binaryFile, []byte{0x7F, 'E', 'L', 'F', 0, 0, 1, 0}, 0644))
+	files := []*types.FileWithModTimestamp{{Path: latin1File}, {Path: gbkFile}, {Path: binaryFile}}
+
+	tables, metrics, err := idx.parseFiles(ctx, files)
+	require.NoError(t, err)
+	assert.Equal(t, 1, metrics.TotalSkippedFiles)
+	assert.Equal(t, 0, metrics.TotalFailedFiles)
+	assert.Equal(t, map[string]string{binaryFile: SkipReasonBinary}, metrics.SkippedFiles)
+	names := make(map[string][]string)
+	for _, table := range tables {
+		for _, e := range table.Elements {
+			names[table.Path] = append(names[table.Path], e.GetName())
+		}
+	}
+	assert.Len(t, names, 2)
+	assert.Contains(t, names[latin1File], "hello")
+	assert.Contains(t, names[gbkFile], "World")
+}
+
+func TestParseFilesNonUTF8Positions(t *testing.T) {
+	ctx := context.Background()
+	// 函数声明前的注释含 Latin-1 的 é（1 字节，转码后为 2 字节）
+	content := []byte("package main\n\n/* caf\xe9 */ func World() {}\n")
+	tests := []struct {
+		name       string
+		encoding   SourceEncoding
+		wantColumn int32
+	}{
+		// 不转码时列与磁盘上的文件字节一致
+		{name: "默认不转码", encoding: SourceEncodingNone, wantColumn: 11},
+		// 转码后列按 UTF-8 内容计算，行号不变
+		{name: "按配置转码", encoding: SourceEncodingLatin1, wantColumn: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, _ := newTestIndexerWithStorage(t)
+			idx.parser = parser.NewSourceFileParser(idx.logger)
+			idx.config.SourceEncoding = tt.encoding
+			file := filepath.Join(t.TempDir(), "main.go")
+			require.NoError(t, os.WriteFile(file, content, 0644))
+
+			tables, _, err := idx.parseFiles(ctx, []*types.FileWithModTimestamp{{Path: file}})
+			require.NoError(t, err)
+			require.Len(t, tables, 1)
+			var world []int32
+			for _, e := range tables[0].Elements {
+				if e.GetName() == "World" && e.GetType() == types.ElementTypeFunction {
+					world = e.GetRange()
+				}
+			}
+			require.NotNil(t, world)
+			assert.Equal(t, int32(2), world[0])
+			assert.Equal(t, tt.wantColumn, world[1])
+		})
+	}
+}
+
+func TestParseFilesSkipEmptyAndUnreadable(t *testing.T) {
+	ctx := context.Background()
+	idx, _ := newTestIndexerWithStorage(t)
+	idx.parser = parser.NewSourceFileParser(idx.logger)
+	dir := t.TempDir()
+
+	emptyFile := filepath.Join(dir, "empty.go")
+	require.NoError(t, os.WriteFile(emptyFile, nil, 0644))
+	mainFile := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(mainFile, []byte("package main\n\nfunc main() {}\n"), 0644))
+	removedFile := filepath.Join(dir, "removed.go")
+	files := []*types.FileWithModTimestamp{{Path: emptyFile}, {Path: mainFile}, {Path: removedFile}}
+
+	tables, metrics, err := idx.parseFiles(ctx, files)
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, mainFile, tables[0].Path)
+	assert.Equal(t, 0, metrics.TotalFailedFiles)
+	assert.Equal(t, 2, metrics.TotalSkippedFiles)
+	assert.Equal(t, 1, metrics.TotalSkippedEmptyFiles)
+	assert.Equal(t, 1, metrics.TotalSkippedUnreadableFiles)
+	assert.Equal(t, map[string]string{emptyFile: SkipReasonEmpty, removedFile: SkipReasonNotFound}, metrics.SkippedFiles)
+}
+
+func TestUnreadableReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "无权限", err: &fs.PathError{Op: "open", Path: "a.go", Err: fs.ErrPermission}, want: SkipReasonPermissionDenied},
+		{name: "文件不存在", err: &fs.PathError{Op: "open", Path: "a.go", Err: fs.ErrNotExist}, want: SkipReasonNotFound},
+		{name: "工作区路径不存在", err: workspace.ErrPathNotExists, want: SkipReasonNotFound},
+		{name: "其他错误按失败处理", err: errors.New("io error"), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, unreadableReason(tt.err))
+		})
+	}
+}
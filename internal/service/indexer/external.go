@@ -0,0 +1,82 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// getExternalIndexes 延迟打开配置的外部只读索引，打开失败的目录跳过；关闭后返回空
+func (idx *Indexer) getExternalIndexes() []*store.ExternalIndex {
+	idx.externalMu.Lock()
+	defer idx.externalMu.Unlock()
+	if idx.externalOpened {
+		return idx.externalIndexes
+	}
+	idx.externalOpened = true
+	for _, dir := range idx.config.ExternalIndexPaths {
+		external, err := store.OpenExternalIndex(dir, idx.logger)
+		if err != nil {
+			idx.logger.Error("failed to open external index %s, err: %v", dir, err)
+			continue
+		}
+		idx.externalIndexes = append(idx.externalIndexes, external)
+	}
+	return idx.externalIndexes
+}
+
+// closeExternalIndexes 关闭已打开的外部索引，之后不再打开
+func (idx *Indexer) closeExternalIndexes() error {
+	idx.externalMu.Lock()
+	defer idx.externalMu.Unlock()
+	idx.externalOpened = true
+	var errs []error
+	for _, external := range idx.externalIndexes {
+		if err := external.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close external index %s: %w", external.Dir(), err))
+		}
+	}
+	idx.externalIndexes = nil
+	return errors.Join(errs...)
+}
+
+// queryExternalDefinitions 本地未找到符号时，按配置顺序在外部索引中查找定义，命中第一个外部索引即返回
+func (idx *Indexer) queryExternalDefinitions(ctx context.Context, languages []lang.Language, symbolName string) []*types.Definition {
+	for _, external := range idx.getExternalIndexes() {
+		var results []*types.Definition
+		for _, language := range languages {
+			values, err := external.GetAll(ctx, store.SymbolNameKey{Language: language, Name: symbolName})
+			if err != nil {
+				if !errors.Is(err, store.ErrKeyNotFound) {
+					idx.logger.Debug("get symbol %s from external index %s err: %v", symbolName, external.Dir(), err)
+				}
+				continue
+			}
+			for _, value := range values {
+				var exist codegraphpb.SymbolOccurrence
+				if err = store.UnmarshalValue(value, &exist); err != nil {
+					idx.logger.Debug("unmarshal external symbol occurrence err: %v", err)
+					continue
+				}
+				for _, o := range exist.Occurrences {
+					results = append(results, &types.Definition{
+						Path:  o.Path,
+						Name:  symbolName,
+						Range: o.Range,
+						Type:  string(proto.ToDefinitionElementType(proto.ElementTypeFromProto(o.ElementType))),
+					})
+				}
+			}
+		}
+		if len(results) > 0 {
+			idx.logger.Debug("symbol %s resolved in external index %s", symbolName, external.Dir())
+			return results
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package indexer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestQueryDefinitionsWithExternalIndex(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+
+	// 本地索引
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	localFile := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key: store.SymbolNameKey{Language: lang.Go, Name: "LocalFunc"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "LocalFunc", Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+			{Path: localFile, Range: []int32{2, 0, 4, 1}, ElementType: codegraphpb.ElementType_FUNCTION},
+		}},
+	}))
+
+	// 外部索引：由另一个存储实例构建后关闭，以只读方式挂载
+	externalDir := t.TempDir()
+	externalStorage, err := store.NewLevelDBStorage(externalDir, idx.logger)
+	require.NoError(t, err)
+	sharedFile := "/shared/lib/strings.go"
+	require.NoError(t, externalStorage.Put(ctx, "shared-lib-uuid", &store.Entry{
+		Key: store.SymbolNameKey{Language: lang.Go, Name: "SharedFunc"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "SharedFunc", Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+			{Path: sharedFile, Range: []int32{10, 0, 12, 1}, ElementType: codegraphpb.ElementType_FUNCTION},
+		}},
+	}))
+	require.NoError(t, externalStorage.Put(ctx, "shared-lib-uuid", &store.Entry{
+		Key: store.SymbolNameKey{Language: lang.Go, Name: "LocalFunc"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "LocalFunc", Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+			{Path: sharedFile, Range: []int32{20, 0, 22, 1}, ElementType: codegraphpb.ElementType_FUNCTION},
+		}},
+	}))
+	// schema 版本与当前不一致的外部项目不参与查询
+	require.NoError(t, externalStorage.Put(ctx, "outdated-lib-uuid", &store.Entry{
+		Key: store.SymbolNameKey{Language: lang.Go, Name: "OutdatedFunc"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "OutdatedFunc", Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+			{Path: "/outdated/lib.go", Range: []int32{1, 0, 2, 1}, ElementType: codegraphpb.ElementType_FUNCTION},
+		}},
+	}))
+	for projectUuid, version := range map[string]string{"shared-lib-uuid": store.CurrentSchemaVersion, "outdated-lib-uuid": "1"} {
+		require.NoError(t, externalStorage.Put(ctx, projectUuid, &store.Entry{
+			Key:   store.ProjectMetaKey{MetaType: store.MetaTypeSchemaVersion},
+			Value: wrapperspb.String(version),
+		}))
+	}
+	require.NoError(t, externalStorage.Close())
+
+	idx.config.ExternalIndexPaths = []string{filepath.Join(t.TempDir(), "missing"), externalDir}
+
+	tests := []struct {
+		name        string
+		symbolNames string
+		wantPaths   []string
+	}{
+		{name: "本地存在的符号不查询外部索引", symbolNames: "LocalFunc", wantPaths: []string{localFile}},
+		{name: "仅外部索引定义的符号", symbolNames: "SharedFunc", wantPaths: []string{sharedFile}},
+		{name: "合并本地和外部结果", symbolNames: "LocalFunc,SharedFunc", wantPaths: []string{localFile, sharedFile}},
+		{name: "都不存在", symbolNames: "MissingFunc", wantPaths: nil},
+		{name: "schema 版本不一致的外部项目跳过", symbolNames: "OutdatedFunc", wantPaths: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace:   workspaceDir,
+				SymbolNames: tt.symbolNames,
+			})
+			require.NoError(t, err)
+			var paths []string
+			for _, d := range definitions {
+				paths = append(paths, d.Path)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+
+	definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{Workspace: workspaceDir, SymbolNames: "SharedFunc"})
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+	assert.Equal(t, []int32{10, 0, 12, 1}, definitions[0].Range)
+	assert.Equal(t, string(types.ElementTypeFunction), definitions[0].Type)
+
+	// 关闭后释放外部索引，构建外部索引的实例可以重新写入
+	require.NoError(t, idx.Shutdown(ctx))
+	assert.Empty(t, idx.getExternalIndexes())
+	writer, err := store.NewLevelDBStorageWithOptions(externalDir, idx.logger, store.LevelDBOptions{})
+	require.NoError(t, err)
+	defer writer.Close()
+	assert.NoError(t, writer.Put(ctx, "shared-lib-uuid", &store.Entry{
+		Key:   store.SymbolNameKey{Language: lang.Go, Name: "NewFunc"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "NewFunc", Language: string(lang.Go)},
+	}))
+}
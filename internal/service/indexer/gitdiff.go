@@ -0,0 +1,131 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// git diff --name-status 输出的变更类型
+const (
+	gitStatusAdded    = 'A'
+	gitStatusCopied   = 'C'
+	gitStatusDeleted  = 'D'
+	gitStatusModified = 'M'
+	gitStatusRenamed  = 'R'
+	gitStatusTypeChg  = 'T'
+)
+
+// gitFileChange 一个文件的 git 变更，路径为绝对路径，OldPath 仅重命名时有值
+type gitFileChange struct {
+	Status  byte
+	Path    string
+	OldPath string
+}
+
+// IndexChangedSince 以 git 引用为基准，只更新工作区内发生变更的文件索引。
+// 新增、修改的文件重新索引，删除的文件移除索引，重命名的文件先迁移索引再重新索引；
+// 变更范围包含已暂存和未暂存的改动，不包含未跟踪的文件
+func (idx *Indexer) IndexChangedSince(ctx context.Context, workspacePath string, gitRef string) (*types.IndexTaskMetrics, error) {
+	start := time.Now()
+	workspacePath = utils.FileURIToPath(workspacePath)
+	if gitRef == "" || strings.HasPrefix(gitRef, "-") {
+		return nil, fmt.Errorf("invalid git ref %q", gitRef)
+	}
+	changes, err := gitDiffChanges(ctx, workspacePath, gitRef)
+	if err != nil {
+		return nil, err
+	}
+	idx.logger.Info("workspace %s has %d changed files since %s", workspacePath, len(changes), gitRef)
+
+	var indexPaths, removePaths []string
+	var errs []error
+	for _, c := range changes {
+		switch c.Status {
+		case gitStatusDeleted:
+			removePaths = append(removePaths, c.Path)
+		case gitStatusRenamed:
+			// 重命名后内容可能也有变化，迁移索引后再按新路径重新索引
+			if err := idx.RenameIndexes(ctx, workspacePath, c.OldPath, c.Path); err != nil {
+				errs = append(errs, err)
+			}
+			indexPaths = append(indexPaths, c.Path)
+		case gitStatusAdded, gitStatusCopied, gitStatusModified, gitStatusTypeChg:
+			indexPaths = append(indexPaths, c.Path)
+		}
+	}
+
+	if len(removePaths) > 0 {
+		if err := idx.RemoveIndexes(ctx, workspacePath, removePaths); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(indexPaths) > 0 {
+		if err := idx.IndexFiles(ctx, workspacePath, indexPaths); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err = errors.Join(errs...)
+	idx.logger.Info("index workspace %s changes since %s end, cost %d ms, indexed %d files, removed %d files, errors: %v",
+		workspacePath, gitRef, time.Since(start).Milliseconds(), len(indexPaths), len(removePaths), utils.TruncateError(err))
+	return &types.IndexTaskMetrics{TotalFiles: len(indexPaths)}, err
+}
+
+// gitDiffChanges 执行 git diff，返回工作区相对 gitRef 的文件变更，路径限定在工作区内并转为绝对路径
+func gitDiffChanges(ctx context.Context, workspacePath string, gitRef string) ([]*gitFileChange, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", workspacePath, "diff", "--name-status", "-z", "-M",
+		"--relative", gitRef, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git diff %s in %s failed: %w: %s", gitRef, workspacePath, err,
+				strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git diff %s in %s failed: %w", gitRef, workspacePath, err)
+	}
+	return parseGitNameStatus(workspacePath, output)
+}
+
+// parseGitNameStatus 解析 git diff --name-status -z 的输出。
+// 每项为状态字段后跟一个路径，重命名和复制（R100、C75 等）后跟旧路径和新路径，字段间以 NUL 分隔
+func parseGitNameStatus(workspacePath string, output []byte) ([]*gitFileChange, error) {
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		return nil, nil
+	}
+	toAbs := func(p string) string {
+		return filepath.Join(workspacePath, filepath.FromSlash(p))
+	}
+	var changes []*gitFileChange
+	for i := 0; i < len(fields); {
+		status := fields[i]
+		if status == "" {
+			return nil, fmt.Errorf("unexpected empty git status at field %d", i)
+		}
+		c := &gitFileChange{Status: status[0]}
+		switch c.Status {
+		case gitStatusRenamed, gitStatusCopied:
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("git status %s missing paths", status)
+			}
+			c.OldPath, c.Path = toAbs(fields[i+1]), toAbs(fields[i+2])
+			i += 3
+		default:
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("git status %s missing path", status)
+			}
+			c.Path = toAbs(fields[i+1])
+			i += 2
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
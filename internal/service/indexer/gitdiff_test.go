@@ -0,0 +1,137 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// savedPathsStorage 记录批量保存过的文件元素表路径
+type savedPathsStorage struct {
+	store.GraphStorage
+	paths []string
+}
+
+func (s *savedPathsStorage) BatchSave(ctx context.Context, projectUuid string, values store.Entries) error {
+	for i := 0; i < values.Len(); i++ {
+		if key, ok := values.Key(i).(store.ElementPathKey); ok {
+			s.paths = append(s.paths, key.Path)
+		}
+	}
+	return s.GraphStorage.BatchSave(ctx, projectUuid, values)
+}
+
+// runGit 在 dir 中执行 git 命令
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	output, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestParseGitNameStatus(t *testing.T) {
+	workspaceDir := filepath.FromSlash("/ws")
+	tests := []struct {
+		name    string
+		output  string
+		want    []*gitFileChange
+		wantErr bool
+	}{
+		{name: "无变更", output: "", want: nil},
+		{
+			name:   "新增修改删除和重命名",
+			output: "A\x00a.go\x00M\x00sub/b.go\x00D\x00c.go\x00R087\x00old.go\x00new name.go\x00",
+			want: []*gitFileChange{
+				{Status: gitStatusAdded, Path: filepath.Join(workspaceDir, "a.go")},
+				{Status: gitStatusModified, Path: filepath.Join(workspaceDir, "sub", "b.go")},
+				{Status: gitStatusDeleted, Path: filepath.Join(workspaceDir, "c.go")},
+				{Status: gitStatusRenamed, OldPath: filepath.Join(workspaceDir, "old.go"),
+					Path: filepath.Join(workspaceDir, "new name.go")},
+			},
+		},
+		{name: "重命名缺少新路径", output: "R100\x00old.go\x00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := parseGitNameStatus(workspaceDir, []byte(tt.output))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, changes)
+		})
+	}
+}
+
+func TestIndexChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	pathOf := func(name string) string { return filepath.Join(workspaceDir, name) }
+	files := map[string]string{
+		"keep.go":   "package main\n\nfunc Keep() {}\n",
+		"modify.go": "package main\n\nfunc Modify() {}\n",
+		"delete.go": "package main\n\nfunc Delete() {}\n",
+		"rename.go": "package main\n\nfunc Rename() {}\n\nfunc RenameHelper() {}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(pathOf(name), []byte(content), 0644))
+	}
+	runGit(t, workspaceDir, "init", "-q")
+	runGit(t, workspaceDir, "add", "-A")
+	runGit(t, workspaceDir, "commit", "-q", "-m", "init")
+
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	// 暂存修改、新增、删除和重命名
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(pathOf("modify.go"), []byte("package main\n\nfunc Modified() {}\n"), 0644))
+	require.NoError(t, os.Chtimes(pathOf("modify.go"), future, future))
+	require.NoError(t, os.WriteFile(pathOf("add.go"), []byte("package main\n\nfunc Add() {}\n"), 0644))
+	runGit(t, workspaceDir, "rm", "-q", "delete.go")
+	runGit(t, workspaceDir, "mv", "rename.go", "renamed.go")
+	runGit(t, workspaceDir, "add", "-A")
+
+	recorder := &savedPathsStorage{GraphStorage: storage}
+	idx.storage = recorder
+	metrics, err := idx.IndexChangedSince(ctx, workspaceDir, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, 3, metrics.TotalFiles)
+
+	sort.Strings(recorder.paths)
+	assert.Equal(t, []string{pathOf("add.go"), pathOf("modify.go"), pathOf("renamed.go")}, recorder.paths)
+
+	projectUuid := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir).Uuid
+	indexed := func(name string) bool {
+		_, err := idx.getFileElementTable(ctx, projectUuid, lang.Go, pathOf(name))
+		return err == nil
+	}
+	for _, name := range []string{"keep.go", "modify.go", "add.go", "renamed.go"} {
+		assert.True(t, indexed(name), name)
+	}
+	for _, name := range []string{"delete.go", "rename.go"} {
+		assert.False(t, indexed(name), name)
+	}
+
+	t.Run("非法引用", func(t *testing.T) {
+		_, err := idx.IndexChangedSince(ctx, workspaceDir, "--output=/tmp/x")
+		assert.Error(t, err)
+	})
+}
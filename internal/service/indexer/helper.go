@@ -1,6 +1,8 @@
 package indexer
 
 import (
+	"bytes"
+	"codebase-indexer/internal/errs"
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/store"
@@ -15,8 +17,11 @@ import (
 	"strings"
 )
 
-// NormalizeLineRange 标准化行范围
+// NormalizeLineRange 标准化行范围，maxLimit 小于 1 时按 1 处理
 func NormalizeLineRange(start, end, maxLimit int) (int, int) {
+	if maxLimit < 1 {
+		maxLimit = 1
+	}
 	// 确保最小为 1
 	if start <= 0 {
 		start = 1
@@ -38,6 +43,14 @@ func NormalizeLineRange(start, end, maxLimit int) (int, int) {
 	return start, end
 }
 
+// lineLimitOrDefault 返回有效的行范围最大跨度，limit 小于等于0时使用默认值，超过 MaxLineLimitCeiling 时按上限处理
+func lineLimitOrDefault(limit, defaultLimit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	return min(limit, MaxLineLimitCeiling)
+}
+
 // isValidRange 验证范围
 func isValidRange(range_ []int32) bool {
 	return len(range_) == 4
@@ -60,6 +73,33 @@ func symbolMapKey(filePath string, ranges []int32) string {
 	return filePath + "-" + utils.SliceToString(ranges)
 }
 
+// convertByteOffsets 与索引时一致地读取文件内容（工作区读取器读取、按 normalizeEncoding 处理编码），
+// 将字节偏移 [StartByte, EndByte) 换算为行列（均从1开始）
+func (idx *Indexer) convertByteOffsets(ctx context.Context, opts *types.QueryDefinitionOptions) error {
+	content, err := idx.workspaceReader.ReadFile(ctx, opts.FilePath, types.ReadOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", opts.FilePath, err)
+	}
+	content, skipReason := normalizeEncoding(content, idx.config.SourceEncoding)
+	if skipReason != types.EmptyString {
+		return fmt.Errorf("failed to read file %s: %s", opts.FilePath, skipReason)
+	}
+	if opts.StartByte < 0 || opts.StartByte >= opts.EndByte || opts.EndByte > len(content) {
+		return errs.NewInvalidParamErr("byte range", fmt.Sprintf("[%d, %d)", opts.StartByte, opts.EndByte))
+	}
+	opts.StartLine, opts.StartColumn = byteOffsetToLineColumn(content, opts.StartByte)
+	opts.EndLine, opts.EndColumn = byteOffsetToLineColumn(content, opts.EndByte-1)
+	return nil
+}
+
+// byteOffsetToLineColumn 字节偏移换算为行号和列号（均从1开始），列按字节计算，与解析结果的列一致
+func byteOffsetToLineColumn(content []byte, offset int) (int, int) {
+	prefix := content[:offset]
+	line := bytes.Count(prefix, []byte{'\n'}) + 1
+	lineStart := bytes.LastIndexByte(prefix, '\n') + 1
+	return line, offset - lineStart + 1
+}
+
 // safeFilePath 安全文件路径，防止目录遍历攻击
 func safeFilePath(workspace, relativeFilePath string) (string, error) {
 	root, err := os.OpenInRoot(workspace, relativeFilePath)
@@ -70,41 +110,52 @@ func safeFilePath(workspace, relativeFilePath string) (string, error) {
 	return filepath.Join(workspace, relativeFilePath), nil
 }
 
-// groupFilesByProject 根据项目对文件进行分组
-func (idx *Indexer) groupFilesByProject(projects []*workspace.Project, filePaths []string) (map[string][]string, error) {
-	projectFilesMap := make(map[string][]string)
-	var errs []error
-
-	for _, p := range projects {
-		for _, filePath := range filePaths {
-			if strings.HasPrefix(filePath, p.Path) {
-				projectUuid := p.Uuid
-				files, ok := projectFilesMap[projectUuid]
-				if !ok {
-					files = make([]string, 0)
-				}
-				files = append(files, filePath)
-				projectFilesMap[projectUuid] = files
-			}
+// normalizeFilePaths 规范化请求中的文件路径（file:// URI、相对路径、分隔符），保证与项目路径的前缀匹配一致，
+// 不在工作区内的路径忽略
+func normalizeFilePaths(workspacePath string, filePaths []string) []string {
+	normalized := make([]string, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		if filePath, err := utils.NormalizeFilePath(workspacePath, filePath); err == nil && filePath != types.EmptyString {
+			normalized = append(normalized, filePath)
 		}
 	}
+	return normalized
+}
 
-	if len(errs) > 0 {
-		return nil, errors.Join(errs...)
+// groupFilesByProject 根据项目对文件进行分组，每个文件只归属于一个项目
+func (idx *Indexer) groupFilesByProject(projects []*workspace.Project, filePaths []string) (map[string][]string, error) {
+	projectFilesMap := make(map[string][]string)
+	for _, filePath := range filePaths {
+		p := matchProject(projects, filePath)
+		if p == nil {
+			continue
+		}
+		projectFilesMap[p.Uuid] = append(projectFilesMap[p.Uuid], filePath)
 	}
-
 	return projectFilesMap, nil
 }
 
 // findProjectForFile 查找文件所属的项目
 func (idx *Indexer) findProjectForFile(projects []*workspace.Project, filePath string) (*workspace.Project, string, error) {
+	if p := matchProject(projects, filePath); p != nil {
+		return p, p.Uuid, nil
+	}
+	return nil, types.EmptyString, fmt.Errorf("no project found for file path %s", filePath)
+}
+
+// matchProject 按路径边界匹配文件所属的项目，路径等于项目根目录时同样属于该项目，嵌套项目取最深的一个。
+// 不能直接用字符串前缀判断，否则 /ws/app-v2 下的文件会被归入 /ws/app 项目
+func matchProject(projects []*workspace.Project, filePath string) *workspace.Project {
+	var matched *workspace.Project
 	for _, p := range projects {
-		if strings.HasPrefix(filePath, p.Path) {
-			return p, p.Uuid, nil
+		if !isPathInWorkspace(p.Path, filePath) {
+			continue
+		}
+		if matched == nil || len(p.Path) > len(matched.Path) {
+			matched = p
 		}
 	}
-
-	return nil, types.EmptyString, fmt.Errorf("no project found for file path %s", filePath)
+	return matched
 }
 
 // GetProjectByFilePath 根据文件路径获取项目并检查项目索引是否存在
@@ -131,45 +182,120 @@ func (idx *Indexer) GetProjectByFilePath(ctx context.Context, workspace, filePat
 	if !exists {
 		return nil, fmt.Errorf("workspace %s index does not exist, project uuid: %s", workspace, project.Uuid)
 	}
+	if err = idx.checkProjectSchema(ctx, project.Uuid); err != nil {
+		return nil, err
+	}
 	return project, nil
 }
 
-// getFileElementTableByPath 通过路径获取FileElementTable
-func (idx *Indexer) getFileElementTableByPath(ctx context.Context, projectUuid string, filePath string) (*codegraphpb.FileElementTable, error) {
-	language, err := lang.InferLanguage(filePath)
+// getProjectByUuid 根据项目uuid获取项目，跳过工作区项目发现，项目索引不存在或 schema 不兼容时返回错误
+func (idx *Indexer) getProjectByUuid(ctx context.Context, workspacePath, projectUuid string) (*workspace.Project, error) {
+	exists, err := idx.storage.ProjectIndexExists(projectUuid)
 	if err != nil {
+		return nil, fmt.Errorf("failed to check project %s index existence: %w", projectUuid, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("project %s index does not exist", projectUuid)
+	}
+	if err = idx.checkProjectSchema(ctx, projectUuid); err != nil {
 		return nil, err
 	}
-	fileTableBytes, err := idx.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: language, Path: filePath})
-	if errors.Is(err, store.ErrKeyNotFound) {
-		return nil, fmt.Errorf("index not found for file %s", filePath)
+	projectPath := idx.getProjectPathMeta(ctx, projectUuid)
+	if projectPath == types.EmptyString {
+		// 旧索引没有记录项目路径时，从工作区的项目中查找
+		for _, p := range idx.workspaceReader.FindProjects(ctx, workspacePath, true, workspace.DefaultVisitPattern) {
+			if p.Uuid == projectUuid {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("project %s not found in workspace %s", projectUuid, workspacePath)
+	}
+	if !strings.HasPrefix(projectPath, blobProjectPathPrefix) && !isPathInWorkspace(workspacePath, projectPath) {
+		return nil, fmt.Errorf("project %s does not belong to workspace %s", projectUuid, workspacePath)
 	}
+	return &workspace.Project{Name: filepath.Base(projectPath), Path: projectPath, Uuid: projectUuid}, nil
+}
+
+// getQueryProject 获取查询所属的项目，指定了项目uuid时直接使用，否则根据文件路径查找
+func (idx *Indexer) getQueryProject(ctx context.Context, workspacePath, projectUuid, filePath string) (*workspace.Project, error) {
+	if projectUuid != types.EmptyString {
+		return idx.getProjectByUuid(ctx, workspacePath, projectUuid)
+	}
+	return idx.GetProjectByFilePath(ctx, workspacePath, filePath)
+}
+
+// getQueryProjects 获取查询涉及的项目列表，指定了项目uuid时只返回该项目，否则发现工作区下所有项目
+func (idx *Indexer) getQueryProjects(ctx context.Context, workspacePath, projectUuid string) ([]*workspace.Project, error) {
+	if projectUuid != types.EmptyString {
+		project, err := idx.getProjectByUuid(ctx, workspacePath, projectUuid)
+		if err != nil {
+			return nil, err
+		}
+		return []*workspace.Project{project}, nil
+	}
+	return idx.workspaceReader.FindProjects(ctx, workspacePath, true, workspace.DefaultVisitPattern), nil
+}
+
+// candidateElementPathKeys 返回文件可能的元素表 key，只按扩展名推断语言，不读取文件：
+// 没有扩展名的文件可能是按 shebang 索引的脚本，返回每种脚本语言的 key
+func candidateElementPathKeys(filePath string) ([]store.ElementPathKey, error) {
+	language, err := lang.InferLanguageByExt(filePath)
+	if err == nil {
+		return []store.ElementPathKey{{Language: language, Path: filePath}}, nil
+	}
+	if !errors.Is(err, lang.ErrFileExtNotFound) {
+		return nil, err
+	}
+	languages := lang.ScriptLanguages()
+	keys := make([]store.ElementPathKey, 0, len(languages))
+	for _, language := range languages {
+		keys = append(keys, store.ElementPathKey{Language: language, Path: filePath})
+	}
+	return keys, nil
+}
+
+// getFileElementTableByPath 通过路径获取FileElementTable，按候选 key 依次查找，不读取文件
+func (idx *Indexer) getFileElementTableByPath(ctx context.Context, projectUuid string, filePath string) (*codegraphpb.FileElementTable, error) {
+	keys, err := candidateElementPathKeys(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file %s index, err: %v", filePath, err)
+		return nil, err
 	}
-	var fileElementTable codegraphpb.FileElementTable
-	if err = store.UnmarshalValue(fileTableBytes, &fileElementTable); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal file %s index value, err: %v", filePath, err)
+	for i, key := range keys {
+		table, err := idx.getFileElementTable(ctx, projectUuid, key.Language, filePath)
+		if errors.Is(err, errs.ErrFileNotIndexed) && i < len(keys)-1 {
+			continue
+		}
+		return table, err
 	}
-	return &fileElementTable, nil
+	return nil, fmt.Errorf("%w: %s", errs.ErrFileNotIndexed, filePath)
+}
+
+// getCachedFileElementTable 从单次查询的缓存中获取FileElementTable，未命中时读取存储并缓存，
+// 读取失败时缓存为 nil，保证每个文件在一次查询内最多读取一次；只有首次读取失败时返回错误
+func (idx *Indexer) getCachedFileElementTable(ctx context.Context, projectUuid string, filePath string,
+	fileTables map[string]*codegraphpb.FileElementTable) (*codegraphpb.FileElementTable, error) {
+	if table, ok := fileTables[filePath]; ok {
+		return table, nil
+	}
+	table, err := idx.getFileElementTableByPath(ctx, projectUuid, filePath)
+	if err != nil {
+		table = nil
+	}
+	fileTables[filePath] = table
+	return table, err
 }
 
 // getFileElementTable 根据文件路径获取文件元素表
 func (idx *Indexer) getFileElementTable(ctx context.Context, projectUuid string, language lang.Language, filePath string) (*codegraphpb.FileElementTable, error) {
 	fileTableBytes, err := idx.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: language, Path: filePath})
 	if errors.Is(err, store.ErrKeyNotFound) {
-		return nil, fmt.Errorf("index not found for file %s", filePath)
+		return nil, fmt.Errorf("%w: %s", errs.ErrFileNotIndexed, filePath)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file %s index, err: %v", filePath, err)
 	}
-
-	var fileElementTable codegraphpb.FileElementTable
-	if err = store.UnmarshalValue(fileTableBytes, &fileElementTable); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal file %s index value, err: %v", filePath, err)
-	}
-
-	return &fileElementTable, nil
+	// 数据损坏时清理并返回 ErrFileIndexStale，调用方可触发重新索引
+	return idx.unmarshalFileElementTable(ctx, projectUuid, language, filePath, fileTableBytes)
 }
 
 // getSymbolOccurrenceByName 通过符号名获取符号出现
@@ -185,21 +311,28 @@ func (idx *Indexer) getSymbolOccurrenceByName(ctx context.Context, projectUuid s
 	return &SymbolOccurrence, err
 }
 
-// findSymbolInDocByRange 按范围查找符号
+// findSymbolInDocByRange 按范围查找符号，优先匹配开始行和开始列，其次匹配开始行
 func (idx *Indexer) findSymbolInDocByRange(fileElementTable *codegraphpb.FileElementTable, symbolRange []int32) *codegraphpb.Element {
 	//TODO 二分查找
+	var lineMatched *codegraphpb.Element
 	for _, s := range fileElementTable.Elements {
 		// 开始行
 		if len(s.Range) < 2 {
 			idx.logger.Debug("findSymbolInDocByRange invalid range in doc:%s, less than 2: %v", s.Name, s.Range)
 			continue
 		}
-
-		if s.Range[0] == symbolRange[0] {
+		if s.Range[0] != symbolRange[0] {
+			continue
+		}
+		// 同一行可能有多个符号，列一致时直接返回
+		if len(symbolRange) < 2 || s.Range[1] == symbolRange[1] {
 			return s
 		}
+		if lineMatched == nil {
+			lineMatched = s
+		}
 	}
-	return nil
+	return lineMatched
 }
 
 // findSymbolInDocByLineRange 按行范围查找符号
@@ -215,7 +348,7 @@ func (idx *Indexer) findSymbolInDocByLineRange(ctx context.Context,
 		if s.Range[0] > endLine {
 			break
 		}
-		// 开始行、(TODO 列一致)
+		// 开始行，列由 filterSymbolsByColumn 收窄
 		if s.Range[0] >= startLine && s.Range[0] <= endLine {
 			res = append(res, s)
 		}
@@ -223,6 +356,29 @@ func (idx *Indexer) findSymbolInDocByLineRange(ctx context.Context,
 	return res
 }
 
+// filterSymbolsByColumn 按列收窄符号，保留开始位置落在 [startLine:startColumn, endLine:endColumn] 内的符号。
+// 行列均从1开始，列 <= 0 表示不限制该端
+func filterSymbolsByColumn(elements []*codegraphpb.Element, startLine, startColumn, endLine, endColumn int) []*codegraphpb.Element {
+	if startColumn <= 0 && endColumn <= 0 {
+		return elements
+	}
+	filtered := make([]*codegraphpb.Element, 0, len(elements))
+	for _, e := range elements {
+		if len(e.Range) < 2 {
+			continue
+		}
+		line, column := int(e.Range[0])+1, int(e.Range[1])+1
+		if startColumn > 0 && (line < startLine || (line == startLine && column < startColumn)) {
+			continue
+		}
+		if endColumn > 0 && (line > endLine || (line == endLine && column > endColumn)) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
 // findReferenceSymbolBelonging 查找引用符号归属
 func (idx *Indexer) findReferenceSymbolBelonging(f *codegraphpb.FileElementTable,
 	referenceElement *codegraphpb.Element) *codegraphpb.Element {
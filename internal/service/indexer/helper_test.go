@@ -1,11 +1,19 @@
 package indexer
 
 import (
+	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNormalizeLineRange(t *testing.T) {
@@ -65,6 +73,14 @@ func TestNormalizeLineRange(t *testing.T) {
 			wantStart: 1,
 			wantEnd:   10,
 		},
+		{
+			name:      "跨度上限为0时至少保留一行",
+			start:     5,
+			end:       10,
+			maxLimit:  0,
+			wantStart: 5,
+			wantEnd:   5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -76,6 +92,25 @@ func TestNormalizeLineRange(t *testing.T) {
 	}
 }
 
+func TestLineLimitOrDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "使用指定上限", limit: 50, want: 50},
+		{name: "零值回退默认值", limit: 0, want: MaxCallGraphLineLimit},
+		{name: "负数回退默认值", limit: -10, want: MaxCallGraphLineLimit},
+		{name: "等于上限", limit: MaxLineLimitCeiling, want: MaxLineLimitCeiling},
+		{name: "超过上限时截断", limit: MaxLineLimitCeiling + 1, want: MaxLineLimitCeiling},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lineLimitOrDefault(tt.limit, MaxCallGraphLineLimit))
+		})
+	}
+}
+
 func TestIsValidRange(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -204,6 +239,108 @@ func TestFindProjectForFile(t *testing.T) {
 	}
 }
 
+func TestGroupFilesByProject_WorkspaceIsolation(t *testing.T) {
+	idx := &Indexer{}
+	root := t.TempDir()
+	// 两个工作区路径存在公共前缀，workspaceA 下还有嵌套项目
+	workspaceA := filepath.Join(root, "app")
+	workspaceB := filepath.Join(root, "app-v2")
+	nestedA := filepath.Join(workspaceA, "server")
+	projectA := workspace.NewProject(filepath.Base(workspaceA), workspaceA)
+	projectB := workspace.NewProject(filepath.Base(workspaceB), workspaceB)
+	projectNested := workspace.NewProject(filepath.Base(nestedA), nestedA)
+	require.NotEqual(t, projectA.Uuid, projectB.Uuid)
+	projects := []*workspace.Project{projectA, projectNested, projectB}
+
+	fileA := filepath.Join(workspaceA, "main.go")
+	fileNested := filepath.Join(nestedA, "server.go")
+	fileB := filepath.Join(workspaceB, "main.go")
+	fileOther := filepath.Join(root, "apple", "main.go")
+
+	result, err := idx.groupFilesByProject(projects, []string{fileA, fileNested, fileB, fileOther})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		projectA.Uuid:      {fileA},
+		projectNested.Uuid: {fileNested},
+		projectB.Uuid:      {fileB},
+	}, result)
+
+	tests := []struct {
+		name     string
+		filePath string
+		wantUuid string
+		wantErr  bool
+	}{
+		{name: "工作区A的文件", filePath: fileA, wantUuid: projectA.Uuid},
+		{name: "工作区B的文件不归入前缀相同的工作区A", filePath: fileB, wantUuid: projectB.Uuid},
+		{name: "嵌套项目取最深的项目", filePath: fileNested, wantUuid: projectNested.Uuid},
+		{name: "路径等于项目根目录", filePath: workspaceB, wantUuid: projectB.Uuid},
+		{name: "路径等于嵌套项目根目录", filePath: nestedA, wantUuid: projectNested.Uuid},
+		{name: "前缀相同但不在任何项目下", filePath: fileOther, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, uuid, err := idx.findProjectForFile(projects, tt.filePath)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUuid, uuid)
+		})
+	}
+}
+
+func TestGroupFilesByProject_NestedGoModules(t *testing.T) {
+	idx := &Indexer{}
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":              "module example.com/app\n\ngo 1.22\n",
+		"main.go":             "package main\n",
+		"internal/server.go":  "package internal\n",
+		"tools/go.mod":        "module example.com/app/tools\n\ngo 1.22\n",
+		"tools/gen.go":        "package tools\n",
+		"tools/lint/go.mod":   "module example.com/app/tools/lint\n\ngo 1.22\n",
+		"tools/lint/lint.go":  "package lint\n",
+		"toolsx/other.go":     "package toolsx\n",
+		"tools/testdata/x.go": "package testdata\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	projects := workspace.NewWorkSpaceReader(&mockLogger{}).FindProjects(context.Background(), root, true, workspace.DefaultVisitPattern)
+	require.Len(t, projects, 3)
+	uuids := make(map[string]string, len(projects))
+	for _, p := range projects {
+		uuids[p.Path] = p.Uuid
+	}
+	rootUuid, toolsUuid, lintUuid := uuids[root], uuids[filepath.Join(root, "tools")], uuids[filepath.Join(root, "tools", "lint")]
+	require.NotEmpty(t, rootUuid)
+	require.NotEmpty(t, toolsUuid)
+	require.NotEmpty(t, lintUuid)
+
+	abs := func(name string) string { return filepath.Join(root, name) }
+	result, err := idx.groupFilesByProject(projects, []string{
+		abs("main.go"), abs("internal/server.go"), abs("tools/gen.go"), abs("tools/testdata/x.go"),
+		abs("tools/lint/lint.go"), abs("toolsx/other.go"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		rootUuid:  {abs("main.go"), abs("internal/server.go"), abs("toolsx/other.go")},
+		toolsUuid: {abs("tools/gen.go"), abs("tools/testdata/x.go")},
+		lintUuid:  {abs("tools/lint/lint.go")},
+	}, result)
+
+	for _, p := range projects {
+		if p.Uuid == toolsUuid {
+			assert.Contains(t, p.GoModules, "example.com/app/tools")
+		}
+	}
+}
+
 func TestIsInLinesRange(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -388,3 +525,63 @@ func TestFindSymbolInDocByLineRange(t *testing.T) {
 	}
 }
 
+
+func TestNormalizeQueryFilePaths(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	absFile := filepath.Join(workspaceDir, "main.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, absFile,
+		"package main\n\nfunc Hello() {}\n",
+		[]*codegraphpb.Element{
+			{Name: "Hello", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 2, 15}},
+		})
+	fileURI := (&url.URL{Scheme: "file", Path: filepath.ToSlash(absFile)}).String()
+
+	tests := []struct {
+		name      string
+		workspace string
+		filePath  string
+	}{
+		{name: "绝对路径", workspace: workspaceDir, filePath: absFile},
+		{name: "file URI", workspace: workspaceDir, filePath: fileURI},
+		{name: "相对路径", workspace: workspaceDir, filePath: "main.go"},
+		{name: "冗余路径元素", workspace: workspaceDir, filePath: "./sub/../main.go"},
+		{name: "工作区为 file URI", workspace: (&url.URL{Scheme: "file", Path: filepath.ToSlash(workspaceDir)}).String(), filePath: "main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace: tt.workspace,
+				FilePath:  tt.filePath,
+				StartLine: 3,
+				EndLine:   3,
+			})
+			require.NoError(t, err)
+			require.Len(t, definitions, 1)
+			assert.Equal(t, absFile, definitions[0].Path)
+			assert.Equal(t, "Hello", definitions[0].Name)
+		})
+	}
+
+	t.Run("工作区外的路径", func(t *testing.T) {
+		for _, filePath := range []string{"../main.go", filepath.Join(filepath.Dir(workspaceDir), "main.go")} {
+			_, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace: workspaceDir,
+				FilePath:  filePath,
+				StartLine: 3,
+				EndLine:   3,
+			})
+			assert.ErrorIs(t, err, utils.ErrPathNotInWorkspace)
+		}
+	})
+
+	t.Run("分组到同一项目", func(t *testing.T) {
+		projects := []*workspace.Project{project}
+		filePaths := normalizeFilePaths(workspaceDir, []string{absFile, fileURI, "main.go", "../main.go"})
+		result, err := idx.groupFilesByProject(projects, filePaths)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{project.Uuid: {absFile, absFile, absFile}}, result)
+	})
+}
@@ -2,21 +2,30 @@ package indexer
 
 import (
 	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/parser"
+	codegraphproto "codebase-indexer/pkg/codegraph/proto"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
 	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"google.golang.org/protobuf/proto"
 )
 
 // RemoveIndexes 根据工作区路径、文件路径/文件夹路径前缀，批量删除索引
 func (idx *Indexer) RemoveIndexes(ctx context.Context, workspacePath string, filePaths []string) error {
 	start := time.Now()
+	workspacePath = utils.FileURIToPath(workspacePath)
+	filePaths = normalizeFilePaths(workspacePath, filePaths)
 	idx.logger.Info("start to remove workspace %s files: %v", workspacePath, filePaths)
+	defer idx.lockWorkspace(workspacePath)()
 
 	projects := idx.workspaceReader.FindProjects(ctx, workspacePath, false, workspace.DefaultVisitPattern)
 	if len(projects) == 0 {
@@ -75,11 +84,18 @@ func (idx *Indexer) removeIndexByFilePaths(ctx context.Context, projectUuid stri
 	}
 
 	// 3. 删除path索引
-	deleted, err := idx.deleteFileIndexes(ctx, projectUuid, deletePaths)
+	deleted, err := idx.deleteFileIndexes(ctx, projectUuid, deleteFileTables)
 	if err != nil {
 		return 0, fmt.Errorf("delete file indexes failed: %w", err)
 	}
 
+	// 4. 移除 callee map 中已删除文件的调用者
+	removedPaths := make([]string, 0, len(deletePaths))
+	for fp := range deletePaths {
+		removedPaths = append(removedPaths, fp)
+	}
+	idx.updateCalleeMap(ctx, projectUuid, removedPaths)
+
 	return deleted, nil
 }
 
@@ -89,11 +105,11 @@ func (idx *Indexer) searchFileElementTablesByPath(ctx context.Context, puuid str
 	var errs []error
 
 	for _, filePath := range filePaths {
-		language, err := lang.InferLanguage(filePath)
-		var fileTable []byte
+		// 文件已删除，只按扩展名推断候选 key，不读取文件
+		var ft *codegraphpb.FileElementTable
+		keys, err := candidateElementPathKeys(filePath)
 		if err == nil {
-			key := store.ElementPathKey{Language: language, Path: filePath}
-			fileTable, err = idx.storage.Get(ctx, puuid, key)
+			ft, err = idx.lookupElementTable(ctx, puuid, keys)
 		}
 
 		if lang.IsUnSupportedFileError(err) || errors.Is(err, store.ErrKeyNotFound) {
@@ -113,11 +129,6 @@ func (idx *Indexer) searchFileElementTablesByPath(ctx context.Context, puuid str
 			errs = append(errs, err)
 			continue
 		}
-		ft := new(codegraphpb.FileElementTable)
-		if err = store.UnmarshalValue(fileTable, ft); err != nil {
-			errs = append(errs, err)
-			continue
-		}
 		deleteFileTables = append(deleteFileTables, ft)
 	}
 
@@ -128,87 +139,109 @@ func (idx *Indexer) searchFileElementTablesByPath(ctx context.Context, puuid str
 	return deleteFileTables, nil
 }
 
-// searchFileElementTablesByPathPrefix 按路径前缀搜索
-func (idx *Indexer) searchFileElementTablesByPathPrefix(ctx context.Context, projectUuid string, path string) (
-	[]*codegraphpb.FileElementTable, []error) {
-	var errs []error
-	tables := make([]*codegraphpb.FileElementTable, 0)
-	iter := idx.storage.Iter(ctx, projectUuid)
-	for iter.Next() {
-		if !store.IsElementPathKey(iter.Key()) {
+// lookupElementTable 按候选 key 依次查找元素表，返回第一个存在的，都不存在时返回 store.ErrKeyNotFound
+func (idx *Indexer) lookupElementTable(ctx context.Context, puuid string,
+	keys []store.ElementPathKey) (*codegraphpb.FileElementTable, error) {
+	for _, key := range keys {
+		data, err := idx.storage.Get(ctx, puuid, key)
+		if errors.Is(err, store.ErrKeyNotFound) {
 			continue
 		}
-		pathKey, err := store.ToElementPathKey(iter.Key())
 		if err != nil {
-			idx.logger.Debug("indexer delete index, parse element path key %s err:%v", iter.Key(), err)
-			continue
+			return nil, err
 		}
-		// path 可能包含分隔符，也可能不包含，统一处理
-		pathPrefix := utils.EnsureTrailingSeparator(path)
-		if strings.HasPrefix(pathKey.Path, pathPrefix) {
-			ft := new(codegraphpb.FileElementTable)
-			if err = store.UnmarshalValue(iter.Value(), ft); err != nil {
-				errs = append(errs, err)
-				continue
-			}
-			tables = append(tables, ft)
+		table := new(codegraphpb.FileElementTable)
+		if err = store.UnmarshalValue(data, table); err != nil {
+			return nil, err
 		}
+		return table, nil
 	}
-	err := iter.Close()
+	return nil, store.ErrKeyNotFound
+}
+
+// searchFileElementTablesByPathPrefix 按路径前缀搜索
+func (idx *Indexer) searchFileElementTablesByPathPrefix(ctx context.Context, projectUuid string, path string) (
+	[]*codegraphpb.FileElementTable, []error) {
+	var errs []error
+	tables := make([]*codegraphpb.FileElementTable, 0)
+	// path 可能包含分隔符，也可能不包含，统一处理为 / 分隔符的目录前缀
+	pathPrefix := strings.TrimSuffix(utils.ToStoragePath(path), types.Slash) + types.Slash
+	err := store.ForEachElementTable(ctx, idx.storage, projectUuid,
+		func(key store.ElementPathKey, table *codegraphpb.FileElementTable) error {
+			if strings.HasPrefix(key.Path, pathPrefix) {
+				tables = append(tables, proto.Clone(table).(*codegraphpb.FileElementTable))
+			}
+			return nil
+		})
 	if err != nil {
-		idx.logger.Error("indexer close graph_store err:%v", err)
+		errs = append(errs, err)
 	}
 	return tables, errs
 }
 
-// cleanupSymbolOccurrences 清理符号定义
+// cleanupSymbolOccurrences 清理符号定义。同一符号可能在多个被删除文件中定义，先在内存中合并，
+// 再通过一次批量写入和一次批量删除落盘
 func (idx *Indexer) cleanupSymbolOccurrences(ctx context.Context, projectUuid string,
 	deleteFileTables []*codegraphpb.FileElementTable, deletedPaths map[string]interface{}) error {
 	var errs []error
+	symbols := make(map[store.SymbolNameKey]*codegraphpb.SymbolOccurrence)
 
 	for _, ft := range deleteFileTables {
 		for _, e := range ft.Elements {
-			if e.IsDefinition {
-				language := lang.Language(ft.Language)
-				sym, err := idx.storage.Get(ctx, projectUuid, store.SymbolNameKey{Language: language, Name: e.GetName()})
-				if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
-					errs = append(errs, err)
-					continue
-				}
-				symDefs := new(codegraphpb.SymbolOccurrence)
-				if err = store.UnmarshalValue(sym, symDefs); err != nil {
-					return fmt.Errorf("unmarshal SymbolOccurrence error:%w", err)
-				}
-
-				newSymDefs := &codegraphpb.SymbolOccurrence{
-					Name:        e.GetName(),
-					Language:    ft.Language,
-					Occurrences: make([]*codegraphpb.Occurrence, 0),
-				}
-				for _, d := range symDefs.Occurrences {
-					if _, ok := deletedPaths[d.Path]; ok {
-						continue
-					}
-					newSymDefs.Occurrences = append(newSymDefs.Occurrences, d)
-				}
-				// 如果新的为0，就无需再写入，并删除旧的
-				if len(newSymDefs.Occurrences) == 0 {
-					if err := idx.storage.Delete(ctx, projectUuid, store.SymbolNameKey{Language: language,
-						Name: newSymDefs.Name}); err != nil {
-						errs = append(errs, err)
-					}
-					continue
-				}
+			if !e.IsDefinition {
+				continue
+			}
+			key := store.SymbolNameKey{Language: lang.Language(ft.Language), Name: e.GetName()}
+			if _, ok := symbols[key]; ok {
+				continue
+			}
+			sym, err := idx.storage.Get(ctx, projectUuid, key)
+			if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+				errs = append(errs, err)
+				continue
+			}
+			symDefs := new(codegraphpb.SymbolOccurrence)
+			if err = store.UnmarshalValue(sym, symDefs); err != nil {
+				return fmt.Errorf("unmarshal SymbolOccurrence error:%w", err)
+			}
+			symbols[key] = symDefs
+		}
+	}
 
-				// 保存更新后的符号表
-				if err := idx.storage.Put(ctx, projectUuid, &store.Entry{Key: store.SymbolNameKey{Language: language,
-					Name: newSymDefs.Name}, Value: newSymDefs}); err != nil {
-					errs = append(errs, err)
-				}
+	var updated workspace.SymbolOccurrences
+	var emptied []store.Key
+	for key, symDefs := range symbols {
+		newSymDefs := &codegraphpb.SymbolOccurrence{
+			Name:        key.Name,
+			Language:    string(key.Language),
+			Occurrences: make([]*codegraphpb.Occurrence, 0, len(symDefs.Occurrences)),
+		}
+		for _, d := range symDefs.Occurrences {
+			if _, ok := deletedPaths[d.Path]; ok {
+				continue
 			}
+			newSymDefs.Occurrences = append(newSymDefs.Occurrences, d)
+		}
+		// 如果新的为0，就无需再写入，并删除旧的
+		if len(newSymDefs.Occurrences) == 0 {
+			emptied = append(emptied, key)
+			continue
+		}
+		if len(newSymDefs.Occurrences) < len(symDefs.Occurrences) {
+			updated = append(updated, newSymDefs)
 		}
 	}
 
+	// 保存更新后的符号表
+	if len(updated) > 0 {
+		if err := idx.storage.BatchSave(ctx, projectUuid, updated); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := idx.storage.BatchDelete(ctx, projectUuid, emptied); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -216,32 +249,131 @@ func (idx *Indexer) cleanupSymbolOccurrences(ctx context.Context, projectUuid st
 	return nil
 }
 
-// deleteFileIndexes 删除文件索引
-func (idx *Indexer) deleteFileIndexes(ctx context.Context, puuid string, deletePaths map[string]any) (int, error) {
+// cleanupStaleSymbolOccurrences 文件重新索引时，对比旧元素表和新解析结果，清理该文件中已不再定义的符号的出现位置。
+// 按文件逐个清理，避免误删同名符号在其他重新索引文件中的定义，返回被清理的符号名
+func (idx *Indexer) cleanupStaleSymbolOccurrences(ctx context.Context, projectUuid string,
+	elementTables []*parser.FileElementTable) ([]string, error) {
 	var errs []error
-	deleted := 0
-	for fp := range deletePaths {
-		// 删除path索引
-		language, err := lang.InferLanguage(fp)
+	var staleNames []string
+	for _, table := range elementTables {
+		data, err := idx.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: table.Language, Path: table.Path})
+		if errors.Is(err, store.ErrKeyNotFound) {
+			continue
+		}
 		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		oldTable := new(codegraphpb.FileElementTable)
+		if err = store.UnmarshalValue(data, oldTable); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// 只有仍作为定义出现的符号才保留，同名的引用、调用不算
+		defined := make(map[string]struct{}, len(table.Elements))
+		for _, e := range table.Elements {
+			if codegraphproto.IsDefinitionType(e.GetType()) {
+				defined[e.GetName()] = struct{}{}
+			}
+		}
+		staleTable := &codegraphpb.FileElementTable{Path: oldTable.Path, Language: oldTable.Language}
+		for _, e := range oldTable.Elements {
+			if !e.IsDefinition {
+				continue
+			}
+			if _, ok := defined[e.Name]; ok {
+				continue
+			}
+			staleTable.Elements = append(staleTable.Elements, e)
+		}
+		if len(staleTable.Elements) == 0 {
 			continue
 		}
-		if err = idx.storage.Delete(ctx, puuid, store.ElementPathKey{Language: language, Path: fp}); err != nil {
+		if err = idx.cleanupSymbolOccurrences(ctx, projectUuid, []*codegraphpb.FileElementTable{staleTable},
+			map[string]any{oldTable.Path: nil}); err != nil {
 			errs = append(errs, err)
-		} else {
-			deleted++
+			continue
+		}
+		for _, e := range staleTable.Elements {
+			staleNames = append(staleNames, e.Name)
 		}
 	}
+	return staleNames, errors.Join(errs...)
+}
 
-	if len(errs) > 0 {
-		return deleted, errors.Join(errs...)
+// cleanupSkippedFileIndexes 重新索引时跳过解析的文件（变为空文件、无法读取等）不再有符号，
+// 删除其之前索引的元素表和符号定义；只按路径精确查找，没有索引过的文件直接忽略。返回被清理的符号名
+func (idx *Indexer) cleanupSkippedFileIndexes(ctx context.Context, projectUuid string,
+	skippedFiles map[string]string) ([]string, error) {
+	var errs []error
+	var oldTables []*codegraphpb.FileElementTable
+	deletePaths := make(map[string]any)
+	for filePath := range skippedFiles {
+		// 文件可能无法读取，只按扩展名推断候选 key
+		keys, err := candidateElementPathKeys(utils.ToStoragePath(filePath))
+		if err != nil {
+			continue
+		}
+		oldTable, err := idx.lookupElementTable(ctx, projectUuid, keys)
+		if errors.Is(err, store.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		oldTables = append(oldTables, oldTable)
+		deletePaths[oldTable.Path] = nil
+	}
+	if len(oldTables) == 0 {
+		return nil, errors.Join(errs...)
 	}
 
-	return deleted, nil
+	if err := idx.cleanupSymbolOccurrences(ctx, projectUuid, oldTables, deletePaths); err != nil {
+		return nil, errors.Join(append(errs, err)...)
+	}
+	if _, err := idx.deleteFileIndexes(ctx, projectUuid, oldTables); err != nil {
+		return nil, errors.Join(append(errs, err)...)
+	}
+	removedPaths := make([]string, 0, len(deletePaths))
+	for fp := range deletePaths {
+		removedPaths = append(removedPaths, fp)
+	}
+	idx.updateCalleeMap(ctx, projectUuid, removedPaths)
+
+	var removedNames []string
+	for _, table := range oldTables {
+		for _, e := range table.Elements {
+			if e.IsDefinition {
+				removedNames = append(removedNames, e.Name)
+			}
+		}
+	}
+	return removedNames, errors.Join(errs...)
+}
+
+// deleteFileIndexes 删除文件索引，使用元素表中记录的语言，不重新推断，所有 path 索引在一次批量删除中完成
+func (idx *Indexer) deleteFileIndexes(ctx context.Context, puuid string, tables []*codegraphpb.FileElementTable) (int, error) {
+	keys := make([]store.Key, 0, len(tables))
+	seen := make(map[store.ElementPathKey]struct{}, len(tables))
+	for _, table := range tables {
+		key := store.ElementPathKey{Language: lang.Language(table.Language), Path: table.Path}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	if err := idx.storage.BatchDelete(ctx, puuid, keys); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
 }
 
 // RemoveAllIndexes 删除工作区的所有索引
 func (idx *Indexer) RemoveAllIndexes(ctx context.Context, workspacePath string) error {
+	defer idx.lockWorkspace(workspacePath)()
 	projects := idx.workspaceReader.FindProjects(ctx, workspacePath, false, workspace.DefaultVisitPattern)
 	if len(projects) == 0 {
 		idx.logger.Info("found no projects in workspace %s", workspacePath)
@@ -258,6 +390,111 @@ func (idx *Indexer) RemoveAllIndexes(ctx context.Context, workspacePath string)
 	return errors.Join(errs...)
 }
 
+// RemoveProjectIndexes 删除工作区下指定项目的全部索引，用于整个子项目被删除的场景，不需要逐个文件查找元素表。
+// 项目必须是该工作区已索引的项目（包括源码目录已删除的孤立索引），有任意一个不属于该工作区时不做任何删除
+func (idx *Indexer) RemoveProjectIndexes(ctx context.Context, workspacePath string, projectUuids []string) error {
+	if workspacePath == types.EmptyString {
+		return fmt.Errorf("workspace path cannot be empty")
+	}
+	if len(projectUuids) == 0 {
+		return nil
+	}
+	start := time.Now()
+	workspacePath = utils.FileURIToPath(workspacePath)
+	defer idx.lockWorkspace(workspacePath)()
+	indexedProjects, err := idx.ListIndexedProjects(ctx, workspacePath)
+	if err != nil {
+		return fmt.Errorf("list workspace %s indexed projects err: %w", workspacePath, err)
+	}
+	fileNums := make(map[string]int, len(indexedProjects))
+	for _, p := range indexedProjects {
+		fileNums[p.Uuid] = p.FileNum
+	}
+	for _, projectUuid := range projectUuids {
+		if _, ok := fileNums[projectUuid]; !ok {
+			return fmt.Errorf("project %s is not an indexed project of workspace %s", projectUuid, workspacePath)
+		}
+	}
+	workspaceModel, err := idx.workspaceRepository.GetWorkspaceByPath(workspacePath)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	totalRemoved := 0
+	for _, projectUuid := range utils.DeDuplicate(projectUuids) {
+		if err := idx.storage.DeleteAll(ctx, projectUuid); err != nil {
+			errs = append(errs, fmt.Errorf("delete project %s index err: %w", projectUuid, err))
+			continue
+		}
+		totalRemoved += fileNums[projectUuid]
+	}
+	// 更新为删除后的值
+	if workspaceModel != nil {
+		if err := idx.workspaceRepository.UpdateCodegraphInfo(workspacePath,
+			max(workspaceModel.CodegraphFileNum-totalRemoved, 0), time.Now().Unix()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err = errors.Join(errs...)
+	idx.logger.Info("remove workspace %s projects %v index end, cost %d ms, removed %d index, errors: %v",
+		workspacePath, projectUuids, time.Since(start).Milliseconds(), totalRemoved, utils.TruncateError(err))
+	return err
+}
+
+// RebuildProject 清空工作区下指定项目的全部索引后重新索引，不影响工作区中的其它项目。
+// 项目路径必须是该工作区下能找到的项目的根目录
+func (idx *Indexer) RebuildProject(ctx context.Context, workspacePath, projectPath string) error {
+	if workspacePath == types.EmptyString {
+		return fmt.Errorf("workspace path cannot be empty")
+	}
+	if projectPath == types.EmptyString {
+		return fmt.Errorf("project path cannot be empty")
+	}
+	start := time.Now()
+	workspacePath = utils.FileURIToPath(workspacePath)
+	projectPath = filepath.Clean(utils.FileURIToPath(projectPath))
+	defer idx.lockWorkspace(workspacePath)()
+
+	var project *workspace.Project
+	for _, p := range idx.workspaceReader.FindProjects(ctx, workspacePath, true, workspace.DefaultVisitPattern) {
+		if filepath.Clean(p.Path) == projectPath {
+			project = p
+			break
+		}
+	}
+	if project == nil {
+		return fmt.Errorf("could not find project %s in workspace %s", projectPath, workspacePath)
+	}
+	workspaceModel, err := idx.workspaceRepository.GetWorkspaceByPath(workspacePath)
+	if err != nil {
+		return err
+	}
+	if workspaceModel == nil {
+		return fmt.Errorf("workspace %s not found in database", workspacePath)
+	}
+
+	removed := idx.storage.Size(ctx, project.Uuid, store.PathKeySystemPrefix)
+	if err := idx.storage.DeleteAll(ctx, project.Uuid); err != nil {
+		return fmt.Errorf("delete project %s index err: %w", project.Path, err)
+	}
+	// 扣减该项目原有的文件数，重新索引时在此基础上累加
+	if err := idx.workspaceRepository.UpdateCodegraphInfo(workspacePath,
+		max(workspaceModel.CodegraphFileNum-removed, 0), time.Now().Unix()); err != nil {
+		return fmt.Errorf("update codegraph info err: %w", err)
+	}
+
+	metrics, errs := idx.indexProjectSafely(ctx, workspacePath, project, true)
+	err = errors.Join(errs...)
+	totalFiles := 0
+	if metrics != nil {
+		totalFiles = metrics.TotalFiles
+	}
+	idx.logger.Info("rebuild workspace %s project %s index end, cost %d ms, removed %d index, indexed %d files, errors: %v",
+		workspacePath, project.Path, time.Since(start).Milliseconds(), removed, totalFiles, utils.TruncateError(err))
+	return err
+}
+
 // RenameIndexes 重命名索引，根据路径（文件或文件夹）
 func (idx *Indexer) RenameIndexes(ctx context.Context, workspacePath string, sourceFilePath string, targetFilePath string) error {
 	//TODO 查出来source，删除、重命名相关path、写入，更新symbol中指向source的路径为target（迭代式进行）
@@ -266,17 +503,9 @@ func (idx *Indexer) RenameIndexes(ctx context.Context, workspacePath string, sou
 		idx.logger.Info("found no projects in workspace %s", workspacePath)
 		return nil
 	}
-	var sourceProject *workspace.Project
-	var targetProject *workspace.Project
-	// rename 后，原文件（目录）已经不存在了。
-	for _, p := range projects {
-		if strings.HasPrefix(sourceFilePath, p.Path) {
-			sourceProject = p
-		}
-		if strings.HasPrefix(targetFilePath, p.Path) {
-			targetProject = p
-		}
-	}
+	// rename 后，原文件（目录）已经不存在了，只按路径匹配项目
+	sourceProject := matchProject(projects, sourceFilePath)
+	targetProject := matchProject(projects, targetFilePath)
 	if sourceProject == nil {
 		return fmt.Errorf("could not find source project in workspace %s for file %s", workspacePath, sourceFilePath)
 	}
@@ -294,8 +523,9 @@ func (idx *Indexer) RenameIndexes(ctx context.Context, workspacePath string, sou
 		idx.logger.Debug("found no index by source path %s", sourceFilePath)
 		return nil
 	}
-	// 统一去掉最后的分隔符（如果有），防止一个有，另一个没有
-	trimmedSourcePath, trimmedTargetPath := utils.TrimLastSeparator(sourceFilePath), utils.TrimLastSeparator(targetFilePath)
+	// 统一为索引中的 / 分隔符并去掉最后的分隔符（如果有），防止一个有，另一个没有
+	trimmedSourcePath, trimmedTargetPath := utils.ToStoragePath(sourceFilePath), utils.ToStoragePath(targetFilePath)
+	var oldPaths, newPaths []string
 	// 将source删除、key重命名为target，更新source相关的symbol 为target
 	for _, st := range sourceTables {
 		oldPath := st.Path
@@ -304,8 +534,10 @@ func (idx *Indexer) RenameIndexes(ctx context.Context, workspacePath string, sou
 		if err = idx.storage.Delete(ctx, sourceProjectUuid, store.ElementPathKey{Language: lang.Language(st.Language), Path: st.Path}); err != nil {
 			idx.logger.Debug("delete index %s %s err:%v", st.Language, st.Path, err)
 		}
+		oldPaths = append(oldPaths, oldPath)
 		// 将path中 sourceFilePath 重命名为targetFilePath，
 		newPath := strings.ReplaceAll(st.Path, trimmedSourcePath, trimmedTargetPath)
+		newPaths = append(newPaths, newPath)
 		newLanguage, err := lang.InferLanguage(newPath)
 		if err != nil {
 			idx.logger.Debug("unsupported language for new path %s", newPath)
@@ -379,5 +611,9 @@ func (idx *Indexer) RenameIndexes(ctx context.Context, workspacePath string, sou
 
 	}
 
+	// 重命名后 callee map 中的调用者路径随之更新
+	idx.updateCalleeMap(ctx, sourceProjectUuid, oldPaths)
+	idx.updateCalleeMap(ctx, targetProjectUuid, newPaths)
+
 	return nil
 }
@@ -1,10 +1,26 @@
 package indexer
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/test/mocks"
+
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSearchFileElementTablesByPath(t *testing.T) {
@@ -49,6 +65,90 @@ func TestDeleteFileIndexes(t *testing.T) {
 	t.Skip("需要完整的存储依赖")
 }
 
+func TestRemoveIndexByFilePaths_BatchDelete(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	removedDir := filepath.Join(workspaceDir, "pkg")
+	require.NoError(t, os.MkdirAll(removedDir, 0755))
+	keptFile := filepath.Join(workspaceDir, "main.go")
+
+	const fileCount = 2000
+	// 每个项目写入相同的数据：removedDir 下的文件各定义一个独有符号和共享符号 Shared，keptFile 也定义 Shared
+	seed := func(projectUuid string) {
+		shared := &codegraphpb.SymbolOccurrence{Name: "Shared", Language: string(lang.Go)}
+		for i := 0; i < fileCount; i++ {
+			path := filepath.Join(removedDir, fmt.Sprintf("file%d.go", i))
+			name := fmt.Sprintf("Func%d", i)
+			saveTestFileElementTable(t, storage, projectUuid, lang.Go, path, "package pkg\n", []*codegraphpb.Element{
+				{Name: name, IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{1, 0, 1, 10}},
+				{Name: "Shared", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 2, 10}},
+			})
+			require.NoError(t, storage.Put(ctx, projectUuid, &store.Entry{
+				Key: store.SymbolNameKey{Language: lang.Go, Name: name},
+				Value: &codegraphpb.SymbolOccurrence{Name: name, Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+					{Path: path, Range: []int32{1, 0, 1, 10}, ElementType: codegraphpb.ElementType_FUNCTION},
+				}},
+			}))
+			shared.Occurrences = append(shared.Occurrences, &codegraphpb.Occurrence{Path: path, Range: []int32{2, 0, 2, 10}})
+		}
+		saveTestFileElementTable(t, storage, projectUuid, lang.Go, keptFile, "package main\n", []*codegraphpb.Element{
+			{Name: "Shared", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 2, 10}},
+		})
+		shared.Occurrences = append(shared.Occurrences, &codegraphpb.Occurrence{Path: keptFile, Range: []int32{2, 0, 2, 10}})
+		require.NoError(t, storage.Put(ctx, projectUuid, &store.Entry{Key: store.SymbolNameKey{Language: lang.Go, Name: "Shared"}, Value: shared}))
+	}
+	listKeys := func(projectUuid string) []string {
+		var keys []string
+		iter := storage.Iter(ctx, projectUuid)
+		for iter.Next() {
+			keys = append(keys, iter.Key())
+		}
+		require.NoError(t, iter.Close())
+		sort.Strings(keys)
+		return keys
+	}
+
+	perKeyProject, batchProject := "per_key_project", "batch_project"
+	seed(perKeyProject)
+	seed(batchProject)
+
+	// 逐 key 删除作为对照：删除元素表、独有符号，并逐个改写共享符号
+	perKeyStart := time.Now()
+	for i := 0; i < fileCount; i++ {
+		path := filepath.Join(removedDir, fmt.Sprintf("file%d.go", i))
+		require.NoError(t, storage.Delete(ctx, perKeyProject, store.SymbolNameKey{Language: lang.Go, Name: fmt.Sprintf("Func%d", i)}))
+		require.NoError(t, storage.Delete(ctx, perKeyProject, store.ElementPathKey{Language: lang.Go, Path: path}))
+		require.NoError(t, storage.Put(ctx, perKeyProject, &store.Entry{
+			Key: store.SymbolNameKey{Language: lang.Go, Name: "Shared"},
+			Value: &codegraphpb.SymbolOccurrence{Name: "Shared", Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+				{Path: keptFile, Range: []int32{2, 0, 2, 10}},
+			}},
+		}))
+	}
+	perKeyCost := time.Since(perKeyStart)
+
+	batchStart := time.Now()
+	removed, err := idx.removeIndexByFilePaths(ctx, batchProject, []string{removedDir})
+	batchCost := time.Since(batchStart)
+	require.NoError(t, err)
+	t.Logf("remove %d files, per key cost %v, batch cost %v", fileCount, perKeyCost, batchCost)
+
+	assert.Equal(t, fileCount, removed)
+	assert.Equal(t, listKeys(perKeyProject), listKeys(batchProject))
+
+	value, err := storage.Get(ctx, batchProject, store.SymbolNameKey{Language: lang.Go, Name: "Shared"})
+	require.NoError(t, err)
+	var shared codegraphpb.SymbolOccurrence
+	require.NoError(t, store.UnmarshalValue(value, &shared))
+	require.Len(t, shared.Occurrences, 1)
+	assert.Equal(t, keptFile, shared.Occurrences[0].Path)
+
+	exists, err := storage.Exists(ctx, batchProject, store.ElementPathKey{Language: lang.Go, Path: keptFile})
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
 func TestRemoveIndexes(t *testing.T) {
 	// 这个测试需要完整的依赖注入
 	t.Skip("需要完整的依赖注入环境")
@@ -59,6 +159,196 @@ func TestRemoveAllIndexes(t *testing.T) {
 	t.Skip("需要完整的依赖注入环境")
 }
 
+func TestRemoveProjectIndexes(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceRepo := mocks.NewMockWorkspaceRepository(ctrl)
+	idx.workspaceRepository = workspaceRepo
+	workspaceDir := t.TempDir()
+
+	// 工作区根项目
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	putTestPathKey(t, storage, project.Uuid, filepath.Join(workspaceDir, "main.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, project))
+	// 源码目录已删除的子项目
+	sub := workspace.NewProject("sub", filepath.Join(workspaceDir, "sub"))
+	putTestPathKey(t, storage, sub.Uuid, filepath.Join(sub.Path, "a.go"))
+	putTestPathKey(t, storage, sub.Uuid, filepath.Join(sub.Path, "b.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, sub))
+	// 其它工作区的项目
+	other := workspace.NewProject("other", filepath.Join(t.TempDir(), "other"))
+	putTestPathKey(t, storage, other.Uuid, filepath.Join(other.Path, "c.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, other))
+
+	t.Run("项目不属于工作区时不删除", func(t *testing.T) {
+		err := idx.RemoveProjectIndexes(ctx, workspaceDir, []string{sub.Uuid, other.Uuid})
+		assert.Error(t, err)
+		assert.Equal(t, 2, storage.Size(ctx, sub.Uuid, store.PathKeySystemPrefix))
+		assert.Equal(t, 1, storage.Size(ctx, other.Uuid, store.PathKeySystemPrefix))
+	})
+
+	t.Run("删除子项目并扣减文件数", func(t *testing.T) {
+		workspaceRepo.EXPECT().GetWorkspaceByPath(workspaceDir).
+			Return(&model.Workspace{WorkspacePath: workspaceDir, CodegraphFileNum: 3}, nil)
+		workspaceRepo.EXPECT().UpdateCodegraphInfo(workspaceDir, 1, gomock.Any()).Return(nil)
+
+		require.NoError(t, idx.RemoveProjectIndexes(ctx, workspaceDir, []string{sub.Uuid, sub.Uuid}))
+		assert.Equal(t, 0, storage.Size(ctx, sub.Uuid, types.EmptyString))
+		assert.Equal(t, 1, storage.Size(ctx, project.Uuid, store.PathKeySystemPrefix))
+		assert.Equal(t, 1, storage.Size(ctx, other.Uuid, store.PathKeySystemPrefix))
+	})
+
+	t.Run("空工作区路径", func(t *testing.T) {
+		assert.Error(t, idx.RemoveProjectIndexes(ctx, "", []string{project.Uuid}))
+	})
+}
+
+func TestRebuildProject(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	for _, name := range []string{"app", "other"} {
+		projectDir := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".git"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "main.go"),
+			[]byte("package main\n\nfunc main() {}\n"), 0644))
+	}
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.MaxProjects = 2
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	app := workspace.NewProject("app", filepath.Join(workspaceDir, "app"))
+	other := workspace.NewProject("other", filepath.Join(workspaceDir, "other"))
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	t.Run("只清空并重建目标项目", func(t *testing.T) {
+		// 标记索引在源码中不存在，清空后不会被重建
+		appMarker := filepath.Join(app.Path, "marker.go")
+		otherMarker := filepath.Join(other.Path, "marker.go")
+		putTestPathKey(t, storage, app.Uuid, appMarker)
+		putTestPathKey(t, storage, other.Uuid, otherMarker)
+
+		require.NoError(t, idx.RebuildProject(ctx, workspaceDir, app.Path))
+		_, err := idx.getFileElementTable(ctx, app.Uuid, lang.Go, appMarker)
+		assert.Error(t, err)
+		_, err = idx.getFileElementTable(ctx, app.Uuid, lang.Go, filepath.Join(app.Path, "main.go"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, storage.Size(ctx, app.Uuid, store.PathKeySystemPrefix))
+		_, err = idx.getFileElementTable(ctx, other.Uuid, lang.Go, otherMarker)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, storage.Size(ctx, other.Uuid, store.PathKeySystemPrefix))
+	})
+
+	t.Run("重复重建不累加文件数", func(t *testing.T) {
+		before, err := idx.workspaceRepository.GetWorkspaceByPath(workspaceDir)
+		require.NoError(t, err)
+		require.NoError(t, idx.RebuildProject(ctx, workspaceDir, app.Path))
+		after, err := idx.workspaceRepository.GetWorkspaceByPath(workspaceDir)
+		require.NoError(t, err)
+		assert.Equal(t, before.CodegraphFileNum, after.CodegraphFileNum)
+	})
+
+	t.Run("项目不在工作区中", func(t *testing.T) {
+		assert.Error(t, idx.RebuildProject(ctx, workspaceDir, filepath.Join(workspaceDir, "not-exists")))
+		assert.Equal(t, 2, storage.Size(ctx, other.Uuid, store.PathKeySystemPrefix))
+	})
+
+	t.Run("空路径", func(t *testing.T) {
+		assert.Error(t, idx.RebuildProject(ctx, "", app.Path))
+		assert.Error(t, idx.RebuildProject(ctx, workspaceDir, ""))
+	})
+}
+
+func TestIndexFiles_CleanupStaleSymbolOccurrences(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	filePath := filepath.Join(workspaceDir, "main.go")
+	otherPath := filepath.Join(workspaceDir, "other.go")
+	require.NoError(t, os.WriteFile(filePath, []byte("package main\n\nfunc Foo() {}\n\nfunc Bar() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(otherPath, []byte("package main\n\nfunc Bar() {}\n"), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	occurrencePaths := func(name string) []string {
+		symbol, err := idx.getSymbolOccurrenceByName(ctx, workspace.NewProject(filepath.Base(workspaceDir), workspaceDir).Uuid, lang.Go, name)
+		if err != nil {
+			return nil
+		}
+		var paths []string
+		for _, o := range symbol.Occurrences {
+			paths = append(paths, o.Path)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+	require.Equal(t, []string{filePath}, occurrencePaths("Foo"))
+	require.Equal(t, []string{filePath, otherPath}, occurrencePaths("Bar"))
+
+	// 删除 Bar 的定义后重新索引该文件，文件中对 Bar 的调用不算作定义
+	require.NoError(t, os.WriteFile(filePath, []byte("package main\n\nfunc Foo() {}\n\nfunc Baz() {\n\tBar()\n}\n"), 0644))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filePath, future, future))
+	require.NoError(t, idx.IndexFiles(ctx, workspaceDir, []string{filePath}))
+
+	assert.Equal(t, []string{filePath}, occurrencePaths("Foo"))
+	assert.Equal(t, []string{otherPath}, occurrencePaths("Bar"))
+}
+
+func TestIndexFiles_CleanupSkippedFileIndexes(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	filePath := filepath.Join(workspaceDir, "main.go")
+	otherPath := filepath.Join(workspaceDir, "other.go")
+	require.NoError(t, os.WriteFile(filePath, []byte("package main\n\nfunc Foo() {}\n\nfunc Bar() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(otherPath, []byte("package main\n\nfunc Bar() {}\n"), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	projectUuid := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir).Uuid
+
+	// 文件被清空后重新索引，旧的元素表和符号定义都要删除
+	require.NoError(t, os.WriteFile(filePath, nil, 0644))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filePath, future, future))
+	require.NoError(t, idx.IndexFiles(ctx, workspaceDir, []string{filePath}))
+
+	_, err = idx.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: lang.Go, Path: filePath})
+	assert.ErrorIs(t, err, store.ErrKeyNotFound)
+	_, err = idx.getSymbolOccurrenceByName(ctx, projectUuid, lang.Go, "Foo")
+	assert.Error(t, err)
+	bar, err := idx.getSymbolOccurrenceByName(ctx, projectUuid, lang.Go, "Bar")
+	require.NoError(t, err)
+	require.Len(t, bar.Occurrences, 1)
+	assert.Equal(t, otherPath, bar.Occurrences[0].Path)
+}
+
+func TestRemoveIndexes_DeletedShebangScript(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	scriptPath := filepath.Join(workspaceDir, "manage")
+	require.NoError(t, os.WriteFile(scriptPath, []byte("#!/usr/bin/env python3\n\ndef migrate():\n    pass\n"), 0755))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	projectUuid := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir).Uuid
+	_, err = idx.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: lang.Python, Path: scriptPath})
+	require.NoError(t, err)
+
+	// 删除后无法读取 shebang，按元素表中的语言删除索引
+	require.NoError(t, os.Remove(scriptPath))
+	require.NoError(t, idx.RemoveIndexes(ctx, workspaceDir, []string{scriptPath}))
+
+	_, err = idx.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: lang.Python, Path: scriptPath})
+	assert.ErrorIs(t, err, store.ErrKeyNotFound)
+	_, err = idx.getSymbolOccurrenceByName(ctx, projectUuid, lang.Python, "migrate")
+	assert.ErrorIs(t, err, store.ErrKeyNotFound)
+}
+
 func TestRenameIndexes(t *testing.T) {
 	// 这个测试需要完整的依赖注入
 	t.Skip("需要完整的依赖注入环境")
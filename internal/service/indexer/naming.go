@@ -0,0 +1,228 @@
+package indexer
+
+import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// namingRule 预编译后的命名规则
+type namingRule struct {
+	*types.NamingRule
+	pattern      *regexp.Regexp
+	elementTypes map[types.ElementType]struct{}
+}
+
+// matchType 规则是否适用于该定义类型
+func (r *namingRule) matchType(t types.ElementType) bool {
+	if len(r.elementTypes) == 0 {
+		return true
+	}
+	_, ok := r.elementTypes[t]
+	return ok
+}
+
+// DefaultNamingRules 默认命名规范规则
+func DefaultNamingRules() []*types.NamingRule {
+	return []*types.NamingRule{
+		{
+			Name:         "go-exported-doc",
+			Language:     string(lang.Go),
+			ElementTypes: []types.ElementType{types.ElementTypeFunction, types.ElementTypeMethod},
+			ExportedOnly: true,
+			RequireDoc:   true,
+		},
+		{
+			Name:         "python-snake-case",
+			Language:     string(lang.Python),
+			ElementTypes: []types.ElementType{types.ElementTypeFunction, types.ElementTypeMethod},
+			Pattern:      `^_{0,2}[a-z][a-z0-9_]*$`,
+		},
+		{
+			Name:         "python-class-cap-words",
+			Language:     string(lang.Python),
+			ElementTypes: []types.ElementType{types.ElementTypeClass},
+			Pattern:      `^_?[A-Z][A-Za-z0-9]*$`,
+		},
+		{
+			Name:         "java-method-camel-case",
+			Language:     string(lang.Java),
+			ElementTypes: []types.ElementType{types.ElementTypeMethod},
+			Pattern:      `^[a-z][A-Za-z0-9]*$`,
+		},
+		{
+			Name:         "java-class-pascal-case",
+			Language:     string(lang.Java),
+			ElementTypes: []types.ElementType{types.ElementTypeClass, types.ElementTypeInterface},
+			Pattern:      `^[A-Z][A-Za-z0-9]*$`,
+		},
+	}
+}
+
+// QueryNamingIssues 基于已索引的定义检查命名规范，rules 为空时使用默认规则
+func (idx *Indexer) QueryNamingIssues(ctx context.Context, workspacePath string, rules []*types.NamingRule) ([]*types.NamingIssue, error) {
+	if workspacePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("workspace")
+	}
+	if len(rules) == 0 {
+		rules = DefaultNamingRules()
+	}
+	rulesByLanguage, err := compileNamingRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	projects := idx.workspaceReader.FindProjects(ctx, workspacePath, false, workspace.DefaultVisitPattern)
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project found in workspace %s", workspacePath)
+	}
+
+	var issues []*types.NamingIssue
+	for _, p := range projects {
+		issues = append(issues, idx.checkProjectNaming(ctx, p.Uuid, rulesByLanguage)...)
+	}
+
+	idx.logger.Info("query naming issues for workspace %s cost %d ms, found %d issues",
+		workspacePath, time.Since(startTime).Milliseconds(), len(issues))
+	return issues, nil
+}
+
+// compileNamingRules 校验并按语言分组命名规则
+func compileNamingRules(rules []*types.NamingRule) (map[string][]*namingRule, error) {
+	rulesByLanguage := make(map[string][]*namingRule)
+	for _, r := range rules {
+		if r == nil {
+			continue
+		}
+		if r.Language == types.EmptyString {
+			return nil, fmt.Errorf("naming rule %s language cannot be empty", r.Name)
+		}
+		compiled := &namingRule{NamingRule: r}
+		if r.Pattern != types.EmptyString {
+			pattern, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid naming rule %s pattern %s: %w", r.Name, r.Pattern, err)
+			}
+			compiled.pattern = pattern
+		}
+		if len(r.ElementTypes) > 0 {
+			compiled.elementTypes = make(map[types.ElementType]struct{}, len(r.ElementTypes))
+			for _, t := range r.ElementTypes {
+				compiled.elementTypes[t] = struct{}{}
+			}
+		}
+		rulesByLanguage[r.Language] = append(rulesByLanguage[r.Language], compiled)
+	}
+	return rulesByLanguage, nil
+}
+
+// checkProjectNaming 遍历项目的元素表，按语言规则检查定义
+func (idx *Indexer) checkProjectNaming(ctx context.Context, projectUuid string, rulesByLanguage map[string][]*namingRule) []*types.NamingIssue {
+	var issues []*types.NamingIssue
+	iter := idx.storage.Iter(ctx, projectUuid)
+	defer iter.Close()
+	for iter.Next() {
+		if !store.IsElementPathKey(iter.Key()) {
+			continue
+		}
+		var elementTable codegraphpb.FileElementTable
+		if err := store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
+			idx.logger.Error("failed to unmarshal file element_table value, err: %v", err)
+			continue
+		}
+		rules, ok := rulesByLanguage[elementTable.Language]
+		if !ok {
+			continue
+		}
+		issues = append(issues, checkFileNaming(&elementTable, rules)...)
+	}
+	return issues
+}
+
+// checkFileNaming 检查单个文件中的定义
+func checkFileNaming(elementTable *codegraphpb.FileElementTable, rules []*namingRule) []*types.NamingIssue {
+	var issues []*types.NamingIssue
+	language := lang.Language(elementTable.Language)
+	for _, e := range elementTable.Elements {
+		if !e.IsDefinition || e.Name == types.EmptyString {
+			continue
+		}
+		elementType := proto.ElementTypeFromProto(e.ElementType)
+		exported := isExportedName(language, e.Name)
+		for _, r := range rules {
+			if !r.matchType(elementType) {
+				continue
+			}
+			if r.ExportedOnly && !exported {
+				continue
+			}
+			if r.pattern != nil && !r.pattern.MatchString(e.Name) {
+				issues = append(issues, newNamingIssue(r, elementTable.Path, e,
+					fmt.Sprintf("name %s does not match pattern %s", e.Name, r.Pattern)))
+			}
+			// 解析器只为函数、方法、类、接口定义记录文档注释范围，其它类型无法判断，不做文档检查
+			if !r.RequireDoc || !docRangeRecorded(elementType) {
+				continue
+			}
+			if !hasDocComment(e) {
+				issues = append(issues, newNamingIssue(r, elementTable.Path, e,
+					fmt.Sprintf("exported symbol %s should have a doc comment", e.Name)))
+			}
+		}
+	}
+	return issues
+}
+
+// newNamingIssue 创建命名问题
+func newNamingIssue(r *namingRule, filePath string, e *codegraphpb.Element, message string) *types.NamingIssue {
+	position := types.ToPosition(e.Range)
+	return &types.NamingIssue{
+		Rule:     r.Name,
+		Name:     e.Name,
+		Type:     string(proto.ElementTypeFromProto(e.ElementType)),
+		FilePath: filePath,
+		Position: &position,
+		Message:  message,
+	}
+}
+
+// isExportedName 根据语言约定判断符号是否导出，无可见性信息的语言视为导出
+func isExportedName(language lang.Language, name string) bool {
+	switch language {
+	case lang.Go:
+		r, _ := utf8.DecodeRuneInString(name)
+		return unicode.IsUpper(r)
+	case lang.Python, lang.JavaScript, lang.TypeScript:
+		return !strings.HasPrefix(name, types.Underline)
+	default:
+		return true
+	}
+}
+
+// docRangeRecorded 索引时是否为该类型的定义记录文档注释范围
+func docRangeRecorded(elementType types.ElementType) bool {
+	switch elementType {
+	case types.ElementTypeFunction, types.ElementTypeMethod, types.ElementTypeClass, types.ElementTypeInterface:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasDocComment 判断定义是否有文档注释，使用索引时解析器记录的文档注释范围，与查询定义时填充的文档一致
+func hasDocComment(e *codegraphpb.Element) bool {
+	docRange, err := proto.GetDocRangeFromExtraData(e.ExtraData)
+	return err == nil && len(docRange) == 4
+}
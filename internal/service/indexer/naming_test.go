@@ -0,0 +1,140 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestIndexerWithStorage 创建使用真实 leveldb 存储和工作区读取器的索引器
+func newTestIndexerWithStorage(t testing.TB) (*Indexer, *store.LevelDBStorage) {
+	t.Helper()
+	logger := &mockLogger{}
+	storage, err := store.NewLevelDBStorage(t.TempDir(), logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+	return &Indexer{
+		workspaceReader: workspace.NewWorkSpaceReader(logger),
+		storage:         storage,
+		config:          &Config{},
+		logger:          logger,
+	}, storage
+}
+
+// saveTestFileElementTable 写入源文件并保存对应的元素表
+func saveTestFileElementTable(t testing.TB, storage store.GraphStorage, projectUuid string,
+	language lang.Language, filePath string, content string, elements []*codegraphpb.Element) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	err := storage.Put(context.Background(), projectUuid, &store.Entry{
+		Key: store.ElementPathKey{Language: language, Path: filePath},
+		Value: &codegraphpb.FileElementTable{
+			Path:     filePath,
+			Language: string(language),
+			Elements: elements,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestQueryNamingIssues(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	pyFile := filepath.Join(workspaceDir, "user.py")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Python, pyFile,
+		"def getUserName():\n    return 'a'\n\n\ndef get_user_id():\n    return 1\n",
+		[]*codegraphpb.Element{
+			{Name: "getUserName", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{0, 0, 1, 14}},
+			{Name: "get_user_id", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{4, 0, 5, 12}},
+		})
+
+	goFile := filepath.Join(workspaceDir, "main.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, goFile,
+		"package main\n\nfunc Undocumented() {}\n\n// Documented 有文档\nfunc Documented() {}\n\nfunc private() {}\n",
+		[]*codegraphpb.Element{
+			{Name: "Undocumented", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 2, 22}},
+			{Name: "Documented", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{5, 0, 5, 20},
+				ExtraData: map[string][]byte{"docRange": []byte("[4,0,4,23]")}},
+			{Name: "private", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{7, 0, 7, 17}},
+		})
+
+	t.Run("默认规则", func(t *testing.T) {
+		issues, err := idx.QueryNamingIssues(ctx, workspaceDir, nil)
+		assert.NoError(t, err)
+
+		flagged := make(map[string]string)
+		for _, issue := range issues {
+			flagged[issue.Name] = issue.Rule
+		}
+		assert.Len(t, issues, 2)
+		assert.Equal(t, "python-snake-case", flagged["getUserName"])
+		assert.Equal(t, "go-exported-doc", flagged["Undocumented"])
+		assert.NotContains(t, flagged, "get_user_id")
+		assert.NotContains(t, flagged, "Documented")
+		assert.NotContains(t, flagged, "private")
+	})
+
+	t.Run("自定义规则", func(t *testing.T) {
+		issues, err := idx.QueryNamingIssues(ctx, workspaceDir, []*types.NamingRule{
+			{Name: "go-no-private", Language: string(lang.Go), Pattern: `^[A-Z]`},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, issues, 1)
+		assert.Equal(t, "private", issues[0].Name)
+		assert.Equal(t, goFile, issues[0].FilePath)
+		assert.Equal(t, 8, issues[0].Position.StartLine)
+	})
+
+	t.Run("无效正则", func(t *testing.T) {
+		_, err := idx.QueryNamingIssues(ctx, workspaceDir, []*types.NamingRule{
+			{Name: "bad", Language: string(lang.Go), Pattern: `(`},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryNamingIssuesUseIndexedDocRange(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	// 与 TestQueryDefinitionsIncludeDoc 相同的源码，文档检查结果与查询定义时填充的文档一致
+	files := map[string]string{
+		"main.go": "package main\n\nvar x = 1 // trailing\n\n// Documented 返回固定值\n// 第二行\nfunc Documented() int {\n\treturn 1\n}\n\n" +
+			"// 与下面的函数之间有空行\n\nfunc Undocumented() {}\n\n/* BlockDoc 块注释 */\nfunc BlockDoc() {}\n",
+		"util.py": "def greet(name):\n    \"\"\"Say hello.\"\"\"\n    return name\n\n\ndef plain():\n    return 1\n",
+		"Calc.java": "public class Calc {\n    /**\n     * Adds two numbers.\n     */\n    public int add(int a, int b) {\n" +
+			"        return a + b;\n    }\n\n    @Deprecated\n    public int sub(int a, int b) {\n        return a - b;\n    }\n}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	functionTypes := []types.ElementType{types.ElementTypeFunction, types.ElementTypeMethod}
+	issues, err := idx.QueryNamingIssues(ctx, workspaceDir, []*types.NamingRule{
+		{Name: "go-doc", Language: string(lang.Go), ElementTypes: functionTypes, RequireDoc: true},
+		{Name: "python-doc", Language: string(lang.Python), ElementTypes: functionTypes, RequireDoc: true},
+		{Name: "java-doc", Language: string(lang.Java), ElementTypes: functionTypes, RequireDoc: true},
+	})
+	require.NoError(t, err)
+
+	flagged := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		flagged = append(flagged, issue.Name)
+	}
+	assert.ElementsMatch(t, []string{"Undocumented", "plain", "sub"}, flagged)
+}
@@ -0,0 +1,345 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ListIndexedProjects 列出工作区下存储中已有索引的项目。
+// 文件系统中能找到的项目按 ProjectIndexExists 过滤；存储中存在但已无法对应到文件系统项目的索引标记为 orphaned，便于回收
+func (idx *Indexer) ListIndexedProjects(ctx context.Context, workspacePath string) ([]*types.IndexedProject, error) {
+	if workspacePath == types.EmptyString {
+		return nil, fmt.Errorf("workspace path cannot be empty")
+	}
+	workspacePath = utils.FileURIToPath(workspacePath)
+
+	var results []*types.IndexedProject
+	found := make(map[string]struct{})
+	projects := idx.workspaceReader.FindProjects(ctx, workspacePath, false, workspace.DefaultVisitPattern)
+	for _, p := range projects {
+		found[p.Uuid] = struct{}{}
+		exists, err := idx.storage.ProjectIndexExists(p.Uuid)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		results = append(results, &types.IndexedProject{
+			Uuid:    p.Uuid,
+			Path:    p.Path,
+			FileNum: idx.storage.Size(ctx, p.Uuid, store.PathKeySystemPrefix),
+		})
+	}
+
+	projectUuids, err := idx.storage.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, projectUuid := range projectUuids {
+		if _, ok := found[projectUuid]; ok {
+			continue
+		}
+		projectPath, filePath := idx.getIndexedProjectLocation(ctx, projectUuid)
+		if !isPathInWorkspace(workspacePath, projectPath) && !isPathInWorkspace(workspacePath, filePath) {
+			// 属于其它工作区，或无法确认归属
+			continue
+		}
+		results = append(results, &types.IndexedProject{
+			Uuid:     projectUuid,
+			Path:     projectPath,
+			FileNum:  idx.storage.Size(ctx, projectUuid, store.PathKeySystemPrefix),
+			Orphaned: true,
+		})
+	}
+	return results, nil
+}
+
+// CleanOrphanedProjectIndexes 回收存储中已失效的项目索引。为避免误删，只在以下情况删除：
+// 项目路径不属于任何已知工作区（工作区记录已删除），或已确认项目路径/所属工作区路径在文件系统中不存在。
+// 无法确认项目路径的索引保留不动
+func (idx *Indexer) CleanOrphanedProjectIndexes(ctx context.Context, workspacePaths []string) ([]*types.IndexedProject, error) {
+	if len(workspacePaths) == 0 {
+		// 没有任何工作区记录时可能是数据库被重置，不做判断
+		return nil, nil
+	}
+	projectUuids, err := idx.storage.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	var cleaned []*types.IndexedProject
+	var errs []error
+	for _, projectUuid := range projectUuids {
+		if err = utils.CheckContext(ctx); err != nil {
+			return cleaned, err
+		}
+		projectPath, filePath := idx.getIndexedProjectLocation(ctx, projectUuid)
+		reason := orphanReason(projectPath, filePath, workspacePaths)
+		if reason == types.EmptyString {
+			continue
+		}
+		fileNum := idx.storage.Size(ctx, projectUuid, store.PathKeySystemPrefix)
+		if err = idx.storage.DeleteAll(ctx, projectUuid); err != nil {
+			errs = append(errs, fmt.Errorf("delete project %s indexes err: %w", projectUuid, err))
+			continue
+		}
+		idx.logger.Info("reclaimed orphaned project %s index (path %s, %d files), reason: %s",
+			projectUuid, projectPath, fileNum, reason)
+		cleaned = append(cleaned, &types.IndexedProject{
+			Uuid:     projectUuid,
+			Path:     projectPath,
+			FileNum:  fileNum,
+			Orphaned: true,
+		})
+	}
+	return cleaned, errors.Join(errs...)
+}
+
+// CompactIndexes 压缩项目索引，回收重命名、删除文件遗留的空间，返回压缩的项目uuid。
+// workspacePath 为空时处理存储中的所有项目；minDeleted 大于0时只压缩上次压缩以来删除的 key 数达到该值的项目，
+// 存储不支持统计删除数时不压缩
+func (idx *Indexer) CompactIndexes(ctx context.Context, workspacePath string, minDeleted int64) ([]string, error) {
+	var projectUuids []string
+	if workspacePath == types.EmptyString {
+		uuids, err := idx.storage.ListProjects()
+		if err != nil {
+			return nil, err
+		}
+		projectUuids = uuids
+	} else {
+		workspacePath = utils.FileURIToPath(workspacePath)
+		for _, p := range idx.workspaceReader.FindProjects(ctx, workspacePath, true, workspace.DefaultVisitPattern) {
+			if exists, err := idx.storage.ProjectIndexExists(p.Uuid); err == nil && exists {
+				projectUuids = append(projectUuids, p.Uuid)
+			}
+		}
+	}
+
+	counter, canCount := idx.storage.(store.DeletionCounter)
+	var compacted []string
+	var errs []error
+	for _, projectUuid := range projectUuids {
+		if err := utils.CheckContext(ctx); err != nil {
+			return compacted, err
+		}
+		if minDeleted > 0 && (!canCount || counter.DeletedSinceCompaction(projectUuid) < minDeleted) {
+			continue
+		}
+		if err := idx.storage.Compact(ctx, projectUuid); err != nil {
+			errs = append(errs, fmt.Errorf("compact project %s indexes err: %w", projectUuid, err))
+			continue
+		}
+		compacted = append(compacted, projectUuid)
+	}
+	return compacted, errors.Join(errs...)
+}
+
+// orphanReason 判断项目索引是否可回收，返回原因，不可回收或无法确认时返回空串。
+// 内存文件的虚拟项目不属于任何工作区，由调用方自行管理，不回收
+func orphanReason(projectPath, filePath string, workspacePaths []string) string {
+	if strings.HasPrefix(projectPath, blobProjectPathPrefix) {
+		return types.EmptyString
+	}
+	location := projectPath
+	if location == types.EmptyString {
+		location = filePath
+	}
+	if location == types.EmptyString {
+		return types.EmptyString
+	}
+
+	owner := types.EmptyString
+	for _, workspacePath := range workspacePaths {
+		if isPathInWorkspace(workspacePath, location) && len(workspacePath) > len(owner) {
+			owner = workspacePath
+		}
+	}
+	if owner == types.EmptyString {
+		return "workspace record removed"
+	}
+	if projectPath != types.EmptyString && isPathMissing(projectPath) {
+		return "project path not exists"
+	}
+	if isPathMissing(owner) {
+		return "workspace path not exists"
+	}
+	return types.EmptyString
+}
+
+// isPathMissing 仅在确认路径不存在时返回 true，权限等其它错误视为存在
+func isPathMissing(path string) bool {
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// migrateProjectUuid 兼容旧版本按未规范化路径派生的项目UUID：项目在当前UUID下还没有索引时，
+// 查找指向同一项目路径的已有索引，迁移到当前UUID，避免旧索引成为孤立数据后全量重建。
+// UUID 由项目名和路径哈希组成，只检查项目名前缀相同的索引，不打开其它项目的数据库
+func (idx *Indexer) migrateProjectUuid(ctx context.Context, project *workspace.Project) {
+	exists, err := idx.storage.ProjectIndexExists(project.Uuid)
+	if err != nil || exists {
+		return
+	}
+	renamer, ok := idx.storage.(store.ProjectRenamer)
+	if !ok {
+		return
+	}
+	projectUuids, err := idx.storage.ListProjects()
+	if err != nil {
+		idx.logger.Debug("list projects for uuid migration err: %v", err)
+		return
+	}
+	namePrefix := project.Uuid[:strings.LastIndex(project.Uuid, types.Underline)+1]
+	projectPath := filepath.Clean(project.Path)
+	for _, projectUuid := range projectUuids {
+		if projectUuid == project.Uuid || !strings.HasPrefix(projectUuid, namePrefix) {
+			continue
+		}
+		if !idx.isLegacyProjectIndex(ctx, projectUuid, projectPath, project.Name) {
+			continue
+		}
+		if err = renamer.RenameProject(projectUuid, project.Uuid); err != nil {
+			idx.logger.Error("migrate project %s index from %s to %s err: %v", project.Path, projectUuid, project.Uuid, err)
+			return
+		}
+		idx.logger.Info("migrated project %s index from %s to %s", project.Path, projectUuid, project.Uuid)
+		return
+	}
+}
+
+// isLegacyProjectIndex 判断已有索引是否属于路径为 projectPath 的项目。优先比较记录的项目路径；
+// 旧索引没有记录项目路径时，用第一个已索引文件判断，文件须在项目目录下，
+// 且不在同名的子目录中（同名子项目的UUID前缀相同，其文件也在该项目目录下）
+func (idx *Indexer) isLegacyProjectIndex(ctx context.Context, projectUuid, projectPath, projectName string) bool {
+	indexedPath, filePath := idx.getIndexedProjectLocation(ctx, projectUuid)
+	if indexedPath != types.EmptyString {
+		return filepath.Clean(indexedPath) == projectPath
+	}
+	if filePath == types.EmptyString || !isPathInWorkspace(projectPath, filePath) {
+		return false
+	}
+	rel, err := filepath.Rel(projectPath, filepath.Dir(filepath.FromSlash(filePath)))
+	if err != nil {
+		return false
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(rel), types.Slash) {
+		if dir == projectName {
+			return false
+		}
+	}
+	return true
+}
+
+// saveProjectMeta 索引完成后记录项目元数据：schema 版本、项目路径
+func (idx *Indexer) saveProjectMeta(ctx context.Context, project *workspace.Project) error {
+	if err := idx.saveSchemaVersion(ctx, project.Uuid); err != nil {
+		return err
+	}
+	return idx.storage.Put(ctx, project.Uuid, &store.Entry{
+		Key:   store.ProjectMetaKey{MetaType: store.MetaTypeProjectPath},
+		Value: wrapperspb.String(project.Path),
+	})
+}
+
+// saveFileLimitMeta 记录项目最近一次索引是否达到最大文件数上限，未达到时清除记录
+func (idx *Indexer) saveFileLimitMeta(ctx context.Context, projectUuid string, fileLimitHit bool, maxFiles int) error {
+	key := store.ProjectMetaKey{MetaType: store.MetaTypeFileLimit}
+	if !fileLimitHit {
+		return idx.storage.Delete(ctx, projectUuid, key)
+	}
+	return idx.storage.Put(ctx, projectUuid, &store.Entry{
+		Key:   key,
+		Value: wrapperspb.Int64(int64(maxFiles)),
+	})
+}
+
+// getFileLimitMeta 获取项目最近一次索引达到的最大文件数上限，未达到时返回0
+func (idx *Indexer) getFileLimitMeta(ctx context.Context, projectUuid string) int {
+	bytes, err := idx.storage.Get(ctx, projectUuid, store.ProjectMetaKey{MetaType: store.MetaTypeFileLimit})
+	if err != nil {
+		if !errors.Is(err, store.ErrKeyNotFound) {
+			idx.logger.Debug("get project %s file limit meta err: %v", projectUuid, err)
+		}
+		return 0
+	}
+	var limit wrapperspb.Int64Value
+	if err = store.UnmarshalValue(bytes, &limit); err != nil {
+		idx.logger.Debug("unmarshal project %s file limit meta err: %v", projectUuid, err)
+		return 0
+	}
+	return int(limit.GetValue())
+}
+
+// saveCallGraphBuiltMeta 记录项目调用图反向索引构建完成的时间
+func (idx *Indexer) saveCallGraphBuiltMeta(ctx context.Context, projectUuid string) error {
+	return idx.storage.Put(ctx, projectUuid, &store.Entry{
+		Key:   store.ProjectMetaKey{MetaType: store.MetaTypeCallGraphBuilt},
+		Value: wrapperspb.Int64(time.Now().Unix()),
+	})
+}
+
+// isCallGraphBuilt 项目调用图反向索引是否已构建
+func (idx *Indexer) isCallGraphBuilt(ctx context.Context, projectUuid string) bool {
+	exists, err := idx.storage.Exists(ctx, projectUuid, store.ProjectMetaKey{MetaType: store.MetaTypeCallGraphBuilt})
+	if err != nil {
+		idx.logger.Debug("check project %s callgraph built meta err: %v", projectUuid, err)
+		return false
+	}
+	return exists
+}
+
+// getProjectPathMeta 获取索引时记录的项目路径，旧索引没有记录时返回空串
+func (idx *Indexer) getProjectPathMeta(ctx context.Context, projectUuid string) string {
+	bytes, err := idx.storage.Get(ctx, projectUuid, store.ProjectMetaKey{MetaType: store.MetaTypeProjectPath})
+	if err == nil {
+		var projectPath wrapperspb.StringValue
+		if err = store.UnmarshalValue(bytes, &projectPath); err == nil {
+			return projectPath.GetValue()
+		}
+	}
+	if !errors.Is(err, store.ErrKeyNotFound) {
+		idx.logger.Debug("get project %s path meta err: %v", projectUuid, err)
+	}
+	return types.EmptyString
+}
+
+// getIndexedProjectLocation 从存储中获取项目路径；旧索引未记录项目路径时，返回第一个已索引文件的路径用于判断归属
+func (idx *Indexer) getIndexedProjectLocation(ctx context.Context, projectUuid string) (string, string) {
+	if projectPath := idx.getProjectPathMeta(ctx, projectUuid); projectPath != types.EmptyString {
+		return projectPath, types.EmptyString
+	}
+
+	iter := idx.storage.Iter(ctx, projectUuid)
+	defer iter.Close()
+	for iter.Next() {
+		if !store.IsElementPathKey(iter.Key()) {
+			continue
+		}
+		pathKey, err := store.ToElementPathKey(iter.Key())
+		if err != nil {
+			continue
+		}
+		return types.EmptyString, pathKey.Path
+	}
+	return types.EmptyString, types.EmptyString
+}
+
+// isPathInWorkspace 判断路径是否为工作区本身或其子路径
+func isPathInWorkspace(workspacePath, path string) bool {
+	if path == types.EmptyString {
+		return false
+	}
+	return utils.PathEqual(utils.ToUnixPath(workspacePath), utils.ToUnixPath(path)) || utils.IsSubdir(workspacePath, path)
+}
@@ -0,0 +1,223 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListIndexedProjects(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+
+	// 文件系统中存在的项目
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filepath.Join(workspaceDir, "main.go"),
+		"package main\n", []*codegraphpb.Element{})
+	require.NoError(t, idx.saveProjectMeta(ctx, project))
+
+	// 源码目录已删除的项目，记录了项目路径
+	deleted := workspace.NewProject("deleted", filepath.Join(workspaceDir, "deleted"))
+	putTestPathKey(t, storage, deleted.Uuid, filepath.Join(deleted.Path, "a.go"))
+	putTestPathKey(t, storage, deleted.Uuid, filepath.Join(deleted.Path, "b.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, deleted))
+
+	// 旧版本索引未记录项目路径，根据已索引文件判断归属
+	legacy := workspace.NewProject("legacy", filepath.Join(workspaceDir, "legacy"))
+	putTestPathKey(t, storage, legacy.Uuid, filepath.Join(legacy.Path, "c.go"))
+
+	// 其它工作区的项目
+	other := workspace.NewProject("other", filepath.Join(t.TempDir(), "other"))
+	putTestPathKey(t, storage, other.Uuid, filepath.Join(other.Path, "d.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, other))
+
+	projects, err := idx.ListIndexedProjects(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	got := make(map[string]*types.IndexedProject)
+	for _, p := range projects {
+		got[p.Uuid] = p
+	}
+	assert.Len(t, got, 3)
+	assert.Equal(t, &types.IndexedProject{Uuid: project.Uuid, Path: workspaceDir, FileNum: 1}, got[project.Uuid])
+	assert.Equal(t, &types.IndexedProject{Uuid: deleted.Uuid, Path: deleted.Path, FileNum: 2, Orphaned: true}, got[deleted.Uuid])
+	assert.Equal(t, &types.IndexedProject{Uuid: legacy.Uuid, FileNum: 1, Orphaned: true}, got[legacy.Uuid])
+	assert.NotContains(t, got, other.Uuid)
+
+	_, err = idx.ListIndexedProjects(ctx, "")
+	assert.Error(t, err)
+}
+
+func putTestPathKey(t *testing.T, storage store.GraphStorage, projectUuid, filePath string) {
+	t.Helper()
+	require.NoError(t, storage.Put(context.Background(), projectUuid, &store.Entry{
+		Key:   store.ElementPathKey{Language: lang.Go, Path: filePath},
+		Value: &codegraphpb.FileElementTable{Path: filePath, Language: string(lang.Go)},
+	}))
+}
+
+func TestCleanOrphanedProjectIndexes(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	removedWorkspaceDir := t.TempDir()
+	missingWorkspaceDir := filepath.Join(t.TempDir(), "missing")
+
+	newIndexedProject := func(name, path string, withMeta bool) *workspace.Project {
+		p := workspace.NewProject(name, path)
+		putTestPathKey(t, storage, p.Uuid, filepath.Join(path, "main.go"))
+		if withMeta {
+			require.NoError(t, idx.saveProjectMeta(ctx, p))
+		}
+		return p
+	}
+	alive := newIndexedProject("alive", workspaceDir, true)
+	legacyAlive := newIndexedProject("legacy_alive", filepath.Join(workspaceDir, "legacy"), false)
+	deleted := newIndexedProject("deleted", filepath.Join(workspaceDir, "deleted"), true)
+	removedWorkspace := newIndexedProject("removed", removedWorkspaceDir, true)
+	legacyMissingWorkspace := newIndexedProject("legacy_missing", filepath.Join(missingWorkspaceDir, "sub"), false)
+
+	t.Run("没有工作区记录时不回收", func(t *testing.T) {
+		cleaned, err := idx.CleanOrphanedProjectIndexes(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, cleaned)
+	})
+
+	cleaned, err := idx.CleanOrphanedProjectIndexes(ctx, []string{workspaceDir, missingWorkspaceDir})
+	require.NoError(t, err)
+	var cleanedUuids []string
+	for _, p := range cleaned {
+		cleanedUuids = append(cleanedUuids, p.Uuid)
+		assert.True(t, p.Orphaned)
+		assert.Equal(t, 1, p.FileNum)
+	}
+	assert.ElementsMatch(t, []string{deleted.Uuid, removedWorkspace.Uuid, legacyMissingWorkspace.Uuid}, cleanedUuids)
+
+	for _, p := range []*workspace.Project{alive, legacyAlive} {
+		assert.Equal(t, 1, storage.Size(ctx, p.Uuid, store.PathKeySystemPrefix), "project %s should be kept", p.Name)
+	}
+	for _, p := range []*workspace.Project{deleted, removedWorkspace, legacyMissingWorkspace} {
+		assert.Equal(t, 0, storage.Size(ctx, p.Uuid, store.PathKeySystemPrefix), "project %s should be reclaimed", p.Name)
+	}
+}
+
+func TestCleanOrphanedProjectIndexesKeepsBlobProjects(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+
+	_, err := idx.ParseAndIndexBlobs(ctx, "remote-app", []types.SourceFile{
+		{Path: "/virtual/app/main.go", Content: []byte("package main\n\nfunc main() {}\n")},
+	})
+	require.NoError(t, err)
+	blobUuid := BlobProjectUuid("remote-app")
+
+	cleaned, err := idx.CleanOrphanedProjectIndexes(ctx, []string{workspaceDir})
+	require.NoError(t, err)
+	assert.Empty(t, cleaned)
+	assert.Equal(t, 1, storage.Size(ctx, blobUuid, store.PathKeySystemPrefix))
+}
+
+func TestMigrateProjectUuid(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	// 旧版本按带末尾分隔符的路径派生出不同的UUID，项目名前缀相同
+	legacy := workspace.NewProject(project.Name, workspaceDir+string(filepath.Separator))
+	legacy.Uuid = project.Name + "_legacy"
+	putTestPathKey(t, storage, legacy.Uuid, filepath.Join(workspaceDir, "main.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, legacy))
+
+	// 其它项目的索引不受影响
+	other := workspace.NewProject("other", filepath.Join(workspaceDir, "other"))
+	putTestPathKey(t, storage, other.Uuid, filepath.Join(other.Path, "a.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, other))
+
+	idx.migrateProjectUuid(ctx, project)
+
+	projectUuids, err := storage.ListProjects()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{project.Uuid, other.Uuid}, projectUuids)
+	assert.Equal(t, 1, storage.Size(ctx, project.Uuid, store.PathKeySystemPrefix))
+
+	// 当前UUID已有索引时不再迁移
+	legacy2 := &workspace.Project{Uuid: project.Name + "_legacy2", Path: workspaceDir}
+	putTestPathKey(t, storage, legacy2.Uuid, filepath.Join(workspaceDir, "b.go"))
+	require.NoError(t, idx.saveProjectMeta(ctx, legacy2))
+	idx.migrateProjectUuid(ctx, project)
+	assert.Equal(t, 1, storage.Size(ctx, project.Uuid, store.PathKeySystemPrefix))
+	exists, err := storage.ProjectIndexExists(legacy2.Uuid)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestMigrateProjectUuid_WithoutPathMeta(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject("app", filepath.Join(workspaceDir, "app"))
+
+	// 更早的版本没有记录项目路径，只能通过已索引的文件判断归属；按名称排序，干扰项先被检查
+	nested := workspace.NewProject("app", filepath.Join(project.Path, "pkg", "app"))
+	putTestPathKey(t, storage, "app_a_nested_legacy", filepath.Join(nested.Path, "nested.go"))
+	putTestPathKey(t, storage, "app_legacy", filepath.Join(project.Path, "main.go"))
+	// 项目名前缀不同的索引即使文件在项目目录下也不迁移
+	putTestPathKey(t, storage, "a_legacy", filepath.Join(project.Path, "other.go"))
+
+	idx.migrateProjectUuid(ctx, project)
+
+	projectUuids, err := storage.ListProjects()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{project.Uuid, "app_a_nested_legacy", "a_legacy"}, projectUuids)
+	_, filePath := idx.getIndexedProjectLocation(ctx, project.Uuid)
+	assert.Equal(t, filepath.Join(project.Path, "main.go"), filePath)
+}
+
+func TestCompactIndexes(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+
+	busy := workspace.NewProject("busy", filepath.Join(workspaceDir, "busy"))
+	idle := workspace.NewProject("idle", filepath.Join(workspaceDir, "idle"))
+	var busyKeys []store.Key
+	for i := 0; i < 3; i++ {
+		filePath := filepath.Join(busy.Path, fmt.Sprintf("file%d.go", i))
+		putTestPathKey(t, storage, busy.Uuid, filePath)
+		busyKeys = append(busyKeys, store.ElementPathKey{Language: lang.Go, Path: filePath})
+	}
+	putTestPathKey(t, storage, idle.Uuid, filepath.Join(idle.Path, "main.go"))
+	require.NoError(t, storage.BatchDelete(ctx, busy.Uuid, busyKeys[:2]))
+
+	tests := []struct {
+		name       string
+		minDeleted int64
+		want       []string
+	}{
+		{name: "只压缩删除数达到阈值的项目", minDeleted: 2, want: []string{busy.Uuid}},
+		{name: "压缩后删除数清零", minDeleted: 2, want: nil},
+		{name: "不限制时压缩所有项目", minDeleted: 0, want: []string{busy.Uuid, idle.Uuid}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compacted, err := idx.CompactIndexes(ctx, "", tt.minDeleted)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, compacted)
+		})
+	}
+	assert.Equal(t, 1, storage.Size(ctx, busy.Uuid, store.PathKeySystemPrefix))
+}
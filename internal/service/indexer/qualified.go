@@ -0,0 +1,120 @@
+package indexer
+
+import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"errors"
+	"strings"
+)
+
+// qualifiedNameSeparators 包名（命名空间、类名）与符号名之间的分隔符，取最后出现的一个
+var qualifiedNameSeparators = []string{"::", "#", types.Dot}
+
+// splitQualifiedName 将限定名拆分为包名和符号名，如 net/http.HandlerFunc、com.foo.Bar、ns::Foo，
+// 没有分隔符时包名为空
+func splitQualifiedName(qualifiedName string) (pkg, symbol string) {
+	pos, sepLen := -1, 0
+	for _, sep := range qualifiedNameSeparators {
+		if i := strings.LastIndex(qualifiedName, sep); i > pos {
+			pos, sepLen = i, len(sep)
+		}
+	}
+	if pos < 0 {
+		return types.EmptyString, qualifiedName
+	}
+	return qualifiedName[:pos], qualifiedName[pos+sepLen:]
+}
+
+// ResolveQualifiedName 不依赖文件上下文，按限定名（包名.符号名）查询定义。先在工作区所有项目的符号索引中
+// 按符号名查找，再按所在目录或文件与包名完全匹配过滤；Go 的包名会去掉项目的 module 前缀。
+// 项目内未找到时查询外部索引，同样按包名过滤
+func (idx *Indexer) ResolveQualifiedName(ctx context.Context, workspacePath, qualifiedName string) ([]*types.Definition, error) {
+	if workspacePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("workspace")
+	}
+	qualifiedName = strings.TrimSpace(qualifiedName)
+	pkg, symbol := splitQualifiedName(qualifiedName)
+	if symbol == types.EmptyString {
+		return nil, errs.NewInvalidParamErr("qualifiedName", qualifiedName)
+	}
+	projects, err := idx.getQueryProjects(ctx, workspacePath, types.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+
+	languages := lang.GetAllSupportedLanguages()
+	var results []*types.Definition
+	for _, project := range projects {
+		for _, language := range languages {
+			exist, err := idx.getSymbolOccurrenceByName(ctx, project.Uuid, language, symbol)
+			if errors.Is(err, store.ErrKeyNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			imports := idx.qualifiedPackageImports(ctx, language, project, pkg)
+			for _, o := range exist.Occurrences {
+				if !matchQualifiedPackage(imports, o.Path) {
+					continue
+				}
+				results = append(results, &types.Definition{
+					Path:  o.Path,
+					Name:  symbol,
+					Range: o.Range,
+					Type:  string(proto.ToDefinitionElementType(proto.ElementTypeFromProto(o.ElementType))),
+				})
+			}
+		}
+	}
+	if len(results) > 0 {
+		return results, nil
+	}
+
+	imports := idx.qualifiedPackageImports(ctx, types.EmptyString, nil, pkg)
+	for _, d := range idx.queryExternalDefinitions(ctx, languages, symbol) {
+		if matchQualifiedPackage(imports, d.Path) {
+			results = append(results, d)
+		}
+	}
+	return results, nil
+}
+
+// qualifiedPackageImports 将限定名中的包名转换为导入，与预处理后的导入格式一致（路径分隔符转为 .）。
+// project 非空时额外按项目预处理一次（如去掉 Go module 前缀），包名为空时返回 nil
+func (idx *Indexer) qualifiedPackageImports(ctx context.Context, language lang.Language,
+	project *workspace.Project, pkg string) []*codegraphpb.Import {
+	if pkg == types.EmptyString {
+		return nil
+	}
+	dotted := strings.NewReplacer(types.UnixSeparator, types.Dot, types.WindowsSeparator, types.Dot, "::", types.Dot).Replace(pkg)
+	imports := []*codegraphpb.Import{{Name: dotted, Source: dotted}}
+	if project == nil || idx.analyzer == nil {
+		return imports
+	}
+	processed, err := idx.analyzer.PreprocessImports(ctx, language, project,
+		[]*resolver.Import{{BaseElement: &resolver.BaseElement{Name: pkg}, Source: pkg}})
+	if err != nil {
+		return imports
+	}
+	for _, imp := range processed {
+		imports = append(imports, &codegraphpb.Import{Name: imp.Name, Source: imp.Source})
+	}
+	return imports
+}
+
+// matchQualifiedPackage 定义所在目录或文件（去掉扩展名）以包名结尾，imports 为空时不过滤
+func matchQualifiedPackage(imports []*codegraphpb.Import, defPath string) bool {
+	if len(imports) == 0 {
+		return true
+	}
+	return analyzer.ImportMatchConfidence(types.EmptyString, imports, defPath) == analyzer.ImportConfidenceExact
+}
@@ -0,0 +1,93 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"codebase-indexer/internal/errs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitQualifiedName(t *testing.T) {
+	tests := []struct {
+		name       string
+		qualified  string
+		wantPkg    string
+		wantSymbol string
+	}{
+		{name: "Go 包路径", qualified: "net/http.HandlerFunc", wantPkg: "net/http", wantSymbol: "HandlerFunc"},
+		{name: "Java 全限定类名", qualified: "com.foo.Bar", wantPkg: "com.foo", wantSymbol: "Bar"},
+		{name: "C++ 命名空间", qualified: "ns::inner::Foo", wantPkg: "ns::inner", wantSymbol: "Foo"},
+		{name: "Java 方法引用", qualified: "com.foo.Bar#baz", wantPkg: "com.foo.Bar", wantSymbol: "baz"},
+		{name: "没有包名", qualified: "Foo", wantPkg: "", wantSymbol: "Foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, symbol := splitQualifiedName(tt.qualified)
+			assert.Equal(t, tt.wantPkg, pkg)
+			assert.Equal(t, tt.wantSymbol, symbol)
+		})
+	}
+}
+
+func TestResolveQualifiedName(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                     "module example.com/demo\n\ngo 1.24\n",
+		"pkg/util/util.go":           "package util\n\nfunc Hello() string {\n\treturn \"util\"\n}\n",
+		"pkg/other/other.go":         "package other\n\nfunc Hello() string {\n\treturn \"other\"\n}\n",
+		"src/com/foo/Bar.java":       "package com.foo;\n\npublic class Bar {\n}\n",
+		"src/com/foobar/Bar.java":    "package com.foobar;\n\npublic class Bar {\n}\n",
+		"src/com/foo/Service.java":   "package com.foo;\n\npublic class Service {\n    public void run() {\n    }\n}\n",
+		"src/com/foo/Scheduler.java": "package com.foo;\n\npublic class Scheduler {\n    public void run() {\n    }\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		qualified string
+		wantPaths []string
+		wantErr   bool
+	}{
+		{name: "Go module 路径限定的函数", qualified: "example.com/demo/pkg/util.Hello",
+			wantPaths: []string{"pkg/util/util.go"}},
+		{name: "Go 项目内相对包路径", qualified: "pkg/other.Hello", wantPaths: []string{"pkg/other/other.go"}},
+		{name: "Java 全限定类名不匹配前缀相同的包", qualified: "com.foo.Bar", wantPaths: []string{"src/com/foo/Bar.java"}},
+		{name: "Java 类名限定的方法", qualified: "com.foo.Service.run", wantPaths: []string{"src/com/foo/Service.java"}},
+		{name: "没有包名时返回全部同名定义", qualified: "Hello", wantPaths: []string{"pkg/other/other.go", "pkg/util/util.go"}},
+		{name: "包名不匹配", qualified: "example.com/demo/pkg/missing.Hello"},
+		{name: "缺少符号名", qualified: "com.foo.", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.ResolveQualifiedName(ctx, workspaceDir, tt.qualified)
+			if tt.wantErr {
+				assert.EqualError(t, err, errs.NewInvalidParamErr("qualifiedName", tt.qualified).Error())
+				return
+			}
+			require.NoError(t, err)
+			var paths []string
+			for _, d := range definitions {
+				rel, err := filepath.Rel(workspaceDir, d.Path)
+				require.NoError(t, err)
+				paths = append(paths, filepath.ToSlash(rel))
+			}
+			sort.Strings(paths)
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
@@ -9,11 +9,16 @@ import (
 	"codebase-indexer/pkg/codegraph/resolver"
 	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 )
@@ -23,55 +28,151 @@ import (
 // 支持查询某个文件内的符号的引用
 // 支持查询某个文件内的行范围的符号的引用
 func (idx *Indexer) QueryReferences(ctx context.Context, opts *types.QueryReferenceOptions) ([]*types.RelationNode, error) {
+	page, err := idx.QueryReferencesPage(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return page.Definitions, nil
+}
+
+// QueryReferencesPage 查询引用并按 Offset/Limit 分页，同时返回引用总数。
+// 引用在全部收集后按所属定义、文件路径、位置排序，相同条件的多次查询分页结果一致
+func (idx *Indexer) QueryReferencesPage(ctx context.Context, opts *types.QueryReferenceOptions) (*types.ReferencePage, error) {
+	if opts.Offset < 0 {
+		return nil, errs.NewInvalidParamErr("offset", opts.Offset)
+	}
+	if opts.Limit < 0 {
+		return nil, errs.NewInvalidParamErr("limit", opts.Limit)
+	}
+	var definitions []*types.RelationNode
+	err := idx.QueryReferencesStream(ctx, opts, func(definition, reference *types.RelationNode) error {
+		if reference == nil {
+			definitions = append(definitions, definition)
+		} else {
+			definition.Children = append(definition.Children, reference)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, def := range definitions {
+		sortReferences(def.Children)
+		total += len(def.Children)
+	}
+	paginateReferences(definitions, opts.Offset, opts.Limit)
+	return &types.ReferencePage{Definitions: definitions, Total: total}, nil
+}
+
+// sortReferences 引用按文件路径、位置排序
+func sortReferences(references []*types.RelationNode) {
+	sort.SliceStable(references, func(i, j int) bool {
+		if references[i].FilePath != references[j].FilePath {
+			return references[i].FilePath < references[j].FilePath
+		}
+		if references[i].Position == nil || references[j].Position == nil {
+			return references[j].Position != nil
+		}
+		return positionLess(*references[i].Position, *references[j].Position)
+	})
+}
+
+// paginateReferences 将各定义的引用按定义顺序首尾相接后截取 [offset, offset+limit)，limit 小于等于0时只跳过 offset
+func paginateReferences(definitions []*types.RelationNode, offset, limit int) {
+	if offset <= 0 && limit <= 0 {
+		return
+	}
+	skip, remaining := offset, limit
+	for _, def := range definitions {
+		children := def.Children
+		if skip >= len(children) {
+			skip -= len(children)
+			def.Children = children[:0]
+			continue
+		}
+		children = children[skip:]
+		skip = 0
+		if limit > 0 {
+			children = children[:min(len(children), remaining)]
+			remaining -= len(children)
+		}
+		def.Children = children
+	}
+}
+
+// withoutTestReferences 丢弃测试文件中的引用，定义不受影响
+func withoutTestReferences(emit types.ReferenceEmitter) types.ReferenceEmitter {
+	return func(definition, reference *types.RelationNode) error {
+		if reference != nil && lang.IsTestFile(reference.FilePath) {
+			return nil
+		}
+		return emit(definition, reference)
+	}
+}
+
+// QueryReferencesStream 流式查询引用，查询条件同 QueryReferences。
+// 先回调找到的定义，再在遍历文件的过程中逐个回调引用，不在内存中累积结果；回调返回错误时终止查询
+func (idx *Indexer) QueryReferencesStream(ctx context.Context, opts *types.QueryReferenceOptions, emit types.ReferenceEmitter) error {
 	startTime := time.Now()
-	filePath := opts.FilePath
-	start, end := NormalizeLineRange(opts.StartLine, opts.EndLine, MaxQueryLineLimit)
+	if _, err := referenceElementTypes(opts.ReferenceKinds); err != nil {
+		return err
+	}
+	opts.Workspace = utils.FileURIToPath(opts.Workspace)
+	filePath, err := utils.NormalizeFilePath(opts.Workspace, opts.FilePath)
+	if err != nil {
+		return err
+	}
+	opts.FilePath = filePath
+	start, end := NormalizeLineRange(opts.StartLine, opts.EndLine, lineLimitOrDefault(opts.MaxLineLimit, MaxQueryLineLimit))
 	opts.StartLine = start
 	opts.EndLine = end
+	if opts.ExcludeTests {
+		emit = withoutTestReferences(emit)
+	}
 
 	if filePath == types.EmptyString {
-		if opts.SymbolName != types.EmptyString {
+		if opts.SymbolName != types.EmptyString && !opts.SameFileOnly {
 			// 根据符号名查询，根据SymbolName查询引用的位置
-			return idx.queryReferencesBySymbolName(ctx, opts)
+			return idx.queryReferencesBySymbolName(ctx, opts, emit)
 		}
-		return nil, errs.NewMissingParamError("filePath")
+		return errs.NewMissingParamError("filePath")
 	}
 
 	if !filepath.IsAbs(filePath) {
-		return nil, fmt.Errorf("param filePath must be absolute path")
+		return fmt.Errorf("param filePath must be absolute path")
 	}
-	project, err := idx.GetProjectByFilePath(ctx, opts.Workspace, filePath)
+	project, err := idx.getQueryProject(ctx, opts.Workspace, opts.ProjectUuid, filePath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	projectUuid := project.Uuid
 	defer func() {
-		idx.logger.Info("Query_reference execution time: %d ms", time.Since(startTime).Milliseconds())
+		idx.ctxLogger(ctx).Info("Query_reference execution time: %d ms", time.Since(startTime).Milliseconds())
 	}()
 
 	// 1. 获取文件元素表
 	fileElementTable, err := idx.getFileElementTableByPath(ctx, projectUuid, filePath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var definitions []*types.RelationNode
 	var foundSymbols []*codegraphpb.Element
 
 	// Find root symbols based on query options
 	if opts.SymbolName != types.EmptyString {
 		foundSymbols = idx.querySymbolsByName(fileElementTable, opts)
-		idx.logger.Debug("Found %d symbols by name and line", len(foundSymbols))
+		idx.ctxLogger(ctx).Debug("Found %d symbols by name and line", len(foundSymbols))
 	} else {
 		foundSymbols = idx.querySymbolsByLines(ctx, fileElementTable, opts)
-		idx.logger.Debug("Found %d symbols by position", len(foundSymbols))
+		idx.ctxLogger(ctx).Debug("Found %d symbols by position", len(foundSymbols))
 	}
 
 	// Check if any root symbols were found
 	if len(foundSymbols) == 0 {
-		idx.logger.Debug("symbol not found: name %s line %d:%d in document %s", opts.SymbolName,
+		idx.ctxLogger(ctx).Debug("symbol not found: name %s line %d:%d in document %s", opts.SymbolName,
 			opts.StartLine, opts.EndLine, opts.FilePath)
-		return definitions, nil
+		return nil
 	}
 
 	// root
@@ -97,28 +198,39 @@ func (idx *Indexer) QueryReferences(ctx context.Context, opts *types.QueryRefere
 			NodeType:   string(proto.ElementTypeFromProto(s.ElementType)),
 			Children:   make([]*types.RelationNode, 0),
 		}
-		definitions = append(definitions, def)
+		if err = emit(def, nil); err != nil {
+			return err
+		}
 		// TODO 未处理同名定义问题，存在覆盖情况
 		definitionNames[s.Name] = def
 	}
-	if len(definitions) == 0 {
-		return definitions, nil
+	if len(definitionNames) == 0 {
+		return nil
+	}
+	if opts.SameFileOnly {
+		// 只在已加载的文件元素表中查找，不遍历项目
+		elementTypes, err := referenceElementTypes(opts.ReferenceKinds)
+		if err != nil {
+			return err
+		}
+		return emitTableReferences(fileElementTable, definitionNames, elementTypes, emit)
 	}
 	// 找定义的所有引用，通过遍历所有文件的方式
-	idx.findSymbolReferences(ctx, projectUuid, definitionNames, filePath)
-
-	return definitions, nil
+	return idx.findSymbolReferences(ctx, projectUuid, definitionNames, opts, emit)
 }
 
 // queryReferencesBySymbolName 按符号名查询引用
-func (idx *Indexer) queryReferencesBySymbolName(ctx context.Context, opts *types.QueryReferenceOptions) ([]*types.RelationNode, error) {
+func (idx *Indexer) queryReferencesBySymbolName(ctx context.Context, opts *types.QueryReferenceOptions, emit types.ReferenceEmitter) error {
 	startTime := time.Now()
 	defer func() {
-		idx.logger.Info("Query_reference execution time: %d ms", time.Since(startTime).Milliseconds())
+		idx.ctxLogger(ctx).Info("Query_reference execution time: %d ms", time.Since(startTime).Milliseconds())
 	}()
-	projects := idx.workspaceReader.FindProjects(ctx, opts.Workspace, true, workspace.DefaultVisitPattern)
+	projects, err := idx.getQueryProjects(ctx, opts.Workspace, opts.ProjectUuid)
+	if err != nil {
+		return err
+	}
 	if len(projects) == 0 {
-		return nil, fmt.Errorf("query references by symbol name [%s] failed, no project found in workspace %s", opts.SymbolName, opts.Workspace)
+		return fmt.Errorf("query references by symbol name [%s] failed, no project found in workspace %s", opts.SymbolName, opts.Workspace)
 	}
 	var defMap = make(map[string]*types.RelationNode)
 	var definitions []*types.RelationNode
@@ -129,12 +241,12 @@ func (idx *Indexer) queryReferencesBySymbolName(ctx context.Context, opts *types
 			defer iter.Close()
 			for iter.Next() {
 				key := iter.Key()
-				if store.IsSymbolNameKey(key) {
+				if !store.IsElementPathKey(key) {
 					continue
 				}
 				var elementTable codegraphpb.FileElementTable
 				if err := store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
-					idx.logger.Error("failed to unmarshal file element_table value, err: %v", err)
+					idx.ctxLogger(ctx).Error("failed to unmarshal file element_table value, err: %v", err)
 					continue
 				}
 				for _, elem := range elementTable.Elements {
@@ -167,51 +279,97 @@ func (idx *Indexer) queryReferencesBySymbolName(ctx context.Context, opts *types
 	findDefinition()
 	if len(definitions) == 0 {
 		// 提前返回，避免遍历所有文件
-		return definitions, nil
+		return nil
+	}
+	for _, def := range definitions {
+		if err = emit(def, nil); err != nil {
+			return err
+		}
 	}
 	for _, project := range projects {
 		// 找定义的所有引用，通过遍历所有文件的方式
-		idx.findSymbolReferences(ctx, project.Uuid, defMap, opts.FilePath)
+		if err = idx.findSymbolReferences(ctx, project.Uuid, defMap, opts, emit); err != nil {
+			return err
+		}
 	}
-	return definitions, nil
+	return nil
 }
 
-// findSymbolReferences 查找符号被调用或引用的位置
-func (idx *Indexer) findSymbolReferences(ctx context.Context, projectUuid string, definitionNames map[string]*types.RelationNode, filePath string) {
+// referenceElementTypes 将引用种类转换为需要收集的元素类型，为空时收集调用和引用
+func referenceElementTypes(kinds []types.SymbolKind) (map[codegraphpb.ElementType]struct{}, error) {
+	if len(kinds) == 0 {
+		kinds = []types.SymbolKind{types.SymbolKindCall, types.SymbolKindReference}
+	}
+	elementTypes := make(map[codegraphpb.ElementType]struct{}, len(kinds))
+	for _, kind := range kinds {
+		switch kind {
+		case types.SymbolKindCall:
+			elementTypes[codegraphpb.ElementType_CALL] = struct{}{}
+		case types.SymbolKindReference:
+			elementTypes[codegraphpb.ElementType_REFERENCE] = struct{}{}
+		default:
+			return nil, errs.NewInvalidParamErr("referenceKinds", kind)
+		}
+	}
+	return elementTypes, nil
+}
+
+// findSymbolReferences 查找符号被调用或引用的位置，找到即回调，回调返回错误或 ctx 结束时终止遍历
+func (idx *Indexer) findSymbolReferences(ctx context.Context, projectUuid string, definitionNames map[string]*types.RelationNode,
+	opts *types.QueryReferenceOptions, emit types.ReferenceEmitter) error {
+	elementTypes, err := referenceElementTypes(opts.ReferenceKinds)
+	if err != nil {
+		return err
+	}
+	filePath := opts.FilePath
 	iter := idx.storage.Iter(ctx, projectUuid)
 	defer iter.Close()
 	for iter.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		key := iter.Key()
-		if store.IsSymbolNameKey(key) {
+		if !store.IsElementPathKey(key) {
 			continue
 		}
 		var elementTable codegraphpb.FileElementTable
 		if err := store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
-			idx.logger.Error("failed to unmarshal file %s element_table value, err: %v", filePath, err)
+			idx.ctxLogger(ctx).Error("failed to unmarshal file %s element_table value, err: %v", filePath, err)
 			continue
 		}
 		// TODO 根据import 过滤
+		if err := emitTableReferences(&elementTable, definitionNames, elementTypes, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		for _, element := range elementTable.Elements {
-			if element.IsDefinition {
-				continue
-			}
-			if element.ElementType != codegraphpb.ElementType_REFERENCE &&
-				element.ElementType != codegraphpb.ElementType_CALL {
-				continue
-			}
-			// 引用
-			if v, ok := definitionNames[element.Name]; ok {
-				position := types.ToPosition(element.Range)
-				v.Children = append(v.Children, &types.RelationNode{
-					FilePath:   elementTable.Path,
-					SymbolName: element.Name,
-					Position:   &position,
-					NodeType:   string(proto.ElementTypeFromProto(element.ElementType)),
-				})
+// emitTableReferences 回调文件元素表中引用了 definitionNames 中定义、且种类在 elementTypes 中的元素
+func emitTableReferences(elementTable *codegraphpb.FileElementTable, definitionNames map[string]*types.RelationNode,
+	elementTypes map[codegraphpb.ElementType]struct{}, emit types.ReferenceEmitter) error {
+	for _, element := range elementTable.Elements {
+		if element.IsDefinition {
+			continue
+		}
+		// 只收集指定种类的引用
+		if _, ok := elementTypes[element.ElementType]; !ok {
+			continue
+		}
+		// 引用
+		if v, ok := definitionNames[element.Name]; ok {
+			position := types.ToPosition(element.Range)
+			if err := emit(v, &types.RelationNode{
+				FilePath:   elementTable.Path,
+				SymbolName: element.Name,
+				Position:   &position,
+				NodeType:   string(proto.ElementTypeFromProto(element.ElementType)),
+			}); err != nil {
+				return err
 			}
 		}
 	}
+	return nil
 }
 
 // querySymbolsByLines 按位置查询 occurrence
@@ -235,7 +393,7 @@ func (idx *Indexer) querySymbolsByLines(ctx context.Context, fileTable *codegrap
 		}
 
 	}
-	return nodes
+	return filterSymbolsByColumn(nodes, opts.StartLine, opts.StartColumn, opts.EndLine, opts.EndColumn)
 }
 
 // querySymbolsByName 通过 symbolName + startLine
@@ -249,15 +407,32 @@ func (idx *Indexer) querySymbolsByName(doc *codegraphpb.FileElementTable, opts *
 			nodes = append(nodes, s)
 		}
 	}
-	return nodes
+	// 指定列时，同名符号按位置收窄
+	return filterSymbolsByColumn(nodes, opts.StartLine, opts.StartColumn, opts.EndLine, opts.EndColumn)
 }
 
 // QueryDefinitions 支持单符号全局查询、行号范围内的符号定义查询、代码片段内的符号定义查询
 func (idx *Indexer) QueryDefinitions(ctx context.Context, opts *types.QueryDefinitionOptions) ([]*types.Definition, error) {
+	definitions, err := idx.queryDefinitions(ctx, opts)
+	if err != nil || !opts.IncludeDoc {
+		return definitions, err
+	}
+	idx.fillDefinitionDocs(ctx, opts.Workspace, opts.ProjectUuid, definitions)
+	return definitions, nil
+}
+
+// queryDefinitions 按查询模式查询定义
+func (idx *Indexer) queryDefinitions(ctx context.Context, opts *types.QueryDefinitionOptions) ([]*types.Definition, error) {
 	// 参数验证
 	if opts.Workspace == "" {
 		return nil, fmt.Errorf("workspace cannot be empty")
 	}
+	opts.Workspace = utils.FileURIToPath(opts.Workspace)
+	filePath, err := utils.NormalizeFilePath(opts.Workspace, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	opts.FilePath = filePath
 	if opts.FilePath == "" {
 		// 查询符号可以不用文件路径
 		// 不能超过默认最大批量查询符号定义数量，避免查询性能问题
@@ -276,54 +451,65 @@ func (idx *Indexer) QueryDefinitions(ctx context.Context, opts *types.QueryDefin
 			}
 		}
 		if len(symbolNames) > 0 {
-			return idx.queryFuncDefinitionsBySymbolNames(ctx, opts.Workspace, symbolNames)
+			return idx.queryFuncDefinitionsBySymbolNames(ctx, opts.Workspace, opts.ProjectUuid, symbolNames)
 		}
 		return nil, fmt.Errorf("file path cannot be empty")
 	}
 	if !filepath.IsAbs(opts.FilePath) {
 		return nil, fmt.Errorf("param filePath must be absolute path")
 	}
-	_, err := lang.InferLanguage(opts.FilePath)
-	if err != nil {
+	switch opts.Resolution {
+	case "", types.DefinitionResolutionRelaxed, types.DefinitionResolutionStrict, types.DefinitionResolutionScoped:
+	default:
+		return nil, errs.NewInvalidParamErr("resolution", opts.Resolution)
+	}
+	// 在文件所属语言中查找，无法推断语言时按需在所有支持的语言中查找并合并结果，
+	// .h 按 C++ 推断，C 头文件也在 C++ 下查找
+	languages := lang.GetAllSupportedLanguages()
+	if language, err := lang.InferLanguage(opts.FilePath); err == nil {
+		languages = []lang.Language{language}
+	} else if !opts.LanguageFallback {
 		return nil, errs.ErrUnSupportedLanguage
 	}
 
 	// 获取项目信息
-	project, err := idx.GetProjectByFilePath(ctx, opts.Workspace, opts.FilePath)
+	project, err := idx.getQueryProject(ctx, opts.Workspace, opts.ProjectUuid, opts.FilePath)
 	if err != nil {
 		return nil, err
 	}
-	projectUuid := project.Uuid
-
-	// 推断语言类型
-	language, err := lang.InferLanguage(opts.FilePath)
-	if err != nil {
-		return nil, lang.ErrUnSupportedLanguage
-	}
 
 	// 性能监控
 	startTime := time.Now()
 	defer func() {
-		idx.logger.Info("query func definitions cost %d ms", time.Since(startTime).Milliseconds())
+		idx.ctxLogger(ctx).Info("query func definitions cost %d ms", time.Since(startTime).Milliseconds())
 	}()
 
 	// 根据不同的查询模式处理
 	// 优先根据SymbolName查询
 	// 其次根据CodeSnippet查询
+	// 然后根据字节偏移查询（换算为行列）
 	// 最后根据行号范围查询
 	switch {
 	case len(opts.CodeSnippet) > 0:
-		return idx.queryFuncDefinitionsBySnippet(ctx, project, language, opts.FilePath, opts.CodeSnippet)
+		return idx.queryFuncDefinitionsBySnippet(ctx, project, languages[0], opts.FilePath, opts.CodeSnippet, opts.Resolution)
+	case opts.EndByte > 0:
+		if err = idx.convertByteOffsets(ctx, opts); err != nil {
+			return nil, err
+		}
+		opts.StartLine, opts.EndLine = NormalizeLineRange(opts.StartLine, opts.EndLine, MaxQueryLineLimit)
+		return idx.queryFuncDefinitionsByLineRange(ctx, project, languages, opts)
 	case opts.StartLine > 0 && opts.EndLine > 0:
 		opts.StartLine, opts.EndLine = NormalizeLineRange(opts.StartLine, opts.EndLine, MaxQueryLineLimit)
-		return idx.queryFuncDefinitionsByLineRange(ctx, projectUuid, language, opts)
+		return idx.queryFuncDefinitionsByLineRange(ctx, project, languages, opts)
 	default:
 		return nil, fmt.Errorf("invalid query definition options: at least one of CodeSnippet or line range must be provided")
 	}
 }
 
-// queryFuncDefinitionsBySnippet 查询代码片段里面所有依赖的符号的定义
-func (idx *Indexer) queryFuncDefinitionsBySnippet(ctx context.Context, project *workspace.Project, language lang.Language, filePath string, codeSnippet []byte) ([]*types.Definition, error) {
+// queryFuncDefinitionsBySnippet 查询代码片段里面所有依赖的符号的定义。
+// 定义按导入所在的包过滤，resolution 为空或 strict 时导入都不匹配的符号不返回定义
+func (idx *Indexer) queryFuncDefinitionsBySnippet(ctx context.Context, project *workspace.Project, language lang.Language, filePath string,
+	codeSnippet []byte, resolution types.DefinitionResolution) ([]*types.Definition, error) {
 	parsedData, err := idx.parser.Parse(ctx, &types.SourceFile{
 		Path:    filePath,
 		Content: codeSnippet},
@@ -342,7 +528,10 @@ func (idx *Indexer) queryFuncDefinitionsBySnippet(ctx context.Context, project *
 			dependencyNames = append(dependencyNames, r.Name)
 		}
 	}
-	if len(dependencyNames) == 0 {
+	// 变量、参数、返回值、字段的类型注解，片段中只出现类型名（没有调用、构造）时也能找到类型定义。
+	// 类型名只在本项目中查找，不查询外部索引，避免基础类型等名称带来的噪声
+	typeNames := collectSnippetTypeNames(elements, dependencyNames)
+	if len(dependencyNames) == 0 && len(typeNames) == 0 {
 		return nil, nil
 	}
 
@@ -364,16 +553,57 @@ func (idx *Indexer) queryFuncDefinitionsBySnippet(ctx context.Context, project *
 	}
 
 	// 根据所找到的call 的name + currentImports， 去模糊匹配symbol
-	symDefs, err := idx.searchSymbolNames(ctx, project.Uuid, language, dependencyNames, currentImports)
+	// 互操作语言（如 Java/Kotlin）的定义也可以被当前语言引用
+	lookupLanguages := idx.interopLanguages(language)
+	symDefs, err := idx.searchSymbolNames(ctx, project.Uuid, lookupLanguages, append(dependencyNames, typeNames...), currentImports)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search index by names: %w", err)
 	}
-	if len(symDefs) == 0 {
-		return nil, nil
+	if language == lang.Go {
+		// 导入过滤掉了全部定义的符号，沿结构体嵌入、类型别名展开导入后再查一次
+		var unresolved []string
+		for _, name := range dependencyNames {
+			if occurrences, ok := symDefs[name]; ok && len(occurrences) == 0 {
+				unresolved = append(unresolved, name)
+			}
+		}
+		if len(unresolved) > 0 {
+			embedded := idx.embeddedImports(ctx, project, filePath, currentImports)
+			if len(embedded) > 0 {
+				retried, err := idx.searchSymbolNames(ctx, project.Uuid, lookupLanguages, unresolved, append(currentImports, embedded...))
+				if err != nil {
+					return nil, fmt.Errorf("failed to search index by names: %w", err)
+				}
+				for name, occurrences := range retried {
+					symDefs[name] = occurrences
+				}
+			}
+		}
+	}
+	if resolution == types.DefinitionResolutionRelaxed || resolution == types.DefinitionResolutionScoped {
+		all, err := idx.searchSymbolNames(ctx, project.Uuid, lookupLanguages, append(dependencyNames, typeNames...), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search index by names: %w", err)
+		}
+		for name, occurrences := range all {
+			symDefs[name] = resolveSnippetOccurrences(filePath, symDefs[name], occurrences, resolution)
+		}
 	}
 
 	// 封装返回结果
-	var results []*types.Definition
+	var results, externals []*types.Definition
+	// 本地未找到的符号，查询外部索引
+	externalSearched := make(map[string]struct{})
+	for _, name := range dependencyNames {
+		if _, ok := symDefs[name]; ok {
+			continue
+		}
+		if _, ok := externalSearched[name]; ok {
+			continue
+		}
+		externalSearched[name] = struct{}{}
+		externals = append(externals, idx.queryExternalDefinitions(ctx, lookupLanguages, name)...)
+	}
 	for name, def := range symDefs {
 		for _, d := range def {
 			if d == nil {
@@ -387,31 +617,116 @@ func (idx *Indexer) queryFuncDefinitionsBySnippet(ctx context.Context, project *
 			})
 		}
 	}
-	return results, nil
+	return idx.rankDefinitions(project.Path, filePath, currentImports, results, externals), nil
+}
+
+// resolveSnippetOccurrences 按解析策略取舍代码片段中符号的定义，imported 为导入匹配的定义，all 为全部同名定义。
+// scoped 优先返回同文件、其次同目录的定义，之后与 relaxed 一样在导入都不匹配时返回全部同名定义
+func resolveSnippetOccurrences(filePath string, imported, all []*codegraphpb.Occurrence,
+	resolution types.DefinitionResolution) []*codegraphpb.Occurrence {
+	if resolution == types.DefinitionResolutionScoped {
+		if scoped := scopedOccurrences(filePath, all); len(scoped) > 0 {
+			return scoped
+		}
+	}
+	if len(imported) == 0 {
+		return all
+	}
+	return imported
+}
+
+// snippetTypeNameRegex 匹配类型注解中的（可能带包名限定的）标识符，如 *pkg.User、[]Order、Map<String, User>
+var snippetTypeNameRegex = regexp.MustCompile(`[A-Za-z_][\w.]*`)
+
+// collectSnippetTypeNames 收集代码片段中变量、参数、返回值、字段的类型名，去掉包名限定，排除已在 exclude 中的名称
+func collectSnippetTypeNames(elements []resolver.Element, exclude []string) []string {
+	seen := make(map[string]struct{}, len(exclude))
+	for _, name := range exclude {
+		seen[name] = struct{}{}
+	}
+	var typeNames []string
+	add := func(annotations ...string) {
+		for _, typ := range extractTypeNames(annotations...) {
+			if _, ok := seen[typ]; ok {
+				continue
+			}
+			seen[typ] = struct{}{}
+			typeNames = append(typeNames, typ)
+		}
+	}
+	addDeclaration := func(d *resolver.Declaration) {
+		if d == nil {
+			return
+		}
+		for _, p := range d.Parameters {
+			add(p.Type...)
+		}
+		add(d.ReturnType...)
+	}
+	for _, e := range elements {
+		switch v := e.(type) {
+		case *resolver.Variable:
+			add(v.VariableType...)
+		case *resolver.Function:
+			addDeclaration(v.Declaration)
+		case *resolver.Method:
+			addDeclaration(v.Declaration)
+		case *resolver.Class:
+			for _, f := range v.Fields {
+				add(f.Type)
+			}
+		}
+	}
+	return typeNames
+}
+
+// extractTypeNames 从类型注解中提取类型名，去掉包名限定，跳过解析器标记的基础类型，结果可能包含重复名称
+func extractTypeNames(annotations ...string) []string {
+	var typeNames []string
+	for _, annotation := range annotations {
+		if annotation == types.PrimitiveType {
+			continue
+		}
+		for _, typ := range snippetTypeNameRegex.FindAllString(annotation, -1) {
+			if i := strings.LastIndex(typ, types.Dot); i >= 0 {
+				typ = typ[i+1:]
+			}
+			if typ != types.EmptyString {
+				typeNames = append(typeNames, typ)
+			}
+		}
+	}
+	return typeNames
 }
 
-// queryFuncDefinitionsByLineRange 通过行号范围查询函数定义
-func (idx *Indexer) queryFuncDefinitionsByLineRange(ctx context.Context, projectUuid string, language lang.Language, opts *types.QueryDefinitionOptions) ([]*types.Definition, error) {
+// queryFuncDefinitionsByLineRange 通过行号范围查询函数定义，languages 为文件的候选语言，
+// 文件元素表取第一个存在的语言，引用符号的定义在所有候选语言及其互操作语言中查找并合并
+func (idx *Indexer) queryFuncDefinitionsByLineRange(ctx context.Context, project *workspace.Project, languages []lang.Language, opts *types.QueryDefinitionOptions) ([]*types.Definition, error) {
+	projectUuid := project.Uuid
 	// 首先查询出来范围内的所有符号
-	var fileTable codegraphpb.FileElementTable
-	fileTableBytes, err := idx.storage.Get(ctx, projectUuid, store.ElementPathKey{Language: language, Path: opts.FilePath})
-	if errors.Is(err, store.ErrKeyNotFound) {
-		return nil, fmt.Errorf("index not found for file %s", opts.FilePath)
+	var fileTable *codegraphpb.FileElementTable
+	var err error
+	for _, language := range languages {
+		if fileTable, err = idx.getFileElementTable(ctx, projectUuid, language, opts.FilePath); err == nil {
+			break
+		}
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file %s index, err: %v", opts.FilePath, err)
-	}
-	if err = store.UnmarshalValue(fileTableBytes, &fileTable); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal file %s index value, err: %v", opts.FilePath, err)
+		return nil, err
 	}
 
 	// 查询范围内的所有符号
 	queryStartLine := int32(opts.StartLine - 1)
 	queryEndLine := int32(opts.EndLine - 1)
-	foundSymbols := idx.findSymbolInDocByLineRange(ctx, &fileTable, queryStartLine, queryEndLine)
+	foundSymbols := idx.findSymbolInDocByLineRange(ctx, fileTable, queryStartLine, queryEndLine)
+	foundSymbols = filterSymbolsByColumn(foundSymbols, opts.StartLine, opts.StartColumn, opts.EndLine, opts.EndColumn)
 	currentImports := fileTable.Imports
+	lookupLanguages := idx.interopLanguages(languages...)
+	// Go 结构体嵌入、类型别名的包按需展开，只在导入匹配不到定义时加载一次
+	var embedded []*codegraphpb.Import
+	embeddedLoaded := false
 
-	var results []*types.Definition
+	var results, externals []*types.Definition
 	for _, s := range foundSymbols {
 		if s.IsDefinition {
 			// 直接加入结果
@@ -422,28 +737,32 @@ func (idx *Indexer) queryFuncDefinitionsByLineRange(ctx context.Context, project
 				Type:  string(proto.ElementTypeFromProto(s.ElementType)),
 			})
 			continue
+		} else if opts.LocalOnly {
+			// 只在当前文件的元素表中查找同名定义，不查询符号索引
+			results = append(results, localDefinitions(fileTable, opts.FilePath, s.Name)...)
 		} else {
 			// 加载其他符号的定义
-			bytes, err := idx.storage.Get(ctx, projectUuid, store.SymbolNameKey{Name: s.GetName(),
-				Language: language})
+			occurrences, err := idx.getSymbolOccurrencesInLanguages(ctx, projectUuid, lookupLanguages, s.GetName())
 			if err != nil {
-				if !errors.Is(err, store.ErrKeyNotFound) {
-					idx.logger.Debug("get symbol occurrence err:%v", err)
-				}
+				idx.ctxLogger(ctx).Debug("get symbol occurrence err:%v", err)
 				continue
 			}
-			var exist codegraphpb.SymbolOccurrence
-			if err = store.UnmarshalValue(bytes, &exist); err != nil {
-				idx.logger.Debug("unmarshal symbol occurrence err:%v", err)
+			if len(occurrences) == 0 {
+				// 本地未找到，查询外部索引
+				externals = append(externals, idx.queryExternalDefinitions(ctx, lookupLanguages, s.GetName())...)
 				continue
 			}
 
-			// TODO 过滤效果待定
-			filtered := idx.analyzer.FilterByImports(opts.FilePath, currentImports, exist.Occurrences)
-			if len(filtered) == 0 {
-				// 防止全部过滤掉
-				filtered = exist.Occurrences
+			imports := currentImports
+			if fileTable.Language == string(lang.Go) && len(idx.analyzer.FilterByImportConfidence(opts.FilePath,
+				currentImports, occurrences, analyzer.ImportConfidencePartial)) == 0 {
+				if !embeddedLoaded {
+					embedded = idx.embeddedImports(ctx, project, opts.FilePath, currentImports)
+					embeddedLoaded = true
+				}
+				imports = append(currentImports[:len(currentImports):len(currentImports)], embedded...)
 			}
+			filtered := idx.resolveOccurrences(opts.FilePath, imports, occurrences, opts.Resolution)
 			for _, o := range filtered {
 				results = append(results, &types.Definition{
 					Path:  o.Path,
@@ -456,28 +775,214 @@ func (idx *Indexer) queryFuncDefinitionsByLineRange(ctx context.Context, project
 	}
 
 	// 最后返回结果
-	return results, nil
+	return idx.rankDefinitions(project.Path, opts.FilePath, currentImports, results, externals), nil
+}
+
+// localDefinitions 返回文件元素表中名称为 name 的定义
+func localDefinitions(fileTable *codegraphpb.FileElementTable, filePath, name string) []*types.Definition {
+	var definitions []*types.Definition
+	for _, e := range fileTable.Elements {
+		if !e.IsDefinition || e.Name != name {
+			continue
+		}
+		definitions = append(definitions, &types.Definition{
+			Path:  filePath,
+			Name:  e.Name,
+			Range: e.Range,
+			Type:  string(proto.ElementTypeFromProto(e.ElementType)),
+		})
+	}
+	return definitions
+}
+
+// resolveOccurrences 按解析策略从同名符号的定义位置中取舍
+func (idx *Indexer) resolveOccurrences(filePath string, imports []*codegraphpb.Import,
+	occurrences []*codegraphpb.Occurrence, resolution types.DefinitionResolution) []*codegraphpb.Occurrence {
+	if resolution == types.DefinitionResolutionScoped {
+		if scoped := scopedOccurrences(filePath, occurrences); len(scoped) > 0 {
+			return scoped
+		}
+	}
+	// 只返回导入匹配置信度最高的一级，New/Get 等常见名不会混入无关的同名定义
+	filtered := idx.analyzer.FilterByImportConfidence(filePath, imports, occurrences, analyzer.ImportConfidencePartial)
+	if len(filtered) == 0 && resolution != types.DefinitionResolutionStrict {
+		// 防止全部过滤掉
+		filtered = occurrences
+	}
+	return filtered
+}
+
+// scopedOccurrences 返回与 filePath 同文件的定义，没有时返回同目录的定义
+func scopedOccurrences(filePath string, occurrences []*codegraphpb.Occurrence) []*codegraphpb.Occurrence {
+	var sameFile, sameDir []*codegraphpb.Occurrence
+	for _, o := range occurrences {
+		if utils.PathEqual(o.Path, filePath) {
+			sameFile = append(sameFile, o)
+		} else if utils.IsSameParentDir(o.Path, filePath) {
+			sameDir = append(sameDir, o)
+		}
+	}
+	if len(sameFile) > 0 {
+		return sameFile
+	}
+	return sameDir
+}
+
+// maxEmbeddingDepth 沿嵌入关系展开导入的最大层数，A 嵌入 B、B 又嵌入 C 时需要两层
+const maxEmbeddingDepth = 3
+
+// embeddedImports 返回 Go 文件经由结构体嵌入、类型别名可以间接访问的包的导入，不包含 imports 中已有的包。
+// 从文件所在包和已导入的包开始，读取包内文件的元素表查找嵌入关系，新发现的包继续展开，最多 maxEmbeddingDepth 层。
+// 导入路径已去掉 module 前缀，按项目根目录下的相对路径定位包目录
+func (idx *Indexer) embeddedImports(ctx context.Context, project *workspace.Project, filePath string,
+	imports []*codegraphpb.Import) []*codegraphpb.Import {
+	known := append([]*codegraphpb.Import(nil), imports...)
+	dirs := []string{filepath.Dir(filePath)}
+	for _, imp := range imports {
+		dirs = append(dirs, goImportDir(project.Path, imp))
+	}
+	visited := make(map[string]bool)
+	var embedded []*codegraphpb.Import
+	for depth := 0; depth < maxEmbeddingDepth && len(dirs) > 0; depth++ {
+		var tables []*codegraphpb.FileElementTable
+		for _, dir := range dirs {
+			if visited[dir] {
+				continue
+			}
+			visited[dir] = true
+			tables = append(tables, idx.getPackageElementTables(ctx, project.Uuid, dir)...)
+		}
+		added := analyzer.EmbeddedImports(tables, known)
+		known = append(known, added...)
+		embedded = append(embedded, added...)
+		dirs = dirs[:0]
+		for _, imp := range added {
+			dirs = append(dirs, goImportDir(project.Path, imp))
+		}
+	}
+	return embedded
+}
+
+// goImportDir 预处理后的 Go 导入路径（module 内相对路径，以 . 分隔）对应的包目录
+func goImportDir(projectPath string, imp *codegraphpb.Import) string {
+	return filepath.Join(projectPath, filepath.FromSlash(strings.ReplaceAll(imp.Source, types.Dot, types.Slash)))
+}
+
+// getPackageElementTables 读取 Go 包目录下已索引的非测试文件的元素表，目录不存在或文件未索引时跳过
+func (idx *Indexer) getPackageElementTables(ctx context.Context, projectUuid string, dir string) []*codegraphpb.FileElementTable {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var tables []*codegraphpb.FileElementTable
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || lang.IsTestFile(name) {
+			continue
+		}
+		table, err := idx.getFileElementTable(ctx, projectUuid, lang.Go, filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// interopLanguages 在 languages 之后追加配置中与其互操作的语言，去重并保持原有顺序
+func (idx *Indexer) interopLanguages(languages ...lang.Language) []lang.Language {
+	seen := make(map[lang.Language]struct{}, len(languages))
+	result := make([]lang.Language, 0, len(languages))
+	add := func(l lang.Language) {
+		if _, ok := seen[l]; !ok {
+			seen[l] = struct{}{}
+			result = append(result, l)
+		}
+	}
+	for _, l := range languages {
+		add(l)
+	}
+	for _, l := range languages {
+		for _, group := range idx.config.InteropLanguages {
+			if slices.Contains(group, l) {
+				for _, other := range group {
+					add(other)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// getSymbolOccurrencesInLanguages 合并多个语言下同名符号的定义位置，都不存在时返回空
+func (idx *Indexer) getSymbolOccurrencesInLanguages(ctx context.Context, projectUuid string,
+	languages []lang.Language, symbolName string) ([]*codegraphpb.Occurrence, error) {
+	var occurrences []*codegraphpb.Occurrence
+	for _, language := range languages {
+		exist, err := idx.getSymbolOccurrenceByName(ctx, projectUuid, language, symbolName)
+		if errors.Is(err, store.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, exist.Occurrences...)
+	}
+	return occurrences, nil
+}
+
+// projectDefinitionBaseScore 项目内定义的基础分，保证项目内定义总是排在外部依赖之前
+const projectDefinitionBaseScore = 1
+
+// rankDefinitions 按与查询文件的接近程度为定义打分并降序排列：同文件、同目录最优先，
+// 其次是导入匹配和项目内的其他定义，外部索引中的定义得分为 0 排在最后。同分时保持原有顺序。
+// projectPath 为查询文件所属项目的路径，各查询入口统一传入解析出的项目路径，保证排序一致
+func (idx *Indexer) rankDefinitions(projectPath, filePath string, imports []*codegraphpb.Import,
+	local, externals []*types.Definition) []*types.Definition {
+	results := append(local, externals...)
+	if idx.config.DefinitionPreference == DefinitionPreferNone || idx.analyzer == nil {
+		return results
+	}
+	for _, d := range local {
+		d.Score = projectDefinitionBaseScore +
+			idx.analyzer.CalculateSymbolMatchScore(projectPath, imports, filePath, d.Path, d.Name, d.Name)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
 }
 
 // queryFuncDefinitionsBySymbolName 通过符号名查询函数定义
-func (idx *Indexer) queryFuncDefinitionsBySymbolNames(ctx context.Context, workspacePath string, symbolNames []string) ([]*types.Definition, error) {
+func (idx *Indexer) queryFuncDefinitionsBySymbolNames(ctx context.Context, workspacePath, projectUuid string, symbolNames []string) ([]*types.Definition, error) {
 	// 遍历所有的语言，查询该符号的Occurrence
 	var results []*types.Definition
 	languages := lang.GetAllSupportedLanguages()
-	projects := idx.workspaceReader.FindProjects(ctx, workspacePath, true, workspace.DefaultVisitPattern)
+	projects, err := idx.getQueryProjects(ctx, workspacePath, projectUuid)
+	if err != nil {
+		return nil, err
+	}
 	if len(projects) == 0 {
 		return nil, fmt.Errorf("query definitions by symbol names [%v] failed, no project found in workspace %s", symbolNames, workspacePath)
 	}
+	found := make(map[string]struct{})
 	for _, project := range projects {
 		for _, language := range languages {
+			keys := make([]store.Key, 0, len(symbolNames))
 			for _, symbolName := range symbolNames {
-				bytes, err := idx.storage.Get(ctx, project.Uuid, store.SymbolNameKey{Name: symbolName,
-					Language: language})
-				if err != nil {
+				keys = append(keys, store.SymbolNameKey{Name: symbolName, Language: language})
+			}
+			values, err := idx.storage.BatchGet(ctx, project.Uuid, keys)
+			if err != nil {
+				continue
+			}
+			for i, symbolName := range symbolNames {
+				if values[i] == nil {
 					continue
 				}
+				found[symbolName] = struct{}{}
 				var exist codegraphpb.SymbolOccurrence
-				if err = store.UnmarshalValue(bytes, &exist); err != nil {
+				if err = store.UnmarshalValue(values[i], &exist); err != nil {
 					return nil, err
 				}
 				// 根据Occurrence信息封装为定义
@@ -492,11 +997,17 @@ func (idx *Indexer) queryFuncDefinitionsBySymbolNames(ctx context.Context, works
 			}
 		}
 	}
+	// 本地未找到的符号，查询外部索引
+	for _, symbolName := range symbolNames {
+		if _, ok := found[symbolName]; !ok {
+			results = append(results, idx.queryExternalDefinitions(ctx, languages, symbolName)...)
+		}
+	}
 	return results, nil
 }
 
-// searchSymbolNames 搜索符号名
-func (idx *Indexer) searchSymbolNames(ctx context.Context, projectUuid string, language lang.Language, names []string, imports []*codegraphpb.Import) (
+// searchSymbolNames 在 languages 中搜索符号名，多个语言的同名定义合并
+func (idx *Indexer) searchSymbolNames(ctx context.Context, projectUuid string, languages []lang.Language, names []string, imports []*codegraphpb.Import) (
 	map[string][]*codegraphpb.Occurrence, error) {
 
 	start := time.Now()
@@ -512,19 +1023,25 @@ func (idx *Indexer) searchSymbolNames(ctx context.Context, projectUuid string, l
 	names = deduped
 	found := make(map[string][]*codegraphpb.Occurrence)
 
-	for _, name := range names {
-
-		bytes, err := idx.storage.Get(ctx, projectUuid, store.SymbolNameKey{
-			Language: language,
-			Name:     name,
-		})
+	keys := make([]store.Key, 0, len(names)*len(languages))
+	for _, language := range languages {
+		for _, name := range names {
+			keys = append(keys, store.SymbolNameKey{Language: language, Name: name})
+		}
+	}
+	values, err := idx.storage.BatchGet(ctx, projectUuid, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to batch get symbols: %w", err)
+	}
 
-		if err != nil {
+	for i := range values {
+		name := names[i%len(names)]
+		if values[i] == nil {
 			continue
 		}
 
 		var symbolOccurrence codegraphpb.SymbolOccurrence
-		if err := store.UnmarshalValue(bytes, &symbolOccurrence); err != nil {
+		if err := store.UnmarshalValue(values[i], &symbolOccurrence); err != nil {
 			return nil, fmt.Errorf("failed to deserialize index: %w", err)
 		}
 
@@ -558,7 +1075,7 @@ func (idx *Indexer) searchSymbolNames(ctx context.Context, projectUuid string, l
 		}
 	}
 
-	idx.logger.Info("codegraph symbol name search end, cost %d ms, names count: %d, key found:%d",
+	idx.ctxLogger(ctx).Info("codegraph symbol name search end, cost %d ms, names count: %d, key found:%d",
 		time.Since(start).Milliseconds(), len(names), total, len(found))
 	return found, nil
 }
@@ -572,6 +1089,75 @@ func (idx *Indexer) GetFileElementTable(ctx context.Context, workspacePath strin
 	return idx.getFileElementTableByPath(ctx, project.Uuid, filePath)
 }
 
+// GetFileOutline 获取单个文件的大纲（定义、引用、调用、导入及其位置），文件未索引时返回 errs.ErrFileNotIndexed
+func (idx *Indexer) GetFileOutline(ctx context.Context, workspacePath string, filePath string) (*types.FileOutline, error) {
+	table, err := idx.GetFileElementTable(ctx, workspacePath, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return toFileOutline(table), nil
+}
+
+// toFileOutline 将文件元素表转换为大纲，各列表按位置排序
+func toFileOutline(table *codegraphpb.FileElementTable) *types.FileOutline {
+	outline := &types.FileOutline{
+		Path:        table.Path,
+		Language:    table.Language,
+		Timestamp:   table.Timestamp,
+		Imports:     make([]*types.OutlineImport, 0, len(table.Imports)),
+		Definitions: make([]*types.OutlineElement, 0),
+		References:  make([]*types.OutlineElement, 0),
+		Calls:       make([]*types.OutlineElement, 0),
+	}
+	if table.Package != nil {
+		outline.Package = &types.OutlineElement{
+			Name:     table.Package.Name,
+			Type:     string(types.ElementTypePackage),
+			Position: types.ToPosition(table.Package.Range),
+		}
+	}
+	for _, imp := range table.Imports {
+		outline.Imports = append(outline.Imports, &types.OutlineImport{
+			Name:     imp.Name,
+			Source:   imp.Source,
+			Alias:    imp.Alias,
+			Position: types.ToPosition(imp.Range),
+		})
+	}
+	for _, elem := range table.Elements {
+		element := &types.OutlineElement{
+			Name:     elem.Name,
+			Type:     string(proto.ElementTypeFromProto(elem.ElementType)),
+			Position: types.ToPosition(elem.Range),
+		}
+		switch {
+		case elem.IsDefinition:
+			outline.Definitions = append(outline.Definitions, element)
+		case elem.ElementType == codegraphpb.ElementType_CALL:
+			outline.Calls = append(outline.Calls, element)
+		default:
+			outline.References = append(outline.References, element)
+		}
+	}
+	sort.SliceStable(outline.Imports, func(i, j int) bool {
+		return positionLess(outline.Imports[i].Position, outline.Imports[j].Position)
+	})
+	for _, elements := range [][]*types.OutlineElement{outline.Definitions, outline.References, outline.Calls} {
+		sort.SliceStable(elements, func(i, j int) bool {
+			return positionLess(elements[i].Position, elements[j].Position)
+		})
+	}
+	return outline
+}
+
+// positionLess 按开始行、开始列比较位置
+func positionLess(a, b types.Position) bool {
+	if a.StartLine != b.StartLine {
+		return a.StartLine < b.StartLine
+	}
+	return a.StartColumn < b.StartColumn
+}
+
 // queryElements 查询elements
 func (idx *Indexer) queryElements(ctx context.Context, workspacePath string, filePaths []string) ([]*codegraphpb.FileElementTable, error) {
 	idx.logger.Info("start to query workspace %s files: %v", workspacePath, filePaths)
@@ -591,20 +1177,15 @@ func (idx *Indexer) queryElements(ctx context.Context, workspacePath string, fil
 
 	for puuid, pfiles := range projectFilesMap {
 		for _, fp := range pfiles {
-			language, err := lang.InferLanguage(fp)
+			keys, err := candidateElementPathKeys(fp)
 			if err != nil {
 				continue
 			}
-			fileTable, err := idx.storage.Get(context.Background(), puuid, store.ElementPathKey{Language: language, Path: fp})
+			ft, err := idx.lookupElementTable(context.Background(), puuid, keys)
 			if err != nil {
 				errs = append(errs, fmt.Errorf("get file table %s failed: %w", fp, err))
 				continue
 			}
-			ft := new(codegraphpb.FileElementTable)
-			if err = store.UnmarshalValue(fileTable, ft); err != nil {
-				errs = append(errs, err)
-				continue
-			}
 			results = append(results, ft)
 		}
 	}
@@ -1,12 +1,27 @@
 package indexer
 
 import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/analyzer"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/parser"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockLogger 是一个简单的 mock logger
@@ -257,3 +272,1504 @@ func TestQueryDefinitions(t *testing.T) {
 	t.Skip("需要完整的依赖注入环境")
 }
 
+func TestQuerySymbolsByColumn(t *testing.T) {
+	idx := &Indexer{logger: &mockLogger{}}
+
+	// 同一行的两个调用：foo(); bar()
+	fileTable := &codegraphpb.FileElementTable{
+		Path: "/test/file.js",
+		Elements: []*codegraphpb.Element{
+			{Name: "foo", ElementType: codegraphpb.ElementType_CALL, Range: []int32{4, 0, 4, 5}},
+			{Name: "bar", ElementType: codegraphpb.ElementType_CALL, Range: []int32{4, 7, 4, 12}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		opts      *types.QueryReferenceOptions
+		wantNames []string
+	}{
+		{
+			name:      "未指定列返回同一行的所有符号",
+			opts:      &types.QueryReferenceOptions{StartLine: 5, EndLine: 5},
+			wantNames: []string{"foo", "bar"},
+		},
+		{
+			name:      "按开始列选中第二个符号",
+			opts:      &types.QueryReferenceOptions{StartLine: 5, EndLine: 5, StartColumn: 8},
+			wantNames: []string{"bar"},
+		},
+		{
+			name:      "按结束列选中第一个符号",
+			opts:      &types.QueryReferenceOptions{StartLine: 5, EndLine: 5, StartColumn: 1, EndColumn: 6},
+			wantNames: []string{"foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := idx.querySymbolsByLines(context.Background(), fileTable, tt.opts)
+			var names []string
+			for _, r := range results {
+				names = append(names, r.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	t.Run("按列区分同一行的同名符号", func(t *testing.T) {
+		doc := &codegraphpb.FileElementTable{
+			Path: "/test/file.js",
+			Elements: []*codegraphpb.Element{
+				{Name: "foo", Range: []int32{4, 0, 4, 5}},
+				{Name: "foo", Range: []int32{4, 7, 4, 12}},
+			},
+		}
+		results := idx.querySymbolsByName(doc, &types.QueryReferenceOptions{
+			SymbolName: "foo", StartLine: 5, EndLine: 5, StartColumn: 8,
+		})
+		assert.Len(t, results, 1)
+		assert.Equal(t, int32(7), results[0].Range[1])
+
+		s := idx.findSymbolInDocByRange(doc, []int32{4, 7, 4, 12})
+		assert.NotNil(t, s)
+		assert.Equal(t, int32(7), s.Range[1])
+	})
+}
+
+// countingWorkspaceReader 统计项目发现次数的 WorkspaceReader
+type countingWorkspaceReader struct {
+	workspace.WorkspaceReader
+	lookups int
+}
+
+func (r *countingWorkspaceReader) FindProjects(ctx context.Context, workspacePath string, resolveModule bool,
+	visitPattern *types.VisitPattern) []*workspace.Project {
+	r.lookups++
+	return r.WorkspaceReader.FindProjects(ctx, workspacePath, resolveModule, visitPattern)
+}
+
+func (r *countingWorkspaceReader) GetProjectByFilePath(ctx context.Context, workspacePath string, filePath string,
+	resolveModule bool) (*workspace.Project, error) {
+	r.lookups++
+	return r.WorkspaceReader.GetProjectByFilePath(ctx, workspacePath, filePath, resolveModule)
+}
+
+func TestQueryByProjectUuid(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	reader := &countingWorkspaceReader{WorkspaceReader: idx.workspaceReader}
+	idx.workspaceReader = reader
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	defFile := filepath.Join(workspaceDir, "user.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, defFile,
+		"package main\n\nfunc GetUser() {\n}\n",
+		[]*codegraphpb.Element{
+			{Name: "GetUser", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 3, 1}},
+		})
+	callFile := filepath.Join(workspaceDir, "main.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, callFile,
+		"package main\n\nfunc main() {\n\tGetUser()\n}\n",
+		[]*codegraphpb.Element{
+			{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 4, 1}},
+			{Name: "GetUser", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 1, 3, 8}},
+		})
+	require.NoError(t, idx.saveProjectMeta(ctx, project))
+
+	tests := []struct {
+		name  string
+		query func(projectUuid string) (interface{}, error)
+	}{
+		{
+			name: "按文件查询引用",
+			query: func(projectUuid string) (interface{}, error) {
+				return idx.QueryReferences(ctx, &types.QueryReferenceOptions{
+					Workspace: workspaceDir, FilePath: defFile, SymbolName: "GetUser", ProjectUuid: projectUuid})
+			},
+		},
+		{
+			name: "按符号名查询引用",
+			query: func(projectUuid string) (interface{}, error) {
+				return idx.QueryReferences(ctx, &types.QueryReferenceOptions{
+					Workspace: workspaceDir, SymbolName: "GetUser", ProjectUuid: projectUuid})
+			},
+		},
+		{
+			name: "按行范围查询定义",
+			query: func(projectUuid string) (interface{}, error) {
+				return idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+					Workspace: workspaceDir, FilePath: defFile, StartLine: 3, EndLine: 4, ProjectUuid: projectUuid})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader.lookups = 0
+			byWorkspace, err := tt.query("")
+			require.NoError(t, err)
+			assert.Greater(t, reader.lookups, 0)
+
+			reader.lookups = 0
+			byUuid, err := tt.query(project.Uuid)
+			require.NoError(t, err)
+			assert.Equal(t, 0, reader.lookups)
+			assert.NotEmpty(t, byUuid)
+			assert.Equal(t, byWorkspace, byUuid)
+		})
+	}
+
+	t.Run("项目uuid不存在", func(t *testing.T) {
+		_, err := idx.QueryReferences(ctx, &types.QueryReferenceOptions{
+			Workspace: workspaceDir, FilePath: defFile, SymbolName: "GetUser", ProjectUuid: "not-exists"})
+		assert.Error(t, err)
+		_, err = idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+			Workspace: workspaceDir, FilePath: callFile, SymbolName: "main", ProjectUuid: "not-exists"})
+		assert.Error(t, err)
+	})
+	t.Run("使用索引记录的项目路径", func(t *testing.T) {
+		got, err := idx.getProjectByUuid(ctx, workspaceDir, project.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, workspaceDir, got.Path)
+
+		sub := workspace.NewProject("sub", filepath.Join(workspaceDir, "sub"))
+		putTestPathKey(t, storage, sub.Uuid, filepath.Join(sub.Path, "a.go"))
+		require.NoError(t, idx.saveProjectMeta(ctx, sub))
+		got, err = idx.getProjectByUuid(ctx, workspaceDir, sub.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, sub.Path, got.Path)
+		assert.Equal(t, "sub", got.Name)
+
+		// 项目不属于查询的工作区
+		_, err = idx.getProjectByUuid(ctx, t.TempDir(), sub.Uuid)
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryReferencesStream(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	defFile := filepath.Join(workspaceDir, "user.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, defFile,
+		"package main\n\nfunc GetUser() {\n}\n",
+		[]*codegraphpb.Element{
+			{Name: "GetUser", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 3, 1}},
+		})
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filepath.Join(workspaceDir, name),
+			"package main\n\nfunc init() {\n\tGetUser()\n}\n",
+			[]*codegraphpb.Element{
+				{Name: "GetUser", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 1, 3, 8}},
+			})
+	}
+
+	tests := []struct {
+		name string
+		opts *types.QueryReferenceOptions
+	}{
+		{name: "按文件查询引用", opts: &types.QueryReferenceOptions{Workspace: workspaceDir, FilePath: defFile, SymbolName: "GetUser"}},
+		{name: "按符号名查询引用", opts: &types.QueryReferenceOptions{Workspace: workspaceDir, SymbolName: "GetUser"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 定义先于引用回调，引用逐个回调而不是挂到定义的 Children 上
+			var definitions, references []*types.RelationNode
+			err := idx.QueryReferencesStream(ctx, tt.opts, func(definition, reference *types.RelationNode) error {
+				if reference == nil {
+					assert.Empty(t, references)
+					definitions = append(definitions, definition)
+					return nil
+				}
+				assert.Contains(t, definitions, definition)
+				references = append(references, reference)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Len(t, definitions, 1)
+			assert.Empty(t, definitions[0].Children)
+			assert.Len(t, references, 3)
+
+			// 缓冲版本的结果与流式一致
+			nodes, err := idx.QueryReferences(ctx, tt.opts)
+			require.NoError(t, err)
+			require.Len(t, nodes, 1)
+			assert.Equal(t, references, nodes[0].Children)
+		})
+	}
+
+	t.Run("回调返回错误时终止查询", func(t *testing.T) {
+		errStop := errors.New("stop")
+		var count int
+		err := idx.QueryReferencesStream(ctx, &types.QueryReferenceOptions{Workspace: workspaceDir, SymbolName: "GetUser"},
+			func(definition, reference *types.RelationNode) error {
+				if reference == nil {
+					return nil
+				}
+				count++
+				return errStop
+			})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestQueryReferencesByKinds(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	defFile := filepath.Join(workspaceDir, "user.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, defFile,
+		"package main\n\ntype User struct {\n}\n\nfunc NewUser() *User {\n\treturn &User{}\n}\n",
+		[]*codegraphpb.Element{
+			{Name: "User", IsDefinition: true, ElementType: codegraphpb.ElementType_CLASS, Range: []int32{2, 0, 3, 1}},
+			{Name: "NewUser", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{5, 0, 7, 1}},
+			{Name: "User", ElementType: codegraphpb.ElementType_REFERENCE, Range: []int32{5, 16, 5, 20}},
+		})
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, mainFile,
+		"package main\n\nfunc main() {\n\tvar u *User = NewUser()\n\t_ = u\n}\n",
+		[]*codegraphpb.Element{
+			{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 5, 1}},
+			{Name: "User", ElementType: codegraphpb.ElementType_REFERENCE, Range: []int32{3, 8, 3, 12}},
+			{Name: "NewUser", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 15, 3, 22}},
+		})
+
+	tests := []struct {
+		name      string
+		kinds     []types.SymbolKind
+		wantTypes map[string]int // 引用的节点类型 -> 数量
+		wantErr   bool
+	}{
+		{name: "默认返回调用和引用", wantTypes: map[string]int{"call.method": 1, "reference": 2}},
+		{name: "只返回调用", kinds: []types.SymbolKind{types.SymbolKindCall}, wantTypes: map[string]int{"call.method": 1}},
+		{name: "只返回非调用引用", kinds: []types.SymbolKind{types.SymbolKindReference}, wantTypes: map[string]int{"reference": 2}},
+		{name: "未知种类", kinds: []types.SymbolKind{"unknown"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 文件内全部符号的引用
+			nodes, err := idx.QueryReferences(ctx, &types.QueryReferenceOptions{
+				Workspace: workspaceDir, FilePath: defFile, StartLine: 1, EndLine: 9, ReferenceKinds: tt.kinds,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			gotTypes := make(map[string]int)
+			for _, n := range nodes {
+				for _, child := range n.Children {
+					gotTypes[child.NodeType]++
+				}
+			}
+			assert.Equal(t, tt.wantTypes, gotTypes)
+		})
+	}
+}
+
+func TestQueryDefinitionsLanguageFallback(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	// add 定义在 C++ 源文件中，符号索引在 C++ 下
+	utilFile := filepath.Join(workspaceDir, "util.cpp")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.CPP, utilFile,
+		"int add(int a, int b) { return a + b; }\n",
+		[]*codegraphpb.Element{
+			{Name: "add", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{0, 0, 0, 39}},
+		})
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key: store.SymbolNameKey{Language: lang.CPP, Name: "add"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "add", Language: string(lang.CPP), Occurrences: []*codegraphpb.Occurrence{
+			{Path: utilFile, Range: []int32{0, 0, 0, 39}, ElementType: codegraphpb.ElementType_FUNCTION},
+		}},
+	}))
+
+	content := "inline int twice(int x) { return add(x, x); }\n"
+	callElements := []*codegraphpb.Element{
+		{Name: "add", ElementType: codegraphpb.ElementType_CALL, Range: []int32{0, 33, 0, 36}},
+	}
+	cppHeader := filepath.Join(workspaceDir, "vec.h")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.CPP, cppHeader, content, callElements)
+	// 无扩展名的文件
+	noExtFile := filepath.Join(workspaceDir, "inline_math")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.CPP, noExtFile, content, callElements)
+
+	tests := []struct {
+		name             string
+		filePath         string
+		languageFallback bool
+		wantErr          error
+	}{
+		{name: "C++语言索引的头文件", filePath: cppHeader},
+		{name: "无扩展名文件默认不支持", filePath: noExtFile, wantErr: errs.ErrUnSupportedLanguage},
+		{name: "无扩展名文件开启回退后在所有语言中查找", filePath: noExtFile, languageFallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace:        workspaceDir,
+				FilePath:         tt.filePath,
+				StartLine:        1,
+				EndLine:          1,
+				LanguageFallback: tt.languageFallback,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, definitions, 1)
+			assert.Equal(t, "add", definitions[0].Name)
+			assert.Equal(t, utilFile, definitions[0].Path)
+		})
+	}
+}
+
+func TestQueryDefinitionsByByteOffset(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	filePath := filepath.Join(workspaceDir, "main.go")
+	content := "package main\n\nfunc Hello() {}; func World() {}\n\nfunc Other() {}\n"
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filePath, content,
+		[]*codegraphpb.Element{
+			{Name: "Hello", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 2, 15}},
+			{Name: "World", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 17, 2, 32}},
+			{Name: "Other", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{4, 0, 4, 15}},
+		})
+
+	tests := []struct {
+		name      string
+		startByte int
+		endByte   int
+		lineOpts  types.QueryDefinitionOptions
+		wantNames []string
+	}{
+		{
+			name:      "同一行按偏移选中第二个定义",
+			startByte: strings.Index(content, "func World"),
+			endByte:   strings.Index(content, "func World") + len("func World() {}"),
+			lineOpts:  types.QueryDefinitionOptions{StartLine: 3, EndLine: 3, StartColumn: 18, EndColumn: 32},
+			wantNames: []string{"World"},
+		},
+		{
+			name:      "跨行偏移",
+			startByte: strings.Index(content, "func Hello"),
+			endByte:   strings.Index(content, "func Other") + len("func Other() {}"),
+			lineOpts:  types.QueryDefinitionOptions{StartLine: 3, EndLine: 5},
+			wantNames: []string{"Hello", "World", "Other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineOpts := tt.lineOpts
+			lineOpts.Workspace, lineOpts.FilePath = workspaceDir, filePath
+			byLines, err := idx.QueryDefinitions(ctx, &lineOpts)
+			require.NoError(t, err)
+
+			byOffset, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace: workspaceDir,
+				FilePath:  filePath,
+				StartByte: tt.startByte,
+				EndByte:   tt.endByte,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, byLines, byOffset)
+
+			var names []string
+			for _, d := range byOffset {
+				names = append(names, d.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	t.Run("偏移超出文件长度", func(t *testing.T) {
+		_, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+			Workspace: workspaceDir,
+			FilePath:  filePath,
+			StartByte: 0,
+			EndByte:   len(content) + 1,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("偏移按去掉BOM后的内容换算，与索引一致", func(t *testing.T) {
+		bomFile := filepath.Join(workspaceDir, "bom.go")
+		saveTestFileElementTable(t, storage, project.Uuid, lang.Go, bomFile, "\xEF\xBB\xBF"+content,
+			[]*codegraphpb.Element{
+				{Name: "Hello", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 2, 15}},
+				{Name: "World", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 17, 2, 32}},
+			})
+		definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+			Workspace: workspaceDir,
+			FilePath:  bomFile,
+			StartByte: strings.Index(content, "func World"),
+			EndByte:   strings.Index(content, "func World") + 1,
+		})
+		require.NoError(t, err)
+		require.Len(t, definitions, 1)
+		assert.Equal(t, "World", definitions[0].Name)
+	})
+}
+
+func TestQueryDefinitionsByImportConfidence(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	idx.analyzer = &analyzer.DependencyAnalyzer{}
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	filePath := filepath.Join(workspaceDir, "cmd", "main.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filePath,
+		"package main\n\nfunc main() {\n\tuser.New()\n}\n", nil)
+	fileTable := &codegraphpb.FileElementTable{
+		Path:     filePath,
+		Language: string(lang.Go),
+		Imports:  []*codegraphpb.Import{{Name: "internal.user", Source: "internal.user"}},
+		Elements: []*codegraphpb.Element{
+			{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 4, 1}},
+			{Name: "New", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 1, 3, 11}},
+		},
+	}
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key:   store.ElementPathKey{Language: lang.Go, Path: filePath},
+		Value: fileTable,
+	}))
+
+	exactPath := filepath.Join(workspaceDir, "internal", "user", "user.go")
+	partialPath := filepath.Join(workspaceDir, "internal", "userx", "x.go")
+	unrelatedPaths := []string{
+		filepath.Join(workspaceDir, "internal", "order", "order.go"),
+		filepath.Join(workspaceDir, "pkg", "cache", "cache.go"),
+		filepath.Join(workspaceDir, "pkg", "client", "client.go"),
+	}
+	tests := []struct {
+		name      string
+		defPaths  []string
+		wantPaths []string
+	}{
+		{
+			name:      "只返回完全匹配导入的定义",
+			defPaths:  append([]string{partialPath, exactPath}, unrelatedPaths...),
+			wantPaths: []string{exactPath},
+		},
+		{
+			name:      "无完全匹配时返回部分匹配",
+			defPaths:  append([]string{partialPath}, unrelatedPaths...),
+			wantPaths: []string{partialPath},
+		},
+		{
+			name:      "都不匹配时返回全部",
+			defPaths:  unrelatedPaths,
+			wantPaths: unrelatedPaths,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences := make([]*codegraphpb.Occurrence, 0, len(tt.defPaths))
+			for _, p := range tt.defPaths {
+				occurrences = append(occurrences, &codegraphpb.Occurrence{Path: p, Range: []int32{2, 0, 4, 1},
+					ElementType: codegraphpb.ElementType_FUNCTION})
+			}
+			require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+				Key:   store.SymbolNameKey{Language: lang.Go, Name: "New"},
+				Value: &codegraphpb.SymbolOccurrence{Name: "New", Language: string(lang.Go), Occurrences: occurrences},
+			}))
+
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace: workspaceDir,
+				FilePath:  filePath,
+				StartLine: 4,
+				EndLine:   4,
+			})
+			require.NoError(t, err)
+			var paths []string
+			for _, d := range definitions {
+				paths = append(paths, d.Path)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
+
+func TestQueryDefinitionsRanking(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	idx.analyzer = &analyzer.DependencyAnalyzer{}
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	filePath := filepath.Join(workspaceDir, "pkg", "order", "service.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filePath,
+		"package order\n\nfunc Create() {\n\tHelper()\n}\n", []*codegraphpb.Element{
+			{Name: "Create", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 4, 1}},
+			{Name: "Helper", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 1, 3, 9}},
+		})
+
+	// 两个定义都不在同目录也不匹配导入，不会被导入置信度过滤，按与查询文件的接近程度排序
+	otherPath := filepath.Join(workspaceDir, "internal", "util", "helper.go")
+	similarPath := filepath.Join(workspaceDir, "pkg", "billing", "service.go")
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key: store.SymbolNameKey{Language: lang.Go, Name: "Helper"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "Helper", Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+			{Path: otherPath, Range: []int32{2, 0, 4, 1}, ElementType: codegraphpb.ElementType_FUNCTION},
+			{Path: similarPath, Range: []int32{2, 0, 4, 1}, ElementType: codegraphpb.ElementType_FUNCTION},
+		}},
+	}))
+
+	tests := []struct {
+		name       string
+		preference DefinitionPreference
+		wantPaths  []string
+		wantRanked bool
+	}{
+		{name: "默认按接近程度排序", wantPaths: []string{similarPath, otherPath}, wantRanked: true},
+		{name: "关闭排序保持索引顺序", preference: DefinitionPreferNone, wantPaths: []string{otherPath, similarPath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.config.DefinitionPreference = tt.preference
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace: workspaceDir,
+				FilePath:  filePath,
+				StartLine: 4,
+				EndLine:   4,
+			})
+			require.NoError(t, err)
+			var paths []string
+			for _, d := range definitions {
+				paths = append(paths, d.Path)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+			if tt.wantRanked {
+				assert.Greater(t, definitions[0].Score, definitions[1].Score)
+				assert.Greater(t, definitions[1].Score, 0)
+			} else {
+				assert.Zero(t, definitions[0].Score)
+			}
+		})
+	}
+}
+
+func TestQueryReferencesMaxLineLimit(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	// 两个定义分别位于第3行和第301行，默认200行上限只能覆盖第一个
+	defFile := filepath.Join(workspaceDir, "user.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, defFile,
+		"package main\n",
+		[]*codegraphpb.Element{
+			{Name: "GetUser", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 3, 1}},
+			{Name: "SaveUser", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{300, 0, 301, 1}},
+		})
+
+	tests := []struct {
+		name         string
+		maxLineLimit int
+		want         []string
+	}{
+		{name: "未指定时使用默认上限", want: []string{"GetUser"}},
+		{name: "零值回退默认上限", maxLineLimit: 0, want: []string{"GetUser"}},
+		{name: "负数回退默认上限", maxLineLimit: -1, want: []string{"GetUser"}},
+		{name: "放大上限覆盖全部定义", maxLineLimit: 500, want: []string{"GetUser", "SaveUser"}},
+		{name: "缩小上限", maxLineLimit: 4, want: []string{"GetUser"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := idx.QueryReferences(ctx, &types.QueryReferenceOptions{
+				Workspace:    workspaceDir,
+				FilePath:     defFile,
+				StartLine:    1,
+				EndLine:      400,
+				MaxLineLimit: tt.maxLineLimit,
+			})
+			require.NoError(t, err)
+			var names []string
+			for _, n := range nodes {
+				names = append(names, n.SymbolName)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestQueryDefinitionsBySnippetTypeNames(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	idx.analyzer = &analyzer.DependencyAnalyzer{}
+	idx.parser = parser.NewSourceFileParser(idx.logger)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	handlerPath := filepath.Join(workspaceDir, "handler.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, handlerPath, "package main\n", nil)
+	modelPath := filepath.Join(workspaceDir, "model.go")
+	for _, name := range []string{"UserRepo", "Order"} {
+		require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+			Key: store.SymbolNameKey{Language: lang.Go, Name: name},
+			Value: &codegraphpb.SymbolOccurrence{Name: name, Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+				{Path: modelPath, Range: []int32{2, 0, 4, 1}, ElementType: codegraphpb.ElementType_CLASS},
+			}},
+		}))
+	}
+
+	tests := []struct {
+		name    string
+		snippet string
+		want    []string
+	}{
+		{name: "参数类型", snippet: "func handle(repo *UserRepo) {\n}\n", want: []string{"UserRepo"}},
+		{name: "包名限定的切片参数类型", snippet: "func handle(orders []model.Order) {\n}\n", want: []string{"Order"}},
+		{name: "没有类型引用", snippet: "func handle() {\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace:   workspaceDir,
+				FilePath:    handlerPath,
+				CodeSnippet: []byte(tt.snippet),
+			})
+			require.NoError(t, err)
+			var names []string
+			for _, d := range definitions {
+				names = append(names, d.Name)
+				assert.Equal(t, modelPath, d.Path)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+// BenchmarkQueryDefinitionsBySnippet 代码片段引用大量标识符时的定义查询耗时
+func BenchmarkQueryDefinitionsBySnippet(b *testing.B) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(b)
+	idx.analyzer = &analyzer.DependencyAnalyzer{}
+	idx.parser = parser.NewSourceFileParser(idx.logger)
+	workspaceDir := b.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	const totalSymbols, calledSymbols = 2000, 200
+
+	handlerPath := filepath.Join(workspaceDir, "handler.go")
+	saveTestFileElementTable(b, storage, project.Uuid, lang.Go, handlerPath, "package main\n", nil)
+	for i := 0; i < totalSymbols; i++ {
+		name := fmt.Sprintf("Func%d", i)
+		require.NoError(b, storage.Put(ctx, project.Uuid, &store.Entry{
+			Key: store.SymbolNameKey{Language: lang.Go, Name: name},
+			Value: &codegraphpb.SymbolOccurrence{Name: name, Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+				{Path: filepath.Join(workspaceDir, fmt.Sprintf("pkg%d.go", i%20)), Range: []int32{2, 0, 4, 1},
+					ElementType: codegraphpb.ElementType_FUNCTION},
+			}},
+		}))
+	}
+	var snippet strings.Builder
+	snippet.WriteString("func handle() {\n")
+	for i := 0; i < calledSymbols; i++ {
+		// 一半引用已有符号，一半引用不存在的符号
+		fmt.Fprintf(&snippet, "\tFunc%d()\n", i*(totalSymbols/calledSymbols)+i%2*totalSymbols)
+	}
+	snippet.WriteString("}\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+			Workspace:   workspaceDir,
+			FilePath:    handlerPath,
+			CodeSnippet: []byte(snippet.String()),
+		})
+		require.NoError(b, err)
+		require.Len(b, definitions, calledSymbols/2)
+	}
+}
+
+// recordingLogger 记录格式化后的日志行
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) record(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Debug(format string, args ...interface{}) { l.record(format, args...) }
+func (l *recordingLogger) Info(format string, args ...interface{})  { l.record(format, args...) }
+func (l *recordingLogger) Warn(format string, args ...interface{})  { l.record(format, args...) }
+func (l *recordingLogger) Error(format string, args ...interface{}) { l.record(format, args...) }
+func (l *recordingLogger) Fatal(format string, args ...interface{}) { l.record(format, args...) }
+
+func TestQueryLogsRequestId(t *testing.T) {
+	workspaceDir := t.TempDir()
+	filePath := filepath.Join(workspaceDir, "main.go")
+	content := "package main\n\nfunc Target() {}\n\nfunc Caller() {\n\tTarget()\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(context.Background(), workspaceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		query func(ctx context.Context) error
+	}{
+		{
+			name: "查询引用",
+			query: func(ctx context.Context) error {
+				_, err := idx.QueryReferences(ctx, &types.QueryReferenceOptions{
+					Workspace: workspaceDir, FilePath: filePath, SymbolName: "Target", StartLine: 3, EndLine: 3,
+				})
+				return err
+			},
+		},
+		{
+			name: "查询定义",
+			query: func(ctx context.Context) error {
+				_, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+					Workspace: workspaceDir, FilePath: filePath, StartLine: 6, EndLine: 6,
+				})
+				return err
+			},
+		},
+		{
+			name: "查询调用图",
+			query: func(ctx context.Context) error {
+				_, err := idx.QueryCallGraph(ctx, &types.QueryCallGraphOptions{
+					Workspace: workspaceDir, FilePath: filePath, SymbolName: "Target",
+				})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingLogger{}
+			idx.logger = recorder
+			ctx := logger.WithRequestId(context.Background(), "req-123")
+			require.NoError(t, tt.query(ctx))
+
+			require.NotEmpty(t, recorder.lines)
+			for _, line := range recorder.lines {
+				assert.True(t, strings.HasPrefix(line, "[req:req-123] "), line)
+			}
+		})
+	}
+}
+
+func TestResolveOccurrences(t *testing.T) {
+	idx := &Indexer{analyzer: &analyzer.DependencyAnalyzer{}}
+	filePath := "/repo/app/views.py"
+	sameFile := &codegraphpb.Occurrence{Path: filePath}
+	sameDir := &codegraphpb.Occurrence{Path: "/repo/app/models.py"}
+	imported := &codegraphpb.Occurrence{Path: "/repo/lib/helpers.py"}
+	unrelated := &codegraphpb.Occurrence{Path: "/repo/other/helpers.py"}
+	imports := []*codegraphpb.Import{{Name: "lib.helpers", Source: "lib.helpers"}}
+
+	tests := []struct {
+		name        string
+		resolution  types.DefinitionResolution
+		occurrences []*codegraphpb.Occurrence
+		want        []*codegraphpb.Occurrence
+	}{
+		{name: "默认只保留导入匹配的定义", occurrences: []*codegraphpb.Occurrence{imported, unrelated},
+			want: []*codegraphpb.Occurrence{imported}},
+		{name: "relaxed导入都不匹配时返回全部", resolution: types.DefinitionResolutionRelaxed,
+			occurrences: []*codegraphpb.Occurrence{unrelated}, want: []*codegraphpb.Occurrence{unrelated}},
+		{name: "strict导入都不匹配时返回空", resolution: types.DefinitionResolutionStrict,
+			occurrences: []*codegraphpb.Occurrence{unrelated}, want: []*codegraphpb.Occurrence{}},
+		{name: "strict导入匹配", resolution: types.DefinitionResolutionStrict,
+			occurrences: []*codegraphpb.Occurrence{imported, unrelated}, want: []*codegraphpb.Occurrence{imported}},
+		{name: "scoped优先同文件", resolution: types.DefinitionResolutionScoped,
+			occurrences: []*codegraphpb.Occurrence{imported, sameDir, sameFile}, want: []*codegraphpb.Occurrence{sameFile}},
+		{name: "scoped其次同目录", resolution: types.DefinitionResolutionScoped,
+			occurrences: []*codegraphpb.Occurrence{imported, sameDir}, want: []*codegraphpb.Occurrence{sameDir}},
+		{name: "scoped没有同目录时按relaxed处理", resolution: types.DefinitionResolutionScoped,
+			occurrences: []*codegraphpb.Occurrence{unrelated}, want: []*codegraphpb.Occurrence{unrelated}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, idx.resolveOccurrences(filePath, imports, tt.occurrences, tt.resolution))
+		})
+	}
+
+	t.Run("非法策略", func(t *testing.T) {
+		_, err := idx.QueryDefinitions(context.Background(), &types.QueryDefinitionOptions{
+			Workspace:  "/repo",
+			FilePath:   filePath,
+			StartLine:  1,
+			EndLine:    1,
+			Resolution: "fuzzy",
+		})
+		assert.ErrorContains(t, err, "resolution")
+	})
+}
+
+func TestQueryDefinitionsReactComponents(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"components/Button.tsx": `import React from 'react';
+
+interface ButtonProps {
+  label: string;
+}
+
+export const Button = ({ label }: ButtonProps) => {
+  return <button className="btn">{label}</button>;
+};
+
+export default function Card(props: { title: string }) {
+  return <div><Button label={props.title} /></div>;
+}
+`,
+		"App.tsx": `import { Button } from './components/Button';
+
+export function App() {
+  return (
+    <main>
+      <Button label="ok" />
+    </main>
+  );
+}
+`,
+		"Widget.jsx": `export const Widget = ({ name }) => <span>{name}</span>;
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	metrics, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	require.Zero(t, metrics.TotalFailedFiles)
+
+	buttonFile := filepath.Join(workspaceDir, "components", "Button.tsx")
+	tests := []struct {
+		name     string
+		opts     *types.QueryDefinitionOptions
+		wantName string
+		wantPath string
+	}{
+		{
+			name:     "箭头函数组件按符号名查询",
+			opts:     &types.QueryDefinitionOptions{SymbolNames: "Button"},
+			wantName: "Button",
+			wantPath: buttonFile,
+		},
+		{
+			name:     "默认导出的函数组件按符号名查询",
+			opts:     &types.QueryDefinitionOptions{SymbolNames: "Card"},
+			wantName: "Card",
+			wantPath: buttonFile,
+		},
+		{
+			name:     "JSX中使用的组件解析到定义",
+			opts:     &types.QueryDefinitionOptions{FilePath: filepath.Join(workspaceDir, "App.tsx"), StartLine: 6, EndLine: 6},
+			wantName: "Button",
+			wantPath: buttonFile,
+		},
+		{
+			name:     "JSX文件中的组件",
+			opts:     &types.QueryDefinitionOptions{SymbolNames: "Widget"},
+			wantName: "Widget",
+			wantPath: filepath.Join(workspaceDir, "Widget.jsx"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Workspace = workspaceDir
+			definitions, err := idx.QueryDefinitions(ctx, tt.opts)
+			require.NoError(t, err)
+			var found bool
+			for _, d := range definitions {
+				if d.Name == tt.wantName && d.Path == tt.wantPath {
+					found = true
+				}
+			}
+			assert.True(t, found, "definitions: %+v", definitions)
+		})
+	}
+}
+
+func TestQueryDefinitionsTsPathAlias(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"tsconfig.json": `{
+  "compilerOptions": {
+    "baseUrl": ".",
+    // 别名映射
+    "paths": { "@app/*": ["src/*"], },
+  },
+}`,
+		"src/format.ts":    "export function formatDate(d: string): string {\n  return d;\n}\n",
+		"shared/format.ts": "export function formatDate(d: string): string {\n  return '';\n}\n",
+		"main.ts":          "import { formatDate } from '@app/format';\n\nexport function run() {\n  return formatDate('today');\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+		Workspace: workspaceDir,
+		FilePath:  filepath.Join(workspaceDir, "main.ts"),
+		StartLine: 4,
+		EndLine:   4,
+	})
+	require.NoError(t, err)
+	var paths []string
+	for _, d := range definitions {
+		if d.Name == "formatDate" {
+			paths = append(paths, d.Path)
+		}
+	}
+	// 别名导入只解析到 src 下的定义，不包括同名的 shared 定义
+	assert.Equal(t, []string{filepath.Join(workspaceDir, "src", "format.ts")}, paths)
+}
+
+func TestQueryDefinitionsInteropLanguages(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"src/format.js": "export function formatDate(d) {\n  return d;\n}\n",
+		"src/main.ts":   "import { formatDate } from './format';\n\nexport function run() {\n  return formatDate('today');\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		interop   [][]lang.Language
+		wantPaths []string
+	}{
+		{name: "默认配置下TS调用解析到JS定义", interop: DefaultInteropLanguages,
+			wantPaths: []string{filepath.Join(workspaceDir, "src", "format.js")}},
+		{name: "关闭互操作后各语言符号互不可见", interop: [][]lang.Language{}},
+		{name: "配置中不包含该语言对", interop: parseInteropLanguages("java:kotlin")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.config.InteropLanguages = tt.interop
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace: workspaceDir,
+				FilePath:  filepath.Join(workspaceDir, "src", "main.ts"),
+				StartLine: 4,
+				EndLine:   4,
+			})
+			require.NoError(t, err)
+			var paths []string
+			for _, d := range definitions {
+				if d.Name == "formatDate" {
+					paths = append(paths, d.Path)
+				}
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
+
+func TestGetFileOutline(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	source := `package main
+
+import (
+	"fmt"
+
+	"example.com/outline/util"
+)
+
+type Greeter struct{}
+
+func (g *Greeter) Greet(name string) {
+	fmt.Println(util.Upper(name))
+}
+
+func main() {
+	g := &Greeter{}
+	g.Greet("world")
+}
+`
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, os.WriteFile(mainFile, []byte(source), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, "go.mod"), []byte("module example.com/outline\n"), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	t.Run("已索引文件", func(t *testing.T) {
+		outline, err := idx.GetFileOutline(ctx, workspaceDir, mainFile)
+		require.NoError(t, err)
+		assert.Equal(t, mainFile, outline.Path)
+		assert.Equal(t, string(lang.Go), outline.Language)
+		require.NotNil(t, outline.Package)
+		assert.Equal(t, "main", outline.Package.Name)
+		var imports []string
+		for _, imp := range outline.Imports {
+			imports = append(imports, imp.Name)
+		}
+		assert.Equal(t, []string{"util"}, imports)
+
+		var definitions []string
+		for _, d := range outline.Definitions {
+			definitions = append(definitions, d.Name)
+		}
+		assert.Equal(t, []string{"Greeter", "Greet", "main", "g"}, definitions)
+		assert.Equal(t, 9, outline.Definitions[0].Position.StartLine)
+		assert.Equal(t, string(types.ElementTypeMethod), outline.Definitions[1].Type)
+
+		var calls []string
+		for _, c := range outline.Calls {
+			calls = append(calls, c.Name)
+			assert.Equal(t, string(types.ElementTypeMethodCall), c.Type)
+		}
+		assert.Contains(t, calls, "Println")
+		assert.Contains(t, calls, "Greet")
+		assert.Contains(t, calls, "Upper")
+		for i := 1; i < len(outline.Calls); i++ {
+			assert.LessOrEqual(t, outline.Calls[i-1].Position.StartLine, outline.Calls[i].Position.StartLine)
+		}
+	})
+
+	t.Run("未索引文件", func(t *testing.T) {
+		newFile := filepath.Join(workspaceDir, "new.go")
+		require.NoError(t, os.WriteFile(newFile, []byte("package main\n"), 0644))
+		_, err := idx.GetFileOutline(ctx, workspaceDir, newFile)
+		assert.ErrorIs(t, err, errs.ErrFileNotIndexed)
+	})
+}
+
+func TestQueryReferencesSameFileOnly(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	mainPath := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, os.WriteFile(mainPath,
+		[]byte("package main\n\nfunc Target() {}\n\nfunc Caller() {\n\tTarget()\n\tTarget()\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, "other.go"),
+		[]byte("package main\n\nfunc Other() {\n\tTarget()\n}\n"), 0644))
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	counting := &iterCountingStorage{GraphStorage: storage}
+	idx.storage = counting
+
+	referenceLines := func(nodes []*types.RelationNode) map[string][]int {
+		lines := make(map[string][]int)
+		require.Len(t, nodes, 1)
+		for _, ref := range nodes[0].Children {
+			lines[filepath.Base(ref.FilePath)] = append(lines[filepath.Base(ref.FilePath)], ref.Position.StartLine)
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name      string
+		opts      *types.QueryReferenceOptions
+		want      map[string][]int
+		wantIters int
+		wantErr   bool
+	}{
+		{
+			name:      "遍历整个项目",
+			opts:      &types.QueryReferenceOptions{FilePath: mainPath, SymbolName: "Target"},
+			want:      map[string][]int{"main.go": {6, 7}, "other.go": {4}},
+			wantIters: 1,
+		},
+		{
+			name:      "只查当前文件",
+			opts:      &types.QueryReferenceOptions{FilePath: mainPath, SymbolName: "Target", SameFileOnly: true},
+			want:      map[string][]int{"main.go": {6, 7}},
+			wantIters: 0,
+		},
+		{
+			name:      "只查当前文件时按行定位",
+			opts:      &types.QueryReferenceOptions{FilePath: mainPath, StartLine: 3, EndLine: 3, SameFileOnly: true},
+			want:      map[string][]int{"main.go": {6, 7}},
+			wantIters: 0,
+		},
+		{
+			name:    "只查当前文件时缺少文件路径",
+			opts:    &types.QueryReferenceOptions{SymbolName: "Target", SameFileOnly: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counting.iters = 0
+			tt.opts.Workspace = workspaceDir
+			nodes, err := idx.QueryReferences(ctx, tt.opts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, referenceLines(nodes))
+			assert.Equal(t, tt.wantIters, counting.iters)
+		})
+	}
+}
+
+func TestQueryReferencesPage(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	mainPath := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, os.WriteFile(mainPath,
+		[]byte("package main\n\nfunc Target() {}\n\nfunc main() {\n\tTarget()\n}\n"), 0644))
+	for _, name := range []string{"a.go", "b.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name),
+			[]byte("package main\n\nfunc caller_"+strings.TrimSuffix(name, ".go")+"() {\n\tTarget()\n\tTarget()\n}\n"), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	type ref struct {
+		file string
+		line int
+	}
+	queryPage := func(offset, limit int) ([]ref, int) {
+		page, err := idx.QueryReferencesPage(ctx, &types.QueryReferenceOptions{
+			Workspace: workspaceDir, FilePath: mainPath, SymbolName: "Target", Offset: offset, Limit: limit,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Definitions, 1)
+		var refs []ref
+		for _, child := range page.Definitions[0].Children {
+			refs = append(refs, ref{filepath.Base(child.FilePath), child.Position.StartLine})
+		}
+		return refs, page.Total
+	}
+
+	full, total := queryPage(0, 0)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []ref{{"a.go", 4}, {"a.go", 5}, {"b.go", 4}, {"b.go", 5}, {"main.go", 6}}, full)
+
+	tests := []struct {
+		name   string
+		offset int
+		limit  int
+		want   []ref
+	}{
+		{name: "第一页", offset: 0, limit: 2, want: full[0:2]},
+		{name: "中间页", offset: 2, limit: 2, want: full[2:4]},
+		{name: "最后一页不足一页", offset: 4, limit: 2, want: full[4:]},
+		{name: "超出总数", offset: 5, limit: 2, want: nil},
+		{name: "只跳过不限制", offset: 3, limit: 0, want: full[3:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, total := queryPage(tt.offset, tt.limit)
+			assert.Equal(t, tt.want, refs)
+			assert.Equal(t, 5, total)
+		})
+	}
+
+	// 逐页拼接等于完整结果
+	var concatenated []ref
+	for offset := 0; offset < total; offset += 2 {
+		refs, _ := queryPage(offset, 2)
+		concatenated = append(concatenated, refs...)
+	}
+	assert.Equal(t, full, concatenated)
+
+	_, err = idx.QueryReferencesPage(ctx, &types.QueryReferenceOptions{
+		Workspace: workspaceDir, FilePath: mainPath, SymbolName: "Target", Offset: -1,
+	})
+	assert.Error(t, err)
+}
+
+func TestQueryDefinitionsLocalOnly(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	mainPath := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, os.WriteFile(mainPath,
+		[]byte("package main\n\nfunc local() {}\n\nfunc main() {\n\tlocal()\n\tRemote()\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, "util.go"),
+		[]byte("package main\n\nfunc Remote() {}\n"), 0644))
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		localOnly bool
+		want      []string
+	}{
+		{name: "默认包含其他文件的定义", localOnly: false, want: []string{"main.go:local", "util.go:Remote"}},
+		{name: "只返回当前文件的定义", localOnly: true, want: []string{"main.go:local"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace: workspaceDir,
+				FilePath:  mainPath,
+				StartLine: 6,
+				EndLine:   7,
+				LocalOnly: tt.localOnly,
+			})
+			require.NoError(t, err)
+			var got []string
+			for _, d := range definitions {
+				got = append(got, filepath.Base(d.Path)+":"+d.Name)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueryDefinitionsBySnippetResolution(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.22\n",
+		"lib/lib.go":     "package lib\n\nfunc Run() {}\n",
+		"util.go":        "package main\n\nfunc Local() {}\n",
+		"other/local.go": "package other\n\nfunc Local() {}\n",
+		"main.go":        "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	// Local 在导入的包中没有定义
+	snippet := []byte("import (\n\t\"example.com/app/lib\"\n)\n\nfunc run() {\n\tlib.Run()\n\tLocal()\n}\n")
+	tests := []struct {
+		name       string
+		resolution types.DefinitionResolution
+		wantPaths  []string
+	}{
+		{name: "默认导入都不匹配时不返回"},
+		{name: "strict导入都不匹配时不返回", resolution: types.DefinitionResolutionStrict},
+		{name: "relaxed导入都不匹配时返回全部", resolution: types.DefinitionResolutionRelaxed,
+			wantPaths: []string{"other/local.go", "util.go"}},
+		{name: "scoped优先同目录", resolution: types.DefinitionResolutionScoped, wantPaths: []string{"util.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace:   workspaceDir,
+				FilePath:    filepath.Join(workspaceDir, "main.go"),
+				CodeSnippet: snippet,
+				Resolution:  tt.resolution,
+			})
+			require.NoError(t, err)
+			var paths []string
+			for _, d := range definitions {
+				if d.Name != "Local" {
+					continue
+				}
+				rel, err := filepath.Rel(workspaceDir, d.Path)
+				require.NoError(t, err)
+				paths = append(paths, filepath.ToSlash(rel))
+			}
+			sort.Strings(paths)
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
+
+func TestQueryDefinitionsRankingSameForSnippetAndLineRange(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	mainContent := "package main\n\nimport (\n\t\"example.com/app/lib\"\n)\n\nfunc main() {\n\tlib.Helper()\n}\n"
+	// 项目位于工作区的子目录，导入的包中有两个同名定义
+	projectDir := filepath.Join(workspaceDir, "app")
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".git"), 0755))
+	files := map[string]string{
+		"go.mod":             "module example.com/app\n\ngo 1.22\n",
+		"main.go":            mainContent,
+		"lib/helper.go":      "package lib\n\nfunc Helper() {}\n",
+		"lib/main_helper.go": "package lib\n\nfunc Helper() {}\n",
+		"other/main.go":      "package other\n\nfunc Helper() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	mainFile := filepath.Join(projectDir, "main.go")
+	helperPaths := func(opts *types.QueryDefinitionOptions) []string {
+		definitions, err := idx.QueryDefinitions(ctx, opts)
+		require.NoError(t, err)
+		var paths []string
+		for _, d := range definitions {
+			if d.Name == "Helper" {
+				rel, err := filepath.Rel(projectDir, d.Path)
+				require.NoError(t, err)
+				paths = append(paths, filepath.ToSlash(rel))
+			}
+		}
+		return paths
+	}
+	byLineRange := helperPaths(&types.QueryDefinitionOptions{
+		Workspace: workspaceDir,
+		FilePath:  mainFile,
+		StartLine: 8,
+		EndLine:   8,
+	})
+	bySnippet := helperPaths(&types.QueryDefinitionOptions{
+		Workspace:   workspaceDir,
+		FilePath:    mainFile,
+		CodeSnippet: []byte(mainContent),
+	})
+	assert.ElementsMatch(t, []string{"lib/main_helper.go", "lib/helper.go"}, byLineRange)
+	assert.Equal(t, byLineRange, bySnippet)
+}
+
+func TestQueryDefinitionsThroughGoEmbedding(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"storage/store.go": `package storage
+
+type Store struct{}
+
+func (s *Store) Save(key string) error {
+	return nil
+}
+`,
+		"audit/logger.go": `package audit
+
+type Logger struct{}
+
+func (l *Logger) Flush() {}
+`,
+		"service/order.go": `package service
+
+import (
+	"example.com/shop/storage"
+)
+
+type OrderService struct {
+	*storage.Store
+	name string
+}
+
+type Repo = storage.Store
+`,
+		"main.go": `package main
+
+import (
+	"example.com/shop/service"
+)
+
+func run(s *service.OrderService) {
+	_ = s.Save("order")
+}
+
+func use(r *service.Repo) {
+	_ = r.Save("repo")
+}
+
+func flush(l interface{ Flush() }) {
+	l.Flush()
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	metrics, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	require.Zero(t, metrics.TotalFailedFiles)
+
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	storeFile := filepath.Join(workspaceDir, "storage", "store.go")
+	tests := []struct {
+		name      string
+		opts      *types.QueryDefinitionOptions
+		wantPaths map[string]string
+	}{
+		{
+			name:      "嵌入结构体的方法",
+			opts:      &types.QueryDefinitionOptions{StartLine: 8, EndLine: 8},
+			wantPaths: map[string]string{"Save": storeFile},
+		},
+		{
+			name:      "类型别名的方法",
+			opts:      &types.QueryDefinitionOptions{StartLine: 12, EndLine: 12},
+			wantPaths: map[string]string{"Save": storeFile},
+		},
+		{
+			name:      "没有嵌入关系的包不展开",
+			opts:      &types.QueryDefinitionOptions{StartLine: 16, EndLine: 16},
+			wantPaths: map[string]string{},
+		},
+		{
+			name: "代码片段中的嵌入方法",
+			opts: &types.QueryDefinitionOptions{CodeSnippet: []byte(
+				"import (\n\t\"example.com/shop/service\"\n)\n\nfunc run(s *service.OrderService) {\n\t_ = s.Save(\"order\")\n}\n")},
+			wantPaths: map[string]string{"Save": storeFile},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Workspace = workspaceDir
+			tt.opts.FilePath = mainFile
+			tt.opts.Resolution = types.DefinitionResolutionStrict
+			definitions, err := idx.QueryDefinitions(ctx, tt.opts)
+			require.NoError(t, err)
+			got := make(map[string]string)
+			for _, d := range definitions {
+				if d.Name == "Save" || d.Name == "Flush" {
+					got[d.Name] = d.Path
+				}
+			}
+			assert.Equal(t, tt.wantPaths, got)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"fmt"
+)
+
+// ReconcileCodegraphFileNum 以 LevelDB 中的文件元素表数量为准，核对 SQLite 记录的 codegraph_file_num。
+// 崩溃或部分操作失败后两者可能不一致，fix 为 true 时修正 SQLite 中的值，否则只返回核对结果。
+// 核对持有工作区锁，进行中的索引完成后才统计，避免以未完成的文件数覆盖索引进度
+func (idx *Indexer) ReconcileCodegraphFileNum(ctx context.Context, workspacePath string, fix bool) (*types.CodegraphReconcileResult, error) {
+	defer idx.lockWorkspace(workspacePath)()
+	workspaceModel, err := idx.workspaceRepository.GetWorkspaceByPath(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	if workspaceModel == nil {
+		return nil, fmt.Errorf("workspace %s not found in database", workspacePath)
+	}
+
+	indexFileNum := 0
+	projects := idx.workspaceReader.FindProjects(ctx, workspacePath, false, workspace.DefaultVisitPattern)
+	for _, p := range projects {
+		indexFileNum += idx.storage.Size(ctx, p.Uuid, store.PathKeySystemPrefix)
+	}
+
+	result := &types.CodegraphReconcileResult{
+		WorkspacePath:   workspacePath,
+		DatabaseFileNum: workspaceModel.CodegraphFileNum,
+		IndexFileNum:    indexFileNum,
+	}
+	if result.DatabaseFileNum == result.IndexFileNum {
+		return result, nil
+	}
+
+	idx.logger.Warn("workspace %s codegraph file num out of sync, database %d, index %d",
+		workspacePath, result.DatabaseFileNum, result.IndexFileNum)
+	if !fix {
+		return result, nil
+	}
+	// 保留原有的构建时间，只修正文件数
+	if err = idx.workspaceRepository.UpdateCodegraphInfo(workspacePath, indexFileNum, workspaceModel.CodegraphTs); err != nil {
+		return result, err
+	}
+	result.Corrected = true
+	idx.logger.Info("workspace %s codegraph file num corrected from %d to %d",
+		workspacePath, result.DatabaseFileNum, result.IndexFileNum)
+	return result, nil
+}
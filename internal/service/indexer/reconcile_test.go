@@ -0,0 +1,130 @@
+package indexer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/database"
+	"codebase-indexer/internal/model"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWorkspaceRepository 为索引器创建基于临时 SQLite 的工作区仓库
+func newTestWorkspaceRepository(t *testing.T, idx *Indexer) repository.WorkspaceRepository {
+	t.Helper()
+	dbManager := database.NewSQLiteManager(&config.DatabaseConfig{
+		DataDir:         t.TempDir(),
+		DatabaseName:    "test-indexer.db",
+		MaxOpenConns:    1,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: time.Minute,
+	}, idx.logger)
+	require.NoError(t, dbManager.Initialize())
+	t.Cleanup(func() { _ = dbManager.Close() })
+	workspaceRepo := repository.NewWorkspaceRepository(dbManager, idx.logger)
+	idx.workspaceRepository = workspaceRepo
+	return workspaceRepo
+}
+
+func TestReconcileCodegraphFileNum(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceRepo := newTestWorkspaceRepository(t, idx)
+
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filepath.Join(workspaceDir, name),
+			"package main\n", []*codegraphpb.Element{})
+	}
+
+	// 故意制造不一致：SQLite 记录 10 个文件，LevelDB 实际只有 3 个
+	require.NoError(t, workspaceRepo.CreateWorkspace(&model.Workspace{
+		WorkspaceName:    "reconcile",
+		WorkspacePath:    workspaceDir,
+		Active:           "true",
+		CodegraphFileNum: 10,
+		CodegraphTs:      12345,
+	}))
+
+	tests := []struct {
+		name          string
+		fix           bool
+		wantCorrected bool
+		wantDbFileNum int
+	}{
+		{name: "只核对不修正", fix: false, wantCorrected: false, wantDbFileNum: 10},
+		{name: "修正为LevelDB中的数量", fix: true, wantCorrected: true, wantDbFileNum: 3},
+		{name: "已一致时无需修正", fix: true, wantCorrected: false, wantDbFileNum: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.ReconcileCodegraphFileNum(ctx, workspaceDir, tt.fix)
+			require.NoError(t, err)
+			assert.Equal(t, 3, result.IndexFileNum)
+			assert.Equal(t, tt.wantCorrected, result.Corrected)
+
+			ws, err := workspaceRepo.GetWorkspaceByPath(workspaceDir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDbFileNum, ws.CodegraphFileNum)
+			assert.Equal(t, int64(12345), ws.CodegraphTs)
+		})
+	}
+
+	_, err := idx.ReconcileCodegraphFileNum(ctx, filepath.Join(workspaceDir, "missing"), true)
+	assert.Error(t, err)
+}
+
+func TestReconcileCodegraphFileNum_WaitsForIndexing(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceRepo := newTestWorkspaceRepository(t, idx)
+
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	require.NoError(t, workspaceRepo.CreateWorkspace(&model.Workspace{
+		WorkspaceName: "reconcile",
+		WorkspacePath: workspaceDir,
+		Active:        "true",
+	}))
+
+	// 模拟进行中的索引：已写入部分文件，尚未更新数据库记录的文件数
+	unlock := idx.lockWorkspace(workspaceDir)
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filepath.Join(workspaceDir, "a.go"),
+		"package main\n", []*codegraphpb.Element{})
+
+	done := make(chan struct{})
+	var corrected bool
+	go func() {
+		defer close(done)
+		result, err := idx.ReconcileCodegraphFileNum(ctx, workspaceDir, true)
+		assert.NoError(t, err)
+		corrected = result != nil && result.Corrected
+	}()
+	select {
+	case <-done:
+		t.Fatal("reconcile should wait for the running index")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filepath.Join(workspaceDir, "b.go"),
+		"package main\n", []*codegraphpb.Element{})
+	require.NoError(t, workspaceRepo.UpdateCodegraphInfo(workspaceDir, 2, 12345))
+	unlock()
+	<-done
+
+	// 索引完成后核对，数量一致无需修正
+	assert.False(t, corrected)
+	ws, err := workspaceRepo.GetWorkspaceByPath(workspaceDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, ws.CodegraphFileNum)
+}
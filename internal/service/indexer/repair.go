@@ -0,0 +1,114 @@
+package indexer
+
+import (
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrFileIndexStale 文件索引数据损坏，已被清理，重新索引该文件后可恢复查询
+var ErrFileIndexStale = errors.New("file index is stale, reindex required")
+
+// unmarshalFileElementTable 反序列化文件元素表。数据损坏时清理该文件的索引，返回 ErrFileIndexStale，
+// 下次索引时该文件会因索引不存在而重新解析
+func (idx *Indexer) unmarshalFileElementTable(ctx context.Context, projectUuid string, language lang.Language,
+	filePath string, value []byte) (*codegraphpb.FileElementTable, error) {
+	var fileElementTable codegraphpb.FileElementTable
+	err := store.UnmarshalValue(value, &fileElementTable)
+	if err == nil {
+		return &fileElementTable, nil
+	}
+	idx.logger.Warn("project %s file %s index corrupted, remove it for reindex, err: %v", projectUuid, filePath, err)
+	if repairErr := idx.repairCorruptedFileIndex(ctx, projectUuid, language, filePath); repairErr != nil {
+		idx.logger.Error("repair project %s file %s corrupted index err: %v", projectUuid, filePath, repairErr)
+	}
+	return nil, fmt.Errorf("%w: file %s, unmarshal err: %v", ErrFileIndexStale, filePath, err)
+}
+
+// repairCorruptedFileIndex 删除损坏的文件元素表，并扣减所属工作区记录的索引文件数。元素表无法解析出定义的符号名，
+// 因此遍历同语言的符号出现，清理指向该文件的记录
+func (idx *Indexer) repairCorruptedFileIndex(ctx context.Context, projectUuid string, language lang.Language, filePath string) error {
+	if err := idx.storage.Delete(ctx, projectUuid, store.ElementPathKey{Language: language, Path: filePath}); err != nil {
+		return fmt.Errorf("delete element table err: %w", err)
+	}
+	var errs []error
+	if err := idx.decreaseCodegraphFileNum(filePath, 1); err != nil {
+		errs = append(errs, err)
+	}
+
+	// 只遍历该语言的符号 key，@sym:<language>:<name>
+	iter := idx.storage.IterPrefix(ctx, projectUuid, fmt.Sprintf("%s:%s:", store.SymKeySystemPrefix, language))
+	var updated []*store.Entry
+	var emptied []store.SymbolNameKey
+	for iter.Next() {
+		key, err := store.ToSymbolNameKey(iter.Key())
+		if err != nil || key.Language != language {
+			continue
+		}
+		var symbol codegraphpb.SymbolOccurrence
+		if err = store.UnmarshalValue(iter.Value(), &symbol); err != nil {
+			continue
+		}
+		kept := make([]*codegraphpb.Occurrence, 0, len(symbol.Occurrences))
+		for _, o := range symbol.Occurrences {
+			if !utils.PathEqual(o.Path, filePath) {
+				kept = append(kept, o)
+			}
+		}
+		if len(kept) == len(symbol.Occurrences) {
+			continue
+		}
+		if len(kept) == 0 {
+			emptied = append(emptied, key)
+			continue
+		}
+		symbol.Occurrences = kept
+		updated = append(updated, &store.Entry{Key: key, Value: &symbol})
+	}
+	if err := iter.Close(); err != nil {
+		idx.logger.Error("project %s iter close err: %v", projectUuid, err)
+	}
+
+	for _, key := range emptied {
+		if err := idx.storage.Delete(ctx, projectUuid, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, entry := range updated {
+		if err := idx.storage.Put(ctx, projectUuid, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// decreaseCodegraphFileNum 扣减文件所属工作区记录的索引文件数，多个工作区匹配时取路径最长的
+func (idx *Indexer) decreaseCodegraphFileNum(filePath string, removed int) error {
+	workspaces, err := idx.workspaceRepository.ListWorkspaces()
+	if err != nil {
+		return fmt.Errorf("list workspaces err: %w", err)
+	}
+	var found *model.Workspace
+	for _, w := range workspaces {
+		if !utils.IsSubdir(w.WorkspacePath, filePath) {
+			continue
+		}
+		if found == nil || len(w.WorkspacePath) > len(found.WorkspacePath) {
+			found = w
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	if err = idx.workspaceRepository.UpdateCodegraphInfo(found.WorkspacePath,
+		max(found.CodegraphFileNum-removed, 0), time.Now().Unix()); err != nil {
+		return fmt.Errorf("update codegraph info err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRepairCorruptedFileElementTable(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	corruptFile := filepath.Join(workspaceDir, "corrupt.go")
+	require.NoError(t, os.WriteFile(mainFile, []byte("package main\n\nfunc Hello() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(corruptFile, []byte("package main\n\nfunc Only() {}\n"), 0644))
+
+	idx, storage := newTestIndexingIndexer(t, workspaceDir)
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+	onlyKey := store.SymbolNameKey{Language: lang.Go, Name: "Only"}
+	exists, err := storage.Exists(ctx, project.Uuid, onlyKey)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// 写入无法反序列化的元素表（path 字段为非法 UTF-8）
+	corruptKey := store.ElementPathKey{Language: lang.Go, Path: corruptFile}
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key:   corruptKey,
+		Value: wrapperspb.Bytes([]byte{0xff, 0xfe, 0xfd}),
+	}))
+
+	t.Run("查询时清理损坏数据并返回需要重新索引", func(t *testing.T) {
+		_, err := idx.getFileElementTableByPath(ctx, project.Uuid, corruptFile)
+		assert.ErrorIs(t, err, ErrFileIndexStale)
+		// 记录的索引文件数与剩余的文件元素表一致
+		result, err := idx.ReconcileCodegraphFileNum(ctx, workspaceDir, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.IndexFileNum)
+		assert.Equal(t, result.IndexFileNum, result.DatabaseFileNum)
+
+		exists, err := storage.Exists(ctx, project.Uuid, corruptKey)
+		require.NoError(t, err)
+		assert.False(t, exists)
+		// 只出现在损坏文件中的符号被清理，其他文件的索引不受影响
+		exists, err = storage.Exists(ctx, project.Uuid, onlyKey)
+		require.NoError(t, err)
+		assert.False(t, exists)
+		table, err := idx.getFileElementTableByPath(ctx, project.Uuid, mainFile)
+		require.NoError(t, err)
+		assert.Equal(t, mainFile, table.Path)
+	})
+
+	t.Run("重新索引后恢复", func(t *testing.T) {
+		_, err := idx.IndexWorkspace(ctx, workspaceDir)
+		require.NoError(t, err)
+		table, err := idx.getFileElementTableByPath(ctx, project.Uuid, corruptFile)
+		require.NoError(t, err)
+		assert.Equal(t, corruptFile, table.Path)
+		exists, err := storage.Exists(ctx, project.Uuid, onlyKey)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
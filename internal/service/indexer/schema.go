@@ -0,0 +1,194 @@
+package indexer
+
+import (
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ErrIndexSchemaOutdated 项目索引 schema 版本与当前不一致且无法原地迁移，需要重建索引后才能查询
+var ErrIndexSchemaOutdated = errors.New("project index schema outdated, rebuild required")
+
+// SchemaMigration 将项目索引从 From 版本原地升级到 To 版本
+type SchemaMigration struct {
+	From    string
+	To      string
+	Migrate func(ctx context.Context, storage store.GraphStorage, projectUuid string) error
+}
+
+var (
+	schemaMigrationsMu sync.RWMutex
+	// from 版本 -> 迁移
+	schemaMigrations = make(map[string]*SchemaMigration)
+)
+
+func init() {
+	// 版本 2 起索引中的路径统一为 / 分隔符。系统分隔符为 / 时已有索引无需改动，
+	// 否则已有 key 中的 \ 无法原地改写，清空后重建
+	RegisterSchemaMigration(&SchemaMigration{From: "1", To: "2",
+		Migrate: func(ctx context.Context, storage store.GraphStorage, projectUuid string) error {
+			if filepath.Separator == '/' {
+				return nil
+			}
+			return fmt.Errorf("element path keys use system separator %q", filepath.Separator)
+		}})
+}
+
+// RegisterSchemaMigration 注册 schema 迁移，同一 From 版本重复注册时覆盖。
+// 未注册迁移路径的旧版本索引会被清空后全量重建
+func RegisterSchemaMigration(migration *SchemaMigration) {
+	schemaMigrationsMu.Lock()
+	defer schemaMigrationsMu.Unlock()
+	schemaMigrations[migration.From] = migration
+}
+
+// unregisterSchemaMigration 移除 From 版本的迁移
+func unregisterSchemaMigration(from string) {
+	schemaMigrationsMu.Lock()
+	defer schemaMigrationsMu.Unlock()
+	delete(schemaMigrations, from)
+}
+
+// findSchemaMigrationPath 查找从 version 升级到当前版本的迁移链，不存在时返回 false。
+// 比当前版本新的索引（二进制降级）无法迁移
+func findSchemaMigrationPath(version string) ([]*SchemaMigration, bool) {
+	schemaMigrationsMu.RLock()
+	defer schemaMigrationsMu.RUnlock()
+	var path []*SchemaMigration
+	visited := make(map[string]bool)
+	for version != store.CurrentSchemaVersion {
+		migration, ok := schemaMigrations[version]
+		if !ok || visited[version] {
+			return nil, false
+		}
+		visited[version] = true
+		path = append(path, migration)
+		version = migration.To
+	}
+	return path, true
+}
+
+// schemaVersionNumber 将 schema 版本转为数字用于比较，未记录版本（旧版本索引）视为 0
+func schemaVersionNumber(version string) int {
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// getSchemaVersion 获取项目索引的 schema 版本，未记录时返回空串
+func (idx *Indexer) getSchemaVersion(ctx context.Context, projectUuid string) (string, error) {
+	bytes, err := idx.storage.Get(ctx, projectUuid, store.ProjectMetaKey{MetaType: store.MetaTypeSchemaVersion})
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return types.EmptyString, nil
+	}
+	if err != nil {
+		return types.EmptyString, err
+	}
+	var version wrapperspb.StringValue
+	if err = store.UnmarshalValue(bytes, &version); err != nil {
+		return types.EmptyString, err
+	}
+	return version.GetValue(), nil
+}
+
+// saveSchemaVersion 记录项目索引为当前 schema 版本
+func (idx *Indexer) saveSchemaVersion(ctx context.Context, projectUuid string) error {
+	return idx.storage.Put(ctx, projectUuid, &store.Entry{
+		Key:   store.ProjectMetaKey{MetaType: store.MetaTypeSchemaVersion},
+		Value: wrapperspb.String(store.CurrentSchemaVersion),
+	})
+}
+
+// isSchemaOutdated 项目已有索引且 schema 版本与当前不一致（含旧版本未记录版本号的索引）时返回 true
+func (idx *Indexer) isSchemaOutdated(ctx context.Context, projectUuid string) bool {
+	if idx.storage.Size(ctx, projectUuid, store.PathKeySystemPrefix) == 0 {
+		return false
+	}
+	version, err := idx.getSchemaVersion(ctx, projectUuid)
+	if err != nil {
+		idx.logger.Warn("get project %s schema version err: %v, treat as outdated", projectUuid, err)
+		return true
+	}
+	return version != store.CurrentSchemaVersion
+}
+
+// upgradeProjectSchema 按注册的迁移链将项目索引原地升级到当前版本，成功后记录当前版本
+func (idx *Indexer) upgradeProjectSchema(ctx context.Context, projectUuid, version string) error {
+	if schemaVersionNumber(version) > schemaVersionNumber(store.CurrentSchemaVersion) {
+		return fmt.Errorf("project %s index schema version %q is newer than current %q",
+			projectUuid, version, store.CurrentSchemaVersion)
+	}
+	path, ok := findSchemaMigrationPath(version)
+	if !ok {
+		return fmt.Errorf("no schema migration registered from version %q to %q", version, store.CurrentSchemaVersion)
+	}
+	for _, migration := range path {
+		start := time.Now()
+		if err := migration.Migrate(ctx, idx.storage, projectUuid); err != nil {
+			return fmt.Errorf("migrate project %s index schema from %q to %q err: %w",
+				projectUuid, migration.From, migration.To, err)
+		}
+		idx.logger.Info("migrated project %s index schema from %q to %q, cost %d ms",
+			projectUuid, migration.From, migration.To, time.Since(start).Milliseconds())
+	}
+	return idx.saveSchemaVersion(ctx, projectUuid)
+}
+
+// checkProjectSchema 查询前校验项目索引的 schema 版本，不一致时尝试原地迁移，
+// 无法迁移时返回 ErrIndexSchemaOutdated，等待下次索引时重建。
+// 版本在索引完成时才记录，未记录版本视为索引进行中，允许查询；旧版本未记录版本号的索引在下次索引时重建
+func (idx *Indexer) checkProjectSchema(ctx context.Context, projectUuid string) error {
+	version, err := idx.getSchemaVersion(ctx, projectUuid)
+	if err != nil {
+		return fmt.Errorf("get project %s schema version err: %w", projectUuid, err)
+	}
+	if version == store.CurrentSchemaVersion || version == types.EmptyString {
+		return nil
+	}
+	if err = idx.upgradeProjectSchema(ctx, projectUuid, version); err != nil {
+		idx.logger.Warn("project %s index schema version %q is not compatible: %v", projectUuid, version, err)
+		return fmt.Errorf("%w: project %s", ErrIndexSchemaOutdated, projectUuid)
+	}
+	return nil
+}
+
+// migrateProjectSchema schema 版本不一致时优先按注册的迁移原地升级，
+// 无法迁移时清空项目索引，由调用方全量重建，避免读取不兼容的数据
+func (idx *Indexer) migrateProjectSchema(ctx context.Context, workspacePath string, project *workspace.Project) error {
+	if !idx.isSchemaOutdated(ctx, project.Uuid) {
+		return nil
+	}
+	version, _ := idx.getSchemaVersion(ctx, project.Uuid)
+	err := idx.upgradeProjectSchema(ctx, project.Uuid, version)
+	if err == nil {
+		return nil
+	}
+	idx.logger.Info("project %s index cannot be migrated in place: %v", project.Path, err)
+	removed := idx.storage.Size(ctx, project.Uuid, store.PathKeySystemPrefix)
+	idx.logger.Info("project %s index schema version %q differs from current %q, start to migrate: "+
+		"drop %d file indexes and rebuild project", project.Path, version, store.CurrentSchemaVersion, removed)
+
+	if err := idx.storage.DeleteAll(ctx, project.Uuid); err != nil {
+		return fmt.Errorf("delete project %s outdated indexes err: %w", project.Path, err)
+	}
+	// 扣除已删除的文件数，重建时重新累加
+	workspaceModel, err := idx.workspaceRepository.GetWorkspaceByPath(workspacePath)
+	if err == nil && workspaceModel != nil {
+		fileNum := max(workspaceModel.CodegraphFileNum-removed, 0)
+		if err = idx.workspaceRepository.UpdateCodegraphInfo(workspacePath, fileNum, time.Now().Unix()); err != nil {
+			idx.logger.Error("update workspace %s codegraph info after migration err: %v", workspacePath, err)
+		}
+	}
+	return nil
+}
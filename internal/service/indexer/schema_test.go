@@ -0,0 +1,152 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/internal/model"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestIsSchemaOutdated(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	// 没有索引时无需迁移
+	assert.False(t, idx.isSchemaOutdated(ctx, project.Uuid))
+
+	// 旧版本没有记录 schema 版本
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, filepath.Join(workspaceDir, "main.go"),
+		"package main\n", []*codegraphpb.Element{})
+	require.NoError(t, storage.Delete(ctx, project.Uuid, store.ProjectMetaKey{MetaType: store.MetaTypeSchemaVersion}))
+	assert.True(t, idx.isSchemaOutdated(ctx, project.Uuid))
+
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key:   store.ProjectMetaKey{MetaType: store.MetaTypeSchemaVersion},
+		Value: wrapperspb.String("0"),
+	}))
+	assert.True(t, idx.isSchemaOutdated(ctx, project.Uuid))
+
+	require.NoError(t, idx.saveSchemaVersion(ctx, project.Uuid))
+	version, err := idx.getSchemaVersion(ctx, project.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, store.CurrentSchemaVersion, version)
+	assert.False(t, idx.isSchemaOutdated(ctx, project.Uuid))
+}
+
+func TestCheckProjectSchemaIndexInProgress(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, mainFile, "package main\n\nfunc main() {\n}\n",
+		[]*codegraphpb.Element{
+			{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 3, 1}},
+		})
+
+	// 索引尚未完成，没有记录 schema 版本
+	version, err := idx.getSchemaVersion(ctx, project.Uuid)
+	require.NoError(t, err)
+	assert.Empty(t, version)
+	assert.NoError(t, idx.checkProjectSchema(ctx, project.Uuid))
+	_, err = idx.QueryReferences(ctx, &types.QueryReferenceOptions{Workspace: workspaceDir, FilePath: mainFile, SymbolName: "main"})
+	assert.NoError(t, err)
+}
+
+// saveTestSchemaVersion 将项目索引的 schema 版本改写为 version，模拟旧版本构建的索引
+func saveTestSchemaVersion(t *testing.T, storage store.GraphStorage, projectUuid, version string) {
+	t.Helper()
+	require.NoError(t, storage.Put(context.Background(), projectUuid, &store.Entry{
+		Key:   store.ProjectMetaKey{MetaType: store.MetaTypeSchemaVersion},
+		Value: wrapperspb.String(version),
+	}))
+}
+
+func TestMigrateProjectSchema(t *testing.T) {
+	ctx := context.Background()
+
+	// 模拟从版本 "0" 升级的迁移：为旧索引补写项目路径元数据
+	migrated := func(ctx context.Context, storage store.GraphStorage, projectUuid string) error {
+		return storage.Put(ctx, projectUuid, &store.Entry{
+			Key:   store.ProjectMetaKey{MetaType: store.MetaTypeProjectPath},
+			Value: wrapperspb.String("migrated"),
+		})
+	}
+	failed := func(ctx context.Context, storage store.GraphStorage, projectUuid string) error {
+		return errors.New("migrate failed")
+	}
+
+	tests := []struct {
+		name        string
+		oldVersion  string
+		migrate     func(ctx context.Context, storage store.GraphStorage, projectUuid string) error
+		wantRebuild bool
+	}{
+		{name: "存在迁移路径时原地升级", oldVersion: "0", migrate: migrated, wantRebuild: false},
+		{name: "没有注册迁移时清空重建", oldVersion: "0", wantRebuild: true},
+		{name: "迁移失败时清空重建", oldVersion: "0", migrate: failed, wantRebuild: true},
+		{name: "比当前版本新的索引清空重建", oldVersion: "999", migrate: migrated, wantRebuild: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, storage := newTestIndexerWithStorage(t)
+			workspaceRepo := newTestWorkspaceRepository(t, idx)
+			workspaceDir := t.TempDir()
+			project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+			mainFile := filepath.Join(workspaceDir, "main.go")
+			saveTestFileElementTable(t, storage, project.Uuid, lang.Go, mainFile, "package main\n\nfunc main() {\n}\n",
+				[]*codegraphpb.Element{
+					{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 3, 1}},
+				})
+			saveTestSchemaVersion(t, storage, project.Uuid, tt.oldVersion)
+			require.NoError(t, workspaceRepo.CreateWorkspace(&model.Workspace{
+				WorkspaceName: "schema", WorkspacePath: workspaceDir, Active: "true", CodegraphFileNum: 1,
+			}))
+			if tt.migrate != nil {
+				RegisterSchemaMigration(&SchemaMigration{From: tt.oldVersion, To: store.CurrentSchemaVersion, Migrate: tt.migrate})
+				t.Cleanup(func() { unregisterSchemaMigration(tt.oldVersion) })
+			}
+
+			// 查询前校验版本：能迁移时直接查询，否则提示需要重建
+			_, err := idx.QueryReferences(ctx, &types.QueryReferenceOptions{Workspace: workspaceDir, FilePath: mainFile, SymbolName: "main"})
+			if tt.wantRebuild {
+				assert.ErrorIs(t, err, ErrIndexSchemaOutdated)
+			} else {
+				require.NoError(t, err)
+				version, err := idx.getSchemaVersion(ctx, project.Uuid)
+				require.NoError(t, err)
+				assert.Equal(t, store.CurrentSchemaVersion, version)
+				_, err = storage.Get(ctx, project.Uuid, store.ProjectMetaKey{MetaType: store.MetaTypeProjectPath})
+				assert.NoError(t, err)
+				saveTestSchemaVersion(t, storage, project.Uuid, tt.oldVersion)
+			}
+
+			// 索引构建时的迁移：无法迁移的索引被清空，交由全量重建
+			require.NoError(t, idx.migrateProjectSchema(ctx, workspaceDir, project))
+			remaining := storage.Size(ctx, project.Uuid, store.PathKeySystemPrefix)
+			workspaceModel, err := workspaceRepo.GetWorkspaceByPath(workspaceDir)
+			require.NoError(t, err)
+			if tt.wantRebuild {
+				assert.Equal(t, 0, remaining)
+				assert.Equal(t, 0, workspaceModel.CodegraphFileNum)
+			} else {
+				assert.Equal(t, 1, remaining)
+				assert.Equal(t, 1, workspaceModel.CodegraphFileNum)
+				assert.False(t, idx.isSchemaOutdated(ctx, project.Uuid))
+			}
+		})
+	}
+}
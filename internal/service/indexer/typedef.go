@@ -0,0 +1,176 @@
+package indexer
+
+import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// untypedLanguages 解析器不记录变量、参数类型的语言，无法查询类型定义
+var untypedLanguages = []lang.Language{lang.JavaScript, lang.Ruby}
+
+// typeDefinitionElementTypes 可以作为变量、参数类型的定义
+var typeDefinitionElementTypes = []types.ElementType{
+	types.ElementTypeClass,
+	types.ElementTypeInterface,
+	types.ElementTypeStruct,
+	types.ElementTypeEnum,
+	types.ElementTypeUnion,
+	types.ElementTypeTypedef,
+	types.ElementTypeTrait,
+	types.ElementTypeTypeAlias,
+}
+
+// QueryTypeDefinitions 查询行号范围内变量、参数的声明类型的定义。类型信息不保存在索引中，
+// 查询时重新解析文件；指定 SymbolNames 时只查询这些变量、参数。不记录类型的语言返回不支持的语言错误
+func (idx *Indexer) QueryTypeDefinitions(ctx context.Context, opts *types.QueryDefinitionOptions) ([]*types.Definition, error) {
+	if opts.Workspace == types.EmptyString {
+		return nil, fmt.Errorf("workspace cannot be empty")
+	}
+	opts.Workspace = utils.FileURIToPath(opts.Workspace)
+	filePath, err := utils.NormalizeFilePath(opts.Workspace, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	opts.FilePath = filePath
+	if opts.FilePath == types.EmptyString {
+		return nil, fmt.Errorf("file path cannot be empty")
+	}
+	if !filepath.IsAbs(opts.FilePath) {
+		return nil, fmt.Errorf("param filePath must be absolute path")
+	}
+	if opts.StartLine <= 0 || opts.EndLine <= 0 {
+		return nil, fmt.Errorf("invalid query type definition options: line range must be provided")
+	}
+	language, err := lang.InferLanguage(opts.FilePath)
+	if err != nil || slices.Contains(untypedLanguages, language) {
+		return nil, errs.ErrUnSupportedLanguage
+	}
+	opts.StartLine, opts.EndLine = NormalizeLineRange(opts.StartLine, opts.EndLine, MaxQueryLineLimit)
+
+	project, err := idx.getQueryProject(ctx, opts.Workspace, opts.ProjectUuid, opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	content, err := idx.workspaceReader.ReadFile(ctx, opts.FilePath, types.ReadOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s for type definition query: %w", opts.FilePath, err)
+	}
+	parsedData, err := idx.parser.Parse(ctx, &types.SourceFile{Path: opts.FilePath, Content: content})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file %s for type definition query: %w", opts.FilePath, err)
+	}
+
+	var symbolNames []string
+	for s := range strings.SplitSeq(opts.SymbolNames, ",") {
+		if t := strings.TrimSpace(s); t != types.EmptyString {
+			symbolNames = append(symbolNames, t)
+		}
+	}
+	typeNames := collectDeclaredTypeNames(parsedData.Elements, int32(opts.StartLine-1), int32(opts.EndLine-1), symbolNames)
+	if len(typeNames) == 0 {
+		return nil, nil
+	}
+
+	imports := parsedData.Imports
+	if filteredImps, err := idx.analyzer.PreprocessImports(ctx, language, project, imports); err == nil {
+		imports = filteredImps
+	}
+	var currentImports []*codegraphpb.Import
+	for _, imp := range imports {
+		currentImports = append(currentImports, &codegraphpb.Import{
+			Name:   imp.Name,
+			Alias:  imp.Alias,
+			Source: imp.Source,
+			Range:  imp.Range,
+		})
+	}
+
+	// 不按导入过滤，同包、同文件中的类型不需要导入，由 resolveOccurrences 按解析策略取舍
+	symDefs, err := idx.searchSymbolNames(ctx, project.Uuid, idx.interopLanguages(language), typeNames, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index by names: %w", err)
+	}
+	var results []*types.Definition
+	for _, name := range typeNames {
+		var occurrences []*codegraphpb.Occurrence
+		for _, o := range symDefs[name] {
+			if o != nil && slices.Contains(typeDefinitionElementTypes, proto.ElementTypeFromProto(o.ElementType)) {
+				occurrences = append(occurrences, o)
+			}
+		}
+		if len(occurrences) == 0 {
+			continue
+		}
+		for _, o := range idx.resolveOccurrences(opts.FilePath, currentImports, occurrences, opts.Resolution) {
+			results = append(results, &types.Definition{
+				Name:  name,
+				Type:  string(proto.ElementTypeFromProto(o.ElementType)),
+				Path:  o.Path,
+				Range: o.Range,
+			})
+		}
+	}
+	definitions := idx.rankDefinitions(project.Path, opts.FilePath, currentImports, results, nil)
+	if opts.IncludeDoc {
+		idx.fillDefinitionDocs(ctx, opts.Workspace, opts.ProjectUuid, definitions)
+	}
+	return definitions, nil
+}
+
+// collectDeclaredTypeNames 收集 [startLine, endLine]（从0开始）范围内声明的变量类型，以及范围内开始的函数、方法的参数类型。
+// 指定 names 时只收集这些变量、参数，参数按名称匹配时不要求函数在范围内开始，只要求函数包含该范围
+func collectDeclaredTypeNames(elements []resolver.Element, startLine, endLine int32, names []string) []string {
+	seen := make(map[string]struct{})
+	var typeNames []string
+	add := func(annotations ...string) {
+		for _, typ := range extractTypeNames(annotations...) {
+			if _, ok := seen[typ]; ok {
+				continue
+			}
+			seen[typ] = struct{}{}
+			typeNames = append(typeNames, typ)
+		}
+	}
+	matchName := func(name string) bool {
+		return len(names) == 0 || slices.Contains(names, name)
+	}
+	addParameters := func(r []int32, d *resolver.Declaration) {
+		if d == nil || len(r) < 3 {
+			return
+		}
+		startsInRange := r[0] >= startLine && r[0] <= endLine
+		containsRange := r[0] <= startLine && r[2] >= endLine
+		if !startsInRange && (len(names) == 0 || !containsRange) {
+			return
+		}
+		for _, p := range d.Parameters {
+			if matchName(p.Name) {
+				add(p.Type...)
+			}
+		}
+	}
+	for _, e := range elements {
+		switch v := e.(type) {
+		case *resolver.Variable:
+			if len(v.Range) < 3 || v.Range[0] > endLine || v.Range[2] < startLine || !matchName(v.Name) {
+				continue
+			}
+			add(v.VariableType...)
+		case *resolver.Function:
+			addParameters(v.Range, v.Declaration)
+		case *resolver.Method:
+			addParameters(v.Range, v.Declaration)
+		}
+	}
+	return typeNames
+}
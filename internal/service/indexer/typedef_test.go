@@ -0,0 +1,73 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTypeDefinitions(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	files := map[string]string{
+		"model.go": "package main\n\ntype User struct {\n\tName string\n}\n\ntype Order struct {\n\tId int\n}\n",
+		"main.go": "package main\n\nfunc handle(o *Order, n int) {\n\tvar u User\n\t_ = u\n}\n\n" +
+			"func User2() {}\n",
+		"app.js": "function f(a) {\n  let b = a;\n}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, name), []byte(content), 0644))
+	}
+	idx, _ := newTestIndexingIndexer(t, workspaceDir)
+	idx.config.CacheCapacity = DefaultCacheCapacity
+	_, err := idx.IndexWorkspace(ctx, workspaceDir)
+	require.NoError(t, err)
+
+	mainPath := filepath.Join(workspaceDir, "main.go")
+	modelPath := filepath.Join(workspaceDir, "model.go")
+	tests := []struct {
+		name      string
+		filePath  string
+		startLine int
+		endLine   int
+		symbols   string
+		wantNames []string
+		wantErr   error
+	}{
+		{name: "变量类型", filePath: mainPath, startLine: 4, endLine: 4, wantNames: []string{"User"}},
+		{name: "函数参数类型", filePath: mainPath, startLine: 3, endLine: 3, wantNames: []string{"Order"}},
+		{name: "函数体内按参数名查询", filePath: mainPath, startLine: 5, endLine: 5, symbols: "o", wantNames: []string{"Order"}},
+		{name: "基础类型没有定义", filePath: mainPath, startLine: 5, endLine: 5, symbols: "n"},
+		{name: "JavaScript 不记录类型", filePath: filepath.Join(workspaceDir, "app.js"), startLine: 2, endLine: 2,
+			wantErr: errs.ErrUnSupportedLanguage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.QueryTypeDefinitions(ctx, &types.QueryDefinitionOptions{
+				Workspace:   workspaceDir,
+				FilePath:    tt.filePath,
+				StartLine:   tt.startLine,
+				EndLine:     tt.endLine,
+				SymbolNames: tt.symbols,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, d := range definitions {
+				names = append(names, d.Name)
+				assert.Equal(t, modelPath, d.Path)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
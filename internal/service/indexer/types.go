@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/store"
 	"codebase-indexer/pkg/codegraph/types"
@@ -8,23 +9,66 @@ import (
 	"codebase-indexer/pkg/logger"
 	"context"
 	"fmt"
+	"time"
 )
 
 // 常量定义
 const (
 	MaxQueryLineLimit         = 200
+	MaxCallGraphLineLimit     = 1000
+	MaxLineLimitCeiling       = 5000 // 调用方指定的行范围最大跨度的上限
 	DefaultConcurrency        = 1
 	DefaultBatchSize          = 50
 	DefaultMapBatchSize       = 5
 	DefaultMaxFiles           = 10000
 	DefaultMaxProjects        = 3
 	DefaultCacheCapacity      = 100000 // 假定单个文件平均10个元素,1万个文件
+	MinCacheCapacity          = 1000   // 内存受限模式下符号缓存收缩的下限
+	DefaultCacheInitCapacity  = 1000   // 符号缓存预分配的 map 容量
 	DefaultTopN               = 10
 	MaxCalleeMapCacheCapacity = 1600
 	VarVariadic               = "..."
 	DefaultMaxLayer           = 3
+	DefaultParseTimeout       = 30 * time.Second
+	DefaultCallGraphMaxNodes  = 5000 // 调用图节点总数的服务端上限，保证响应大小可预期
+	DefaultWalkConcurrency    = 1    // 收集文件时遍历目录的协程数，1 表示串行遍历
 )
 
+// ProjectSelectStrategy 项目数超过 MaxProjects 时选择要索引的项目的策略
+type ProjectSelectStrategy string
+
+const (
+	ProjectSelectDefault      ProjectSelectStrategy = ""             // 按项目发现顺序截断（原逻辑）
+	ProjectSelectLargestFirst ProjectSelectStrategy = "largest"      // 按源码文件数从多到少
+	ProjectSelectAlphabetical ProjectSelectStrategy = "alphabetical" // 按项目路径字母序
+	ProjectSelectAllowlist    ProjectSelectStrategy = "allowlist"    // 只索引白名单中的项目
+)
+
+// DefinitionPreference 同名符号有多个定义时的排序偏好
+type DefinitionPreference string
+
+const (
+	DefinitionPreferLocal DefinitionPreference = ""     // 同文件、同目录优先，其次项目内，最后外部依赖（默认）
+	DefinitionPreferNone  DefinitionPreference = "none" // 不排序，保持索引中的顺序
+)
+
+// SourceEncoding 没有 BOM 的非 UTF-8 源文件的编码
+type SourceEncoding string
+
+const (
+	SourceEncodingNone    SourceEncoding = ""        // 不转码，直接解析源文件字节（默认）
+	SourceEncodingGB18030 SourceEncoding = "gb18030" // 按 GB18030（兼容 GBK、GB2312）转码
+	SourceEncodingLatin1  SourceEncoding = "latin1"  // 按 Latin-1（ISO-8859-1）转码
+)
+
+// DefaultInteropLanguages 默认的互操作语言组，组内语言共用一个符号命名空间查找定义。
+// 目前只有 JavaScript/TypeScript 组实际生效；还没有 Kotlin 解析器，Kotlin 文件不会被索引，
+// Java/Kotlin 组暂时不起作用，保留以便支持 Kotlin 后直接生效
+var DefaultInteropLanguages = [][]lang.Language{
+	{lang.Java, lang.Kotlin},
+	{lang.JavaScript, lang.TypeScript},
+}
+
 // Config 索引器配置
 type Config struct {
 	MaxConcurrency int
@@ -33,12 +77,48 @@ type Config struct {
 	MaxProjects    int
 	VisitPattern   *types.VisitPattern
 	CacheCapacity  int
+	// CacheInitCapacity 符号缓存预分配的 map 容量，不限制缓存大小
+	CacheInitCapacity int
+	// MemoryLimitMB 堆内存阈值（MB），超过后符号缓存清空并收缩，0 表示不限制
+	MemoryLimitMB int
+	// ProjectSelectStrategy 截断项目前的选择策略
+	ProjectSelectStrategy ProjectSelectStrategy
+	// ProjectAllowlist 白名单策略下要索引的项目路径，支持绝对路径或相对工作区的路径
+	ProjectAllowlist []string
+	// ExternalIndexPaths 只读的外部索引目录，本地未找到符号定义时依次查询
+	ExternalIndexPaths []string
+	// ParseTimeout 单个文件的解析超时时间，超时的文件计为解析失败
+	ParseTimeout time.Duration
+	// DefinitionPreference 定义查询结果的排序偏好
+	DefinitionPreference DefinitionPreference
+	// InteropLanguages 互操作的语言组，查找定义时组内语言视为同一个符号命名空间（如 TypeScript 调用解析到 JavaScript 定义），
+	// nil 时使用 DefaultInteropLanguages，空切片表示各语言的符号互不可见
+	InteropLanguages [][]lang.Language
+	// SkipTestFiles 索引时按语言约定跳过测试文件，默认索引
+	SkipTestFiles bool
+	// SkipSymlinks 收集文件时跳过所有符号链接，默认跟随链接并按真实路径去重
+	SkipSymlinks bool
+	// FollowExternalSymlinks 收集文件时跟随目标在项目目录之外的符号链接，默认跳过
+	FollowExternalSymlinks bool
+	// CallGraphMaxNodes 调用图节点总数的服务端上限，请求未指定 MaxNodes 或超过该值时按该值截断
+	CallGraphMaxNodes int
+	// WalkConcurrency 收集文件时遍历目录的协程数，NFS 等高延迟文件系统上调大可加快收集
+	WalkConcurrency int
+	// SourceEncoding 非 UTF-8 源文件的转码编码，默认不转码，位置与磁盘上的文件字节一致
+	SourceEncoding SourceEncoding
 }
 
 // CalleeKey 表示被调用的符号信息
 type CalleeKey struct {
-	SymbolName string
-	ParamCount int
+	SymbolName    string
+	ParamCount    int
+	IsConstructor bool // new Foo() 等显式的构造调用，SymbolName 为类名
+}
+
+// CallSite 函数内部的一次调用，记录被调用符号和调用标识符的位置
+type CallSite struct {
+	CalleeKey
+	Position types.Position
 }
 
 // CalleeInfo 被调用者信息
@@ -48,6 +128,8 @@ type CalleeInfo struct {
 	SymbolName string         `json:"symbolName,omitempty"`
 	ParamCount int            `json:"paramCount,omitempty"`
 	IsVariadic bool           `json:"isVariadic,omitempty"`
+	// ClassName 构造函数（或没有显式构造函数的类）所属的类名，非空时按类名查找构造调用
+	ClassName string `json:"className,omitempty"`
 }
 
 // Key 生成被调用者唯一键
@@ -66,11 +148,14 @@ func (c *CalleeInfo) Key() string {
 type CallerInfo struct {
 	SymbolName string
 	FilePath   string
-	Position   types.Position
+	Position   types.Position // 调用处（被调用标识符）的位置
 	ParamCount int
 	IsVariadic bool
 	CalleeKey  CalleeKey
 	Score      float64 // 起到排序的作用
+
+	definitionPosition types.Position // 调用者定义的位置，查询调用链时填充
+	constructorOf      string         // 调用者是构造函数时所属的类名，查询调用链时填充
 }
 
 // Key 生成调用者唯一键
@@ -85,6 +170,12 @@ func (c *CallerInfo) Key() string {
 	)
 }
 
+// definitionKey 按调用者定义位置生成唯一键，与调用者作为被调用者时的 CalleeInfo.Key 一致
+func (c *CallerInfo) definitionKey() string {
+	callee := CalleeInfo{SymbolName: c.SymbolName, FilePath: c.FilePath, Position: c.definitionPosition}
+	return callee.Key()
+}
+
 // MapBatcher 批量映射处理器
 type MapBatcher struct {
 	storage     store.GraphStorage // 存储
@@ -146,8 +237,9 @@ func (mb *MapBatcher) flush(tempCalleeMap map[string][]CallerInfo) {
 				},
 				ParamCount: int32(c.ParamCount),
 				CalleeKey: &codegraphpb.CalleeKey{
-					SymbolName: c.CalleeKey.SymbolName,
-					ParamCount: int32(c.CalleeKey.ParamCount),
+					SymbolName:    c.CalleeKey.SymbolName,
+					ParamCount:    int32(c.CalleeKey.ParamCount),
+					IsConstructor: c.CalleeKey.IsConstructor,
 				},
 				IsVariadic: c.IsVariadic,
 				Score:      c.Score,
@@ -0,0 +1,157 @@
+package indexer
+
+import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultUnusedSymbolAllowlist 默认视为入口的符号名：程序入口、包初始化、测试函数、python 魔术方法
+var DefaultUnusedSymbolAllowlist = []string{
+	`^main$`,
+	`^init$`,
+	`^(Test|Benchmark|Example|Fuzz)`,
+	`^__\w+__$`,
+}
+
+// defaultUnusedSymbolTypes 默认检查的定义类型
+var defaultUnusedSymbolTypes = []types.ElementType{
+	types.ElementTypeFunction,
+	types.ElementTypeMethod,
+	types.ElementTypeClass,
+	types.ElementTypeInterface,
+}
+
+// FindUnusedSymbols 查询项目内没有任何引用的导出定义，结果按文件分组、文件内按行排序。
+// 引用按符号名匹配元素表中的调用、引用，以及调用关系反向索引（callee map）中的被调用符号，
+// 同名符号只要有一处引用就都视为已使用。以下场景会误报，结果只能作为死代码分析的候选：
+//   - 通过反射、依赖注入、序列化框架按字符串或注解使用的符号
+//   - 只被项目外部调用的公开 API 和框架回调（如 ServeHTTP），可通过 Allowlist 排除
+func (idx *Indexer) FindUnusedSymbols(ctx context.Context, workspacePath string, opts *types.FindUnusedSymbolsOptions) ([]*types.Definition, error) {
+	if workspacePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("workspace")
+	}
+	if opts == nil {
+		opts = &types.FindUnusedSymbolsOptions{}
+	}
+	allowlist := opts.Allowlist
+	if len(allowlist) == 0 {
+		allowlist = DefaultUnusedSymbolAllowlist
+	}
+	patterns := make([]*regexp.Regexp, 0, len(allowlist))
+	for _, p := range allowlist {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unused symbol allowlist pattern %s: %w", p, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	elementTypes := opts.ElementTypes
+	if len(elementTypes) == 0 {
+		elementTypes = defaultUnusedSymbolTypes
+	}
+	checkTypes := make(map[codegraphpb.ElementType]struct{}, len(elementTypes))
+	for _, t := range elementTypes {
+		checkTypes[proto.ElementTypeToProto(t)] = struct{}{}
+	}
+
+	startTime := time.Now()
+	projects, err := idx.getQueryProjects(ctx, workspacePath, opts.ProjectUuid)
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project found in workspace %s", workspacePath)
+	}
+
+	var results []*types.Definition
+	for _, p := range projects {
+		results = append(results, idx.findProjectUnusedSymbols(ctx, p.Uuid, checkTypes, patterns)...)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Range[0] < results[j].Range[0]
+	})
+
+	idx.logger.Info("find unused symbols for workspace %s cost %d ms, found %d symbols",
+		workspacePath, time.Since(startTime).Milliseconds(), len(results))
+	return results, nil
+}
+
+// findProjectUnusedSymbols 一次遍历项目索引，收集被引用的符号名和候选定义，再筛出没有引用的定义
+func (idx *Indexer) findProjectUnusedSymbols(ctx context.Context, projectUuid string,
+	checkTypes map[codegraphpb.ElementType]struct{}, allowlist []*regexp.Regexp) []*types.Definition {
+	referenced := make(map[string]struct{})
+	var candidates []*types.Definition
+
+	iter := idx.storage.Iter(ctx, projectUuid)
+	for iter.Next() {
+		key := iter.Key()
+		if store.IsCalleeMapKey(key) {
+			referenced[strings.TrimPrefix(key, store.CalleeMapKeySystemPrefix+types.Colon)] = struct{}{}
+			continue
+		}
+		if !store.IsElementPathKey(key) {
+			continue
+		}
+		var elementTable codegraphpb.FileElementTable
+		if err := store.UnmarshalValue(iter.Value(), &elementTable); err != nil {
+			idx.logger.Error("failed to unmarshal file element_table value, err: %v", err)
+			continue
+		}
+		language := lang.Language(elementTable.Language)
+		for _, e := range elementTable.Elements {
+			if e.Name == types.EmptyString {
+				continue
+			}
+			if !e.IsDefinition {
+				referenced[e.Name] = struct{}{}
+				continue
+			}
+			if _, ok := checkTypes[e.ElementType]; !ok || !isValidRange(e.Range) {
+				continue
+			}
+			if !isExportedName(language, e.Name) || matchAnyPattern(allowlist, e.Name) {
+				continue
+			}
+			candidates = append(candidates, &types.Definition{
+				Name:  e.Name,
+				Type:  string(proto.ElementTypeFromProto(e.ElementType)),
+				Path:  elementTable.Path,
+				Range: e.Range,
+			})
+		}
+	}
+	if err := iter.Close(); err != nil {
+		idx.logger.Error("project %s iter close err: %v", projectUuid, err)
+	}
+
+	var unused []*types.Definition
+	for _, d := range candidates {
+		if _, ok := referenced[d.Name]; !ok {
+			unused = append(unused, d)
+		}
+	}
+	return unused
+}
+
+// matchAnyPattern 名称是否匹配任意一个正则
+func matchAnyPattern(patterns []*regexp.Regexp, name string) bool {
+	for _, p := range patterns {
+		if p.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package indexer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUnusedSymbols(t *testing.T) {
+	ctx := context.Background()
+	idx, storage := newTestIndexerWithStorage(t)
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, mainFile, "package main\n",
+		[]*codegraphpb.Element{
+			{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 5, 1}},
+			{Name: "Serve", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 1, 3, 8}},
+			{Name: "Config", ElementType: codegraphpb.ElementType_REFERENCE, Range: []int32{4, 1, 4, 7}},
+		})
+	serverFile := filepath.Join(workspaceDir, "server.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, serverFile, "package main\n",
+		[]*codegraphpb.Element{
+			{Name: "Serve", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 4, 1}},
+			{Name: "Config", IsDefinition: true, ElementType: codegraphpb.ElementType_CLASS, Range: []int32{6, 0, 8, 1}},
+			{Name: "Shutdown", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{10, 0, 12, 1}},
+			{Name: "Handler", IsDefinition: true, ElementType: codegraphpb.ElementType_INTERFACE, Range: []int32{14, 0, 16, 1}},
+			{Name: "helper", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{18, 0, 20, 1}},
+			{Name: "TestServe", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{22, 0, 24, 1}},
+			{Name: "Version", IsDefinition: true, ElementType: codegraphpb.ElementType_VARIABLE, Range: []int32{26, 0, 26, 20}},
+		})
+	utilFile := filepath.Join(workspaceDir, "util.go")
+	saveTestFileElementTable(t, storage, project.Uuid, lang.Go, utilFile, "package main\n",
+		[]*codegraphpb.Element{
+			{Name: "Retry", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 4, 1}},
+			{Name: "Backoff", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{6, 0, 8, 1}},
+		})
+	// 调用关系反向索引中记录的被调用符号也视为已使用
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key:   store.CalleeMapKey{SymbolName: "Backoff"},
+		Value: &codegraphpb.CalleeMapItem{CalleeName: "Backoff"},
+	}))
+
+	type symbol struct {
+		path string
+		name string
+	}
+	tests := []struct {
+		name    string
+		opts    *types.FindUnusedSymbolsOptions
+		want    []symbol
+		wantErr bool
+	}{
+		{
+			name: "默认规则按文件分组返回",
+			want: []symbol{{serverFile, "Shutdown"}, {serverFile, "Handler"}, {utilFile, "Retry"}},
+		},
+		{
+			name: "自定义入口白名单",
+			opts: &types.FindUnusedSymbolsOptions{Allowlist: []string{`^Shutdown$`}},
+			want: []symbol{{serverFile, "Handler"}, {serverFile, "TestServe"}, {utilFile, "Retry"}},
+		},
+		{
+			name: "只检查指定类型",
+			opts: &types.FindUnusedSymbolsOptions{ElementTypes: []types.ElementType{types.ElementTypeInterface, types.ElementTypeVariable}},
+			want: []symbol{{serverFile, "Handler"}, {serverFile, "Version"}},
+		},
+		{
+			name:    "白名单正则非法",
+			opts:    &types.FindUnusedSymbolsOptions{Allowlist: []string{"("}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := idx.FindUnusedSymbols(ctx, workspaceDir, tt.opts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var got []symbol
+			for _, d := range definitions {
+				got = append(got, symbol{d.Path, d.Name})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package indexer
+
+import (
+	"codebase-indexer/internal/errs"
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxVerifyViolationSamples 一致性检查每个类别在报告中保留的样例数
+const maxVerifyViolationSamples = 100
+
+// verifySymbol 待核对的符号出现，需要遍历完所有元素表后才能判断
+type verifySymbol struct {
+	key      string
+	language lang.Language
+	paths    []string
+}
+
+// VerifyIndex 检查工作区下各项目索引的一致性：符号出现指向的文件都有元素表、调用关系中的调用者文件都存在、
+// 元素表为空时带有解析错误标记。只读不修改索引，用于排查查询返回过期或错误数据的问题
+func (idx *Indexer) VerifyIndex(ctx context.Context, workspacePath string) (*types.VerifyReport, error) {
+	if workspacePath == types.EmptyString {
+		return nil, errs.NewMissingParamError("workspace")
+	}
+	projects, err := idx.getQueryProjects(ctx, workspacePath, types.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project found in workspace %s", workspacePath)
+	}
+
+	startTime := time.Now()
+	report := &types.VerifyReport{
+		Workspace:  workspacePath,
+		Counts:     make(map[string]int),
+		Violations: make(map[string][]*types.IndexViolation),
+	}
+	for _, p := range projects {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		exists, err := idx.storage.ProjectIndexExists(p.Uuid)
+		if err != nil || !exists {
+			continue
+		}
+		report.Projects++
+		if err := idx.verifyProjectIndex(ctx, p.Uuid, report); err != nil {
+			return nil, fmt.Errorf("verify project %s index err: %w", p.Uuid, err)
+		}
+	}
+	idx.logger.Info("verify workspace %s index finish, cost %d ms, projects %d, element tables %d, symbols %d, callee maps %d, violations %v",
+		workspacePath, time.Since(startTime).Milliseconds(), report.Projects, report.ElementTables, report.Symbols,
+		report.CalleeMaps, report.Counts)
+	return report, nil
+}
+
+// verifyProjectIndex 遍历一次项目索引，检查结果追加到 report
+func (idx *Indexer) verifyProjectIndex(ctx context.Context, projectUuid string, report *types.VerifyReport) error {
+	indexedFiles := make(map[store.ElementPathKey]struct{})
+	var symbols []verifySymbol
+	fileExists := make(map[string]bool)
+
+	iter := idx.storage.Iter(ctx, projectUuid)
+	for iter.Next() {
+		key := iter.Key()
+		switch {
+		case store.IsElementPathKey(key):
+			pathKey, err := store.ToElementPathKey(key)
+			if err != nil {
+				continue
+			}
+			report.ElementTables++
+			indexedFiles[pathKey] = struct{}{}
+			var table codegraphpb.FileElementTable
+			if err = store.UnmarshalValue(iter.Value(), &table); err != nil {
+				addViolation(report, types.IndexViolationCorruptedValue, &types.IndexViolation{ProjectUuid: projectUuid,
+					Key: key, FilePath: pathKey.Path, Message: err.Error()})
+				continue
+			}
+			if len(table.Elements) == 0 && !table.HasParseErrors {
+				addViolation(report, types.IndexViolationEmptyElementTable, &types.IndexViolation{ProjectUuid: projectUuid,
+					Key: key, FilePath: pathKey.Path, Message: "element table has no elements and no parse error flag"})
+			}
+		case store.IsSymbolNameKey(key):
+			symbolKey, err := store.ToSymbolNameKey(key)
+			if err != nil {
+				continue
+			}
+			report.Symbols++
+			var symbol codegraphpb.SymbolOccurrence
+			if err = store.UnmarshalValue(iter.Value(), &symbol); err != nil {
+				addViolation(report, types.IndexViolationCorruptedValue, &types.IndexViolation{ProjectUuid: projectUuid,
+					Key: key, Message: err.Error()})
+				continue
+			}
+			s := verifySymbol{key: key, language: symbolKey.Language}
+			for _, o := range symbol.Occurrences {
+				s.paths = append(s.paths, o.Path)
+			}
+			symbols = append(symbols, s)
+		case store.IsCalleeMapKey(key):
+			report.CalleeMaps++
+			var item codegraphpb.CalleeMapItem
+			if err := store.UnmarshalValue(iter.Value(), &item); err != nil {
+				addViolation(report, types.IndexViolationCorruptedValue, &types.IndexViolation{ProjectUuid: projectUuid,
+					Key: key, Message: err.Error()})
+				continue
+			}
+			for _, caller := range item.Callers {
+				exists, ok := fileExists[caller.FilePath]
+				if !ok {
+					_, err := os.Stat(caller.FilePath)
+					exists = err == nil
+					fileExists[caller.FilePath] = exists
+				}
+				if !exists {
+					addViolation(report, types.IndexViolationMissingCallerFile, &types.IndexViolation{ProjectUuid: projectUuid,
+						Key: key, FilePath: caller.FilePath,
+						Message: fmt.Sprintf("caller %s of %s not found on disk", caller.SymbolName, item.CalleeName)})
+				}
+			}
+		}
+	}
+	err := iter.Error()
+	if closeErr := iter.Close(); closeErr != nil {
+		idx.logger.Error("project %s iter close err: %v", projectUuid, closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, s := range symbols {
+		for _, path := range s.paths {
+			if _, ok := indexedFiles[store.ElementPathKey{Language: s.language, Path: path}]; !ok {
+				addViolation(report, types.IndexViolationDanglingOccurrence, &types.IndexViolation{ProjectUuid: projectUuid,
+					Key: s.key, FilePath: path, Message: "symbol occurrence points to a file without element table"})
+			}
+		}
+	}
+	return nil
+}
+
+// addViolation 记录一条不一致，超过样例数后只计数
+func addViolation(report *types.VerifyReport, category string, v *types.IndexViolation) {
+	report.Counts[category]++
+	if len(report.Violations[category]) < maxVerifyViolationSamples {
+		report.Violations[category] = append(report.Violations[category], v)
+	}
+}
@@ -0,0 +1,132 @@
+package indexer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestVerifyIndex(t *testing.T) {
+	ctx := context.Background()
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	emptyFile := filepath.Join(workspaceDir, "empty.go")
+	brokenFile := filepath.Join(workspaceDir, "broken.go")
+	deletedFile := filepath.Join(workspaceDir, "deleted.go")
+
+	// seedConsistent 写入一致的索引：main.go 定义 Handle 并调用自身
+	seedConsistent := func(t *testing.T, storage store.GraphStorage) {
+		saveTestFileElementTable(t, storage, project.Uuid, lang.Go, mainFile, "package main\n",
+			[]*codegraphpb.Element{{Name: "Handle", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION,
+				Range: []int32{2, 0, 4, 1}}})
+		// 有解析错误标记的空元素表不算不一致
+		require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+			Key:   store.ElementPathKey{Language: lang.Go, Path: brokenFile},
+			Value: &codegraphpb.FileElementTable{Path: brokenFile, Language: string(lang.Go), HasParseErrors: true},
+		}))
+		require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+			Key: store.SymbolNameKey{Language: lang.Go, Name: "Handle"},
+			Value: &codegraphpb.SymbolOccurrence{Name: "Handle", Language: string(lang.Go),
+				Occurrences: []*codegraphpb.Occurrence{{Path: mainFile, Range: []int32{2, 0, 4, 1}}}},
+		}))
+		require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+			Key: store.CalleeMapKey{SymbolName: "Handle"},
+			Value: &codegraphpb.CalleeMapItem{CalleeName: "Handle",
+				Callers: []*codegraphpb.CallerInfo{{SymbolName: "Handle", FilePath: mainFile}}},
+		}))
+	}
+
+	tests := []struct {
+		name       string
+		seed       func(t *testing.T, storage store.GraphStorage)
+		wantCounts map[string]int
+		wantPaths  map[string][]string
+	}{
+		{
+			name:       "一致的索引",
+			seed:       func(t *testing.T, storage store.GraphStorage) {},
+			wantCounts: map[string]int{},
+		},
+		{
+			name: "符号出现指向没有元素表的文件",
+			seed: func(t *testing.T, storage store.GraphStorage) {
+				require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+					Key: store.SymbolNameKey{Language: lang.Go, Name: "Stale"},
+					Value: &codegraphpb.SymbolOccurrence{Name: "Stale", Language: string(lang.Go),
+						Occurrences: []*codegraphpb.Occurrence{{Path: deletedFile}, {Path: mainFile}}},
+				}))
+			},
+			wantCounts: map[string]int{types.IndexViolationDanglingOccurrence: 1},
+			wantPaths:  map[string][]string{types.IndexViolationDanglingOccurrence: {deletedFile}},
+		},
+		{
+			name: "调用者文件已不存在",
+			seed: func(t *testing.T, storage store.GraphStorage) {
+				require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+					Key: store.CalleeMapKey{SymbolName: "Handle"},
+					Value: &codegraphpb.CalleeMapItem{CalleeName: "Handle", Callers: []*codegraphpb.CallerInfo{
+						{SymbolName: "Handle", FilePath: mainFile}, {SymbolName: "Run", FilePath: deletedFile}}},
+				}))
+			},
+			wantCounts: map[string]int{types.IndexViolationMissingCallerFile: 1},
+			wantPaths:  map[string][]string{types.IndexViolationMissingCallerFile: {deletedFile}},
+		},
+		{
+			name: "没有解析错误标记的空元素表",
+			seed: func(t *testing.T, storage store.GraphStorage) {
+				saveTestFileElementTable(t, storage, project.Uuid, lang.Go, emptyFile, "package main\n", nil)
+			},
+			wantCounts: map[string]int{types.IndexViolationEmptyElementTable: 1},
+			wantPaths:  map[string][]string{types.IndexViolationEmptyElementTable: {emptyFile}},
+		},
+		{
+			name: "元素表无法反序列化",
+			seed: func(t *testing.T, storage store.GraphStorage) {
+				require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+					Key:   store.ElementPathKey{Language: lang.Go, Path: mainFile},
+					Value: wrapperspb.Bytes([]byte{0xff, 0xfe, 0xfd}), // path 字段为非法 UTF-8
+				}))
+			},
+			wantCounts: map[string]int{types.IndexViolationCorruptedValue: 1},
+			wantPaths:  map[string][]string{types.IndexViolationCorruptedValue: {mainFile}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, storage := newTestIndexerWithStorage(t)
+			seedConsistent(t, storage)
+			tt.seed(t, storage)
+
+			report, err := idx.VerifyIndex(ctx, workspaceDir)
+			require.NoError(t, err)
+			assert.Equal(t, 1, report.Projects)
+			assert.Equal(t, tt.wantCounts, report.Counts)
+			assert.Equal(t, len(tt.wantCounts) == 0, report.Consistent())
+			for category, paths := range tt.wantPaths {
+				var got []string
+				for _, v := range report.Violations[category] {
+					assert.Equal(t, project.Uuid, v.ProjectUuid)
+					got = append(got, v.FilePath)
+				}
+				assert.Equal(t, paths, got)
+			}
+		})
+	}
+
+	t.Run("工作区为空", func(t *testing.T) {
+		idx, _ := newTestIndexerWithStorage(t)
+		_, err := idx.VerifyIndex(ctx, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/logger"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// DefaultWatchDebounce 文件变更的合并窗口，窗口内的连续变更合并为一次检测
+	DefaultWatchDebounce = 2 * time.Second
+	// defaultWatchPollInterval 无法监听时退化为轮询的间隔
+	defaultWatchPollInterval = time.Minute
+	// defaultWatchRetryInterval 退化为轮询后重新尝试监听的间隔
+	defaultWatchRetryInterval = 10 * time.Minute
+	// defaultMaxWatchDirs 单个工作区最多监听的目录数，超过后退化为轮询
+	defaultMaxWatchDirs = 8192
+)
+
+var errTooManyWatchDirs = errors.New("too many directories to watch")
+
+// WorkspaceWatcher 基于 fsnotify 监听工作区源文件变更，去抖后通过 FileScanService 写入事件表，
+// 由 EventProcessorJob 统一处理。无法建立监听的工作区退化为轮询，并定期重试监听
+type WorkspaceWatcher struct {
+	scanner       FileScanService
+	fileScanner   repository.ScannerInterface
+	logger        logger.Logger
+	debounce      time.Duration
+	pollInterval  time.Duration
+	retryInterval time.Duration
+	maxWatchDirs  int
+
+	watcher *fsnotify.Watcher
+	mu      sync.Mutex
+	// workspacePath -> 已监听的目录
+	watched map[string]map[string]struct{}
+	// 退化为轮询的工作区 -> 退化时间
+	polling map[string]time.Time
+	// workspacePath -> 待检测的变更路径
+	pending map[string]map[string]struct{}
+	ignores map[string]*config.IgnoreConfig
+}
+
+// NewWorkspaceWatcher 创建工作区监听器
+func NewWorkspaceWatcher(
+	scanner FileScanService,
+	fileScanner repository.ScannerInterface,
+	logger logger.Logger,
+	debounce time.Duration,
+) (*WorkspaceWatcher, error) {
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &WorkspaceWatcher{
+		scanner:       scanner,
+		fileScanner:   fileScanner,
+		logger:        logger,
+		debounce:      debounce,
+		pollInterval:  defaultWatchPollInterval,
+		retryInterval: defaultWatchRetryInterval,
+		maxWatchDirs:  defaultMaxWatchDirs,
+		watcher:       watcher,
+		watched:       make(map[string]map[string]struct{}),
+		polling:       make(map[string]time.Time),
+		pending:       make(map[string]map[string]struct{}),
+		ignores:       make(map[string]*config.IgnoreConfig),
+	}, nil
+}
+
+// WatchWorkspace 递归监听工作区目录，跳过忽略的目录（如 node_modules）。
+// 监听失败（如达到系统 inotify 上限）时退化为轮询并返回错误。
+// 轮询中的工作区超过重试间隔后重新尝试监听，成功后停止轮询
+func (w *WorkspaceWatcher) WatchWorkspace(workspacePath string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.watched[workspacePath]; ok {
+		return nil
+	}
+	fallbackAt, polling := w.polling[workspacePath]
+	if polling && time.Since(fallbackAt) < w.retryInterval {
+		return nil
+	}
+	w.ignores[workspacePath] = w.fileScanner.LoadIgnoreConfig(workspacePath)
+	w.watched[workspacePath] = make(map[string]struct{})
+	if err := w.watchDir(workspacePath, workspacePath); err != nil {
+		w.fallbackToPolling(workspacePath)
+		return err
+	}
+	if polling {
+		delete(w.polling, workspacePath)
+		w.logger.Info("workspace %s resumed watching after polling", workspacePath)
+	}
+	w.logger.Info("watching workspace %s, %d directories", workspacePath, len(w.watched[workspacePath]))
+	return nil
+}
+
+// UnwatchWorkspace 取消工作区的监听或轮询
+func (w *WorkspaceWatcher) UnwatchWorkspace(workspacePath string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unwatchWorkspace(workspacePath)
+	delete(w.polling, workspacePath)
+}
+
+// Workspaces 返回监听中（含退化为轮询）的工作区
+func (w *WorkspaceWatcher) Workspaces() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	workspaces := make([]string, 0, len(w.watched)+len(w.polling))
+	for workspacePath := range w.watched {
+		workspaces = append(workspaces, workspacePath)
+	}
+	for workspacePath := range w.polling {
+		workspaces = append(workspaces, workspacePath)
+	}
+	sort.Strings(workspaces)
+	return workspaces
+}
+
+// Run 处理文件变更直到 ctx 结束，结束时关闭监听。enabled 返回 false 时丢弃变更，不生成事件
+func (w *WorkspaceWatcher) Run(ctx context.Context, enabled func() bool) {
+	defer w.watcher.Close()
+
+	pollTicker := time.NewTicker(w.pollInterval)
+	defer pollTicker.Stop()
+	debounceTimer := time.NewTimer(w.debounce)
+	debounceTimer.Stop()
+	defer debounceTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pollTicker.C:
+			w.pollWorkspaces(ctx, enabled)
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				w.logger.Warn("file watcher events channel closed")
+				return
+			}
+			if w.handleEvent(event) {
+				// 重置去抖窗口，合并连续的变更
+				debounceTimer.Reset(w.debounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				w.logger.Warn("file watcher errors channel closed")
+				return
+			}
+			w.logger.Error("file watcher error: %v", err)
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// 事件溢出可能丢失变更，对所有监听中的工作区做一次全量检测
+				w.reconcileWatchedWorkspaces(ctx, enabled)
+			}
+		case <-debounceTimer.C:
+			w.flushPending(ctx, enabled)
+		}
+	}
+}
+
+// watchDir 递归监听目录
+func (w *WorkspaceWatcher) watchDir(workspacePath, dir string) error {
+	dirs := w.watched[workspacePath]
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			w.logger.Debug("workspace watcher walk %s err: %v", path, err)
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != workspacePath && w.isIgnored(workspacePath, &types.FileInfo{Path: path, IsDir: true}) {
+			return filepath.SkipDir
+		}
+		if _, ok := dirs[path]; ok {
+			return nil
+		}
+		if len(dirs) >= w.maxWatchDirs {
+			return errTooManyWatchDirs
+		}
+		if err := w.watcher.Add(path); err != nil {
+			// 通常是达到系统 inotify 上限
+			return err
+		}
+		dirs[path] = struct{}{}
+		return nil
+	})
+}
+
+// unwatchWorkspace 取消工作区的目录监听
+func (w *WorkspaceWatcher) unwatchWorkspace(workspacePath string) {
+	for dir := range w.watched[workspacePath] {
+		_ = w.watcher.Remove(dir)
+	}
+	delete(w.watched, workspacePath)
+	delete(w.pending, workspacePath)
+	delete(w.ignores, workspacePath)
+}
+
+// unwatchDir 取消已删除或重命名目录及其所有子目录的监听
+func (w *WorkspaceWatcher) unwatchDir(workspacePath, dir string) {
+	dirs := w.watched[workspacePath]
+	prefix := dir + string(filepath.Separator)
+	for path := range dirs {
+		if path != dir && !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		// 删除的目录已被系统移除监听，重命名的目录仍需显式移除
+		_ = w.watcher.Remove(path)
+		delete(dirs, path)
+	}
+}
+
+// fallbackToPolling 工作区退化为轮询
+func (w *WorkspaceWatcher) fallbackToPolling(workspacePath string) {
+	w.unwatchWorkspace(workspacePath)
+	w.polling[workspacePath] = time.Now()
+}
+
+// handleEvent 记录变更路径，返回是否需要触发检测
+func (w *WorkspaceWatcher) handleEvent(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	workspacePath := w.findWorkspace(event.Name)
+	if workspacePath == types.EmptyString {
+		return false
+	}
+	info, statErr := os.Stat(event.Name)
+	if statErr == nil {
+		fileInfo := &types.FileInfo{Path: event.Name, Size: info.Size(), IsDir: info.IsDir()}
+		if w.isIgnored(workspacePath, fileInfo) {
+			return false
+		}
+		if info.IsDir() && event.Has(fsnotify.Create) {
+			// 新建的目录需要补充监听，目录下已有的文件随目录路径一起检测
+			if err := w.watchDir(workspacePath, event.Name); err != nil {
+				w.logger.Warn("failed to watch new directory %s, fall back to polling workspace %s: %v",
+					event.Name, workspacePath, err)
+				w.fallbackToPolling(workspacePath)
+				return false
+			}
+		}
+	}
+	if statErr != nil {
+		// 删除或重命名的目录及其子目录不再需要监听
+		w.unwatchDir(workspacePath, event.Name)
+	}
+
+	if w.pending[workspacePath] == nil {
+		w.pending[workspacePath] = make(map[string]struct{})
+	}
+	w.pending[workspacePath][event.Name] = struct{}{}
+	return true
+}
+
+// findWorkspace 查找路径所属的工作区，多个匹配时取最长的
+func (w *WorkspaceWatcher) findWorkspace(path string) string {
+	var found string
+	for workspacePath := range w.watched {
+		if path != workspacePath && !strings.HasPrefix(path, workspacePath+string(filepath.Separator)) {
+			continue
+		}
+		if len(workspacePath) > len(found) {
+			found = workspacePath
+		}
+	}
+	return found
+}
+
+// isIgnored 使用与索引收集文件相同的忽略配置判断
+func (w *WorkspaceWatcher) isIgnored(workspacePath string, fileInfo *types.FileInfo) bool {
+	ignoreConfig := w.ignores[workspacePath]
+	if ignoreConfig == nil {
+		return false
+	}
+	skip, err := w.fileScanner.CheckIgnoreFile(ignoreConfig, workspacePath, fileInfo)
+	if err != nil {
+		w.logger.Debug("workspace watcher check ignore file %s err: %v", fileInfo.Path, err)
+		return false
+	}
+	return skip
+}
+
+// flushPending 将去抖窗口内累积的变更写入事件表
+func (w *WorkspaceWatcher) flushPending(ctx context.Context, enabled func() bool) {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = make(map[string]map[string]struct{})
+	w.mu.Unlock()
+
+	if len(pending) == 0 || !enabled() {
+		return
+	}
+	for workspacePath, pathSet := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		paths := make([]string, 0, len(pathSet))
+		for path := range pathSet {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		events, err := w.scanner.DetectPathChanges(workspacePath, paths)
+		if err != nil {
+			w.logger.Error("failed to detect watched changes in workspace %s: %v", workspacePath, err)
+			continue
+		}
+		w.logger.Debug("workspace watcher enqueued %d events from %d paths in workspace %s",
+			len(events), len(paths), workspacePath)
+	}
+}
+
+// pollWorkspaces 对无法监听的工作区做全量检测
+func (w *WorkspaceWatcher) pollWorkspaces(ctx context.Context, enabled func() bool) {
+	w.mu.Lock()
+	workspaces := make([]string, 0, len(w.polling))
+	for workspacePath := range w.polling {
+		workspaces = append(workspaces, workspacePath)
+	}
+	w.mu.Unlock()
+	w.detectWorkspaces(ctx, workspaces, enabled)
+}
+
+// reconcileWatchedWorkspaces 对所有监听中的工作区做全量检测
+func (w *WorkspaceWatcher) reconcileWatchedWorkspaces(ctx context.Context, enabled func() bool) {
+	w.mu.Lock()
+	workspaces := make([]string, 0, len(w.watched))
+	for workspacePath := range w.watched {
+		workspaces = append(workspaces, workspacePath)
+	}
+	w.mu.Unlock()
+	w.detectWorkspaces(ctx, workspaces, enabled)
+}
+
+func (w *WorkspaceWatcher) detectWorkspaces(ctx context.Context, workspaces []string, enabled func() bool) {
+	if len(workspaces) == 0 || !enabled() {
+		return
+	}
+	for _, workspacePath := range workspaces {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := w.scanner.DetectFileChanges(workspacePath); err != nil {
+			w.logger.Error("failed to detect file changes in workspace %s: %v", workspacePath, err)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codebase-indexer/internal/config"
+	"codebase-indexer/internal/model"
+	"codebase-indexer/internal/repository"
+	"codebase-indexer/internal/utils"
+	"codebase-indexer/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEventRepository 记录经 FileScanService 写入事件表的事件
+type recordingEventRepository struct {
+	repository.EventRepository
+	mu     sync.Mutex
+	events map[string]string // 源文件路径 -> 事件类型
+}
+
+func (r *recordingEventRepository) GetEventsByWorkspaceForDeduplication(workspacePath string) ([]*model.Event, error) {
+	return nil, nil
+}
+
+func (r *recordingEventRepository) GetEventsByTypeAndStatusAndWorkspaces(eventTypes []string, workspacePaths []string,
+	limit int, isDesc bool, embeddingStatuses []int, codegraphStatuses []int) ([]*model.Event, error) {
+	return nil, nil
+}
+
+func (r *recordingEventRepository) BatchCreateEvents(events []*model.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, event := range events {
+		r.events[event.SourceFilePath] = event.EventType
+	}
+	return nil
+}
+
+func (r *recordingEventRepository) eventType(path string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events[path]
+}
+
+func (r *recordingEventRepository) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = make(map[string]string)
+}
+
+// staticEmbeddingRepository 返回固定的已上传文件哈希
+type staticEmbeddingRepository struct {
+	repository.EmbeddingFileRepository
+	hashTree map[string]string
+}
+
+func (r *staticEmbeddingRepository) GetEmbeddingConfig(embeddingId string) (*config.EmbeddingConfig, error) {
+	return &config.EmbeddingConfig{CodebaseId: embeddingId, HashTree: r.hashTree}, nil
+}
+
+// recordingFileScanService 记录监听器触发的增量检测和全量检测
+type recordingFileScanService struct {
+	FileScanService
+	mu          sync.Mutex
+	pathChanges [][]string
+	scanned     []string
+}
+
+func (s *recordingFileScanService) DetectPathChanges(workspacePath string, paths []string) ([]*model.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pathChanges = append(s.pathChanges, paths)
+	return nil, nil
+}
+
+func (s *recordingFileScanService) DetectFileChanges(workspacePath string) ([]*model.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scanned = append(s.scanned, workspacePath)
+	return nil, nil
+}
+
+func (s *recordingFileScanService) detectCalls() ([][]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.pathChanges...), append([]string(nil), s.scanned...)
+}
+
+// runWorkspaceWatcher 在后台运行监听器，测试结束时停止
+func runWorkspaceWatcher(t *testing.T, watcher *WorkspaceWatcher) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.Run(ctx, func() bool { return true })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestWorkspaceWatcher_WatchWorkspace(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+
+	workspaceDir := t.TempDir()
+	existingFile := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, os.WriteFile(existingFile, []byte("package main\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(workspaceDir, "node_modules", "lib"), 0755))
+
+	storage, err := repository.NewStorageManager(t.TempDir(), logger)
+	require.NoError(t, err)
+	require.NoError(t, storage.SaveCodebaseConfig(&config.CodebaseConfig{
+		CodebaseId:   utils.GenerateCodebaseID(workspaceDir),
+		CodebasePath: workspaceDir,
+		HashTree:     map[string]string{},
+	}))
+	eventRepo := &recordingEventRepository{events: make(map[string]string)}
+	// main.go 已上传过，哈希与本地不同
+	embeddingRepo := &staticEmbeddingRepository{hashTree: map[string]string{"main.go": "stale"}}
+	fileScanner := repository.NewFileScanner(logger)
+	scanService := NewFileScanService(nil, eventRepo, fileScanner, storage, embeddingRepo, logger)
+	watcher, err := NewWorkspaceWatcher(scanService, fileScanner, logger, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, watcher.WatchWorkspace(workspaceDir))
+	assert.Equal(t, []string{workspaceDir}, watcher.Workspaces())
+	runWorkspaceWatcher(t, watcher)
+
+	newFile := filepath.Join(workspaceDir, "util.go")
+	tests := []struct {
+		name          string
+		change        func() error
+		wantPath      string
+		wantEventType string
+	}{
+		{
+			name:          "新建文件",
+			change:        func() error { return os.WriteFile(newFile, []byte("package main\n"), 0644) },
+			wantPath:      "util.go",
+			wantEventType: model.EventTypeAddFile,
+		},
+		{
+			name:          "修改文件",
+			change:        func() error { return os.WriteFile(existingFile, []byte("package main\n\nfunc main() {}\n"), 0644) },
+			wantPath:      "main.go",
+			wantEventType: model.EventTypeModifyFile,
+		},
+		{
+			name:          "删除文件",
+			change:        func() error { return os.Remove(existingFile) },
+			wantPath:      "main.go",
+			wantEventType: model.EventTypeDeleteFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventRepo.reset()
+			require.NoError(t, tt.change())
+			assert.Eventually(t, func() bool { return eventRepo.eventType(tt.wantPath) == tt.wantEventType },
+				2*time.Second, 20*time.Millisecond, "events: %v", eventRepo.events)
+		})
+	}
+
+	t.Run("忽略目录下的变更不入队", func(t *testing.T) {
+		eventRepo.reset()
+		ignoredFile := filepath.Join(workspaceDir, "node_modules", "lib", "index.js")
+		require.NoError(t, os.WriteFile(ignoredFile, []byte("module.exports = {}\n"), 0644))
+		// 写入一个正常文件作为参照，确保去抖窗口已经刷新
+		marker := filepath.Join(workspaceDir, "marker.go")
+		require.NoError(t, os.WriteFile(marker, []byte("package main\n"), 0644))
+		assert.Eventually(t, func() bool { return eventRepo.eventType("marker.go") != "" }, 2*time.Second, 20*time.Millisecond)
+		assert.Empty(t, eventRepo.eventType("node_modules/lib/index.js"))
+	})
+
+	t.Run("取消监听", func(t *testing.T) {
+		watcher.UnwatchWorkspace(workspaceDir)
+		assert.Empty(t, watcher.Workspaces())
+	})
+}
+
+func TestWorkspaceWatcher_Debounce(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	workspaceDir := t.TempDir()
+	scanService := &recordingFileScanService{}
+	const debounce = 300 * time.Millisecond
+	watcher, err := NewWorkspaceWatcher(scanService, repository.NewFileScanner(logger), logger, debounce)
+	require.NoError(t, err)
+	require.NoError(t, watcher.WatchWorkspace(workspaceDir))
+	runWorkspaceWatcher(t, watcher)
+
+	// 去抖窗口内的连续变更合并为一次检测
+	var wantPaths []string
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		path := filepath.Join(workspaceDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+		wantPaths = append(wantPaths, path)
+	}
+	assert.Eventually(t, func() bool {
+		pathChanges, _ := scanService.detectCalls()
+		return len(pathChanges) > 0
+	}, 2*time.Second, 20*time.Millisecond)
+	time.Sleep(2 * debounce)
+
+	pathChanges, scanned := scanService.detectCalls()
+	require.Len(t, pathChanges, 1)
+	assert.Equal(t, wantPaths, pathChanges[0])
+	assert.Empty(t, scanned)
+}
+
+func TestWorkspaceWatcher_PollingFallback(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	workspaceDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspaceDir, "pkg"), 0755))
+	scanService := &recordingFileScanService{}
+	watcher, err := NewWorkspaceWatcher(scanService, repository.NewFileScanner(logger), logger, 50*time.Millisecond)
+	require.NoError(t, err)
+	// 目录数超过上限，无法建立监听
+	watcher.maxWatchDirs = 1
+	watcher.pollInterval = 50 * time.Millisecond
+
+	err = watcher.WatchWorkspace(workspaceDir)
+	assert.ErrorIs(t, err, errTooManyWatchDirs)
+	assert.Equal(t, []string{workspaceDir}, watcher.Workspaces())
+	runWorkspaceWatcher(t, watcher)
+
+	// 轮询时定期全量检测，文件变更不再触发增量检测
+	require.NoError(t, os.WriteFile(filepath.Join(workspaceDir, "main.go"), []byte("package main\n"), 0644))
+	assert.Eventually(t, func() bool {
+		_, scanned := scanService.detectCalls()
+		return len(scanned) >= 2
+	}, 2*time.Second, 20*time.Millisecond)
+	pathChanges, scanned := scanService.detectCalls()
+	assert.Empty(t, pathChanges)
+	assert.Equal(t, workspaceDir, scanned[0])
+
+	// 取消后不再轮询，等待进行中的轮询结束后计数不再增加
+	watcher.UnwatchWorkspace(workspaceDir)
+	assert.Empty(t, watcher.Workspaces())
+	time.Sleep(watcher.pollInterval)
+	_, scanned = scanService.detectCalls()
+	time.Sleep(4 * watcher.pollInterval)
+	_, after := scanService.detectCalls()
+	assert.Equal(t, len(scanned), len(after))
+}
+
+func TestWorkspaceWatcher_RetryAfterPolling(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	workspaceDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspaceDir, "pkg"), 0755))
+	scanService := &recordingFileScanService{}
+	watcher, err := NewWorkspaceWatcher(scanService, repository.NewFileScanner(logger), logger, 50*time.Millisecond)
+	require.NoError(t, err)
+	watcher.maxWatchDirs = 1
+	watcher.retryInterval = 100 * time.Millisecond
+	require.ErrorIs(t, watcher.WatchWorkspace(workspaceDir), errTooManyWatchDirs)
+	runWorkspaceWatcher(t, watcher)
+
+	// 重试间隔内不重新监听
+	watcher.maxWatchDirs = defaultMaxWatchDirs
+	require.NoError(t, watcher.WatchWorkspace(workspaceDir))
+	assert.Contains(t, watcher.polling, workspaceDir)
+
+	// 超过重试间隔后恢复监听，文件变更重新触发增量检测
+	time.Sleep(watcher.retryInterval)
+	require.NoError(t, watcher.WatchWorkspace(workspaceDir))
+	assert.NotContains(t, watcher.polling, workspaceDir)
+	assert.Equal(t, []string{workspaceDir}, watcher.Workspaces())
+
+	path := filepath.Join(workspaceDir, "pkg", "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+	assert.Eventually(t, func() bool {
+		pathChanges, _ := scanService.detectCalls()
+		return len(pathChanges) > 0
+	}, 2*time.Second, 20*time.Millisecond)
+	pathChanges, _ := scanService.detectCalls()
+	assert.Contains(t, pathChanges[0], path)
+}
+
+func TestWorkspaceWatcher_UnwatchDeletedDirTree(t *testing.T) {
+	logger := &mocks.MockLogger{}
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		logger.On(level, mock.Anything, mock.Anything).Maybe().Return()
+	}
+	workspaceDir := t.TempDir()
+	tests := []struct {
+		name   string
+		remove func(dir string) error
+	}{
+		{name: "删除目录", remove: os.RemoveAll},
+		{name: "重命名目录", remove: func(dir string) error { return os.Rename(dir, dir+"-renamed") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(workspaceDir, "pkg")
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0755))
+			watcher, err := NewWorkspaceWatcher(&recordingFileScanService{}, repository.NewFileScanner(logger), logger,
+				50*time.Millisecond)
+			require.NoError(t, err)
+			require.NoError(t, watcher.WatchWorkspace(workspaceDir))
+			runWorkspaceWatcher(t, watcher)
+
+			require.NoError(t, tt.remove(dir))
+			assert.Eventually(t, func() bool {
+				watcher.mu.Lock()
+				defer watcher.mu.Unlock()
+				for path := range watcher.watched[workspaceDir] {
+					if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+						return false
+					}
+				}
+				return true
+			}, 2*time.Second, 20*time.Millisecond)
+			for _, path := range watcher.watcher.WatchList() {
+				assert.False(t, path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)), path)
+			}
+			require.NoError(t, os.RemoveAll(dir+"-renamed"))
+		})
+	}
+}
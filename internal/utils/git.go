@@ -0,0 +1,58 @@
+// utils/git.go - Git repository state utilities
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitOperationMarkers git 在 rebase、merge、cherry-pick、revert 等批量操作进行中时在 git 目录下创建的文件或目录
+var gitOperationMarkers = []string{
+	"rebase-merge",
+	"rebase-apply",
+	"MERGE_HEAD",
+	"CHERRY_PICK_HEAD",
+	"REVERT_HEAD",
+}
+
+// GitOperationInProgress 检查工作区是否有进行中的 git 批量操作，返回检测到的标记。
+// 支持 .git 为文件（worktree、子模块）的情况
+func GitOperationInProgress(workspacePath string) (string, bool) {
+	gitDir := resolveGitDir(workspacePath)
+	if gitDir == "" {
+		return "", false
+	}
+	for _, marker := range gitOperationMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, marker)); err == nil {
+			return marker, true
+		}
+	}
+	return "", false
+}
+
+// resolveGitDir 获取工作区的 git 目录，不是 git 仓库时返回空串
+func resolveGitDir(workspacePath string) string {
+	gitPath := filepath.Join(workspacePath, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return gitPath
+	}
+	// .git 文件内容形如 "gitdir: ../.git/worktrees/feature"
+	content, err := os.ReadFile(gitPath)
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(workspacePath, gitDir)
+	}
+	return gitDir
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitOperationInProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, dir string)
+		subDir     string // 工作区相对临时目录的路径
+		wantMarker string
+		wantOk     bool
+	}{
+		{
+			name:  "not a git repository",
+			setup: func(t *testing.T, dir string) {},
+		},
+		{
+			name: "no operation in progress",
+			setup: func(t *testing.T, dir string) {
+				mustMkdir(t, filepath.Join(dir, ".git"))
+			},
+		},
+		{
+			name: "rebase in progress",
+			setup: func(t *testing.T, dir string) {
+				mustMkdir(t, filepath.Join(dir, ".git", "rebase-merge"))
+			},
+			wantMarker: "rebase-merge",
+			wantOk:     true,
+		},
+		{
+			name: "merge in progress",
+			setup: func(t *testing.T, dir string) {
+				mustMkdir(t, filepath.Join(dir, ".git"))
+				mustWriteFile(t, filepath.Join(dir, ".git", "MERGE_HEAD"), "abc\n")
+			},
+			wantMarker: "MERGE_HEAD",
+			wantOk:     true,
+		},
+		{
+			name: "worktree with gitdir file",
+			setup: func(t *testing.T, dir string) {
+				mustMkdir(t, filepath.Join(dir, "main.git", "worktrees", "feature", "rebase-apply"))
+				mustMkdir(t, filepath.Join(dir, "feature"))
+				mustWriteFile(t, filepath.Join(dir, "feature", ".git"), "gitdir: ../main.git/worktrees/feature\n")
+			},
+			subDir:     "feature",
+			wantMarker: "rebase-apply",
+			wantOk:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+			marker, ok := GitOperationInProgress(filepath.Join(dir, tt.subDir))
+			if marker != tt.wantMarker || ok != tt.wantOk {
+				t.Errorf("GitOperationInProgress() = (%q, %v), want (%q, %v)", marker, ok, tt.wantMarker, tt.wantOk)
+			}
+		})
+	}
+}
+
+func mustMkdir(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -115,6 +117,8 @@ type HTTPError struct {
 	Message    string // 错误消息
 	RequestID  string // 请求ID
 	Timestamp  int64  // 错误时间戳
+	// RetryAfter 服务端通过 Retry-After 响应头要求的等待时间，未返回时为0
+	RetryAfter time.Duration
 }
 
 // Error 实现error接口
@@ -258,7 +262,9 @@ func (hc *HTTPClient) handleHTTPError(resp *fasthttp.Response) error {
 	case statusCode == fasthttp.StatusNotFound:
 		return NewHTTPError(statusCode, "resource not found")
 	case statusCode == fasthttp.StatusTooManyRequests:
-		return NewHTTPError(statusCode, "too many requests")
+		httpErr := NewHTTPError(statusCode, "too many requests")
+		httpErr.RetryAfter = parseRetryAfter(string(resp.Header.Peek("Retry-After")), time.Now())
+		return httpErr
 	case statusCode >= 500:
 		return NewHTTPError(statusCode, "server internal error")
 	default:
@@ -266,6 +272,24 @@ func (hc *HTTPClient) handleHTTPError(resp *fasthttp.Response) error {
 	}
 }
 
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和 HTTP 日期两种格式，无法解析时返回0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
 // DoJSONRequest 执行JSON请求的专用方法
 func (hc *HTTPClient) DoJSONRequest(method, url string, requestBody interface{}, token string, response interface{}) error {
 	headers := map[string]string{
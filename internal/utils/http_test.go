@@ -2,7 +2,9 @@ package utils
 
 import (
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -177,6 +179,27 @@ func TestIsServiceUnavailableError(t *testing.T) {
 	}
 }
 
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "秒数", value: "3", want: 3 * time.Second},
+		{name: "HTTP日期", value: now.Add(10 * time.Second).Format(http.TimeFormat), want: 10 * time.Second},
+		{name: "过去的日期", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{name: "空值", value: "", want: 0},
+		{name: "负数", value: "-1", want: 0},
+		{name: "非法值", value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseRetryAfter(tt.value, now))
+		})
+	}
+}
+
 // func TestNewHTTPError(t *testing.T) {
 // 	statusCode := 404
 // 	message := "resource not found"
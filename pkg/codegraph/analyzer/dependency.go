@@ -3,6 +3,7 @@ package analyzer
 import (
 	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
 	"codebase-indexer/pkg/codegraph/cache"
+	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/parser"
 	"codebase-indexer/pkg/codegraph/proto"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
@@ -197,7 +198,7 @@ func (da *DependencyAnalyzer) FilterByImports(filePath string, imports []*codegr
 	found := make([]*codegraphpb.Occurrence, 0)
 	for _, def := range occurrences {
 		// 1、同文件
-		if def.Path == filePath {
+		if utils.PathEqual(def.Path, filePath) {
 			found = append(found, def)
 			continue
 		}
@@ -219,7 +220,38 @@ func (da *DependencyAnalyzer) FilterByImports(filePath string, imports []*codegr
 	return found
 }
 
+// FilterByImportConfidence 按导入匹配置信度对符号出现分级，返回不低于 minConfidence 的最高一级。
+// 没有满足 minConfidence 的出现时返回空
+func (da *DependencyAnalyzer) FilterByImportConfidence(filePath string, imports []*codegraphpb.Import,
+	occurrences []*codegraphpb.Occurrence, minConfidence ImportConfidence) []*codegraphpb.Occurrence {
+	top := minConfidence
+	found := make([]*codegraphpb.Occurrence, 0)
+	for _, def := range occurrences {
+		confidence := ImportMatchConfidence(filePath, imports, def.Path)
+		if confidence < top {
+			continue
+		}
+		if confidence > top {
+			top = confidence
+			found = found[:0]
+		}
+		found = append(found, def)
+	}
+	return found
+}
+
+// CalculateSymbolMatchScore 使用默认打分计算调用者与被调用符号的匹配分数，见 DefaultSymbolScorer
 func (da *DependencyAnalyzer) CalculateSymbolMatchScore(workspace string, callerImports []*codegraphpb.Import, callerFilePath string, calleeFilePath string, calleeSymbolName string, callerSymbolName string) int {
+	return calculateSymbolMatchScore(workspace, callerImports, callerFilePath, calleeFilePath, calleeSymbolName, callerSymbolName)
+}
+
+// ScoreSymbolMatch 使用被调用符号所属语言注册的打分实现计算匹配分数，未注册时使用默认打分
+func (da *DependencyAnalyzer) ScoreSymbolMatch(language lang.Language, workspace string, callerImports []*codegraphpb.Import, callerFilePath string, calleeFilePath string, calleeSymbolName string, callerSymbolName string) int {
+	return GetSymbolScorer(language).Score(workspace, callerImports, callerFilePath, calleeFilePath, calleeSymbolName, callerSymbolName)
+}
+
+// calculateSymbolMatchScore 默认打分：同文件 > 同目录 > 导入匹配 > 名称、文件名相似度和包路径接近程度
+func calculateSymbolMatchScore(workspace string, callerImports []*codegraphpb.Import, callerFilePath string, calleeFilePath string, calleeSymbolName string, callerSymbolName string) int {
 	// 1、同文件
 	if callerFilePath == calleeFilePath {
 		return 100
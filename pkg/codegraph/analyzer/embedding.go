@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"codebase-indexer/pkg/codegraph/proto"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/types"
+	"strings"
+)
+
+// EmbeddedImports 根据文件元素表中 Go 结构体嵌入、类型别名的关系，返回经由这些类型可以间接访问的包的导入。
+// 结构体嵌入 pkg.Type 或声明 type A = pkg.Type 时，pkg.Type 的方法可以通过该结构体、别名调用，调用方即使没有导入 pkg，
+// 按导入过滤定义时也应把 pkg 视为已导入。限定名按所在文件的导入解析，同包的嵌入已在可见范围内不作处理；
+// 结果不包含 imports 中已有的包
+func EmbeddedImports(tables []*codegraphpb.FileElementTable, imports []*codegraphpb.Import) []*codegraphpb.Import {
+	seen := make(map[string]struct{}, len(imports))
+	for _, imp := range imports {
+		seen[imp.Source] = struct{}{}
+	}
+	var embedded []*codegraphpb.Import
+	for _, table := range tables {
+		for _, e := range table.Elements {
+			if e.ElementType != codegraphpb.ElementType_CLASS {
+				continue
+			}
+			superClasses, err := proto.GetSuperClassesFromExtraData(e.ExtraData)
+			if err != nil {
+				continue
+			}
+			for _, typ := range superClasses {
+				i := strings.LastIndex(typ, types.Dot)
+				if i <= 0 {
+					continue
+				}
+				imp := findImportByQualifier(table.Imports, typ[:i])
+				if imp == nil {
+					continue
+				}
+				if _, ok := seen[imp.Source]; ok {
+					continue
+				}
+				seen[imp.Source] = struct{}{}
+				embedded = append(embedded, &codegraphpb.Import{Name: imp.Name, Source: imp.Source})
+			}
+		}
+	}
+	return embedded
+}
+
+// findImportByQualifier 按类型限定名查找导入：有别名时匹配别名，否则匹配导入路径的最后一段
+func findImportByQualifier(imports []*codegraphpb.Import, qualifier string) *codegraphpb.Import {
+	for _, imp := range imports {
+		if imp.Alias != types.EmptyString {
+			if imp.Alias == qualifier {
+				return imp
+			}
+			continue
+		}
+		name := imp.Name
+		if i := strings.LastIndex(name, types.Dot); i >= 0 {
+			name = name[i+1:]
+		}
+		if name == qualifier {
+			return imp
+		}
+	}
+	return nil
+}
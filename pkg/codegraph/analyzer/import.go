@@ -4,6 +4,7 @@ import (
 	packageclassifier "codebase-indexer/pkg/codegraph/analyzer/package_classifier"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
 	"codebase-indexer/pkg/codegraph/workspace"
 	"context"
 	"path/filepath"
@@ -17,7 +18,7 @@ import (
 // 1、python、js、ts 导入相对路径处理，转为绝对路径。
 // 2、go同包不同文件处理；大小写处理；import部分移除模块名。
 // 3、c/cpp 简单处理，只关心项目内的源码，根据当前文件的using部分，再结合符号名；
-// 4、python、ts、go 别名处理；
+// 4、python、ts、go 别名处理，ts/js 的 tsconfig paths 路径别名改写为真实路径；
 // 5、作用域。
 // 6、将 / 统一转为 .，方便后续处理。
 func (da *DependencyAnalyzer) PreprocessImports(ctx context.Context,
@@ -56,6 +57,11 @@ func (da *DependencyAnalyzer) processImportByLanguage(imp *resolver.Import, lang
 		}
 	}
 
+	// ts/js 路径别名（tsconfig paths）改写为真实的绝对路径，之后与相对路径导入一样处理
+	if source, ok := da.PackageClassifier.RewriteImport(language, imp.Source, project); ok {
+		imp.Source = source
+	}
+
 	// 处理相对路径
 	if strings.HasPrefix(imp.Source, types.Dot) {
 		imp.Source = da.resolveRelativePath(imp.Source, imp.Path)
@@ -110,3 +116,53 @@ func IsFilePathInImportPackage(filePath string, imp *codegraphpb.Import) bool {
 	// 如果满足则说明，filePath(绝对路径)是imp包(相对路径)下面的一个文件，则大概率可以说明文件A可导入文件B里面的符号
 	return strings.Contains(filePath, imp.Name) || strings.Contains(filePath, imp.Source)
 }
+
+// ImportConfidence 符号所在文件与当前文件导入的匹配置信度
+type ImportConfidence int
+
+const (
+	// ImportConfidenceNone 与导入无关
+	ImportConfidenceNone ImportConfidence = iota
+	// ImportConfidencePartial 文件路径包含导入路径
+	ImportConfidencePartial
+	// ImportConfidenceExact 同文件、同包，或所在包（文件）与导入路径完全匹配
+	ImportConfidenceExact
+)
+
+// ImportMatchConfidence 计算 defPath 处的定义被 filePath 文件通过 imports 引用的置信度
+func ImportMatchConfidence(filePath string, imports []*codegraphpb.Import, defPath string) ImportConfidence {
+	if defPath == filePath || utils.IsSameParentDir(defPath, filePath) {
+		return ImportConfidenceExact
+	}
+	dir := toDottedPath(filepath.Dir(defPath))
+	file := toDottedPath(strings.TrimSuffix(defPath, filepath.Ext(defPath)))
+	confidence := ImportConfidenceNone
+	for _, imp := range imports {
+		if imp == nil {
+			continue
+		}
+		for _, p := range []string{imp.Name, imp.Source} {
+			if p == types.EmptyString || p == types.Dot {
+				continue
+			}
+			if hasDottedSuffix(dir, p) || hasDottedSuffix(file, p) {
+				return ImportConfidenceExact
+			}
+		}
+		if IsFilePathInImportPackage(defPath, imp) {
+			confidence = ImportConfidencePartial
+		}
+	}
+	return confidence
+}
+
+// toDottedPath 将路径分隔符统一转为 .，与预处理后的导入路径格式一致
+func toDottedPath(path string) string {
+	path = strings.ReplaceAll(path, types.WindowsSeparator, types.Dot)
+	return strings.ReplaceAll(path, types.UnixSeparator, types.Dot)
+}
+
+// hasDottedSuffix path 以完整的 suffix 段结尾，避免 a.userservice 匹配 service
+func hasDottedSuffix(path, suffix string) bool {
+	return path == suffix || strings.HasSuffix(path, types.Dot+suffix)
+}
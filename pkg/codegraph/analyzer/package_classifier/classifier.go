@@ -7,6 +7,12 @@ type Classifier interface {
 	Classify(packageName string, project *workspace.Project) PackageType
 }
 
+// ImportRewriter 分类器可选实现的接口，将导入路径改写为项目内的真实路径（如 TypeScript 路径别名），
+// 无需改写时返回 false
+type ImportRewriter interface {
+	RewriteImport(importPath string, project *workspace.Project) (string, bool)
+}
+
 // ClassifierFactory 分类器工厂接口
 type ClassifierFactory interface {
 	CreateClassifier() Classifier
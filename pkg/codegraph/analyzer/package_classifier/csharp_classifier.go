@@ -0,0 +1,62 @@
+package packageclassifier
+
+import (
+	"codebase-indexer/pkg/codegraph/workspace"
+	"strings"
+)
+
+// CSharpClassifier C#包（命名空间）分类器
+type CSharpClassifier struct {
+	systemPrefixes map[string]bool
+}
+
+// NewCSharpClassifier 创建C#分类器
+func NewCSharpClassifier() *CSharpClassifier {
+	classifier := &CSharpClassifier{
+		systemPrefixes: make(map[string]bool),
+	}
+
+	// 加载系统命名空间
+	defaultPrefixes := []string{
+		"System", "Microsoft", "Windows", "Mono",
+	}
+
+	for _, prefix := range defaultPrefixes {
+		classifier.systemPrefixes[prefix] = true
+	}
+
+	return classifier
+}
+
+func (c *CSharpClassifier) Classify(packageName string, project *workspace.Project) PackageType {
+	// 同一程序集（项目）的命名空间视为项目内包，优先于系统命名空间判断，避免 Microsoft.* 项目被误判
+	if project != nil {
+		for _, namespace := range project.CSharpNamespaces {
+			if namespace != "" && isCSharpSubNamespace(packageName, namespace) {
+				return ProjectPackage
+			}
+		}
+	}
+
+	// 检查是否为系统命名空间
+	for prefix := range c.systemPrefixes {
+		if isCSharpSubNamespace(packageName, prefix) {
+			return SystemPackage
+		}
+	}
+
+	// 其他情况视为未知包
+	return UnknownPackage
+}
+
+// isCSharpSubNamespace 判断命名空间是否等于或位于 parent 命名空间之下
+func isCSharpSubNamespace(namespace, parent string) bool {
+	return namespace == parent || strings.HasPrefix(namespace, parent+".")
+}
+
+// CSharpClassifierFactory C#分类器工厂
+type CSharpClassifierFactory struct{}
+
+func (f *CSharpClassifierFactory) CreateClassifier() Classifier {
+	return NewCSharpClassifier()
+}
@@ -2,6 +2,7 @@ package packageclassifier
 
 import (
 	"codebase-indexer/pkg/codegraph/workspace"
+	"os"
 	"strings"
 )
 
@@ -46,6 +47,11 @@ func (js *JavaScriptClassifier) Classify(packageName string, project *workspace.
 		return ProjectPackage
 	}
 
+	// 匹配 tsconfig paths 的别名导入指向项目内文件
+	if alias, _ := matchTsPathAlias(packageName, project); alias != nil {
+		return ProjectPackage
+	}
+
 	// 检查是否为项目内包（通过 project.JsPackages 判断）
 	if project != nil && len(project.JsPackages) > 0 {
 		for _, jsPackage := range project.JsPackages {
@@ -59,6 +65,11 @@ func (js *JavaScriptClassifier) Classify(packageName string, project *workspace.
 	return UnknownPackage
 }
 
+// RewriteImport 将匹配 jsconfig/tsconfig paths 的别名导入改写为真实路径
+func (js *JavaScriptClassifier) RewriteImport(importPath string, project *workspace.Project) (string, bool) {
+	return resolveTsPathAlias(importPath, project)
+}
+
 // JavaScriptClassifierFactory JavaScript分类器工厂
 type JavaScriptClassifierFactory struct{}
 
@@ -103,6 +114,11 @@ func (ts *TypeScriptClassifier) Classify(packageName string, project *workspace.
 		return ProjectPackage
 	}
 
+	// 匹配 tsconfig paths 的别名导入指向项目内文件
+	if alias, _ := matchTsPathAlias(packageName, project); alias != nil {
+		return ProjectPackage
+	}
+
 	// 检查是否为项目内包（通过 project.JsPackages 判断）
 	if project != nil && len(project.JsPackages) > 0 {
 		for _, jsPackage := range project.JsPackages {
@@ -116,9 +132,84 @@ func (ts *TypeScriptClassifier) Classify(packageName string, project *workspace.
 	return UnknownPackage
 }
 
+// RewriteImport 将匹配 tsconfig paths 的别名导入改写为真实路径
+func (ts *TypeScriptClassifier) RewriteImport(importPath string, project *workspace.Project) (string, bool) {
+	return resolveTsPathAlias(importPath, project)
+}
+
 // TypeScriptClassifierFactory TypeScript分类器工厂
 type TypeScriptClassifierFactory struct{}
 
 func (f *TypeScriptClassifierFactory) CreateClassifier() Classifier {
 	return NewTypeScriptClassifier()
 }
+
+// tsModuleExtensions 别名目标省略扩展名时依次尝试的扩展名，.d.ts 在 .ts 之前以便去掉完整的扩展名
+var tsModuleExtensions = []string{".d.ts", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+
+// matchTsPathAlias 查找与导入路径匹配的别名，返回别名和 * 匹配到的部分。
+// 与 TypeScript 的规则一致：精确匹配优先，其次取 * 之前前缀最长的映射
+func matchTsPathAlias(importPath string, project *workspace.Project) (*workspace.TsPathAlias, string) {
+	if project == nil {
+		return nil, ""
+	}
+	var matched *workspace.TsPathAlias
+	var wildcard string
+	longestPrefix := -1
+	for _, alias := range project.TsPathAliases {
+		prefix, suffix, hasStar := strings.Cut(alias.Pattern, "*")
+		if !hasStar {
+			if alias.Pattern == importPath {
+				return alias, ""
+			}
+			continue
+		}
+		if len(importPath) < len(prefix)+len(suffix) || !strings.HasPrefix(importPath, prefix) ||
+			!strings.HasSuffix(importPath, suffix) {
+			continue
+		}
+		if len(prefix) > longestPrefix {
+			matched, longestPrefix = alias, len(prefix)
+			wildcard = importPath[len(prefix) : len(importPath)-len(suffix)]
+		}
+	}
+	return matched, wildcard
+}
+
+// resolveTsPathAlias 将别名导入改写为真实路径（不含扩展名）。多个映射目标时取第一个存在的文件或目录，都不存在时取第一个
+func resolveTsPathAlias(importPath string, project *workspace.Project) (string, bool) {
+	alias, wildcard := matchTsPathAlias(importPath, project)
+	if alias == nil {
+		return importPath, false
+	}
+	resolved := make([]string, 0, len(alias.Targets))
+	for _, target := range alias.Targets {
+		p := strings.Replace(target, "*", wildcard, 1)
+		for _, ext := range tsModuleExtensions {
+			if strings.HasSuffix(p, ext) {
+				p = strings.TrimSuffix(p, ext)
+				break
+			}
+		}
+		resolved = append(resolved, p)
+	}
+	for _, p := range resolved {
+		if tsModuleExists(p) {
+			return p, true
+		}
+	}
+	return resolved[0], true
+}
+
+// tsModuleExists 路径作为目录或补全扩展名后的文件是否存在
+func tsModuleExists(path string) bool {
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	for _, ext := range tsModuleExtensions {
+		if _, err := os.Stat(path + ext); err == nil {
+			return true
+		}
+	}
+	return false
+}
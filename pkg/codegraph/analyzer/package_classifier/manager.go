@@ -27,6 +27,7 @@ func NewPackageClassifier() *PackageClassifier {
 	classifier.RegisterFactory(lang.CPP, &CppClassifierFactory{})
 	classifier.RegisterFactory(lang.JavaScript, &JavaScriptClassifierFactory{})
 	classifier.RegisterFactory(lang.TypeScript, &TypeScriptClassifierFactory{})
+	classifier.RegisterFactory(lang.CSharp, &CSharpClassifierFactory{})
 
 	return classifier
 }
@@ -57,6 +58,20 @@ func (pc *PackageClassifier) GetClassifier(language lang.Language) (Classifier,
 	return classifier, nil
 }
 
+// RewriteImport 使用语言对应的分类器改写导入路径，分类器未实现 ImportRewriter 或无需改写时返回 false
+func (pc *PackageClassifier) RewriteImport(language lang.Language, importPath string,
+	project *workspace.Project) (string, bool) {
+	classifier, err := pc.GetClassifier(language)
+	if err != nil {
+		return importPath, false
+	}
+	rewriter, ok := classifier.(ImportRewriter)
+	if !ok {
+		return importPath, false
+	}
+	return rewriter.RewriteImport(importPath, project)
+}
+
 // ClassifyPackage 分类包
 func (pc *PackageClassifier) ClassifyPackage(language lang.Language, packageName string,
 	project *workspace.Project) (PackageType, error) {
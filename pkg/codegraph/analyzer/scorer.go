@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"sync"
+)
+
+// SymbolScorer 计算调用者与被调用符号定义的匹配分数，分数越高越可能是真实的调用关系，用于调用链中调用者的排序和截断
+type SymbolScorer interface {
+	Score(workspace string, callerImports []*codegraphpb.Import, callerFilePath string, calleeFilePath string,
+		calleeSymbolName string, callerSymbolName string) int
+}
+
+// SymbolScorerFunc 函数形式的 SymbolScorer
+type SymbolScorerFunc func(workspace string, callerImports []*codegraphpb.Import, callerFilePath string,
+	calleeFilePath string, calleeSymbolName string, callerSymbolName string) int
+
+// Score 调用函数本身
+func (f SymbolScorerFunc) Score(workspace string, callerImports []*codegraphpb.Import, callerFilePath string,
+	calleeFilePath string, calleeSymbolName string, callerSymbolName string) int {
+	return f(workspace, callerImports, callerFilePath, calleeFilePath, calleeSymbolName, callerSymbolName)
+}
+
+// DefaultSymbolScorer 未注册语言打分实现时使用的默认打分
+var DefaultSymbolScorer SymbolScorer = SymbolScorerFunc(calculateSymbolMatchScore)
+
+var (
+	symbolScorersMu sync.RWMutex
+	symbolScorers   = make(map[lang.Language]SymbolScorer)
+)
+
+// RegisterSymbolScorer 为语言注册打分实现，覆盖已注册的实现，scorer 为 nil 时恢复使用默认打分
+func RegisterSymbolScorer(language lang.Language, scorer SymbolScorer) {
+	symbolScorersMu.Lock()
+	defer symbolScorersMu.Unlock()
+	if scorer == nil {
+		delete(symbolScorers, language)
+		return
+	}
+	symbolScorers[language] = scorer
+}
+
+// GetSymbolScorer 返回语言注册的打分实现，未注册时返回 DefaultSymbolScorer
+func GetSymbolScorer(language lang.Language) SymbolScorer {
+	symbolScorersMu.RLock()
+	defer symbolScorersMu.RUnlock()
+	if scorer, ok := symbolScorers[language]; ok {
+		return scorer
+	}
+	return DefaultSymbolScorer
+}
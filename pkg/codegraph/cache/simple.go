@@ -20,6 +20,21 @@ type LRUCache[T any] struct {
 	maxCapacity int                 // 最大元素数量（超过则淘汰）
 	size        int                 // 当前元素数量
 	mu          sync.Mutex          // 互斥锁，保证并发安全
+	stats       Stats               // 命中、未命中、淘汰计数
+}
+
+// Stats 缓存计数，Purge 不会清零
+type Stats struct {
+	Hits      uint64 `json:"hits"`      // Get 命中次数
+	Misses    uint64 `json:"misses"`    // Get 未命中次数
+	Evictions uint64 `json:"evictions"` // 因超出容量被淘汰的元素数
+}
+
+// Add 累加另一份计数
+func (s *Stats) Add(other Stats) {
+	s.Hits += other.Hits
+	s.Misses += other.Misses
+	s.Evictions += other.Evictions
 }
 
 // NewLRUCache 创建新的LRU缓存
@@ -74,9 +89,11 @@ func (c *LRUCache[T]) Get(key string) (T, bool) {
 
 	if node, ok := c.cache[key]; ok {
 		c.moveToHead(node)
+		c.stats.Hits++
 		return node.value, true
 	}
 
+	c.stats.Misses++
 	var zero T
 	return zero, false
 }
@@ -107,9 +124,25 @@ func (c *LRUCache[T]) Put(key string, value T) {
 		removedNode := c.removeTail()
 		delete(c.cache, removedNode.key)
 		c.size--
+		c.stats.Evictions++
 	}
 }
 
+// Remove 并发安全的删除操作，返回key是否存在
+func (c *LRUCache[T]) Remove(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node, ok := c.cache[key]
+	if !ok {
+		return false
+	}
+	c.removeNode(node)
+	delete(c.cache, key)
+	c.size--
+	return true
+}
+
 // Purge 清理所有缓存
 func (c *LRUCache[T]) Purge() {
 	c.mu.Lock()
@@ -131,5 +164,35 @@ func (c *LRUCache[T]) Len() int {
 
 // MaxCapacity 返回最大容量限制
 func (c *LRUCache[T]) MaxCapacity() int {
-	return c.maxCapacity // 只读字段，无需加锁
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.maxCapacity
+}
+
+// Resize 调整最大容量，超出新容量的最少使用节点被淘汰，返回淘汰数量
+// maxCapacity: 新的最大元素数量（小于1时按1处理）
+func (c *LRUCache[T]) Resize(maxCapacity int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maxCapacity < 1 {
+		maxCapacity = 1
+	}
+	c.maxCapacity = maxCapacity
+	evicted := 0
+	for c.size > c.maxCapacity {
+		removedNode := c.removeTail()
+		delete(c.cache, removedNode.key)
+		c.size--
+		evicted++
+	}
+	c.stats.Evictions += uint64(evicted)
+	return evicted
+}
+
+// Stats 返回命中、未命中、淘汰计数的快照
+func (c *LRUCache[T]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
 }
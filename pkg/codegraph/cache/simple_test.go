@@ -237,3 +237,133 @@ func TestNewLRUCacheLenAndMaxCapacity(t *testing.T) {
 		t.Errorf("MaxCapacity() = %d, 期望 %d", got, maxCapacity)
 	}
 }
+
+// TestLRUCacheResize 验证Resize()淘汰最少使用节点并限制后续写入
+func TestLRUCacheResize(t *testing.T) {
+	testCases := []struct {
+		name            string
+		newCapacity     int
+		expectedEvicted int
+		expectedLen     int
+		expectedMaxCap  int
+		expectedKept    []string
+	}{
+		{
+			name:            "缩小容量：淘汰最少使用的节点",
+			newCapacity:     2,
+			expectedEvicted: 2,
+			expectedLen:     2,
+			expectedMaxCap:  2,
+			expectedKept:    []string{"a", "d"},
+		},
+		{
+			name:            "扩大容量：不淘汰节点",
+			newCapacity:     8,
+			expectedEvicted: 0,
+			expectedLen:     4,
+			expectedMaxCap:  8,
+			expectedKept:    []string{"a", "b", "c", "d"},
+		},
+		{
+			name:            "边界情况：容量小于1时按1处理",
+			newCapacity:     0,
+			expectedEvicted: 3,
+			expectedLen:     1,
+			expectedMaxCap:  1,
+			expectedKept:    []string{"a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := NewLRUCache[int](0, 4)
+			for i, key := range []string{"a", "b", "c", "d"} {
+				cache.Put(key, i)
+			}
+			// 访问a，使其成为最近使用
+			cache.Get("a")
+
+			if got := cache.Resize(tc.newCapacity); got != tc.expectedEvicted {
+				t.Errorf("Resize() = %d, 期望 %d", got, tc.expectedEvicted)
+			}
+			if got := cache.Len(); got != tc.expectedLen {
+				t.Errorf("Len() = %d, 期望 %d", got, tc.expectedLen)
+			}
+			if got := cache.MaxCapacity(); got != tc.expectedMaxCap {
+				t.Errorf("MaxCapacity() = %d, 期望 %d", got, tc.expectedMaxCap)
+			}
+			for _, key := range tc.expectedKept {
+				if _, ok := cache.Get(key); !ok {
+					t.Errorf("期望保留 %s", key)
+				}
+			}
+
+			// 调整后写入仍受新容量限制
+			cache.Put("e", 4)
+			if got := cache.Len(); got > tc.expectedMaxCap {
+				t.Errorf("写入后 Len() = %d, 超过容量 %d", got, tc.expectedMaxCap)
+			}
+		})
+	}
+}
+
+// TestLRUCacheRemove 验证Remove()删除节点并维护链表
+func TestLRUCacheRemove(t *testing.T) {
+	cache := NewLRUCache[int](0, 3)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+
+	if !cache.Remove("a") {
+		t.Error("Remove(a) 期望返回 true")
+	}
+	if cache.Remove("missing") {
+		t.Error("Remove(missing) 期望返回 false")
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("删除后不应再获取到 a")
+	}
+	if got := cache.Len(); got != 1 {
+		t.Errorf("Len() = %d, 期望 1", got)
+	}
+
+	// 删除后容量可以继续写入，不会淘汰 b
+	cache.Put("c", 3)
+	cache.Put("d", 4)
+	if _, ok := cache.Get("b"); !ok {
+		t.Error("期望保留 b")
+	}
+}
+
+// TestLRUCacheStats 验证命中、未命中、淘汰计数
+func TestLRUCacheStats(t *testing.T) {
+	cache := NewLRUCache[int](0, 2)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Get("a")       // 命中
+	cache.Get("missing") // 未命中
+	cache.Put("a", 10)   // 更新不淘汰
+	cache.Put("c", 3)    // 淘汰 b
+	cache.Get("b")       // 未命中
+	cache.Get("c")       // 命中
+
+	expected := Stats{Hits: 2, Misses: 2, Evictions: 1}
+	if got := cache.Stats(); got != expected {
+		t.Errorf("Stats() = %+v, 期望 %+v", got, expected)
+	}
+
+	// 缩容淘汰计入 Evictions，Remove 和 Purge 不计入也不清零
+	cache.Resize(1)
+	cache.Remove("a")
+	cache.Purge()
+	expected.Evictions = 2
+	if got := cache.Stats(); got != expected {
+		t.Errorf("Resize/Purge 后 Stats() = %+v, 期望 %+v", got, expected)
+	}
+
+	var total Stats
+	total.Add(expected)
+	total.Add(Stats{Hits: 1, Misses: 1, Evictions: 1})
+	if total != (Stats{Hits: 3, Misses: 3, Evictions: 3}) {
+		t.Errorf("Add() = %+v", total)
+	}
+}
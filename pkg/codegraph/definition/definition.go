@@ -32,14 +32,13 @@ func (s *DefParser) Parse(ctx context.Context, codeFile *types.SourceFile, opts
 	if err != nil {
 		return nil, err
 	}
-	query, ok := parser.DefinitionQueries[langConf.Language]
+	sitterLanguage, query, ok := parser.DefinitionQueryOf(langConf, codeFile.Path)
 	if !ok {
 		return nil, lang.ErrQueryNotFound
 	}
 
 	sitterParser := sitter.NewParser()
 	defer sitterParser.Close()
-	sitterLanguage := langConf.SitterLanguage()
 	if err := sitterParser.SetLanguage(sitterLanguage); err != nil {
 		return nil, err
 	}
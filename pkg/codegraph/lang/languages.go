@@ -8,7 +8,7 @@ import (
 
 	//sitterkotlin "github.com/tree-sitter-grammars/tree-sitter-kotlin/bindings/go"
 	sitter "github.com/tree-sitter/go-tree-sitter"
-	//sittercsharp "github.com/tree-sitter/tree-sitter-c-sharp/bindings/go"
+	sittercsharp "github.com/tree-sitter/tree-sitter-c-sharp/bindings/go"
 
 	sittercpp "github.com/tree-sitter/tree-sitter-cpp/bindings/go"
 	sittergo "github.com/tree-sitter/tree-sitter-go/bindings/go"
@@ -47,6 +47,29 @@ type TreeSitterParser struct {
 	Language       Language
 	SitterLanguage func() *sitter.Language
 	SupportedExts  []string
+	// Dialects 语法不同的语言变体，变体的扩展名使用变体语法解析
+	Dialects []*Dialect
+}
+
+// Dialect 语言变体，如 TypeScript 的 TSX。变体沿用所属语言的解析器和查询规则，
+// 并追加 queries 下以变体名命名的查询规则和变体共用的查询规则（如 JSX 元素）
+type Dialect struct {
+	Name           string
+	SitterLanguage func() *sitter.Language
+	SupportedExts  []string
+}
+
+// DialectOf 返回文件所属的语言变体，不属于任何变体时返回 nil
+func (tp *TreeSitterParser) DialectOf(path string) *Dialect {
+	ext := filepath.Ext(path)
+	for _, d := range tp.Dialects {
+		for _, supportedExt := range d.SupportedExts {
+			if supportedExt == ext {
+				return d
+			}
+		}
+	}
+	return nil
 }
 
 // treeSitterParsers 定义了所有支持的语言配置
@@ -85,6 +108,15 @@ var treeSitterParsers = []*TreeSitterParser{
 			return sitter.NewLanguage(sittertypescript.LanguageTypescript())
 		},
 		SupportedExts: []string{".ts", ".tsx"},
+		Dialects: []*Dialect{
+			{
+				Name: "tsx",
+				SitterLanguage: func() *sitter.Language {
+					return sitter.NewLanguage(sittertypescript.LanguageTSX())
+				},
+				SupportedExts: []string{".tsx"},
+			},
+		},
 	},
 	//{
 	//	Language: Rust,
@@ -100,13 +132,13 @@ var treeSitterParsers = []*TreeSitterParser{
 		},
 		SupportedExts: []string{".cpp", ".cc", ".cxx", ".hpp", ".h", ".c"},
 	},
-	//{
-	//	Language: CSharp,
-	//	SitterLanguage: func() *sitter.Language {
-	//		return sitter.NewLanguage(sittercsharp.Language())
-	//	},
-	//	SupportedExts: []string{".cs"},
-	//},
+	{
+		Language: CSharp,
+		SitterLanguage: func() *sitter.Language {
+			return sitter.NewLanguage(sittercsharp.Language())
+		},
+		SupportedExts: []string{".cs"},
+	},
 	//{
 	//	Language: Ruby,
 	//	SitterLanguage: func() *sitter.Language {
@@ -154,7 +186,18 @@ func getSitterParserByExt(ext string) *TreeSitterParser {
 	return nil
 }
 
+// InferLanguage 根据扩展名推断文件语言，没有扩展名或扩展名未知时读取文件首行按 shebang 识别
 func InferLanguage(path string) (Language, error) {
+	langConf, err := GetSitterParserByFilePath(path)
+	if err != nil {
+		return types.EmptyString, err
+	}
+	return langConf.Language, nil
+}
+
+// InferLanguageByExt 只根据扩展名推断文件语言，不读取文件，没有扩展名时返回 ErrFileExtNotFound。
+// 用于文件可能已删除或批量查找的场景，没有扩展名的脚本可结合 ScriptLanguages 查找
+func InferLanguageByExt(path string) (Language, error) {
 	ext := filepath.Ext(path)
 	if ext == types.EmptyString {
 		return types.EmptyString, ErrFileExtNotFound
@@ -166,16 +209,19 @@ func InferLanguage(path string) (Language, error) {
 	return langConf.Language, nil
 }
 
+// GetSitterParserByFilePath 根据扩展名获取语言配置，没有扩展名时读取文件首行按 shebang 识别，扩展名未知时不读取文件
 func GetSitterParserByFilePath(path string) (*TreeSitterParser, error) {
 	ext := filepath.Ext(path)
-	if ext == types.EmptyString {
-		return nil, ErrFileExtNotFound
-	}
-	langConf := getSitterParserByExt(ext)
-	if langConf == nil {
+	if ext != types.EmptyString {
+		if langConf := getSitterParserByExt(ext); langConf != nil {
+			return langConf, nil
+		}
 		return nil, ErrLanguageParserNotFound
 	}
-	return langConf, nil
+	if language, ok := InferScriptLanguage(path); ok {
+		return GetSitterParserByLanguage(language)
+	}
+	return nil, ErrFileExtNotFound
 }
 
 func IsUnSupportedFileError(err error) bool {
@@ -0,0 +1,105 @@
+package lang
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxShebangLength 读取文件开头用于识别 shebang 的最大字节数
+const maxShebangLength = 256
+
+// shebangInterpreters shebang 解释器到语言的映射，解释器名已去掉版本号后缀（如 python3.11 -> python）。
+// 没有对应解析器的语言（如 bash、sh）不在此列，这类脚本仍按不支持的文件跳过
+var shebangInterpreters = map[string]Language{
+	"python":  Python,
+	"pypy":    Python,
+	"node":    JavaScript,
+	"nodejs":  JavaScript,
+	"deno":    TypeScript,
+	"ts-node": TypeScript,
+	"tsx":     TypeScript,
+	"bun":     JavaScript,
+	"ruby":    Ruby,
+	"php":     PHP,
+}
+
+// LanguageOfShebang 根据首行 shebang 识别脚本语言，支持 #!/usr/bin/python3、#!/usr/bin/env node、
+// #!/usr/bin/env -S deno run 等写法，无法识别时返回 false
+func LanguageOfShebang(line string) (Language, bool) {
+	line, ok := strings.CutPrefix(line, "#!")
+	if !ok {
+		return "", false
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		// env 后跳过选项（如 -S）和环境变量赋值，第一个参数才是解释器
+		interpreter = ""
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "-") || strings.Contains(f, "=") {
+				continue
+			}
+			interpreter = filepath.Base(f)
+			break
+		}
+	}
+	interpreter = strings.TrimRight(interpreter, "0123456789.")
+	language, ok := shebangInterpreters[interpreter]
+	return language, ok
+}
+
+// ScriptLanguages 返回没有扩展名的脚本可能被索引成的语言，即 shebang 可识别且有解析器的语言，按名称排序
+func ScriptLanguages() []Language {
+	languages := make([]Language, 0, len(shebangInterpreters))
+	for _, language := range shebangInterpreters {
+		if slices.Contains(languages, language) {
+			continue
+		}
+		if _, err := GetSitterParserByLanguage(language); err != nil {
+			continue
+		}
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// InferScriptLanguage 读取没有扩展名的文件首行，按 shebang 识别有解析器的语言。
+// 有扩展名的文件不读取，直接返回 false
+func InferScriptLanguage(path string) (Language, bool) {
+	if filepath.Ext(path) != "" {
+		return "", false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	buf := make([]byte, maxShebangLength)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && n == 0 {
+		return "", false
+	}
+	buf = buf[:n]
+	if !bytes.HasPrefix(buf, []byte("#!")) {
+		return "", false
+	}
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf = buf[:i]
+	}
+	language, ok := LanguageOfShebang(strings.TrimSpace(string(buf)))
+	if !ok {
+		return "", false
+	}
+	if _, err := GetSitterParserByLanguage(language); err != nil {
+		return "", false
+	}
+	return language, true
+}
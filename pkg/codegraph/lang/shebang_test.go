@@ -0,0 +1,109 @@
+package lang
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageOfShebang(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   Language
+		wantOk bool
+	}{
+		{name: "直接指定解释器", line: "#!/usr/bin/python3", want: Python, wantOk: true},
+		{name: "带版本号的解释器", line: "#!/usr/local/bin/python3.11 -u", want: Python, wantOk: true},
+		{name: "env 指定解释器", line: "#!/usr/bin/env node", want: JavaScript, wantOk: true},
+		{name: "env -S 和环境变量", line: "#!/usr/bin/env -S NODE_ENV=prod deno run", want: TypeScript, wantOk: true},
+		{name: "#! 后有空格", line: "#! /usr/bin/env python", want: Python, wantOk: true},
+		{name: "没有解析器的 shell", line: "#!/bin/bash", wantOk: false},
+		{name: "只有 env", line: "#!/usr/bin/env", wantOk: false},
+		{name: "不是 shebang", line: "# comment", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LanguageOfShebang(tt.line)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInferLanguageByShebang(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"manage":     "#!/usr/bin/env python3\r\nprint('hi')\n",
+		"server.cgi": "#!/usr/bin/node\nconsole.log(1)\n",
+		"deploy":     "#!/bin/bash\necho hi\n",
+		"README":     "no shebang\n",
+		"main.go":    "#!/usr/bin/env python\npackage main\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0755))
+	}
+	tests := []struct {
+		name    string
+		file    string
+		want    Language
+		wantErr error
+	}{
+		{name: "没有扩展名的 Python 脚本", file: "manage", want: Python},
+		{name: "扩展名未知时不读取 shebang", file: "server.cgi", wantErr: ErrLanguageParserNotFound},
+		{name: "扩展名优先于 shebang", file: "main.go", want: Go},
+		{name: "没有解析器的 shell 脚本", file: "deploy", wantErr: ErrFileExtNotFound},
+		{name: "没有 shebang", file: "README", wantErr: ErrFileExtNotFound},
+		{name: "文件不存在", file: "missing.txt", wantErr: ErrLanguageParserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InferLanguage(filepath.Join(dir, tt.file))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInferLanguageByExt(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "manage")
+	require.NoError(t, os.WriteFile(script, []byte("#!/usr/bin/env python3\n"), 0755))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    Language
+		wantErr error
+	}{
+		{name: "按扩展名推断", path: filepath.Join(dir, "main.go"), want: Go},
+		{name: "没有扩展名时不读取 shebang", path: script, wantErr: ErrFileExtNotFound},
+		{name: "扩展名未知", path: filepath.Join(dir, "server.cgi"), wantErr: ErrLanguageParserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InferLanguageByExt(tt.path)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScriptLanguages(t *testing.T) {
+	languages := ScriptLanguages()
+	assert.Contains(t, languages, Python)
+	assert.Contains(t, languages, JavaScript)
+	assert.NotContains(t, languages, Go)
+	assert.IsNonDecreasing(t, languages)
+}
@@ -0,0 +1,82 @@
+package lang
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// testFileRule 语言的测试文件约定，文件名前缀、后缀（含扩展名）或所在目录任一匹配即视为测试文件
+type testFileRule struct {
+	prefixes []string
+	suffixes []string
+	dirs     []string
+}
+
+// testFileRules 各语言的测试文件约定，未配置的语言不识别测试文件
+var testFileRules = map[Language]testFileRule{
+	Go: {
+		suffixes: []string{"_test.go"},
+	},
+	Java: {
+		suffixes: []string{"Test.java", "Tests.java", "IT.java"},
+		dirs:     []string{"src/test"},
+	},
+	Python: {
+		prefixes: []string{"test_"},
+		suffixes: []string{"_test.py"},
+		dirs:     []string{"tests"},
+	},
+	JavaScript: {
+		suffixes: []string{".test.js", ".spec.js", ".test.jsx", ".spec.jsx"},
+		dirs:     []string{"__tests__"},
+	},
+	TypeScript: {
+		suffixes: []string{".test.ts", ".spec.ts", ".test.tsx", ".spec.tsx"},
+		dirs:     []string{"__tests__"},
+	},
+	CPP: {
+		suffixes: []string{"_test.cc", "_test.cpp", "_unittest.cc", "_unittest.cpp"},
+	},
+	CSharp: {
+		suffixes: []string{"Test.cs", "Tests.cs"},
+	},
+	Kotlin: {
+		suffixes: []string{"Test.kt", "Tests.kt"},
+		dirs:     []string{"src/test"},
+	},
+	Scala: {
+		suffixes: []string{"Test.scala", "Spec.scala", "Suite.scala"},
+		dirs:     []string{"src/test"},
+	},
+}
+
+// IsTestFile 按语言约定判断文件是否为测试文件，如 Go 的 _test.go、Java 的 *Test.java 和 src/test 目录、
+// Python 的 test_*.py、JS/TS 的 *.test.ts 和 __tests__ 目录。不支持的语言返回 false
+func IsTestFile(path string) bool {
+	language, err := InferLanguage(path)
+	if err != nil {
+		return false
+	}
+	rule, ok := testFileRules[language]
+	if !ok {
+		return false
+	}
+	base := filepath.Base(path)
+	for _, prefix := range rule.prefixes {
+		if strings.HasPrefix(base, prefix) {
+			return true
+		}
+	}
+	for _, suffix := range rule.suffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	dir := "/" + filepath.ToSlash(filepath.Dir(path)) + "/"
+	for _, d := range rule.dirs {
+		if strings.Contains(dir, "/"+d+"/") {
+			return true
+		}
+	}
+	return false
+}
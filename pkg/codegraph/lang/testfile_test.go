@@ -0,0 +1,35 @@
+package lang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "Go测试文件", path: "/repo/pkg/util_test.go", want: true},
+		{name: "Go源文件", path: "/repo/pkg/util.go", want: false},
+		{name: "Java测试类", path: "/repo/src/main/java/UserServiceTest.java", want: true},
+		{name: "Java src/test目录", path: "/repo/src/test/java/Fixtures.java", want: true},
+		{name: "Java源文件", path: "/repo/src/main/java/UserService.java", want: false},
+		{name: "Python test_前缀", path: "/repo/app/test_views.py", want: true},
+		{name: "Python tests目录", path: "/repo/tests/conftest.py", want: true},
+		{name: "Python源文件", path: "/repo/app/views.py", want: false},
+		{name: "TS spec文件", path: "/repo/src/app.spec.ts", want: true},
+		{name: "JS __tests__目录", path: "/repo/src/__tests__/app.js", want: true},
+		{name: "TSX源文件", path: "/repo/src/App.tsx", want: false},
+		{name: "C++测试文件", path: "/repo/src/parser_unittest.cc", want: true},
+		{name: "其他语言的测试约定不生效", path: "/repo/src/test_util.go", want: false},
+		{name: "不支持的语言", path: "/repo/README_test.md", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTestFile(tt.path))
+		})
+	}
+}
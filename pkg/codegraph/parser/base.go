@@ -45,25 +45,28 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 		return nil, err
 	}
 
+	sitterLanguage, baseQuery, ok := BaseQueryOf(langParser, sourceFile.Path)
+	if !ok {
+		return nil, lang.ErrQueryNotFound
+	}
+
 	sitterParser := sitter.NewParser()
 	defer sitterParser.Close()
-	sitterLanguage := langParser.SitterLanguage()
 	if err := sitterParser.SetLanguage(sitterLanguage); err != nil {
 		return nil, err
 	}
 
 	content := sourceFile.Content
-	tree := sitterParser.Parse(content, nil)
+	tree := parseContent(ctx, sitterParser, content)
 	if tree == nil {
+		if err = utils.CheckContextCanceled(ctx); err != nil {
+			return nil, fmt.Errorf("parse file %s canceled: %w", sourceFile.Path, err)
+		}
 		return nil, fmt.Errorf("failed to parse file: %s", sourceFile.Path)
 	}
 
 	defer tree.Close()
 
-	baseQuery, ok := BaseQueries[langParser.Language]
-	if !ok {
-		return nil, lang.ErrQueryNotFound
-	}
 	// TODO baseQuery永远不会关闭，影响？
 
 	captureNames := baseQuery.CaptureNames() // 根据scm文件从上到下排列的
@@ -121,18 +124,49 @@ func (p *SourceFileParser) Parse(ctx context.Context,
 		}
 
 	}
+	if langParser.Language == lang.CPP || langParser.Language == lang.C {
+		// 通过宏发生的调用、引用，替换为宏展开后的目标符号
+		resolver.ResolveCppMacroAliases(elements, resolver.CollectCppMacroAliases(tree.RootNode(), content))
+	}
+	if langParser.Language == lang.CSharp {
+		// 同一文件中的 partial 类声明合并为一个类
+		elements = resolver.MergeCSharpPartialClasses(elements)
+	}
 	//TODO 顺序解析，对于使用在前，定义在后的类型，未进行处理，比如函数、方法、全局变量。需要再进行二次解析。
 
+	// 语法错误不影响其余部分的解析，只记录错误节点数，便于解释符号缺失
+	errorCount := countErrorNodes(tree.RootNode())
+	if errorCount > 0 {
+		p.logger.Debug("file %s parsed with %d syntax error nodes", sourceFile.Path, errorCount)
+	}
+
 	// 返回结构信息，包含处理后的定义
 	return &FileElementTable{
-		Path:     sourceFile.Path,
-		Package:  sourcePackage,
-		Imports:  imports,
-		Language: langParser.Language,
-		Elements: elements,
+		Path:            sourceFile.Path,
+		Package:         sourcePackage,
+		Imports:         imports,
+		Language:        langParser.Language,
+		Elements:        elements,
+		HasParseErrors:  errorCount > 0,
+		ParseErrorCount: errorCount,
 	}, nil
 }
 
+// countErrorNodes 统计语法树中 ERROR 和 MISSING 节点的数量，只进入包含错误的子树
+func countErrorNodes(node *sitter.Node) int {
+	if node == nil || !node.HasError() {
+		return 0
+	}
+	count := 0
+	if node.IsError() || node.IsMissing() {
+		count++
+	}
+	for i := uint(0); i < node.ChildCount(); i++ {
+		count += countErrorNodes(node.Child(i))
+	}
+	return count
+}
+
 func (p *SourceFileParser) processNode(
 	ctx context.Context,
 	language lang.Language,
@@ -178,3 +212,18 @@ func isSamePosition(source []int32, target []int32) bool {
 	}
 	return true
 }
+
+// parseContent 解析源码，ctx 取消或超时后 tree-sitter 在下一次进度回调时中断解析并返回 nil
+func parseContent(ctx context.Context, sitterParser *sitter.Parser, content []byte) *sitter.Tree {
+	length := len(content)
+	return sitterParser.ParseWithOptions(func(i int, _ sitter.Point) []byte {
+		if i < length {
+			return content[i:]
+		}
+		return []byte{}
+	}, nil, &sitter.ParseOptions{
+		ProgressCallback: func(sitter.ParseState) bool {
+			return ctx.Err() != nil
+		},
+	})
+}
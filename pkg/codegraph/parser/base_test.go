@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func initLogger() logger.Logger {
@@ -193,6 +194,24 @@ func TestJavaBaseParse(t *testing.T) {
 	}
 }
 
+// parseDocRanges 解析源码，按名称返回函数、方法、类定义记录的文档注释范围
+func parseDocRanges(t *testing.T, path string, content string) map[string][]int32 {
+	res, err := NewSourceFileParser(initLogger()).Parse(context.Background(), &types.SourceFile{Path: path, Content: []byte(content)})
+	require.NoError(t, err)
+	docRanges := make(map[string][]int32)
+	for _, element := range res.Elements {
+		switch e := element.(type) {
+		case *resolver.Function:
+			docRanges[e.GetName()] = e.GetDocRange()
+		case *resolver.Method:
+			docRanges[e.GetName()] = e.GetDocRange()
+		case *resolver.Class:
+			docRanges[e.GetName()] = e.GetDocRange()
+		}
+	}
+	return docRanges
+}
+
 func readFile(path string) []byte {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
@@ -266,3 +285,48 @@ func readFile(path string) []byte {
 // 		}
 // 	}
 // }
+
+func TestParseWithSyntaxErrors(t *testing.T) {
+	parser := NewSourceFileParser(initLogger())
+	testCases := []struct {
+		name          string
+		content       string
+		wantErrors    bool
+		wantFunctions []string
+	}{
+		{
+			name:          "语法正确",
+			content:       "package main\n\nfunc Valid() {}\n\nfunc Other() {}\n",
+			wantErrors:    false,
+			wantFunctions: []string{"Valid", "Other"},
+		},
+		{
+			name:          "语法错误不影响其他函数",
+			content:       "package main\n\nfunc Valid() {}\n\nfunc Broken( {\n\tx := \n}\n\nfunc Other() {}\n",
+			wantErrors:    true,
+			wantFunctions: []string{"Valid", "Other"},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parser.Parse(context.Background(), &types.SourceFile{
+				Path:    "test.go",
+				Content: []byte(tt.content),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantErrors, res.HasParseErrors)
+			if tt.wantErrors {
+				assert.Greater(t, res.ParseErrorCount, 0)
+			} else {
+				assert.Zero(t, res.ParseErrorCount)
+			}
+			var functions []string
+			for _, e := range res.Elements {
+				if e.GetType() == types.ElementTypeFunction {
+					functions = append(functions, e.GetName())
+				}
+			}
+			assert.Subset(t, functions, tt.wantFunctions)
+		})
+	}
+}
@@ -586,3 +586,112 @@ func TestResolveFile(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, res.Elements)
 }
+
+func TestCPPResolver_ResolveMacroAlias(t *testing.T) {
+	logger := initLogger()
+	parser := NewSourceFileParser(logger)
+
+	sourceFile := &types.SourceFile{
+		Path:    "testdata/cpp/testmacro.cpp",
+		Content: readFile("testdata/cpp/testmacro.cpp"),
+	}
+	res, err := parser.Parse(context.Background(), sourceFile)
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+
+	// 按调用所在行收集调用名
+	callsByLine := make(map[int32]string)
+	refNames := make(map[string]bool)
+	for _, element := range res.Elements {
+		switch e := element.(type) {
+		case *resolver.Call:
+			callsByLine[e.GetRange()[0]] = e.GetName()
+		case *resolver.Reference:
+			refNames[e.GetName()] = true
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		line     int32 // 从0开始
+		wantCall string
+	}{
+		{"对象宏别名", 20, "compute_sum"},
+		{"多级对象宏别名", 21, "compute_sum"},
+		{"简单函数宏", 22, "log_message"},
+		{"带括号的函数宏", 23, "log_message"},
+		{"复杂宏保持不变", 25, "COMPLEX"},
+		{"循环宏保持不变", 26, "LOOP_A"},
+		{"普通调用", 27, "unknown_func"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantCall, callsByLine[tc.line])
+		})
+	}
+
+	t.Run("类型宏别名", func(t *testing.T) {
+		assert.True(t, refNames["RealBuffer"])
+		assert.False(t, refNames["Buffer"])
+	})
+}
+
+func TestCPPResolver_ResolveConstructor(t *testing.T) {
+	logger := initLogger()
+	parser := NewSourceFileParser(logger)
+
+	sourceFile := &types.SourceFile{
+		Path: "testdata/cpp/constructor.cpp",
+		Content: []byte("class Foo {\npublic:\n  Foo(int x) : x_(x) {}\n  ~Foo() {}\n  int get() { return x_; }\n" +
+			"private:\n  int x_;\n};\n\nFoo::Foo(int a, int b) : x_(a + b) {}\n\nTEST(Suite, Case) {}\n\n" +
+			"void run() {\n  Foo* f = new Foo(1);\n  int n = f->get();\n}\n"),
+	}
+	res, err := parser.Parse(context.Background(), sourceFile)
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+
+	// 按定义所在行收集方法
+	methods := make(map[int32]*resolver.Method)
+	calls := make(map[string]*resolver.Call)
+	for _, element := range res.Elements {
+		switch e := element.(type) {
+		case *resolver.Method:
+			methods[e.GetRange()[0]] = e
+		case *resolver.Call:
+			calls[e.GetName()] = e
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		line            int32 // 从0开始
+		wantName        string
+		wantConstructor bool
+		wantParamCount  int
+	}{
+		{"类内定义的构造函数", 2, "Foo", true, 1},
+		{"普通方法", 4, "get", false, 0},
+		{"类外定义的构造函数", 9, "Foo", true, 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method, ok := methods[tc.line]
+			if !assert.True(t, ok, "method at line %d not found", tc.line) {
+				return
+			}
+			assert.Equal(t, tc.wantName, method.GetName())
+			assert.Equal(t, "Foo", method.Owner)
+			assert.Equal(t, tc.wantConstructor, method.IsConstructor)
+			assert.Len(t, method.Declaration.Parameters, tc.wantParamCount)
+		})
+	}
+
+	t.Run("宏调用不是构造函数", func(t *testing.T) {
+		_, ok := methods[11]
+		assert.False(t, ok)
+	})
+	t.Run("new 表达式为构造调用", func(t *testing.T) {
+		assert.True(t, calls["Foo"].IsConstructor)
+		assert.False(t, calls["get"].IsConstructor)
+	})
+}
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"codebase-indexer/pkg/codegraph/resolver"
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSharpResolver_ResolveImport(t *testing.T) {
+	logger := initLogger()
+	parser := NewSourceFileParser(logger)
+
+	res, err := parser.Parse(context.Background(), &types.SourceFile{
+		Path: "testdata/csharp/Imports.cs",
+		Content: []byte(`using System.Text;
+using static System.Math;
+using Json = Newtonsoft.Json;
+namespace Demo.App;
+`),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, res.Package)
+	assert.Equal(t, "Demo.App", res.Package.GetName())
+
+	imports := make(map[string]string)
+	for _, imp := range res.Imports {
+		imports[imp.Name] = imp.Alias
+	}
+	assert.Equal(t, map[string]string{
+		"System.Text":     "",
+		"System.Math":     "",
+		"Newtonsoft.Json": "Json",
+	}, imports)
+}
+
+func TestCSharpResolver_ResolveClass(t *testing.T) {
+	logger := initLogger()
+	parser := NewSourceFileParser(logger)
+
+	testCases := []struct {
+		name            string
+		content         string
+		className       string
+		superClasses    []string
+		superInterfaces []string
+	}{
+		{
+			name:            "类继承与实现",
+			content:         `public class UserService : BaseService<User>, IUserService, IDisposable { }`,
+			className:       "UserService",
+			superClasses:    []string{"BaseService"},
+			superInterfaces: []string{"IUserService", "IDisposable"},
+		},
+		{
+			name:            "结构体实现接口",
+			content:         `struct Point : IEquatable<Point> { }`,
+			className:       "Point",
+			superInterfaces: []string{"IEquatable"},
+		},
+		{
+			name:         "record主构造",
+			content:      `public record Student(string Name) : Person(Name);`,
+			className:    "Student",
+			superClasses: []string{"Person"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parser.Parse(context.Background(), &types.SourceFile{
+				Path:    "testdata/csharp/Class.cs",
+				Content: []byte(tt.content),
+			})
+			require.NoError(t, err)
+			var found *resolver.Class
+			for _, e := range res.Elements {
+				if c, ok := e.(*resolver.Class); ok && c.Name == tt.className {
+					found = c
+				}
+			}
+			require.NotNil(t, found)
+			assert.Equal(t, tt.superClasses, found.SuperClasses)
+			assert.Equal(t, tt.superInterfaces, found.SuperInterfaces)
+		})
+	}
+}
+
+func TestCSharpResolver_ResolvePartialClass(t *testing.T) {
+	logger := initLogger()
+	parser := NewSourceFileParser(logger)
+
+	owners := make(map[string]string)
+	for _, file := range []string{"testdata/csharp/OrderService.cs", "testdata/csharp/OrderService.Query.cs"} {
+		res, err := parser.Parse(context.Background(), &types.SourceFile{
+			Path:    file,
+			Content: readFile(file),
+		})
+		require.NoError(t, err)
+		classCnt := 0
+		for _, e := range res.Elements {
+			switch elem := e.(type) {
+			case *resolver.Class:
+				assert.Equal(t, "OrderService", elem.Name)
+				classCnt++
+			case *resolver.Method:
+				owners[elem.Name] = elem.Owner
+			}
+		}
+		// 同一文件中的多个 partial 声明合并为一个类
+		assert.Equal(t, 1, classCnt)
+	}
+	// 不同文件中的方法归属到同一个逻辑类
+	assert.Equal(t, map[string]string{
+		"Create":   "OrderService",
+		"Cancel":   "OrderService",
+		"FindById": "OrderService",
+	}, owners)
+}
+
+func TestCSharpResolver_ResolveCall(t *testing.T) {
+	logger := initLogger()
+	parser := NewSourceFileParser(logger)
+
+	res, err := parser.Parse(context.Background(), &types.SourceFile{
+		Path: "testdata/csharp/Call.cs",
+		Content: []byte(`class App {
+    void Run() {
+        var repo = new UserRepository(db);
+        repo.Save(user, true);
+        Log<string>("done");
+    }
+}`),
+	})
+	require.NoError(t, err)
+	calls := make(map[string]*resolver.Call)
+	for _, e := range res.Elements {
+		if c, ok := e.(*resolver.Call); ok {
+			calls[c.Name] = c
+		}
+	}
+	require.Contains(t, calls, "UserRepository")
+	require.Contains(t, calls, "Save")
+	require.Contains(t, calls, "Log")
+	assert.Equal(t, "repo", calls["Save"].Owner)
+	assert.Len(t, calls["Save"].Parameters, 2)
+	assert.Len(t, calls["UserRepository"].Parameters, 1)
+}
@@ -13,6 +13,10 @@ type FileElementTable struct {
 	Imports   []*resolver.Import
 	Language  lang.Language
 	Elements  []resolver.Element
+	// HasParseErrors 语法树中存在 ERROR/MISSING 节点，Elements 只包含能正常解析的部分
+	HasParseErrors bool
+	// ParseErrorCount 语法树中 ERROR/MISSING 节点的数量
+	ParseErrorCount int
 }
 
 func newRootElement(elementTypeValue string, rootIndex uint32) resolver.Element {
@@ -733,6 +733,44 @@ func main() {
 	}
 }
 
+func TestGoResolver_ResolveCallRange(t *testing.T) {
+	logger := initLogger()
+	parser := NewSourceFileParser(logger)
+
+	sourceFile := &types.SourceFile{
+		Path: "testdata/call_range.go",
+		Content: []byte(`package main
+
+import "fmt"
+
+func main() {
+	run(1, 2)
+	fmt.Println("hello")
+}
+
+func run(a, b int) {}
+`),
+	}
+	res, err := parser.Parse(context.Background(), sourceFile)
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+
+	// 调用的范围为被调用的标识符，而不是整个调用表达式
+	expected := map[string][]int32{
+		"run":     {5, 1, 5, 4},
+		"Println": {6, 5, 6, 12},
+	}
+	found := make(map[string][]int32)
+	for _, element := range res.Elements {
+		if call, ok := element.(*resolver.Call); ok {
+			found[call.GetName()] = call.GetRange()
+		}
+	}
+	for name, wantRange := range expected {
+		assert.Equal(t, wantRange, found[name], "Call %s range", name)
+	}
+}
+
 func TestGoResolver_AllResolveMethods(t *testing.T) {
 	logger := initLogger()
 	parser := NewSourceFileParser(logger)
@@ -940,3 +978,29 @@ func createLogger(level int) *FileLogger {
 		}
 	}
 }
+
+func TestGoResolver_DocRange(t *testing.T) {
+	docRanges := parseDocRanges(t, "testdata/doc.go", "package main\n\n"+
+		"// LineDoc 行注释\n// 第二行\nfunc LineDoc() {}\n\n"+
+		"/* BlockDoc 块注释 */\nfunc BlockDoc() {}\n\n"+
+		"// 与函数之间有空行\n\nfunc NoDoc() {}\n\n"+
+		"var x = 1 // 行尾注释\nfunc AfterTrailing() {}\n")
+
+	testCases := []struct {
+		name   string
+		symbol string
+		want   []int32
+	}{
+		{"连续的行注释", "LineDoc", []int32{2, 0, 3, 12}},
+		{"块注释", "BlockDoc", []int32{6, 0, 6, 24}},
+		{"空行隔开的注释不属于函数", "NoDoc", nil},
+		{"上一行的行尾注释不属于函数", "AfterTrailing", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			docRange, ok := docRanges[tc.symbol]
+			assert.True(t, ok, "function %s not found", tc.symbol)
+			assert.Equal(t, tc.want, docRange)
+		})
+	}
+}
@@ -762,3 +762,26 @@ func TestJavaResolver_AllResolveMethods(t *testing.T) {
 		}
 	}
 }
+
+func TestJavaResolver_DocRange(t *testing.T) {
+	docRanges := parseDocRanges(t, "testdata/Doc.java", "/** Calculator. */\npublic class Calc {\n"+
+		"    /**\n     * Adds two numbers.\n     */\n    public int add(int a, int b) {\n        return a + b;\n    }\n\n"+
+		"    public int sub(int a, int b) {\n        return a - b;\n    }\n}\n")
+
+	testCases := []struct {
+		name   string
+		symbol string
+		want   []int32
+	}{
+		{"类的 Javadoc", "Calc", []int32{0, 0, 0, 18}},
+		{"方法的多行 Javadoc", "add", []int32{2, 4, 4, 7}},
+		{"没有 Javadoc 的方法", "sub", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			docRange, ok := docRanges[tc.symbol]
+			assert.True(t, ok, "definition %s not found", tc.symbol)
+			assert.Equal(t, tc.want, docRange)
+		})
+	}
+}
@@ -1953,3 +1953,25 @@ func TestPythonResolver_ResolveCall(t *testing.T) {
 		})
 	}
 }
+
+func TestPythonResolver_DocRange(t *testing.T) {
+	docRanges := parseDocRanges(t, "testdata/doc.py", "def greet(name):\n    \"\"\"Say hello.\"\"\"\n    return name\n\n\n"+
+		"class Greeter:\n    '''\n    Greets people.\n    '''\n\n    def plain(self):\n        # 注释不是 docstring\n        return 1\n")
+
+	testCases := []struct {
+		name   string
+		symbol string
+		want   []int32
+	}{
+		{"函数 docstring", "greet", []int32{1, 4, 1, 20}},
+		{"多行类 docstring", "Greeter", []int32{6, 4, 8, 7}},
+		{"没有 docstring 的方法", "plain", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			docRange, ok := docRanges[tc.symbol]
+			assert.True(t, ok, "definition %s not found", tc.symbol)
+			assert.Equal(t, tc.want, docRange)
+		})
+	}
+}
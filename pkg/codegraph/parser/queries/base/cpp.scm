@@ -232,6 +232,29 @@
               )
 ) @definition.method
 
+;; 类内定义的构造函数，没有返回类型：Foo(int x) : x_(x) {}
+(field_declaration_list
+  (function_definition
+    !type
+    declarator: (function_declarator
+                  declarator: (identifier) @definition.method.name
+                  parameters: (parameter_list) @definition.method.parameters
+                )
+  ) @definition.method
+)
+
+;; 类外定义的构造函数：Foo::Foo(int x) {}
+(function_definition
+  !type
+  declarator: (function_declarator
+                declarator: (qualified_identifier
+                              scope: (namespace_identifier) @definition.method.owner
+                              name: (identifier) @definition.method.name)
+                parameters: (parameter_list) @definition.method.parameters
+              )
+  (#eq? @definition.method.owner @definition.method.name)
+) @definition.method
+
 
 ;; -----------------------------方法/函数调用-----------------------------
 ;; TODO 对象.方法 对象->方法
@@ -1,89 +1,77 @@
-(using_directive
-  (identifier)* @import.name
-  (qualified_name name: (identifier)@import.name )*
-  ) @import
+;; ------------------------- import/package-------------------------
+;; using System.Text; using static System.Math; using Json = Newtonsoft.Json;
+;; 别名、static 等形式在 resolver 中解析
+(using_directive) @import
 
 (namespace_declaration
-  name: (identifier) @namespace.name
-  ) @namespace
+  name: (_) @package.name
+  ) @package
 
+(file_scoped_namespace_declaration
+  name: (_) @package.name
+  ) @package
 
-;; Method definitio
-(method_declaration
-  name: (identifier) @definition.method.name) @definition.method
-
-;; Class declarations
+;; -------------------------Class declarations-------------------------
 (class_declaration
-  name: (identifier) @definition.class.name) @definition.class
+  name: (identifier) @definition.class.name
+  (base_list)? @definition.class.extends
+  ) @definition.class
 
-;; Interface declarations
-(interface_declaration
-  name: (identifier) @definition.interface.name) @definition.interface
+;; record 作为类处理
+(record_declaration
+  name: (identifier) @definition.class.name
+  (base_list)? @definition.class.extends
+  ) @definition.class
 
-;; Struct declarations
 (struct_declaration
-  name: (identifier) @definition.struct.name) @definition.struct
-
-;; Property declarations
-(property_declaration
-  name: (identifier) @definition.property.name) @definition.property
-
-;; Delegate declarations
-(delegate_declaration
-  name: (identifier) @definition.delegate.name) @definition.delegate
-
-;; Event declarations
-(event_declaration
-  name: (identifier) @definition.event.name) @definition.event
-
-;; Constructor declarations
-(constructor_declaration
-  name: (identifier) @definition.constructor.name) @definition.constructor
-
-;; Destructor declarations
-(destructor_declaration
-  name: (identifier) @definition.destructor.name) @definition.destructor
+  name: (identifier) @definition.struct.name
+  (base_list)? @definition.struct.extends
+  ) @definition.struct
 
-;; Enum declarations
 (enum_declaration
-  name: (identifier) @definition.enum.name) @definition.enum
+  name: (identifier) @definition.enum.name
+  ) @definition.enum
 
-;; Field declarations
-(field_declaration
-  (variable_declaration
-    (variable_declarator
-      name: (identifier) @definition.field.name))) @definition.field
-
-;; Indexer declarations
-(indexer_declaration
-  type: (identifier) @definition.indexer.name) @definition.indexer
-
-;; Operator declarations
-(operator_declaration
-  type: (identifier) @definition.operator.name) @definition.operator
-
-;; Type parameter declarations
-(type_parameter
-  name: (identifier) @definition.type_parameter.name) @definition.type_parameter
-
-;; Record definitions (C# 9.0+)
-(record_declaration
-  name: (identifier) @definition.record.name) @definition.record
+;; --------------------------------Interface declarations--------------------------------
+(interface_declaration
+  name: (identifier) @definition.interface.name
+  (base_list)? @definition.interface.extends
+  ) @definition.interface
 
-;; Local function definitions
-(local_function_statement
-  name: (identifier) @definition.local_function.name) @definition.local_function
+;; ---------------------------------method declaration---------------------------------
+;; 返回类型、修饰符在 resolver 中解析
+(method_declaration
+  name: (identifier) @definition.method.name
+  parameters: (parameter_list) @definition.method.parameters
+  ) @definition.method
 
-;; Conversion operator definitions
-(conversion_operator_declaration
-  type: (identifier) @definition.conversion_operator.name) @definition.conversion_operator
+;; --------------------------------Field/Property declaration--------------------------------
+(property_declaration
+  type: (_) @definition.field.type
+  name: (identifier) @definition.field.name
+  ) @definition.field
 
-;; 方法调用
+;; -------------------------------- call expression --------------------------------
+;; Foo(x)
+(invocation_expression
+  function: [
+    (identifier)
+    (generic_name)
+  ] @call.function.name
+  arguments: (argument_list) @call.function.arguments
+  ) @call.function
+
+;; obj.Foo(x)
 (invocation_expression
   function: (member_access_expression
-              expression: (identifier) @call.method.owner
-              name: (identifier) @call.method.name
-              )
+    expression: (_) @call.method.owner
+    name: (_) @call.method.name
+    )
   arguments: (argument_list) @call.method.arguments
-  )
+  ) @call.method
 
+;; new Foo(x)
+(object_creation_expression
+  type: (_) @call.new.type
+  arguments: (argument_list)? @call.new.args
+  ) @call.new
@@ -105,6 +105,9 @@
 
 (type_declaration (type_spec name: (type_identifier) @definition.struct.name type: (struct_type) @definition.struct.type)) @definition.struct
 
+;;类型别名 type A = pkg.B，别名拥有原类型的全部方法，记为嵌入原类型
+(type_declaration (type_alias name: (type_identifier) @definition.struct.name type: [(type_identifier) (qualified_type) (pointer_type) (generic_type)] @definition.struct.type)) @definition.struct
+
 ;;-----------------------------接口定义--------------------------
 
 (type_declaration (type_spec name: (type_identifier) @definition.interface.name type: (interface_type) @definition.interface.type)) @definition.interface
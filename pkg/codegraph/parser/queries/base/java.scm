@@ -50,11 +50,12 @@
   parameters: (formal_parameters) @definition.method.parameters
 ) @definition.method
 
-;; Constructor declarations
-;;(constructor_declaration
-;;  name: (identifier) @definition.constructor.name
-;;  parameters: (formal_parameters) @definition.constructor.parameters
-;;  ) @definition.constructor
+;; Constructor declarations，与类同名且没有返回类型，按方法处理
+(constructor_declaration
+  (modifiers)? @definition.method.modifier
+  name: (identifier) @definition.method.name
+  parameters: (formal_parameters) @definition.method.parameters
+) @definition.method
 
 
 
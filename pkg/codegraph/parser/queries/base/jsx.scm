@@ -0,0 +1,22 @@
+;;-----------------------------JSX组件使用--------------------------
+;; JavaScript 和 TSX 共用，追加在各自的查询规则之后
+
+;; <Button />、<Layout>...</Layout>，小写开头的是原生标签，不作为引用
+(jsx_self_closing_element
+  name: (identifier) @call.struct
+  (#match? @call.struct "^[A-Z]")
+)
+
+(jsx_opening_element
+  name: (identifier) @call.struct
+  (#match? @call.struct "^[A-Z]")
+)
+
+;; <UI.Button />
+(jsx_self_closing_element
+  name: (member_expression) @call.struct
+)
+
+(jsx_opening_element
+  name: (member_expression) @call.struct
+)
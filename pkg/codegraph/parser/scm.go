@@ -19,6 +19,16 @@ const queryExt = ".scm"
 var DefinitionQueries = make(map[lang.Language]*sitter.Query)
 var BaseQueries = make(map[lang.Language]*sitter.Query)
 
+// DialectDefinitionQueries、DialectBaseQueries 语言变体的查询，按变体名索引
+var DialectDefinitionQueries = make(map[string]*sitter.Query)
+var DialectBaseQueries = make(map[string]*sitter.Query)
+
+// sharedQueries 多种语言或变体共用的查询规则，按语言或变体名索引，追加在其自身的查询规则之后
+var sharedQueries = map[string][]string{
+	string(lang.JavaScript): {"jsx"},
+	"tsx":                   {"jsx"},
+}
+
 func init() {
 	if err := loadScm(); err != nil {
 		panic(fmt.Errorf("tree_sitter parser load scm queries err:%v", err))
@@ -52,10 +62,59 @@ func loadScm() error {
 		langParser.Close()
 		BaseQueries[l.Language] = baseQuery
 		DefinitionQueries[l.Language] = defQuery
+
+		for _, d := range l.Dialects {
+			dialectLang := d.SitterLanguage()
+			if DialectBaseQueries[d.Name], err = loadDialectScm(l, d, baseSubDir, dialectLang); err != nil {
+				return err
+			}
+			if DialectDefinitionQueries[d.Name], err = loadDialectScm(l, d, defSubdir, dialectLang); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// loadDialectScm 用变体语法编译所属语言的查询规则，变体存在同名查询文件时追加其中的规则，再追加变体共用的规则
+func loadDialectScm(l *lang.TreeSitterParser, d *lang.Dialect, scmDir string, sitterLang *sitter.Language) (*sitter.Query, error) {
+	queryPath := makeQueryPath(l.Language, scmDir)
+	content, err := scmFS.ReadFile(queryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query file %s for %s: %w", queryPath, d.Name, err)
+	}
+	dialectPath := makeQueryPath(lang.Language(d.Name), scmDir)
+	if extra, err := scmFS.ReadFile(dialectPath); err == nil {
+		content = append(append(content, '\n'), extra...)
+	}
+	content = appendSharedScm(d.Name, scmDir, content)
+	query, queryError := sitter.NewQuery(sitterLang, string(content))
+	if queryError != nil && lang.IsRealQueryErr(queryError) {
+		return nil, fmt.Errorf("failed to parse query file %s for %s: %w", queryPath, d.Name, queryError)
+	}
+	return query, nil
+}
+
+// BaseQueryOf 返回解析文件使用的语法和基础查询，文件属于语言变体时使用变体的语法和查询
+func BaseQueryOf(l *lang.TreeSitterParser, path string) (*sitter.Language, *sitter.Query, bool) {
+	if d := l.DialectOf(path); d != nil {
+		query, ok := DialectBaseQueries[d.Name]
+		return d.SitterLanguage(), query, ok
+	}
+	query, ok := BaseQueries[l.Language]
+	return l.SitterLanguage(), query, ok
+}
+
+// DefinitionQueryOf 返回解析文件使用的语法和定义查询，文件属于语言变体时使用变体的语法和查询
+func DefinitionQueryOf(l *lang.TreeSitterParser, path string) (*sitter.Language, *sitter.Query, bool) {
+	if d := l.DialectOf(path); d != nil {
+		query, ok := DialectDefinitionQueries[d.Name]
+		return d.SitterLanguage(), query, ok
+	}
+	query, ok := DefinitionQueries[l.Language]
+	return l.SitterLanguage(), query, ok
+}
+
 func loadLanguageScm(l *lang.TreeSitterParser, scmDir string, sitterLang *sitter.Language) (*sitter.Query, error) {
 	var err error
 	baseQuery := makeQueryPath(l.Language, scmDir)
@@ -63,6 +122,7 @@ func loadLanguageScm(l *lang.TreeSitterParser, scmDir string, sitterLang *sitter
 	if err != nil {
 		return nil, fmt.Errorf("failed to read base query file %s for %s: %w", baseQuery, l.Language, err)
 	}
+	baseQueryContent = appendSharedScm(string(l.Language), scmDir, baseQueryContent)
 	query, queryError := sitter.NewQuery(sitterLang, string(baseQueryContent))
 	if queryError != nil && lang.IsRealQueryErr(queryError) {
 		return nil, fmt.Errorf("failed to parse base query file %s: %w", baseQuery, queryError)
@@ -70,6 +130,16 @@ func loadLanguageScm(l *lang.TreeSitterParser, scmDir string, sitterLang *sitter
 	return query, nil
 }
 
+// appendSharedScm 追加语言或变体共用的查询规则，共用规则在该子目录下不存在时跳过
+func appendSharedScm(name string, scmDir string, content []byte) []byte {
+	for _, shared := range sharedQueries[name] {
+		if extra, err := scmFS.ReadFile(makeQueryPath(lang.Language(shared), scmDir)); err == nil {
+			content = append(append(content, '\n'), extra...)
+		}
+	}
+	return content
+}
+
 func makeQueryPath(lang lang.Language, subdir string) string {
 	return filepath.ToSlash(filepath.Join(queryDir, subdir, string(lang)+queryExt))
 }
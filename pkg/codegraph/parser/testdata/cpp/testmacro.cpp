@@ -0,0 +1,30 @@
+#include <cstdio>
+
+int compute_sum(int a, int b) { return a + b; }
+void log_message(const char *msg) { printf("%s\n", msg); }
+struct RealBuffer { int size; };
+
+// 对象宏别名
+#define SUM compute_sum
+#define ADD SUM
+// 简单函数宏
+#define LOG(msg) log_message(msg)
+#define WRAPPED_LOG(msg) (log_message(msg)) /* 带括号 */
+// 类型别名宏
+#define Buffer RealBuffer
+// 无法静态解析的宏
+#define COMPLEX(x) compute_sum(x, 1) + 1
+#define LOOP_A LOOP_B
+#define LOOP_B LOOP_A
+
+int main() {
+    int x = SUM(1, 2);
+    int y = ADD(3, 4);
+    LOG("hello");
+    WRAPPED_LOG("world");
+    Buffer buf;
+    int z = COMPLEX(5);
+    LOOP_A();
+    unknown_func();
+    return x + y + z + buf.size;
+}
@@ -0,0 +1,12 @@
+using System.Linq;
+using Demo.Shop.Models;
+
+namespace Demo.Shop.Services;
+
+public partial class OrderService
+{
+    public Order FindById(int id)
+    {
+        return _repository.All().FirstOrDefault(o => o.Id == id);
+    }
+}
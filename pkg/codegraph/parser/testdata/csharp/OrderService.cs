@@ -0,0 +1,25 @@
+using System;
+using Demo.Shop.Models;
+
+namespace Demo.Shop.Services
+{
+    public partial class OrderService : IOrderService
+    {
+        private readonly IOrderRepository _repository;
+
+        public Order Create(Customer customer)
+        {
+            var order = new Order(customer);
+            _repository.Save(order);
+            return order;
+        }
+    }
+
+    public partial class OrderService : IDisposable
+    {
+        public void Cancel(Order order)
+        {
+            order.Cancel();
+        }
+    }
+}
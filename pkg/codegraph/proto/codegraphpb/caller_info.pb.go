@@ -239,8 +239,9 @@ func (x *Position) GetEndColumn() int32 {
 // CalleeKey 被调用者的信息
 type CalleeKey struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SymbolName    string                 `protobuf:"bytes,1,opt,name=symbol_name,json=symbolName,proto3" json:"symbol_name,omitempty"`  // 符号名称
-	ParamCount    int32                  `protobuf:"varint,2,opt,name=param_count,json=paramCount,proto3" json:"param_count,omitempty"` // 参数数量
+	SymbolName    string                 `protobuf:"bytes,1,opt,name=symbol_name,json=symbolName,proto3" json:"symbol_name,omitempty"`           // 符号名称
+	ParamCount    int32                  `protobuf:"varint,2,opt,name=param_count,json=paramCount,proto3" json:"param_count,omitempty"`          // 参数数量
+	IsConstructor bool                   `protobuf:"varint,3,opt,name=is_constructor,json=isConstructor,proto3" json:"is_constructor,omitempty"` // 是否为构造调用（new Foo()、Foo()）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -289,6 +290,13 @@ func (x *CalleeKey) GetParamCount() int32 {
 	return 0
 }
 
+func (x *CalleeKey) GetIsConstructor() bool {
+	if x != nil {
+		return x.IsConstructor
+	}
+	return false
+}
+
 var File_pkg_codegraph_proto_caller_info_proto protoreflect.FileDescriptor
 
 const file_pkg_codegraph_proto_caller_info_proto_rawDesc = "" +
@@ -317,12 +325,13 @@ const file_pkg_codegraph_proto_caller_info_proto_rawDesc = "" +
 	"\fstart_column\x18\x02 \x01(\x05R\vstartColumn\x12\x19\n" +
 	"\bend_line\x18\x03 \x01(\x05R\aendLine\x12\x1d\n" +
 	"\n" +
-	"end_column\x18\x04 \x01(\x05R\tendColumn\"M\n" +
+	"end_column\x18\x04 \x01(\x05R\tendColumn\"t\n" +
 	"\tCalleeKey\x12\x1f\n" +
 	"\vsymbol_name\x18\x01 \x01(\tR\n" +
 	"symbolName\x12\x1f\n" +
 	"\vparam_count\x18\x02 \x01(\x05R\n" +
-	"paramCountB-Z+pkg/codegraph/proto/codegraphpb;codegraphpbb\x06proto3"
+	"paramCount\x12%\n" +
+	"\x0eis_constructor\x18\x03 \x01(\bR\risConstructorB-Z+pkg/codegraph/proto/codegraphpb;codegraphpbb\x06proto3"
 
 var (
 	file_pkg_codegraph_proto_caller_info_proto_rawDescOnce sync.Once
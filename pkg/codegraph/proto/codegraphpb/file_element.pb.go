@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.7
 // 	protoc        v4.25.3
 // source: pkg/codegraph/proto/file_element.proto
 
@@ -23,15 +23,19 @@ const (
 
 // FileElementTable 文件元素表，简化版本
 type FileElementTable struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Imports       []*Import              `protobuf:"bytes,4,rep,name=imports,proto3" json:"imports,omitempty"`
-	Package       *Package               `protobuf:"bytes,5,opt,name=package,proto3" json:"package,omitempty"`
-	Elements      []*Element             `protobuf:"bytes,6,rep,name=elements,proto3" json:"elements,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Path      string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Language  string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Timestamp int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Imports   []*Import              `protobuf:"bytes,4,rep,name=imports,proto3" json:"imports,omitempty"`
+	Package   *Package               `protobuf:"bytes,5,opt,name=package,proto3" json:"package,omitempty"`
+	Elements  []*Element             `protobuf:"bytes,6,rep,name=elements,proto3" json:"elements,omitempty"`
+	// 语法树中存在 ERROR/MISSING 节点，文件解析不完整
+	HasParseErrors bool `protobuf:"varint,7,opt,name=has_parse_errors,json=hasParseErrors,proto3" json:"has_parse_errors,omitempty"`
+	// 语法树中 ERROR/MISSING 节点的数量
+	ParseErrorCount int32 `protobuf:"varint,8,opt,name=parse_error_count,json=parseErrorCount,proto3" json:"parse_error_count,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *FileElementTable) Reset() {
@@ -106,6 +110,20 @@ func (x *FileElementTable) GetElements() []*Element {
 	return nil
 }
 
+func (x *FileElementTable) GetHasParseErrors() bool {
+	if x != nil {
+		return x.HasParseErrors
+	}
+	return false
+}
+
+func (x *FileElementTable) GetParseErrorCount() int32 {
+	if x != nil {
+		return x.ParseErrorCount
+	}
+	return 0
+}
+
 // 导入
 type Import struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -309,14 +327,16 @@ var File_pkg_codegraph_proto_file_element_proto protoreflect.FileDescriptor
 
 const file_pkg_codegraph_proto_file_element_proto_rawDesc = "" +
 	"\n" +
-	"&pkg/codegraph/proto/file_element.proto\x12\vcodegraphpb\x1a\x1fpkg/codegraph/proto/types.proto\"\xf1\x01\n" +
+	"&pkg/codegraph/proto/file_element.proto\x12\vcodegraphpb\x1a\x1fpkg/codegraph/proto/types.proto\"\xc7\x02\n" +
 	"\x10FileElementTable\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1a\n" +
 	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x1c\n" +
 	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12-\n" +
 	"\aimports\x18\x04 \x03(\v2\x13.codegraphpb.ImportR\aimports\x12.\n" +
 	"\apackage\x18\x05 \x01(\v2\x14.codegraphpb.PackageR\apackage\x120\n" +
-	"\belements\x18\x06 \x03(\v2\x14.codegraphpb.ElementR\belements\"`\n" +
+	"\belements\x18\x06 \x03(\v2\x14.codegraphpb.ElementR\belements\x12(\n" +
+	"\x10has_parse_errors\x18\a \x01(\bR\x0ehasParseErrors\x12*\n" +
+	"\x11parse_error_count\x18\b \x01(\x05R\x0fparseErrorCount\"`\n" +
 	"\x06Import\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
 	"\x06source\x18\x02 \x01(\tR\x06source\x12\x14\n" +
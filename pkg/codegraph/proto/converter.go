@@ -199,6 +199,8 @@ const (
 	keyReturnType      = "returnType"
 	keySuperClasses    = "superClasses"
 	keySuperInterfaces = "superInterfaces"
+	keyDocRange        = "docRange"
+	keyConstructor     = "constructor"
 )
 
 // FileElementTablesToProto 将 []parser.FileElementTable 转换为 []*codegraphpb.FileElementTable
@@ -209,11 +211,13 @@ func FileElementTablesToProto(fileElementTables []*parser.FileElementTable) []*c
 	protoElementTables := make([]*codegraphpb.FileElementTable, len(fileElementTables))
 	for j, ft := range fileElementTables {
 		pft := &codegraphpb.FileElementTable{
-			Path:      ft.Path,
-			Language:  string(ft.Language),
-			Timestamp: ft.Timestamp,
-			Elements:  make([]*codegraphpb.Element, len(ft.Elements)),
-			Imports:   make([]*codegraphpb.Import, len(ft.Imports)),
+			Path:            ft.Path,
+			Language:        string(ft.Language),
+			Timestamp:       ft.Timestamp,
+			Elements:        make([]*codegraphpb.Element, len(ft.Elements)),
+			Imports:         make([]*codegraphpb.Import, len(ft.Imports)),
+			HasParseErrors:  ft.HasParseErrors,
+			ParseErrorCount: int32(ft.ParseErrorCount),
 		}
 		if ft.Package != nil {
 			pft.Package = &codegraphpb.Package{Name: ft.Package.Name, Range: ft.Package.Range}
@@ -230,12 +234,7 @@ func FileElementTablesToProto(fileElementTables []*parser.FileElementTable) []*c
 				ElementType: ElementTypeToProto(e.GetType()),
 				Range:       e.GetRange(),
 			}
-			// 定义：class interface method function variable
-			if e.GetType() == types.ElementTypeClass || e.GetType() == types.ElementTypeInterface ||
-				e.GetType() == types.ElementTypeMethod || e.GetType() == types.ElementTypeFunction ||
-				e.GetType() == types.ElementTypeVariable {
-				pbe.IsDefinition = true
-			}
+			pbe.IsDefinition = IsDefinitionType(e.GetType())
 
 			//for _, r := range e.GetRelations() {
 			//	pbe.Relations = append(pbe.Relations, RelationToProto(r))
@@ -283,6 +282,32 @@ func GetSuperClassesFromExtraData(extraData map[string][]byte) (superClasses []s
 	return
 }
 
+// GetDocRangeFromExtraData 获取定义的文档注释范围，没有文档注释时返回 nil
+func GetDocRangeFromExtraData(extraData map[string][]byte) (docRange []int32, err error) {
+	docRangeBytes, ok := extraData[keyDocRange]
+	if !ok {
+		return
+	}
+	err = json.Unmarshal(docRangeBytes, &docRange)
+	return
+}
+
+// GetConstructorClassFromExtraData 获取构造函数所属的类名，不是构造函数时返回空串
+func GetConstructorClassFromExtraData(extraData map[string][]byte) (className string, err error) {
+	classNameBytes, ok := extraData[keyConstructor]
+	if !ok {
+		return
+	}
+	err = json.Unmarshal(classNameBytes, &className)
+	return
+}
+
+// IsConstructorCallFromExtraData 调用是否为 new 表达式等显式的构造调用
+func IsConstructorCallFromExtraData(extraData map[string][]byte) bool {
+	_, ok := extraData[keyConstructor]
+	return ok
+}
+
 func GetSuperInterfacesFromExtraData(extraData map[string][]byte) (superInterfaces []string, err error) {
 	superInterfacesBytes, ok := extraData[keySuperInterfaces]
 	if !ok {
@@ -339,6 +364,16 @@ func MarshalExtraData(element resolver.Element) (map[string][]byte, error) {
 			}
 		}
 
+		if e.IsConstructor {
+			// 构造函数记录所属类名，构造调用按类名关联到构造函数
+			classNameBytes, err := json.Marshal(e.Owner)
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				extraData[keyConstructor] = classNameBytes
+			}
+		}
+
 	case *resolver.Class:
 		if len(e.SuperClasses) > 0 {
 			superClassesBytes, err := json.Marshal(e.SuperClasses)
@@ -377,11 +412,31 @@ func MarshalExtraData(element resolver.Element) (map[string][]byte, error) {
 				extraData[keyParameters] = parametersBytes
 			}
 		}
+		if e.IsConstructor {
+			extraData[keyConstructor] = []byte("true")
+		}
+	}
+
+	if docRange := docRangeOf(element); len(docRange) > 0 {
+		docRangeBytes, err := json.Marshal(docRange)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			extraData[keyDocRange] = docRangeBytes
+		}
 	}
 
 	return extraData, errors.Join(errs...)
 }
 
+// docRangeOf 返回元素记录的文档注释范围
+func docRangeOf(element resolver.Element) []int32 {
+	if e, ok := element.(interface{ GetDocRange() []int32 }); ok {
+		return e.GetDocRange()
+	}
+	return nil
+}
+
 func UnMarshalExtraData(element *codegraphpb.Element) (map[string]any, error) {
 	var errs []error
 	extraData := make(map[string]any)
@@ -414,6 +469,15 @@ func UnMarshalExtraData(element *codegraphpb.Element) (map[string]any, error) {
 			}
 		}
 
+		if classNameBytes, ok := extraDataRaw[keyConstructor]; ok {
+			var className string
+			if err := json.Unmarshal(classNameBytes, &className); err != nil {
+				errs = append(errs, err)
+			} else {
+				extraData[keyConstructor] = className
+			}
+		}
+
 	case codegraphpb.ElementType_CLASS:
 		if superClassesBytes, ok := extraDataRaw[keySuperClasses]; ok {
 			var superClasses []resolver.Parameter
@@ -452,7 +516,22 @@ func UnMarshalExtraData(element *codegraphpb.Element) (map[string]any, error) {
 				extraData[keyParameters] = params
 			}
 		}
+
+		if _, ok := extraDataRaw[keyConstructor]; ok {
+			extraData[keyConstructor] = true
+		}
 	}
 
 	return extraData, errors.Join(errs...)
 }
+
+// IsDefinitionType 元素类型是否为定义：class interface method function variable
+func IsDefinitionType(elementType types.ElementType) bool {
+	switch elementType {
+	case types.ElementTypeClass, types.ElementTypeInterface, types.ElementTypeMethod,
+		types.ElementTypeFunction, types.ElementTypeVariable:
+		return true
+	default:
+		return false
+	}
+}
@@ -147,6 +147,17 @@ func updateElementRange(element Element, capture *sitter.QueryCapture) {
 		int32(capture.Node.EndPosition().Column),
 	})
 }
+
+// updateElementRangeByNode 使用节点的位置作为元素范围
+func updateElementRangeByNode(element Element, node *sitter.Node) {
+	element.SetRange([]int32{
+		int32(node.StartPosition().Row),
+		int32(node.StartPosition().Column),
+		int32(node.EndPosition().Row),
+		int32(node.EndPosition().Column),
+	})
+}
+
 func NewReference(rootElement Element, curNode *sitter.Node, name string, owner string) *Reference {
 	return &Reference{
 		BaseElement: &BaseElement{
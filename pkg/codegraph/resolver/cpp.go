@@ -91,6 +91,9 @@ func (c *CppResolver) resolveMethod(ctx context.Context, element *Method, rc *Re
 		case types.ElementTypeMethodName:
 			element.BaseElement.Name = StripSpaces(content)
 			element.Declaration.Name = element.BaseElement.Name
+		case types.ElementTypeMethodOwner:
+			// 类外定义 Foo::Foo(...)
+			element.Owner = StripSpaces(content)
 		}
 	}
 	// 设置owner并且补充默认修饰符
@@ -100,6 +103,9 @@ func (c *CppResolver) resolveMethod(ctx context.Context, element *Method, rc *Re
 		element.Owner = extractNodeName(ownerNode, rc.SourceFile.Content)
 		ownerKind = types.ToNodeKind(ownerNode.Kind())
 	}
+	// 没有返回类型且与类同名的方法为构造函数
+	element.IsConstructor = rootCap.Node.ChildByFieldName("type") == nil && element.Owner != types.EmptyString &&
+		element.Owner == element.BaseElement.Name
 	modifier := findAccessSpecifier(&rootCap.Node, rc.SourceFile.Content)
 	// 补充作用域
 	element.BaseElement.Scope = getScopeFromModifiers(modifier, ownerKind)
@@ -211,6 +217,7 @@ func (c *CppResolver) resolveCall(ctx context.Context, element *Call, rc *Resolv
 				// 避免为空
 				element.BaseElement.Name = StripSpaces(content)
 			}
+			element.IsConstructor = types.ToElementType(captureName) == types.ElementTypeNewExpressionType
 		case types.ElementTypeFunctionOwner, types.ElementTypeCallOwner, types.ElementTypeNewExpressionOwner:
 			element.Owner = StripSpaces(content)
 		case types.ElementTypeTemplateCallArgs:
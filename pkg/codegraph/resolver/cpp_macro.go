@@ -0,0 +1,142 @@
+package resolver
+
+import (
+	"codebase-indexer/pkg/codegraph/types"
+	"regexp"
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// 宏展开的最大深度，防止 #define A B / #define B A 这类循环定义
+const maxMacroExpandDepth = 8
+
+var (
+	macroIdentifierRegex = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	macroCallRegex       = regexp.MustCompile(`^([A-Za-z_]\w*)\s*\(`)
+)
+
+// CollectCppMacroAliases 收集文件中可静态解析的宏别名，key 为宏名，value 为展开后的符号名。
+// 仅支持对象宏 #define FOO bar 和简单函数宏 #define FOO(x) bar(x)，其它宏忽略
+func CollectCppMacroAliases(root *sitter.Node, content []byte) map[string]string {
+	aliases := make(map[string]string)
+	collectCppMacroAliases(root, content, aliases)
+	return aliases
+}
+
+func collectCppMacroAliases(node *sitter.Node, content []byte, aliases map[string]string) {
+	if node == nil {
+		return
+	}
+	kind := types.ToNodeKind(node.Kind())
+	if kind == types.NodeKindPreprocDef || kind == types.NodeKindPreprocFunctionDef {
+		nameNode := node.ChildByFieldName("name")
+		valueNode := node.ChildByFieldName("value")
+		if nameNode == nil || valueNode == nil {
+			return
+		}
+		if target := parseMacroTarget(kind, valueNode.Utf8Text(content)); target != types.EmptyString {
+			aliases[nameNode.Utf8Text(content)] = target
+		}
+		return
+	}
+	for i := uint(0); i < node.NamedChildCount(); i++ {
+		collectCppMacroAliases(node.NamedChild(i), content, aliases)
+	}
+}
+
+// parseMacroTarget 解析宏体，返回宏展开后指向的符号名，无法解析时返回空
+func parseMacroTarget(kind types.NodeKind, value string) string {
+	value = stripMacroComment(value)
+	if kind == types.NodeKindPreprocDef {
+		// #define FOO bar
+		if macroIdentifierRegex.MatchString(value) {
+			return value
+		}
+		return types.EmptyString
+	}
+	// #define FOO(x) (bar(x))
+	for len(value) > 1 && value[0] == '(' && matchingParen(value, 0) == len(value)-1 {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	loc := macroCallRegex.FindStringSubmatchIndex(value)
+	if loc == nil {
+		return types.EmptyString
+	}
+	// 宏体必须是单个调用表达式，bar(x) + 1、bar(x); baz(x) 等不处理
+	if matchingParen(value, loc[1]-1) != len(value)-1 {
+		return types.EmptyString
+	}
+	return value[loc[2]:loc[3]]
+}
+
+// stripMacroComment 去除宏体中的行尾注释及首尾空白
+func stripMacroComment(value string) string {
+	if i := strings.Index(value, "//"); i >= 0 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, "/*"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// matchingParen 返回与 open 位置左括号匹配的右括号位置，不匹配返回-1
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ResolveCppMacroAliases 将通过宏发生的调用、引用替换为宏展开后的目标符号，无法静态解析的保持不变
+func ResolveCppMacroAliases(elements []Element, aliases map[string]string) {
+	if len(aliases) == 0 {
+		return
+	}
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *Call:
+			// obj.FOO() 这类成员调用不是宏
+			if e.Owner != types.EmptyString {
+				continue
+			}
+		case *Reference:
+		default:
+			continue
+		}
+		if target, ok := expandMacroAlias(element.GetName(), aliases); ok {
+			element.SetName(target)
+		}
+	}
+}
+
+// expandMacroAlias 沿宏别名链展开符号名，出现循环或超过深度时视为无法解析
+func expandMacroAlias(name string, aliases map[string]string) (string, bool) {
+	target, ok := aliases[name]
+	if !ok {
+		return types.EmptyString, false
+	}
+	visited := map[string]struct{}{name: {}}
+	for depth := 0; depth < maxMacroExpandDepth; depth++ {
+		if _, seen := visited[target]; seen {
+			return types.EmptyString, false
+		}
+		next, ok := aliases[target]
+		if !ok {
+			return target, true
+		}
+		visited[target] = struct{}{}
+		target = next
+	}
+	return types.EmptyString, false
+}
@@ -0,0 +1,459 @@
+package resolver
+
+import (
+	"codebase-indexer/pkg/codegraph/types"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+type CSharpResolver struct {
+}
+
+var _ ElementResolver = &CSharpResolver{}
+
+var (
+	// csharpTypeNameRegex 匹配类型文本中的（限定）类型名，如 System.Collections.Generic.List
+	csharpTypeNameRegex = regexp.MustCompile(`[A-Za-z_@][\w.]*`)
+	// csharpInterfaceNameRegex 按 C# 命名约定识别接口，如 IDisposable
+	csharpInterfaceNameRegex = regexp.MustCompile(`^I[A-Z]`)
+)
+
+// csharpBuiltinTypes c# 内置类型及上下文关键字，不作为引用
+var csharpBuiltinTypes = map[string]struct{}{
+	"bool": {}, "byte": {}, "sbyte": {}, "char": {}, "decimal": {}, "double": {}, "float": {},
+	"int": {}, "uint": {}, "nint": {}, "nuint": {}, "long": {}, "ulong": {}, "short": {}, "ushort": {},
+	"object": {}, "string": {}, "void": {}, "dynamic": {}, "var": {},
+	"ref": {}, "out": {}, "in": {}, "params": {}, "this": {}, "readonly": {}, "scoped": {}, "global": {},
+}
+
+func (c *CSharpResolver) Resolve(ctx context.Context, element Element, rc *ResolveContext) ([]Element, error) {
+	return resolve(ctx, c, element, rc)
+}
+
+func (c *CSharpResolver) resolveImport(ctx context.Context, element *Import, rc *ResolveContext) ([]Element, error) {
+	rootCap := rc.Match.Captures[0]
+	updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
+	if rootCap.Node.IsMissing() || rootCap.Node.IsError() {
+		return nil, fmt.Errorf("import is missing or error")
+	}
+	name, alias := parseCSharpUsing(rootCap.Node.Utf8Text(rc.SourceFile.Content))
+	if name == types.EmptyString {
+		return nil, fmt.Errorf("csharp using directive has no namespace")
+	}
+	element.BaseElement.Name = name
+	element.Source = name
+	element.Alias = alias
+	element.BaseElement.Scope = types.ScopePackage
+	return []Element{element}, nil
+}
+
+func (c *CSharpResolver) resolvePackage(ctx context.Context, element *Package, rc *ResolveContext) ([]Element, error) {
+	rootCap := rc.Match.Captures[0]
+	updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
+	for _, cap := range rc.Match.Captures {
+		captureName := rc.CaptureNames[cap.Index]
+		if cap.Node.IsMissing() || cap.Node.IsError() {
+			continue
+		}
+		if types.ToElementType(captureName) == types.ElementTypePackageName {
+			element.BaseElement.Name = StripSpaces(cap.Node.Utf8Text(rc.SourceFile.Content))
+		}
+	}
+	element.BaseElement.Scope = types.ScopeProject
+	return []Element{element}, nil
+}
+
+func (c *CSharpResolver) resolveFunction(ctx context.Context, element *Function, rc *ResolveContext) ([]Element, error) {
+	// c# 中函数都定义在类型中，本地函数暂不处理
+	return nil, fmt.Errorf("csharp function not supported")
+}
+
+func (c *CSharpResolver) resolveMethod(ctx context.Context, element *Method, rc *ResolveContext) ([]Element, error) {
+	rootCap := rc.Match.Captures[0]
+	updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
+	if element.Declaration == nil {
+		element.Declaration = &Declaration{}
+	}
+	var refs []*Reference
+	for _, cap := range rc.Match.Captures {
+		captureName := rc.CaptureNames[cap.Index]
+		if cap.Node.IsMissing() || cap.Node.IsError() {
+			continue
+		}
+		switch types.ToElementType(captureName) {
+		case types.ElementTypeMethodName:
+			element.BaseElement.Name = StripSpaces(cap.Node.Utf8Text(rc.SourceFile.Content))
+			element.Declaration.Name = element.BaseElement.Name
+		case types.ElementTypeMethodParameters:
+			element.Declaration.Parameters = parseCSharpParameters(&cap.Node, rc.SourceFile.Content)
+		}
+	}
+	// 返回类型字段在不同版本的语法中为 returns 或 type
+	returnNode := rootCap.Node.ChildByFieldName("returns")
+	if returnNode == nil {
+		returnNode = rootCap.Node.ChildByFieldName("type")
+	}
+	if returnNode != nil {
+		for _, typ := range parseCSharpTypeNames(returnNode.Utf8Text(rc.SourceFile.Content)) {
+			owner, name := splitCSharpQualifiedName(typ)
+			element.Declaration.ReturnType = append(element.Declaration.ReturnType, name)
+			refs = append(refs, NewReference(element, returnNode, name, owner))
+		}
+	}
+	if len(element.Declaration.ReturnType) == 0 {
+		element.Declaration.ReturnType = []string{types.PrimitiveType}
+	}
+
+	modifiers := findCSharpModifiers(&rootCap.Node, rc.SourceFile.Content)
+	element.Declaration.Modifier = getElementModifier(modifiers)
+	ownerNode := findCSharpTypeOwner(&rootCap.Node)
+	var ownerKind types.NodeKind
+	if ownerNode != nil {
+		// partial 类的方法都归属到同一个逻辑类，owner 只取类名
+		element.Owner = findCSharpTypeName(ownerNode, rc.SourceFile.Content)
+		ownerKind = types.ToNodeKind(ownerNode.Kind())
+	}
+	if element.Declaration.Modifier == types.EmptyString {
+		// c# 类成员默认 private，接口成员默认 public
+		if ownerKind == types.NodeKindInterfaceDeclaration {
+			element.Declaration.Modifier = types.ModifierPublic
+		} else {
+			element.Declaration.Modifier = types.ModifierPrivate
+		}
+	}
+	element.BaseElement.Scope = getCSharpScope(element.Declaration.Modifier)
+	elements := []Element{element}
+	for _, r := range refs {
+		elements = append(elements, r)
+	}
+	return elements, nil
+}
+
+func (c *CSharpResolver) resolveClass(ctx context.Context, element *Class, rc *ResolveContext) ([]Element, error) {
+	rootCap := rc.Match.Captures[0]
+	updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
+	var refs []*Reference
+	for _, cap := range rc.Match.Captures {
+		captureName := rc.CaptureNames[cap.Index]
+		if cap.Node.IsMissing() || cap.Node.IsError() {
+			continue
+		}
+		elemType := types.ToElementType(captureName)
+		switch elemType {
+		case types.ElementTypeClassName, types.ElementTypeStructName, types.ElementTypeEnumName:
+			element.BaseElement.Name = StripSpaces(cap.Node.Utf8Text(rc.SourceFile.Content))
+		case types.ElementTypeClassExtends, types.ElementTypeStructExtends:
+			for i, typ := range parseCSharpBaseList(cap.Node.Utf8Text(rc.SourceFile.Content)) {
+				owner, parent := splitCSharpQualifiedName(typ)
+				// c# 单继承多实现，只有类的第一个基类型可能是父类，按命名约定区分接口
+				if i == 0 && elemType == types.ElementTypeClassExtends && !csharpInterfaceNameRegex.MatchString(parent) {
+					element.SuperClasses = append(element.SuperClasses, parent)
+				} else {
+					element.SuperInterfaces = append(element.SuperInterfaces, parent)
+				}
+				refs = append(refs, NewReference(element, &cap.Node, parent, owner))
+			}
+		}
+	}
+	modifiers := findCSharpModifiers(&rootCap.Node, rc.SourceFile.Content)
+	element.BaseElement.Scope = getCSharpScope(getElementModifier(modifiers))
+
+	elements := []Element{element}
+	for _, r := range refs {
+		elements = append(elements, r)
+	}
+	return elements, nil
+}
+
+func (c *CSharpResolver) resolveVariable(ctx context.Context, element *Variable, rc *ResolveContext) ([]Element, error) {
+	rootCap := rc.Match.Captures[0]
+	updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
+	var refs []*Reference
+	for _, cap := range rc.Match.Captures {
+		captureName := rc.CaptureNames[cap.Index]
+		if cap.Node.IsMissing() || cap.Node.IsError() {
+			continue
+		}
+		switch types.ToElementType(captureName) {
+		case types.ElementTypeFieldName:
+			element.BaseElement.Name = StripSpaces(cap.Node.Utf8Text(rc.SourceFile.Content))
+		case types.ElementTypeFieldType:
+			for _, typ := range parseCSharpTypeNames(cap.Node.Utf8Text(rc.SourceFile.Content)) {
+				owner, name := splitCSharpQualifiedName(typ)
+				element.VariableType = append(element.VariableType, name)
+				refs = append(refs, NewReference(element, &cap.Node, name, owner))
+			}
+		}
+	}
+	if len(element.VariableType) == 0 {
+		element.VariableType = []string{types.PrimitiveType}
+	}
+	element.BaseElement.Scope = types.ScopeClass
+	elements := []Element{element}
+	for _, r := range refs {
+		elements = append(elements, r)
+	}
+	return elements, nil
+}
+
+func (c *CSharpResolver) resolveInterface(ctx context.Context, element *Interface, rc *ResolveContext) ([]Element, error) {
+	rootCap := rc.Match.Captures[0]
+	updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
+	var refs []*Reference
+	for _, cap := range rc.Match.Captures {
+		captureName := rc.CaptureNames[cap.Index]
+		if cap.Node.IsMissing() || cap.Node.IsError() {
+			continue
+		}
+		switch types.ToElementType(captureName) {
+		case types.ElementTypeInterfaceName:
+			element.BaseElement.Name = StripSpaces(cap.Node.Utf8Text(rc.SourceFile.Content))
+		case types.ElementTypeInterfaceExtends:
+			for _, typ := range parseCSharpBaseList(cap.Node.Utf8Text(rc.SourceFile.Content)) {
+				owner, parent := splitCSharpQualifiedName(typ)
+				element.SuperInterfaces = append(element.SuperInterfaces, parent)
+				refs = append(refs, NewReference(element, &cap.Node, parent, owner))
+			}
+		}
+	}
+	modifiers := findCSharpModifiers(&rootCap.Node, rc.SourceFile.Content)
+	element.BaseElement.Scope = getCSharpScope(getElementModifier(modifiers))
+	elements := []Element{element}
+	for _, r := range refs {
+		elements = append(elements, r)
+	}
+	return elements, nil
+}
+
+func (c *CSharpResolver) resolveCall(ctx context.Context, element *Call, rc *ResolveContext) ([]Element, error) {
+	rootCap := rc.Match.Captures[0]
+	updateRootElement(element, &rootCap, rc.CaptureNames[rootCap.Index], rc.SourceFile.Content)
+	var refs []*Reference
+	for _, cap := range rc.Match.Captures {
+		captureName := rc.CaptureNames[cap.Index]
+		if cap.Node.IsMissing() || cap.Node.IsError() {
+			continue
+		}
+		content := cap.Node.Utf8Text(rc.SourceFile.Content)
+		switch types.ToElementType(captureName) {
+		case types.ElementTypeFunctionCallName, types.ElementTypeCallName:
+			// Foo<T>(x) 去掉泛型参数
+			element.BaseElement.Name = stripCSharpGenerics(StripSpaces(content))
+		case types.ElementTypeCallOwner:
+			element.Owner = StripSpaces(content)
+		case types.ElementTypeNewExpressionType:
+			for i, typ := range parseCSharpTypeNames(content) {
+				owner, name := splitCSharpQualifiedName(typ)
+				if i == 0 {
+					// 第一个类型作为这个调用的name，泛型参数走引用
+					element.BaseElement.Name = name
+					element.Owner = owner
+					continue
+				}
+				refs = append(refs, NewReference(element, &cap.Node, name, owner))
+			}
+		case types.ElementTypeFunctionArguments, types.ElementTypeCallArguments, types.ElementTypeNewExpressionArgs:
+			for _, param := range ParseArgumentList(&cap.Node, rc.SourceFile.Content) {
+				element.Parameters = append(element.Parameters, &param)
+			}
+		}
+	}
+	element.BaseElement.Scope = types.ScopeFunction
+	elements := []Element{element}
+	for _, r := range refs {
+		elements = append(elements, r)
+	}
+	return elements, nil
+}
+
+// MergeCSharpPartialClasses 合并同一文件中同名的 partial 类声明，基类型取并集。
+// 跨文件的 partial 类通过方法的 owner（类名）归属到同一个逻辑类
+func MergeCSharpPartialClasses(elements []Element) []Element {
+	merged := make(map[string]*Class)
+	result := make([]Element, 0, len(elements))
+	for _, element := range elements {
+		class, ok := element.(*Class)
+		if !ok {
+			result = append(result, element)
+			continue
+		}
+		first, exists := merged[class.Name]
+		if !exists {
+			merged[class.Name] = class
+			result = append(result, class)
+			continue
+		}
+		first.SuperClasses = removeDuplicates(append(first.SuperClasses, class.SuperClasses...))
+		first.SuperInterfaces = removeDuplicates(append(first.SuperInterfaces, class.SuperInterfaces...))
+		first.Fields = append(first.Fields, class.Fields...)
+		first.Methods = append(first.Methods, class.Methods...)
+	}
+	return result
+}
+
+// parseCSharpUsing 解析 using 指令，返回导入的命名空间（或类型）及别名
+// using System.Text; -> System.Text
+// using static System.Math; -> System.Math
+// using Json = Newtonsoft.Json; -> Newtonsoft.Json, Json
+func parseCSharpUsing(text string) (name string, alias string) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), ";"))
+	fields := strings.Fields(text)
+	for len(fields) > 0 {
+		switch fields[0] {
+		case "global", "using", "static", "unsafe":
+			fields = fields[1:]
+			continue
+		}
+		break
+	}
+	rest := strings.Join(fields, types.Space)
+	if i := strings.Index(rest, "="); i >= 0 {
+		alias = StripSpaces(rest[:i])
+		rest = rest[i+1:]
+	}
+	return StripSpaces(rest), alias
+}
+
+// parseCSharpBaseList 解析基类型列表，返回去掉泛型参数后的类型名
+// : Base<T>, IComparable<T>, IDisposable -> [Base, IComparable, IDisposable]
+// record 的主构造参数 Base(x) -> Base
+func parseCSharpBaseList(text string) []string {
+	text = strings.TrimPrefix(strings.TrimSpace(text), types.Colon)
+	var typs []string
+	for _, part := range splitCSharpTopLevel(text) {
+		if i := strings.Index(part, "("); i >= 0 {
+			part = part[:i]
+		}
+		part = stripCSharpGenerics(StripSpaces(part))
+		if part != types.EmptyString {
+			typs = append(typs, part)
+		}
+	}
+	return typs
+}
+
+// parseCSharpTypeNames 解析类型文本中的所有自定义类型，包含泛型参数，过滤内置类型
+// Dictionary<string, List<User>> -> [Dictionary, List, User]
+func parseCSharpTypeNames(text string) []string {
+	var typs []string
+	for _, typ := range csharpTypeNameRegex.FindAllString(text, -1) {
+		typ = strings.TrimPrefix(strings.Trim(typ, types.Dot), types.EmailAt)
+		if _, ok := csharpBuiltinTypes[typ]; ok || typ == types.EmptyString {
+			continue
+		}
+		typs = append(typs, typ)
+	}
+	return removeDuplicates(typs)
+}
+
+// splitCSharpTopLevel 按最外层逗号分割，忽略泛型、括号内的逗号
+func splitCSharpTopLevel(text string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range text {
+		switch r {
+		case '<', '(', '[':
+			depth++
+		case '>', ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// stripCSharpGenerics 去掉泛型参数 List<int> -> List
+func stripCSharpGenerics(name string) string {
+	if i := strings.Index(name, "<"); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// splitCSharpQualifiedName 拆分限定名为 owner 和名称 System.IO.File -> System.IO, File
+func splitCSharpQualifiedName(name string) (owner string, simple string) {
+	i := strings.LastIndex(name, types.Dot)
+	if i < 0 {
+		return types.EmptyString, name
+	}
+	return name[:i], name[i+1:]
+}
+
+// parseCSharpParameters 解析参数列表 (int a, List<User> users)
+func parseCSharpParameters(node *sitter.Node, content []byte) []Parameter {
+	if node == nil {
+		return nil
+	}
+	var params []Parameter
+	for i := uint(0); i < node.NamedChildCount(); i++ {
+		child := node.NamedChild(i)
+		if child == nil || child.Kind() != "parameter" {
+			continue
+		}
+		nameNode := child.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+		typs := []string{types.PrimitiveType}
+		if typeNode := child.ChildByFieldName("type"); typeNode != nil {
+			if names := parseCSharpTypeNames(typeNode.Utf8Text(content)); len(names) > 0 {
+				typs = names
+			}
+		}
+		params = append(params, Parameter{Name: nameNode.Utf8Text(content), Type: typs})
+	}
+	return params
+}
+
+// findCSharpModifiers 获取声明节点上的所有修饰符，以空格连接
+func findCSharpModifiers(node *sitter.Node, content []byte) string {
+	var modifiers []string
+	for i := uint(0); i < node.NamedChildCount(); i++ {
+		child := node.NamedChild(i)
+		if child != nil && types.ToNodeKind(child.Kind()) == types.NodeKindCSharpModifier {
+			modifiers = append(modifiers, child.Utf8Text(content))
+		}
+	}
+	return strings.Join(modifiers, types.Space)
+}
+
+// findCSharpTypeOwner 向上查找方法所属的类、结构体、记录或接口声明
+func findCSharpTypeOwner(node *sitter.Node) *sitter.Node {
+	for current := node.Parent(); current != nil; current = current.Parent() {
+		switch types.ToNodeKind(current.Kind()) {
+		case types.NodeKindClassDeclaration, types.NodeKindStructDeclaration,
+			types.NodeKindRecordDeclaration, types.NodeKindInterfaceDeclaration:
+			return current
+		}
+	}
+	return nil
+}
+
+// findCSharpTypeName 获取类型声明节点的名称
+func findCSharpTypeName(node *sitter.Node, content []byte) string {
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+		return StripSpaces(nameNode.Utf8Text(content))
+	}
+	return types.EmptyString
+}
+
+// getCSharpScope 根据 c# 访问修饰符确定作用域
+// 未声明修饰符的顶层类型默认 internal，与 public 一样视为项目（程序集）内可见
+func getCSharpScope(modifier string) types.Scope {
+	switch modifier {
+	case types.ModifierPrivate:
+		return types.ScopeClass
+	case types.ModifierProtected:
+		return types.ScopePackage
+	default:
+		return types.ScopeProject
+	}
+}
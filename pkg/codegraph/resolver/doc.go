@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// docRangeOf 返回定义节点关联的文档注释范围 [开始行，开始列，结束行，结束列]，没有时返回 nil。
+// Python 取函数、类体中的第一条字符串语句（docstring），其他语言取紧挨在声明之前的连续注释
+func docRangeOf(language lang.Language, node *sitter.Node) []int32 {
+	if node == nil {
+		return nil
+	}
+	if language == lang.Python {
+		return pythonDocstringRange(node)
+	}
+	target := node
+	// 声明外层的包装节点（如 export 语句）与声明同行开始时，注释位于包装节点之前
+	for target.PrevNamedSibling() == nil {
+		parent := target.Parent()
+		if parent == nil || parent.Parent() == nil || parent.StartPosition().Row != target.StartPosition().Row {
+			break
+		}
+		target = parent
+	}
+
+	var first, last *sitter.Node
+	row := target.StartPosition().Row
+	for prev := target.PrevNamedSibling(); prev != nil && isCommentNode(prev); prev = prev.PrevNamedSibling() {
+		// 注释与声明（或下一段注释）之间有空行时不属于该声明
+		if prev.EndPosition().Row+1 < row {
+			break
+		}
+		// 跟在上一条语句后面的行尾注释
+		if before := prev.PrevSibling(); before != nil && before.EndPosition().Row == prev.StartPosition().Row {
+			break
+		}
+		if last == nil {
+			last = prev
+		}
+		first = prev
+		row = prev.StartPosition().Row
+	}
+	if first == nil {
+		return nil
+	}
+	return []int32{
+		int32(first.StartPosition().Row),
+		int32(first.StartPosition().Column),
+		int32(last.EndPosition().Row),
+		int32(last.EndPosition().Column),
+	}
+}
+
+// pythonDocstringRange 返回 Python 函数、类的 docstring 范围，即函数体中第一条语句为字符串字面量
+func pythonDocstringRange(node *sitter.Node) []int32 {
+	body := node.ChildByFieldName("body")
+	if body == nil {
+		return nil
+	}
+	for i := uint(0); i < body.NamedChildCount(); i++ {
+		stmt := body.NamedChild(i)
+		if stmt == nil || isCommentNode(stmt) {
+			continue
+		}
+		if stmt.Kind() != "expression_statement" || stmt.NamedChildCount() != 1 {
+			return nil
+		}
+		str := stmt.NamedChild(0)
+		if str == nil || str.Kind() != "string" {
+			return nil
+		}
+		return []int32{
+			int32(str.StartPosition().Row),
+			int32(str.StartPosition().Column),
+			int32(str.EndPosition().Row),
+			int32(str.EndPosition().Column),
+		}
+	}
+	return nil
+}
+
+// isCommentNode 是否注释节点，各语言的命名不同，如 comment、line_comment、block_comment
+func isCommentNode(node *sitter.Node) bool {
+	return strings.Contains(node.Kind(), "comment")
+}
@@ -31,6 +31,7 @@ type BaseElement struct {
 	Content          []byte
 	Range            []int32
 	Relations        []*Relation // 与该节点有关的节点
+	DocRange         []int32     // 文档注释范围，仅函数、方法、类、接口定义有值
 }
 
 type Relation struct {
@@ -98,6 +99,14 @@ func (e *BaseElement) GetScope() types.Scope {
 	return e.Scope
 }
 
+func (e *BaseElement) GetDocRange() []int32 {
+	return e.DocRange
+}
+
+func (e *BaseElement) SetDocRange(docRange []int32) {
+	e.DocRange = docRange
+}
+
 // Import 表示导入语句
 type Import struct {
 	*BaseElement
@@ -122,6 +131,8 @@ type Method struct {
 	*BaseElement
 	Owner       string
 	Declaration *Declaration
+	// IsConstructor 构造函数，Java/C++ 中与类同名的方法，Python 中的 __init__
+	IsConstructor bool
 }
 
 // Call 函数调用
@@ -129,6 +140,8 @@ type Call struct {
 	*BaseElement
 	Owner      string
 	Parameters []*Parameter
+	// IsConstructor new 表达式等显式的构造调用，Name 为被实例化的类名
+	IsConstructor bool
 }
 
 // Reference 结构体、类的引用
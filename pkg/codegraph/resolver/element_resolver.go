@@ -48,9 +48,34 @@ func resolve(ctx context.Context, b ElementResolver, element Element, rc *Resolv
 			element.GetType(), element.GetPath(), element.GetRange())
 		return nil, err
 	}
+	if err == nil {
+		captureDocRange(element, rc)
+	}
 	return FilterValidElems(elems, rc.Logger), err
 }
 
+// captureDocRange 记录函数、方法、类、接口定义的文档注释范围
+func captureDocRange(element Element, rc *ResolveContext) {
+	var base *BaseElement
+	switch e := element.(type) {
+	case *Function:
+		base = e.BaseElement
+	case *Method:
+		base = e.BaseElement
+	case *Class:
+		base = e.BaseElement
+	case *Interface:
+		base = e.BaseElement
+	default:
+		return
+	}
+	if base == nil || len(rc.Match.Captures) == 0 {
+		return
+	}
+	rootCap := rc.Match.Captures[0]
+	base.SetDocRange(docRangeOf(rc.Language, &rootCap.Node))
+}
+
 // IsValidElement 检查必须字段
 func IsValidElement(e Element) bool {
 	_, isElement := e.(*Import)
@@ -242,7 +242,8 @@ func (r *GoResolver) processStructFields(structTypeNode *sitter.Node, element *C
 						newReferences = append(newReferences, ref)
 					}
 				} else {
-					element.SuperClasses = append(element.SuperClasses, fieldType)
+					// 匿名字段即嵌入，被嵌入类型的方法提升为结构体的方法
+					element.SuperClasses = append(element.SuperClasses, goEmbeddedTypeName(fieldType))
 					continue
 				}
 				// 判断可见性（公有/私有）
@@ -264,6 +265,15 @@ func (r *GoResolver) processStructFields(structTypeNode *sitter.Node, element *C
 	return newReferences, nil
 }
 
+// goEmbeddedTypeName 嵌入或别名的原类型名，去掉指针和泛型参数，保留包名限定，如 *storage.Store[K] -> storage.Store
+func goEmbeddedTypeName(typ string) string {
+	typ = strings.TrimLeft(strings.TrimSpace(typ), "*")
+	if i := strings.Index(typ, "["); i >= 0 {
+		typ = typ[:i]
+	}
+	return typ
+}
+
 func (r *GoResolver) resolveClass(ctx context.Context, element *Class, rc *ResolveContext) ([]Element, error) {
 	elements := []Element{element}
 	rootCapture := rc.Match.Captures[0]
@@ -277,6 +287,11 @@ func (r *GoResolver) resolveClass(ctx context.Context, element *Class, rc *Resol
 			element.Name = content
 			element.Scope = analyzeScope(content)
 		case types.ElementTypeStructType:
+			if types.ToNodeKind(capture.Node.Kind()) != types.NodeKindStructType {
+				// 类型别名，别名拥有原类型的全部方法，与嵌入一样记录原类型
+				element.SuperClasses = append(element.SuperClasses, goEmbeddedTypeName(content))
+				continue
+			}
 			// 处理结构体字段
 			newlyFoundReferences, err := r.processStructFields(&capture.Node, element, rc)
 			if err != nil {
@@ -330,6 +345,7 @@ func (r *GoResolver) resolveVariable(ctx context.Context, element *Variable, rc
 			} else {
 				refPathMap := extractReferencePath(&capture.Node, rc.SourceFile.Content)
 				refPathMap["property"] = strings.TrimLeft(refPathMap["property"], "0123456789")
+				element.VariableType = append(element.VariableType, refPathMap["property"])
 				ref := NewReference(element, &capture.Node, refPathMap["property"], refPathMap["object"])
 				elements = append(elements, ref)
 			}
@@ -447,12 +463,15 @@ func (r *GoResolver) resolveCall(ctx context.Context, element *Call, rc *Resolve
 				case types.NodeKindIdentifier:
 					element.BaseElement.Name = funcNode.Utf8Text(rc.SourceFile.Content)
 					element.Scope = types.ScopeFunction
+					// 范围取被调用的标识符，而不是整个调用表达式
+					updateElementRangeByNode(element, funcNode)
 				case types.NodeKindSelectorExpression:
 					// 带包名/接收者的函数调用，如pkg.Func()或obj.Method()
 					field := funcNode.ChildByFieldName("field")
 					operand := funcNode.ChildByFieldName("operand")
 					if field != nil && field.Kind() == string(types.NodeKindFieldIdentifier) {
 						element.BaseElement.Name = field.Utf8Text(rc.SourceFile.Content)
+						updateElementRangeByNode(element, field)
 						if operand != nil {
 							element.Owner = operand.Utf8Text(rc.SourceFile.Content)
 							element.Type = types.ElementTypeMethodCall
@@ -470,6 +489,7 @@ func (r *GoResolver) resolveCall(ctx context.Context, element *Call, rc *Resolve
 						element.BaseElement.Name = CallName
 					}
 					element.Scope = types.ScopeFunction
+					updateElementRangeByNode(element, funcNode)
 				}
 			}
 		case types.ElementTypeFunctionArguments:
@@ -95,6 +95,7 @@ func (j *JavaResolver) resolveMethod(ctx context.Context, element *Method, rc *R
 		element.Owner = StripSpaces(owner)
 		ownerKind = types.ToNodeKind(ownerNode.Kind())
 	}
+	element.IsConstructor = types.ToNodeKind(rootCap.Node.Kind()) == types.NodeKindConstructor
 
 	// 补充作用域
 	element.BaseElement.Scope = getScopeFromModifiers(element.Declaration.Modifier, ownerKind)
@@ -347,9 +348,10 @@ func (j *JavaResolver) resolveCall(ctx context.Context, element *Call, rc *Resol
 					})
 				}
 				if i == 0 {
-					// 第一个类型作为这个调用的name
+					// 第一个类型作为这个调用的name，new Foo(...) 为构造调用
 					element.BaseElement.Name = realTyp
 					element.Owner = owner
+					element.IsConstructor = types.ToElementType(captureName) == types.ElementTypeNewExpressionType
 					continue
 				}
 				// 同时剩余的类型都要走引用
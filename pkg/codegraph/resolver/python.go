@@ -9,6 +9,9 @@ import (
 	sitter "github.com/tree-sitter/go-tree-sitter"
 )
 
+// pyConstructorName Python 构造函数名
+const pyConstructorName = "__init__"
+
 type PythonResolver struct {
 }
 
@@ -100,6 +103,8 @@ func (py *PythonResolver) resolveMethod(ctx context.Context, element *Method, rc
 	if pnode != nil {
 		element.Owner = extractNodeName(pnode, rc.SourceFile.Content)
 	}
+	// Foo(...) 实例化时调用 __init__
+	element.IsConstructor = element.BaseElement.Name == pyConstructorName && element.Owner != types.EmptyString
 	element.BaseElement.Scope = types.ScopeClass
 	return []Element{element}, nil
 }
@@ -217,6 +222,11 @@ func parsePyFuncParams(node *sitter.Node, content []byte) []Parameter {
 		}
 		// fmt.Println("child", child.Kind())
 		switch types.ToNodeKind(child.Kind()) {
+		case types.NodeKindIdentifier:
+			// 不带类型和默认值的普通参数，包括方法的 self
+			params = append(params, Parameter{
+				Name: child.Utf8Text(content),
+			})
 		case types.NodeKindListSplatPattern:
 			name := child.Utf8Text(content)
 			name = strings.ReplaceAll(name, "*", "...")
@@ -36,6 +36,7 @@ func NewResolverManager() *ResolverManager {
 	manager.register(lang.CPP, &CppResolver{})
 	manager.register(lang.JavaScript, &JavaScriptResolver{})
 	manager.register(lang.TypeScript, &TypeScriptResolver{})
+	manager.register(lang.CSharp, &CSharpResolver{})
 
 	return manager
 
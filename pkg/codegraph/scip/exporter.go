@@ -0,0 +1,268 @@
+package scip
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/workspace"
+	"codebase-indexer/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// ToolName 写入 SCIP Metadata 的工具名，同时作为 moniker 的 scheme
+	ToolName = "codebase-indexer"
+	// packageVersion 我们没有包版本信息，使用 SCIP 约定的占位符
+	packageVersion = "."
+)
+
+var simpleDescriptorRegex = regexp.MustCompile(`^[A-Za-z0-9_+\-$]+$`)
+
+// Exporter 将存储中的 FileElementTable/SymbolOccurrence 导出为 SCIP 索引
+type Exporter struct {
+	workspaceReader workspace.WorkspaceReader
+	storage         store.GraphStorage
+	logger          logger.Logger
+	// ToolVersion 写入 Metadata 的工具版本，可选
+	ToolVersion string
+}
+
+// NewExporter 创建 SCIP 导出器
+func NewExporter(workspaceReader workspace.WorkspaceReader, storage store.GraphStorage, logger logger.Logger) *Exporter {
+	return &Exporter{
+		workspaceReader: workspaceReader,
+		storage:         storage,
+		logger:          logger,
+	}
+}
+
+// ExportSCIP 导出工作区内所有项目的索引，写入 w 的是一个 scip.Index。
+// 为降低内存占用，文档逐个以 Index 消息片段写出，按 protobuf 合并语义等价于单个 Index
+func (e *Exporter) ExportSCIP(ctx context.Context, workspacePath string, w io.Writer) error {
+	if workspacePath == types.EmptyString {
+		return fmt.Errorf("workspace path cannot be empty")
+	}
+	projects := e.workspaceReader.FindProjects(ctx, workspacePath, false, workspace.DefaultVisitPattern)
+	if len(projects) == 0 {
+		return fmt.Errorf("no project found in workspace %s", workspacePath)
+	}
+
+	startTime := time.Now()
+	metadata := &Index{Metadata: &Metadata{
+		Version:              ProtocolVersion,
+		ToolInfo:             &ToolInfo{Name: ToolName, Version: e.ToolVersion},
+		ProjectRoot:          "file://" + filepath.ToSlash(workspacePath),
+		TextDocumentEncoding: TextEncodingUTF8,
+	}}
+	if _, err := w.Write(metadata.Marshal()); err != nil {
+		return fmt.Errorf("failed to write scip metadata: %w", err)
+	}
+
+	total := 0
+	for _, p := range projects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		count, err := e.exportProject(ctx, workspacePath, p, w)
+		if err != nil {
+			return fmt.Errorf("failed to export project %s: %w", p.Path, err)
+		}
+		total += count
+	}
+	e.logger.Info("export scip for workspace %s cost %d ms, projects %d, documents %d",
+		workspacePath, time.Since(startTime).Milliseconds(), len(projects), total)
+	return nil
+}
+
+// exportProject 导出单个项目，返回写出的文档数
+func (e *Exporter) exportProject(ctx context.Context, workspacePath string, project *workspace.Project, w io.Writer) (int, error) {
+	resolver := &symbolResolver{
+		exporter:      e,
+		workspacePath: workspacePath,
+		project:       project,
+		cache:         make(map[string]string),
+	}
+	count := 0
+	err := store.ForEachElementTable(ctx, e.storage, project.Uuid,
+		func(_ store.ElementPathKey, elementTable *codegraphpb.FileElementTable) error {
+			doc := resolver.buildDocument(ctx, elementTable)
+			if len(doc.Occurrences) == 0 {
+				return nil
+			}
+			if _, err := w.Write((&Index{Documents: []*Document{doc}}).Marshal()); err != nil {
+				return err
+			}
+			count++
+			return nil
+		})
+	var corrupted *store.CorruptedValueError
+	if errors.As(err, &corrupted) {
+		// 损坏的元素表跳过，不影响其余文档导出
+		e.logger.Error("failed to unmarshal file element_table value, err: %v", err)
+		return count, nil
+	}
+	return count, err
+}
+
+// symbolResolver 为项目内的元素生成 moniker，并将引用解析到定义
+type symbolResolver struct {
+	exporter      *Exporter
+	workspacePath string
+	project       *workspace.Project
+	// cache language:name -> 定义的 moniker，未解析到时为空串
+	cache map[string]string
+}
+
+// buildDocument 将单个文件的元素表转换为 SCIP 文档
+func (r *symbolResolver) buildDocument(ctx context.Context, elementTable *codegraphpb.FileElementTable) *Document {
+	doc := &Document{
+		RelativePath: r.relativePath(elementTable.Path),
+		Language:     elementTable.Language,
+	}
+	for _, element := range elementTable.Elements {
+		if len(element.Range) != 4 || element.Name == types.EmptyString {
+			continue
+		}
+		if element.IsDefinition {
+			kind, ok := symbolKind(element.ElementType)
+			if !ok {
+				continue
+			}
+			symbol := r.moniker(elementTable.Language, elementTable.Path, element.Name, element.ElementType, element.Range)
+			doc.Occurrences = append(doc.Occurrences, &Occurrence{
+				Range:       toSCIPRange(element.Range),
+				Symbol:      symbol,
+				SymbolRoles: SymbolRoleDefinition,
+			})
+			doc.Symbols = append(doc.Symbols, &SymbolInformation{
+				Symbol:      symbol,
+				Kind:        kind,
+				DisplayName: element.Name,
+			})
+			continue
+		}
+		switch element.ElementType {
+		case codegraphpb.ElementType_CALL, codegraphpb.ElementType_REFERENCE:
+		default:
+			continue
+		}
+		symbol := r.resolveReference(ctx, elementTable.Language, element.Name)
+		if symbol == types.EmptyString {
+			continue
+		}
+		doc.Occurrences = append(doc.Occurrences, &Occurrence{
+			Range:       toSCIPRange(element.Range),
+			Symbol:      symbol,
+			SymbolRoles: SymbolRoleReadAccess,
+		})
+	}
+	return doc
+}
+
+// resolveReference 通过 SymbolOccurrence 查找引用指向的定义，有多个定义时取第一个
+func (r *symbolResolver) resolveReference(ctx context.Context, language string, name string) string {
+	cacheKey := language + ":" + name
+	if symbol, ok := r.cache[cacheKey]; ok {
+		return symbol
+	}
+	symbol := types.EmptyString
+	bytes, err := r.exporter.storage.Get(ctx, r.project.Uuid, store.SymbolNameKey{Language: lang.Language(language), Name: name})
+	if err == nil {
+		var occurrence codegraphpb.SymbolOccurrence
+		if err = store.UnmarshalValue(bytes, &occurrence); err == nil {
+			for _, o := range occurrence.Occurrences {
+				if _, ok := symbolKind(o.ElementType); ok && len(o.Range) == 4 {
+					symbol = r.moniker(language, o.Path, name, o.ElementType, o.Range)
+					break
+				}
+			}
+		}
+	}
+	r.cache[cacheKey] = symbol
+	return symbol
+}
+
+// moniker 由语言、路径、名称、范围合成稳定的 SCIP 符号，格式：
+// codebase-indexer <language> <project> . `<relative path>`/`<name>@<line>:<column>`<suffix>
+func (r *symbolResolver) moniker(language string, filePath string, name string,
+	elementType codegraphpb.ElementType, ranges []int32) string {
+	descriptor := escapeDescriptor(fmt.Sprintf("%s@%d:%d", name, ranges[0], ranges[1]))
+	return strings.Join([]string{
+		ToolName,
+		escapePackagePart(language),
+		escapePackagePart(r.project.Name),
+		packageVersion,
+		escapeDescriptor(r.relativePath(filePath)) + "/" + descriptor + descriptorSuffix(elementType),
+	}, " ")
+}
+
+// relativePath 返回相对工作区的路径，统一使用 / 分隔
+func (r *symbolResolver) relativePath(filePath string) string {
+	rel, err := filepath.Rel(r.workspacePath, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filePath
+	}
+	return filepath.ToSlash(rel)
+}
+
+// symbolKind 元素类型映射为 SCIP 符号种类，返回 false 表示不导出该类型的定义
+func symbolKind(elementType codegraphpb.ElementType) (int32, bool) {
+	switch elementType {
+	case codegraphpb.ElementType_FUNCTION:
+		return SymbolKindFunction, true
+	case codegraphpb.ElementType_METHOD:
+		return SymbolKindMethod, true
+	case codegraphpb.ElementType_CLASS:
+		return SymbolKindClass, true
+	case codegraphpb.ElementType_INTERFACE:
+		return SymbolKindInterface, true
+	case codegraphpb.ElementType_VARIABLE:
+		return SymbolKindVariable, true
+	default:
+		return SymbolKindUnspecified, false
+	}
+}
+
+// descriptorSuffix 按 SCIP 描述符语法区分类型、方法和term
+func descriptorSuffix(elementType codegraphpb.ElementType) string {
+	switch elementType {
+	case codegraphpb.ElementType_CLASS, codegraphpb.ElementType_INTERFACE:
+		return "#"
+	case codegraphpb.ElementType_FUNCTION, codegraphpb.ElementType_METHOD:
+		return "()."
+	default:
+		return "."
+	}
+}
+
+// toSCIPRange 转换为 SCIP 范围，单行时使用三元素形式 [line, startColumn, endColumn]
+func toSCIPRange(ranges []int32) []int32 {
+	if ranges[0] == ranges[2] {
+		return []int32{ranges[0], ranges[1], ranges[3]}
+	}
+	return []int32{ranges[0], ranges[1], ranges[2], ranges[3]}
+}
+
+// escapeDescriptor 非简单标识符使用反引号包裹，内部反引号双写转义
+func escapeDescriptor(name string) string {
+	if simpleDescriptorRegex.MatchString(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// escapePackagePart 包字段以空格分隔，内部空格双写转义，空值使用占位符
+func escapePackagePart(s string) string {
+	if s == types.EmptyString {
+		return packageVersion
+	}
+	return strings.ReplaceAll(s, " ", "  ")
+}
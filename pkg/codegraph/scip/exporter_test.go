@@ -0,0 +1,95 @@
+package scip
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/store"
+	"codebase-indexer/pkg/codegraph/workspace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSCIP(t *testing.T) {
+	ctx := context.Background()
+	logger := &store.MockLogger{}
+	storage, err := store.NewLevelDBStorage(t.TempDir(), logger)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	workspaceDir := t.TempDir()
+	project := workspace.NewProject(filepath.Base(workspaceDir), workspaceDir)
+
+	serviceFile := filepath.Join(workspaceDir, "service", "user.go")
+	mainFile := filepath.Join(workspaceDir, "main.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(serviceFile), 0755))
+	require.NoError(t, os.WriteFile(serviceFile, []byte("package service\n\nfunc GetUser() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(mainFile, []byte("package main\n\nfunc main() {\n\tservice.GetUser()\n}\n"), 0644))
+
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key: store.ElementPathKey{Language: lang.Go, Path: serviceFile},
+		Value: &codegraphpb.FileElementTable{Path: serviceFile, Language: string(lang.Go), Elements: []*codegraphpb.Element{
+			{Name: "GetUser", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 2, 18}},
+		}},
+	}))
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key: store.ElementPathKey{Language: lang.Go, Path: mainFile},
+		Value: &codegraphpb.FileElementTable{Path: mainFile, Language: string(lang.Go), Elements: []*codegraphpb.Element{
+			{Name: "main", IsDefinition: true, ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{2, 0, 4, 1}},
+			{Name: "GetUser", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 1, 3, 18}},
+			// 未索引的外部符号不导出
+			{Name: "Println", ElementType: codegraphpb.ElementType_CALL, Range: []int32{3, 1, 3, 12}},
+		}},
+	}))
+	require.NoError(t, storage.Put(ctx, project.Uuid, &store.Entry{
+		Key: store.SymbolNameKey{Language: lang.Go, Name: "GetUser"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "GetUser", Language: string(lang.Go), Occurrences: []*codegraphpb.Occurrence{
+			{Path: serviceFile, Range: []int32{2, 0, 2, 18}, ElementType: codegraphpb.ElementType_FUNCTION},
+		}},
+	}))
+
+	var buf bytes.Buffer
+	exporter := NewExporter(workspace.NewWorkSpaceReader(logger), storage, logger)
+	require.NoError(t, exporter.ExportSCIP(ctx, workspaceDir, &buf))
+
+	index, err := Unmarshal(buf.Bytes())
+	require.NoError(t, err)
+	require.NotNil(t, index.Metadata)
+	assert.Equal(t, ToolName, index.Metadata.ToolInfo.Name)
+	assert.Equal(t, TextEncodingUTF8, index.Metadata.TextDocumentEncoding)
+	require.Len(t, index.Documents, 2)
+
+	docs := make(map[string]*Document)
+	for _, d := range index.Documents {
+		docs[d.RelativePath] = d
+	}
+	serviceDoc := docs["service/user.go"]
+	mainDoc := docs["main.go"]
+	require.NotNil(t, serviceDoc)
+	require.NotNil(t, mainDoc)
+
+	// 定义：单行范围转换为三元素形式
+	require.Len(t, serviceDoc.Occurrences, 1)
+	definition := serviceDoc.Occurrences[0]
+	assert.Equal(t, []int32{2, 0, 18}, definition.Range)
+	assert.Equal(t, SymbolRoleDefinition, definition.SymbolRoles)
+	assert.True(t, strings.HasPrefix(definition.Symbol, "codebase-indexer go "))
+	assert.True(t, strings.HasSuffix(definition.Symbol, "`service/user.go`/`GetUser@2:0`()."))
+	require.Len(t, serviceDoc.Symbols, 1)
+	assert.Equal(t, SymbolKindFunction, serviceDoc.Symbols[0].Kind)
+	assert.Equal(t, "GetUser", serviceDoc.Symbols[0].DisplayName)
+
+	// 引用指向同一个 moniker
+	require.Len(t, mainDoc.Occurrences, 2)
+	reference := mainDoc.Occurrences[1]
+	assert.Equal(t, definition.Symbol, reference.Symbol)
+	assert.Equal(t, SymbolRoleReadAccess, reference.SymbolRoles)
+	assert.Equal(t, []int32{2, 0, 4, 1}, mainDoc.Occurrences[0].Range)
+}
@@ -0,0 +1,327 @@
+// Package scip 将索引数据导出为 SCIP（SCIP Code Intelligence Protocol）格式，
+// 便于外部代码导航工具复用。消息字段编号与 scip.proto 保持一致。
+package scip
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ProtocolVersion SCIP 协议版本
+const ProtocolVersion int32 = 0
+
+// TextEncodingUTF8 文档编码 UTF8
+const TextEncodingUTF8 int32 = 1
+
+// SymbolRole 符号在出现位置的角色，位掩码
+const (
+	SymbolRoleDefinition  int32 = 0x1
+	SymbolRoleImport      int32 = 0x2
+	SymbolRoleWriteAccess int32 = 0x4
+	SymbolRoleReadAccess  int32 = 0x8
+)
+
+// SymbolKind 符号种类，取值与 scip.proto 中 SymbolInformation.Kind 一致
+const (
+	SymbolKindUnspecified int32 = 0
+	SymbolKindClass       int32 = 7
+	SymbolKindFunction    int32 = 17
+	SymbolKindInterface   int32 = 21
+	SymbolKindMethod      int32 = 26
+	SymbolKindPackage     int32 = 35
+	SymbolKindVariable    int32 = 61
+)
+
+// Index scip.Index
+type Index struct {
+	Metadata  *Metadata
+	Documents []*Document
+}
+
+// Metadata scip.Metadata
+type Metadata struct {
+	Version              int32
+	ToolInfo             *ToolInfo
+	ProjectRoot          string
+	TextDocumentEncoding int32
+}
+
+// ToolInfo scip.ToolInfo
+type ToolInfo struct {
+	Name      string
+	Version   string
+	Arguments []string
+}
+
+// Document scip.Document
+type Document struct {
+	RelativePath string
+	Language     string
+	Occurrences  []*Occurrence
+	Symbols      []*SymbolInformation
+}
+
+// Occurrence scip.Occurrence
+type Occurrence struct {
+	Range       []int32
+	Symbol      string
+	SymbolRoles int32
+}
+
+// SymbolInformation scip.SymbolInformation
+type SymbolInformation struct {
+	Symbol      string
+	Kind        int32
+	DisplayName string
+}
+
+// Marshal 编码为 protobuf 二进制
+func (x *Index) Marshal() []byte {
+	var b []byte
+	if x.Metadata != nil {
+		b = appendMessage(b, 1, x.Metadata.marshal())
+	}
+	for _, d := range x.Documents {
+		b = appendMessage(b, 2, d.marshal())
+	}
+	return b
+}
+
+func (x *Metadata) marshal() []byte {
+	var b []byte
+	b = appendVarint(b, 1, int64(x.Version))
+	if x.ToolInfo != nil {
+		b = appendMessage(b, 2, x.ToolInfo.marshal())
+	}
+	b = appendString(b, 3, x.ProjectRoot)
+	b = appendVarint(b, 4, int64(x.TextDocumentEncoding))
+	return b
+}
+
+func (x *ToolInfo) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, x.Name)
+	b = appendString(b, 2, x.Version)
+	for _, a := range x.Arguments {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, a)
+	}
+	return b
+}
+
+func (x *Document) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, x.RelativePath)
+	for _, o := range x.Occurrences {
+		b = appendMessage(b, 2, o.marshal())
+	}
+	for _, s := range x.Symbols {
+		b = appendMessage(b, 3, s.marshal())
+	}
+	b = appendString(b, 4, x.Language)
+	return b
+}
+
+func (x *Occurrence) marshal() []byte {
+	var b []byte
+	if len(x.Range) > 0 {
+		var packed []byte
+		for _, r := range x.Range {
+			packed = protowire.AppendVarint(packed, uint64(int64(r)))
+		}
+		b = appendMessage(b, 1, packed)
+	}
+	b = appendString(b, 2, x.Symbol)
+	b = appendVarint(b, 3, int64(x.SymbolRoles))
+	return b
+}
+
+func (x *SymbolInformation) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, x.Symbol)
+	b = appendVarint(b, 5, int64(x.Kind))
+	b = appendString(b, 6, x.DisplayName)
+	return b
+}
+
+func appendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// Unmarshal 解析 SCIP 索引，未识别的字段忽略。多个 Index 消息拼接后按 protobuf 合并语义解析
+func Unmarshal(b []byte) (*Index, error) {
+	index := &Index{}
+	err := walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			m, err := unmarshalMetadata(v)
+			if err != nil {
+				return err
+			}
+			index.Metadata = m
+		case num == 2 && typ == protowire.BytesType:
+			d, err := unmarshalDocument(v)
+			if err != nil {
+				return err
+			}
+			index.Documents = append(index.Documents, d)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func unmarshalMetadata(b []byte) (*Metadata, error) {
+	m := &Metadata{}
+	err := walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			m.Version = int32(n)
+		case num == 2 && typ == protowire.BytesType:
+			t := &ToolInfo{}
+			if err := walkFields(v, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+				if typ != protowire.BytesType {
+					return nil
+				}
+				switch num {
+				case 1:
+					t.Name = string(v)
+				case 2:
+					t.Version = string(v)
+				case 3:
+					t.Arguments = append(t.Arguments, string(v))
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			m.ToolInfo = t
+		case num == 3 && typ == protowire.BytesType:
+			m.ProjectRoot = string(v)
+		case num == 4 && typ == protowire.VarintType:
+			m.TextDocumentEncoding = int32(n)
+		}
+		return nil
+	})
+	return m, err
+}
+
+func unmarshalDocument(b []byte) (*Document, error) {
+	d := &Document{}
+	err := walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			d.RelativePath = string(v)
+		case 2:
+			o, err := unmarshalOccurrence(v)
+			if err != nil {
+				return err
+			}
+			d.Occurrences = append(d.Occurrences, o)
+		case 3:
+			s, err := unmarshalSymbolInformation(v)
+			if err != nil {
+				return err
+			}
+			d.Symbols = append(d.Symbols, s)
+		case 4:
+			d.Language = string(v)
+		}
+		return nil
+	})
+	return d, err
+}
+
+func unmarshalOccurrence(b []byte) (*Occurrence, error) {
+	o := &Occurrence{}
+	err := walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			for len(v) > 0 {
+				r, l := protowire.ConsumeVarint(v)
+				if l < 0 {
+					return protowire.ParseError(l)
+				}
+				o.Range = append(o.Range, int32(r))
+				v = v[l:]
+			}
+		case num == 1 && typ == protowire.VarintType:
+			o.Range = append(o.Range, int32(n))
+		case num == 2 && typ == protowire.BytesType:
+			o.Symbol = string(v)
+		case num == 3 && typ == protowire.VarintType:
+			o.SymbolRoles = int32(n)
+		}
+		return nil
+	})
+	return o, err
+}
+
+func unmarshalSymbolInformation(b []byte) (*SymbolInformation, error) {
+	s := &SymbolInformation{}
+	err := walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			s.Symbol = string(v)
+		case num == 5 && typ == protowire.VarintType:
+			s.Kind = int32(n)
+		case num == 6 && typ == protowire.BytesType:
+			s.DisplayName = string(v)
+		}
+		return nil
+	})
+	return s, err
+}
+
+// walkFields 逐个遍历消息字段，bytes 类型通过 v 返回，varint 类型通过 n 返回
+func walkFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error) error {
+	for len(b) > 0 {
+		num, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return fmt.Errorf("invalid scip tag: %w", protowire.ParseError(l))
+		}
+		b = b[l:]
+		var v []byte
+		var n uint64
+		switch typ {
+		case protowire.BytesType:
+			v, l = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			n, l = protowire.ConsumeVarint(b)
+		default:
+			l = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if l < 0 {
+			return fmt.Errorf("invalid scip field %d: %w", num, protowire.ParseError(l))
+		}
+		b = b[l:]
+		if err := fn(num, typ, v, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,570 @@
+package store
+
+import (
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"google.golang.org/protobuf/proto"
+)
+
+// badgerDataDir 项目下 badger 数据目录，与 leveldb 的数据目录区分，切换后端时互不影响
+const badgerDataDir = "badger"
+
+// 每个项目一个数据库实例，badger 默认选项面向单个大库（256MB 块缓存、5 个 64MB memtable、1GB 值日志文件），
+// 多个项目同时打开时内存占用过高，按 leveldb 的配置量级调小
+const (
+	badgerBlockCacheSize   = 8 << 20
+	badgerMemTableSize     = 8 << 20
+	badgerNumMemtables     = 2
+	badgerNumCompactors    = 2 // badger 要求至少 2 个
+	badgerValueLogFileSize = 64 << 20
+)
+
+// badgerAccessRecord 记录数据库实例的最后访问时间，超过 InactiveThreshold 未访问时关闭
+type badgerAccessRecord struct {
+	db             *badger.DB
+	lastAccessTime time.Time
+}
+
+// BadgerStorage implements GraphStorage interface using BadgerDB，每个项目一个数据库实例
+type BadgerStorage struct {
+	baseDir       string
+	logger        logger.Logger
+	mu            sync.Mutex
+	clients       map[string]*badgerAccessRecord
+	closed        bool
+	cleanupCancel context.CancelFunc
+	cleanupWG     sync.WaitGroup
+}
+
+// NewBadgerStorage creates new BadgerDB storage instance
+func NewBadgerStorage(baseDir string, logger logger.Logger) (*BadgerStorage, error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create base directory: %w", err)
+	}
+	if err := checkDirWritable(baseDir); err != nil {
+		return nil, fmt.Errorf("directory not writable: %w", err)
+	}
+	logger.Info("badger: initialized successfully baseDir %s", baseDir)
+	storage := &BadgerStorage{
+		baseDir: baseDir,
+		logger:  logger,
+		clients: make(map[string]*badgerAccessRecord),
+	}
+	storage.startCleanupTask()
+	return storage, nil
+}
+
+// badgerOptions 项目数据库选项
+func badgerOptions(dbPath string) badger.Options {
+	return badger.DefaultOptions(dbPath).
+		WithLogger(nil).
+		WithBlockCacheSize(badgerBlockCacheSize).
+		WithMemTableSize(badgerMemTableSize).
+		WithNumMemtables(badgerNumMemtables).
+		WithNumCompactors(badgerNumCompactors).
+		WithValueLogFileSize(badgerValueLogFileSize)
+}
+
+func (s *BadgerStorage) generateDbPath(projectUuid string) string {
+	return filepath.Join(s.baseDir, projectUuid, badgerDataDir)
+}
+
+// getDB gets or creates BadgerDB instance for specified project
+func (s *BadgerStorage) getDB(projectUuid string) (*badger.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, fmt.Errorf("storage is closed")
+	}
+	now := time.Now()
+	if record, ok := s.clients[projectUuid]; ok {
+		record.lastAccessTime = now
+		return record.db, nil
+	}
+	dbPath := s.generateDbPath(projectUuid)
+	if err := os.MkdirAll(dbPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create project directory %s: %w", dbPath, err)
+	}
+	db, err := badger.Open(badgerOptions(dbPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", dbPath, err)
+	}
+	s.clients[projectUuid] = &badgerAccessRecord{db: db, lastAccessTime: now}
+	return db, nil
+}
+
+// cleanupInactiveDBs 关闭 threshold 内未访问的数据库，下次访问时重新打开
+func (s *BadgerStorage) cleanupInactiveDBs(threshold time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	now := time.Now()
+	for projectUuid, record := range s.clients {
+		if now.Sub(record.lastAccessTime) <= threshold {
+			continue
+		}
+		if err := record.db.Close(); err != nil {
+			s.logger.Error("cleanup: failed to close database. project %s, err: %v", projectUuid, err)
+		}
+		delete(s.clients, projectUuid)
+		s.logger.Info("cleanup: closed inactive database. project %s, last access: %v", projectUuid, record.lastAccessTime)
+	}
+}
+
+// startCleanupTask 启动后台清理任务，与 LevelDB 使用相同的检查间隔和不活跃阈值
+func (s *BadgerStorage) startCleanupTask() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cleanupCancel = cancel
+	s.cleanupWG.Add(1)
+	go func() {
+		defer s.cleanupWG.Done()
+		ticker := time.NewTicker(CleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.cleanupInactiveDBs(InactiveThreshold)
+			}
+		}
+	}()
+}
+
+// marshalBadgerValue 序列化存储值，兼容实现了 Marshal 方法的自定义消息
+func marshalBadgerValue(value proto.Message) ([]byte, error) {
+	if customMsg, ok := value.(interface {
+		Marshal() ([]byte, error)
+	}); ok {
+		return customMsg.Marshal()
+	}
+	return proto.Marshal(value)
+}
+
+// BatchSave saves multiple values in batch
+func (s *BadgerStorage) BatchSave(ctx context.Context, projectUuid string, values Entries) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	batch := db.NewWriteBatch()
+	defer batch.Cancel()
+	for i := 0; i < values.Len(); i++ {
+		key, err := values.Key(i).Get()
+		if err != nil {
+			s.logger.Error("badger batch save error:%v", err)
+			continue
+		}
+		data, err := marshalBadgerValue(values.Value(i))
+		if err != nil {
+			s.logger.Error("badger batch save failed to marshal data for key %s, %v", key, err)
+			continue
+		}
+		if err = batch.Set([]byte(key), data); err != nil {
+			return fmt.Errorf("failed to batch save key %s: %w", key, err)
+		}
+	}
+	return batch.Flush()
+}
+
+// Put saves single value
+func (s *BadgerStorage) Put(ctx context.Context, projectUuid string, entry *Entry) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	keyStr, err := entry.Key.Get()
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(entry.Value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data for type %s: %w", keyStr, err)
+	}
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(keyStr), data)
+	})
+}
+
+// Get retrieves data by key
+func (s *BadgerStorage) Get(ctx context.Context, projectUuid string, key Key) ([]byte, error) {
+	if err := utils.CheckContext(ctx); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	keyStr, err := key.Get()
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	err = db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(keyStr))
+		if err != nil {
+			return err
+		}
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get key %s: %w", keyStr, err)
+	}
+	return data, nil
+}
+
+// BatchGet 在同一个只读事务中读取多个 key，返回值与 keys 一一对应，不存在的 key 对应 nil
+func (s *BadgerStorage) BatchGet(ctx context.Context, projectUuid string, keys []Key) ([][]byte, error) {
+	if err := utils.CheckContext(ctx); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	values := make([][]byte, len(keys))
+	err = db.View(func(txn *badger.Txn) error {
+		for i, key := range keys {
+			keyStr, err := key.Get()
+			if err != nil {
+				return err
+			}
+			item, err := txn.Get([]byte(keyStr))
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get key %s: %w", keyStr, err)
+			}
+			if values[i], err = item.ValueCopy(nil); err != nil {
+				return fmt.Errorf("failed to copy value of key %s: %w", keyStr, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (s *BadgerStorage) Exists(ctx context.Context, projectUuid string, key Key) (bool, error) {
+	_, err := s.Get(ctx, projectUuid, key)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Delete deletes data by key
+func (s *BadgerStorage) Delete(ctx context.Context, projectUuid string, key Key) error {
+	return s.BatchDelete(ctx, projectUuid, []Key{key})
+}
+
+// BatchDelete 使用一次 write batch 删除多个 key，不存在的 key 忽略
+func (s *BadgerStorage) BatchDelete(ctx context.Context, projectUuid string, keys []Key) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	batch := db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, key := range keys {
+		keyStr, err := key.Get()
+		if err != nil {
+			return err
+		}
+		if err = batch.Delete([]byte(keyStr)); err != nil {
+			return fmt.Errorf("failed to delete key %s: %w", keyStr, err)
+		}
+	}
+	if err = batch.Flush(); err != nil {
+		return fmt.Errorf("failed to batch delete %d keys: %w", len(keys), err)
+	}
+	return nil
+}
+
+func (s *BadgerStorage) DeleteAll(ctx context.Context, projectUuid string) error {
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		s.logger.Debug("failed to get database. project %s, error: %v", projectUuid, err)
+		return nil
+	}
+	s.logger.Info("start to delete all for project %s", projectUuid)
+	return db.DropAll()
+}
+
+func (s *BadgerStorage) DeleteAllWithPrefix(ctx context.Context, projectUuid string, keyPrefix string) error {
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		s.logger.Debug("failed to get database. project %s, error: %v", projectUuid, err)
+		return nil
+	}
+	if keyPrefix == types.EmptyString {
+		return db.DropAll()
+	}
+	return db.DropPrefix([]byte(keyPrefix))
+}
+
+// Iter creates iterator
+func (s *BadgerStorage) Iter(ctx context.Context, projectUuid string) Iterator {
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		s.logger.Debug("iter: failed to get database. project %s, error: %v", projectUuid, err)
+		return newErrIterator(fmt.Errorf("failed to get database: %w", err))
+	}
+	txn := db.NewTransaction(false)
+	return &badgerIterator{
+		ctx:  ctx,
+		txn:  txn,
+		iter: txn.NewIterator(badger.DefaultIteratorOptions),
+	}
+}
+
+// IterPrefix returns iterator over keys with the given prefix
+func (s *BadgerStorage) IterPrefix(ctx context.Context, projectUuid string, prefix string) Iterator {
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		s.logger.Debug("iter prefix: failed to get database. project %s, error: %v", projectUuid, err)
+		return newErrIterator(fmt.Errorf("failed to get database: %w", err))
+	}
+	txn := db.NewTransaction(false)
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(prefix)
+	return &badgerIterator{
+		ctx:  ctx,
+		txn:  txn,
+		iter: txn.NewIterator(opts),
+	}
+}
+
+// Size returns project data size
+func (s *BadgerStorage) Size(ctx context.Context, projectUuid string, keyPrefix string) int {
+	if err := utils.CheckContext(ctx); err != nil {
+		s.logger.Debug("size: context cancelled. project %s", projectUuid)
+		return 0
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		s.logger.Debug("size: failed to get database. project %s, error:%v", projectUuid, err)
+		return 0
+	}
+	count := 0
+	_ = db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte(keyPrefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// Compact 合并 LSM 树各层并回收值日志中的过期数据
+func (s *BadgerStorage) Compact(ctx context.Context, projectUuid string) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	beforeSize := s.Size(ctx, projectUuid, types.EmptyString)
+	if err = db.Flatten(1); err != nil {
+		return fmt.Errorf("failed to compact project %s: %w", projectUuid, err)
+	}
+	// 每次最多回收一个值日志文件，直到没有可回收的文件
+	for db.RunValueLogGC(0.5) == nil {
+	}
+	s.logger.Info("compact project %s end, size: %d -> %d", projectUuid, beforeSize,
+		s.Size(ctx, projectUuid, types.EmptyString))
+	return nil
+}
+
+// Close closes all database connections
+func (s *BadgerStorage) Close() error {
+	s.cleanupCancel()
+	s.cleanupWG.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var errs []error
+	for projectUuid, record := range s.clients {
+		if err := record.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close project %s database: %w", projectUuid, err))
+		}
+	}
+	s.clients = nil
+	return errors.Join(errs...)
+}
+
+func (s *BadgerStorage) ProjectIndexExists(projectUuid string) (bool, error) {
+	_, err := os.Stat(s.generateDbPath(projectUuid))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("check project index path err: %w", err)
+}
+
+// DiskUsage 返回 LSM 树与 value log 的大小
+func (s *BadgerStorage) DiskUsage(projectUuid string) (int64, error) {
+	exists, err := s.ProjectIndexExists(projectUuid)
+	if err != nil || !exists {
+		return 0, err
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return 0, err
+	}
+	lsm, vlog := db.Size()
+	return lsm + vlog, nil
+}
+
+// ListProjects lists uuids of all projects that have index data in storage
+func (s *BadgerStorage) ListProjects() ([]string, error) {
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("read storage base dir err: %w", err)
+	}
+	var projects []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(s.generateDbPath(entry.Name())); err != nil {
+			continue
+		}
+		projects = append(projects, entry.Name())
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// Ping 从一个项目数据库读取元数据，没有任何项目索引时只检查根目录可读
+func (s *BadgerStorage) Ping(ctx context.Context) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return fmt.Errorf("storage is closed")
+	}
+	projects, err := s.ListProjects()
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		return nil
+	}
+	if _, err = s.Exists(ctx, projects[0], ProjectMetaKey{MetaType: MetaTypeProjectPath}); err != nil {
+		return fmt.Errorf("read project %s index err: %w", projects[0], err)
+	}
+	return nil
+}
+
+// badgerIterator implements Iterator interface，迭代器持有一个只读事务，Close 时释放
+type badgerIterator struct {
+	ctx      context.Context
+	txn      *badger.Txn
+	iter     *badger.Iterator
+	started  bool
+	currentK []byte
+	currentV []byte
+	err      error
+	closed   bool
+}
+
+func (it *badgerIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	select {
+	case <-it.ctx.Done():
+		it.err = it.ctx.Err()
+		return false
+	default:
+	}
+	if !it.started {
+		it.iter.Rewind()
+		it.started = true
+	} else {
+		it.iter.Next()
+	}
+	if !it.iter.Valid() {
+		return false
+	}
+	item := it.iter.Item()
+	it.currentK = item.KeyCopy(nil)
+	it.currentV, it.err = item.ValueCopy(nil)
+	return it.err == nil
+}
+
+func (it *badgerIterator) Key() string {
+	return string(it.currentK)
+}
+
+func (it *badgerIterator) Value() []byte {
+	return it.currentV
+}
+
+func (it *badgerIterator) Error() error {
+	return it.err
+}
+
+func (it *badgerIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.iter.Close()
+	it.txn.Discard()
+	return it.err
+}
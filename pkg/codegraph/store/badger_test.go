@@ -0,0 +1,55 @@
+package store
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadgerStorage_Conformance(t *testing.T) {
+	runStorageConformance(t, func(t *testing.T) GraphStorage {
+		storage, err := NewGraphStorage(BackendBadger, t.TempDir(), &MockLogger{})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = storage.Close() })
+		return storage
+	})
+}
+
+func TestBadgerOptions(t *testing.T) {
+	opts := badgerOptions(t.TempDir())
+	assert.Equal(t, int64(badgerBlockCacheSize), opts.BlockCacheSize)
+	assert.Equal(t, int64(badgerMemTableSize), opts.MemTableSize)
+	assert.Equal(t, badgerNumMemtables, opts.NumMemtables)
+	assert.Equal(t, badgerNumCompactors, opts.NumCompactors)
+	assert.Equal(t, int64(badgerValueLogFileSize), opts.ValueLogFileSize)
+}
+
+func TestBadgerStorage_CleanupInactiveDBs(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewBadgerStorage(t.TempDir(), &MockLogger{})
+	require.NoError(t, err)
+	defer storage.Close()
+
+	key := SymbolNameKey{Language: lang.Go, Name: "Foo"}
+	for _, projectUuid := range []string{"idle", "active"} {
+		require.NoError(t, storage.Put(ctx, projectUuid, &Entry{Key: key, Value: &codegraphpb.SymbolOccurrence{Name: "Foo"}}))
+	}
+	storage.mu.Lock()
+	storage.clients["idle"].lastAccessTime = time.Now().Add(-time.Hour)
+	storage.mu.Unlock()
+
+	storage.cleanupInactiveDBs(time.Minute)
+	storage.mu.Lock()
+	assert.NotContains(t, storage.clients, "idle")
+	assert.Contains(t, storage.clients, "active")
+	storage.mu.Unlock()
+
+	// 关闭的数据库在下次访问时重新打开，数据不丢失
+	_, err = storage.Get(ctx, "idle", key)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,237 @@
+package store
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// runStorageConformance 所有 GraphStorage 后端都需要满足的行为，新增后端时用该函数验证
+func runStorageConformance(t *testing.T, newStorage func(t *testing.T) GraphStorage) {
+	ctx := context.Background()
+	pathKey := ElementPathKey{Language: lang.Go, Path: "/project/main.go"}
+	symKey := SymbolNameKey{Language: lang.Go, Name: "Handle"}
+	calleeKey := CalleeMapKey{SymbolName: "Handle"}
+	metaKey := ProjectMetaKey{MetaType: MetaTypeProjectPath}
+
+	// seed 写入每种 key 各一个，覆盖 Put 和 BatchSave 两种写入方式
+	seed := func(t *testing.T, storage GraphStorage, projectUuid string) {
+		require.NoError(t, storage.Put(ctx, projectUuid, &Entry{Key: pathKey, Value: &codegraphpb.FileElementTable{Path: pathKey.Path}}))
+		require.NoError(t, storage.BatchSave(ctx, projectUuid, CreateTestValues(
+			[]proto.Message{
+				&codegraphpb.SymbolOccurrence{Name: symKey.Name},
+				&codegraphpb.CalleeMapItem{CalleeName: calleeKey.SymbolName},
+				&codegraphpb.TestMessage{Value: "/project"},
+			},
+			[]Key{symKey, calleeKey, metaKey},
+		)))
+	}
+
+	t.Run("读写和删除", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+
+		data, err := storage.Get(ctx, "p1", pathKey)
+		require.NoError(t, err)
+		var table codegraphpb.FileElementTable
+		require.NoError(t, UnmarshalValue(data, &table))
+		assert.Equal(t, pathKey.Path, table.Path)
+
+		exists, err := storage.Exists(ctx, "p1", symKey)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		require.NoError(t, storage.Delete(ctx, "p1", symKey))
+		_, err = storage.Get(ctx, "p1", symKey)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		exists, err = storage.Exists(ctx, "p1", symKey)
+		require.NoError(t, err)
+		assert.False(t, exists)
+		// 删除不存在的 key 不报错
+		assert.NoError(t, storage.Delete(ctx, "p1", symKey))
+	})
+
+	t.Run("批量读取", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+		values, err := storage.BatchGet(ctx, "p1", []Key{symKey, ElementPathKey{Language: lang.Go, Path: "/missing.go"}, pathKey})
+		require.NoError(t, err)
+		require.Len(t, values, 3)
+		var sym codegraphpb.SymbolOccurrence
+		require.NoError(t, UnmarshalValue(values[0], &sym))
+		assert.Equal(t, symKey.Name, sym.Name)
+		assert.Nil(t, values[1])
+		var table codegraphpb.FileElementTable
+		require.NoError(t, UnmarshalValue(values[2], &table))
+		assert.Equal(t, pathKey.Path, table.Path)
+
+		values, err = storage.BatchGet(ctx, "p1", nil)
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("批量删除", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+		require.NoError(t, storage.BatchDelete(ctx, "p1", []Key{pathKey, calleeKey, ElementPathKey{Language: lang.Go, Path: "/missing.go"}}))
+		assert.Equal(t, 2, storage.Size(ctx, "p1", ""))
+		assert.NoError(t, storage.BatchDelete(ctx, "p1", nil))
+	})
+
+	t.Run("迭代和key前缀判断", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+
+		iter := storage.Iter(ctx, "p1")
+		var paths, syms, callees, metas int
+		for iter.Next() {
+			key := iter.Key()
+			assert.NotEmpty(t, iter.Value())
+			switch {
+			case IsElementPathKey(key):
+				paths++
+			case IsSymbolNameKey(key):
+				syms++
+			case IsCalleeMapKey(key):
+				callees++
+			case IsProjectMetaKey(key):
+				metas++
+			}
+		}
+		require.NoError(t, iter.Error())
+		require.NoError(t, iter.Close())
+		assert.Equal(t, []int{1, 1, 1, 1}, []int{paths, syms, callees, metas})
+
+		assert.Equal(t, 4, storage.Size(ctx, "p1", ""))
+		assert.Equal(t, 1, storage.Size(ctx, "p1", PathKeySystemPrefix))
+		assert.Equal(t, 1, storage.Size(ctx, "p1", CalleeMapKeySystemPrefix))
+	})
+
+	t.Run("按前缀迭代", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+
+		iter := storage.IterPrefix(ctx, "p1", CalleeMapKeySystemPrefix)
+		var keys []string
+		for iter.Next() {
+			keys = append(keys, iter.Key())
+		}
+		require.NoError(t, iter.Error())
+		require.NoError(t, iter.Close())
+		wantKey, err := calleeKey.Get()
+		require.NoError(t, err)
+		assert.Equal(t, []string{wantKey}, keys)
+	})
+
+	t.Run("按前缀删除", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+		require.NoError(t, storage.DeleteAllWithPrefix(ctx, "p1", CalleeMapKeySystemPrefix))
+		assert.Equal(t, 0, storage.Size(ctx, "p1", CalleeMapKeySystemPrefix))
+		assert.Equal(t, 3, storage.Size(ctx, "p1", ""))
+	})
+
+	t.Run("删除项目全部数据", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+		seed(t, storage, "p2")
+		require.NoError(t, storage.DeleteAll(ctx, "p1"))
+		assert.Equal(t, 0, storage.Size(ctx, "p1", ""))
+		assert.Equal(t, 4, storage.Size(ctx, "p2", ""))
+	})
+
+	t.Run("不同分隔符的路径对应同一个key", func(t *testing.T) {
+		storage := newStorage(t)
+		windowsKey := ElementPathKey{Language: lang.Go, Path: `C:\project\pkg\main.go`}
+		posixKey := ElementPathKey{Language: lang.Go, Path: "C:/project/pkg/main.go"}
+		require.NoError(t, storage.Put(ctx, "p1", &Entry{Key: windowsKey, Value: &codegraphpb.FileElementTable{Path: posixKey.Path}}))
+
+		_, err := storage.Get(ctx, "p1", posixKey)
+		assert.NoError(t, err)
+		exists, err := storage.Exists(ctx, "p1", windowsKey)
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, 1, storage.Size(ctx, "p1", PathKeySystemPrefix))
+
+		iter := storage.Iter(ctx, "p1")
+		defer iter.Close()
+		require.True(t, iter.Next())
+		key, err := ToElementPathKey(iter.Key())
+		require.NoError(t, err)
+		assert.Equal(t, posixKey.Path, key.Path)
+
+		require.NoError(t, storage.Delete(ctx, "p1", posixKey))
+		assert.Equal(t, 0, storage.Size(ctx, "p1", PathKeySystemPrefix))
+	})
+
+	t.Run("项目隔离和项目列表", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+		seed(t, storage, "p2")
+
+		require.NoError(t, storage.Delete(ctx, "p2", pathKey))
+		_, err := storage.Get(ctx, "p1", pathKey)
+		assert.NoError(t, err)
+
+		exists, err := storage.ProjectIndexExists("p1")
+		require.NoError(t, err)
+		assert.True(t, exists)
+		exists, err = storage.ProjectIndexExists("missing")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		projects, err := storage.ListProjects()
+		require.NoError(t, err)
+		sort.Strings(projects)
+		assert.Equal(t, []string{"p1", "p2"}, projects)
+	})
+
+	t.Run("关闭后操作报错", func(t *testing.T) {
+		storage := newStorage(t)
+		seed(t, storage, "p1")
+		require.NoError(t, PingStorage(ctx, storage))
+		require.NoError(t, storage.Close())
+		assert.Error(t, PingStorage(ctx, storage))
+		assert.Error(t, storage.Put(ctx, "p1", &Entry{Key: pathKey, Value: &codegraphpb.FileElementTable{}}))
+		assert.NoError(t, storage.Close())
+	})
+}
+
+func TestLevelDBStorage_Conformance(t *testing.T) {
+	runStorageConformance(t, func(t *testing.T) GraphStorage {
+		storage, err := NewGraphStorage(BackendLevelDB, t.TempDir(), &MockLogger{})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = storage.Close() })
+		return storage
+	})
+}
+
+func TestNewGraphStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend StorageBackend
+		wantErr bool
+	}{
+		{name: "默认使用leveldb", backend: ""},
+		{name: "忽略大小写", backend: "LevelDB"},
+		{name: "不支持的后端", backend: "rocksdb", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := NewGraphStorage(tt.backend, t.TempDir(), &MockLogger{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer storage.Close()
+			assert.IsType(t, &LevelDBStorage{}, storage)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"codebase-indexer/pkg/codegraph/utils"
+	"codebase-indexer/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ExternalIndex 只读的外部索引目录（如集中构建的公共库索引），目录结构与本地索引一致：<dir>/<projectUuid>/data。
+// 以只读方式打开，不会修改或锁定写入外部索引
+type ExternalIndex struct {
+	dir      string
+	logger   logger.Logger
+	mu       sync.RWMutex // 保护 Close 与查询并发
+	projects []string     // 按 uuid 排序，保证查询结果稳定
+	dbs      map[string]*leveldb.DB
+}
+
+// OpenExternalIndex 打开外部索引目录下的所有项目索引，无法打开或 schema 版本与当前不一致的项目跳过
+func OpenExternalIndex(dir string, logger logger.Logger) (*ExternalIndex, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read external index dir %s: %w", dir, err)
+	}
+	e := &ExternalIndex{
+		dir:    dir,
+		logger: logger,
+		dbs:    make(map[string]*leveldb.DB),
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dbPath := filepath.Join(dir, entry.Name(), dataDir)
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+		db, err := leveldb.OpenFile(dbPath, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+		if err != nil {
+			logger.Warn("external_index: failed to open project %s in %s, err: %v", entry.Name(), dir, err)
+			continue
+		}
+		// key 格式或值结构不一致时查询结果不可信，未记录版本的旧索引同样跳过
+		if version := externalSchemaVersion(db); version != CurrentSchemaVersion {
+			logger.Warn("external_index: skip project %s in %s, schema version %q does not match current %q",
+				entry.Name(), dir, version, CurrentSchemaVersion)
+			_ = db.Close()
+			continue
+		}
+		e.dbs[entry.Name()] = db
+		e.projects = append(e.projects, entry.Name())
+	}
+	sort.Strings(e.projects)
+	logger.Info("external_index: opened %d projects in %s", len(e.projects), dir)
+	return e, nil
+}
+
+// externalSchemaVersion 读取项目索引记录的 schema 版本，未记录或读取失败时返回空串
+func externalSchemaVersion(db *leveldb.DB) string {
+	key, err := ProjectMetaKey{MetaType: MetaTypeSchemaVersion}.Get()
+	if err != nil {
+		return ""
+	}
+	data, err := db.Get([]byte(key), nil)
+	if err != nil {
+		return ""
+	}
+	var version wrapperspb.StringValue
+	if err = UnmarshalValue(data, &version); err != nil {
+		return ""
+	}
+	return version.GetValue()
+}
+
+// Dir 外部索引目录
+func (e *ExternalIndex) Dir() string {
+	return e.dir
+}
+
+// GetAll 在所有项目中查找 key，返回每个命中项目的值，均未命中时返回 ErrKeyNotFound
+func (e *ExternalIndex) GetAll(ctx context.Context, key Key) ([][]byte, error) {
+	if err := utils.CheckContext(ctx); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+	keyStr, err := key.Get()
+	if err != nil {
+		return nil, err
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var values [][]byte
+	for _, projectUuid := range e.projects {
+		data, err := e.dbs[projectUuid].Get([]byte(keyStr), nil)
+		if errors.Is(err, leveldb.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return values, fmt.Errorf("failed to get key %s from external project %s: %w", keyStr, projectUuid, err)
+		}
+		values = append(values, data)
+	}
+	if len(values) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	return values, nil
+}
+
+// Close 关闭所有项目索引
+func (e *ExternalIndex) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var errs []error
+	for _, db := range e.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.dbs = make(map[string]*leveldb.DB)
+	e.projects = nil
+	return errors.Join(errs...)
+}
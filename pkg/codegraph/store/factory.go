@@ -0,0 +1,71 @@
+package store
+
+import (
+	"codebase-indexer/pkg/logger"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StorageBackend 索引存储后端类型
+type StorageBackend string
+
+const (
+	// BackendLevelDB 默认存储后端
+	BackendLevelDB StorageBackend = "leveldb"
+	// BackendBadger BadgerDB 存储后端，写放大更小
+	BackendBadger StorageBackend = "badger"
+)
+
+// StorageBackendEnv 选择索引存储后端的环境变量，未设置时使用 leveldb
+const StorageBackendEnv = "STORAGE_BACKEND"
+
+// StorageFactory 创建存储实例，baseDir 为所有项目索引的根目录
+type StorageFactory func(baseDir string, logger logger.Logger) (GraphStorage, error)
+
+var (
+	storageFactoriesMu sync.RWMutex
+	storageFactories   = map[StorageBackend]StorageFactory{
+		BackendLevelDB: func(baseDir string, logger logger.Logger) (GraphStorage, error) {
+			return NewLevelDBStorage(baseDir, logger)
+		},
+		BackendBadger: func(baseDir string, logger logger.Logger) (GraphStorage, error) {
+			return NewBadgerStorage(baseDir, logger)
+		},
+	}
+)
+
+// RegisterStorageBackend 注册存储后端，同名后端会被覆盖
+func RegisterStorageBackend(backend StorageBackend, factory StorageFactory) {
+	storageFactoriesMu.Lock()
+	defer storageFactoriesMu.Unlock()
+	storageFactories[backend] = factory
+}
+
+// NewGraphStorage 根据后端类型创建存储，backend 为空时使用 leveldb
+func NewGraphStorage(backend StorageBackend, baseDir string, logger logger.Logger) (GraphStorage, error) {
+	backend = StorageBackend(strings.ToLower(strings.TrimSpace(string(backend))))
+	if backend == "" {
+		backend = BackendLevelDB
+	}
+	storageFactoriesMu.RLock()
+	factory, ok := storageFactories[backend]
+	storageFactoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported storage backend %q, available: %v", backend, AvailableStorageBackends())
+	}
+	return factory(baseDir, logger)
+}
+
+// AvailableStorageBackends 返回当前二进制中可用的存储后端
+func AvailableStorageBackends() []StorageBackend {
+	storageFactoriesMu.RLock()
+	defer storageFactoriesMu.RUnlock()
+	backends := make([]StorageBackend, 0, len(storageFactories))
+	for backend := range storageFactories {
+		backends = append(backends, backend)
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i] < backends[j] })
+	return backends
+}
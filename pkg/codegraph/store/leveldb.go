@@ -9,12 +9,15 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/util"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
 
 	"codebase-indexer/pkg/logger"
 
@@ -27,42 +30,75 @@ const (
 	InactiveThreshold = 6 * time.Hour
 	// CleanupInterval 定义清理任务的执行间隔
 	CleanupInterval = time.Hour
+	// snapshotRetireDelay 索引副本重新复制后，旧副本延迟关闭，等待正在进行的查询结束
+	snapshotRetireDelay = time.Minute
 )
 
 // dbAccessRecord 记录数据库实例的访问信息
 type dbAccessRecord struct {
 	lastAccessTime time.Time
 	db             *leveldb.DB
+	snapshotDir    string    // 只读实例打开的索引副本目录，直接打开时为空
+	openedAt       time.Time // 打开时间，用于判断索引副本是否过期
 }
 
 // LevelDBStorage implements GraphStorage interface using LevelDB
 type LevelDBStorage struct {
 	baseDir       string
 	logger        logger.Logger
+	options       LevelDBOptions
 	clients       sync.Map // projectUuid -> *dbAccessRecord
+	retired       sync.Map // *dbAccessRecord -> struct{}，等待关闭的过期索引副本
+	instanceLock  storage.Storage
 	closeOnce     sync.Once
 	closed        bool
 	dbMutex       sync.Map // projectUuid -> *sync.Mutex
+	deletedKeys   sync.Map // projectUuid -> *atomic.Int64，上次压缩以来删除的 key 数
 	cleanupCtx    context.Context
 	cleanupCancel context.CancelFunc
 	cleanupWG     sync.WaitGroup
 }
 
-// NewLevelDBStorage creates new LevelDB storage instance
+// NewLevelDBStorage creates new LevelDB storage instance, options are read from environment variables
 func NewLevelDBStorage(baseDir string, logger logger.Logger) (*LevelDBStorage, error) {
-	logger.Info("leveldb: checking base directory baseDir %s", baseDir)
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create base directory: %w", err)
-	}
+	return NewLevelDBStorageWithOptions(baseDir, logger, LevelDBOptionsFromEnv())
+}
+
+// NewLevelDBStorageWithOptions creates new LevelDB storage instance with options
+func NewLevelDBStorageWithOptions(baseDir string, logger logger.Logger, options LevelDBOptions) (*LevelDBStorage, error) {
+	if options.ReadOnly {
+		logger.Info("leveldb: opening read-only baseDir %s", baseDir)
+		if _, err := os.Stat(baseDir); err != nil {
+			return nil, fmt.Errorf("failed to stat base directory: %w", err)
+		}
+	} else {
+		logger.Info("leveldb: checking base directory baseDir %s", baseDir)
+		if err := os.MkdirAll(baseDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create base directory: %w", err)
+		}
 
-	logger.Info("leveldb: checking directory permissions")
-	if err := checkDirWritable(baseDir); err != nil {
-		return nil, fmt.Errorf("directory not writable: %w", err)
+		logger.Info("leveldb: checking directory permissions")
+		if err := checkDirWritable(baseDir); err != nil {
+			return nil, fmt.Errorf("directory not writable: %w", err)
+		}
 	}
 
 	storage := &LevelDBStorage{
 		baseDir: baseDir,
 		logger:  logger,
+		options: options,
+	}
+	if options.ReadOnly {
+		if storage.primaryRunning() {
+			logger.Info("leveldb: read-write instance (pid %d) is running, projects will be opened from snapshots",
+				readLockOwner(filepath.Join(baseDir, instanceLockDir)))
+		}
+	} else {
+		lock, err := storage.acquireInstanceLock()
+		if err != nil {
+			return nil, err
+		}
+		storage.instanceLock = lock
 	}
 
 	// 启动后台清理任务
@@ -90,12 +126,16 @@ func (s *LevelDBStorage) getDB(projectUuid string) (*leveldb.DB, error) {
 
 	if record, exists := s.clients.Load(projectUuid); exists {
 		accessRecord := record.(*dbAccessRecord)
+		if accessRecord.snapshotDir != "" && s.options.SnapshotMaxAge > 0 &&
+			now.Sub(accessRecord.openedAt) > s.options.SnapshotMaxAge {
+			return s.refreshSnapshot(projectUuid, accessRecord, now), nil
+		}
 		// 更新最后访问时间
 		accessRecord.lastAccessTime = now
 		return accessRecord.db, nil
 	}
 
-	db, err := s.createDB(projectUuid)
+	db, snapshotDir, err := s.createDB(projectUuid)
 	if err != nil {
 		return nil, err
 	}
@@ -103,33 +143,98 @@ func (s *LevelDBStorage) getDB(projectUuid string) (*leveldb.DB, error) {
 	accessRecord := &dbAccessRecord{
 		lastAccessTime: now,
 		db:             db,
+		snapshotDir:    snapshotDir,
+		openedAt:       now,
 	}
 
 	actual, loaded := s.clients.LoadOrStore(projectUuid, accessRecord)
 	if loaded {
-		db.Close()
+		s.closeRecord(projectUuid, accessRecord)
 		return actual.(*dbAccessRecord).db, nil
 	}
 
 	return db, nil
 }
 
+// refreshSnapshot 重新复制过期的索引副本，旧副本延迟关闭；复制失败时继续使用旧副本
+func (s *LevelDBStorage) refreshSnapshot(projectUuid string, record *dbAccessRecord, now time.Time) *leveldb.DB {
+	db, snapshotDir, err := openSnapshot(s.generateDbPath(projectUuid))
+	if err != nil {
+		s.logger.Warn("failed to refresh database snapshot, keep the old one. project %s err:%v", projectUuid, err)
+		record.lastAccessTime = now
+		record.openedAt = now
+		return record.db
+	}
+	s.clients.Store(projectUuid, &dbAccessRecord{
+		lastAccessTime: now,
+		db:             db,
+		snapshotDir:    snapshotDir,
+		openedAt:       now,
+	})
+	s.retired.Store(record, struct{}{})
+	time.AfterFunc(snapshotRetireDelay, func() {
+		if _, ok := s.retired.LoadAndDelete(record); ok {
+			s.closeRecord(projectUuid, record)
+		}
+	})
+	s.logger.Debug("refreshed database snapshot. project %s path %s", projectUuid, snapshotDir)
+	return db
+}
+
+// closeRecord 关闭数据库，并删除只读实例的索引副本
+func (s *LevelDBStorage) closeRecord(projectUuid string, record *dbAccessRecord) error {
+	err := record.db.Close()
+	if record.snapshotDir != "" {
+		if removeErr := os.RemoveAll(record.snapshotDir); removeErr != nil {
+			s.logger.Warn("failed to remove database snapshot. project %s path %s err:%v",
+				projectUuid, record.snapshotDir, removeErr)
+		}
+	}
+	return err
+}
+
 func (s *LevelDBStorage) generateDbPath(projectUuid string) string {
 	return filepath.Join(s.baseDir, projectUuid, dataDir)
 }
 
-// createDB creates new LevelDB instance
-func (s *LevelDBStorage) createDB(projectUuid string) (*leveldb.DB, error) {
+// createDB creates new LevelDB instance, snapshotDir 为只读实例打开的索引副本目录
+func (s *LevelDBStorage) createDB(projectUuid string) (db *leveldb.DB, snapshotDir string, err error) {
+	dbPath := s.generateDbPath(projectUuid)
+	if s.options.ReadOnly {
+		return s.openReadOnly(projectUuid, dbPath)
+	}
+	db, err = s.createWritableDB(projectUuid, dbPath)
+	return db, "", err
+}
+
+// openReadOnly 读写实例未运行时直接打开项目数据库，运行时打开数据库副本，避免与读写实例争用锁
+func (s *LevelDBStorage) openReadOnly(projectUuid, dbPath string) (*leveldb.DB, string, error) {
+	if !s.primaryRunning() {
+		s.logger.Info("opening database read-only project %s path %s", projectUuid, dbPath)
+		db, err := openLevelDB(dbPath, true)
+		if err == nil || !isLockError(err) {
+			return db, "", err
+		}
+	}
+	s.logger.Info("opening database snapshot read-only project %s path %s", projectUuid, dbPath)
+	return openSnapshot(dbPath)
+}
+
+func (s *LevelDBStorage) createWritableDB(projectUuid, dbPath string) (*leveldb.DB, error) {
 	s.logger.Info("creating project directory project %s", projectUuid)
 	projectDir := filepath.Join(s.baseDir, projectUuid)
 	if err := os.MkdirAll(projectDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create project directory %s: %w", projectDir, err)
 	}
 
-	dbPath := s.generateDbPath(projectUuid)
 	s.logger.Info("opening database project %s path %s", projectUuid, dbPath)
 
-	db, err := openLevelDB(dbPath)
+	db, err := s.openWithLockRetry(dbPath)
+	if errors.Is(err, ErrDatabaseLocked) {
+		// 其他进程持有锁时数据库是完好的，不能删除重建
+		s.logger.Error("database is locked. project %s err:%v", projectUuid, err)
+		return nil, err
+	}
 	if err != nil {
 		s.logger.Warn("database open failed, attempting to recreate. project %s err:%v", projectUuid, err)
 
@@ -140,21 +245,25 @@ func (s *LevelDBStorage) createDB(projectUuid string) (*leveldb.DB, error) {
 		}
 
 		// 重新尝试创建数据库
-		db, err = openLevelDB(dbPath)
+		db, err = openLevelDB(dbPath, false)
 		if err != nil {
 			return nil, fmt.Errorf("failed to recreate project database %s: %w", dbPath, err)
 		}
 	}
+	if err = writeLockOwner(projectDir); err != nil {
+		s.logger.Debug("failed to record database owner. project %s err:%v", projectUuid, err)
+	}
 
 	s.logger.Debug("created new project database. project %s path %s", projectUuid, dbPath)
 	return db, nil
 }
 
-func openLevelDB(dbPath string) (*leveldb.DB, error) {
+func openLevelDB(dbPath string, readOnly bool) (*leveldb.DB, error) {
 	// 配置LevelDB选项
 	dbOptions := &opt.Options{
 		WriteBuffer:        4 * 1024 * 1024, // 5MB write buffer
 		BlockCacheCapacity: 8 * 1024 * 1024, // 8MB block cache
+		ReadOnly:           readOnly,
 	}
 
 	db, err := leveldb.OpenFile(dbPath, dbOptions)
@@ -265,6 +374,43 @@ func (s *LevelDBStorage) Get(ctx context.Context, projectUuid string, key Key) (
 
 	return data, nil
 }
+
+// BatchGet 在同一个快照上读取多个 key，返回值与 keys 一一对应，不存在的 key 对应 nil
+func (s *LevelDBStorage) BatchGet(ctx context.Context, projectUuid string, keys []Key) ([][]byte, error) {
+	if err := utils.CheckContext(ctx); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	snapshot, err := db.GetSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer snapshot.Release()
+
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		keyStr, err := key.Get()
+		if err != nil {
+			return nil, err
+		}
+		data, err := snapshot.Get([]byte(keyStr), nil)
+		if errors.Is(err, leveldb.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get key %s: %w", keyStr, err)
+		}
+		values[i] = data
+	}
+	return values, nil
+}
+
 func (s *LevelDBStorage) Exists(ctx context.Context, projectUuid string, key Key) (bool, error) {
 	if err := utils.CheckContext(ctx); err != nil {
 		return false, fmt.Errorf("context cancelled: %w", err)
@@ -300,12 +446,86 @@ func (s *LevelDBStorage) Delete(ctx context.Context, projectUuid string, key Key
 	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
 		return fmt.Errorf("failed to delete key %s: %w", keyStr, err)
 	}
+	s.addDeleted(projectUuid, 1)
+
+	return nil
+}
+
+// BatchDelete 使用一次 write batch 删除多个 key，不存在的 key 忽略
+func (s *LevelDBStorage) BatchDelete(ctx context.Context, projectUuid string, keys []Key) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
 
+	batch := new(leveldb.Batch)
+	for _, key := range keys {
+		keyStr, err := key.Get()
+		if err != nil {
+			return err
+		}
+		batch.Delete([]byte(keyStr))
+	}
+	if err = db.Write(batch, nil); err != nil {
+		return fmt.Errorf("failed to batch delete %d keys: %w", len(keys), err)
+	}
+	s.addDeleted(projectUuid, int64(len(keys)))
+	return nil
+}
+
+// addDeleted 累加项目上次压缩以来删除的 key 数
+func (s *LevelDBStorage) addDeleted(projectUuid string, n int64) {
+	counter, _ := s.deletedKeys.LoadOrStore(projectUuid, new(atomic.Int64))
+	counter.(*atomic.Int64).Add(n)
+}
+
+// DeletedSinceCompaction 返回项目自上次压缩（或进程启动）以来删除的 key 数
+func (s *LevelDBStorage) DeletedSinceCompaction(projectUuid string) int64 {
+	counter, ok := s.deletedKeys.Load(projectUuid)
+	if !ok {
+		return 0
+	}
+	return counter.(*atomic.Int64).Load()
+}
+
+// Compact 对项目索引做全范围压缩，清理删除、覆盖写入遗留的旧数据，并记录压缩前后的记录数和磁盘占用
+func (s *LevelDBStorage) Compact(ctx context.Context, projectUuid string) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	start := time.Now()
+	beforeSize := s.Size(ctx, projectUuid, types.EmptyString)
+	beforeUsage, _ := s.DiskUsage(projectUuid)
+	if err = db.CompactRange(util.Range{}); err != nil {
+		return fmt.Errorf("failed to compact project %s: %w", projectUuid, err)
+	}
+	s.deletedKeys.Delete(projectUuid)
+	afterUsage, _ := s.DiskUsage(projectUuid)
+	s.logger.Info("compact project %s end, cost %d ms, size: %d -> %d, disk usage: %d -> %d bytes",
+		projectUuid, time.Since(start).Milliseconds(), beforeSize, s.Size(ctx, projectUuid, types.EmptyString),
+		beforeUsage, afterUsage)
 	return nil
 }
 
 func (s *LevelDBStorage) DeleteAll(ctx context.Context, projectUuid string) error {
+	if s.options.ReadOnly {
+		return ErrReadOnlyStorage
+	}
 	db, err := s.getDB(projectUuid)
+	if errors.Is(err, ErrDatabaseLocked) {
+		// 其他进程持有锁时索引没有被删除，不能当作删除成功
+		return err
+	}
 	if err != nil {
 		s.logger.Debug("failed to get database. project %s, error: %v", projectUuid, err)
 		return nil
@@ -319,12 +539,20 @@ func (s *LevelDBStorage) DeleteAll(ctx context.Context, projectUuid string) erro
 		s.logger.Debug("failed to close iter for project %s, error: %v", projectUuid, err)
 	}
 	err = db.CompactRange(util.Range{})
+	s.deletedKeys.Delete(projectUuid)
 	s.logger.Info("delete all for project %s end, after size: %d", projectUuid,
 		s.Size(ctx, projectUuid, types.EmptyString))
 	return err	
 }
 func (s *LevelDBStorage) DeleteAllWithPrefix(ctx context.Context, projectUuid string, keyPrefix string) error {
+	if s.options.ReadOnly {
+		return ErrReadOnlyStorage
+	}
 	db, err := s.getDB(projectUuid)
+	if errors.Is(err, ErrDatabaseLocked) {
+		// 其他进程持有锁时索引没有被删除，不能当作删除成功
+		return err
+	}
 	if err != nil {
 		s.logger.Debug("failed to get database. project %s, error: %v", projectUuid, err)
 		return nil
@@ -340,6 +568,7 @@ func (s *LevelDBStorage) DeleteAllWithPrefix(ctx context.Context, projectUuid st
 		s.logger.Debug("failed to close iter for project %s, error: %v", projectUuid, err)
 	}
 	err = db.CompactRange(util.Range{})
+	s.deletedKeys.Delete(projectUuid)
 	s.logger.Info("delete all with prefix %s for project %s end, after size: %d", keyPrefix, projectUuid,
 		s.Size(ctx, projectUuid, keyPrefix))
 	return err
@@ -350,7 +579,7 @@ func (s *LevelDBStorage) Iter(ctx context.Context, projectUuid string) Iterator
 	db, err := s.getDB(projectUuid)
 	if err != nil {
 		s.logger.Debug("iter: failed to get database. project %s, error: %v", projectUuid, err)
-		return nil
+		return newErrIterator(fmt.Errorf("failed to get database: %w", err))
 	}
 	return &leveldbIterator{
 		storage:     s,
@@ -361,6 +590,22 @@ func (s *LevelDBStorage) Iter(ctx context.Context, projectUuid string) Iterator
 	}
 }
 
+// IterPrefix returns iterator over keys with the given prefix
+func (s *LevelDBStorage) IterPrefix(ctx context.Context, projectUuid string, prefix string) Iterator {
+	db, err := s.getDB(projectUuid)
+	if err != nil {
+		s.logger.Debug("iter prefix: failed to get database. project %s, error: %v", projectUuid, err)
+		return newErrIterator(fmt.Errorf("failed to get database: %w", err))
+	}
+	return &leveldbIterator{
+		storage:     s,
+		projectUuid: projectUuid,
+		ctx:         ctx,
+		db:          db,
+		iter:        db.NewIterator(util.BytesPrefix([]byte(prefix)), nil),
+	}
+}
+
 // Size returns project data size
 func (s *LevelDBStorage) Size(ctx context.Context, projectUuid string, keyPrefix string) int {
 	if err := utils.CheckContext(ctx); err != nil {
@@ -408,10 +653,9 @@ func (s *LevelDBStorage) Close() error {
 	s.clients.Range(func(key, value interface{}) bool {
 		projectID := key.(string)
 		record := value.(*dbAccessRecord)
-		db := record.db
 
 		s.logger.Info("leveldb_close: closing database. projectUuid %s", projectID)
-		if err := db.Close(); err != nil {
+		if err := s.closeRecord(projectID, record); err != nil {
 			s.logger.Error("leveldb_close: failed to close database. projectUuid %s, err: %v", projectID, err)
 			errs = append(errs, fmt.Errorf("failed to close project %s database: %w", projectID, err))
 		} else {
@@ -419,6 +663,17 @@ func (s *LevelDBStorage) Close() error {
 		}
 		return true
 	})
+	s.retired.Range(func(key, value interface{}) bool {
+		if _, ok := s.retired.LoadAndDelete(key); ok {
+			_ = s.closeRecord(types.EmptyString, key.(*dbAccessRecord))
+		}
+		return true
+	})
+	if s.instanceLock != nil {
+		if err := s.instanceLock.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to release instance lock: %w", err))
+		}
+	}
 
 	s.closeOnce.Do(func() {
 		s.closed = true
@@ -472,7 +727,7 @@ func (s *LevelDBStorage) cleanupInactiveDBs() {
 					// }
 
 					// 关闭数据库连接
-					if err := currentAccessRecord.db.Close(); err != nil {
+					if err := s.closeRecord(projectUuid, currentAccessRecord); err != nil {
 						s.logger.Error("cleanup: failed to close database. project %s, err: %v",
 							projectUuid, err)
 					}
@@ -566,6 +821,32 @@ func (s *LevelDBStorage) stopCleanupTask() {
 	}
 }
 
+// Ping 从一个项目数据库读取元数据，没有任何项目索引时只检查根目录可读
+func (s *LevelDBStorage) Ping(ctx context.Context) error {
+	if err := utils.CheckContext(ctx); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	if s.closed {
+		return fmt.Errorf("storage is closed")
+	}
+	projects, err := s.ListProjects()
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		return nil
+	}
+	if _, err = s.Exists(ctx, projects[0], ProjectMetaKey{MetaType: MetaTypeProjectPath}); err != nil {
+		return fmt.Errorf("read project %s index err: %w", projects[0], err)
+	}
+	return nil
+}
+
+// ReadOnly 存储是否以只读方式打开
+func (s *LevelDBStorage) ReadOnly() bool {
+	return s.options.ReadOnly
+}
+
 func (s *LevelDBStorage) ProjectIndexExists(projectUuid string) (bool, error) {
 	dbPath := s.generateDbPath(projectUuid)
 	// 调用os.Stat获取路径信息
@@ -580,6 +861,93 @@ func (s *LevelDBStorage) ProjectIndexExists(projectUuid string) (bool, error) {
 	return false, fmt.Errorf("check project index path err: %w", err)
 }
 
+// RenameProject 关闭 fromUuid 已打开的数据库后，将其项目目录重命名为 toUuid
+func (s *LevelDBStorage) RenameProject(fromUuid, toUuid string) error {
+	if s.closed {
+		return fmt.Errorf("storage is closed")
+	}
+	if s.options.ReadOnly {
+		return ErrReadOnlyStorage
+	}
+	if fromUuid == toUuid {
+		return nil
+	}
+	// 按固定顺序加锁，避免并发迁移时死锁
+	first, second := fromUuid, toUuid
+	if first > second {
+		first, second = second, first
+	}
+	for _, projectUuid := range []string{first, second} {
+		mutexInterface, _ := s.dbMutex.LoadOrStore(projectUuid, &sync.Mutex{})
+		mutex := mutexInterface.(*sync.Mutex)
+		mutex.Lock()
+		defer mutex.Unlock()
+	}
+
+	if _, loaded := s.clients.Load(toUuid); loaded {
+		return fmt.Errorf("project %s index is in use", toUuid)
+	}
+	targetDir := filepath.Join(s.baseDir, toUuid)
+	if _, err := os.Stat(targetDir); err == nil {
+		return fmt.Errorf("project %s index already exists", toUuid)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("check project %s index dir err: %w", toUuid, err)
+	}
+	if record, loaded := s.clients.LoadAndDelete(fromUuid); loaded {
+		if err := record.(*dbAccessRecord).db.Close(); err != nil {
+			s.logger.Error("rename: failed to close database. project %s, err: %v", fromUuid, err)
+		}
+	}
+	if err := os.Rename(filepath.Join(s.baseDir, fromUuid), targetDir); err != nil {
+		return fmt.Errorf("rename project %s index to %s err: %w", fromUuid, toUuid, err)
+	}
+	s.logger.Info("rename: project index %s renamed to %s", fromUuid, toUuid)
+	return nil
+}
+
+// DiskUsage 统计项目索引目录下所有文件的大小
+func (s *LevelDBStorage) DiskUsage(projectUuid string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(s.generateDbPath(projectUuid), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("stat project %s index disk usage err: %w", projectUuid, err)
+	}
+	return total, nil
+}
+
+// ListProjects lists uuids of all projects that have index data in storage
+func (s *LevelDBStorage) ListProjects() ([]string, error) {
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("read storage base dir err: %w", err)
+	}
+	var projects []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(s.generateDbPath(entry.Name())); err != nil {
+			continue
+		}
+		projects = append(projects, entry.Name())
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
 // leveldbIterator implements Iterator interface
 type leveldbIterator struct {
 	storage     *LevelDBStorage
@@ -591,6 +959,7 @@ type leveldbIterator struct {
 	currentV    []byte
 	err         error
 	closed      bool
+	closeOnce   sync.Once
 }
 
 func (it *leveldbIterator) Next() bool {
@@ -656,20 +1025,19 @@ func (it *leveldbIterator) Error() error {
 	return nil
 }
 
+// Close 释放迭代器，可以重复调用（如 defer 关闭的同时提前显式关闭），只有第一次调用会释放资源并返回错误，之后返回 nil
 func (it *leveldbIterator) Close() error {
-	if it.closed {
-		return nil
-	}
-
-	it.closed = true
 	var err error
-	if it.iter != nil {
-		err = it.iter.Error()
-		it.iter.Release()
-		it.iter = nil
-	}
-	it.currentK = nil
-	it.currentV = nil
-	it.db = nil
+	it.closeOnce.Do(func() {
+		it.closed = true
+		if it.iter != nil {
+			err = it.iter.Error()
+			it.iter.Release()
+			it.iter = nil
+		}
+		it.currentK = nil
+		it.currentV = nil
+		it.db = nil
+	})
 	return err
 }
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+// LevelDB 存储选项的环境变量
+const (
+	LevelDBLockRetriesEnv = "LEVELDB_LOCK_RETRIES" // 数据库被其他进程锁定时的重试次数
+	LevelDBLockBackoffEnv = "LEVELDB_LOCK_BACKOFF" // 第一次重试前的等待时间，之后每次翻倍，如 200ms
+	LevelDBReadOnlyEnv    = "LEVELDB_READ_ONLY"    // 以只读方式打开索引，用于只提供查询的从实例
+	// LevelDBSnapshotMaxAgeEnv 读写实例运行时，只读实例打开的索引副本超过该时间后重新复制，如 5m
+	LevelDBSnapshotMaxAgeEnv = "LEVELDB_SNAPSHOT_MAX_AGE"
+)
+
+const (
+	DefaultLevelDBLockRetries = 3
+	DefaultLevelDBLockBackoff = 200 * time.Millisecond
+	maxLevelDBLockBackoff     = 5 * time.Second
+	// DefaultLevelDBSnapshotMaxAge 只读实例的索引副本默认有效时间
+	DefaultLevelDBSnapshotMaxAge = 5 * time.Minute
+	// instanceLockDir 索引根目录下的实例锁目录，读写实例创建存储时加锁，只读实例据此判断读写实例是否在运行
+	instanceLockDir = ".instance"
+	// lockOwnerFile 记录以读写方式打开索引的进程号，锁冲突时用于提示持有锁的进程
+	lockOwnerFile = "owner.pid"
+	// errSharingViolation Windows 下文件被其他进程占用（ERROR_SHARING_VIOLATION）
+	errSharingViolation syscall.Errno = 32
+)
+
+// ErrDatabaseLocked 数据库被其他进程锁定
+var ErrDatabaseLocked = errors.New("database is locked by another process")
+
+// ErrReadOnlyStorage 只读打开的存储不支持修改索引目录
+var ErrReadOnlyStorage = errors.New("storage is read-only")
+
+// LevelDBOptions LevelDB 存储选项
+type LevelDBOptions struct {
+	// LockRetries 数据库被其他进程锁定时的重试次数，0 表示不重试
+	LockRetries int
+	// LockBackoff 第一次重试前的等待时间，之后每次翻倍，最长 5s
+	LockBackoff time.Duration
+	// ReadOnly 以只读方式打开数据库，用于只提供查询的从实例。读写实例未运行时直接打开项目索引，
+	// 此时随后启动的读写实例无法打开该项目；读写实例运行时复制项目索引到临时目录后打开副本，
+	// 副本超过 SnapshotMaxAge 后重新复制。写入返回 leveldb.ErrReadOnly，不会创建、删除或重命名索引目录
+	ReadOnly bool
+	// SnapshotMaxAge 只读实例的索引副本有效时间，0 表示不重新复制
+	SnapshotMaxAge time.Duration
+}
+
+// DefaultLevelDBOptions 返回默认的 LevelDB 存储选项
+func DefaultLevelDBOptions() LevelDBOptions {
+	return LevelDBOptions{
+		LockRetries:    DefaultLevelDBLockRetries,
+		LockBackoff:    DefaultLevelDBLockBackoff,
+		SnapshotMaxAge: DefaultLevelDBSnapshotMaxAge,
+	}
+}
+
+// LevelDBOptionsFromEnv 在默认选项的基础上读取环境变量，无效的值忽略
+func LevelDBOptionsFromEnv() LevelDBOptions {
+	options := DefaultLevelDBOptions()
+	if envVal, ok := os.LookupEnv(LevelDBLockRetriesEnv); ok {
+		if val, err := strconv.Atoi(strings.TrimSpace(envVal)); err == nil && val >= 0 {
+			options.LockRetries = val
+		}
+	}
+	if envVal, ok := os.LookupEnv(LevelDBLockBackoffEnv); ok {
+		if val, err := time.ParseDuration(strings.TrimSpace(envVal)); err == nil && val > 0 {
+			options.LockBackoff = val
+		}
+	}
+	if envVal, ok := os.LookupEnv(LevelDBReadOnlyEnv); ok {
+		if val, err := strconv.ParseBool(strings.TrimSpace(envVal)); err == nil {
+			options.ReadOnly = val
+		}
+	}
+	if envVal, ok := os.LookupEnv(LevelDBSnapshotMaxAgeEnv); ok {
+		if val, err := time.ParseDuration(strings.TrimSpace(envVal)); err == nil && val >= 0 {
+			options.SnapshotMaxAge = val
+		}
+	}
+	return options
+}
+
+// DatabaseLockedError 重试后数据库仍被其他进程锁定，PID 为记录的持有锁的进程号，未知时为 0
+type DatabaseLockedError struct {
+	Path string
+	PID  int
+	Err  error
+}
+
+func (e *DatabaseLockedError) Error() string {
+	holder := "another process"
+	if e.PID > 0 {
+		holder = fmt.Sprintf("another process (pid %d)", e.PID)
+	}
+	return fmt.Sprintf("database %s is locked by %s: stop the other codebase-indexer instance or use a different index directory: %v",
+		e.Path, holder, e.Err)
+}
+
+func (e *DatabaseLockedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DatabaseLockedError) Is(target error) bool {
+	return target == ErrDatabaseLocked
+}
+
+// isLockError 是否因为锁文件被其他进程持有而打开失败
+func isLockError(err error) bool {
+	if errors.Is(err, syscall.EWOULDBLOCK) || errors.Is(err, syscall.EAGAIN) {
+		return true
+	}
+	return runtime.GOOS == "windows" && errors.Is(err, errSharingViolation)
+}
+
+// retryOnLock 执行 open，被其他进程锁定时按退避时间重试，重试用尽后返回 *DatabaseLockedError，ownerDir 为记录持有者进程号的目录
+func (s *LevelDBStorage) retryOnLock(path, ownerDir string, open func() error) error {
+	backoff := s.options.LockBackoff
+	for attempt := 0; ; attempt++ {
+		err := open()
+		if err == nil || !isLockError(err) {
+			return err
+		}
+		if attempt >= s.options.LockRetries {
+			return &DatabaseLockedError{Path: path, PID: readLockOwner(ownerDir), Err: err}
+		}
+		s.logger.Warn("%s is locked by another process, retry %d/%d after %v",
+			path, attempt+1, s.options.LockRetries, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxLevelDBLockBackoff)
+	}
+}
+
+// openWithLockRetry 以读写方式打开项目数据库，被其他进程锁定时重试
+func (s *LevelDBStorage) openWithLockRetry(dbPath string) (*leveldb.DB, error) {
+	var db *leveldb.DB
+	err := s.retryOnLock(dbPath, filepath.Dir(dbPath), func() error {
+		var err error
+		db, err = openLevelDB(dbPath, false)
+		return err
+	})
+	return db, err
+}
+
+// acquireInstanceLock 读写实例独占索引根目录下的实例锁，同一索引目录只能有一个读写实例
+func (s *LevelDBStorage) acquireInstanceLock() (storage.Storage, error) {
+	lockDir := filepath.Join(s.baseDir, instanceLockDir)
+	var lock storage.Storage
+	err := s.retryOnLock(s.baseDir, lockDir, func() error {
+		var err error
+		lock, err = storage.OpenFile(lockDir, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = writeLockOwner(lockDir); err != nil {
+		s.logger.Debug("failed to record instance owner. baseDir %s err:%v", s.baseDir, err)
+	}
+	return lock, nil
+}
+
+// primaryRunning 是否有读写实例持有索引根目录的实例锁
+func (s *LevelDBStorage) primaryRunning() bool {
+	lock, err := storage.OpenFile(filepath.Join(s.baseDir, instanceLockDir), true)
+	if err != nil {
+		return isLockError(err)
+	}
+	_ = lock.Close()
+	return false
+}
+
+// writeLockOwner 记录当前进程为 dir 下数据库的持有者
+func writeLockOwner(dir string) error {
+	return os.WriteFile(filepath.Join(dir, lockOwnerFile), []byte(strconv.Itoa(os.Getpid())), 0644)
+}
+
+// readLockOwner 读取记录的 dir 下数据库持有者进程号，没有记录时返回 0
+func readLockOwner(dir string) int {
+	data, err := os.ReadFile(filepath.Join(dir, lockOwnerFile))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0
+	}
+	return pid
+}
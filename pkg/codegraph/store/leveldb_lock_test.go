@@ -0,0 +1,250 @@
+package store
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// openLockedStorage 以读写方式打开存储并写入一个符号，返回的存储持有项目数据库的锁
+func openLockedStorage(t *testing.T, baseDir, projectID string) *LevelDBStorage {
+	writer, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{}, LevelDBOptions{})
+	require.NoError(t, err)
+	require.NoError(t, writer.Put(context.Background(), projectID, &Entry{Key: SymbolNameKey{Language: lang.Go, Name: "Foo"},
+		Value: &codegraphpb.SymbolOccurrence{Name: "Foo"}}))
+	return writer
+}
+
+func TestLevelDBStorageLock(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+	key := SymbolNameKey{Language: lang.Go, Name: "Foo"}
+
+	t.Run("重试用尽后返回锁错误且不删除数据库", func(t *testing.T) {
+		baseDir := t.TempDir()
+		writer := openLockedStorage(t, baseDir, projectID)
+		defer writer.Close()
+
+		start := time.Now()
+		_, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{},
+			LevelDBOptions{LockRetries: 2, LockBackoff: 10 * time.Millisecond})
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+		require.ErrorIs(t, err, ErrDatabaseLocked)
+		var locked *DatabaseLockedError
+		require.True(t, errors.As(err, &locked))
+		assert.Equal(t, os.Getpid(), locked.PID)
+		assert.Contains(t, err.Error(), "stop the other codebase-indexer instance")
+
+		// 数据库没有被当作损坏删除重建
+		_, statErr := os.Stat(filepath.Join(writer.generateDbPath(projectID), "CURRENT"))
+		assert.NoError(t, statErr)
+		_, err = writer.Get(ctx, projectID, key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("锁释放后重试成功", func(t *testing.T) {
+		baseDir := t.TempDir()
+		writer := openLockedStorage(t, baseDir, projectID)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			writer.Close()
+		}()
+		second, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{},
+			LevelDBOptions{LockRetries: 5, LockBackoff: 20 * time.Millisecond})
+		require.NoError(t, err)
+		defer second.Close()
+
+		value, err := second.Get(ctx, projectID, key)
+		require.NoError(t, err)
+		assert.NotEmpty(t, value)
+	})
+
+	t.Run("只读实例共享索引并拒绝写入", func(t *testing.T) {
+		baseDir := t.TempDir()
+		require.NoError(t, openLockedStorage(t, baseDir, projectID).Close())
+
+		readOnly := LevelDBOptions{ReadOnly: true}
+		first, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{}, readOnly)
+		require.NoError(t, err)
+		defer first.Close()
+		second, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{}, readOnly)
+		require.NoError(t, err)
+		defer second.Close()
+
+		for _, storage := range []*LevelDBStorage{first, second} {
+			_, err = storage.Get(ctx, projectID, key)
+			assert.NoError(t, err)
+		}
+		err = first.Put(ctx, projectID, &Entry{Key: SymbolNameKey{Language: lang.Go, Name: "Bar"},
+			Value: &codegraphpb.SymbolOccurrence{Name: "Bar"}})
+		assert.ErrorIs(t, err, leveldb.ErrReadOnly)
+		assert.ErrorIs(t, first.RenameProject(projectID, "renamed"), ErrReadOnlyStorage)
+		assert.ErrorIs(t, first.DeleteAll(ctx, projectID), ErrReadOnlyStorage)
+		assert.True(t, IsReadOnlyStorage(first))
+
+		// 只读实例打开期间，读写实例无法获得锁
+		writer, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{}, LevelDBOptions{})
+		require.NoError(t, err)
+		defer writer.Close()
+		_, err = writer.Get(ctx, projectID, key)
+		assert.ErrorIs(t, err, ErrDatabaseLocked)
+		assert.ErrorIs(t, writer.DeleteAll(ctx, projectID), ErrDatabaseLocked)
+		assert.False(t, IsReadOnlyStorage(writer))
+	})
+
+	t.Run("读写实例运行时只读实例读取索引副本", func(t *testing.T) {
+		baseDir := t.TempDir()
+		writer := openLockedStorage(t, baseDir, projectID)
+		defer writer.Close()
+		require.NoError(t, writer.Compact(ctx, projectID))
+		require.NoError(t, writer.Put(ctx, projectID, &Entry{Key: SymbolNameKey{Language: lang.Go, Name: "Journal"},
+			Value: &codegraphpb.SymbolOccurrence{Name: "Journal"}}))
+
+		// 复制副本期间读写实例持续写入
+		stop := make(chan struct{})
+		writeDone := make(chan error)
+		go func() {
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					writeDone <- nil
+					return
+				default:
+				}
+				name := fmt.Sprintf("Concurrent%d", i)
+				if err := writer.Put(ctx, projectID, &Entry{Key: SymbolNameKey{Language: lang.Go, Name: name},
+					Value: &codegraphpb.SymbolOccurrence{Name: name}}); err != nil {
+					writeDone <- err
+					return
+				}
+			}
+		}()
+
+		reader, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{},
+			LevelDBOptions{ReadOnly: true, SnapshotMaxAge: 50 * time.Millisecond})
+		require.NoError(t, err)
+		for _, name := range []string{"Foo", "Journal"} {
+			_, err = reader.Get(ctx, projectID, SymbolNameKey{Language: lang.Go, Name: name})
+			assert.NoError(t, err, name)
+		}
+		close(stop)
+		require.NoError(t, <-writeDone)
+
+		err = reader.Put(ctx, projectID, &Entry{Key: SymbolNameKey{Language: lang.Go, Name: "Bar"},
+			Value: &codegraphpb.SymbolOccurrence{Name: "Bar"}})
+		assert.ErrorIs(t, err, leveldb.ErrReadOnly)
+
+		// 读写实例不受只读实例影响，之后的写入在副本过期重新复制后可见
+		bar := SymbolNameKey{Language: lang.Go, Name: "Bar"}
+		require.NoError(t, writer.Put(ctx, projectID, &Entry{Key: bar, Value: &codegraphpb.SymbolOccurrence{Name: "Bar"}}))
+		_, err = reader.Get(ctx, projectID, bar)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		record, ok := reader.clients.Load(projectID)
+		require.True(t, ok)
+		firstSnapshot := record.(*dbAccessRecord).snapshotDir
+		require.NotEmpty(t, firstSnapshot)
+
+		time.Sleep(60 * time.Millisecond)
+		_, err = reader.Get(ctx, projectID, bar)
+		assert.NoError(t, err)
+		record, _ = reader.clients.Load(projectID)
+		lastSnapshot := record.(*dbAccessRecord).snapshotDir
+		assert.NotEqual(t, firstSnapshot, lastSnapshot)
+
+		// 关闭后删除所有副本
+		require.NoError(t, reader.Close())
+		for _, dir := range []string{firstSnapshot, lastSnapshot} {
+			_, statErr := os.Stat(dir)
+			assert.True(t, os.IsNotExist(statErr), dir)
+		}
+		_, err = writer.Get(ctx, projectID, bar)
+		assert.NoError(t, err)
+	})
+
+	t.Run("只读实例打开期间读写实例无法打开该项目", func(t *testing.T) {
+		baseDir := t.TempDir()
+		require.NoError(t, openLockedStorage(t, baseDir, projectID).Close())
+
+		readOnly, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{}, LevelDBOptions{ReadOnly: true})
+		require.NoError(t, err)
+		defer readOnly.Close()
+		_, err = readOnly.Get(ctx, projectID, key)
+		require.NoError(t, err)
+
+		second, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{}, LevelDBOptions{})
+		require.NoError(t, err)
+		defer second.Close()
+
+		for _, iter := range []Iterator{
+			second.Iter(ctx, projectID),
+			second.IterPrefix(ctx, projectID, SymKeySystemPrefix),
+		} {
+			require.NotNil(t, iter)
+			assert.False(t, iter.Next())
+			assert.Empty(t, iter.Key())
+			assert.ErrorIs(t, iter.Error(), ErrDatabaseLocked)
+			assert.NoError(t, iter.Close())
+		}
+	})
+
+	t.Run("只读实例不创建不存在的索引", func(t *testing.T) {
+		baseDir := t.TempDir()
+		storage, err := NewLevelDBStorageWithOptions(baseDir, &MockLogger{}, LevelDBOptions{ReadOnly: true})
+		require.NoError(t, err)
+		defer storage.Close()
+
+		_, err = storage.Get(ctx, projectID, key)
+		assert.Error(t, err)
+		_, statErr := os.Stat(filepath.Join(baseDir, projectID))
+		assert.True(t, os.IsNotExist(statErr))
+	})
+}
+
+func TestLevelDBOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		envVars map[string]string
+		want    LevelDBOptions
+	}{
+		{name: "默认值", envVars: map[string]string{}, want: DefaultLevelDBOptions()},
+		{
+			name: "从环境变量读取",
+			envVars: map[string]string{
+				LevelDBLockRetriesEnv:    "0",
+				LevelDBLockBackoffEnv:    "1s",
+				LevelDBReadOnlyEnv:       "true",
+				LevelDBSnapshotMaxAgeEnv: "1m",
+			},
+			want: LevelDBOptions{LockRetries: 0, LockBackoff: time.Second, ReadOnly: true, SnapshotMaxAge: time.Minute},
+		},
+		{
+			name: "无效值使用默认值",
+			envVars: map[string]string{
+				LevelDBLockRetriesEnv:    "-1",
+				LevelDBLockBackoffEnv:    "soon",
+				LevelDBReadOnlyEnv:       "maybe",
+				LevelDBSnapshotMaxAgeEnv: "-1m",
+			},
+			want: DefaultLevelDBOptions(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.envVars {
+				t.Setenv(key, value)
+			}
+			assert.Equal(t, tt.want, LevelDBOptionsFromEnv())
+		})
+	}
+}
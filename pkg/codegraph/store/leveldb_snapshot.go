@@ -0,0 +1,110 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	// snapshotAttempts 复制索引期间读写实例压缩删除了文件时的重新复制次数
+	snapshotAttempts = 3
+	// snapshotDirPattern 只读实例索引副本的临时目录名
+	snapshotDirPattern = "codebase-indexer-snapshot-*"
+	// leveldbCurrentFile 记录当前 MANIFEST 文件名的文件
+	leveldbCurrentFile = "CURRENT"
+)
+
+// openSnapshot 读写实例运行时复制项目数据库到临时目录并以只读方式打开，返回数据库和副本目录。
+// 读写实例持续写入，副本是复制时的近似时间点，未写完的日志记录在打开时丢弃
+func openSnapshot(dbPath string) (*leveldb.DB, string, error) {
+	if _, err := os.Stat(filepath.Join(dbPath, leveldbCurrentFile)); err != nil {
+		return nil, "", fmt.Errorf("failed to open database %s: %w", dbPath, err)
+	}
+	var lastErr error
+	for attempt := 0; attempt < snapshotAttempts; attempt++ {
+		snapshotDir, err := os.MkdirTemp("", snapshotDirPattern)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create snapshot dir: %w", err)
+		}
+		if err = copyLevelDBFiles(dbPath, snapshotDir); err == nil {
+			var db *leveldb.DB
+			if db, err = openLevelDB(snapshotDir, true); err == nil {
+				return db, snapshotDir, nil
+			}
+		}
+		_ = os.RemoveAll(snapshotDir)
+		lastErr = err
+	}
+	return nil, "", fmt.Errorf("failed to snapshot database %s: %w", dbPath, lastErr)
+}
+
+// copyLevelDBFiles 复制 MANIFEST、表文件和日志文件，最后写入 CURRENT。
+// 先复制 MANIFEST 保证其引用的表文件都在随后的复制范围内，复制期间 CURRENT 变化时返回错误以便重新复制
+func copyLevelDBFiles(dbPath, snapshotDir string) error {
+	current, err := os.ReadFile(filepath.Join(dbPath, leveldbCurrentFile))
+	if err != nil {
+		return err
+	}
+	manifest := strings.TrimSpace(string(current))
+	if !strings.HasPrefix(manifest, "MANIFEST-") {
+		return fmt.Errorf("invalid CURRENT file in %s: %q", dbPath, manifest)
+	}
+	if err = copyFile(filepath.Join(dbPath, manifest), filepath.Join(snapshotDir, manifest)); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isLevelDBDataFile(name) {
+			continue
+		}
+		if err = copyFile(filepath.Join(dbPath, name), filepath.Join(snapshotDir, name)); err != nil {
+			return err
+		}
+	}
+
+	latest, err := os.ReadFile(filepath.Join(dbPath, leveldbCurrentFile))
+	if err != nil {
+		return err
+	}
+	if string(latest) != string(current) {
+		return fmt.Errorf("database %s manifest changed during snapshot", dbPath)
+	}
+	return os.WriteFile(filepath.Join(snapshotDir, leveldbCurrentFile), current, 0644)
+}
+
+// isLevelDBDataFile 是否为表文件或日志文件，LOCK、LOG 等文件不复制
+func isLevelDBDataFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".ldb", ".sst", ".log":
+		return true
+	}
+	return false
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
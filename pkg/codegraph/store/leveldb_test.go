@@ -3,6 +3,7 @@ package store
 import (
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"codebase-indexer/pkg/codegraph/types"
 	"context"
 	"fmt"
 	"io/fs"
@@ -360,6 +361,68 @@ func TestLevelDBStorage_Delete(t *testing.T) {
 	}
 }
 
+func TestLevelDBStorage_BatchDelete(t *testing.T) {
+	storage, cleanup := setupLeveldbTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	projectID := "test-project"
+
+	for i := 0; i < 5; i++ {
+		err := storage.Put(ctx, projectID, &Entry{Key: TestKey{key: fmt.Sprintf("key-%d", i)},
+			Value: &codegraphpb.TestMessage{Value: fmt.Sprintf("value-%d", i)}})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		keys     []Key
+		ctx      func() context.Context
+		wantErr  bool
+		wantLeft []string
+	}{
+		{
+			name:     "空列表",
+			wantLeft: []string{"key-0", "key-1", "key-2", "key-3", "key-4"},
+		},
+		{
+			name:     "上下文已取消",
+			keys:     []Key{TestKey{key: "key-0"}},
+			ctx:      func() context.Context { c, cancel := context.WithCancel(ctx); cancel(); return c },
+			wantErr:  true,
+			wantLeft: []string{"key-0", "key-1", "key-2", "key-3", "key-4"},
+		},
+		{
+			name:     "批量删除存在和不存在的键",
+			keys:     []Key{TestKey{key: "key-0"}, TestKey{key: "key-2"}, TestKey{key: "non-existent"}},
+			wantLeft: []string{"key-1", "key-3", "key-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCtx := ctx
+			if tt.ctx != nil {
+				testCtx = tt.ctx()
+			}
+			err := storage.BatchDelete(testCtx, projectID, tt.keys)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var left []string
+			iter := storage.Iter(ctx, projectID)
+			for iter.Next() {
+				left = append(left, iter.Key())
+			}
+			require.NoError(t, iter.Close())
+			assert.Equal(t, tt.wantLeft, left)
+		})
+	}
+}
+
 func TestLevelDBStorage_Size(t *testing.T) {
 	storage, cleanup := setupLeveldbTestStorage(t)
 	defer cleanup()
@@ -522,6 +585,71 @@ func TestLevelDBStorage_Iter(t *testing.T) {
 	}
 }
 
+func TestLevelDBStorage_IterCloseTwice(t *testing.T) {
+	storage, cleanup := setupLeveldbTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	projectID := "test-project"
+	require.NoError(t, storage.Put(ctx, projectID, &Entry{Key: TestKey{"test-value"}, Value: &codegraphpb.TestMessage{Value: "value"}}))
+
+	t.Run("顺序关闭两次", func(t *testing.T) {
+		iter := storage.Iter(ctx, projectID)
+		require.NotNil(t, iter)
+		assert.True(t, iter.Next())
+		assert.NotPanics(t, func() {
+			assert.NoError(t, iter.Close())
+			assert.NoError(t, iter.Close())
+		})
+		assert.False(t, iter.Next())
+	})
+
+	t.Run("并发关闭", func(t *testing.T) {
+		iter := storage.Iter(ctx, projectID)
+		require.NotNil(t, iter)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NotPanics(t, func() { _ = iter.Close() })
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, iter.Close())
+	})
+}
+
+func TestLevelDBStorage_Compact(t *testing.T) {
+	storage, cleanup := setupLeveldbTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	projectID := "test-project"
+	var keys []Key
+	for i := 0; i < 100; i++ {
+		key := ElementPathKey{Language: lang.Go, Path: fmt.Sprintf("/app/file-%d.go", i)}
+		keys = append(keys, key)
+		require.NoError(t, storage.Put(ctx, projectID, &Entry{Key: key, Value: &codegraphpb.TestMessage{Value: "value"}}))
+	}
+	assert.Equal(t, int64(0), storage.DeletedSinceCompaction(projectID))
+
+	require.NoError(t, storage.BatchDelete(ctx, projectID, keys[:50]))
+	require.NoError(t, storage.Delete(ctx, projectID, keys[50]))
+	assert.Equal(t, int64(51), storage.DeletedSinceCompaction(projectID))
+
+	require.NoError(t, storage.Compact(ctx, projectID))
+	assert.Equal(t, int64(0), storage.DeletedSinceCompaction(projectID))
+	assert.Equal(t, 49, storage.Size(ctx, projectID, types.EmptyString))
+	value, err := storage.Get(ctx, projectID, keys[99])
+	require.NoError(t, err)
+	assert.NotEmpty(t, value)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, storage.Compact(cancelCtx, projectID))
+}
+
 // leveldbTestValues 用于测试的 Entries 实现
 type leveldbTestValues struct {
 	values []proto.Message
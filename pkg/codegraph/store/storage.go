@@ -3,6 +3,7 @@ package store
 import (
 	"codebase-indexer/pkg/codegraph/lang"
 	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
 	"context"
 	"fmt"
 	"os"
@@ -18,14 +19,67 @@ type GraphStorage interface {
 	BatchSave(ctx context.Context, projectUuid string, values Entries) error
 	Put(ctx context.Context, projectUuid string, entry *Entry) error
 	Get(ctx context.Context, projectUuid string, key Key) ([]byte, error)
+	// BatchGet 一次读取多个 key，返回值与 keys 一一对应，不存在的 key 对应 nil
+	BatchGet(ctx context.Context, projectUuid string, keys []Key) ([][]byte, error)
 	Exists(ctx context.Context, projectUuid string, key Key) (bool, error)
 	Delete(ctx context.Context, projectUuid string, key Key) error
+	BatchDelete(ctx context.Context, projectUuid string, keys []Key) error
 	DeleteAll(ctx context.Context, projectUuid string) error
 	DeleteAllWithPrefix(ctx context.Context, projectUuid string, prefix string) error
 	Iter(ctx context.Context, projectUuid string) Iterator
+	// IterPrefix 只遍历以 prefix 开头的 key，如 CalleeMapKeySystemPrefix、SymKeySystemPrefix
+	IterPrefix(ctx context.Context, projectUuid string, prefix string) Iterator
 	Size(ctx context.Context, projectUuid string, keyPrefix string) int
+	// Compact 压缩项目索引，回收删除操作遗留的空间
+	Compact(ctx context.Context, projectUuid string) error
 	Close() error
 	ProjectIndexExists(projectUuid string) (bool, error)
+	ListProjects() ([]string, error)
+}
+
+// DiskUsageReporter 可选接口，存储实现支持统计项目索引占用的磁盘空间时实现
+type DiskUsageReporter interface {
+	// DiskUsage 返回项目索引占用的磁盘字节数，项目索引不存在时返回0
+	DiskUsage(projectUuid string) (int64, error)
+}
+
+// ProjectRenamer 可选接口，存储实现支持将项目索引整体迁移到新的 projectUuid 时实现
+type ProjectRenamer interface {
+	// RenameProject 将 fromUuid 的索引迁移到 toUuid，toUuid 已有索引时返回错误
+	RenameProject(fromUuid, toUuid string) error
+}
+
+// DeletionCounter 可选接口，存储实现支持统计上次压缩以来删除的 key 数时实现，用于判断是否需要压缩
+type DeletionCounter interface {
+	// DeletedSinceCompaction 返回项目自上次压缩（或进程启动）以来删除的 key 数
+	DeletedSinceCompaction(projectUuid string) int64
+}
+
+// HealthChecker 可选接口，存储实现支持就绪检查时实现
+type HealthChecker interface {
+	// Ping 检查存储是否可读，存储已关闭或读取失败时返回错误
+	Ping(ctx context.Context) error
+}
+
+// PingStorage 检查存储是否可用，未实现 HealthChecker 的存储通过列出项目检查根目录可读
+func PingStorage(ctx context.Context, storage GraphStorage) error {
+	if checker, ok := storage.(HealthChecker); ok {
+		return checker.Ping(ctx)
+	}
+	_, err := storage.ListProjects()
+	return err
+}
+
+// ReadOnlyChecker 可以以只读方式打开的存储
+type ReadOnlyChecker interface {
+	// ReadOnly 存储是否只读，只读时不能建立、更新或删除索引
+	ReadOnly() bool
+}
+
+// IsReadOnlyStorage 存储是否只读，未实现 ReadOnlyChecker 的存储视为可写
+func IsReadOnlyStorage(storage GraphStorage) bool {
+	checker, ok := storage.(ReadOnlyChecker)
+	return ok && checker.ReadOnly()
 }
 
 // Iterator 定义了遍历存储中元素的接口
@@ -46,13 +100,48 @@ type Iterator interface {
 	Close() error
 }
 
+// errIterator 打开数据库失败时返回的迭代器，Next 始终返回 false，Error 返回打开失败的原因，
+// 调用方无需判断迭代器是否为 nil
+type errIterator struct {
+	err error
+}
+
+func newErrIterator(err error) Iterator {
+	return &errIterator{err: err}
+}
+
+func (it *errIterator) Next() bool    { return false }
+func (it *errIterator) Key() string   { return "" }
+func (it *errIterator) Value() []byte { return nil }
+func (it *errIterator) Error() error  { return it.err }
+func (it *errIterator) Close() error  { return nil }
+
 const (
-	PathKeySystemPrefix      = "@path"
-	SymKeySystemPrefix       = "@sym"
-	CalleeMapKeySystemPrefix = "@callee"
-	dataDir                  = "data"
+	PathKeySystemPrefix       = "@path"
+	SymKeySystemPrefix        = "@sym"
+	CalleeMapKeySystemPrefix  = "@callee"
+	CallerFileKeySystemPrefix = "@callerfile"
+	MetaKeySystemPrefix       = "@meta"
+	dataDir                   = "data"
 )
 
+// 项目元数据类型
+const (
+	// MetaTypeSchemaVersion 索引数据的 schema 版本
+	MetaTypeSchemaVersion = "schema_version"
+	// MetaTypeProjectPath 项目源码路径，源码目录删除后仍可定位索引所属项目
+	MetaTypeProjectPath = "project_path"
+	// MetaTypeFileLimit 最近一次索引达到的 MaxFiles 上限，存在时说明项目索引不完整
+	MetaTypeFileLimit = "file_limit"
+	// MetaTypeCallGraphBuilt 调用图反向索引（callee map）及其文件索引构建完成的时间，删除 callee map 时一并删除。
+	// 旧版本构建的 callee map 没有文件索引，无法增量更新，使用新的元数据名使其在下次查询时重建
+	MetaTypeCallGraphBuilt = "callgraph_built_v2"
+)
+
+// CurrentSchemaVersion 当前二进制写入的索引 schema 版本。
+// 修改 proto 结构或 key 格式等导致已有索引不兼容时递增，已有项目会在下次索引时自动重建
+const CurrentSchemaVersion = "2"
+
 type Key interface {
 	Get() (string, error)
 }
@@ -69,7 +158,8 @@ func (p ElementPathKey) Get() (string, error) {
 	if p.Path == types.EmptyString {
 		return types.EmptyString, fmt.Errorf("ElementPathKey field Path must not be empty")
 	}
-	return fmt.Sprintf("%s:%s:%s", PathKeySystemPrefix, p.Language, p.Path), nil
+	// 路径统一为 / 分隔符，不同分隔符写法的同一文件对应同一个 key
+	return fmt.Sprintf("%s:%s:%s", PathKeySystemPrefix, p.Language, utils.ToStoragePath(p.Path)), nil
 }
 
 type SymbolNameKey struct {
@@ -95,6 +185,41 @@ func (c CalleeMapKey) Get() (string, error) {
 	return fmt.Sprintf("%s:%s", CalleeMapKeySystemPrefix, c.SymbolName), nil
 }
 
+// CallerFileKey callee map 的文件索引：调用者文件中调用了 CalleeName，值为 emptypb.Empty。
+// 文件变更时据此只读取和重写该文件涉及的 callee map，不需要遍历整个 callee map
+type CallerFileKey struct {
+	FilePath   string
+	CalleeName string
+}
+
+func (c CallerFileKey) Get() (string, error) {
+	if c.FilePath == types.EmptyString {
+		return types.EmptyString, fmt.Errorf("CallerFileKey field FilePath must not be empty")
+	}
+	return CallerFilePrefix(c.FilePath) + c.CalleeName, nil
+}
+
+// CallerFilePrefix 文件所有 CallerFileKey 的公共前缀，路径后以 \x00 分隔，避免匹配到以该路径为前缀的其他文件
+func CallerFilePrefix(filePath string) string {
+	return fmt.Sprintf("%s:%s\x00", CallerFileKeySystemPrefix, utils.ToStoragePath(filePath))
+}
+
+// ProjectMetaKey 项目级元数据，值为 wrapperspb.StringValue
+type ProjectMetaKey struct {
+	MetaType string
+}
+
+func (m ProjectMetaKey) Get() (string, error) {
+	if m.MetaType == types.EmptyString {
+		return types.EmptyString, fmt.Errorf("ProjectMetaKey field MetaType must not be empty")
+	}
+	return fmt.Sprintf("%s:%s", MetaKeySystemPrefix, m.MetaType), nil
+}
+
+func IsProjectMetaKey(key string) bool {
+	return strings.HasPrefix(key, MetaKeySystemPrefix)
+}
+
 func IsSymbolNameKey(key string) bool {
 	return strings.HasPrefix(key, SymKeySystemPrefix)
 }
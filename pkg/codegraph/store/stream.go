@@ -0,0 +1,68 @@
+package store
+
+import (
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrStopIteration 回调返回该错误时提前结束遍历，ForEachElementTable 返回 nil
+var ErrStopIteration = errors.New("stop iteration")
+
+// CorruptedValueError 元素表反序列化失败
+type CorruptedValueError struct {
+	Key ElementPathKey
+	Err error
+}
+
+func (e *CorruptedValueError) Error() string {
+	return fmt.Sprintf("unmarshal element_table %s:%s err: %v", e.Key.Language, e.Key.Path, e.Err)
+}
+
+func (e *CorruptedValueError) Unwrap() error {
+	return e.Err
+}
+
+// ElementTableFunc 元素表回调。table 在两次回调之间复用，回调返回后不能再持有，需要保留时使用 proto.Clone；
+// 返回 ErrStopIteration 提前结束遍历，返回其他错误中止遍历并原样返回
+type ElementTableFunc func(key ElementPathKey, table *codegraphpb.FileElementTable) error
+
+// ForEachElementTable 流式遍历项目的全部元素表，每次只解码一个并复用同一个对象，内存占用与索引规模无关，
+// 遍历结束或中止时关闭迭代器。反序列化失败的元素表跳过，以 *CorruptedValueError 合并到返回的错误中
+func ForEachElementTable(ctx context.Context, storage GraphStorage, projectUuid string, fn ElementTableFunc) error {
+	iter := storage.Iter(ctx, projectUuid)
+	defer iter.Close()
+
+	var corrupted []error
+	table := new(codegraphpb.FileElementTable)
+	for iter.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !IsElementPathKey(iter.Key()) {
+			continue
+		}
+		pathKey, err := ToElementPathKey(iter.Key())
+		if err != nil {
+			continue
+		}
+		proto.Reset(table)
+		if err = UnmarshalValue(iter.Value(), table); err != nil {
+			corrupted = append(corrupted, &CorruptedValueError{Key: pathKey, Err: err})
+			continue
+		}
+		if err = fn(pathKey, table); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				break
+			}
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return errors.Join(corrupted...)
+}
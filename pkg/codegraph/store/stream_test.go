@@ -0,0 +1,173 @@
+package store
+
+import (
+	"codebase-indexer/pkg/codegraph/lang"
+	"codebase-indexer/pkg/codegraph/proto/codegraphpb"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// seedElementTables 写入 n 个元素表，每个约 elementsPerTable 个元素
+func seedElementTables(t *testing.T, storage GraphStorage, projectID string, n, elementsPerTable int) {
+	ctx := context.Background()
+	values := &TestValues{}
+	for i := 0; i < n; i++ {
+		path := fmt.Sprintf("/project/pkg%d/file%d.go", i%50, i)
+		table := &codegraphpb.FileElementTable{Path: path, Language: string(lang.Go)}
+		for j := 0; j < elementsPerTable; j++ {
+			table.Elements = append(table.Elements, &codegraphpb.Element{
+				Name: fmt.Sprintf("Symbol_%d_%d", i, j), IsDefinition: true,
+				ElementType: codegraphpb.ElementType_FUNCTION, Range: []int32{int32(j), 0, int32(j) + 1, 0}})
+		}
+		values.keys = append(values.keys, ElementPathKey{Language: lang.Go, Path: path})
+		values.values = append(values.values, table)
+	}
+	require.NoError(t, storage.BatchSave(ctx, projectID, values))
+}
+
+func TestForEachElementTable(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+
+	t.Run("遍历全部元素表并跳过其他 key", func(t *testing.T) {
+		storage, cleanup := setupLeveldbTestStorage(t)
+		defer cleanup()
+		seedElementTables(t, storage, projectID, 100, 2)
+		require.NoError(t, storage.Put(ctx, projectID, &Entry{Key: SymbolNameKey{Language: lang.Go, Name: "Symbol_0_0"},
+			Value: &codegraphpb.SymbolOccurrence{Name: "Symbol_0_0"}}))
+
+		paths := make(map[string]struct{})
+		err := ForEachElementTable(ctx, storage, projectID, func(key ElementPathKey, table *codegraphpb.FileElementTable) error {
+			assert.Equal(t, key.Path, table.Path)
+			assert.Len(t, table.Elements, 2)
+			paths[table.Path] = struct{}{}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, paths, 100)
+	})
+
+	t.Run("提前结束遍历", func(t *testing.T) {
+		storage, cleanup := setupLeveldbTestStorage(t)
+		defer cleanup()
+		seedElementTables(t, storage, projectID, 10, 1)
+
+		count := 0
+		err := ForEachElementTable(ctx, storage, projectID, func(ElementPathKey, *codegraphpb.FileElementTable) error {
+			count++
+			if count == 3 {
+				return ErrStopIteration
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("回调错误中止遍历", func(t *testing.T) {
+		storage, cleanup := setupLeveldbTestStorage(t)
+		defer cleanup()
+		seedElementTables(t, storage, projectID, 10, 1)
+
+		count := 0
+		err := ForEachElementTable(ctx, storage, projectID, func(ElementPathKey, *codegraphpb.FileElementTable) error {
+			count++
+			return ErrTestError
+		})
+		assert.ErrorIs(t, err, ErrTestError)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("损坏的元素表跳过并返回错误", func(t *testing.T) {
+		storage, cleanup := setupLeveldbTestStorage(t)
+		defer cleanup()
+		seedElementTables(t, storage, projectID, 5, 1)
+		corruptedKey := ElementPathKey{Language: lang.Go, Path: "/project/corrupted.go"}
+		require.NoError(t, storage.Put(ctx, projectID, &Entry{Key: corruptedKey,
+			Value: wrapperspb.Bytes([]byte{0xff, 0xfe, 0xfd})}))
+
+		count := 0
+		err := ForEachElementTable(ctx, storage, projectID, func(ElementPathKey, *codegraphpb.FileElementTable) error {
+			count++
+			return nil
+		})
+		var corrupted *CorruptedValueError
+		require.True(t, errors.As(err, &corrupted))
+		assert.Equal(t, corruptedKey, corrupted.Key)
+		assert.Equal(t, 5, count)
+	})
+
+	t.Run("上下文取消", func(t *testing.T) {
+		storage, cleanup := setupLeveldbTestStorage(t)
+		defer cleanup()
+		seedElementTables(t, storage, projectID, 5, 1)
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := ForEachElementTable(cancelCtx, storage, projectID, func(ElementPathKey, *codegraphpb.FileElementTable) error {
+			t.Fatal("callback should not be called after cancel")
+			return nil
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("内存占用与索引规模无关", func(t *testing.T) {
+		storage, cleanup := setupLeveldbTestStorage(t)
+		defer cleanup()
+		const tables = 5000
+		seedElementTables(t, storage, projectID, tables, 20)
+
+		// 每处理 1000 个元素表 GC 一次，记录存活堆的峰值；累积到切片时会随元素表数线性增长
+		var before runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		var peak uint64
+		var first *codegraphpb.FileElementTable
+		var totalBytes int
+		count := 0
+		err := ForEachElementTable(ctx, storage, projectID, func(_ ElementPathKey, table *codegraphpb.FileElementTable) error {
+			if first == nil {
+				first = table
+			}
+			assert.Same(t, first, table)
+			totalBytes += proto.Size(table)
+			count++
+			if count%1000 == 0 {
+				var m runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&m)
+				peak = max(peak, m.HeapAlloc)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tables, count)
+		growth := int64(peak) - int64(before.HeapAlloc)
+		assert.Less(t, growth, int64(totalBytes/4),
+			"live heap grew %d bytes while streaming %d bytes of element tables", growth, totalBytes)
+
+		// 每个元素表的分配次数与已处理的元素表数无关
+		allocsPerTable := func(n int) float64 {
+			return testing.AllocsPerRun(1, func() {
+				processed := 0
+				_ = ForEachElementTable(ctx, storage, projectID, func(ElementPathKey, *codegraphpb.FileElementTable) error {
+					processed++
+					if processed == n {
+						return ErrStopIteration
+					}
+					return nil
+				})
+			}) / float64(n)
+		}
+		small, large := allocsPerTable(500), allocsPerTable(tables)
+		assert.InDelta(t, small, large, small*0.5)
+	})
+}
@@ -0,0 +1,135 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 调用图的导出格式
+const (
+	CallGraphFormatJSON    = "json"
+	CallGraphFormatDOT     = "dot"
+	CallGraphFormatMermaid = "mermaid"
+)
+
+// callGraphNode 导出时的图节点，同一文件中的同名符号合并为一个节点。
+// 调用者节点的位置是调用处，不能用于区分符号，环上的调用者按名称合并后才能指回祖先节点
+type callGraphNode struct {
+	id       string
+	name     string
+	filePath string
+	variable bool
+}
+
+// callGraphEdge 父节点到子节点的边，cyclic 表示子节点已出现在祖先路径上
+type callGraphEdge struct {
+	from, to string
+	cyclic   bool
+}
+
+// flattenCallGraph 将调用图树展开为去重后的节点和边，按深度优先的出现顺序排列。
+// 同一符号在树中多次出现时只生成一个节点，环路表现为指回已有节点的边，节点指针成环时也不会无限递归
+func flattenCallGraph(roots []*RelationNode) ([]*callGraphNode, []callGraphEdge) {
+	var nodes []*callGraphNode
+	var edges []callGraphEdge
+	ids := make(map[string]string)
+	seenEdges := make(map[callGraphEdge]struct{})
+	expanded := make(map[*RelationNode]struct{})
+
+	nodeId := func(n *RelationNode) string {
+		key := n.FilePath + "\x00" + n.SymbolName
+		if id, ok := ids[key]; ok {
+			return id
+		}
+		id := fmt.Sprintf("n%d", len(nodes))
+		ids[key] = id
+		nodes = append(nodes, &callGraphNode{id: id, name: n.SymbolName, filePath: n.FilePath, variable: n.Variable})
+		return id
+	}
+
+	var walk func(n *RelationNode)
+	walk = func(n *RelationNode) {
+		if _, ok := expanded[n]; ok {
+			return
+		}
+		expanded[n] = struct{}{}
+		id := nodeId(n)
+		for _, child := range n.Children {
+			if child == nil {
+				continue
+			}
+			edge := callGraphEdge{from: id, to: nodeId(child), cyclic: child.Cyclic}
+			if _, ok := seenEdges[edge]; !ok {
+				seenEdges[edge] = struct{}{}
+				edges = append(edges, edge)
+			}
+			walk(child)
+		}
+	}
+	for _, root := range roots {
+		if root != nil {
+			walk(root)
+		}
+	}
+	return nodes, edges
+}
+
+// FormatCallGraphDOT 将调用图导出为 Graphviz DOT 格式，节点标签为符号名和文件路径，边由父节点指向子节点，
+// 指向环上节点的边和变量节点用不同样式区分
+func FormatCallGraphDOT(nodes []*RelationNode) string {
+	graphNodes, edges := flattenCallGraph(nodes)
+	var sb strings.Builder
+	sb.WriteString("digraph callgraph {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, n := range graphNodes {
+		fmt.Fprintf(&sb, "  %s [label=\"%s\\n%s\"", n.id, escapeDOT(n.name), escapeDOT(n.filePath))
+		if n.variable {
+			sb.WriteString(", shape=ellipse")
+		}
+		sb.WriteString("];\n")
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&sb, "  %s -> %s", e.from, e.to)
+		if e.cyclic {
+			sb.WriteString(" [style=dashed]")
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// FormatCallGraphMermaid 将调用图导出为 Mermaid flowchart，节点标签为符号名和文件路径，边由父节点指向子节点，
+// 指向环上节点的边用虚线表示，变量节点用圆角矩形表示
+func FormatCallGraphMermaid(nodes []*RelationNode) string {
+	graphNodes, edges := flattenCallGraph(nodes)
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, n := range graphNodes {
+		label := escapeMermaid(n.name) + "<br/>" + escapeMermaid(n.filePath)
+		if n.variable {
+			fmt.Fprintf(&sb, "  %s([\"%s\"])\n", n.id, label)
+		} else {
+			fmt.Fprintf(&sb, "  %s[\"%s\"]\n", n.id, label)
+		}
+	}
+	for _, e := range edges {
+		arrow := "-->"
+		if e.cyclic {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&sb, "  %s %s %s\n", e.from, arrow, e.to)
+	}
+	return sb.String()
+}
+
+// escapeDOT 转义 DOT 双引号字符串中的反斜杠、双引号和换行
+func escapeDOT(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(s)
+}
+
+// escapeMermaid 将 Mermaid 标签中有特殊含义的字符替换为实体编码
+func escapeMermaid(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\r", "", "\n", " ").Replace(s)
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCallGraph 构建 Target <- Handle <- Route、Target <- Run，Route 又被 Target 调用形成环，
+// Handle 还引用了变量 limit
+func newTestCallGraph() []*RelationNode {
+	cyclic := &RelationNode{FilePath: "/app/target.go", SymbolName: "Target", Cyclic: true,
+		Position: &Position{StartLine: 9}}
+	route := &RelationNode{FilePath: "/app/api/route.go", SymbolName: "Route", Children: []*RelationNode{cyclic}}
+	limit := &RelationNode{FilePath: "/app/api/config.go", SymbolName: "limit", Variable: true}
+	handle := &RelationNode{FilePath: "/app/api/api.go", SymbolName: "Handle", Children: []*RelationNode{limit, route}}
+	run := &RelationNode{FilePath: `C:\app\cli "v2".go`, SymbolName: "Run<T>"}
+	return []*RelationNode{{FilePath: "/app/target.go", SymbolName: "Target", Position: &Position{StartLine: 3},
+		Children: []*RelationNode{handle, run}}}
+}
+
+func TestFormatCallGraphDOT(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []*RelationNode
+		want  string
+	}{
+		{name: "空图", nodes: nil, want: "digraph callgraph {\n  rankdir=LR;\n  node [shape=box];\n}\n"},
+		{
+			name:  "带环和变量的调用图",
+			nodes: newTestCallGraph(),
+			want: "digraph callgraph {\n" +
+				"  rankdir=LR;\n" +
+				"  node [shape=box];\n" +
+				"  n0 [label=\"Target\\n/app/target.go\"];\n" +
+				"  n1 [label=\"Handle\\n/app/api/api.go\"];\n" +
+				"  n2 [label=\"limit\\n/app/api/config.go\", shape=ellipse];\n" +
+				"  n3 [label=\"Route\\n/app/api/route.go\"];\n" +
+				"  n4 [label=\"Run<T>\\nC:\\\\app\\\\cli \\\"v2\\\".go\"];\n" +
+				"  n0 -> n1;\n" +
+				"  n1 -> n2;\n" +
+				"  n1 -> n3;\n" +
+				"  n3 -> n0 [style=dashed];\n" +
+				"  n0 -> n4;\n" +
+				"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatCallGraphDOT(tt.nodes)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, strings.Count(got, "{"), strings.Count(got, "}"))
+		})
+	}
+}
+
+func TestFormatCallGraphMermaid(t *testing.T) {
+	got := FormatCallGraphMermaid(newTestCallGraph())
+	want := "flowchart LR\n" +
+		"  n0[\"Target<br/>/app/target.go\"]\n" +
+		"  n1[\"Handle<br/>/app/api/api.go\"]\n" +
+		"  n2([\"limit<br/>/app/api/config.go\"])\n" +
+		"  n3[\"Route<br/>/app/api/route.go\"]\n" +
+		"  n4[\"Run#lt;T#gt;<br/>C:\\app\\cli #quot;v2#quot;.go\"]\n" +
+		"  n0 --> n1\n" +
+		"  n1 --> n2\n" +
+		"  n1 --> n3\n" +
+		"  n3 -.-> n0\n" +
+		"  n0 --> n4\n"
+	assert.Equal(t, want, got)
+
+	t.Run("节点指针成环时不会无限递归", func(t *testing.T) {
+		a := &RelationNode{FilePath: "/a.go", SymbolName: "A"}
+		b := &RelationNode{FilePath: "/b.go", SymbolName: "B", Children: []*RelationNode{a}}
+		a.Children = []*RelationNode{b}
+		assert.Equal(t, "flowchart LR\n  n0[\"A<br/>/a.go\"]\n  n1[\"B<br/>/b.go\"]\n  n0 --> n1\n  n1 --> n0\n",
+			FormatCallGraphMermaid([]*RelationNode{a}))
+	})
+}
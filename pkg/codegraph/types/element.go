@@ -360,6 +360,18 @@ const (
 	NodeKindFunctionDeclaration NodeKind = "function_declaration"
 	NodeKindMethodDeclaration   NodeKind = "method_declaration"
 	NodeKindClassDefinition     NodeKind = "class_definition"
+
+	// c/cpp 宏定义
+	NodeKindPreprocDef         NodeKind = "preproc_def"          // #define FOO bar
+	NodeKindPreprocFunctionDef NodeKind = "preproc_function_def" // #define FOO(x) bar(x)
+
+	// c# 类型声明
+	NodeKindStructDeclaration              NodeKind = "struct_declaration"
+	NodeKindRecordDeclaration              NodeKind = "record_declaration"
+	NodeKindBaseList                       NodeKind = "base_list"
+	NodeKindCSharpModifier                 NodeKind = "modifier"
+	NodeKindNamespaceDeclaration           NodeKind = "namespace_declaration"
+	NodeKindFileScopedNamespaceDeclaration NodeKind = "file_scoped_namespace_declaration"
 )
 
 var NodeKindMappings = map[string]NodeKind{
@@ -453,8 +465,17 @@ var NodeKindMappings = map[string]NodeKind{
 	string(NodeKindQualifiedIdentifier):  NodeKindQualifiedIdentifier,
 	string(NodeKindTypeList):             NodeKindTypeList,
 	string(NodeKindBaseClassClause):      NodeKindBaseClassClause,
-	string(NodeKindFunctionDeclaration): NodeKindFunctionDeclaration,
-	string(NodeKindClassDefinition):     NodeKindClassDefinition,
+	string(NodeKindFunctionDeclaration):  NodeKindFunctionDeclaration,
+	string(NodeKindClassDefinition):      NodeKindClassDefinition,
+	string(NodeKindPreprocDef):           NodeKindPreprocDef,
+	string(NodeKindPreprocFunctionDef):   NodeKindPreprocFunctionDef,
+	// c# 类型声明
+	string(NodeKindStructDeclaration):              NodeKindStructDeclaration,
+	string(NodeKindRecordDeclaration):              NodeKindRecordDeclaration,
+	string(NodeKindBaseList):                       NodeKindBaseList,
+	string(NodeKindCSharpModifier):                 NodeKindCSharpModifier,
+	string(NodeKindNamespaceDeclaration):           NodeKindNamespaceDeclaration,
+	string(NodeKindFileScopedNamespaceDeclaration): NodeKindFileScopedNamespaceDeclaration,
 }
 
 // 用于接收函数的返回类型和字段的类型
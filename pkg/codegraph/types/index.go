@@ -1,12 +1,15 @@
 package types
 
+import "time"
+
 type NodeType string
 
 const ( //
-	NodeTypeDefinition     NodeType = "definition"     // 定义节点（根节点）
-	NodeTypeUnknown        NodeType = "unknown"        // 未知
-	NodeTypeReference      NodeType = "reference"      // 引用关系
-	NodeTypeImplementation NodeType = "implementation" // 实现关系（类 -> 接口）
+	NodeTypeDefinition      NodeType = "definition"       // 定义节点（根节点）
+	NodeTypeUnknown         NodeType = "unknown"          // 未知
+	NodeTypeReference       NodeType = "reference"        // 引用关系
+	NodeTypeImplementation  NodeType = "implementation"   // 实现关系（类 -> 接口）
+	NodeTypeConstructorCall NodeType = "constructor_call" // 构造调用（实例化类）
 )
 
 type FileWithModTimestamp struct {
@@ -22,6 +25,28 @@ type IndexTaskMetrics struct {
 	TotalSavedVariables int
 	TotalFailedFiles    int
 	FailedFilePaths     []string
+	// TotalForceReparsedFiles 强制重建时，时间戳未变化但仍重新解析的文件数
+	TotalForceReparsedFiles int
+	// TotalSkippedFiles 二进制或非 UTF-8 等原因跳过解析的文件数
+	TotalSkippedFiles int
+	// SkippedFiles 跳过解析的文件路径及原因
+	SkippedFiles map[string]string
+	// TotalSkippedEmptyFiles 内容为空而跳过解析的文件数，已计入 TotalSkippedFiles
+	TotalSkippedEmptyFiles int
+	// TotalSkippedUnreadableFiles 无读取权限或收集后被删除而跳过解析的文件数，已计入 TotalSkippedFiles
+	TotalSkippedUnreadableFiles int
+	// TotalParseErrorFiles 存在语法错误、解析不完整的文件数，这些文件中的符号可能缺失
+	TotalParseErrorFiles int
+	// ParseErrorFilePaths 存在语法错误的文件路径
+	ParseErrorFilePaths []string
+	// Truncated 索引不完整，存在未被索引的源文件
+	Truncated bool
+	// FileLimitHit 收集文件时达到 MaxFiles 上限，超出的文件未被索引
+	FileLimitHit bool
+	// MaxFilesLimit 达到上限时的 MaxFiles 值
+	MaxFilesLimit int
+	// FailedProjects 索引出错或崩溃的项目路径，其余项目照常索引
+	FailedProjects []string
 }
 
 // CodeDefinition 代码文件结构
@@ -37,32 +62,172 @@ type Definition struct {
 	Path    string
 	Range   []int32
 	Content []byte
+	Score   int    // 与查询文件的接近程度，越高越优先，结果按其降序排列
+	Doc     string // 文档注释，查询时指定 IncludeDoc 才填充
 }
 
 type QueryDefinitionOptions struct {
 	StartLine   int
 	EndLine     int
+	StartColumn int // 开始列（从1开始），可选，用于区分同一行的多个符号
+	EndColumn   int // 结束列（从1开始），可选
 	Workspace   string
 	FilePath    string
 	SymbolNames string
 	CodeSnippet []byte
+	ProjectUuid string // 项目uuid，可选，指定后直接查询该项目，跳过工作区项目发现
+	StartByte   int    // 开始字节偏移（从0开始），可选，与 EndByte 一起使用
+	EndByte     int    // 结束字节偏移（不含），可选，大于0时按文件内容换算为行列查询，优先于行号范围
+	// LanguageFallback 可选，无法按扩展名推断语言时在所有支持的语言中查找，默认返回不支持的语言错误
+	LanguageFallback bool
+	// Resolution 可选，按名称匹配到多个定义时的取舍策略，为空时使用 DefinitionResolutionRelaxed；
+	// 代码片段查询为空时按 DefinitionResolutionStrict 处理
+	Resolution DefinitionResolution
+	// IncludeDoc 可选，为定义填充其文档注释（Go、Java 等语言为声明前的注释，Python 为 docstring）
+	IncludeDoc bool
+	// LocalOnly 可选，只返回查询文件内的定义，不查找其他文件和外部索引，仅对行号范围（字节偏移）查询生效
+	LocalOnly bool
 }
 
+// DefinitionResolution 定义解析策略。Python、JS 等动态语言无法按类型确定定义，只能按名称匹配，
+// 匹配结果需要结合导入和位置取舍
+type DefinitionResolution string
+
+const (
+	// DefinitionResolutionRelaxed 只保留导入匹配的定义，全部不匹配时返回所有同名定义
+	DefinitionResolutionRelaxed DefinitionResolution = "relaxed"
+	// DefinitionResolutionStrict 只返回导入匹配的定义，全部不匹配时返回空
+	DefinitionResolutionStrict DefinitionResolution = "strict"
+	// DefinitionResolutionScoped 优先返回同文件的定义，其次同目录，都没有时按 relaxed 处理
+	DefinitionResolutionScoped DefinitionResolution = "scoped"
+)
+
+// SymbolKind 引用的种类，用于过滤引用查询结果
+type SymbolKind string
+
+const (
+	SymbolKindCall      SymbolKind = "call"      // 函数、方法调用
+	SymbolKindReference SymbolKind = "reference" // 调用以外的引用，如类型引用
+)
+
 type QueryReferenceOptions struct {
-	Workspace  string
-	FilePath   string
-	StartLine  int
-	EndLine    int
-	SymbolName string
+	Workspace      string
+	FilePath       string
+	StartLine      int
+	EndLine        int
+	StartColumn    int // 开始列（从1开始），可选，用于区分同一行的多个符号
+	EndColumn      int // 结束列（从1开始），可选
+	SymbolName     string
+	ProjectUuid    string       // 项目uuid，可选，指定后直接查询该项目，跳过工作区项目发现
+	ReferenceKinds []SymbolKind // 可选，只返回指定种类的引用，为空时返回所有种类
+	MaxLineLimit   int          // 可选，行范围最大跨度，小于等于0时使用默认值，超过上限时按上限处理
+	ExcludeTests   bool         // 可选，排除测试文件中的引用
+	SameFileOnly   bool         // 可选，只在 FilePath 所在文件内查找引用，不遍历项目，用于编辑器高亮同一符号
+	Offset         int          // 可选，分页时跳过的引用数
+	Limit          int          // 可选，每页最多返回的引用数，小于等于0时不分页
+}
+
+// ReferencePage 一页引用查询结果。引用按所属定义、文件路径、位置排序，分页只作用于引用，定义总是全部返回
+type ReferencePage struct {
+	Definitions []*RelationNode // 定义节点，Children 为本页中属于该定义的引用
+	Total       int             // 分页前的引用总数
+}
+
+// QueryExpandedContextOptions 查询定义及其引用符号定义的扩展上下文
+type QueryExpandedContextOptions struct {
+	Workspace   string
+	FilePath    string
+	SymbolName  string
+	MaxRefs     int    // 最多展开的引用符号定义数，<=0 时使用默认值
+	ProjectUuid string // 项目uuid，可选，指定后直接查询该项目，跳过工作区项目发现
+}
+
+// ExpandedContext 目标定义及其直接引用（一层）的符号定义
+type ExpandedContext struct {
+	Target     *Definition
+	References []*Definition
+	Truncated  bool // 引用符号定义数超过 MaxRefs 被截断
 }
 
 type QueryCallGraphOptions struct {
-	Workspace  string
-	FilePath   string
-	LineRange  string
-	SymbolName string
-	MaxLayer   int
+	Workspace    string
+	FilePath     string
+	LineRange    string
+	SymbolName   string
+	MaxLayer     int
+	ProjectUuid  string // 项目uuid，可选，指定后直接查询该项目，跳过工作区项目发现
+	MaxLineLimit int    // 可选，行范围最大跨度，小于等于0时使用默认值，超过上限时按上限处理
+	PathPrefix   string // 可选，只沿该目录（或文件）下的调用者遍历，支持相对工作区的路径
+	DetectCycles bool   // 可选，标记闭合环路的调用者节点，不开启时直接跳过已访问的调用者
+	// IncludeVariables 可选，为每个展开的函数附加其函数体内引用的变量、常量、字段定义，作为不再展开的叶子节点
+	IncludeVariables bool
+	// MaxNodes 可选，调用图节点总数上限，达到后停止展开，未指定或超过服务端上限时使用服务端上限
+	MaxNodes int
+	// Timeout 可选，构建调用图的耗时上限，超时后停止展开，小于等于0时不限制
+	Timeout time.Duration
+	// ExcludeTests 可选，不沿测试文件中的调用者遍历
+	ExcludeTests bool
+}
+
+// NamingRule 命名规范规则，按语言配置
+type NamingRule struct {
+	Name         string        `json:"name"`         // 规则名称
+	Language     string        `json:"language"`     // 适用语言
+	ElementTypes []ElementType `json:"elementTypes"` // 适用的定义类型，为空表示所有定义
+	Pattern      string        `json:"pattern"`      // 名称需要满足的正则，为空不校验
+	ExportedOnly bool          `json:"exportedOnly"` // 仅校验导出（公开）的符号
+	RequireDoc   bool          `json:"requireDoc"`   // 要求定义有文档注释，仅校验函数、方法、类、接口
+}
+
+// NamingIssue 违反命名规范的定义
+type NamingIssue struct {
+	Rule     string    `json:"rule"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	FilePath string    `json:"filePath"`
+	Position *Position `json:"position,omitempty"`
+	Message  string    `json:"message"`
+}
+
+// FindUnusedSymbolsOptions 未引用符号查询选项
+type FindUnusedSymbolsOptions struct {
+	ProjectUuid  string        // 项目uuid，可选，指定后只检查该项目
+	ElementTypes []ElementType // 检查的定义类型，为空时检查函数、方法、类和接口
+	Allowlist    []string      // 视为入口、不做检查的符号名正则，为空时使用默认入口规则
+}
+
+// 索引一致性检查发现的问题类别
+const (
+	IndexViolationDanglingOccurrence = "dangling_occurrence" // 符号出现指向的文件没有元素表
+	IndexViolationMissingCallerFile  = "missing_caller_file" // 调用关系中调用者所在的文件已不存在
+	IndexViolationEmptyElementTable  = "empty_element_table" // 元素表为空且没有解析错误标记
+	IndexViolationCorruptedValue     = "corrupted_value"     // 值无法反序列化
+)
+
+// IndexViolation 一条索引不一致记录
+type IndexViolation struct {
+	ProjectUuid string `json:"projectUuid"`
+	Key         string `json:"key"`
+	FilePath    string `json:"filePath,omitempty"`
+	Message     string `json:"message"`
+}
+
+// VerifyReport 索引一致性检查结果。Violations 按类别分组，每个类别只保留部分样例，Counts 为各类别的完整数量
+type VerifyReport struct {
+	Workspace     string                       `json:"workspace"`
+	Projects      int                          `json:"projects"`
+	ElementTables int                          `json:"elementTables"`
+	Symbols       int                          `json:"symbols"`
+	CalleeMaps    int                          `json:"calleeMaps"`
+	Counts        map[string]int               `json:"counts"`
+	Violations    map[string][]*IndexViolation `json:"violations"`
+}
+
+// Consistent 没有发现任何不一致
+func (r *VerifyReport) Consistent() bool {
+	return len(r.Counts) == 0
 }
+
 type RelationNode struct {
 	FilePath   string          `json:"filePath,omitempty"`
 	SymbolName string          `json:"symbolName,omitempty"`
@@ -70,7 +235,73 @@ type RelationNode struct {
 	Content    string          `json:"content,omitempty"`
 	NodeType   string          `json:"nodeType,omitempty"`
 	Children   []*RelationNode `json:"children,omitempty"`
+	// Cyclic 调用图中该节点的定义已出现在祖先路径上，形成环，不再继续展开
+	Cyclic bool `json:"cyclic,omitempty"`
+	// Variable 该节点是被引用的变量、常量或字段定义，作为叶子节点不再展开
+	Variable bool `json:"variable,omitempty"`
+	// Truncated 达到节点数或耗时上限，该节点的调用者未展开或未完全展开
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CallGraphCycleNode 环上的一个符号
+type CallGraphCycleNode struct {
+	FilePath   string `json:"filePath"`
+	SymbolName string `json:"symbolName"`
+}
+
+// CallGraphCycle 调用图中检测到的一个环，Nodes 从环的起点沿调用者方向排列，最后一个节点回到起点
+type CallGraphCycle struct {
+	Nodes []CallGraphCycleNode `json:"nodes"`
+}
+
+// CollectCallGraphCycles 汇总调用图中 Cyclic 节点闭合的环
+func CollectCallGraphCycles(roots []*RelationNode) []*CallGraphCycle {
+	var cycles []*CallGraphCycle
+	var path []*RelationNode
+	var walk func(node *RelationNode)
+	walk = func(node *RelationNode) {
+		if node.Cyclic {
+			// 从祖先路径中找到环的起点
+			for i := len(path) - 1; i >= 0; i-- {
+				if path[i].FilePath != node.FilePath || path[i].SymbolName != node.SymbolName {
+					continue
+				}
+				cycle := &CallGraphCycle{}
+				for _, n := range path[i:] {
+					cycle.Nodes = append(cycle.Nodes, CallGraphCycleNode{FilePath: n.FilePath, SymbolName: n.SymbolName})
+				}
+				cycle.Nodes = append(cycle.Nodes, CallGraphCycleNode{FilePath: node.FilePath, SymbolName: node.SymbolName})
+				cycles = append(cycles, cycle)
+				break
+			}
+			return
+		}
+		path = append(path, node)
+		for _, child := range node.Children {
+			walk(child)
+		}
+		path = path[:len(path)-1]
+	}
+	for _, root := range roots {
+		walk(root)
+	}
+	return cycles
+}
+
+// IsCallGraphTruncated 调用图中是否存在因节点数或耗时上限未完全展开的节点
+func IsCallGraphTruncated(roots []*RelationNode) bool {
+	for _, root := range roots {
+		if root.Truncated || IsCallGraphTruncated(root.Children) {
+			return true
+		}
+	}
+	return false
 }
+
+// ReferenceEmitter 流式返回引用查询结果。reference 为 nil 时表示找到了定义 definition，
+// 否则 reference 为 definition 的一个引用；返回错误时终止查询
+type ReferenceEmitter func(definition, reference *RelationNode) error
+
 type CallerElement struct {
 	FilePath   string   `json:"filePath,omitempty"`
 	SymbolName string   `json:"symbolName,omitempty"`
@@ -80,6 +311,23 @@ type CallerElement struct {
 }
 type CodeGraphSummary struct {
 	TotalFiles int `json:"totalFiles"`
+	// Truncated 有项目在最近一次索引时达到 MaxFiles 上限，索引不完整
+	Truncated bool `json:"truncated"`
+	// CallGraphBuilt 所有已索引项目的调用图反向索引均已构建
+	CallGraphBuilt bool `json:"callGraphBuilt"`
+	// DiskUsage 索引占用的磁盘字节数，存储不支持统计时为0
+	DiskUsage int64 `json:"diskUsage"`
+}
+
+// WorkspaceStatus 已索引工作区的状态
+type WorkspaceStatus struct {
+	WorkspaceName  string `json:"workspaceName"`
+	WorkspacePath  string `json:"workspacePath"`
+	Active         bool   `json:"active"`
+	FileNum        int    `json:"fileNum"`             // 已索引的文件数
+	LastIndexTime  int64  `json:"lastIndexTime"`       // 最近一次索引的时间（unix秒），未索引时为0
+	CallGraphBuilt bool   `json:"callGraphBuilt"`      // 调用图反向索引是否已构建
+	DiskUsage      int64  `json:"diskUsage,omitempty"` // 索引占用的磁盘字节数
 }
 
 type Position struct {
@@ -116,3 +364,46 @@ func ToRange(position Position) []int32 {
 	return []int32{int32(position.StartLine) - 1, int32(position.StartColumn) - 1,
 		int32(position.EndLine) - 1, int32(position.EndColumn) - 1}
 }
+
+// CodegraphReconcileResult SQLite 记录的索引文件数与 LevelDB 实际文件数的核对结果
+type CodegraphReconcileResult struct {
+	WorkspacePath   string `json:"workspacePath"`
+	DatabaseFileNum int    `json:"databaseFileNum"` // SQLite 中记录的 codegraph_file_num
+	IndexFileNum    int    `json:"indexFileNum"`    // LevelDB 中实际的文件元素表数量
+	Corrected       bool   `json:"corrected"`       // 是否已修正 SQLite 记录
+}
+
+// IndexedProject 存储中已有索引的项目
+type IndexedProject struct {
+	Uuid     string `json:"uuid"`
+	Path     string `json:"path"`     // 项目路径，孤立项目未记录路径时为空
+	FileNum  int    `json:"fileNum"`  // 已索引的文件数
+	Orphaned bool   `json:"orphaned"` // 索引存在但文件系统中已找不到对应项目
+}
+
+// FileOutline 单个已索引文件的大纲，用于文档符号/大纲视图，各列表按位置排序
+type FileOutline struct {
+	Path        string            `json:"path"`
+	Language    string            `json:"language"`
+	Timestamp   int64             `json:"timestamp"`         // 索引时文件的修改时间
+	Package     *OutlineElement   `json:"package,omitempty"` // 包声明，语言没有包声明时为空
+	Imports     []*OutlineImport  `json:"imports"`
+	Definitions []*OutlineElement `json:"definitions"`
+	References  []*OutlineElement `json:"references"` // 类型、变量等非调用引用
+	Calls       []*OutlineElement `json:"calls"`
+}
+
+// OutlineElement 大纲中的一个元素
+type OutlineElement struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Position Position `json:"position"`
+}
+
+// OutlineImport 大纲中的一条导入
+type OutlineImport struct {
+	Name     string   `json:"name"`
+	Source   string   `json:"source,omitempty"`
+	Alias    string   `json:"alias,omitempty"`
+	Position Position `json:"position"`
+}
@@ -1,7 +1,9 @@
 package types
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"path/filepath"
 	"regexp"
 	"slices"
@@ -54,10 +56,66 @@ var SkipDir = errors.New("skip this directory")
 type WalkOptions struct {
 	IgnoreError  bool
 	VisitPattern *VisitPattern
+	// SkipSymlinks 跳过所有符号链接，默认跟随链接并按真实路径去重
+	SkipSymlinks bool
+	// FollowExternalSymlinks 跟随真实路径在遍历根目录之外的链接，默认跳过，避免遍历到工作区外的文件
+	FollowExternalSymlinks bool
+	// Concurrency 并发遍历目录的协程数，大于1时并发读取目录和文件属性，适用于 NFS 等高延迟文件系统。
+	// 并发时回调顺序不确定，但回调仍串行执行
+	Concurrency int
 }
 
 type SkipFunc func(fileInfo *FileInfo) (bool, error)
 
+// IndexFilter 单次索引临时生效的 glob 过滤规则，不持久化。语法同 .gitignore，相对工作区根目录匹配，支持 **
+type IndexFilter struct {
+	Include []string `json:"include,omitempty"` // 只索引匹配的文件，为空时不限制
+	Exclude []string `json:"exclude,omitempty"` // 跳过匹配的文件或目录
+}
+
+// IsEmpty 是否没有任何规则
+func (f *IndexFilter) IsEmpty() bool {
+	return f == nil || (len(f.Include) == 0 && len(f.Exclude) == 0)
+}
+
+type indexFilterKey struct{}
+
+// WithIndexFilter 将过滤规则写入上下文，只对使用该上下文的这一次索引生效，filter 为空时原样返回
+func WithIndexFilter(ctx context.Context, filter *IndexFilter) context.Context {
+	if filter.IsEmpty() {
+		return ctx
+	}
+	return context.WithValue(ctx, indexFilterKey{}, filter)
+}
+
+// IndexFilterFromContext 从上下文中取出过滤规则，不存在时返回 nil
+func IndexFilterFromContext(ctx context.Context) *IndexFilter {
+	if ctx == nil {
+		return nil
+	}
+	filter, _ := ctx.Value(indexFilterKey{}).(*IndexFilter)
+	return filter
+}
+
+// Validate 校验 glob 语法，返回第一个非法规则的错误
+func (f *IndexFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, patterns := range [][]string{f.Include, f.Exclude} {
+		for _, p := range patterns {
+			if strings.TrimSpace(p) == EmptyString {
+				return fmt.Errorf("empty glob pattern")
+			}
+			// ** 按单层通配校验语法即可
+			if _, err := filepath.Match(strings.ReplaceAll(p, "**", "*"), EmptyString); err != nil {
+				return fmt.Errorf("invalid glob pattern %q: %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
 type VisitPattern struct {
 	MaxVisitLimit   int
 	ExcludeExts     []string
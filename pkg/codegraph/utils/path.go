@@ -4,6 +4,8 @@ import (
 	"codebase-indexer/pkg/codegraph/types"
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -31,7 +33,67 @@ func ToUnixPath(rawPath string) string {
 
 // PathEqual 比较路径是否相等，/ \ 转为 /
 func PathEqual(a, b string) bool {
-	return filepath.ToSlash(a) == filepath.ToSlash(b)
+	return strings.ReplaceAll(a, types.WindowsSeparator, types.Slash) ==
+		strings.ReplaceAll(b, types.WindowsSeparator, types.Slash)
+}
+
+// ToStoragePath 将路径转换为索引中存储的形式：统一使用 / 分隔符并清理冗余路径元素，
+// 使同一文件在不同系统、不同分隔符写法下对应同一条索引。空路径原样返回
+func ToStoragePath(rawPath string) string {
+	if rawPath == types.EmptyString {
+		return types.EmptyString
+	}
+	return ToUnixPath(rawPath)
+}
+
+const fileURIScheme = "file://"
+
+// FileURIToPath 将编辑器传入的 file:// URI 解码为本地路径（处理百分号编码、Windows 盘符），非 URI 原样返回
+func FileURIToPath(rawPath string) string {
+	if len(rawPath) < len(fileURIScheme) || !strings.EqualFold(rawPath[:len(fileURIScheme)], fileURIScheme) {
+		return rawPath
+	}
+	u, err := url.Parse(rawPath)
+	if err != nil {
+		return rawPath
+	}
+	p := u.Path
+	// file:///C:/a/b.go → C:/a/b.go
+	if len(p) >= 3 && p[0] == '/' && p[2] == ':' && isDriveLetter(p[1]) {
+		p = p[1:]
+	}
+	// file://server/share/a.go → //server/share/a.go
+	if u.Host != types.EmptyString && u.Host != "localhost" {
+		p = "//" + u.Host + p
+	}
+	return filepath.FromSlash(p)
+}
+
+func isDriveLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// ErrPathNotInWorkspace 请求中的路径不在工作区内
+var ErrPathNotInWorkspace = errors.New("path is not in workspace")
+
+// NormalizeFilePath 规范化请求中的文件路径：解码 file:// URI，相对路径基于 workspace 解析，统一分隔符并清理冗余路径元素。
+// workspace 不为空时，相对路径和绝对路径规范化后都必须位于工作区内，否则返回 ErrPathNotInWorkspace，防止目录遍历
+func NormalizeFilePath(workspace, rawPath string) (string, error) {
+	if rawPath == types.EmptyString {
+		return types.EmptyString, nil
+	}
+	p := filepath.Clean(filepath.FromSlash(FileURIToPath(rawPath)))
+	if workspace == types.EmptyString {
+		return p, nil
+	}
+	root := filepath.Clean(filepath.FromSlash(FileURIToPath(workspace)))
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	if !PathEqual(p, root) && !IsSubdir(root, p) {
+		return types.EmptyString, fmt.Errorf("%w: %s is not in %s", ErrPathNotInWorkspace, rawPath, root)
+	}
+	return p, nil
 }
 
 // IsSubdir 判断sub绝对路径是否是parent绝对路径的子目录
@@ -121,26 +183,27 @@ func ListSubDirs(dir string) ([]string, error) {
 	return subDirs, nil
 }
 
-// EnsureTrailingSeparator 确保路径尾部带有系统对应的路径分隔符
-// 若已有分隔符则不重复添加
+// EnsureTrailingSeparator 确保路径尾部带有路径分隔符
+// 若已有分隔符（/ 或 \）则不重复添加，否则追加系统对应的分隔符
 func EnsureTrailingSeparator(path string) string {
 	if path == types.EmptyString {
 		return types.EmptyString
 	}
-	// 获取当前系统的路径分隔符（如'/'或'\\'）
-	sep := string(filepath.Separator)
-	// 判断路径最后一个字符是否为分隔符
-	if strings.HasSuffix(path, sep) {
+	// 判断路径最后一个字符是否为分隔符，索引中的路径统一为 /，传入的路径可能是系统分隔符
+	if strings.HasSuffix(path, types.Slash) || strings.HasSuffix(path, types.WindowsSeparator) {
 		return path
 	}
 	// 追加分隔符
-	return path + sep
+	return path + string(filepath.Separator)
 }
 
-// TrimLastSeparator 移除路径尾部最后一个系统分隔符
+// TrimLastSeparator 移除路径尾部最后一个分隔符（/ 或 \）
 // 问题：无法处理连续分隔符（如 "dir//" 会保留 "dir/"），根路径处理可能不符合预期
 func TrimLastSeparator(path string) string {
-	return strings.TrimSuffix(path, string(filepath.Separator))
+	if strings.HasSuffix(path, types.WindowsSeparator) {
+		return strings.TrimSuffix(path, types.WindowsSeparator)
+	}
+	return strings.TrimSuffix(path, types.Slash)
 }
 
 // FindLongestExistingPath 从路径末尾向上查找最长的存在路径（文件或目录均可）
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -172,6 +173,8 @@ func TestEnsureTrailingSeparator(t *testing.T) {
 		{"有分隔符-单级", "dir" + sep, "dir" + sep},
 		{"无分隔符-多级", "a" + sep + "b", "a" + sep + "b" + sep},
 		{"有分隔符-多级", "a" + sep + "b" + sep, "a" + sep + "b" + sep},
+		{"已有/分隔符", "a/b/", "a/b/"},
+		{"已有\\分隔符", `a\b\`, `a\b\`},
 	}
 
 	for _, tt := range tests {
@@ -183,6 +186,28 @@ func TestEnsureTrailingSeparator(t *testing.T) {
 	}
 }
 
+func TestToStoragePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"空路径", "", ""},
+		{"Unix路径", "/home/user/project/main.go", "/home/user/project/main.go"},
+		{"Windows路径", `C:\Users\project\main.go`, "C:/Users/project/main.go"},
+		{"混合分隔符", `C:\Users/project\pkg/main.go`, "C:/Users/project/pkg/main.go"},
+		{"尾部分隔符", `C:\Users\project\`, "C:/Users/project"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToStoragePath(tt.input); got != tt.expected {
+				t.Errorf("输入: %q\n期望: %q\n实际: %q", tt.input, tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestListSubDirs(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -492,3 +517,76 @@ func TestFindLongestExistingPath(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeFilePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix paths only")
+	}
+	tests := []struct {
+		name      string
+		workspace string
+		rawPath   string
+		expected  string
+		wantErr   bool
+	}{
+		{name: "AbsolutePath", workspace: "/home/user/project", rawPath: "/home/user/project/main.go", expected: "/home/user/project/main.go"},
+		{name: "FileURI", workspace: "/home/user/project", rawPath: "file:///home/user/project/main.go", expected: "/home/user/project/main.go"},
+		{name: "FileURIEncoded", workspace: "/home/user/my project", rawPath: "file:///home/user/my%20project/a%2Bb.go", expected: "/home/user/my project/a+b.go"},
+		{name: "FileURILocalhost", workspace: "/home/user/project", rawPath: "file://localhost/home/user/project/main.go", expected: "/home/user/project/main.go"},
+		{name: "RelativePath", workspace: "/home/user/project", rawPath: "pkg/main.go", expected: "/home/user/project/pkg/main.go"},
+		{name: "RelativeDotPath", workspace: "/home/user/project", rawPath: "./pkg/../main.go", expected: "/home/user/project/main.go"},
+		{name: "FileURIWorkspace", workspace: "file:///home/user/project", rawPath: "main.go", expected: "/home/user/project/main.go"},
+		{name: "RedundantSeparators", workspace: "/home/user/project", rawPath: "/home/user//project/./main.go", expected: "/home/user/project/main.go"},
+		{name: "EmptyPath", workspace: "/home/user/project", rawPath: "", expected: ""},
+		{name: "WorkspaceRoot", workspace: "/home/user/project", rawPath: ".", expected: "/home/user/project"},
+		{name: "NoWorkspace", workspace: "", rawPath: "/etc/passwd", expected: "/etc/passwd"},
+		{name: "RelativeTraversal", workspace: "/home/user/project", rawPath: "../../../etc/passwd", wantErr: true},
+		{name: "RelativeTraversalAfterClean", workspace: "/home/user/project", rawPath: "pkg/../../other/main.go", wantErr: true},
+		{name: "AbsoluteOutsideWorkspace", workspace: "/home/user/project", rawPath: "/etc/passwd", wantErr: true},
+		{name: "SiblingWithSamePrefix", workspace: "/home/user/project", rawPath: "/home/user/project2/main.go", wantErr: true},
+		{name: "FileURIOutsideWorkspace", workspace: "/home/user/project", rawPath: "file:///etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeFilePath(tt.workspace, tt.rawPath)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPathNotInWorkspace) {
+					t.Errorf("NormalizeFilePath(workspace=%q, rawPath=%q) error = %v, expected ErrPathNotInWorkspace",
+						tt.workspace, tt.rawPath, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeFilePath(workspace=%q, rawPath=%q) unexpected error: %v", tt.workspace, tt.rawPath, err)
+			}
+			if got != tt.expected {
+				t.Errorf("NormalizeFilePath(workspace=%q, rawPath=%q) = %q, expected %q",
+					tt.workspace, tt.rawPath, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFileURIToPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawPath  string
+		expected string
+	}{
+		{name: "NotURI", rawPath: "/home/user/main.go", expected: "/home/user/main.go"},
+		{name: "UpperCaseScheme", rawPath: "FILE:///home/user/main.go", expected: filepath.FromSlash("/home/user/main.go")},
+		{name: "WindowsDrive", rawPath: "file:///C:/Users/user/main.go", expected: filepath.FromSlash("C:/Users/user/main.go")},
+		{name: "WindowsDriveEncoded", rawPath: "file:///c%3A/Users/main.go", expected: filepath.FromSlash("c:/Users/main.go")},
+		{name: "UNCPath", rawPath: "file://server/share/main.go", expected: filepath.FromSlash("//server/share/main.go")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FileURIToPath(tt.rawPath)
+			if got != tt.expected {
+				t.Errorf("FileURIToPath(%q) = %q, expected %q", tt.rawPath, got, tt.expected)
+			}
+		})
+	}
+}
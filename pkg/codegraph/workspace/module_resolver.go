@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pelletier/go-toml/v2"
@@ -79,6 +80,24 @@ func (mr *ModuleResolver) ResolveProjectModules(ctx context.Context, project *Pr
 	}
 	//mr.logger.Debug("resolve project path %s go modules cost %d ms.", path, time.Since(goStart).Milliseconds())
 
+	// 解析C#项目命名空间
+	csharpNamespaces, err := mr.resolveCSharpNamespaces(ctx, path)
+	if err != nil {
+		mr.logger.Debug("project path %s resolve c# namespaces err: %v", path, err)
+	} else if len(csharpNamespaces) > 0 {
+		project.CSharpNamespaces = utils.DeDuplicate(append(project.CSharpNamespaces, csharpNamespaces...))
+		mr.logger.Debug("project path %s resolved c# namespaces: %v", path, csharpNamespaces)
+	}
+
+	// 解析TypeScript/JavaScript路径别名
+	tsPathAliases, err := mr.resolveTsPathAliases(ctx, path)
+	if err != nil {
+		mr.logger.Debug("project path %s resolve ts path aliases err: %v", path, err)
+	} else if len(tsPathAliases) > 0 {
+		project.TsPathAliases = append(project.TsPathAliases, tsPathAliases...)
+		mr.logger.Debug("project path %s resolved %d ts path aliases", path, len(tsPathAliases))
+	}
+
 	//// 解析Java包前缀
 	//javaPrefixes, err := mr.resolveJavaPackagePrefixes(ctx, path)
 	//if err != nil {
@@ -734,3 +753,186 @@ func (mr *ModuleResolver) resolveGoModules(ctx context.Context, projectPath stri
 
 	return utils.DeDuplicate(modules), nil
 }
+
+// CsProj .csproj文件结构，只关心命名空间相关属性
+type CsProj struct {
+	XMLName        xml.Name `xml:"Project"`
+	PropertyGroups []struct {
+		RootNamespace string `xml:"RootNamespace"`
+		AssemblyName  string `xml:"AssemblyName"`
+	} `xml:"PropertyGroup"`
+}
+
+// IsDotNetProjectDir 判断目录下是否有 .sln 或 .csproj 文件，用于确定C#项目根目录
+func IsDotNetProjectDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext == ".sln" || ext == ".csproj" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCSharpNamespaces 解析目录下所有 .csproj 文件的根命名空间
+// 优先使用 RootNamespace，其次 AssemblyName，都未配置时使用项目文件名（与 dotnet 默认行为一致）
+func (mr *ModuleResolver) resolveCSharpNamespaces(ctx context.Context, projectPath string) ([]string, error) {
+	csprojFiles, err := filepath.Glob(filepath.Join(projectPath, "*.csproj"))
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for _, csprojPath := range csprojFiles {
+		namespace, err := mr.parseCsProj(csprojPath)
+		if err != nil {
+			mr.logger.Debug("parse csproj file %s err: %v", csprojPath, err)
+		}
+		if namespace == "" {
+			namespace = strings.TrimSuffix(filepath.Base(csprojPath), filepath.Ext(csprojPath))
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	return utils.DeDuplicate(namespaces), nil
+}
+
+// parseCsProj 解析.csproj文件，返回配置的根命名空间，未配置时返回空
+func (mr *ModuleResolver) parseCsProj(csprojPath string) (string, error) {
+	data, err := os.ReadFile(csprojPath)
+	if err != nil {
+		return "", fmt.Errorf("read csproj file err: %v", err)
+	}
+	var csproj CsProj
+	if err = xml.Unmarshal(data, &csproj); err != nil {
+		return "", fmt.Errorf("parse csproj file err: %v", err)
+	}
+	var assemblyName string
+	for _, group := range csproj.PropertyGroups {
+		if ns := strings.TrimSpace(group.RootNamespace); ns != "" {
+			return ns, nil
+		}
+		if name := strings.TrimSpace(group.AssemblyName); name != "" && assemblyName == "" {
+			assemblyName = name
+		}
+	}
+	return assemblyName, nil
+}
+
+// tsConfigFiles 声明路径别名的配置文件，jsconfig.json 为纯 JavaScript 项目的等价配置
+var tsConfigFiles = []string{"tsconfig.json", "jsconfig.json"}
+
+// TsConfig tsconfig.json/jsconfig.json 中与路径别名相关的部分
+type TsConfig struct {
+	CompilerOptions struct {
+		BaseUrl string              `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+}
+
+// resolveTsPathAliases 解析 tsconfig.json、jsconfig.json 中的 compilerOptions.paths。
+// 映射目标相对 baseUrl 解析，未设置 baseUrl 时相对配置文件所在目录。不处理 extends 继承的配置
+func (mr *ModuleResolver) resolveTsPathAliases(ctx context.Context, projectPath string) ([]*TsPathAlias, error) {
+	var aliases []*TsPathAlias
+	for _, name := range tsConfigFiles {
+		data, err := os.ReadFile(filepath.Join(projectPath, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read %s err: %v", name, err)
+		}
+		var config TsConfig
+		if err := json.Unmarshal(stripJsonComments(data), &config); err != nil {
+			return nil, fmt.Errorf("parse %s err: %v", name, err)
+		}
+		baseDir := filepath.Join(projectPath, config.CompilerOptions.BaseUrl)
+		patterns := make([]string, 0, len(config.CompilerOptions.Paths))
+		for pattern := range config.CompilerOptions.Paths {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			alias := &TsPathAlias{Pattern: pattern}
+			for _, target := range config.CompilerOptions.Paths[pattern] {
+				alias.Targets = append(alias.Targets, filepath.Join(baseDir, filepath.FromSlash(target)))
+			}
+			if len(alias.Targets) > 0 {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	return aliases, nil
+}
+
+// stripJsonComments 去掉 JSON 中字符串以外的 // 和 /* */ 注释以及对象、数组末尾多余的逗号，
+// tsconfig.json 允许这些写法，标准库无法直接解析
+func stripJsonComments(data []byte) []byte {
+	// 第一遍去掉注释
+	stripped := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			stripped = append(stripped, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				stripped = append(stripped, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			stripped = append(stripped, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return stripped
+			}
+			i += end + 3
+		default:
+			stripped = append(stripped, c)
+		}
+	}
+
+	// 第二遍去掉下一个有效字符为 } 或 ] 的逗号
+	out := make([]byte, 0, len(stripped))
+	inString = false
+	for i := 0; i < len(stripped); i++ {
+		c := stripped[i]
+		if inString {
+			if c == '\\' && i+1 < len(stripped) {
+				out = append(out, c)
+				i++
+				c = stripped[i]
+			} else if c == '"' {
+				inString = false
+			}
+			out = append(out, c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		} else if c == ',' {
+			j := i + 1
+			for j < len(stripped) && (stripped[j] == ' ' || stripped[j] == '\t' || stripped[j] == '\r' || stripped[j] == '\n') {
+				j++
+			}
+			if j < len(stripped) && (stripped[j] == '}' || stripped[j] == ']') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -102,6 +102,7 @@ func TestResolveProjectModules(t *testing.T) {
 		t.Error("未找到开始解析的日志消息")
 	}
 }
+
 // TODO 待进一步校验
 // TestResolveProjectModulesWithVariousLanguages 测试各种语言包信息的解析
 func TestResolveProjectModulesWithVariousLanguages(t *testing.T) {
@@ -857,3 +858,148 @@ func containsString(slice []string, str string) bool {
 	}
 	return false
 }
+
+func TestResolveCSharpNamespaces(t *testing.T) {
+	ctx := context.Background()
+	resolver := NewModuleResolver(NewMockLogger())
+
+	tests := []struct {
+		name     string
+		csprojs  map[string]string
+		expected []string
+	}{
+		{
+			name: "RootNamespace优先",
+			csprojs: map[string]string{
+				"Demo.Web.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <AssemblyName>Demo.Web.Host</AssemblyName>
+    <RootNamespace>Demo.Web</RootNamespace>
+  </PropertyGroup>
+</Project>`,
+			},
+			expected: []string{"Demo.Web"},
+		},
+		{
+			name: "使用AssemblyName",
+			csprojs: map[string]string{
+				"App.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <PropertyGroup>
+    <AssemblyName>Demo.Core</AssemblyName>
+  </PropertyGroup>
+</Project>`,
+			},
+			expected: []string{"Demo.Core"},
+		},
+		{
+			name: "未配置时使用文件名",
+			csprojs: map[string]string{
+				"Demo.Shop.csproj": `<Project Sdk="Microsoft.NET.Sdk"></Project>`,
+			},
+			expected: []string{"Demo.Shop"},
+		},
+		{
+			name:     "没有csproj文件",
+			csprojs:  map[string]string{},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for name, content := range tt.csprojs {
+				if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("创建 %s 文件失败: %v", name, err)
+				}
+			}
+			namespaces, err := resolver.resolveCSharpNamespaces(ctx, tempDir)
+			if err != nil {
+				t.Fatalf("解析 C# 命名空间时发生错误: %v", err)
+			}
+			if strings.Join(namespaces, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("C# 命名空间解析不正确，期望: %v, 实际: %v", tt.expected, namespaces)
+			}
+			if IsDotNetProjectDir(tempDir) != (len(tt.csprojs) > 0) {
+				t.Errorf("C# 项目目录识别不正确: %s", tempDir)
+			}
+		})
+	}
+}
+
+func TestResolveTsPathAliases(t *testing.T) {
+	ctx := context.Background()
+	resolver := NewModuleResolver(NewMockLogger())
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string // pattern=target，target 为相对项目目录的路径
+	}{
+		{
+			name: "带注释和末尾逗号的tsconfig",
+			files: map[string]string{"tsconfig.json": `{
+  // 路径别名
+  "compilerOptions": {
+    "baseUrl": "./",
+    /* 通配映射 */
+    "paths": {
+      "@app/*": ["src/*", "generated/*",],
+      "@utils": ["src/utils/index.ts"],
+    },
+  },
+}`},
+			expected: []string{"@app/*=src/*", "@app/*=generated/*", "@utils=src/utils/index.ts"},
+		},
+		{
+			name:     "目标相对baseUrl",
+			files:    map[string]string{"tsconfig.json": `{"compilerOptions": {"baseUrl": "src", "paths": {"~/*": ["*"]}}}`},
+			expected: []string{"~/*=src/*"},
+		},
+		{
+			name:     "jsconfig",
+			files:    map[string]string{"jsconfig.json": `{"compilerOptions": {"paths": {"@/*": ["./lib/*"]}}}`},
+			expected: []string{"@/*=lib/*"},
+		},
+		{
+			name:     "字符串中的注释符号不处理",
+			files:    map[string]string{"tsconfig.json": `{"compilerOptions": {"paths": {"//*": ["src/*"]}}}`},
+			expected: []string{"//*=src/*"},
+		},
+		{
+			name:     "没有配置文件",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("创建 %s 文件失败: %v", name, err)
+				}
+			}
+			aliases, err := resolver.resolveTsPathAliases(ctx, tempDir)
+			if err != nil {
+				t.Fatalf("解析路径别名时发生错误: %v", err)
+			}
+			var actual []string
+			for _, alias := range aliases {
+				for _, target := range alias.Targets {
+					rel, err := filepath.Rel(tempDir, target)
+					if err != nil {
+						t.Fatalf("计算相对路径失败: %v", err)
+					}
+					actual = append(actual, alias.Pattern+"="+filepath.ToSlash(rel))
+				}
+			}
+			if strings.Join(actual, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("路径别名解析不正确，期望: %v, 实际: %v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"codebase-indexer/pkg/codegraph/types"
 	"crypto/sha256"
 	"encoding/hex"
+	"path/filepath"
 
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // Project 项目基础配置信息
@@ -26,6 +28,17 @@ type Project struct {
 	CppIncludes []string
 	// JsPackages JavaScript/TypeScript 项目包列表（如 myapp, @myapp/utils）
 	JsPackages []string
+	// CSharpNamespaces C# 项目根命名空间列表（来自 .csproj 的 RootNamespace/AssemblyName）
+	CSharpNamespaces []string
+	// TsPathAliases TypeScript/JavaScript 路径别名（来自 tsconfig.json/jsconfig.json 的 compilerOptions.paths）
+	TsPathAliases []*TsPathAlias
+}
+
+// TsPathAlias tsconfig paths 中的一条映射，如 "@app/*": ["src/*"]。
+// Pattern 最多包含一个 *，Targets 为按 baseUrl 解析后的绝对路径，同样可以包含 *
+type TsPathAlias struct {
+	Pattern string
+	Targets []string
 }
 
 func NewProject(name, path string) *Project {
@@ -38,16 +51,22 @@ func NewProject(name, path string) *Project {
 		PythonPackages:    []string{}, // 默认为空切片
 		CppIncludes:       []string{}, // 默认为空切片
 		JsPackages:        []string{}, // 默认为空切片
+		CSharpNamespaces:  []string{}, // 默认为空切片
+		TsPathAliases:     []*TsPathAlias{},
 	}
 }
 
-// generateUuid 生成缩短的项目UUID，保持唯一性同时减少长度
+// generateUuid 生成缩短的项目UUID，保持唯一性同时减少长度。
+// 由项目绝对路径派生，不同工作区的项目路径不同，多个工作区共用一个存储时索引互不干扰。
+// 路径先规范化（去掉末尾分隔符、多余的 . 和 ..），与扫描顺序和传入路径的写法无关，同一项目总是对应同一个存储目录
 func generateUuid(name, path string) string {
 	if name == types.EmptyString {
 		name = "empty"
 	}
 	if path == types.EmptyString {
 		path = "empty"
+	} else {
+		path = filepath.Clean(path)
 	}
 
 	// 计算路径的SHA-256哈希
@@ -88,4 +107,14 @@ func (l CalleeMapItems) Value(i int) proto.Message {
 }
 func (l CalleeMapItems) Key(i int) store.Key {
 	return store.CalleeMapKey{SymbolName: l[i].CalleeName}
+}
+
+type CallerFileKeys []store.CallerFileKey
+
+func (l CallerFileKeys) Len() int { return len(l) }
+func (l CallerFileKeys) Value(i int) proto.Message {
+	return &emptypb.Empty{}
+}
+func (l CallerFileKeys) Key(i int) store.Key {
+	return l[i]
 }
\ No newline at end of file
@@ -0,0 +1,252 @@
+package workspace
+
+import (
+	"codebase-indexer/pkg/codegraph/types"
+	"codebase-indexer/pkg/codegraph/utils"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// walkDirJob 待遍历的目录，回调中的路径按逻辑目录 logical 展示，实际读取真实目录 real
+type walkDirJob struct {
+	logical string
+	real    string
+}
+
+// concurrentWalker 并发遍历目录。读取目录和 stat 在多个协程中并发执行，
+// 跳过规则判断、去重、计数和 walkFn 回调串行执行，回调顺序不确定，但 walkFn 无需考虑并发
+type concurrentWalker struct {
+	ctx      context.Context
+	dir      string
+	realDir  string
+	walkFn   types.WalkFunc
+	walkOpts types.WalkOptions
+
+	// mu 保护以下字段，以及 walkFn 和 VisitPattern.ShouldSkip 的调用
+	mu           sync.Mutex
+	cond         *sync.Cond
+	queue        []walkDirJob
+	pending      int // 已入队但未处理完的目录数
+	stopped      bool
+	err          error
+	visitCount   int
+	visitedDirs  map[string]struct{}
+	visitedFiles map[string]struct{}
+}
+
+// walkFileConcurrent 以 concurrency 个协程遍历 dir（真实路径为 realDir），跳过规则、链接处理和去重与串行遍历一致
+func walkFileConcurrent(ctx context.Context, dir, realDir string, walkFn types.WalkFunc,
+	walkOpts types.WalkOptions, concurrency int) error {
+	w := &concurrentWalker{
+		ctx:          ctx,
+		dir:          dir,
+		realDir:      realDir,
+		walkFn:       walkFn,
+		walkOpts:     walkOpts,
+		visitedDirs:  map[string]struct{}{realDir: {}},
+		visitedFiles: make(map[string]struct{}),
+		queue:        []walkDirJob{{logical: dir, real: realDir}},
+		pending:      1,
+	}
+	w.cond = sync.NewCond(&w.mu)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				job, ok := w.next()
+				if !ok {
+					return
+				}
+				w.walkDir(job)
+				w.finish()
+			}
+		}()
+	}
+	wg.Wait()
+	return w.err
+}
+
+// next 取出下一个待遍历的目录，全部处理完或已停止时返回 false
+func (w *concurrentWalker) next() (walkDirJob, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue) == 0 && w.pending > 0 && !w.stopped {
+		w.cond.Wait()
+	}
+	if w.stopped || len(w.queue) == 0 {
+		return walkDirJob{}, false
+	}
+	job := w.queue[len(w.queue)-1]
+	w.queue = w.queue[:len(w.queue)-1]
+	return job, true
+}
+
+// finish 标记一个目录处理完成
+func (w *concurrentWalker) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending--
+	if w.pending == 0 {
+		w.cond.Broadcast()
+	}
+}
+
+// stop 停止遍历，err 不为空时作为遍历结果返回，调用方需持有锁
+func (w *concurrentWalker) stop(err error) {
+	if !w.stopped {
+		w.stopped = true
+		w.err = err
+	}
+	w.cond.Broadcast()
+}
+
+// isStopped 是否已停止遍历
+func (w *concurrentWalker) isStopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stopped
+}
+
+// walkDir 遍历单个目录下的直接子项，子目录入队由其他协程处理
+func (w *concurrentWalker) walkDir(job walkDirJob) {
+	if err := w.ctx.Err(); err != nil {
+		w.mu.Lock()
+		w.stop(err)
+		w.mu.Unlock()
+		return
+	}
+	entries, err := os.ReadDir(job.real)
+	if err != nil && !w.walkOpts.IgnoreError {
+		w.mu.Lock()
+		w.stop(err)
+		w.mu.Unlock()
+		return
+	}
+	for _, entry := range entries {
+		if w.isStopped() {
+			return
+		}
+		if err := w.visit(job, entry); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				// 与 filepath.WalkDir 一致，文件回调返回 SkipDir 时跳过所在目录的剩余子项
+				return
+			}
+			w.mu.Lock()
+			if errors.Is(err, filepath.SkipAll) {
+				w.stop(nil)
+			} else {
+				w.stop(err)
+			}
+			w.mu.Unlock()
+			return
+		}
+	}
+}
+
+// visit 处理目录下的一个子项，返回 filepath.SkipDir、filepath.SkipAll 或回调的错误
+func (w *concurrentWalker) visit(job walkDirJob, entry fs.DirEntry) error {
+	// 跳过隐藏文件和目录
+	if utils.IsHiddenFile(entry.Name()) {
+		return nil
+	}
+	realPath := filepath.Join(job.real, entry.Name())
+	filePath := filepath.Join(job.logical, entry.Name())
+	relativePath, err := filepath.Rel(w.dir, filePath)
+	if err != nil && !w.walkOpts.IgnoreError {
+		return err
+	}
+
+	isSymlink := entry.Type()&fs.ModeSymlink != 0
+	if isSymlink && w.walkOpts.SkipSymlinks {
+		return nil
+	}
+
+	// stat 不持锁，是高延迟文件系统上并发的主要收益
+	isDir := entry.IsDir()
+	var size int64
+	var modTime time.Time
+	if isSymlink {
+		// 链接按目标的真实路径和属性处理，目标不存在时跳过
+		target, err := filepath.EvalSymlinks(realPath)
+		if err != nil {
+			return nil
+		}
+		if !w.walkOpts.FollowExternalSymlinks && !isWithinDir(w.realDir, target) {
+			return nil
+		}
+		targetInfo, err := os.Stat(target)
+		if err != nil {
+			return nil
+		}
+		realPath = target
+		isDir = targetInfo.IsDir()
+		size = targetInfo.Size()
+		modTime = targetInfo.ModTime()
+	} else if fileInfo, err := entry.Info(); err == nil {
+		size = fileInfo.Size()
+		modTime = fileInfo.ModTime()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return nil
+	}
+	skip, err := w.walkOpts.VisitPattern.ShouldSkip(&types.FileInfo{
+		Name:    entry.Name(),
+		Path:    filePath,
+		IsDir:   isDir,
+		Size:    size,
+		ModTime: modTime,
+	})
+	if skip {
+		return nil
+	}
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return err
+	}
+
+	// 已遍历过的目录（包括链接形成的环路）和已回调过的文件不再重复处理
+	visited := w.visitedFiles
+	if isDir {
+		visited = w.visitedDirs
+	}
+	if _, ok := visited[realPath]; ok {
+		return nil
+	}
+	visited[realPath] = struct{}{}
+
+	w.visitCount++
+	if w.visitCount > w.walkOpts.VisitPattern.MaxVisitLimit {
+		return filepath.SkipAll
+	}
+
+	// 只回调文件，目录入队等待遍历
+	if isDir {
+		w.queue = append(w.queue, walkDirJob{logical: filePath, real: realPath})
+		w.pending++
+		w.cond.Signal()
+		return nil
+	}
+
+	return w.walkFn(&types.WalkContext{
+		Path:         filePath,
+		RelativePath: filepath.ToSlash(relativePath),
+		Info: &types.FileInfo{
+			Name:    entry.Name(),
+			Path:    filePath,
+			IsDir:   false,
+			Size:    size,
+			ModTime: modTime,
+		},
+		ParentPath: filepath.Dir(filePath),
+	})
+}
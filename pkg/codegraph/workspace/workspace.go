@@ -80,8 +80,8 @@ func (w *workspaceReader) FindProjects(ctx context.Context, workspacePath string
 		return err == nil && info.IsDir()
 	}
 
-	// 1. 当前目录是 git 仓库
-	if hasGitDir(workspacePath) {
+	// 1. 当前目录是 git 仓库或 .sln/.csproj 所在的C#项目
+	if hasGitDir(workspacePath) || IsDotNetProjectDir(workspacePath) {
 		projectName := filepath.Base(workspacePath)
 		project := &Project{
 			Path: workspacePath,
@@ -138,7 +138,7 @@ func (w *workspaceReader) FindProjects(ctx context.Context, workspacePath string
 						continue
 					}
 
-					if hasGitDir(subDir) {
+					if hasGitDir(subDir) || IsDotNetProjectDir(subDir) {
 						projectName := filepath.Base(subDir)
 						project := &Project{
 							Path: subDir,
@@ -180,6 +180,27 @@ func (w *workspaceReader) FindProjects(ctx context.Context, workspacePath string
 		}
 	}
 
+	// 4. Go 模块项目下嵌套的 go.mod 作为独立项目，文件按路径归属到最内层的模块
+	for _, p := range projects {
+		if !isGoModuleRoot(p.Path) {
+			continue
+		}
+		for _, moduleDir := range findNestedGoModules(p.Path, visitPattern) {
+			projectName := filepath.Base(moduleDir)
+			project := &Project{
+				Path: moduleDir,
+				Name: projectName,
+				Uuid: generateUuid(projectName, moduleDir),
+			}
+			projects = append(projects, project)
+			if resolveModule {
+				if err := moduleResolver.ResolveProjectModules(ctx, project, project.Path, 2); err != nil {
+					w.logger.Error("resolve project modules err:%v", err)
+				}
+			}
+		}
+	}
+
 	var projectNames string
 	var goModules []string
 	for _, p := range projects {
@@ -192,6 +213,64 @@ func (w *workspaceReader) FindProjects(ctx context.Context, workspacePath string
 	return projects
 }
 
+// nestedGoModuleMaxDepth 查找嵌套 Go 模块的最大目录深度
+const nestedGoModuleMaxDepth = 4
+
+// isGoModuleRoot 判断目录是否为 Go 模块或 Go 工作区的根目录
+func isGoModuleRoot(dir string) bool {
+	for _, marker := range []string{"go.mod", "go.work"} {
+		if info, err := os.Stat(filepath.Join(dir, marker)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// NestedProjectRoots 返回 FindProjects 在项目下识别为独立项目的子目录（目前为 Go 模块下嵌套的 go.mod），
+// 收集项目文件时需要跳过，避免同一文件被两个项目重复索引
+func NestedProjectRoots(projectPath string, visitPattern *types.VisitPattern) []string {
+	if !isGoModuleRoot(projectPath) {
+		return nil
+	}
+	return findNestedGoModules(projectPath, visitPattern)
+}
+
+// findNestedGoModules 广度优先查找项目目录下含 go.mod 的子目录（不含项目根目录），
+// 跳过隐藏目录、vendor、testdata 以及过滤规则排除的目录
+func findNestedGoModules(projectPath string, visitPattern *types.VisitPattern) []string {
+	type queueItem struct {
+		dir   string
+		depth int
+	}
+	var modules []string
+	queue := []queueItem{{dir: projectPath, depth: 0}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		entries, err := os.ReadDir(current.dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, types.Dot) || name == "vendor" || name == "testdata" {
+				continue
+			}
+			subDir := filepath.Join(current.dir, name)
+			if skip, _ := visitPattern.ShouldSkip(&types.FileInfo{Path: subDir, IsDir: true}); skip {
+				continue
+			}
+			if info, err := os.Stat(filepath.Join(subDir, "go.mod")); err == nil && !info.IsDir() {
+				modules = append(modules, subDir)
+			}
+			if current.depth+1 < nestedGoModuleMaxDepth {
+				queue = append(queue, queueItem{dir: subDir, depth: current.depth + 1})
+			}
+		}
+	}
+	return modules
+}
+
 // ReadFile 读取单个文件
 func (w *workspaceReader) ReadFile(ctx context.Context, path string, option types.ReadOptions) ([]byte, error) {
 
@@ -285,7 +364,15 @@ func (w *workspaceReader) Exists(ctx context.Context, path string) (bool, error)
 	return true, nil
 }
 
-// WalkFile 遍历目录下的文件
+// isWithinDir 真实路径 target 是否为 root 本身或在 root 之下
+func isWithinDir(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// WalkFile 遍历目录下的文件。默认跟随目标在 dir 内的符号链接，按真实路径去重：
+// 指向祖先目录的目录链接（环路）和已遍历过的目录不再进入，经多个链接到达的同一文件只回调一次；
+// 目标在 dir 之外的链接默认跳过，FollowExternalSymlinks 开启时跟随；SkipSymlinks 开启时跳过所有符号链接
 func (w *workspaceReader) WalkFile(ctx context.Context, dir string, walkFn types.WalkFunc, walkOpts types.WalkOptions) error {
 	if dir == types.EmptyString {
 		return errors.New("dir cannot be empty")
@@ -302,86 +389,165 @@ func (w *workspaceReader) WalkFile(ctx context.Context, dir string, walkFn types
 	if walkOpts.VisitPattern.MaxVisitLimit <= 0 {
 		walkOpts.VisitPattern.MaxVisitLimit = MaxFileVisitLimit
 	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if walkOpts.Concurrency > 1 {
+		return walkFileConcurrent(ctx, dir, realDir, walkFn, walkOpts, walkOpts.Concurrency)
+	}
 
 	var visitCount int
+	// 已遍历的目录和已回调的文件的真实路径
+	visitedDirs := make(map[string]struct{})
+	visitedFiles := make(map[string]struct{})
+	// 符号链接目录的遍历中途返回 SkipAll 时，外层遍历也需要停止
+	stopped := false
+
+	// walkDir 遍历真实目录 realRoot，回调中的路径按逻辑目录 logicalRoot 展示
+	var walkDir func(logicalRoot, realRoot string) error
+	walkDir = func(logicalRoot, realRoot string) error {
+		visit := func(realPath string, info fs.DirEntry, err error) error {
+			if stopped {
+				return filepath.SkipAll
+			}
+			if err != nil && !walkOpts.IgnoreError {
+				return err
+			}
+			if info == nil {
+				return nil
+			}
 
-	return filepath.WalkDir(dir, func(filePath string, info fs.DirEntry, err error) error {
-		if err != nil && !walkOpts.IgnoreError {
-			return err
-		}
+			filePath := logicalRoot
+			if realPath != realRoot {
+				filePath = filepath.Join(logicalRoot, strings.TrimPrefix(realPath, realRoot+string(filepath.Separator)))
+			} else if logicalRoot != dir {
+				// 符号链接目录本身已在外层处理
+				return nil
+			}
 
-		// 跳过隐藏文件和目录
-		if utils.IsHiddenFile(info.Name()) {
-			if info.IsDir() {
-				return filepath.SkipDir
+			// 跳过隐藏文件和目录
+			if utils.IsHiddenFile(info.Name()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
 			}
-			return nil
-		}
 
-		relativePath, err := filepath.Rel(dir, filePath)
-		if err != nil && !walkOpts.IgnoreError {
-			return err
-		}
+			relativePath, err := filepath.Rel(dir, filePath)
+			if err != nil && !walkOpts.IgnoreError {
+				return err
+			}
 
-		if relativePath == types.Dot {
-			return nil
-		}
+			if relativePath == types.Dot {
+				visitedDirs[realPath] = struct{}{}
+				return nil
+			}
 
-		var size int64
-		var modTime time.Time
-		if fileInfo, err := info.Info(); err == nil {
-			size = fileInfo.Size()
-			modTime = fileInfo.ModTime()
-		}
+			isSymlink := info.Type()&fs.ModeSymlink != 0
+			if isSymlink && walkOpts.SkipSymlinks {
+				return nil
+			}
 
-		skip, err := walkOpts.VisitPattern.ShouldSkip(
-			&types.FileInfo{
-				Name:    info.Name(),
-				Path:    filePath,
-				IsDir:   info.IsDir(),
-				Size:    size,
-				ModTime: modTime,
-			})
-		if skip {
-			// 跳过目录
-			if info.IsDir() {
-				return filepath.SkipDir
+			isDir := info.IsDir()
+			var size int64
+			var modTime time.Time
+			if isSymlink {
+				// 链接按目标的真实路径和属性处理，目标不存在时跳过
+				target, err := filepath.EvalSymlinks(realPath)
+				if err != nil {
+					return nil
+				}
+				if !walkOpts.FollowExternalSymlinks && !isWithinDir(realDir, target) {
+					return nil
+				}
+				targetInfo, err := os.Stat(target)
+				if err != nil {
+					return nil
+				}
+				realPath = target
+				isDir = targetInfo.IsDir()
+				size = targetInfo.Size()
+				modTime = targetInfo.ModTime()
+			} else if fileInfo, err := info.Info(); err == nil {
+				size = fileInfo.Size()
+				modTime = fileInfo.ModTime()
+			}
+
+			skip, err := walkOpts.VisitPattern.ShouldSkip(
+				&types.FileInfo{
+					Name:    info.Name(),
+					Path:    filePath,
+					IsDir:   isDir,
+					Size:    size,
+					ModTime: modTime,
+				})
+			if skip {
+				// 跳过目录
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+				return err
 			}
-			return nil
-		}
-		if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
-			return err
-		}
 
-		// Convert Windows filePath separators to forward slashes
-		relativePath = filepath.ToSlash(relativePath)
+			// 已遍历过的目录（包括链接形成的环路）和已回调过的文件不再重复处理
+			visited := visitedFiles
+			if isDir {
+				visited = visitedDirs
+			}
+			if _, ok := visited[realPath]; ok {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			visited[realPath] = struct{}{}
 
-		visitCount++
-		if visitCount > walkOpts.VisitPattern.MaxVisitLimit {
-			return filepath.SkipAll
-		}
+			// Convert Windows filePath separators to forward slashes
+			relativePath = filepath.ToSlash(relativePath)
 
-		// 只处理文件，不处理目录
-		if info.IsDir() {
-			return nil
-		}
+			visitCount++
+			if visitCount > walkOpts.VisitPattern.MaxVisitLimit {
+				return filepath.SkipAll
+			}
 
-		// 构建 WalkContext
-		walkCtx := &types.WalkContext{
-			Path:         filePath,
-			RelativePath: relativePath,
-			Info: &types.FileInfo{
-				Name:    info.Name(),
-				Path:    filePath,
-				IsDir:   info.IsDir(),
-				Size:    size,
-				ModTime: modTime,
-			},
-			ParentPath: filepath.Dir(filePath),
+			// 只处理文件，不处理目录，链接到的目录在这里进入遍历
+			if isDir {
+				if isSymlink {
+					return walkDir(filePath, realPath)
+				}
+				return nil
+			}
+
+			// 构建 WalkContext
+			walkCtx := &types.WalkContext{
+				Path:         filePath,
+				RelativePath: relativePath,
+				Info: &types.FileInfo{
+					Name:    info.Name(),
+					Path:    filePath,
+					IsDir:   false,
+					Size:    size,
+					ModTime: modTime,
+				},
+				ParentPath: filepath.Dir(filePath),
+			}
+
+			return walkFn(walkCtx)
 		}
+		return filepath.WalkDir(realRoot, func(realPath string, info fs.DirEntry, err error) error {
+			err = visit(realPath, info, err)
+			if errors.Is(err, filepath.SkipAll) {
+				stopped = true
+			}
+			return err
+		})
+	}
 
-		return walkFn(walkCtx)
-	})
+	return walkDir(dir, realDir)
 }
 
 func (l *workspaceReader) Tree(ctx context.Context, workspacePath string, subDir string, option types.TreeOptions) ([]*types.TreeNode, error) {
@@ -543,11 +709,16 @@ func (w *workspaceReader) GetProjectByFilePath(ctx context.Context, workspacePat
 	if len(projects) == 0 {
 		return nil, fmt.Errorf("found no projects in workspace %s", workspacePath)
 	}
+	// 嵌套项目取最深的一个
+	var matched *Project
 	for _, p := range projects {
-		if utils.IsSubdir(p.Path, filePath) {
-			return p, nil
+		if utils.IsSubdir(p.Path, filePath) && (matched == nil || len(p.Path) > len(matched.Path)) {
+			matched = p
 		}
 	}
+	if matched != nil {
+		return matched, nil
+	}
 	return nil, fmt.Errorf("failed to find project which file %s belongs to in workspace %s", filePath, workspacePath)
 }
 
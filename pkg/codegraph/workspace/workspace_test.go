@@ -65,6 +65,28 @@ func TestFindProjects(t *testing.T) {
 			expectNum: 1,
 			expectHas: []string{"."},
 		},
+		{
+			name: "go模块下嵌套的go模块作为独立项目",
+			structure: map[string]bool{
+				".git":              true,
+				"go.mod":            false,
+				"tools/go.mod":      false,
+				"tools/gen/go.mod":  false,
+				"vendor/dep/go.mod": false,
+				"pkg/util.go":       false,
+			},
+			expectNum: 3,
+			expectHas: []string{".", "tools", "tools/gen"},
+		},
+		{
+			name: "根目录不是go模块时不拆分子目录的go.mod",
+			structure: map[string]bool{
+				".git":         true,
+				"tools/go.mod": false,
+			},
+			expectNum: 1,
+			expectHas: []string{"."},
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,3 +113,160 @@ func TestFindProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestFindProjects_StableUuid(t *testing.T) {
+	dir := createTestDir(t, map[string]bool{
+		"repo-a/.git":       true,
+		"repo-b/.git":       true,
+		"group/repo-c/.git": true,
+	})
+	wr := NewWorkSpaceReader(NewMockLogger())
+	uuids := func(workspacePath string) map[string]string {
+		result := make(map[string]string)
+		for _, p := range wr.FindProjects(context.Background(), workspacePath, false, &types.VisitPattern{}) {
+			result[filepath.Clean(p.Path)] = p.Uuid
+		}
+		return result
+	}
+
+	first := uuids(dir)
+	assert.Len(t, first, 3)
+	assert.Equal(t, first, uuids(dir), "两次扫描的项目UUID应一致")
+	assert.Equal(t, first, uuids(dir+string(filepath.Separator)), "工作区路径带末尾分隔符时UUID应一致")
+	assert.Equal(t, first, uuids(filepath.Join(dir, "group")+string(filepath.Separator)+".."), "工作区路径含 .. 时UUID应一致")
+
+	seen := make(map[string]bool)
+	for _, uuid := range first {
+		assert.False(t, seen[uuid], "不同项目的UUID不应重复")
+		seen[uuid] = true
+	}
+
+	// 工作区本身即为项目
+	repoA := filepath.Join(dir, "repo-a")
+	assert.Equal(t, uuids(repoA), uuids(repoA+string(filepath.Separator)))
+	assert.Equal(t, first[repoA], uuids(repoA)[repoA])
+}
+
+func TestWalkFile_Symlinks(t *testing.T) {
+	dir := createTestDir(t, map[string]bool{
+		"src/main.go":     false,
+		"src/pkg/util.go": false,
+	})
+	// 目录链接指回祖先目录形成环路，另一个目录链接和文件链接指向树内已有的路径
+	if err := os.Symlink(dir, filepath.Join(dir, "src", "pkg", "loop")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "src", "pkg"), filepath.Join(dir, "pkglink")))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "src", "main.go"), filepath.Join(dir, "main_link.go")))
+
+	tests := []struct {
+		name         string
+		skipSymlinks bool
+		wantFiles    int
+	}{
+		{name: "跟随链接按真实路径去重", wantFiles: 2},
+		{name: "跳过所有链接", skipSymlinks: true, wantFiles: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := NewWorkSpaceReader(NewMockLogger())
+			var files []string
+			err := wr.WalkFile(context.Background(), dir, func(walkCtx *types.WalkContext) error {
+				files = append(files, walkCtx.RelativePath)
+				return nil
+			}, types.WalkOptions{IgnoreError: true, VisitPattern: &types.VisitPattern{}, SkipSymlinks: tt.skipSymlinks})
+			assert.NoError(t, err)
+			assert.Len(t, files, tt.wantFiles, "files: %v", files)
+		})
+	}
+
+	t.Run("指向根目录之外的链接", func(t *testing.T) {
+		outside := createTestDir(t, map[string]bool{"lib/ext.go": false, "secret.go": false})
+		root := createTestDir(t, map[string]bool{"main.go": false})
+		assert.NoError(t, os.Symlink(filepath.Join(outside, "lib"), filepath.Join(root, "lib")))
+		assert.NoError(t, os.Symlink(filepath.Join(outside, "secret.go"), filepath.Join(root, "secret.go")))
+		wr := NewWorkSpaceReader(NewMockLogger())
+
+		externalTests := []struct {
+			name        string
+			follow      bool
+			concurrency int
+			wantFiles   []string
+		}{
+			{name: "默认不跟随", wantFiles: []string{"main.go"}},
+			{name: "并发遍历默认不跟随", concurrency: 4, wantFiles: []string{"main.go"}},
+			{name: "开启后跟随", follow: true, wantFiles: []string{"main.go", "lib/ext.go", "secret.go"}},
+			{name: "并发遍历开启后跟随", follow: true, concurrency: 4,
+				wantFiles: []string{"main.go", "lib/ext.go", "secret.go"}},
+		}
+		for _, tt := range externalTests {
+			t.Run(tt.name, func(t *testing.T) {
+				var files []string
+				err := wr.WalkFile(context.Background(), root, func(walkCtx *types.WalkContext) error {
+					files = append(files, walkCtx.RelativePath)
+					return nil
+				}, types.WalkOptions{IgnoreError: true, VisitPattern: &types.VisitPattern{},
+					FollowExternalSymlinks: tt.follow, Concurrency: tt.concurrency})
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, tt.wantFiles, files)
+			})
+		}
+	})
+}
+
+func TestWalkFile_Concurrent(t *testing.T) {
+	structure := map[string]bool{".hidden/skip.go": false, "node_modules/dep.js": false}
+	for _, d := range []string{"a", "a/b", "a/b/c", "d", "e/f"} {
+		for _, f := range []string{"1.go", "2.go", "3.py"} {
+			structure[filepath.Join(d, f)] = false
+		}
+	}
+	dir := createTestDir(t, structure)
+	if err := os.Symlink(dir, filepath.Join(dir, "a", "loop")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	wr := NewWorkSpaceReader(NewMockLogger())
+	walk := func(concurrency int, limit int) []string {
+		var files []string
+		err := wr.WalkFile(context.Background(), dir, func(walkCtx *types.WalkContext) error {
+			if limit > 0 && len(files) >= limit {
+				return filepath.SkipAll
+			}
+			files = append(files, walkCtx.RelativePath)
+			return nil
+		}, types.WalkOptions{IgnoreError: true, VisitPattern: DefaultVisitPattern, Concurrency: concurrency})
+		assert.NoError(t, err)
+		return files
+	}
+	sequential := walk(1, 0)
+	assert.Len(t, sequential, 15)
+
+	tests := []struct {
+		name        string
+		concurrency int
+		limit       int
+		wantLen     int
+	}{
+		{name: "并发遍历结果与串行一致", concurrency: 4, wantLen: 15},
+		{name: "回调中的数量上限精确生效", concurrency: 4, limit: 7, wantLen: 7},
+		{name: "协程数多于目录数", concurrency: 32, wantLen: 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := walk(tt.concurrency, tt.limit)
+			assert.Len(t, files, tt.wantLen)
+			assert.Subset(t, sequential, files)
+			if tt.limit == 0 {
+				assert.ElementsMatch(t, sequential, files)
+			}
+		})
+	}
+
+	t.Run("上下文取消", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := wr.WalkFile(ctx, dir, func(walkCtx *types.WalkContext) error { return nil },
+			types.WalkOptions{IgnoreError: true, VisitPattern: DefaultVisitPattern, Concurrency: 4})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIdHeader 透传请求ID的HTTP头
+const RequestIdHeader = "X-Request-ID"
+
+// maxRequestIdLength 客户端传入的请求ID的最大长度
+const maxRequestIdLength = 64
+
+type requestIdKey struct{}
+
+// NewRequestId 生成随机请求ID
+func NewRequestId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// IsValidRequestId 请求ID是否可以写入日志：非空、不超过 64 个字符，只包含字母、数字和 "-"、"_"、"."、":"
+func IsValidRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeRequestId 客户端传入的请求ID合法时沿用，否则生成新的请求ID
+func NormalizeRequestId(id string) string {
+	if IsValidRequestId(id) {
+		return id
+	}
+	return NewRequestId()
+}
+
+// WithRequestId 将请求ID写入上下文，id 为空时原样返回
+func WithRequestId(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// RequestIdFromContext 从上下文中取出请求ID，不存在时返回空串
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// FormatRequestId 格式化请求ID作为日志前缀，如 "[req:1a2b3c] "，id 为空时返回空串
+func FormatRequestId(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "[req:" + id + "] "
+}
+
+// ContextLogger 返回在每条日志前附加上下文请求ID的 Logger，上下文没有请求ID时返回 l 本身
+func ContextLogger(ctx context.Context, l Logger) Logger {
+	id := RequestIdFromContext(ctx)
+	if id == "" {
+		return l
+	}
+	return &contextLogger{Logger: l, prefix: FormatRequestId(id)}
+}
+
+// contextLogger 带固定前缀的 Logger
+type contextLogger struct {
+	Logger
+	prefix string
+}
+
+// Debug level log
+func (l *contextLogger) Debug(format string, args ...any) {
+	l.Logger.Debug(l.prefix+format, args...)
+}
+
+// Info level log
+func (l *contextLogger) Info(format string, args ...any) {
+	l.Logger.Info(l.prefix+format, args...)
+}
+
+// Warning level log
+func (l *contextLogger) Warn(format string, args ...any) {
+	l.Logger.Warn(l.prefix+format, args...)
+}
+
+// Error level log
+func (l *contextLogger) Error(format string, args ...any) {
+	l.Logger.Error(l.prefix+format, args...)
+}
+
+// Fatal error log
+func (l *contextLogger) Fatal(format string, args ...any) {
+	l.Logger.Fatal(l.prefix+format, args...)
+}
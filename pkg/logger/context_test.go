@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeRequestId(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantKeep bool
+	}{
+		{name: "十六进制ID", id: "1a2b3c4d", wantKeep: true},
+		{name: "UUID", id: "3f2b8c1e-7d4a-4f0b-9c6e-2a1d5e8f7b90", wantKeep: true},
+		{name: "带点和冒号", id: "client.vscode:42", wantKeep: true},
+		{name: "为空时生成"},
+		{name: "包含换行", id: "abc\n[req:forged] login ok"},
+		{name: "包含空格", id: "abc def"},
+		{name: "包含非ASCII字符", id: "请求"},
+		{name: "超过长度上限", id: strings.Repeat("a", maxRequestIdLength+1)},
+		{name: "长度上限", id: strings.Repeat("a", maxRequestIdLength), wantKeep: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantKeep, IsValidRequestId(tt.id))
+			got := NormalizeRequestId(tt.id)
+			if tt.wantKeep {
+				assert.Equal(t, tt.id, got)
+				return
+			}
+			assert.NotEqual(t, tt.id, got)
+			assert.True(t, IsValidRequestId(got))
+		})
+	}
+}
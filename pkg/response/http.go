@@ -39,6 +39,11 @@ func Ok(c *gin.Context) {
 	c.JSON(http.StatusOK, wrapResponse(nil))
 }
 
+// Accepted 请求已受理，异步执行
+func Accepted(c *gin.Context) {
+	c.JSON(http.StatusAccepted, wrapResponse(nil))
+}
+
 func Error(c *gin.Context, httpStatusCode int, e error) {
 	c.JSON(httpStatusCode, wrapResponse(e))
 }
@@ -49,6 +54,13 @@ func Bytes(c *gin.Context, v []byte) {
 	_, _ = c.Writer.Write(v)
 }
 
+// Text 以指定的内容类型返回文本
+func Text(c *gin.Context, contentType string, v string) {
+	c.Header("Content-Type", contentType)
+	c.Writer.WriteHeader(http.StatusOK)
+	_, _ = c.Writer.WriteString(v)
+}
+
 func OkJson(c *gin.Context, v any) {
 	c.JSON(http.StatusOK, wrapResponse(v))
 }
@@ -0,0 +1,51 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NDJSONWriter 以 NDJSON（每行一个 JSON 对象）分块写出响应，每行写入后立即刷新
+type NDJSONWriter struct {
+	c       *gin.Context
+	started bool
+	mu      sync.Mutex
+}
+
+// NewNDJSONWriter 创建 NDJSON 流式写入器
+func NewNDJSONWriter(c *gin.Context) *NDJSONWriter {
+	return &NDJSONWriter{c: c}
+}
+
+// Write 写入一行
+func (w *NDJSONWriter) Write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// 第一次写入时设置响应头
+	if !w.started {
+		w.c.Header("Content-Type", "application/x-ndjson")
+		w.c.Header("Transfer-Encoding", "chunked")
+		w.c.Writer.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+	if _, err = w.c.Writer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// Started 是否已经开始写出响应，开始后无法再返回普通的错误响应
+func (w *NDJSONWriter) Started() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.started
+}
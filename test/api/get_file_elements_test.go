@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GetFileElementsIntegrationTestSuite struct {
+	BaseIntegrationTestSuite
+}
+
+type getFileElementsTestCase struct {
+	name           string
+	workspace      string
+	path           string
+	expectedStatus int
+	expectedCode   string
+	validateResp   func(t *testing.T, response map[string]interface{})
+}
+
+func (s *GetFileElementsIntegrationTestSuite) TestGetFileElements() {
+	testCases := []getFileElementsTestCase{
+		{
+			name:           "获取已索引文件的元素表",
+			workspace:      s.workspacePath,
+			path:           filepath.Join(s.workspacePath, "internal/handler/backend.go"),
+			expectedStatus: http.StatusOK,
+			expectedCode:   "0",
+			validateResp: func(t *testing.T, response map[string]interface{}) {
+				assert.True(t, response["success"].(bool))
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "go", data["language"])
+				assert.Greater(t, data["timestamp"].(float64), float64(0))
+				assert.NotEmpty(t, data["imports"])
+
+				names := make(map[string]bool)
+				for _, e := range data["elements"].([]interface{}) {
+					element := e.(map[string]interface{})
+					assert.Contains(t, element, "elementType")
+					assert.Contains(t, element, "range")
+					names[element["name"].(string)] = true
+				}
+				assert.True(t, names["SearchReference"])
+				assert.True(t, names["GetFileElements"])
+			},
+		},
+		{
+			name:           "相对路径",
+			workspace:      s.workspacePath,
+			path:           "internal/handler/backend.go",
+			expectedStatus: http.StatusOK,
+			expectedCode:   "0",
+			validateResp: func(t *testing.T, response map[string]interface{}) {
+				assert.True(t, response["success"].(bool))
+				data := response["data"].(map[string]interface{})
+				assert.NotEmpty(t, data["elements"])
+			},
+		},
+		{
+			name:           "缺少path参数",
+			workspace:      s.workspacePath,
+			expectedStatus: http.StatusBadRequest,
+			validateResp: func(t *testing.T, response map[string]interface{}) {
+				assert.False(t, response["success"].(bool))
+			},
+		},
+		{
+			name:           "未索引的文件",
+			workspace:      s.workspacePath,
+			path:           filepath.Join(s.workspacePath, "not_exists.go"),
+			expectedStatus: http.StatusBadRequest,
+			validateResp: func(t *testing.T, response map[string]interface{}) {
+				assert.False(t, response["success"].(bool))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.name, func(t *testing.T) {
+			reqURL, err := url.Parse(s.baseURL + "/codebase-indexer/api/v1/index/file-elements")
+			s.Require().NoError(err)
+
+			q := reqURL.Query()
+			if tc.workspace != "" {
+				q.Add("workspace", tc.workspace)
+			}
+			if tc.path != "" {
+				q.Add("path", tc.path)
+			}
+			reqURL.RawQuery = q.Encode()
+
+			req, err := s.CreateGETRequest(reqURL.String())
+			s.Require().NoError(err)
+
+			resp, err := s.SendRequest(req)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			s.AssertHTTPStatus(t, tc.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			s.Require().NoError(err)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(body, &response)
+			s.Require().NoError(err)
+
+			s.ValidateCommonResponse(t, response, tc.expectedCode)
+
+			if tc.validateResp != nil {
+				tc.validateResp(t, response)
+			}
+		})
+	}
+}
+
+func TestGetFileElementsIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(GetFileElementsIntegrationTestSuite))
+}
@@ -96,6 +96,7 @@ func TestWalkProjectCostTime(t *testing.T) {
 	ctx := context.Background()
 	env, err := setupTestEnvironment()
 	assert.NoError(t, err)
+	defer teardownTestEnvironment(t, env)
 	testCases := []struct {
 		name  string
 		path  string
@@ -16,6 +16,7 @@ func TestModuleResolver(t *testing.T) {
 	if err != nil {
 		panic(err)
 	}
+	defer teardownTestEnvironment(t, env)
 	testCases := []struct {
 		Name            string
 		Workspace       string
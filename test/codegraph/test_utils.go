@@ -69,6 +69,10 @@ func setupTestEnvironment() (*testEnvironment, error) {
 
 	// 创建存储
 	storage, err := store.NewLevelDBStorage(storageDir, newLogger)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 
 	// 创建工作区读取器
 	workspaceReader := workspace.NewWorkSpaceReader(newLogger)
@@ -36,6 +36,35 @@ func (m *MockGraphStorage) EXPECT() *MockGraphStorageMockRecorder {
 	return m.recorder
 }
 
+// BatchDelete mocks base method.
+func (m *MockGraphStorage) BatchDelete(ctx context.Context, projectUuid string, keys []store.Key) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDelete", ctx, projectUuid, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchDelete indicates an expected call of BatchDelete.
+func (mr *MockGraphStorageMockRecorder) BatchDelete(ctx, projectUuid, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDelete", reflect.TypeOf((*MockGraphStorage)(nil).BatchDelete), ctx, projectUuid, keys)
+}
+
+// BatchGet mocks base method.
+func (m *MockGraphStorage) BatchGet(ctx context.Context, projectUuid string, keys []store.Key) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchGet", ctx, projectUuid, keys)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchGet indicates an expected call of BatchGet.
+func (mr *MockGraphStorageMockRecorder) BatchGet(ctx, projectUuid, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchGet", reflect.TypeOf((*MockGraphStorage)(nil).BatchGet), ctx, projectUuid, keys)
+}
+
 // BatchSave mocks base method.
 func (m *MockGraphStorage) BatchSave(ctx context.Context, projectUuid string, values store.Entries) error {
 	m.ctrl.T.Helper()
@@ -64,6 +93,20 @@ func (mr *MockGraphStorageMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockGraphStorage)(nil).Close))
 }
 
+// Compact mocks base method.
+func (m *MockGraphStorage) Compact(ctx context.Context, projectUuid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compact", ctx, projectUuid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Compact indicates an expected call of Compact.
+func (mr *MockGraphStorageMockRecorder) Compact(ctx, projectUuid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compact", reflect.TypeOf((*MockGraphStorage)(nil).Compact), ctx, projectUuid)
+}
+
 // Delete mocks base method.
 func (m *MockGraphStorage) Delete(ctx context.Context, projectUuid string, key store.Key) error {
 	m.ctrl.T.Helper()
@@ -136,6 +179,35 @@ func (mr *MockGraphStorageMockRecorder) Iter(ctx, projectUuid interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iter", reflect.TypeOf((*MockGraphStorage)(nil).Iter), ctx, projectUuid)
 }
 
+// IterPrefix mocks base method.
+func (m *MockGraphStorage) IterPrefix(ctx context.Context, projectUuid, prefix string) store.Iterator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterPrefix", ctx, projectUuid, prefix)
+	ret0, _ := ret[0].(store.Iterator)
+	return ret0
+}
+
+// IterPrefix indicates an expected call of IterPrefix.
+func (mr *MockGraphStorageMockRecorder) IterPrefix(ctx, projectUuid, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterPrefix", reflect.TypeOf((*MockGraphStorage)(nil).IterPrefix), ctx, projectUuid, prefix)
+}
+
+// ListProjects mocks base method.
+func (m *MockGraphStorage) ListProjects() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjects")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjects indicates an expected call of ListProjects.
+func (mr *MockGraphStorageMockRecorder) ListProjects() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjects", reflect.TypeOf((*MockGraphStorage)(nil).ListProjects))
+}
+
 // ProjectIndexExists mocks base method.
 func (m *MockGraphStorage) ProjectIndexExists(projectUuid string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"codebase-indexer/internal/config"
 	"codebase-indexer/internal/dto"
 
@@ -11,6 +13,10 @@ type MockHTTPSync struct {
 	mock.Mock
 }
 
+func (m *MockHTTPSync) SetContext(ctx context.Context) {
+	m.Called(ctx)
+}
+
 func (m *MockHTTPSync) SetSyncConfig(config *config.SyncConfig) {
 	m.Called(config)
 }
@@ -5,10 +5,12 @@
 package mocks
 
 import (
+	cache "codebase-indexer/pkg/codegraph/cache"
 	codegraphpb "codebase-indexer/pkg/codegraph/proto/codegraphpb"
 	store "codebase-indexer/pkg/codegraph/store"
 	types "codebase-indexer/pkg/codegraph/types"
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -32,11 +34,70 @@ func NewMockIndexer(ctrl *gomock.Controller) *MockIndexer {
 	return mock
 }
 
+// CleanOrphanedProjectIndexes mocks base method.
+func (m *MockIndexer) CleanOrphanedProjectIndexes(ctx context.Context, workspacePaths []string) ([]*types.IndexedProject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanOrphanedProjectIndexes", ctx, workspacePaths)
+	ret0, _ := ret[0].([]*types.IndexedProject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanOrphanedProjectIndexes indicates an expected call of CleanOrphanedProjectIndexes.
+func (mr *MockIndexerMockRecorder) CleanOrphanedProjectIndexes(ctx, workspacePaths interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanOrphanedProjectIndexes", reflect.TypeOf((*MockIndexer)(nil).CleanOrphanedProjectIndexes), ctx, workspacePaths)
+}
+
+// CompactIndexes mocks base method.
+func (m *MockIndexer) CompactIndexes(ctx context.Context, workspacePath string, minDeleted int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompactIndexes", ctx, workspacePath, minDeleted)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompactIndexes indicates an expected call of CompactIndexes.
+func (mr *MockIndexerMockRecorder) CompactIndexes(ctx, workspacePath, minDeleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompactIndexes", reflect.TypeOf((*MockIndexer)(nil).CompactIndexes), ctx, workspacePath, minDeleted)
+}
+
 // EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockIndexer) EXPECT() *MockIndexerMockRecorder {
 	return m.recorder
 }
 
+// ExportSCIP mocks base method.
+func (m *MockIndexer) ExportSCIP(ctx context.Context, workspacePath string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportSCIP", ctx, workspacePath, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportSCIP indicates an expected call of ExportSCIP.
+func (mr *MockIndexerMockRecorder) ExportSCIP(ctx, workspacePath, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportSCIP", reflect.TypeOf((*MockIndexer)(nil).ExportSCIP), ctx, workspacePath, w)
+}
+
+// FindUnusedSymbols mocks base method.
+func (m *MockIndexer) FindUnusedSymbols(ctx context.Context, workspacePath string, opts *types.FindUnusedSymbolsOptions) ([]*types.Definition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUnusedSymbols", ctx, workspacePath, opts)
+	ret0, _ := ret[0].([]*types.Definition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUnusedSymbols indicates an expected call of FindUnusedSymbols.
+func (mr *MockIndexerMockRecorder) FindUnusedSymbols(ctx, workspacePath, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUnusedSymbols", reflect.TypeOf((*MockIndexer)(nil).FindUnusedSymbols), ctx, workspacePath, opts)
+}
+
 // GetFileElementTable mocks base method.
 func (m *MockIndexer) GetFileElementTable(ctx context.Context, workspacePath, filePath string) (*codegraphpb.FileElementTable, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +113,21 @@ func (mr *MockIndexerMockRecorder) GetFileElementTable(ctx, workspacePath, fileP
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileElementTable", reflect.TypeOf((*MockIndexer)(nil).GetFileElementTable), ctx, workspacePath, filePath)
 }
 
+// GetFileOutline mocks base method.
+func (m *MockIndexer) GetFileOutline(ctx context.Context, workspacePath, filePath string) (*types.FileOutline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileOutline", ctx, workspacePath, filePath)
+	ret0, _ := ret[0].(*types.FileOutline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileOutline indicates an expected call of GetFileOutline.
+func (mr *MockIndexerMockRecorder) GetFileOutline(ctx, workspacePath, filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileOutline", reflect.TypeOf((*MockIndexer)(nil).GetFileOutline), ctx, workspacePath, filePath)
+}
+
 // GetSummary mocks base method.
 func (m *MockIndexer) GetSummary(ctx context.Context, workspacePath string) (*types.CodeGraphSummary, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +143,21 @@ func (mr *MockIndexerMockRecorder) GetSummary(ctx, workspacePath interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummary", reflect.TypeOf((*MockIndexer)(nil).GetSummary), ctx, workspacePath)
 }
 
+// IndexChangedSince mocks base method.
+func (m *MockIndexer) IndexChangedSince(ctx context.Context, workspacePath, gitRef string) (*types.IndexTaskMetrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexChangedSince", ctx, workspacePath, gitRef)
+	ret0, _ := ret[0].(*types.IndexTaskMetrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IndexChangedSince indicates an expected call of IndexChangedSince.
+func (mr *MockIndexerMockRecorder) IndexChangedSince(ctx, workspacePath, gitRef interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexChangedSince", reflect.TypeOf((*MockIndexer)(nil).IndexChangedSince), ctx, workspacePath, gitRef)
+}
+
 // IndexFiles mocks base method.
 func (m *MockIndexer) IndexFiles(ctx context.Context, workspacePath string, filePaths []string) error {
 	m.ctrl.T.Helper()
@@ -110,6 +201,78 @@ func (mr *MockIndexerMockRecorder) IndexWorkspace(ctx, workspacePath interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexWorkspace", reflect.TypeOf((*MockIndexer)(nil).IndexWorkspace), ctx, workspacePath)
 }
 
+// ListIndexedProjects mocks base method.
+func (m *MockIndexer) ListIndexedProjects(ctx context.Context, workspacePath string) ([]*types.IndexedProject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIndexedProjects", ctx, workspacePath)
+	ret0, _ := ret[0].([]*types.IndexedProject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIndexedProjects indicates an expected call of ListIndexedProjects.
+func (mr *MockIndexerMockRecorder) ListIndexedProjects(ctx, workspacePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIndexedProjects", reflect.TypeOf((*MockIndexer)(nil).ListIndexedProjects), ctx, workspacePath)
+}
+
+// RebuildCalleeMap mocks base method.
+func (m *MockIndexer) RebuildCalleeMap(ctx context.Context, workspacePath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildCalleeMap", ctx, workspacePath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildCalleeMap indicates an expected call of RebuildCalleeMap.
+func (mr *MockIndexerMockRecorder) RebuildCalleeMap(ctx, workspacePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildCalleeMap", reflect.TypeOf((*MockIndexer)(nil).RebuildCalleeMap), ctx, workspacePath)
+}
+
+// SymbolCacheStats mocks base method.
+func (m *MockIndexer) SymbolCacheStats() cache.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SymbolCacheStats")
+	ret0, _ := ret[0].(cache.Stats)
+	return ret0
+}
+
+// SymbolCacheStats indicates an expected call of SymbolCacheStats.
+func (mr *MockIndexerMockRecorder) SymbolCacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SymbolCacheStats", reflect.TypeOf((*MockIndexer)(nil).SymbolCacheStats))
+}
+
+// Shutdown mocks base method.
+func (m *MockIndexer) Shutdown(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockIndexerMockRecorder) Shutdown(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockIndexer)(nil).Shutdown), ctx)
+}
+
+// ParseAndIndexBlobs mocks base method.
+func (m *MockIndexer) ParseAndIndexBlobs(ctx context.Context, projectId string, files []types.SourceFile) (*types.IndexTaskMetrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseAndIndexBlobs", ctx, projectId, files)
+	ret0, _ := ret[0].(*types.IndexTaskMetrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseAndIndexBlobs indicates an expected call of ParseAndIndexBlobs.
+func (mr *MockIndexerMockRecorder) ParseAndIndexBlobs(ctx, projectId, files interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseAndIndexBlobs", reflect.TypeOf((*MockIndexer)(nil).ParseAndIndexBlobs), ctx, projectId, files)
+}
+
 // QueryCallGraph mocks base method.
 func (m *MockIndexer) QueryCallGraph(ctx context.Context, opts *types.QueryCallGraphOptions) ([]*types.RelationNode, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +303,36 @@ func (mr *MockIndexerMockRecorder) QueryDefinitions(ctx, options interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryDefinitions", reflect.TypeOf((*MockIndexer)(nil).QueryDefinitions), ctx, options)
 }
 
+// QueryExpandedContext mocks base method.
+func (m *MockIndexer) QueryExpandedContext(ctx context.Context, opts *types.QueryExpandedContextOptions) (*types.ExpandedContext, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryExpandedContext", ctx, opts)
+	ret0, _ := ret[0].(*types.ExpandedContext)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryExpandedContext indicates an expected call of QueryExpandedContext.
+func (mr *MockIndexerMockRecorder) QueryExpandedContext(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryExpandedContext", reflect.TypeOf((*MockIndexer)(nil).QueryExpandedContext), ctx, opts)
+}
+
+// QueryNamingIssues mocks base method.
+func (m *MockIndexer) QueryNamingIssues(ctx context.Context, workspacePath string, rules []*types.NamingRule) ([]*types.NamingIssue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryNamingIssues", ctx, workspacePath, rules)
+	ret0, _ := ret[0].([]*types.NamingIssue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryNamingIssues indicates an expected call of QueryNamingIssues.
+func (mr *MockIndexerMockRecorder) QueryNamingIssues(ctx, workspacePath, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryNamingIssues", reflect.TypeOf((*MockIndexer)(nil).QueryNamingIssues), ctx, workspacePath, rules)
+}
+
 // QueryReferences mocks base method.
 func (m *MockIndexer) QueryReferences(ctx context.Context, opts *types.QueryReferenceOptions) ([]*types.RelationNode, error) {
 	m.ctrl.T.Helper()
@@ -155,6 +348,94 @@ func (mr *MockIndexerMockRecorder) QueryReferences(ctx, opts interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryReferences", reflect.TypeOf((*MockIndexer)(nil).QueryReferences), ctx, opts)
 }
 
+// QueryReferencesPage mocks base method.
+func (m *MockIndexer) QueryReferencesPage(ctx context.Context, opts *types.QueryReferenceOptions) (*types.ReferencePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryReferencesPage", ctx, opts)
+	ret0, _ := ret[0].(*types.ReferencePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryReferencesPage indicates an expected call of QueryReferencesPage.
+func (mr *MockIndexerMockRecorder) QueryReferencesPage(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryReferencesPage", reflect.TypeOf((*MockIndexer)(nil).QueryReferencesPage), ctx, opts)
+}
+
+// QueryReferencesStream mocks base method.
+func (m *MockIndexer) QueryReferencesStream(ctx context.Context, opts *types.QueryReferenceOptions, emit types.ReferenceEmitter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryReferencesStream", ctx, opts, emit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QueryReferencesStream indicates an expected call of QueryReferencesStream.
+func (mr *MockIndexerMockRecorder) QueryReferencesStream(ctx, opts, emit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryReferencesStream", reflect.TypeOf((*MockIndexer)(nil).QueryReferencesStream), ctx, opts, emit)
+}
+
+// QueryTypeDefinitions mocks base method.
+func (m *MockIndexer) QueryTypeDefinitions(ctx context.Context, opts *types.QueryDefinitionOptions) ([]*types.Definition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryTypeDefinitions", ctx, opts)
+	ret0, _ := ret[0].([]*types.Definition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryTypeDefinitions indicates an expected call of QueryTypeDefinitions.
+func (mr *MockIndexerMockRecorder) QueryTypeDefinitions(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryTypeDefinitions", reflect.TypeOf((*MockIndexer)(nil).QueryTypeDefinitions), ctx, opts)
+}
+
+// RebuildProject mocks base method.
+func (m *MockIndexer) RebuildProject(ctx context.Context, workspacePath, projectPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildProject", ctx, workspacePath, projectPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildProject indicates an expected call of RebuildProject.
+func (mr *MockIndexerMockRecorder) RebuildProject(ctx, workspacePath, projectPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildProject", reflect.TypeOf((*MockIndexer)(nil).RebuildProject), ctx, workspacePath, projectPath)
+}
+
+// ReconcileCodegraphFileNum mocks base method.
+func (m *MockIndexer) ReconcileCodegraphFileNum(ctx context.Context, workspacePath string, fix bool) (*types.CodegraphReconcileResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileCodegraphFileNum", ctx, workspacePath, fix)
+	ret0, _ := ret[0].(*types.CodegraphReconcileResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileCodegraphFileNum indicates an expected call of ReconcileCodegraphFileNum.
+func (mr *MockIndexerMockRecorder) ReconcileCodegraphFileNum(ctx, workspacePath, fix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileCodegraphFileNum", reflect.TypeOf((*MockIndexer)(nil).ReconcileCodegraphFileNum), ctx, workspacePath, fix)
+}
+
+// ReindexWorkspace mocks base method.
+func (m *MockIndexer) ReindexWorkspace(ctx context.Context, workspacePath string) (*types.IndexTaskMetrics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReindexWorkspace", ctx, workspacePath)
+	ret0, _ := ret[0].(*types.IndexTaskMetrics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReindexWorkspace indicates an expected call of ReindexWorkspace.
+func (mr *MockIndexerMockRecorder) ReindexWorkspace(ctx, workspacePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReindexWorkspace", reflect.TypeOf((*MockIndexer)(nil).ReindexWorkspace), ctx, workspacePath)
+}
+
 // RemoveAllIndexes mocks base method.
 func (m *MockIndexer) RemoveAllIndexes(ctx context.Context, workspacePath string) error {
 	m.ctrl.T.Helper()
@@ -183,6 +464,20 @@ func (mr *MockIndexerMockRecorder) RemoveIndexes(ctx, workspacePath, filePaths i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIndexes", reflect.TypeOf((*MockIndexer)(nil).RemoveIndexes), ctx, workspacePath, filePaths)
 }
 
+// RemoveProjectIndexes mocks base method.
+func (m *MockIndexer) RemoveProjectIndexes(ctx context.Context, workspacePath string, projectUuids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveProjectIndexes", ctx, workspacePath, projectUuids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveProjectIndexes indicates an expected call of RemoveProjectIndexes.
+func (mr *MockIndexerMockRecorder) RemoveProjectIndexes(ctx, workspacePath, projectUuids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProjectIndexes", reflect.TypeOf((*MockIndexer)(nil).RemoveProjectIndexes), ctx, workspacePath, projectUuids)
+}
+
 // RenameIndexes mocks base method.
 func (m *MockIndexer) RenameIndexes(ctx context.Context, workspacePath, sourceFilePath, targetFilePath string) error {
 	m.ctrl.T.Helper()
@@ -196,3 +491,61 @@ func (mr *MockIndexerMockRecorder) RenameIndexes(ctx, workspacePath, sourceFileP
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameIndexes", reflect.TypeOf((*MockIndexer)(nil).RenameIndexes), ctx, workspacePath, sourceFilePath, targetFilePath)
 }
+
+// SetIndexFilter mocks base method.
+func (m *MockIndexer) SetIndexFilter(eventId int64, filter *types.IndexFilter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetIndexFilter", eventId, filter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetIndexFilter indicates an expected call of SetIndexFilter.
+func (mr *MockIndexerMockRecorder) SetIndexFilter(eventId, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIndexFilter", reflect.TypeOf((*MockIndexer)(nil).SetIndexFilter), eventId, filter)
+}
+
+// TakeIndexFilter mocks base method.
+func (m *MockIndexer) TakeIndexFilter(eventId int64) *types.IndexFilter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeIndexFilter", eventId)
+	ret0, _ := ret[0].(*types.IndexFilter)
+	return ret0
+}
+
+// TakeIndexFilter indicates an expected call of TakeIndexFilter.
+func (mr *MockIndexerMockRecorder) TakeIndexFilter(eventId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeIndexFilter", reflect.TypeOf((*MockIndexer)(nil).TakeIndexFilter), eventId)
+}
+
+// ResolveQualifiedName mocks base method.
+func (m *MockIndexer) ResolveQualifiedName(ctx context.Context, workspacePath, qualifiedName string) ([]*types.Definition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveQualifiedName", ctx, workspacePath, qualifiedName)
+	ret0, _ := ret[0].([]*types.Definition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveQualifiedName indicates an expected call of ResolveQualifiedName.
+func (mr *MockIndexerMockRecorder) ResolveQualifiedName(ctx, workspacePath, qualifiedName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveQualifiedName", reflect.TypeOf((*MockIndexer)(nil).ResolveQualifiedName), ctx, workspacePath, qualifiedName)
+}
+
+// VerifyIndex mocks base method.
+func (m *MockIndexer) VerifyIndex(ctx context.Context, workspacePath string) (*types.VerifyReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyIndex", ctx, workspacePath)
+	ret0, _ := ret[0].(*types.VerifyReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyIndex indicates an expected call of VerifyIndex.
+func (mr *MockIndexerMockRecorder) VerifyIndex(ctx, workspacePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyIndex", reflect.TypeOf((*MockIndexer)(nil).VerifyIndex), ctx, workspacePath)
+}